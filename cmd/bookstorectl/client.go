@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// client calls the REST API of a running instance
+type client struct {
+	baseURL      string
+	token        string
+	tenant       string
+	tenantHeader string
+	http         *http.Client
+}
+
+// apiResponse is the envelope of every API response
+type apiResponse struct {
+	Error   bool            `json:"error"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Details interface{}     `json:"details"`
+}
+
+// newClient creates a client for the API at baseURL
+func newClient(baseURL, token, tenant, tenantHeader string, timeout time.Duration) *client {
+	return &client{
+		baseURL:      strings.TrimRight(baseURL, "/") + "/api/v1",
+		token:        token,
+		tenant:       tenant,
+		tenantHeader: tenantHeader,
+		http:         &http.Client{Timeout: timeout},
+	}
+}
+
+// call sends a JSON request and decodes the response envelope, returning an
+// error for non-2xx responses
+func (c *client) call(method, path string, query url.Values, body interface{}) (*apiResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	resp, err := c.do(method, path, query, reader)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unexpected response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode >= 300 || result.Error {
+		if result.Details != nil {
+			return nil, fmt.Errorf("%s (%s): %v", result.Message, resp.Status, result.Details)
+		}
+		return nil, fmt.Errorf("%s (%s)", result.Message, resp.Status)
+	}
+	return &result, nil
+}
+
+// download sends a GET request and copies a successful response body to w
+func (c *client) download(path string, query url.Values, w io.Writer) error {
+	resp, err := c.do(http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var result apiResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Message == "" {
+			return fmt.Errorf("request failed (%s)", resp.Status)
+		}
+		return fmt.Errorf("%s (%s)", result.Message, resp.Status)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil
+}
+
+// do sends an authenticated request
+func (c *client) do(method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.tenant != "" {
+		req.Header.Set(c.tenantHeader, c.tenant)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"time"
+)
+
+const usage = `Usage: bookstorectl [flags] <command> [arguments]
+
+Commands:
+  users create -email=<email> [-name=<name>] [-role=admin]
+  users set-role -id=<user id> -role=<customer|admin>
+  api-keys list
+  api-keys create -name=<name> [-role=admin] [-expires-in=<duration>]
+  api-keys rotate -id=<api key id>
+  api-keys revoke -id=<api key id>
+  reindex
+  export books [-o=<file>] [-columns=<list>] [-q=<query>] [-author-id=<id>] [-category-id=<id>]
+  jobs list
+  jobs run <task name>
+
+Flags:`
+
+func main() {
+	log.SetFlags(0)
+
+	var (
+		baseURL      = flag.String("url", envOr("BOOKSTORE_URL", "http://localhost:8080"), "Base URL of the running instance (BOOKSTORE_URL)")
+		token        = flag.String("token", os.Getenv("BOOKSTORE_TOKEN"), "Admin access token or API key (BOOKSTORE_TOKEN)")
+		tenant       = flag.String("tenant", os.Getenv("BOOKSTORE_TENANT"), "Slug or ID of the tenant to act on (BOOKSTORE_TENANT)")
+		tenantHeader = flag.String("tenant-header", envOr("TENANT_HEADER", "X-Tenant"), "Header the instance reads the tenant from")
+		timeout      = flag.Duration("timeout", 5*time.Minute, "Request timeout")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	c := newClient(*baseURL, *token, *tenant, *tenantHeader, *timeout)
+
+	var err error
+	switch args[0] {
+	case "users":
+		err = runUsers(c, args[1:])
+	case "api-keys":
+		err = runAPIKeys(c, args[1:])
+	case "reindex":
+		err = printData(c.call("POST", "/admin/maintenance/reindex", nil, nil))
+	case "export":
+		err = runExport(c, args[1:])
+	case "jobs":
+		err = runJobs(c, args[1:])
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("bookstorectl: %v", err)
+	}
+}
+
+// runUsers handles the users command
+func runUsers(c *client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("users: expected create or set-role")
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("users create", flag.ExitOnError)
+		email := fs.String("email", "", "Email the user will sign in with")
+		name := fs.String("name", "", "Display name")
+		role := fs.String("role", "admin", "Role: customer or admin")
+		fs.Parse(args[1:])
+		if *email == "" {
+			return fmt.Errorf("users create: -email is required")
+		}
+		return printData(c.call("POST", "/admin/users", nil, map[string]string{
+			"email": *email,
+			"name":  *name,
+			"role":  *role,
+		}))
+
+	case "set-role":
+		fs := flag.NewFlagSet("users set-role", flag.ExitOnError)
+		id := fs.String("id", "", "User ID")
+		role := fs.String("role", "", "Role: customer or admin")
+		fs.Parse(args[1:])
+		if *id == "" || *role == "" {
+			return fmt.Errorf("users set-role: -id and -role are required")
+		}
+		return printMessage(c.call("PUT", "/admin/users/"+url.PathEscape(*id)+"/role", nil, map[string]string{
+			"role": *role,
+		}))
+	}
+	return fmt.Errorf("users: unknown subcommand %q", args[0])
+}
+
+// runAPIKeys handles the api-keys command
+func runAPIKeys(c *client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("api-keys: expected list, create, rotate, or revoke")
+	}
+
+	switch args[0] {
+	case "list":
+		return printData(c.call("GET", "/admin/api-keys", url.Values{"limit": {"100"}}, nil))
+
+	case "create":
+		fs := flag.NewFlagSet("api-keys create", flag.ExitOnError)
+		name := fs.String("name", "", "Name describing where the key is used")
+		role := fs.String("role", "admin", "Role the key acts with: customer or admin")
+		expiresIn := fs.Duration("expires-in", 0, "Lifetime of the key (default: no expiry)")
+		fs.Parse(args[1:])
+		if *name == "" {
+			return fmt.Errorf("api-keys create: -name is required")
+		}
+		body := map[string]interface{}{
+			"name": *name,
+			"role": *role,
+		}
+		if *expiresIn > 0 {
+			body["expires_at"] = time.Now().Add(*expiresIn).UTC().Format(time.RFC3339)
+		}
+		return printData(c.call("POST", "/admin/api-keys", nil, body))
+
+	case "rotate", "revoke":
+		fs := flag.NewFlagSet("api-keys "+args[0], flag.ExitOnError)
+		id := fs.String("id", "", "API key ID")
+		fs.Parse(args[1:])
+		if *id == "" {
+			return fmt.Errorf("api-keys %s: -id is required", args[0])
+		}
+		if args[0] == "rotate" {
+			return printData(c.call("POST", "/admin/api-keys/"+url.PathEscape(*id)+"/rotate", nil, nil))
+		}
+		return printMessage(c.call("DELETE", "/admin/api-keys/"+url.PathEscape(*id), nil, nil))
+	}
+	return fmt.Errorf("api-keys: unknown subcommand %q", args[0])
+}
+
+// runExport handles the export command
+func runExport(c *client, args []string) error {
+	if len(args) == 0 || args[0] != "books" {
+		return fmt.Errorf("export: expected books")
+	}
+
+	fs := flag.NewFlagSet("export books", flag.ExitOnError)
+	output := fs.String("o", "", "File to write the CSV to (default: stdout)")
+	columns := fs.String("columns", "", "Comma-separated columns to export (default: all)")
+	query := fs.String("q", "", "Only books matching the search query")
+	authorID := fs.String("author-id", "", "Only books by the author")
+	categoryID := fs.String("category-id", "", "Only books in the category")
+	fs.Parse(args[1:])
+
+	params := url.Values{"format": {"csv"}}
+	for name, value := range map[string]string{
+		"columns":     *columns,
+		"q":           *query,
+		"author_id":   *authorID,
+		"category_id": *categoryID,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	return c.download("/books/export", params, w)
+}
+
+// runJobs handles the jobs command
+func runJobs(c *client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("jobs: expected list or run")
+	}
+
+	switch args[0] {
+	case "list":
+		return printData(c.call("GET", "/admin/scheduler", nil, nil))
+	case "run":
+		if len(args) != 2 {
+			return fmt.Errorf("jobs run: expected a task name")
+		}
+		return printMessage(c.call("POST", "/admin/scheduler/"+url.PathEscape(args[1])+"/run", nil, nil))
+	}
+	return fmt.Errorf("jobs: unknown subcommand %q", args[0])
+}
+
+// printData prints a response's data as indented JSON
+func printData(resp *apiResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	if len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+// printMessage prints a response's message
+func printMessage(resp *apiResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.Message)
+	return nil
+}
+
+// envOr returns the environment variable key, or fallback when it is unset
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
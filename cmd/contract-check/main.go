@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"bookstore-api/internal/contract"
+	"bookstore-api/internal/testutil"
+)
+
+func main() {
+	var (
+		timeout = flag.Duration("timeout", 5*time.Minute, "Time allowed for starting PostgreSQL and running the checks")
+	)
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	env, err := testutil.Setup(ctx)
+	if err != nil {
+		log.Fatalf("Failed to set up test environment: %v", err)
+	}
+
+	results, err := contract.Run(ctx, env)
+	if closeErr := env.Close(); closeErr != nil {
+		log.Printf("Warning: %v", closeErr)
+	}
+	if err != nil {
+		log.Fatalf("Contract checks failed to run: %v", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Passed() {
+			fmt.Printf("PASS  %s\n", result.Scenario)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s\n", result.Scenario)
+		for _, problem := range result.Problems {
+			fmt.Printf("      - %s\n", problem)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d contract checks failed\n", failed, len(results))
+		os.Exit(1)
+	}
+	fmt.Printf("All %d contract checks passed\n", len(results))
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"bookstore-api/internal/database"
+	"bookstore-api/internal/loadtest"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/testutil"
+)
+
+const usage = `Usage: loadtest <command> [flags]
+
+Commands:
+  bench   Benchmark the hot service paths against a seeded PostgreSQL (requires Docker)
+  k6      Generate a k6 script for a running instance
+  vegeta  Generate a vegeta target list for a running instance
+
+Run "loadtest <command> -h" for a command's flags.`
+
+// errRegressed is returned by runBench when a benchmark regressed
+var errRegressed = errors.New("benchmarks regressed")
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "bench":
+		err = runBench(os.Args[2:])
+	case "k6", "vegeta":
+		err = runScenario(os.Args[1], os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+	if errors.Is(err, errRegressed) {
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("loadtest: %v", err)
+	}
+}
+
+// runBench seeds a database, runs the benchmarks, and compares them with a
+// baseline, returning errRegressed if any benchmark regressed
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	books := fs.Int("books", 5000, "Number of books to seed")
+	authors := fs.Int("authors", 200, "Number of authors to seed")
+	categories := fs.Int("categories", 50, "Number of categories to seed")
+	benchtime := fs.String("benchtime", "1s", "Run time or iteration count (e.g. 100x) per benchmark")
+	baseline := fs.String("baseline", "", "Results file to compare against")
+	save := fs.String("save", "", "File to write the results to, for use as a baseline")
+	tolerance := fs.Float64("tolerance", 0.2, "Allowed growth in ns/op and allocs/op over the baseline, as a fraction")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Time allowed for starting PostgreSQL and seeding")
+	fs.Parse(args)
+
+	var previous []loadtest.Result
+	if *baseline != "" {
+		var err error
+		if previous, err = loadtest.LoadResults(*baseline); err != nil {
+			return err
+		}
+	}
+
+	setupCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	env, err := testutil.Setup(setupCtx)
+	if err != nil {
+		return fmt.Errorf("failed to set up test environment: %w", err)
+	}
+	defer func() {
+		if err := env.Close(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}()
+
+	ctx := tenancy.WithTenantID(context.Background(), models.DefaultTenantID)
+	log.Printf("Seeding %d books, %d authors, and %d categories...", *books, *authors, *categories)
+	fixtures, err := loadtest.Seed(ctx, database.GetDB(), loadtest.SeedOptions{
+		Authors:    *authors,
+		Categories: *categories,
+		Books:      *books,
+	})
+	if err != nil {
+		return err
+	}
+
+	results, err := loadtest.RunBenchmarks(ctx, database.GetDB(), fixtures, *benchtime)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		fmt.Println(result)
+	}
+
+	if *save != "" {
+		if err := loadtest.SaveResults(*save, results); err != nil {
+			return err
+		}
+		log.Printf("Results saved to %s", *save)
+	}
+
+	if previous == nil {
+		return nil
+	}
+	regressions := loadtest.Compare(previous, results, *tolerance)
+	if len(regressions) == 0 {
+		fmt.Printf("No regressions against %s\n", *baseline)
+		return nil
+	}
+	fmt.Printf("%d regressions against %s:\n", len(regressions), *baseline)
+	for _, regression := range regressions {
+		fmt.Printf("  - %s\n", regression)
+	}
+	return errRegressed
+}
+
+// runScenario samples a running instance and writes a k6 script or vegeta
+// target list for it
+func runScenario(format string, args []string) error {
+	fs := flag.NewFlagSet(format, flag.ExitOnError)
+	baseURL := fs.String("url", envOr("BOOKSTORE_URL", "http://localhost:8080"), "Base URL of the running instance (BOOKSTORE_URL)")
+	tenant := fs.String("tenant", os.Getenv("BOOKSTORE_TENANT"), "Slug or ID of the tenant to load (BOOKSTORE_TENANT)")
+	tenantHeader := fs.String("tenant-header", envOr("TENANT_HEADER", "X-Tenant"), "Header the instance reads the tenant from")
+	sampleSize := fs.Int("sample", 100, "Number of books, authors, and categories to sample (at most 100)")
+	output := fs.String("o", "", "Output file (default stdout)")
+	vus := fs.Int("vus", 20, "Virtual users (k6)")
+	duration := fs.Duration("duration", time.Minute, "Test duration (k6)")
+	requests := fs.Int("requests", 10000, "Number of targets (vegeta)")
+	fs.Parse(args)
+
+	scenario := loadtest.Scenario{
+		BaseURL:      *baseURL,
+		Tenant:       *tenant,
+		TenantHeader: *tenantHeader,
+		VUs:          *vus,
+		Duration:     *duration,
+		Requests:     *requests,
+	}
+
+	sample, err := loadtest.FetchSample(&http.Client{Timeout: 30 * time.Second}, scenario, *sampleSize)
+	if err != nil {
+		return err
+	}
+	scenario.Sample = sample
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if format == "k6" {
+		return loadtest.WriteK6(w, scenario)
+	}
+	return loadtest.WriteVegeta(w, scenario)
+}
+
+// envOr returns the named environment variable, or fallback if it is unset
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
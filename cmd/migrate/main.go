@@ -1,18 +1,29 @@
 package main
 
 import (
+	"compress/gzip"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
 
+	"bookstore-api/internal/backup"
 	"bookstore-api/internal/config"
 	"bookstore-api/internal/database"
+	"bookstore-api/internal/opsalerts"
+	"bookstore-api/internal/pii"
+	"bookstore-api/internal/services"
 )
 
 func main() {
 	var (
-		action = flag.String("action", "migrate", "Action to perform: migrate, status, rollback, validate")
+		action  = flag.String("action", "migrate", "Action to perform: migrate, status, rollback, validate, backup, restore, diff, rotate-keys, rebuild-stock, reconcile-stock")
+		file    = flag.String("file", "", "Catalog archive to write (backup) or read (restore, or the earlier archive for diff); gzipped if it ends in .gz")
+		against = flag.String("against", "", "Later catalog archive compared with -file (diff)")
 	)
 	flag.Parse()
 
@@ -25,6 +36,9 @@ func main() {
 	switch *action {
 	case "migrate":
 		if err := database.Migrate(cfg); err != nil {
+			if alertErr := opsalerts.NewAlerter(cfg).Send(context.Background(), opsalerts.MigrationFailed(err)); alertErr != nil {
+				log.Printf("Failed to post migration failure alert: %v", alertErr)
+			}
 			log.Fatalf("Migration failed: %v", err)
 		}
 		fmt.Println("Migrations completed successfully")
@@ -57,9 +71,199 @@ func main() {
 		}
 		fmt.Println("All migration files are valid")
 
+	case "backup":
+		if *file == "" {
+			log.Fatalf("Backup failed: -file is required")
+		}
+		summary, err := runBackup(cfg, *file)
+		if err != nil {
+			log.Fatalf("Backup failed: %v", err)
+		}
+		printSummary("Backed up", summary)
+
+	case "restore":
+		if *file == "" {
+			log.Fatalf("Restore failed: -file is required")
+		}
+		summary, err := runRestore(cfg, *file)
+		if err != nil {
+			log.Fatalf("Restore failed: %v", err)
+		}
+		printSummary("Restored", summary)
+		if summary.Detached > 0 {
+			fmt.Printf("Cleared %d references to user accounts missing from this database\n", summary.Detached)
+		}
+
+	case "diff":
+		if *file == "" || *against == "" {
+			log.Fatalf("Diff failed: -file and -against are required")
+		}
+		diff, err := runDiff(*file, *against)
+		if err != nil {
+			log.Fatalf("Diff failed: %v", err)
+		}
+		printDiff(diff)
+
+	case "rotate-keys":
+		rotated, err := runRotateKeys(cfg)
+		if err != nil {
+			log.Fatalf("Key rotation failed: %v", err)
+		}
+		fmt.Println("Re-encrypted personal data with the current key:")
+		for _, table := range []string{"users", "user_identities"} {
+			fmt.Printf("  - %s: %d rows\n", table, rotated[table])
+		}
+
+	case "rebuild-stock":
+		db, err := database.Connect(cfg)
+		if err != nil {
+			log.Fatalf("Stock rebuild failed: %v", err)
+		}
+		rebuilt, err := services.RebuildStock(db)
+		if err != nil {
+			log.Fatalf("Stock rebuild failed: %v", err)
+		}
+		fmt.Printf("Rebuilt stock from the movement log; corrected %d books\n", rebuilt)
+
+	case "reconcile-stock":
+		db, err := database.Connect(cfg)
+		if err != nil {
+			log.Fatalf("Stock reconciliation failed: %v", err)
+		}
+		reconciled, err := services.ReconcileStock(db)
+		if err != nil {
+			log.Fatalf("Stock reconciliation failed: %v", err)
+		}
+		fmt.Printf("Recorded %d reconciliation movements\n", reconciled)
+
 	default:
 		fmt.Printf("Unknown action: %s\n", *action)
-		fmt.Println("Available actions: migrate, status, rollback, validate")
+		fmt.Println("Available actions: migrate, status, rollback, validate, backup, restore, diff, rotate-keys, rebuild-stock, reconcile-stock")
 		os.Exit(1)
 	}
 }
+
+// runBackup dumps the catalog to the archive at path
+func runBackup(cfg *config.Config, path string) (*backup.Summary, error) {
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	summary, err := backup.Dump(db, w)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return summary, nil
+}
+
+// runRestore restores the archive at path into the database
+func runRestore(cfg *config.Config, path string) (*backup.Summary, error) {
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return backup.Restore(db, r)
+}
+
+// runDiff compares the books of the archives at two paths
+func runDiff(beforePath, afterPath string) (*backup.CatalogDiff, error) {
+	before, err := os.Open(beforePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", beforePath, err)
+	}
+	defer before.Close()
+
+	after, err := os.Open(afterPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", afterPath, err)
+	}
+	defer after.Close()
+
+	return backup.Diff(before, after)
+}
+
+// runRotateKeys re-encrypts personal data with the first configured key
+func runRotateKeys(cfg *config.Config) (map[string]int64, error) {
+	keyring, err := pii.NewKeyring(cfg.PII)
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return pii.Rotate(db, keyring)
+}
+
+// printSummary prints the rows backed up or restored per table
+func printSummary(verb string, summary *backup.Summary) {
+	fmt.Printf("%s catalog at schema version %s:\n", verb, summary.SchemaVersion)
+	for _, name := range backup.Tables() {
+		fmt.Printf("  - %s: %d rows\n", name, summary.Tables[name])
+	}
+}
+
+// printDiff prints the titles added, removed, and changed between two
+// archives, with the fields that changed
+func printDiff(diff *backup.CatalogDiff) {
+	fmt.Printf("Compared %d books (schema %s) with %d books (schema %s): %d added, %d removed, %d changed, %d unchanged\n",
+		diff.Before.Books, diff.Before.SchemaVersion, diff.After.Books, diff.After.SchemaVersion,
+		len(diff.Added), len(diff.Removed), len(diff.Changed), diff.Unchanged)
+	for _, book := range diff.Added {
+		fmt.Printf("+ %s %s %q\n", book.Tenant, book.ISBN, book.Title)
+	}
+	for _, book := range diff.Removed {
+		fmt.Printf("- %s %s %q\n", book.Tenant, book.ISBN, book.Title)
+	}
+	for _, book := range diff.Changed {
+		fmt.Printf("~ %s %s %q\n", book.Tenant, book.ISBN, book.Title)
+		fields := make([]string, 0, len(book.Changes))
+		for field := range book.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			change := book.Changes[field]
+			fmt.Printf("    %s: %v -> %v\n", field, change.From, change.To)
+		}
+	}
+}
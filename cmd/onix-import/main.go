@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/database"
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/timestamps"
+)
+
+func main() {
+	var (
+		file   = flag.String("file", "", "Path to the ONIX 3.0 XML file to import")
+		dryRun = flag.Bool("dry-run", false, "Validate the feed without writing to the database")
+		tenant = flag.String("tenant", "", "Slug or ID of the tenant to import into (default: TENANT_DEFAULT)")
+	)
+	flag.Parse()
+
+	if *file == "" {
+		fmt.Println("Usage: onix-import -file=<feed.xml> [-tenant=<slug>] [-dry-run]")
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := ids.Initialize(cfg); err != nil {
+		log.Fatalf("Failed to initialize ID generation: %v", err)
+	}
+	if err := timestamps.Initialize(cfg); err != nil {
+		log.Fatalf("Failed to initialize timestamps: %v", err)
+	}
+
+	if err := database.InitializeDB(cfg); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.CloseDB()
+	services.InitializeCountCache(cfg)
+	tenancy.InitializeResolver(database.GetDB(), cfg)
+
+	target, err := tenancy.GetResolver().Resolve(*tenant, "")
+	if err != nil {
+		log.Fatalf("Failed to resolve tenant: %v", err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open ONIX file: %v", err)
+	}
+	defer f.Close()
+
+	report, importErr := services.NewONIXService(database.GetDB(), cfg.ONIX).WithContext(tenancy.WithTenant(context.Background(), target)).Import(f, services.ONIXImportOptions{
+		DryRun:    *dryRun,
+		BatchSize: cfg.Import.BatchSize,
+	})
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode report: %v", err)
+	}
+	fmt.Println(string(output))
+
+	if importErr != nil {
+		log.Fatalf("ONIX import failed: %v", importErr)
+	}
+	if report.Failed > 0 {
+		os.Exit(2)
+	}
+}
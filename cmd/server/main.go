@@ -1,15 +1,46 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"bookstore-api/internal/alerts"
+	"bookstore-api/internal/analytics"
+	"bookstore-api/internal/auth"
 	"bookstore-api/internal/config"
+	"bookstore-api/internal/dashboard"
 	"bookstore-api/internal/database"
+	"bookstore-api/internal/events"
 	"bookstore-api/internal/grpc"
+	"bookstore-api/internal/handlers"
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/listener"
+	"bookstore-api/internal/maintenance"
+	"bookstore-api/internal/marketing"
+	"bookstore-api/internal/metadata"
+	"bookstore-api/internal/notifications"
+	"bookstore-api/internal/operations"
+	"bookstore-api/internal/opsalerts"
+	"bookstore-api/internal/pii"
+	"bookstore-api/internal/readmodel"
+	"bookstore-api/internal/reports"
+	"bookstore-api/internal/scheduler"
 	"bookstore-api/internal/server"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/storage"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/timestamps"
+	"bookstore-api/internal/utils"
+	"bookstore-api/internal/utils/signing"
+	"bookstore-api/internal/webhooks"
+
+	"golang.org/x/sync/errgroup"
 )
 
 func main() {
@@ -19,9 +50,35 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Log at the configured level until an admin changes it
+	logLevel, err := utils.ParseLogLevel(cfg.Logging.Level)
+	if err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+	utils.SetLogLevel(logLevel)
+
+	// Keep every timestamp in UTC
+	if err := timestamps.Initialize(cfg); err != nil {
+		log.Fatalf("Failed to initialize timestamps: %v", err)
+	}
+
+	// Encrypt personal data before anything reads or writes it
+	if err := pii.InitializeKeyring(cfg); err != nil {
+		log.Fatalf("Failed to initialize PII encryption: %v", err)
+	}
+
+	// Generate time-ordered primary keys unless configured otherwise
+	if err := ids.Initialize(cfg); err != nil {
+		log.Fatalf("Failed to initialize ID generation: %v", err)
+	}
+
 	log.Printf("Starting Bookstore API server on port %s", cfg.Server.Port)
 	log.Printf("Database: %s", cfg.Database.Host)
 
+	// Post operational alerts to Slack and Teams when configured
+	opsalerts.Initialize(cfg)
+	opsAlerter := opsalerts.GetAlerter()
+
 	// Initialize database connection using singleton pattern
 	if err := database.InitializeDB(cfg); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -34,6 +91,9 @@ func main() {
 
 	// Run database migrations
 	if err := database.Migrate(cfg); err != nil {
+		if alertErr := opsAlerter.Send(context.Background(), opsalerts.MigrationFailed(err)); alertErr != nil {
+			log.Printf("Failed to post migration failure alert: %v", alertErr)
+		}
 		log.Fatalf("Failed to run database migrations: %v", err)
 	}
 
@@ -50,45 +110,281 @@ func main() {
 
 	log.Printf("Database connection established successfully")
 
-	// Initialize servers
-	httpServer := server.NewHTTPServer(cfg)
-	httpServer.SetupRoutes()
+	// Detect a lost database connection and recover it without a restart
+	database.InitializeSupervisor(cfg)
+	dbSupervisor := database.GetSupervisor()
+	dbSupervisor.OnStateChange(opsAlerter.DatabaseStateChanged)
+
+	// Alert when the share of requests failing with server errors spikes
+	opsalerts.InitializeErrorRateMonitor(cfg)
+	errorRateMonitor := opsalerts.GetErrorRateMonitor()
+
+	// Initialize tenant resolution
+	db := database.GetDB()
+	tenancy.InitializeResolver(db, cfg)
+
+	// Configure pagination count caching
+	services.InitializeCountCache(cfg)
+	log.Printf("Pagination count mode: %s", cfg.Pagination.CountMode)
+
+	// Configure how stock changes are kept
+	if err := services.InitializeInventory(cfg); err != nil {
+		log.Fatalf("Failed to configure inventory: %v", err)
+	}
+	log.Printf("Inventory mode: %s", cfg.Inventory.Mode)
+
+	// Access tokens for social login
+	if err := auth.InitializeIssuer(cfg); err != nil {
+		log.Fatalf("Failed to initialize token issuer: %v", err)
+	}
+	services.InitializeRevocationCache(cfg)
+	auth.InitializeLoginGuard(cfg)
 
-	grpcServer := grpc.NewGRPCServer()
+	// Build the services on the database connection, for the background
+	// work, handlers, and servers to share
+	mediaStorage := storage.NewLocal(cfg.Storage.Dir, cfg.Storage.URLPrefix)
+	appServices := services.New(db, cfg, mediaStorage)
 
-	// Setup graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	// Initialize background job queue
+	jobs.InitializeQueue(db, cfg)
+	jobQueue := jobs.GetQueue()
 
-	go func() {
-		<-c
-		log.Println("Gracefully shutting down...")
-		if err := httpServer.Shutdown(); err != nil {
-			log.Printf("Error shutting down HTTP server: %v", err)
+	// Initialize domain event dispatcher
+	events.InitializeDispatcher(db, cfg)
+	eventDispatcher := events.GetDispatcher()
+	eventDispatcher.Subscribe(events.AllEvents, events.ConsumerFunc(events.LogEvent))
+
+	// Publish domain events to the configured message broker
+	publisher, err := events.NewPublisher(cfg.Broker)
+	if err != nil {
+		log.Fatalf("Failed to configure message broker: %v", err)
+	}
+	if publisher != nil {
+		eventDispatcher.Subscribe(events.AllEvents, events.NewBrokerConsumer(publisher, cfg.Broker.TopicPrefix))
+		log.Printf("Publishing domain events to %s", cfg.Broker.Type)
+	}
+
+	// Deliver domain events to registered webhooks
+	jobQueue.Register(webhooks.JobType, webhooks.NewDeliverer(appServices, cfg.Webhooks.Timeout))
+	eventDispatcher.Subscribe(events.AllEvents, webhooks.NewConsumer(jobQueue, appServices))
+
+	// Generate and email scheduled reports
+	jobQueue.Register(reports.JobType, reports.NewGenerator(cfg, appServices))
+	if cfg.Mail.SMTPHost == "" {
+		log.Printf("SMTP is not configured; scheduled reports will fail until SMTP_HOST is set")
+	}
+
+	// Notify users about saved search matches and books back in stock, and
+	// admins about low stock, over the channels each chose
+	notifications.InitializeDispatcher(cfg, appServices)
+	notificationDispatcher := notifications.GetDispatcher()
+	jobQueue.Register(notifications.JobType, notifications.NewDeliverer(notificationDispatcher, appServices))
+	jobQueue.Register(alerts.JobType, alerts.NewNotifier(notificationDispatcher))
+	eventDispatcher.Subscribe(events.BookStockChanged, notifications.NewLowStockConsumer(notificationDispatcher, appServices, cfg.Scheduler.LowStockThreshold))
+
+	// Send marketing email only to users who consent to it
+	jobQueue.Register(marketing.JobType, marketing.NewSender(cfg, appServices))
+
+	// Record book views in batches off the request path
+	analytics.InitializeTracker(db, cfg)
+	viewTracker := analytics.GetTracker()
+
+	// Stream stock and price changes to Server-Sent Events clients
+	events.InitializeStream(db, cfg)
+	eventStream := events.GetStream()
+	for _, eventType := range events.StreamEvents {
+		eventDispatcher.Subscribe(eventType, eventStream)
+	}
+
+	// Push metrics and low stock alerts to admin dashboards
+	dashboard.InitializeHub(db, cfg)
+	dashboardHub := dashboard.GetHub()
+	eventDispatcher.Subscribe(events.BookStockChanged, dashboardHub)
+
+	// Keep the storefront read models up to date
+	if cfg.ReadModels.Enabled {
+		projector := readmodel.NewProjector(appServices.Listings)
+		for _, eventType := range readmodel.EventTypes {
+			eventDispatcher.Subscribe(eventType, projector)
 		}
-		// gRPC server will be stopped when the process exits
-		if err := database.CloseDB(); err != nil {
-			log.Printf("Error closing database: %v", err)
+	}
+
+	// Configure external book metadata providers
+	if err := metadata.InitializeLookup(cfg); err != nil {
+		log.Fatalf("Failed to configure metadata providers: %v", err)
+	}
+
+	// Rebuild search indexes and flush caches in the background for admins
+	jobQueue.Register(maintenance.ReindexJobType, maintenance.NewReindexer(jobQueue, appServices))
+	jobQueue.Register(maintenance.CacheFlushJobType, maintenance.NewCacheFlusher(jobQueue, metadata.GetLookup()))
+
+	// Run large book imports and exports as operations clients poll
+	operations.InitializeManager(cfg)
+	operationManager := operations.GetManager()
+	jobQueue.Register(operations.ExportJobType, operations.NewExporter(operationManager, appServices))
+	jobQueue.Register(operations.ImportJobType, operations.NewImporter(operationManager, appServices))
+
+	// Signed links to private assets such as report downloads
+	if err := signing.InitializeSigner(cfg); err != nil {
+		log.Fatalf("Failed to initialize URL signer: %v", err)
+	}
+
+	// Initialize scheduler and register recurring tasks
+	scheduler.InitializeScheduler()
+	taskScheduler := scheduler.GetScheduler()
+	if err := scheduler.RegisterDefaultTasks(taskScheduler, cfg, db, appServices); err != nil {
+		log.Fatalf("Failed to register scheduled tasks: %v", err)
+	}
+
+	// Build the handlers on the services, and the servers on both
+	appHandlers := handlers.New(cfg, appServices)
+
+	// Initialize servers
+	grpcServer := grpc.NewGRPCServer(appServices)
+
+	httpServer := server.NewHTTPServer(cfg, appServices, appHandlers)
+	if cfg.GRPC.Web {
+		httpServer.SetGRPCWebHandler(grpcServer.WebHandler(cfg))
+	}
+	httpServer.SetupRoutes()
+
+	// Stop on SIGINT or SIGTERM, or when either server fails
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	servers, serversCtx := errgroup.WithContext(ctx)
+
+	// Open the listeners, inheriting them if a previous process handed
+	// them over
+	httpListener, err := listener.Listen("http", cfg.Server.Host+":"+cfg.Server.Port, cfg.Server.ReusePort)
+	if err != nil {
+		log.Fatalf("Failed to listen for HTTP: %v", err)
+	}
+	var grpcListener net.Listener
+	var mux *listener.Mux
+	if cfg.GRPC.SharedPort {
+		// Serve gRPC on the HTTP port, telling the connections apart
+		mux = listener.NewMux(httpListener)
+		httpListener, grpcListener = mux.HTTP(), mux.GRPC()
+	} else {
+		grpcListener, err = listener.Listen("grpc", cfg.GRPC.Host+":"+cfg.GRPC.Port, cfg.Server.ReusePort)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
 		}
-		os.Exit(0)
-	}()
+	}
 
 	log.Println("Starting servers...")
 
-	// Start HTTP server in goroutine
-	go func() {
-		if err := httpServer.Start(); err != nil {
-			log.Fatalf("Failed to start HTTP server: %v", err)
+	// Start supervising the database connection
+	dbSupervisor.Start()
+
+	// Start checking error rates
+	errorRateMonitor.Start()
+
+	// Start background job workers
+	jobQueue.Start()
+
+	// Start event dispatcher
+	eventDispatcher.Start()
+
+	// Start dashboard hub
+	dashboardHub.Start()
+
+	// Start view tracker
+	viewTracker.Start()
+
+	// Start scheduler
+	if cfg.Scheduler.Enabled {
+		taskScheduler.Start()
+	}
+
+	servers.Go(func() error {
+		if err := httpServer.Serve(httpListener); err != nil {
+			return fmt.Errorf("HTTP server failed: %w", err)
+		}
+		return nil
+	})
+
+	if mux != nil {
+		servers.Go(func() error {
+			if err := mux.Serve(); err != nil {
+				return fmt.Errorf("shared listener failed: %w", err)
+			}
+			return nil
+		})
+	}
+
+	log.Printf("Starting gRPC server on %s", grpcListener.Addr())
+	servers.Go(func() error {
+		if err := grpcServer.Serve(cfg, grpcListener); err != nil {
+			return fmt.Errorf("gRPC server failed: %w", err)
 		}
-	}()
+		return nil
+	})
+
+	// Let the process that handed its listeners over, if any, drain and exit
+	if err := listener.NotifyParent(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Hand the listeners to a new copy of the binary on SIGUSR2. This
+	// process shuts down once the new one is serving.
+	if len(listener.HandoverSignals) > 0 {
+		handover := make(chan os.Signal, 1)
+		signal.Notify(handover, listener.HandoverSignals...)
+		go func() {
+			for range handover {
+				process, err := listener.Handover()
+				if err != nil {
+					log.Printf("Failed to hand over listeners: %v", err)
+					continue
+				}
+				log.Printf("Handed listeners over to process %d; shutting down once it is serving", process.Pid)
+			}
+		}()
+	}
+
+	// Drain both servers once shutdown begins
+	servers.Go(func() error {
+		<-serversCtx.Done()
+		log.Printf("Gracefully shutting down (timeout %s)...", cfg.Server.ShutdownTimeout)
 
-	// Start gRPC server in goroutine
-	go func() {
-		if err := grpcServer.Start(cfg); err != nil {
-			log.Fatalf("Failed to start gRPC server: %v", err)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+
+		// Close long-lived streams first so they do not hold the drain open
+		eventStream.Close()
+		dashboardHub.Stop()
+
+		var drain errgroup.Group
+		drain.Go(func() error { return httpServer.Shutdown(shutdownCtx) })
+		drain.Go(func() error { return grpcServer.Shutdown(shutdownCtx) })
+		if err := drain.Wait(); err != nil {
+			return fmt.Errorf("failed to drain in-flight requests: %w", err)
 		}
-	}()
+		return nil
+	})
+
+	err = servers.Wait()
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
 
-	// Keep the main goroutine alive
-	select {}
+	// The servers have stopped, so nothing enqueues new work
+	taskScheduler.Stop()
+	viewTracker.Stop()
+	eventDispatcher.Stop()
+	if publisher != nil {
+		publisher.Close()
+	}
+	jobQueue.Stop()
+	errorRateMonitor.Stop()
+	dbSupervisor.Stop()
+	if closeErr := database.CloseDB(); closeErr != nil {
+		log.Printf("Error closing database: %v", closeErr)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+	log.Println("Shutdown complete")
 }
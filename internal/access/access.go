@@ -0,0 +1,109 @@
+package access
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	unrestrictedKey
+)
+
+// Principal is the authenticated caller a request acts for
+type Principal struct {
+	// UserID is the signed-in user, or uuid.Nil for API keys, which do not
+	// belong to a user
+	UserID   uuid.UUID
+	Role     string
+	APIKeyID uuid.UUID
+}
+
+// WithPrincipal returns a context whose queries are restricted by the rules
+// registered for the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns the principal set by WithPrincipal, or nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// Unrestricted returns a context whose queries ignore the registered rules,
+// for checks that must see every row of the tenant, such as uniqueness
+func Unrestricted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unrestrictedKey, true)
+}
+
+// IsUnrestricted reports whether the context ignores the registered rules
+func IsUnrestricted(ctx context.Context) bool {
+	unrestricted, _ := ctx.Value(unrestrictedKey).(bool)
+	return unrestricted
+}
+
+// Rule returns the condition rows of a table must meet to be visible to the
+// principal, or nil to leave the principal unrestricted. Conditions should
+// qualify columns with clause.CurrentTable.
+type Rule func(p *Principal) clause.Expression
+
+var (
+	rulesMu sync.RWMutex
+	rules   = make(map[string][]Rule)
+)
+
+// Register adds a rule restricting the rows of table that principals may
+// read, update, and delete. Rules are registered at startup, typically in an
+// init function next to the service querying the table.
+func Register(table string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[table] = append(rules[table], rule)
+}
+
+// rulesFor returns the rules registered for table
+func rulesFor(table string) []Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return rules[table]
+}
+
+// Scope returns a key naming the rows of table visible in ctx, for caches of
+// results computed over the table, such as counts. It is empty when no rule
+// restricts the principal, so unrestricted callers share cached results.
+func Scope(ctx context.Context, table string) string {
+	p := FromContext(ctx)
+	if p == nil || IsUnrestricted(ctx) {
+		return ""
+	}
+	for _, rule := range rulesFor(table) {
+		if rule(p) != nil {
+			return p.Role + ":" + p.UserID.String()
+		}
+	}
+	return ""
+}
+
+// OwnedBy returns a rule restricting principals with one of the given roles
+// to rows whose column holds their user ID. Principals with other roles are
+// unrestricted; those with one of the roles but no user see no rows.
+func OwnedBy(column string, roles ...string) Rule {
+	return func(p *Principal) clause.Expression {
+		for _, role := range roles {
+			if p.Role != role {
+				continue
+			}
+			if p.UserID == uuid.Nil {
+				return clause.Expr{SQL: "1 = 0"}
+			}
+			return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: p.UserID}
+		}
+		return nil
+	}
+}
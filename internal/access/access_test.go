@@ -0,0 +1,79 @@
+package access
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// listing is a model for a table only these tests register rules for
+type listing struct {
+	ID       uuid.UUID
+	SellerID uuid.UUID
+}
+
+func (listing) TableName() string {
+	return "access_test_listings"
+}
+
+// dryRun returns a database that builds SQL without connecting, with the
+// plugin installed
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(Plugin{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOwnedBy(t *testing.T) {
+	Register("access_test_listings", OwnedBy("seller_id", "seller"))
+	db := dryRun(t)
+	seller := uuid.New()
+	const owned = `"access_test_listings"."seller_id" = $1`
+
+	for _, tt := range []struct {
+		name  string
+		ctx   context.Context
+		where string
+		scope string
+	}{
+		{"seller", WithPrincipal(context.Background(), &Principal{UserID: seller, Role: "seller"}), owned, "seller:" + seller.String()},
+		{"seller without a user", WithPrincipal(context.Background(), &Principal{Role: "seller"}), "1 = 0", "seller:" + uuid.Nil.String()},
+		{"other role", WithPrincipal(context.Background(), &Principal{UserID: seller, Role: "customer"}), "", ""},
+		{"no principal", context.Background(), "", ""},
+		{"unrestricted", Unrestricted(WithPrincipal(context.Background(), &Principal{UserID: seller, Role: "seller"})), "", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := db.WithContext(tt.ctx)
+			var listings []listing
+			var total int64
+			for _, stmt := range []*gorm.Statement{
+				tx.Find(&listings).Statement,
+				tx.Model(&listing{}).Count(&total).Statement,
+			} {
+				sql := stmt.SQL.String()
+				if tt.where == "" && strings.Contains(sql, "WHERE") {
+					t.Errorf("%s is restricted, expected no conditions", sql)
+				}
+				if tt.where != "" && !strings.Contains(sql, "WHERE "+tt.where) {
+					t.Errorf("%s is not restricted to %s", sql, tt.where)
+				}
+			}
+			if got := Scope(tt.ctx, "access_test_listings"); got != tt.scope {
+				t.Errorf("scope is %q, expected %q", got, tt.scope)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package access
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Plugin restricts queries, updates, and deletes on tables with registered
+// rules to the rows the principal in the statement context may see.
+// Statements whose context has no principal, such as background work and
+// anonymous requests, and those marked Unrestricted are not restricted. Raw
+// SQL and creates are not restricted.
+type Plugin struct{}
+
+// Name returns the plugin name
+func (Plugin) Name() string {
+	return "access"
+}
+
+// Initialize registers the plugin's callbacks
+func (p Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("access:query", p.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("access:update", p.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("access:delete", p.scope); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("access:row", p.scope)
+}
+
+// scope adds the conditions of the table's rules for the principal
+func (Plugin) scope(db *gorm.DB) {
+	if db.Statement.Schema == nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+	tableRules := rulesFor(db.Statement.Table)
+	if len(tableRules) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	p := FromContext(ctx)
+	if p == nil || IsUnrestricted(ctx) {
+		return
+	}
+
+	for _, rule := range tableRules {
+		if expr := rule(p); expr != nil {
+			db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{expr}})
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package adminui serves the admin single-page app.
+//
+// The app's build output in dist is embedded in the binary and served as
+// static files. The app holds no server-side logic: it signs requests with
+// an access token or API key entered by the administrator and calls the REST
+// API like any other client.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+//go:embed dist
+var dist embed.FS
+
+// Handler returns a handler serving the app, to be mounted with app.Use under
+// the path the app is served from
+func Handler() fiber.Handler {
+	root, err := fs.Sub(dist, "dist")
+	if err != nil {
+		// dist is embedded above, so this only fails if the directive changes
+		panic(err)
+	}
+
+	return filesystem.New(filesystem.Config{
+		Root:  http.FS(root),
+		Index: "index.html",
+	})
+}
@@ -0,0 +1,291 @@
+// Bookstore admin app. Every request goes through the REST API under
+// /api/v1, authenticated with the token stored for this browser session.
+(function () {
+  'use strict';
+
+  var API = '/api/v1';
+  var TOKEN_KEY = 'bookstore-admin-token';
+  var view = document.getElementById('view');
+  var status = document.getElementById('status');
+
+  // Editable resources: the fields shown in listings and in the edit form
+  var resources = {
+    books: {
+      title: 'Books',
+      columns: ['title', 'isbn', 'price', 'stock'],
+      fields: [
+        { name: 'title', label: 'Title', required: true },
+        { name: 'isbn', label: 'ISBN', required: true },
+        { name: 'price', label: 'Price', type: 'number', step: '0.01', required: true },
+        { name: 'stock', label: 'Stock', type: 'number', step: '1' },
+        { name: 'author_id', label: 'Author', options: 'authors', required: true },
+        { name: 'category_id', label: 'Category', options: 'categories', required: true },
+        { name: 'description', label: 'Description', type: 'textarea' }
+      ]
+    },
+    authors: {
+      title: 'Authors',
+      columns: ['name', 'email'],
+      fields: [
+        { name: 'name', label: 'Name', required: true },
+        { name: 'email', label: 'Email', type: 'email', required: true },
+        { name: 'biography', label: 'Biography', type: 'textarea' }
+      ]
+    },
+    categories: {
+      title: 'Categories',
+      columns: ['name', 'description'],
+      fields: [
+        { name: 'name', label: 'Name', required: true },
+        { name: 'description', label: 'Description', type: 'textarea' }
+      ]
+    }
+  };
+
+  function setStatus(message, isError) {
+    status.textContent = message || '';
+    status.className = isError ? 'error' : '';
+  }
+
+  function api(method, path, body) {
+    var headers = { 'Accept': 'application/json', 'X-Response-Format': 'envelope' };
+    var token = sessionStorage.getItem(TOKEN_KEY);
+    if (token) {
+      headers['Authorization'] = 'Bearer ' + token;
+    }
+    if (body !== undefined) {
+      headers['Content-Type'] = 'application/json';
+    }
+    return fetch(API + path, {
+      method: method,
+      headers: headers,
+      body: body === undefined ? undefined : JSON.stringify(body)
+    }).then(function (response) {
+      return response.json().catch(function () { return {}; }).then(function (payload) {
+        if (!response.ok || payload.error) {
+          var message = payload.message || response.statusText;
+          throw new Error(payload.details ? message + ': ' + payload.details : message);
+        }
+        return payload;
+      });
+    });
+  }
+
+  function el(tag, attrs, children) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key === 'text') {
+        node.textContent = attrs[key];
+      } else if (key.indexOf('on') === 0) {
+        node.addEventListener(key.slice(2), attrs[key]);
+      } else {
+        node.setAttribute(key, attrs[key]);
+      }
+    });
+    (children || []).forEach(function (child) { node.appendChild(child); });
+    return node;
+  }
+
+  function pager(pagination, go) {
+    var page = pagination.page;
+    var pages = Math.max(pagination.total_pages, 1);
+    var previous = el('button', { text: 'Previous', onclick: function () { go(page - 1); } });
+    var next = el('button', { text: 'Next', onclick: function () { go(page + 1); } });
+    previous.disabled = page <= 1;
+    next.disabled = page >= pages;
+    return el('div', { 'class': 'toolbar' }, [
+      previous,
+      el('span', { text: 'Page ' + page + ' of ' + pages + ' (' + pagination.total + ' total)' }),
+      next
+    ]);
+  }
+
+  function table(columns, rows, actions) {
+    var head = el('tr', {}, columns.map(function (c) { return el('th', { text: c }); }).concat([el('th')]));
+    var body = rows.map(function (row) {
+      var cells = columns.map(function (c) {
+        var value = row[c];
+        return el('td', { text: value === null || value === undefined ? '' : String(value) });
+      });
+      return el('tr', {}, cells.concat([el('td', {}, actions(row))]));
+    });
+    return el('table', {}, [el('thead', {}, [head]), el('tbody', {}, body)]);
+  }
+
+  function listResource(name, page) {
+    var resource = resources[name];
+    setStatus('Loading…');
+    api('GET', '/' + name + '?page=' + page + '&limit=20').then(function (payload) {
+      setStatus('');
+      view.replaceChildren(
+        el('div', { 'class': 'toolbar' }, [
+          el('h2', { text: resource.title }),
+          el('button', { text: 'New', onclick: function () { location.hash = '#/' + name + '/new'; } })
+        ]),
+        table(resource.columns, payload.data || [], function (row) {
+          return [
+            el('button', { text: 'Edit', onclick: function () { location.hash = '#/' + name + '/' + row.id; } }),
+            el('button', { text: 'Delete', onclick: function () { deleteResource(name, row, page); } })
+          ];
+        }),
+        pager(payload.pagination, function (p) { listResource(name, p); })
+      );
+    }).catch(function (err) { setStatus(err.message, true); });
+  }
+
+  function deleteResource(name, row, page) {
+    if (!confirm('Delete ' + (row.title || row.name) + '?')) {
+      return;
+    }
+    api('DELETE', '/' + name + '/' + row.id).then(function () {
+      setStatus('Deleted');
+      listResource(name, page);
+    }).catch(function (err) { setStatus(err.message, true); });
+  }
+
+  function loadOptions(name) {
+    return api('GET', '/' + name + '?limit=100').then(function (payload) {
+      return (payload.data || []).map(function (item) {
+        return { value: item.id, label: item.name };
+      });
+    });
+  }
+
+  function fieldInput(field, value, options) {
+    if (field.options) {
+      var select = el('select', { name: field.name }, [el('option', { value: '', text: '—' })].concat(
+        options[field.options].map(function (o) { return el('option', { value: o.value, text: o.label }); })));
+      select.value = value || '';
+      return select;
+    }
+    var input = field.type === 'textarea'
+      ? el('textarea', { name: field.name })
+      : el('input', { name: field.name, type: field.type || 'text' });
+    if (field.step) {
+      input.setAttribute('step', field.step);
+    }
+    input.value = value === null || value === undefined ? '' : value;
+    return input;
+  }
+
+  function editResource(name, id) {
+    var resource = resources[name];
+    var isNew = id === 'new';
+    var lookups = resource.fields.filter(function (f) { return f.options; }).map(function (f) { return f.options; });
+    setStatus('Loading…');
+
+    Promise.all([
+      isNew ? Promise.resolve({ data: {} }) : api('GET', '/' + name + '/' + id),
+      Promise.all(lookups.map(loadOptions))
+    ]).then(function (results) {
+      var item = results[0].data;
+      var options = {};
+      lookups.forEach(function (lookup, i) { options[lookup] = results[1][i]; });
+      setStatus('');
+
+      var form = el('form', { 'class': 'edit' });
+      resource.fields.forEach(function (field) {
+        form.appendChild(el('label', { text: field.label, 'for': field.name }));
+        var input = fieldInput(field, item[field.name], options);
+        input.id = field.name;
+        input.required = !!field.required && isNew;
+        form.appendChild(input);
+      });
+      form.appendChild(el('span'));
+      form.appendChild(el('button', { type: 'submit', text: isNew ? 'Create' : 'Save' }));
+      form.addEventListener('submit', function (event) {
+        event.preventDefault();
+        saveResource(name, isNew ? null : id, resource, form, item);
+      });
+
+      view.replaceChildren(el('h2', { text: (isNew ? 'New ' : 'Edit ') + resource.title.toLowerCase() }), form);
+    }).catch(function (err) { setStatus(err.message, true); });
+  }
+
+  function saveResource(name, id, resource, form, original) {
+    var body = {};
+    resource.fields.forEach(function (field) {
+      var raw = form.elements[field.name].value;
+      var value = field.type === 'number' ? (raw === '' ? undefined : Number(raw)) : raw;
+      // Updates only send the fields that changed
+      if (value === undefined || (id && value === (original[field.name] === null ? '' : original[field.name]))) {
+        return;
+      }
+      body[field.name] = value;
+    });
+
+    api(id ? 'PUT' : 'POST', '/' + name + (id ? '/' + id : ''), body).then(function () {
+      setStatus(id ? 'Saved' : 'Created');
+      location.hash = '#/' + name;
+    }).catch(function (err) { setStatus(err.message, true); });
+  }
+
+  function listRentals(page, state) {
+    setStatus('Loading…');
+    var query = '?page=' + page + '&limit=20' + (state ? '&status=' + state : '');
+    api('GET', '/rentals' + query).then(function (payload) {
+      setStatus('');
+      var rows = (payload.data || []).map(function (rental) {
+        return {
+          id: rental.id,
+          book: rental.book && rental.book.title ? rental.book.title : rental.book_id,
+          user_id: rental.user_id,
+          starts_at: rental.starts_at,
+          due_at: rental.due_at,
+          returned_at: rental.returned_at,
+          late: rental.late
+        };
+      });
+      var filter = el('select', {}, ['', 'out', 'overdue', 'returned'].map(function (s) {
+        return el('option', { value: s, text: s || 'all' });
+      }));
+      filter.value = state || '';
+      filter.addEventListener('change', function () { listRentals(1, filter.value); });
+
+      view.replaceChildren(
+        el('div', { 'class': 'toolbar' }, [el('h2', { text: 'Orders' }), filter]),
+        table(['book', 'user_id', 'starts_at', 'due_at', 'returned_at', 'late'], rows, function () { return []; }),
+        pager(payload.pagination, function (p) { listRentals(p, state); })
+      );
+    }).catch(function (err) { setStatus(err.message, true); });
+  }
+
+  function route() {
+    var parts = location.hash.replace(/^#\/?/, '').split('/');
+    var name = parts[0] || 'books';
+    document.querySelectorAll('header nav a').forEach(function (a) {
+      a.classList.toggle('active', a.getAttribute('href') === '#/' + name);
+    });
+
+    if (!sessionStorage.getItem(TOKEN_KEY)) {
+      view.replaceChildren();
+      setStatus('Sign in with an administrator access token or API key.');
+      return;
+    }
+    if (name === 'rentals') {
+      listRentals(1, '');
+    } else if (resources[name] && parts[1]) {
+      editResource(name, parts[1]);
+    } else if (resources[name]) {
+      listResource(name, 1);
+    } else {
+      setStatus('Unknown page', true);
+    }
+  }
+
+  document.getElementById('token-form').addEventListener('submit', function (event) {
+    event.preventDefault();
+    var input = document.getElementById('token');
+    if (input.value) {
+      sessionStorage.setItem(TOKEN_KEY, input.value);
+      input.value = '';
+    }
+    route();
+  });
+  document.getElementById('sign-out').addEventListener('click', function () {
+    sessionStorage.removeItem(TOKEN_KEY);
+    route();
+  });
+  window.addEventListener('hashchange', route);
+  route();
+})();
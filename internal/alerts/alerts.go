@@ -0,0 +1,95 @@
+package alerts
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/notifications"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// JobType is the background job type that notifies a user of an alert
+const JobType = "alert.notify"
+
+// Payload is the payload of alert notification jobs. The message is rendered
+// when the alert fires, so it describes the books as they were then.
+type Payload struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Topic    string    `json:"topic"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+}
+
+// BackInStock returns the notification for a stock alert whose book is back in stock
+func BackInStock(alert *models.StockAlert) Payload {
+	return Payload{
+		TenantID: alert.TenantID,
+		UserID:   alert.UserID,
+		Topic:    models.TopicBackInStock,
+		Subject:  fmt.Sprintf("Back in stock: %s", alert.Book.Title),
+		Body: fmt.Sprintf("%q (ISBN %s) is back in stock.\r\n\r\nYou asked to be told when it returned; this alert will not fire again unless you subscribe again.\r\n",
+			alert.Book.Title, alert.Book.ISBN),
+	}
+}
+
+// NewMatches returns the notification for a saved search matched by newly added books
+func NewMatches(search *models.SavedSearch, books []models.Book) Payload {
+	var body strings.Builder
+	fmt.Fprintf(&body, "New books match your saved search %q:\r\n\r\n", search.Name)
+	for _, book := range books {
+		fmt.Fprintf(&body, "- %s (ISBN %s)\r\n", book.Title, book.ISBN)
+	}
+	if len(books) == services.MaxAlertMatches {
+		fmt.Fprintf(&body, "\r\nMore books may match; search for %q to see them all.\r\n", search.Query)
+	}
+	return Payload{
+		TenantID: search.TenantID,
+		UserID:   search.UserID,
+		Topic:    models.TopicSavedSearch,
+		Subject:  fmt.Sprintf("New matches for %q", search.Name),
+		Body:     body.String(),
+	}
+}
+
+// Notifier is the job handler that sends alert notifications to users over
+// the channels they chose for the alert's topic
+type Notifier struct {
+	dispatcher *notifications.Dispatcher
+}
+
+// NewNotifier creates a new alert notifier sending through dispatcher
+func NewNotifier(dispatcher *notifications.Dispatcher) *Notifier {
+	return &Notifier{dispatcher: dispatcher}
+}
+
+// Handle schedules delivery of the notification in the job to its user
+func (n *Notifier) Handle(ctx context.Context, job *models.Job) error {
+	var payload Payload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid alert job payload: %w", err)
+	}
+	// Alerts queued before they recorded a topic follow the back in stock
+	// settings; both topics default to email
+	if payload.Topic == "" {
+		payload.Topic = models.TopicBackInStock
+	}
+
+	tenant, err := tenancy.GetResolver().Get(payload.TenantID)
+	if err != nil {
+		if errors.Is(err, tenancy.ErrUnknownTenant) {
+			return nil
+		}
+		return err
+	}
+	return n.dispatcher.Notify(tenancy.WithTenant(ctx, tenant), payload.UserID, notifications.Notification{
+		Topic:   payload.Topic,
+		Subject: payload.Subject,
+		Body:    payload.Body,
+	})
+}
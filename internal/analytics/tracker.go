@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	tracker *Tracker
+	once    sync.Once
+)
+
+// Tracker records book views without slowing the requests that make them.
+// Views are buffered in memory and written in batches; when the buffer is
+// full, views are dropped rather than blocking, and views still buffered when
+// the process dies are lost.
+type Tracker struct {
+	db      *gorm.DB
+	cfg     config.AnalyticsConfig
+	views   chan models.BookView
+	dropped atomic.Int64
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewTracker creates a new view tracker writing to db
+func NewTracker(db *gorm.DB, cfg config.AnalyticsConfig) *Tracker {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.BufferSize < cfg.BatchSize {
+		cfg.BufferSize = cfg.BatchSize
+	}
+
+	return &Tracker{
+		db:    db,
+		cfg:   cfg,
+		views: make(chan models.BookView, cfg.BufferSize),
+	}
+}
+
+// InitializeTracker initializes the shared view tracker on db
+func InitializeTracker(db *gorm.DB, cfg *config.Config) {
+	once.Do(func() {
+		tracker = NewTracker(tenancy.System(db), cfg.Analytics)
+	})
+}
+
+// GetTracker returns the shared view tracker
+func GetTracker() *Tracker {
+	if tracker == nil {
+		log.Fatal("View tracker not initialized. Call InitializeTracker first.")
+	}
+	return tracker
+}
+
+// RecordView queues a view of the book by the user, nil for anonymous
+// viewers, in the tenant
+func (t *Tracker) RecordView(tenantID, bookID uuid.UUID, userID *uuid.UUID, viewedAt time.Time) {
+	select {
+	case t.views <- models.BookView{TenantID: tenantID, BookID: bookID, UserID: userID, ViewedAt: viewedAt}:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// Start begins writing buffered views
+func (t *Tracker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go t.loop(ctx)
+	log.Println("Started view tracker")
+}
+
+// Stop stops the tracker after writing the views still buffered
+func (t *Tracker) Stop() {
+	if t.cancel == nil {
+		return
+	}
+	log.Println("Stopping view tracker...")
+	t.cancel()
+	t.wg.Wait()
+}
+
+// loop writes a batch whenever it fills up or the flush interval passes
+func (t *Tracker) loop(ctx context.Context) {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.BookView, 0, t.cfg.BatchSize)
+	for {
+		select {
+		case view := <-t.views:
+			batch = append(batch, view)
+			if len(batch) >= t.cfg.BatchSize {
+				batch = t.flush(batch)
+			}
+		case <-ticker.C:
+			batch = t.flush(batch)
+		case <-ctx.Done():
+			for {
+				select {
+				case view := <-t.views:
+					batch = append(batch, view)
+				default:
+					t.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes the batch and returns it emptied. Failed batches are logged
+// and dropped: views are analytics, not worth holding up later ones for.
+func (t *Tracker) flush(batch []models.BookView) []models.BookView {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := t.db.Omit(clause.Associations).CreateInBatches(batch, t.cfg.BatchSize).Error; err != nil {
+		log.Printf("Failed to record %d book views: %v", len(batch), err)
+	}
+	if dropped := t.dropped.Swap(0); dropped > 0 {
+		log.Printf("Dropped %d book views because the view buffer was full", dropped)
+	}
+	return batch[:0]
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key so it can be told apart from access tokens
+const APIKeyPrefix = "bsk_"
+
+// NewAPIKey returns a random API key and the hash to store for it
+func NewAPIKey() (string, string, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := APIKeyPrefix + secret
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of an API key
+func HashAPIKey(key string) string {
+	return HashRefreshToken(key)
+}
+
+// LooksLikeAPIKey reports whether s has the shape of an API key
+func LooksLikeAPIKey(s string) bool {
+	return strings.HasPrefix(s, APIKeyPrefix)
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrCaptchaFailed is returned for CAPTCHA responses the verifier rejects
+var ErrCaptchaFailed = errors.New("captcha verification failed")
+
+// CaptchaVerifier checks a CAPTCHA response solved by the client
+type CaptchaVerifier interface {
+	// Verify returns ErrCaptchaFailed when the response is missing, wrong, or
+	// already used, and another error when the check itself failed
+	Verify(ctx context.Context, response, remoteIP string) error
+}
+
+// NewCaptchaVerifier returns a verifier for the configured siteverify
+// endpoint, or nil when none is configured
+func NewCaptchaVerifier(cfg config.LoginConfig) CaptchaVerifier {
+	if cfg.CaptchaVerifyURL == "" {
+		return nil
+	}
+	return &siteVerifier{
+		url:    cfg.CaptchaVerifyURL,
+		secret: cfg.CaptchaSecret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// siteVerifier checks responses with the siteverify API shared by reCAPTCHA,
+// hCaptcha, and Turnstile
+type siteVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// Verify posts the response to the siteverify endpoint
+func (v *siteVerifier) Verify(ctx context.Context, response, remoteIP string) error {
+	if response == "" {
+		return ErrCaptchaFailed
+	}
+
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {response},
+		"remoteip": {remoteIP},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build captcha request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to verify captcha: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to verify captcha: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode captcha response: %w", err)
+	}
+	if !result.Success {
+		return ErrCaptchaFailed
+	}
+	return nil
+}
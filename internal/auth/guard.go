@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"bookstore-api/internal/config"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxGuardEntries bounds the number of IPs and accounts with remembered failures
+const maxGuardEntries = 100000
+
+var (
+	loginGuard     *LoginGuard
+	loginGuardOnce sync.Once
+)
+
+// failureEntry holds the recent failures of one IP or account
+type failureEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginGuard counts failed logins and token refreshes per client IP and per
+// account, asking for a CAPTCHA and then locking out for progressively longer
+// as failures accumulate. Counters are held in memory, so each instance keeps
+// its own, as with rate limits.
+type LoginGuard struct {
+	captchaAfter int
+	lockoutAfter int
+	lockoutBase  time.Duration
+	lockoutMax   time.Duration
+	window       time.Duration
+	captcha      CaptchaVerifier
+
+	mu      sync.Mutex
+	entries map[string]*failureEntry
+}
+
+// NewLoginGuard creates a login guard
+func NewLoginGuard(cfg config.LoginConfig) *LoginGuard {
+	base := cfg.LockoutBase
+	if base <= 0 {
+		base = time.Minute
+	}
+	max := cfg.LockoutMax
+	if max < base {
+		max = base
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	return &LoginGuard{
+		captchaAfter: cfg.CaptchaAfter,
+		lockoutAfter: cfg.LockoutAfter,
+		lockoutBase:  base,
+		lockoutMax:   max,
+		window:       window,
+		captcha:      NewCaptchaVerifier(cfg),
+		entries:      make(map[string]*failureEntry),
+	}
+}
+
+// InitializeLoginGuard initializes the shared login guard from configuration
+func InitializeLoginGuard(cfg *config.Config) {
+	loginGuardOnce.Do(func() {
+		loginGuard = NewLoginGuard(cfg.Login)
+	})
+}
+
+// GetLoginGuard returns the shared login guard
+func GetLoginGuard() *LoginGuard {
+	loginGuardOnce.Do(func() {
+		loginGuard = NewLoginGuard(config.LoginConfig{})
+	})
+	return loginGuard
+}
+
+// IPKey returns the key failures from a client IP are counted under
+func IPKey(tenantID uuid.UUID, ip string) string {
+	return "ip:" + tenantID.String() + "|" + ip
+}
+
+// AccountKey returns the key failures for a user's account are counted under
+func AccountKey(tenantID, userID uuid.UUID) string {
+	return "account:" + tenantID.String() + "|" + userID.String()
+}
+
+// Captcha returns the CAPTCHA verifier, or nil when none is configured
+func (g *LoginGuard) Captcha() CaptchaVerifier {
+	return g.captcha
+}
+
+// LockedOut returns how long the key remains locked out, or zero
+func (g *LoginGuard) LockedOut(key string) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry := g.entry(key, time.Now())
+	if entry == nil {
+		return 0
+	}
+	if remaining := time.Until(entry.lockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// CaptchaRequired reports whether attempts under the key must carry a solved
+// CAPTCHA. It is never required when no verifier is configured.
+func (g *LoginGuard) CaptchaRequired(key string) bool {
+	if g.captcha == nil || g.captchaAfter <= 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry := g.entry(key, time.Now())
+	return entry != nil && entry.failures >= g.captchaAfter
+}
+
+// Fail records a failure under the key and returns the number of recent
+// failures and, when this failure locked the key out, for how long
+func (g *LoginGuard) Fail(key string) (int, time.Duration) {
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry := g.entry(key, now)
+	if entry == nil {
+		g.prune(now)
+		entry = &failureEntry{}
+		g.entries[key] = entry
+	}
+	entry.failures++
+	entry.lastFailure = now
+
+	if g.lockoutAfter <= 0 || entry.failures < g.lockoutAfter {
+		return entry.failures, 0
+	}
+	lockout := g.lockoutBase
+	for i := g.lockoutAfter; i < entry.failures && lockout < g.lockoutMax; i++ {
+		lockout *= 2
+	}
+	if lockout > g.lockoutMax {
+		lockout = g.lockoutMax
+	}
+	entry.lockedUntil = now.Add(lockout)
+	return entry.failures, lockout
+}
+
+// Failures returns the number of recent failures under the key
+func (g *LoginGuard) Failures(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if entry := g.entry(key, time.Now()); entry != nil {
+		return entry.failures
+	}
+	return 0
+}
+
+// Reset forgets the failures under the key, after a successful login
+func (g *LoginGuard) Reset(key string) {
+	g.mu.Lock()
+	delete(g.entries, key)
+	g.mu.Unlock()
+}
+
+// entry returns the key's entry, dropping it when its failures are older than
+// the window and it is not locked out. The caller holds the lock.
+func (g *LoginGuard) entry(key string, now time.Time) *failureEntry {
+	entry, ok := g.entries[key]
+	if !ok {
+		return nil
+	}
+	if g.expired(entry, now) {
+		delete(g.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// expired reports whether an entry no longer counts
+func (g *LoginGuard) expired(entry *failureEntry, now time.Time) bool {
+	return now.Sub(entry.lastFailure) > g.window && now.After(entry.lockedUntil)
+}
+
+// prune drops expired entries when the guard is full. The caller holds the lock.
+func (g *LoginGuard) prune(now time.Time) {
+	if len(g.entries) < maxGuardEntries {
+		return
+	}
+	for key, entry := range g.entries {
+		if g.expired(entry, now) {
+			delete(g.entries, key)
+		}
+	}
+	// Everything is still fresh; start over rather than grow without bound
+	if len(g.entries) >= maxGuardEntries {
+		g.entries = make(map[string]*failureEntry)
+	}
+}
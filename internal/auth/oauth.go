@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownProvider is returned for providers that are not configured
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+// Identity is a user's account at an external identity provider
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+}
+
+// Provider is an OAuth2 identity provider
+type Provider interface {
+	// Name returns the provider's identifier, as used in routes and stored identities
+	Name() string
+	// AuthCodeURL returns the URL the user is redirected to in order to sign in
+	AuthCodeURL(state, challenge, redirectURI string) string
+	// Exchange trades an authorization code for an access token
+	Exchange(ctx context.Context, code, verifier, redirectURI string) (string, error)
+	// Identity fetches the signed-in user's account details
+	Identity(ctx context.Context, accessToken string) (*Identity, error)
+}
+
+// NewProviders returns the identity providers with configured client credentials
+func NewProviders(cfg config.AuthConfig) map[string]Provider {
+	client := &http.Client{Timeout: 10 * time.Second}
+	providers := make(map[string]Provider)
+	if cfg.Google.ClientID != "" {
+		providers["google"] = &googleProvider{cfg: cfg.Google, client: client}
+	}
+	if cfg.GitHub.ClientID != "" {
+		providers["github"] = &githubProvider{cfg: cfg.GitHub, client: client}
+	}
+	return providers
+}
+
+// ProviderNames returns the sorted names of the given providers
+func ProviderNames(providers map[string]Provider) []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// googleProvider signs users in with Google using OpenID Connect
+type googleProvider struct {
+	cfg    config.OAuthProviderConfig
+	client *http.Client
+}
+
+func (p *googleProvider) Name() string { return "google" }
+
+func (p *googleProvider) AuthCodeURL(state, challenge, redirectURI string) string {
+	return "https://accounts.google.com/o/oauth2/v2/auth?" + url.Values{
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+		"prompt":                {"select_account"},
+	}.Encode()
+}
+
+func (p *googleProvider) Exchange(ctx context.Context, code, verifier, redirectURI string) (string, error) {
+	return exchangeCode(ctx, p.client, "https://oauth2.googleapis.com/token", url.Values{
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code":          {code},
+		"code_verifier": {verifier},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {redirectURI},
+	})
+}
+
+func (p *googleProvider) Identity(ctx context.Context, accessToken string) (*Identity, error) {
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := getJSON(ctx, p.client, "https://openidconnect.googleapis.com/v1/userinfo", accessToken, &info); err != nil {
+		return nil, fmt.Errorf("failed to fetch google user info: %w", err)
+	}
+	if info.Sub == "" {
+		return nil, fmt.Errorf("google user info has no subject")
+	}
+
+	return &Identity{
+		Subject:       info.Sub,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Name:          info.Name,
+		AvatarURL:     info.Picture,
+	}, nil
+}
+
+// githubProvider signs users in with GitHub OAuth apps
+type githubProvider struct {
+	cfg    config.OAuthProviderConfig
+	client *http.Client
+}
+
+func (p *githubProvider) Name() string { return "github" }
+
+func (p *githubProvider) AuthCodeURL(state, challenge, redirectURI string) string {
+	return "https://github.com/login/oauth/authorize?" + url.Values{
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"read:user user:email"},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code, verifier, redirectURI string) (string, error) {
+	return exchangeCode(ctx, p.client, "https://github.com/login/oauth/access_token", url.Values{
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code":          {code},
+		"code_verifier": {verifier},
+		"redirect_uri":  {redirectURI},
+	})
+}
+
+func (p *githubProvider) Identity(ctx context.Context, accessToken string) (*Identity, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, p.client, "https://api.github.com/user", accessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch github user: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("github user has no id")
+	}
+
+	identity := &Identity{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	// The public profile email is unverified and often empty, so use the primary
+	// address from the emails endpoint instead
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.client, "https://api.github.com/user/emails", accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch github emails: %w", err)
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
+
+// exchangeCode posts an authorization code grant and returns the access token
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	// GitHub reports failed exchanges with a 200 and an error field
+	if token.Error != "" {
+		return "", fmt.Errorf("token exchange failed: %s: %s", token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode >= 300 || token.AccessToken == "" {
+		return "", fmt.Errorf("token exchange failed with status %d", resp.StatusCode)
+	}
+	return token.AccessToken, nil
+}
+
+// getJSON fetches url with a bearer token and decodes the JSON response into v
+func getJSON(ctx context.Context, client *http.Client, url, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
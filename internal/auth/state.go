@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StateCookie is the cookie holding the signed OAuth state between login and callback
+const StateCookie = "bookstore_oauth_state"
+
+// StateTTL is how long a login attempt has to complete
+const StateTTL = 10 * time.Minute
+
+// ErrInvalidState is returned when the callback state does not match the login attempt
+var ErrInvalidState = errors.New("invalid oauth state")
+
+// LoginState is the per-attempt state of an OAuth login
+type LoginState struct {
+	Provider string
+	State    string
+	Verifier string
+}
+
+// NewLoginState creates a random state and PKCE verifier for a login attempt
+func NewLoginState(provider string) (*LoginState, error) {
+	state, err := randomString(24)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString(48)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginState{Provider: provider, State: state, Verifier: verifier}, nil
+}
+
+// Challenge returns the S256 PKCE code challenge for the verifier
+func (s *LoginState) Challenge() string {
+	sum := sha256.Sum256([]byte(s.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// EncodeState signs the login state for storage in a cookie
+func (i *Issuer) EncodeState(s *LoginState) string {
+	expiresAt := strconv.FormatInt(time.Now().Add(StateTTL).Unix(), 10)
+	value := strings.Join([]string{s.Provider, s.State, s.Verifier, expiresAt}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + i.sign("state:"+value)
+}
+
+// DecodeState verifies a state cookie and checks it belongs to the callback's provider and state
+func (i *Issuer) DecodeState(cookie, provider, state string) (*LoginState, error) {
+	encoded, signature, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	value := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(i.sign("state:"+value))) {
+		return nil, ErrInvalidState
+	}
+
+	parts := strings.Split(value, "|")
+	if len(parts) != 4 {
+		return nil, ErrInvalidState
+	}
+	expiresAt, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return nil, ErrInvalidState
+	}
+	if parts[0] != provider || !hmac.Equal([]byte(parts[1]), []byte(state)) {
+		return nil, ErrInvalidState
+	}
+	return &LoginState{Provider: parts[0], State: parts[1], Verifier: parts[2]}, nil
+}
+
+// randomString returns n random bytes encoded as URL-safe base64
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
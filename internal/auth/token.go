@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"bookstore-api/internal/config"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, badly signed, or expired
+var ErrInvalidToken = errors.New("invalid token")
+
+// jwtHeader is the encoded header of every issued token ({"alg":"HS256","typ":"JWT"})
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+var (
+	issuer *Issuer
+	once   sync.Once
+)
+
+// Claims are the claims carried by an access token
+type Claims struct {
+	Subject   string `json:"sub"`
+	SessionID string `json:"sid"`
+	TenantID  string `json:"tid"`
+	Role      string `json:"role"`
+	Email     string `json:"email,omitempty"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// UserID returns the subject as a user ID
+func (c *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// Tenant returns the ID of the tenant the token was issued by
+func (c *Claims) Tenant() (uuid.UUID, error) {
+	return uuid.Parse(c.TenantID)
+}
+
+// Session returns the ID of the session the token was issued for
+func (c *Claims) Session() (uuid.UUID, error) {
+	return uuid.Parse(c.SessionID)
+}
+
+// Issuer issues and verifies HS256-signed JWT access tokens
+type Issuer struct {
+	secret []byte
+	ttl    time.Duration
+	name   string
+}
+
+// NewIssuer creates a token issuer. Without a configured secret a random one is
+// generated, so tokens stop verifying when the process restarts.
+func NewIssuer(cfg config.AuthConfig) (*Issuer, error) {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate token secret: %w", err)
+		}
+		log.Println("AUTH_JWT_SECRET is not set; using a random secret, issued tokens will not survive a restart")
+	}
+	ttl := cfg.TokenTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	return &Issuer{
+		secret: secret,
+		ttl:    ttl,
+		name:   cfg.Issuer,
+	}, nil
+}
+
+// InitializeIssuer initializes the shared token issuer
+func InitializeIssuer(cfg *config.Config) error {
+	var err error
+	once.Do(func() {
+		issuer, err = NewIssuer(cfg.Auth)
+	})
+	return err
+}
+
+// GetIssuer returns the shared token issuer
+func GetIssuer() *Issuer {
+	if issuer == nil {
+		log.Fatal("Token issuer not initialized. Call InitializeIssuer first.")
+	}
+	return issuer
+}
+
+// Issue returns a signed access token for the user's session within a tenant and when it expires
+func (i *Issuer) Issue(userID, sessionID, tenantID uuid.UUID, role, email string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+	claims := Claims{
+		Subject:   userID.String(),
+		SessionID: sessionID.String(),
+		TenantID:  tenantID.String(),
+		Role:      role,
+		Email:     email,
+		Issuer:    i.name,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode token claims: %w", err)
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + i.sign(unsigned), expiresAt, nil
+}
+
+// Parse verifies a token's signature, issuer, and expiry and returns its claims
+func (i *Issuer) Parse(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(i.sign(parts[0]+"."+parts[1]))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Issuer != i.name || claims.SessionID == "" || time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// NewRefreshToken returns a random opaque refresh token and the hash to store for it
+func NewRefreshToken() (string, string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the stored form of a refresh token
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// LooksLikeToken reports whether s has the shape of a JWT
+func LooksLikeToken(s string) bool {
+	return strings.Count(s, ".") == 2
+}
+
+// sign returns the encoded HMAC-SHA256 signature of the signing input
+func (i *Issuer) sign(input string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(input))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
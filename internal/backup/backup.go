@@ -0,0 +1,160 @@
+// Package backup dumps the catalog to a versioned archive and restores it into
+// a fresh database.
+//
+// An archive is JSON Lines: a header line followed by one line per row, each
+// naming the table it belongs to. Tables are written parents first so a
+// restore can check every reference as it goes.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Format identifies bookstore catalog archives
+const Format = "bookstore-catalog"
+
+// FormatVersion is the version of the archive layout written by Dump. Restore
+// only reads archives of this version.
+const FormatVersion = 1
+
+// Header is the first line of an archive
+type Header struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	SchemaVersion string    `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Summary reports the rows written to or read from an archive per table
+type Summary struct {
+	SchemaVersion string           `json:"schema_version"`
+	Tables        map[string]int64 `json:"tables"`
+	// Detached counts optional references to rows outside the archive, such
+	// as an author's user account, that were cleared because the row does not
+	// exist in the target database
+	Detached int64 `json:"detached"`
+}
+
+// line is an archive line holding one row
+type line struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// reference is a column of a table holding the ID of a row in another table
+type reference struct {
+	column   string
+	table    string
+	optional bool
+}
+
+// table is a catalog table included in archives
+type table struct {
+	name       string
+	references []reference
+	// orderBy lists rows so those referencing others of the same table follow them
+	orderBy string
+}
+
+// categoryDepth is an SQL expression counting the ancestors of the category
+// row t
+const categoryDepth = `(WITH RECURSIVE ancestors(id) AS (
+	SELECT t.parent_id WHERE t.parent_id IS NOT NULL
+	UNION ALL
+	SELECT c.parent_id FROM categories c JOIN ancestors a ON c.id = a.id WHERE c.parent_id IS NOT NULL
+) SELECT count(*) FROM ancestors)`
+
+// tables lists the catalog tables in the order they are dumped and restored
+var tables = []table{
+	{name: "tenants", orderBy: "created_at, id"},
+	{name: "publishers", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+	}},
+	{name: "authors", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "user_id", table: "users", optional: true},
+	}},
+	// Categories are ordered by their depth in the hierarchy, so parents
+	// precede their children even when a category was moved under a newer one
+	{name: "categories", orderBy: categoryDepth + ", created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "parent_id", table: "categories"},
+	}},
+	{name: "books", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "author_id", table: "authors"},
+		{column: "category_id", table: "categories"},
+		{column: "publisher_id", table: "publishers"},
+	}},
+	{name: "book_ratings", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "book_id", table: "books"},
+	}},
+	{name: "translations", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+	}},
+	{name: "slug_history", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+	}},
+	{name: "book_previews", orderBy: "created_at, book_id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "book_id", table: "books"},
+	}},
+	{name: "price_history", orderBy: "changed_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "book_id", table: "books"},
+	}},
+	{name: "stock_movements", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "book_id", table: "books"},
+	}},
+	{name: "promotions", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "book_id", table: "books"},
+		{column: "category_id", table: "categories"},
+	}},
+	{name: "warehouses", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+	}},
+	{name: "warehouse_stocks", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "warehouse_id", table: "warehouses"},
+		{column: "book_id", table: "books"},
+	}},
+	{name: "stock_transfers", orderBy: "created_at, id", references: []reference{
+		{column: "tenant_id", table: "tenants"},
+		{column: "book_id", table: "books"},
+		{column: "from_warehouse_id", table: "warehouses", optional: true},
+		{column: "to_warehouse_id", table: "warehouses", optional: true},
+	}},
+}
+
+// Tables returns the names of the archived tables in archive order
+func Tables() []string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.name
+	}
+	return names
+}
+
+// rowID returns the UUID in a row's column, or uuid.Nil if it is null or missing
+func rowID(row map[string]json.RawMessage, column string) (uuid.UUID, error) {
+	raw, ok := row[column]
+	if !ok || string(raw) == "null" {
+		return uuid.Nil, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s: %w", column, err)
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s: %w", column, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,260 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+
+	"bookstore-api/internal/models"
+)
+
+// diffIgnored are book columns left out of diffs: keys, timestamps, and
+// short codes, which differ between databases holding the same titles
+var diffIgnored = map[string]bool{
+	"id":           true,
+	"tenant_id":    true,
+	"isbn":         true,
+	"short_code":   true,
+	"created_at":   true,
+	"updated_at":   true,
+	"deleted_at":   true,
+	"author_id":    true,
+	"category_id":  true,
+	"publisher_id": true,
+}
+
+// ArchiveInfo describes an archive compared by Diff
+type ArchiveInfo struct {
+	SchemaVersion string    `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Books         int       `json:"books"`
+}
+
+// BookDiff is a title added, removed, or changed between two archives.
+// Changes are only set on changed titles.
+type BookDiff struct {
+	Tenant  string              `json:"tenant"`
+	ISBN    string              `json:"isbn"`
+	Title   string              `json:"title"`
+	Changes models.FieldChanges `json:"changes,omitempty"`
+}
+
+// CatalogDiff is the difference between the books of two archives, each
+// list ordered by tenant and ISBN
+type CatalogDiff struct {
+	Before    ArchiveInfo `json:"before"`
+	After     ArchiveInfo `json:"after"`
+	Added     []BookDiff  `json:"added"`
+	Removed   []BookDiff  `json:"removed"`
+	Changed   []BookDiff  `json:"changed"`
+	Unchanged int         `json:"unchanged"`
+}
+
+// snapshot is the books of an archive keyed by tenant slug and ISBN, with
+// their author, category, and publisher resolved to names
+type snapshot struct {
+	info  ArchiveInfo
+	books map[bookKey]map[string]interface{}
+}
+
+// bookKey identifies a title across databases, where IDs differ
+type bookKey struct {
+	tenant string
+	isbn   string
+}
+
+// Diff compares the books of two archives written by Dump, such as ones taken
+// before and after an import, and returns the titles added, removed, and
+// changed with the fields that changed. Titles are matched by tenant and
+// ISBN, so archives of different databases can be compared; soft deleted
+// books count as removed. Author, category, and publisher are compared by
+// name. Either archive may be gzipped.
+func Diff(before, after io.Reader) (*CatalogDiff, error) {
+	from, err := readSnapshot(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+	to, err := readSnapshot(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %w", err)
+	}
+
+	diff := &CatalogDiff{
+		Before:  from.info,
+		After:   to.info,
+		Added:   []BookDiff{},
+		Removed: []BookDiff{},
+		Changed: []BookDiff{},
+	}
+	for key, book := range to.books {
+		previous, ok := from.books[key]
+		if !ok {
+			diff.Added = append(diff.Added, bookDiff(key, book, nil))
+			continue
+		}
+		changes := fieldChanges(previous, book)
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Changed = append(diff.Changed, bookDiff(key, book, changes))
+	}
+	for key, book := range from.books {
+		if _, ok := to.books[key]; !ok {
+			diff.Removed = append(diff.Removed, bookDiff(key, book, nil))
+		}
+	}
+
+	for _, list := range [][]BookDiff{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Tenant != list[j].Tenant {
+				return list[i].Tenant < list[j].Tenant
+			}
+			return list[i].ISBN < list[j].ISBN
+		})
+	}
+	return diff, nil
+}
+
+// bookDiff describes a book of a diff
+func bookDiff(key bookKey, book map[string]interface{}, changes models.FieldChanges) BookDiff {
+	title, _ := book["title"].(string)
+	return BookDiff{Tenant: key.tenant, ISBN: key.isbn, Title: title, Changes: changes}
+}
+
+// fieldChanges returns the fields whose values differ between two versions
+// of a book, including fields present in only one of them
+func fieldChanges(before, after map[string]interface{}) models.FieldChanges {
+	changes := models.FieldChanges{}
+	for field, value := range after {
+		if previous := before[field]; !reflect.DeepEqual(previous, value) {
+			changes[field] = models.FieldChange{From: previous, To: value}
+		}
+	}
+	for field, previous := range before {
+		if _, ok := after[field]; !ok {
+			changes[field] = models.FieldChange{From: previous, To: nil}
+		}
+	}
+	return changes
+}
+
+// readSnapshot reads the books of an archive, and the tenants, authors,
+// categories, and publishers they reference
+func readSnapshot(r io.Reader) (*snapshot, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	header, err := readHeader(scanner)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]map[string]string{}
+	var books []map[string]interface{}
+	for lineNumber := 2; scanner.Scan(); lineNumber++ {
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, fmt.Errorf("invalid archive: line %d: %w", lineNumber, err)
+		}
+
+		switch l.Table {
+		case "tenants", "authors", "categories", "publishers":
+			var row struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+				Slug string `json:"slug"`
+			}
+			if err := json.Unmarshal(l.Row, &row); err != nil {
+				return nil, fmt.Errorf("invalid archive: line %d: %w", lineNumber, err)
+			}
+			if names[l.Table] == nil {
+				names[l.Table] = map[string]string{}
+			}
+			// Tenants are matched by slug, which is the same in every database
+			if l.Table == "tenants" {
+				names[l.Table][row.ID] = row.Slug
+			} else {
+				names[l.Table][row.ID] = row.Name
+			}
+		case "books":
+			var row map[string]interface{}
+			if err := json.Unmarshal(l.Row, &row); err != nil {
+				return nil, fmt.Errorf("invalid archive: line %d: %w", lineNumber, err)
+			}
+			books = append(books, row)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	snap := &snapshot{
+		info:  ArchiveInfo{SchemaVersion: header.SchemaVersion, CreatedAt: header.CreatedAt},
+		books: make(map[bookKey]map[string]interface{}, len(books)),
+	}
+	for _, row := range books {
+		if row["deleted_at"] != nil {
+			continue
+		}
+		tenantID, _ := row["tenant_id"].(string)
+		isbn, _ := row["isbn"].(string)
+		key := bookKey{tenant: names["tenants"][tenantID], isbn: isbn}
+		if key.tenant == "" {
+			key.tenant = tenantID
+		}
+
+		book := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			if !diffIgnored[column] {
+				book[column] = value
+			}
+		}
+		for column, table := range map[string]string{"author_id": "authors", "category_id": "categories", "publisher_id": "publishers"} {
+			field := column[:len(column)-len("_id")]
+			if id, ok := row[column].(string); ok {
+				book[field] = names[table][id]
+			} else {
+				book[field] = nil
+			}
+		}
+		snap.books[key] = book
+	}
+	snap.info.Books = len(snap.books)
+	return snap, nil
+}
+
+// readHeader reads and checks the header line of an archive
+func readHeader(scanner *bufio.Scanner) (*Header, error) {
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		return nil, fmt.Errorf("invalid archive: empty")
+	}
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Format != Format {
+		return nil, fmt.Errorf("invalid archive: missing %s header", Format)
+	}
+	if header.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d (expected %d)", header.Version, FormatVersion)
+	}
+	return &header, nil
+}
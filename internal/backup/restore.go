@@ -0,0 +1,311 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"bookstore-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// restoreBatchSize is the number of rows inserted per statement
+	restoreBatchSize = 500
+	// maxLineSize bounds a single archive line
+	maxLineSize = 64 << 20
+	// maxProblems bounds the integrity problems reported for one restore
+	maxProblems = 20
+)
+
+// translationTables maps translation entity types to the table they reference
+var translationTables = map[string]string{
+	models.TranslationEntityBook:     "books",
+	models.TranslationEntityCategory: "categories",
+}
+
+// IntegrityError lists the rows of an archive whose references cannot be
+// satisfied. Nothing is restored when it is returned.
+type IntegrityError struct {
+	Problems []string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("archive failed integrity checks:\n  %s", strings.Join(e.Problems, "\n  "))
+}
+
+// restorer restores an archive inside a transaction
+type restorer struct {
+	tx      *gorm.DB
+	summary *Summary
+	// ids holds the IDs of restored rows per table
+	ids map[string]map[uuid.UUID]bool
+	// external caches whether rows of tables outside the archive exist
+	external map[string]map[uuid.UUID]bool
+	problems []string
+	// skipped counts problems beyond maxProblems
+	skipped int
+}
+
+// Restore reads an archive written by Dump into db. The database must be
+// migrated to the archive's schema version and hold no catalog data besides
+// tenants; existing tenants with an archived ID are kept as they are. Every
+// reference is checked before its row is inserted and the whole restore runs
+// in one transaction, so it either restores everything or nothing.
+func Restore(db *gorm.DB, r io.Reader) (*Summary, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	header, err := readHeader(scanner)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := schemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if target != header.SchemaVersion {
+		return nil, fmt.Errorf("archive was taken at schema version %s but the database is at %s; migrate a database to %s to restore it", header.SchemaVersion, target, header.SchemaVersion)
+	}
+
+	summary := &Summary{SchemaVersion: header.SchemaVersion, Tables: make(map[string]int64, len(tables))}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := checkEmpty(tx); err != nil {
+			return err
+		}
+
+		rs := &restorer{
+			tx:       tx,
+			summary:  summary,
+			ids:      make(map[string]map[uuid.UUID]bool, len(tables)),
+			external: make(map[string]map[uuid.UUID]bool),
+		}
+		return rs.run(scanner)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// run restores the archive rows following the header
+func (rs *restorer) run(scanner *bufio.Scanner) error {
+	position := 0
+	var batch []json.RawMessage
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// Rows are only inserted while the archive is sound; later problems
+		// are still collected so they can be reported together
+		if len(rs.problems) == 0 {
+			if err := insertRows(rs.tx, tables[position], batch); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for lineNumber := 2; scanner.Scan(); lineNumber++ {
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return fmt.Errorf("invalid archive: line %d: %w", lineNumber, err)
+		}
+
+		if l.Table != tables[position].name {
+			next := tableIndex(l.Table)
+			if next < 0 {
+				return fmt.Errorf("invalid archive: line %d: unknown table %q", lineNumber, l.Table)
+			}
+			if next < position {
+				return fmt.Errorf("invalid archive: line %d: %s rows must come before %s rows", lineNumber, l.Table, tables[position].name)
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			position = next
+		}
+
+		row, err := rs.check(tables[position], l.Row, lineNumber)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		rs.summary.Tables[l.Table]++
+
+		if len(batch) >= restoreBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if len(rs.problems) > 0 {
+		if rs.skipped > 0 {
+			rs.problems = append(rs.problems, fmt.Sprintf("and %d more", rs.skipped))
+		}
+		return &IntegrityError{Problems: rs.problems}
+	}
+	return nil
+}
+
+// check verifies the references of a row and records its ID, returning the
+// row with any missing optional references cleared
+func (rs *restorer) check(t table, raw json.RawMessage, lineNumber int) (json.RawMessage, error) {
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return nil, fmt.Errorf("invalid archive: line %d: %w", lineNumber, err)
+	}
+
+	id, err := rowID(row, "id")
+	if err != nil || id == uuid.Nil {
+		rs.problem("line %d: %s row has no valid id", lineNumber, t.name)
+		return raw, nil
+	}
+	if rs.ids[t.name] == nil {
+		rs.ids[t.name] = make(map[uuid.UUID]bool)
+	}
+	if rs.ids[t.name][id] {
+		rs.problem("line %d: duplicate %s %s", lineNumber, t.name, id)
+	}
+	rs.ids[t.name][id] = true
+
+	references := t.references
+	if t.name == "translations" {
+		var entityType string
+		json.Unmarshal(row["entity_type"], &entityType)
+		entityTable, ok := translationTables[entityType]
+		if !ok {
+			rs.problem("line %d: translation %s has unknown entity_type %q", lineNumber, id, entityType)
+		} else {
+			references = append(references[:len(references):len(references)], reference{column: "entity_id", table: entityTable})
+		}
+	}
+
+	changed := false
+	for _, ref := range references {
+		refID, err := rowID(row, ref.column)
+		if err != nil {
+			rs.problem("line %d: %s %s: %v", lineNumber, t.name, id, err)
+			continue
+		}
+		if refID == uuid.Nil {
+			continue
+		}
+
+		found, err := rs.exists(ref.table, refID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case found:
+		case ref.optional:
+			row[ref.column] = json.RawMessage("null")
+			rs.summary.Detached++
+			changed = true
+		default:
+			rs.problem("line %d: %s %s references missing %s %s", lineNumber, t.name, id, strings.TrimSuffix(ref.table, "s"), refID)
+		}
+	}
+
+	if !changed {
+		return raw, nil
+	}
+	updated, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s %s: %w", t.name, id, err)
+	}
+	return updated, nil
+}
+
+// exists reports whether a row is in the archive's restored rows or, for
+// tables outside the archive, in the database
+func (rs *restorer) exists(tableName string, id uuid.UUID) (bool, error) {
+	if tableIndex(tableName) >= 0 {
+		return rs.ids[tableName][id], nil
+	}
+
+	cache := rs.external[tableName]
+	if cache == nil {
+		cache = make(map[uuid.UUID]bool)
+		rs.external[tableName] = cache
+	}
+	if found, ok := cache[id]; ok {
+		return found, nil
+	}
+
+	var count int64
+	if err := rs.tx.Table(tableName).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", tableName, err)
+	}
+	cache[id] = count > 0
+	return cache[id], nil
+}
+
+// problem records an integrity problem
+func (rs *restorer) problem(format string, args ...interface{}) {
+	if len(rs.problems) >= maxProblems {
+		rs.skipped++
+		return
+	}
+	rs.problems = append(rs.problems, fmt.Sprintf(format, args...))
+}
+
+// checkEmpty fails if any catalog table other than tenants holds rows
+func checkEmpty(tx *gorm.DB) error {
+	for _, t := range tables {
+		if t.name == "tenants" {
+			continue
+		}
+		var count int64
+		if err := tx.Table(t.name).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check %s: %w", t.name, err)
+		}
+		if count > 0 {
+			return fmt.Errorf("database is not empty: %s has %d rows; restore into a freshly migrated database", t.name, count)
+		}
+	}
+	return nil
+}
+
+// insertRows inserts a batch of archived rows into a table
+func insertRows(tx *gorm.DB, t table, rows []json.RawMessage) error {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", t.name, err)
+	}
+
+	// Tenants such as the default one are created by the migrations
+	conflict := ""
+	if t.name == "tenants" {
+		conflict = " ON CONFLICT (id) DO NOTHING"
+	}
+	query := fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, ?::json)%s", t.name, t.name, conflict)
+	if err := tx.Exec(query, string(data)).Error; err != nil {
+		return fmt.Errorf("failed to restore %s: %w", t.name, err)
+	}
+	return nil
+}
+
+// tableIndex returns the position of a table in tables, or -1 if it is not archived
+func tableIndex(name string) int {
+	for i, t := range tables {
+		if t.name == name {
+			return i
+		}
+	}
+	return -1
+}
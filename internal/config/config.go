@@ -1,23 +1,102 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for our application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	GRPC     GRPCConfig
+	// Profile is the configuration profile selected by APP_PROFILE
+	Profile       string
+	Server        ServerConfig
+	Timeouts      TimeoutConfig
+	Bulkheads     BulkheadConfig
+	Responses     ResponseConfig
+	Database      DatabaseConfig
+	GRPC          GRPCConfig
+	Pagination    PaginationConfig
+	Jobs          JobsConfig
+	Scheduler     SchedulerConfig
+	Events        EventsConfig
+	Broker        BrokerConfig
+	Webhooks      WebhooksConfig
+	Stream        StreamConfig
+	Dashboard     DashboardConfig
+	ONIX          ONIXConfig
+	Import        ImportConfig
+	Operations    OperationsConfig
+	Metadata      MetadataConfig
+	Suppliers     SuppliersConfig
+	Mail          MailConfig
+	Notifications NotificationsConfig
+	OpsAlerts     OpsAlertsConfig
+	Auth          AuthConfig
+	Login         LoginConfig
+	Signing       SigningConfig
+	Tenancy       TenancyConfig
+	I18n          I18nConfig
+	Rentals       RentalsConfig
+	Inventory     InventoryConfig
+	ReadModels    ReadModelsConfig
+	AdminUI       AdminUIConfig
+	Storage       StorageConfig
+	Analytics     AnalyticsConfig
+	Privacy       PrivacyConfig
+	PII           PIIConfig
+	IDs           IDConfig
+	Timestamps    TimestampConfig
+	Archive       ArchiveConfig
+	Storefront    StorefrontConfig
+	Feeds         FeedsConfig
+	SlowQuery     SlowQueryConfig
+	BodySample    BodySampleConfig
+	Logging       LoggingConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port string
 	Host string
+	// ShutdownTimeout bounds how long shutdown waits for in-flight HTTP and
+	// gRPC requests before closing their connections
+	ShutdownTimeout time.Duration
+	// ReusePort sets SO_REUSEPORT on the HTTP and gRPC listeners so several
+	// processes can bind the same ports
+	ReusePort bool
+	// DisabledModules lists, comma-separated, the route modules the HTTP
+	// server leaves out, such as webhooks or admin-ui
+	DisabledModules string
+}
+
+// TimeoutConfig holds request timeout configuration
+type TimeoutConfig struct {
+	// Request is how long a request may run before its context is cancelled
+	// and it fails with 504 Gateway Timeout; zero disables the timeout
+	Request time.Duration
+	// Routes overrides Request for path prefixes, as comma-separated
+	// prefix=duration pairs; the longest matching prefix wins
+	Routes string
+}
+
+// BulkheadConfig caps the requests expensive endpoints serve at once; zero
+// removes a cap
+type BulkheadConfig struct {
+	Search int
+	Export int
+	Import int
+}
+
+// ResponseConfig holds response format configuration
+type ResponseConfig struct {
+	// Format is the format responses use unless the request asks for
+	// another: envelope or jsonapi
+	Format string
 }
 
 // DatabaseConfig holds database configuration
@@ -28,25 +107,457 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// HealthInterval is how often the connection is pinged; zero disables
+	// supervision
+	HealthInterval time.Duration
+	PingTimeout    time.Duration
+	// FailureThreshold is the number of failed pings in a row after which
+	// the database is reported down and the connection is recovered
+	FailureThreshold int
+	// ReconnectMaxBackoff caps the delay between reconnect attempts
+	ReconnectMaxBackoff time.Duration
 }
 
 // GRPCConfig holds gRPC configuration
 type GRPCConfig struct {
 	Port string
 	Host string
+	// MaxDeadline bounds how long a call may run, whatever deadline the
+	// client sent; zero leaves client deadlines alone
+	MaxDeadline time.Duration
+	// Web serves the gRPC services to gRPC-Web clients on the HTTP server,
+	// under /grpc-web
+	Web bool
+	// SharedPort serves gRPC on the HTTP port instead of its own, routing
+	// connections that open with the HTTP/2 preface to it
+	SharedPort bool
+}
+
+// PaginationConfig holds pagination configuration
+type PaginationConfig struct {
+	CountMode     string
+	CountCacheTTL time.Duration
+	// DefaultLimit is the page size of listings when the request sets no
+	// limit, and MaxLimit the largest limit a request may set
+	DefaultLimit int
+	MaxLimit     int
+	// Routes overrides DefaultLimit and MaxLimit for path prefixes, as
+	// comma-separated prefix=default:max pairs; the longest matching prefix
+	// wins
+	Routes string
+}
+
+// JobsConfig holds background job worker configuration
+type JobsConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	StaleAfter   time.Duration
+}
+
+// SchedulerConfig holds cron expressions and settings for scheduled tasks
+type SchedulerConfig struct {
+	Enabled             bool
+	LowStockScan        string
+	LowStockThreshold   int
+	SoftDeletePurge     string
+	SoftDeleteRetention time.Duration
+	OutboxPurge         string
+	ReportDispatch      string
+	SessionPurge        string
+	PromotionSync       string
+	RentalLateScan      string
+	AlertScan           string
+	ListingRebuild      string
+	StatsRefresh        string
+	PartitionSync       string
+	PartitionsAhead     int
+	Archive             string
+	SupplierSync        string
+	FeedGeneration      string
+	SitemapGeneration   string
+}
+
+// EventsConfig holds domain event dispatcher configuration
+type EventsConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	Retention    time.Duration
+}
+
+// BrokerConfig holds message broker configuration for publishing domain events
+type BrokerConfig struct {
+	Type         string
+	NATSURL      string
+	KafkaRESTURL string
+	TopicPrefix  string
+	Timeout      time.Duration
+}
+
+// WebhooksConfig holds outbound webhook delivery configuration
+type WebhooksConfig struct {
+	Timeout time.Duration
+}
+
+// StreamConfig holds Server-Sent Events stream configuration
+type StreamConfig struct {
+	Heartbeat   time.Duration
+	BufferSize  int
+	ReplayLimit int
+}
+
+// DashboardConfig holds admin dashboard WebSocket configuration
+type DashboardConfig struct {
+	Interval time.Duration
+}
+
+// ONIXConfig holds ONIX feed import configuration
+type ONIXConfig struct {
+	Currency        string
+	DefaultCategory string
+}
+
+// ImportConfig holds bulk import configuration
+type ImportConfig struct {
+	// BatchSize is the number of books the CSV and ONIX importers insert per
+	// transaction unless a request sets its own
+	BatchSize int
+}
+
+// OperationsConfig holds configuration of the imports and exports run as
+// long-running operations
+type OperationsConfig struct {
+	// Dir is the directory uploads waiting to be imported and finished
+	// exports are kept in. It is never served over HTTP; exports are
+	// downloaded through their operation.
+	Dir string
+	// AsyncImportSize is the upload size in bytes above which book imports
+	// run as operations even when the request does not ask for it
+	AsyncImportSize int
+}
+
+// MetadataConfig holds external book metadata lookup configuration
+type MetadataConfig struct {
+	Providers         string
+	GoogleBooksAPIKey string
+	Timeout           time.Duration
+	CacheTTL          time.Duration
+	RateLimit         int
+}
+
+// SuppliersConfig holds configuration of dropshipping supplier feed syncs
+type SuppliersConfig struct {
+	// Timeout bounds fetching one supplier's feed
+	Timeout time.Duration
+	// MaxFeedSize is the largest feed in bytes a sync reads
+	MaxFeedSize int
+}
+
+// MailConfig holds outbound SMTP email configuration
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// NotificationsConfig holds configuration of the SMS and push notification
+// channels. A channel without credentials is disabled; users who chose it
+// are not notified over it.
+type NotificationsConfig struct {
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFromNumber string
+	// FCMProjectID is the Firebase project push notifications are sent
+	// through, the credentials file's project when empty
+	FCMProjectID string
+	// FCMCredentialsFile is the path of a Google service account key file
+	// allowed to send Firebase Cloud Messaging messages
+	FCMCredentialsFile string
+	Timeout            time.Duration
+}
+
+// OpsAlertsConfig holds configuration of the operational alerts posted to
+// Slack and Microsoft Teams. Alerts are off unless a webhook URL is set.
+type OpsAlertsConfig struct {
+	SlackWebhookURL string
+	TeamsWebhookURL string
+	// Cooldown is how long an alert is not posted again while its
+	// condition persists
+	Cooldown time.Duration
+	// ErrorRateThreshold is the fraction of requests failing with a server
+	// error over ErrorRateWindow that raises an alert; zero disables it
+	ErrorRateThreshold float64
+	ErrorRateWindow    time.Duration
+	// ErrorRateMinRequests is the number of requests a window needs before
+	// its error rate is judged
+	ErrorRateMinRequests int
+	Timeout              time.Duration
+}
+
+// OAuthProviderConfig holds the OAuth2 client credentials for an identity provider
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// AuthConfig holds access token and social login configuration
+type AuthConfig struct {
+	JWTSecret          string
+	TokenTTL           time.Duration
+	RefreshTokenTTL    time.Duration
+	RevocationCacheTTL time.Duration
+	Issuer             string
+	CallbackBaseURL    string
+	Google             OAuthProviderConfig
+	GitHub             OAuthProviderConfig
+}
+
+// LoginConfig holds brute-force protection for the login and token refresh
+// endpoints. Failures are counted per client IP and per account over Window.
+type LoginConfig struct {
+	// CaptchaAfter is the number of failures from an IP after which its
+	// attempts must carry a solved CAPTCHA; zero never asks for one
+	CaptchaAfter int
+	// LockoutAfter is the number of failures after which the IP or account is
+	// locked out; zero disables lockouts
+	LockoutAfter int
+	// LockoutBase is the first lockout's length. Each further failure doubles
+	// it, up to LockoutMax.
+	LockoutBase time.Duration
+	LockoutMax  time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+	// CaptchaVerifyURL is a reCAPTCHA, hCaptcha, or Turnstile compatible
+	// siteverify endpoint; without it no CAPTCHA is asked for
+	CaptchaVerifyURL string
+	CaptchaSecret    string
+}
+
+// SigningConfig holds signed URL configuration
+type SigningConfig struct {
+	// Secret is the HMAC key signed URLs are signed with; without one a random
+	// key is generated and signed URLs do not survive a restart
+	Secret string
+	// TTL is how long signed URLs stay valid unless their issuer says otherwise
+	TTL time.Duration
+}
+
+// TenancyConfig holds storefront tenant resolution configuration
+type TenancyConfig struct {
+	Header        string
+	BaseDomain    string
+	DefaultTenant string
+	CacheTTL      time.Duration
+}
+
+// I18nConfig holds localization configuration
+type I18nConfig struct {
+	DefaultLocale string
+}
+
+// AdminUIConfig holds admin app configuration
+type AdminUIConfig struct {
+	// Enabled serves the embedded admin app under /admin
+	Enabled bool
+}
+
+// StorageConfig holds media storage configuration
+type StorageConfig struct {
+	// Dir is the directory uploaded media such as author photos is stored in
+	Dir string
+	// URLPrefix is prepended to media keys to form their URLs. A path is served
+	// from Dir by the HTTP server; an absolute URL points at a CDN mirroring Dir.
+	URLPrefix string
+}
+
+// AnalyticsConfig holds book view tracking configuration
+type AnalyticsConfig struct {
+	// FlushInterval is how often buffered views are written
+	FlushInterval time.Duration
+	// BatchSize is the number of buffered views that triggers an early write
+	BatchSize int
+	// BufferSize caps the views held in memory; views beyond it are dropped
+	BufferSize int
+	// TrendingWindow is how far back views count toward trending books
+	TrendingWindow time.Duration
+}
+
+// PrivacyConfig holds consent management configuration
+type PrivacyConfig struct {
+	// PolicyVersion is the current privacy policy version, recorded with
+	// consents given without naming one
+	PolicyVersion string
+}
+
+// PIIConfig holds the keys encrypting personal data at rest
+type PIIConfig struct {
+	// EncryptionKeys lists AES-256 keys as comma-separated id:base64 pairs.
+	// The first encrypts new values; the others only decrypt values written
+	// before a rotation. No keys leaves personal data unencrypted.
+	EncryptionKeys string
+	// IndexKey is the base64 HMAC key of the blind indexes used to look up
+	// encrypted values such as user emails
+	IndexKey string
+}
+
+// IDConfig holds primary key generation configuration
+type IDConfig struct {
+	// Strategy is how the IDs of new records are generated: uuidv7 (the
+	// default) or ulid, both time-ordered, or uuidv4 for random IDs
+	Strategy string
+}
+
+// TimestampConfig holds timestamp handling configuration
+type TimestampConfig struct {
+	// PublicationTimezone is the IANA time zone whose date is kept when a
+	// publication date is given as a full timestamp
+	PublicationTimezone string
+}
+
+// SlowQueryConfig holds slow query logging configuration
+type SlowQueryConfig struct {
+	// Threshold is the duration above which statements are logged; zero
+	// disables the slow query log
+	Threshold time.Duration
+	// Explain captures the EXPLAIN ANALYZE plan of slow SELECT statements by
+	// running them again; enable it outside production only
+	Explain bool
+	// BufferSize is the number of recent slow statements kept for the admin API
+	BufferSize int
+}
+
+// LoggingConfig holds logging configuration
+type LoggingConfig struct {
+	// Level is the initial log level: debug, info, warn, or error. Admins can
+	// change it at runtime.
+	Level string
+	// QueryRedactColumns are columns, comma-separated, whose values are
+	// masked in logged statements in addition to the built-in personal and
+	// secret columns
+	QueryRedactColumns string
+}
+
+// BodySampleConfig holds configuration of the debug logging of sampled
+// request and response bodies
+type BodySampleConfig struct {
+	// Percent is the percentage of requests whose bodies are logged; zero
+	// disables sampling
+	Percent float64
+	// RedactFields lists JSON fields, in addition to the built-in personal
+	// and secret fields, whose values are masked, comma-separated
+	RedactFields string
+	// MaxBytes truncates each logged body
+	MaxBytes int
+}
+
+// ArchiveConfig holds configuration of the archival of old log rows
+type ArchiveConfig struct {
+	Enabled bool
+	// Dir is the cold storage directory archives are written to, such as a
+	// mounted object storage bucket. It is never served over HTTP.
+	Dir string
+	// Retention is how long rows are kept in the database before they are
+	// archived
+	Retention time.Duration
+	// BatchSize is the number of rows per archive
+	BatchSize int
+}
+
+// StorefrontConfig holds the storefront the catalog is sold on, which
+// generated feeds link to
+type StorefrontConfig struct {
+	// URL is the storefront's base URL. Tenants with a domain use
+	// https://<domain> instead.
+	URL string
+	// BookPath, AuthorPath, and CategoryPath are the paths of pages, with
+	// {slug} replaced by the book's, author's, or category's slug
+	BookPath     string
+	AuthorPath   string
+	CategoryPath string
+	// Currency is the ISO 4217 code of book prices
+	Currency string
+}
+
+// FeedsConfig holds configuration of the generated storefront product feeds
+// and sitemaps
+type FeedsConfig struct {
+	// Dir is the directory generated feeds and sitemaps are kept in until
+	// they are regenerated
+	Dir string
+	// NewReleasesLimit is the number of books in the new release feeds
+	NewReleasesLimit int
+	// SitemapPageSize is the number of pages listed per sitemap file, at
+	// most 50,000
+	SitemapPageSize int
+}
+
+// RentalsConfig holds book rental configuration
+type RentalsConfig struct {
+	Enabled       bool
+	DefaultPeriod time.Duration
+	MaxPeriod     time.Duration
+}
+
+// InventoryConfig holds stock keeping configuration
+type InventoryConfig struct {
+	// Mode is "state", where stock is a column written in place, or
+	// "event_sourced", where every change is appended to the stock movement
+	// log and the column is a projection of it
+	Mode string
+}
+
+// ReadModelsConfig holds configuration of the denormalized read models
+type ReadModelsConfig struct {
+	// Enabled keeps the book_listings read model up to date from domain
+	// events and serves book listings from it
+	Enabled bool
 }
 
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
+	// A profile's .env.<profile> file takes precedence over .env
+	profile := os.Getenv("APP_PROFILE")
+	if profile != "" {
+		if err := godotenv.Load(".env." + profile); err != nil {
+			log.Printf("No .env.%s file found, using profile defaults", profile)
+		}
+	}
+
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// Secrets may come from a file mounted by a secret manager, in .env format
+	if path := os.Getenv("SECRETS_FILE"); path != "" {
+		if err := godotenv.Overload(path); err != nil {
+			return nil, fmt.Errorf("failed to load secrets file %s: %w", path, err)
+		}
+	}
+
 	cfg := &Config{
+		Profile: profile,
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "localhost"),
+			Port:            getEnv("SERVER_PORT", "8080"),
+			Host:            getEnv("SERVER_HOST", "localhost"),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ReusePort:       getEnvBool("SERVER_REUSE_PORT", false),
+			DisabledModules: getEnv("SERVER_DISABLED_MODULES", ""),
+		},
+		Timeouts: TimeoutConfig{
+			Request: getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+			Routes:  getEnv("REQUEST_ROUTE_TIMEOUTS", "/api/v1/books/search=10s,/api/v1/books/export=10m,/api/v1/books/import=5m,/api/v1/admin/onix=10m,/api/v1/admin/maintenance=10m,/api/v1/suppliers=10m,/api/v1/events=0,/ws=0"),
+		},
+		Bulkheads: BulkheadConfig{
+			Search: getEnvInt("BULKHEAD_SEARCH", 32),
+			Export: getEnvInt("BULKHEAD_EXPORT", 2),
+			Import: getEnvInt("BULKHEAD_IMPORT", 2),
+		},
+		Responses: ResponseConfig{
+			Format: getEnv("RESPONSE_FORMAT", "envelope"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -55,10 +566,229 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", "password"),
 			DBName:   getEnv("DB_NAME", "bookstore"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			HealthInterval:      getEnvDuration("DB_HEALTH_INTERVAL", 5*time.Second),
+			PingTimeout:         getEnvDuration("DB_PING_TIMEOUT", 2*time.Second),
+			FailureThreshold:    getEnvInt("DB_FAILURE_THRESHOLD", 3),
+			ReconnectMaxBackoff: getEnvDuration("DB_RECONNECT_MAX_BACKOFF", 30*time.Second),
 		},
 		GRPC: GRPCConfig{
-			Port: getEnv("GRPC_PORT", "9090"),
-			Host: getEnv("GRPC_HOST", "localhost"),
+			Port:        getEnv("GRPC_PORT", "9090"),
+			Host:        getEnv("GRPC_HOST", "localhost"),
+			MaxDeadline: getEnvDuration("GRPC_MAX_DEADLINE", 30*time.Second),
+			Web:         getEnvBool("GRPC_WEB_ENABLED", false),
+			SharedPort:  getEnvBool("GRPC_SHARED_PORT", false),
+		},
+		Pagination: PaginationConfig{
+			CountMode:     getEnv("PAGINATION_COUNT_MODE", "exact"),
+			CountCacheTTL: getEnvDuration("PAGINATION_COUNT_CACHE_TTL", 30*time.Second),
+			DefaultLimit:  getEnvInt("PAGINATION_DEFAULT_LIMIT", 10),
+			MaxLimit:      getEnvInt("PAGINATION_MAX_LIMIT", 100),
+			Routes:        getEnv("PAGINATION_ROUTE_LIMITS", ""),
+		},
+		Jobs: JobsConfig{
+			Workers:      getEnvInt("JOBS_WORKERS", 4),
+			PollInterval: getEnvDuration("JOBS_POLL_INTERVAL", time.Second),
+			MaxAttempts:  getEnvInt("JOBS_MAX_ATTEMPTS", 5),
+			StaleAfter:   getEnvDuration("JOBS_STALE_AFTER", 10*time.Minute),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:             getEnvBool("SCHEDULER_ENABLED", true),
+			LowStockScan:        getEnv("SCHEDULE_LOW_STOCK_SCAN", "0 * * * *"),
+			LowStockThreshold:   getEnvInt("LOW_STOCK_THRESHOLD", 5),
+			SoftDeletePurge:     getEnv("SCHEDULE_SOFT_DELETE_PURGE", "30 3 * * *"),
+			SoftDeleteRetention: getEnvDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+			OutboxPurge:         getEnv("SCHEDULE_OUTBOX_PURGE", "0 4 * * *"),
+			ReportDispatch:      getEnv("SCHEDULE_REPORT_DISPATCH", "* * * * *"),
+			SessionPurge:        getEnv("SCHEDULE_SESSION_PURGE", "15 4 * * *"),
+			PromotionSync:       getEnv("SCHEDULE_PROMOTION_SYNC", "* * * * *"),
+			RentalLateScan:      getEnv("SCHEDULE_RENTAL_LATE_SCAN", "*/15 * * * *"),
+			AlertScan:           getEnv("SCHEDULE_ALERT_SCAN", "*/15 * * * *"),
+			ListingRebuild:      getEnv("SCHEDULE_LISTING_REBUILD", "0 5 * * *"),
+			StatsRefresh:        getEnv("SCHEDULE_STATS_REFRESH", "*/15 * * * *"),
+			PartitionSync:       getEnv("SCHEDULE_PARTITION_SYNC", "0 2 * * *"),
+			PartitionsAhead:     getEnvInt("PARTITIONS_AHEAD", 3),
+			Archive:             getEnv("SCHEDULE_ARCHIVE", "45 3 * * *"),
+			SupplierSync:        getEnv("SCHEDULE_SUPPLIER_SYNC", "0 * * * *"),
+			FeedGeneration:      getEnv("SCHEDULE_FEED_GENERATION", "30 * * * *"),
+			SitemapGeneration:   getEnv("SCHEDULE_SITEMAP_GENERATION", "15 2 * * *"),
+		},
+		Events: EventsConfig{
+			PollInterval: getEnvDuration("EVENTS_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvInt("EVENTS_BATCH_SIZE", 100),
+			MaxAttempts:  getEnvInt("EVENTS_MAX_ATTEMPTS", 10),
+			Retention:    getEnvDuration("EVENTS_RETENTION", 7*24*time.Hour),
+		},
+		Broker: BrokerConfig{
+			Type:         getEnv("BROKER_TYPE", "none"),
+			NATSURL:      getEnv("NATS_URL", "nats://localhost:4222"),
+			KafkaRESTURL: getEnv("KAFKA_REST_URL", "http://localhost:8082"),
+			TopicPrefix:  getEnv("BROKER_TOPIC_PREFIX", "bookstore"),
+			Timeout:      getEnvDuration("BROKER_TIMEOUT", 5*time.Second),
+		},
+		Webhooks: WebhooksConfig{
+			Timeout: getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		},
+		Stream: StreamConfig{
+			Heartbeat:   getEnvDuration("STREAM_HEARTBEAT", 15*time.Second),
+			BufferSize:  getEnvInt("STREAM_BUFFER_SIZE", 64),
+			ReplayLimit: getEnvInt("STREAM_REPLAY_LIMIT", 500),
+		},
+		Dashboard: DashboardConfig{
+			Interval: getEnvDuration("DASHBOARD_INTERVAL", 5*time.Second),
+		},
+		ONIX: ONIXConfig{
+			Currency:        getEnv("ONIX_CURRENCY", "USD"),
+			DefaultCategory: getEnv("ONIX_DEFAULT_CATEGORY", "Uncategorized"),
+		},
+		Import: ImportConfig{
+			BatchSize: getEnvInt("IMPORT_BATCH_SIZE", 100),
+		},
+		Operations: OperationsConfig{
+			Dir:             getEnv("OPERATIONS_DIR", "./operations"),
+			AsyncImportSize: getEnvInt("OPERATIONS_ASYNC_IMPORT_SIZE", 1<<20),
+		},
+		Metadata: MetadataConfig{
+			Providers:         getEnv("METADATA_PROVIDERS", "openlibrary,googlebooks"),
+			GoogleBooksAPIKey: getEnv("GOOGLE_BOOKS_API_KEY", ""),
+			Timeout:           getEnvDuration("METADATA_TIMEOUT", 5*time.Second),
+			CacheTTL:          getEnvDuration("METADATA_CACHE_TTL", 24*time.Hour),
+			RateLimit:         getEnvInt("METADATA_RATE_LIMIT", 60),
+		},
+		Suppliers: SuppliersConfig{
+			Timeout:     getEnvDuration("SUPPLIER_FEED_TIMEOUT", 30*time.Second),
+			MaxFeedSize: getEnvInt("SUPPLIER_FEED_MAX_SIZE", 32<<20),
+		},
+		Mail: MailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", "bookstore@localhost"),
+		},
+		Notifications: NotificationsConfig{
+			TwilioAccountSID:   getEnv("TWILIO_ACCOUNT_SID", ""),
+			TwilioAuthToken:    getEnv("TWILIO_AUTH_TOKEN", ""),
+			TwilioFromNumber:   getEnv("TWILIO_FROM_NUMBER", ""),
+			FCMProjectID:       getEnv("FCM_PROJECT_ID", ""),
+			FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
+			Timeout:            getEnvDuration("NOTIFICATION_TIMEOUT", 10*time.Second),
+		},
+		OpsAlerts: OpsAlertsConfig{
+			SlackWebhookURL:      getEnv("OPS_ALERT_SLACK_WEBHOOK_URL", ""),
+			TeamsWebhookURL:      getEnv("OPS_ALERT_TEAMS_WEBHOOK_URL", ""),
+			Cooldown:             getEnvDuration("OPS_ALERT_COOLDOWN", 15*time.Minute),
+			ErrorRateThreshold:   getEnvFloat("OPS_ALERT_ERROR_RATE", 0.05),
+			ErrorRateWindow:      getEnvDuration("OPS_ALERT_ERROR_RATE_WINDOW", time.Minute),
+			ErrorRateMinRequests: getEnvInt("OPS_ALERT_ERROR_RATE_MIN_REQUESTS", 50),
+			Timeout:              getEnvDuration("OPS_ALERT_TIMEOUT", 5*time.Second),
+		},
+		Auth: AuthConfig{
+			JWTSecret:          getEnv("AUTH_JWT_SECRET", ""),
+			TokenTTL:           getEnvDuration("AUTH_TOKEN_TTL", time.Hour),
+			RefreshTokenTTL:    getEnvDuration("AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			RevocationCacheTTL: getEnvDuration("AUTH_REVOCATION_CACHE_TTL", 10*time.Second),
+			Issuer:             getEnv("AUTH_ISSUER", "bookstore-api"),
+			CallbackBaseURL:    getEnv("AUTH_CALLBACK_BASE_URL", "http://localhost:8080"),
+			Google: OAuthProviderConfig{
+				ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+				ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+			},
+			GitHub: OAuthProviderConfig{
+				ClientID:     getEnv("GITHUB_CLIENT_ID", ""),
+				ClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+			},
+		},
+		Login: LoginConfig{
+			CaptchaAfter:     getEnvInt("LOGIN_CAPTCHA_AFTER", 3),
+			LockoutAfter:     getEnvInt("LOGIN_LOCKOUT_AFTER", 5),
+			LockoutBase:      getEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+			LockoutMax:       getEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+			Window:           getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			CaptchaVerifyURL: getEnv("CAPTCHA_VERIFY_URL", ""),
+			CaptchaSecret:    getEnv("CAPTCHA_SECRET", ""),
+		},
+		Signing: SigningConfig{
+			Secret: getEnv("URL_SIGNING_SECRET", ""),
+			TTL:    getEnvDuration("SIGNED_URL_TTL", 15*time.Minute),
+		},
+		Tenancy: TenancyConfig{
+			Header:        getEnv("TENANT_HEADER", "X-Tenant"),
+			BaseDomain:    getEnv("TENANT_BASE_DOMAIN", ""),
+			DefaultTenant: getEnv("TENANT_DEFAULT", "default"),
+			CacheTTL:      getEnvDuration("TENANT_CACHE_TTL", time.Minute),
+		},
+		I18n: I18nConfig{
+			DefaultLocale: getEnv("I18N_DEFAULT_LOCALE", "en"),
+		},
+		Rentals: RentalsConfig{
+			Enabled:       getEnvBool("RENTALS_ENABLED", false),
+			DefaultPeriod: getEnvDuration("RENTAL_DEFAULT_PERIOD", 14*24*time.Hour),
+			MaxPeriod:     getEnvDuration("RENTAL_MAX_PERIOD", 90*24*time.Hour),
+		},
+		Inventory: InventoryConfig{
+			Mode: getEnv("INVENTORY_MODE", "state"),
+		},
+		ReadModels: ReadModelsConfig{
+			Enabled: getEnvBool("READ_MODELS_ENABLED", false),
+		},
+		AdminUI: AdminUIConfig{
+			Enabled: getEnvBool("ADMIN_UI_ENABLED", true),
+		},
+		Storage: StorageConfig{
+			Dir:       getEnv("STORAGE_DIR", "./media"),
+			URLPrefix: getEnv("STORAGE_URL_PREFIX", "/media"),
+		},
+		Analytics: AnalyticsConfig{
+			FlushInterval:  getEnvDuration("VIEWS_FLUSH_INTERVAL", 5*time.Second),
+			BatchSize:      getEnvInt("VIEWS_BATCH_SIZE", 500),
+			BufferSize:     getEnvInt("VIEWS_BUFFER_SIZE", 10000),
+			TrendingWindow: getEnvDuration("TRENDING_WINDOW", 7*24*time.Hour),
+		},
+		Privacy: PrivacyConfig{
+			PolicyVersion: getEnv("PRIVACY_POLICY_VERSION", "1"),
+		},
+		PII: PIIConfig{
+			EncryptionKeys: getEnv("PII_ENCRYPTION_KEYS", ""),
+			IndexKey:       getEnv("PII_INDEX_KEY", ""),
+		},
+		Timestamps: TimestampConfig{
+			PublicationTimezone: getEnv("PUBLICATION_TIMEZONE", "UTC"),
+		},
+		IDs: IDConfig{
+			Strategy: getEnv("ID_STRATEGY", "uuidv7"),
+		},
+		Archive: ArchiveConfig{
+			Enabled:   getEnvBool("ARCHIVE_ENABLED", false),
+			Dir:       getEnv("ARCHIVE_DIR", "./archives"),
+			Retention: getEnvDuration("ARCHIVE_RETENTION", 365*24*time.Hour),
+			BatchSize: getEnvInt("ARCHIVE_BATCH_SIZE", 10000),
+		},
+		Storefront: StorefrontConfig{
+			URL:          getEnv("STOREFRONT_URL", "http://localhost:3000"),
+			BookPath:     getEnv("STOREFRONT_BOOK_PATH", "/books/{slug}"),
+			AuthorPath:   getEnv("STOREFRONT_AUTHOR_PATH", "/authors/{slug}"),
+			CategoryPath: getEnv("STOREFRONT_CATEGORY_PATH", "/categories/{slug}"),
+			Currency:     getEnv("STOREFRONT_CURRENCY", "USD"),
+		},
+		Feeds: FeedsConfig{
+			Dir:              getEnv("FEEDS_DIR", "./feeds"),
+			NewReleasesLimit: getEnvInt("FEEDS_NEW_RELEASES_LIMIT", 50),
+			SitemapPageSize:  getEnvInt("SITEMAP_PAGE_SIZE", 10000),
+		},
+		SlowQuery: SlowQueryConfig{
+			Threshold:  getEnvDuration("SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+			Explain:    getEnvBool("SLOW_QUERY_EXPLAIN", false),
+			BufferSize: getEnvInt("SLOW_QUERY_BUFFER", 100),
+		},
+		Logging: LoggingConfig{
+			Level:              getEnv("LOG_LEVEL", "info"),
+			QueryRedactColumns: getEnv("LOG_QUERY_REDACT_COLUMNS", ""),
+		},
+		BodySample: BodySampleConfig{
+			Percent:      getEnvFloat("BODY_SAMPLE_PERCENT", 0),
+			RedactFields: getEnv("BODY_SAMPLE_REDACT_FIELDS", ""),
+			MaxBytes:     getEnvInt("BODY_SAMPLE_MAX_BYTES", 4096),
 		},
 	}
 
@@ -73,6 +803,51 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt gets an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+		log.Printf("Invalid integer for %s: %q, using default %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets a floating-point environment variable or returns a default
+// value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		log.Printf("Invalid number for %s: %q, using default %g", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("Invalid boolean for %s: %q, using default %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets a duration environment variable or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		log.Printf("Invalid duration for %s: %q, using default %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 // GetDSN returns the database connection string
 func (c *Config) GetDSN() string {
 	return "host=" + c.Database.Host +
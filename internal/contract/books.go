@@ -0,0 +1,250 @@
+package contract
+
+import (
+	"bookstore-api/internal/timestamps"
+	pb "bookstore-api/proto"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// fixtures are the records every scenario's books belong to
+type fixtures struct {
+	AuthorID   string
+	CategoryID string
+}
+
+// bookInput is a fixture book
+type bookInput struct {
+	Title       string
+	ISBN        string
+	Description string
+	Price       float64
+	Stock       int
+	PublishedAt time.Time
+}
+
+// bookView is a book as returned by either transport, with values normalized
+// so they can be compared
+type bookView struct {
+	ID             string
+	Title          string
+	ISBN           string
+	Description    string
+	Price          float64
+	EffectivePrice float64
+	Stock          int
+	PublishedAt    string
+	AuthorID       string
+	CategoryID     string
+}
+
+// restBook is a book in a REST response
+type restBook struct {
+	ID             string   `json:"id"`
+	Title          string   `json:"title"`
+	ISBN           string   `json:"isbn"`
+	Description    string   `json:"description"`
+	Price          float64  `json:"price"`
+	EffectivePrice *float64 `json:"effective_price"`
+	Stock          int      `json:"stock"`
+	PublishedAt    *string  `json:"published_at"`
+	AuthorID       string   `json:"author_id"`
+	CategoryID     string   `json:"category_id"`
+}
+
+// view normalizes a REST book
+func (b restBook) view() bookView {
+	v := bookView{
+		ID:             b.ID,
+		Title:          b.Title,
+		ISBN:           b.ISBN,
+		Description:    b.Description,
+		Price:          b.Price,
+		EffectivePrice: b.Price,
+		Stock:          b.Stock,
+		AuthorID:       b.AuthorID,
+		CategoryID:     b.CategoryID,
+	}
+	if b.EffectivePrice != nil {
+		v.EffectivePrice = *b.EffectivePrice
+	}
+	if b.PublishedAt != nil {
+		v.PublishedAt = normalizeTime(*b.PublishedAt)
+	}
+	return v
+}
+
+// protoBookView normalizes a gRPC book
+func protoBookView(b *pb.Book) bookView {
+	if b == nil {
+		return bookView{}
+	}
+	return bookView{
+		ID:             b.Id,
+		Title:          b.Title,
+		ISBN:           b.Isbn,
+		Description:    b.Description,
+		Price:          b.Price,
+		EffectivePrice: b.EffectivePrice,
+		Stock:          int(b.Stock),
+		PublishedAt:    normalizeTime(b.PublishedAt),
+		AuthorID:       b.AuthorId,
+		CategoryID:     b.CategoryId,
+	}
+}
+
+// inputView is the book a fixture should read back as
+func (c *checker) inputView(in bookInput) bookView {
+	return bookView{
+		Title:          in.Title,
+		ISBN:           in.ISBN,
+		Description:    in.Description,
+		Price:          in.Price,
+		EffectivePrice: in.Price,
+		Stock:          in.Stock,
+		PublishedAt:    in.PublishedAt.In(timestamps.PublicationZone()).Format(timestamps.DateLayout),
+		AuthorID:       c.fixtures.AuthorID,
+		CategoryID:     c.fixtures.CategoryID,
+	}
+}
+
+// newBook returns a fixture book with an unused ISBN
+func (c *checker) newBook() bookInput {
+	return bookInput{
+		Title:       "Contract Fixture",
+		ISBN:        c.nextISBN(),
+		Description: "A book created by the contract checks",
+		Price:       24.5,
+		Stock:       7,
+		PublishedAt: time.Date(2021, time.March, 14, 9, 30, 0, 0, time.UTC),
+	}
+}
+
+// createFixtures creates the author and category fixture books belong to
+func (c *checker) createFixtures() (*fixtures, error) {
+	suffix := randomID()[:8]
+	var author, category struct {
+		ID string `json:"id"`
+	}
+
+	resp, err := c.env.Request(http.MethodPost, "/authors", map[string]string{
+		"name":  "Contract Author " + suffix,
+		"email": "contract-" + suffix + "@example.com",
+	}, c.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create author fixture: %d %s", resp.StatusCode, resp.Message)
+	}
+	if err := resp.Decode(&author); err != nil {
+		return nil, err
+	}
+
+	resp, err = c.env.Request(http.MethodPost, "/categories", map[string]string{
+		"name": "Contract " + suffix,
+	}, c.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create category fixture: %d %s", resp.StatusCode, resp.Message)
+	}
+	if err := resp.Decode(&category); err != nil {
+		return nil, err
+	}
+
+	return &fixtures{AuthorID: author.ID, CategoryID: category.ID}, nil
+}
+
+// restCreateBook creates a book through the REST API
+func (c *checker) restCreateBook(in bookInput) (bookView, int, error) {
+	resp, err := c.env.Request(http.MethodPost, "/books", map[string]interface{}{
+		"title":        in.Title,
+		"isbn":         in.ISBN,
+		"description":  in.Description,
+		"price":        in.Price,
+		"stock":        in.Stock,
+		"published_at": in.PublishedAt.Format(time.RFC3339),
+		"author_id":    c.fixtures.AuthorID,
+		"category_id":  c.fixtures.CategoryID,
+	}, c.token)
+	if err != nil {
+		return bookView{}, 0, err
+	}
+	var book restBook
+	// A repeated create returns the existing book with 200
+	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK {
+		if err := resp.Decode(&book); err != nil {
+			return bookView{}, 0, err
+		}
+	}
+	return book.view(), resp.StatusCode, nil
+}
+
+// grpcCreateBook creates a book through the gRPC API
+func (c *checker) grpcCreateBook(in bookInput) (bookView, error) {
+	resp, err := c.env.Books().CreateBook(c.ctx, &pb.CreateBookRequest{
+		Title:       in.Title,
+		Isbn:        in.ISBN,
+		Description: in.Description,
+		Price:       in.Price,
+		Stock:       int32(in.Stock),
+		PublishedAt: in.PublishedAt.Format(time.RFC3339),
+		AuthorId:    c.fixtures.AuthorID,
+		CategoryId:  c.fixtures.CategoryID,
+	})
+	if err != nil {
+		return bookView{}, err
+	}
+	return protoBookView(resp.Book), nil
+}
+
+// restGetBook reads a book through the REST API
+func (c *checker) restGetBook(id string) (bookView, int, error) {
+	resp, err := c.env.Request(http.MethodGet, "/books/"+id, nil, "")
+	if err != nil {
+		return bookView{}, 0, err
+	}
+	var book restBook
+	if resp.StatusCode == http.StatusOK {
+		if err := resp.Decode(&book); err != nil {
+			return bookView{}, 0, err
+		}
+	}
+	return book.view(), resp.StatusCode, nil
+}
+
+// grpcGetBook reads a book through the gRPC API
+func (c *checker) grpcGetBook(id string) (bookView, error) {
+	resp, err := c.env.Books().GetBook(c.ctx, &pb.GetBookRequest{Id: id})
+	if err != nil {
+		return bookView{}, err
+	}
+	return protoBookView(resp.Book), nil
+}
+
+// restUpdateBook updates a book through the REST API
+func (c *checker) restUpdateBook(id string, body map[string]interface{}) (int, error) {
+	resp, err := c.env.Request(http.MethodPut, "/books/"+id, body, c.token)
+	if err != nil {
+		return 0, err
+	}
+	return resp.StatusCode, nil
+}
+
+// readBoth reads a book through both transports, recording a problem if they
+// disagree, and returns the REST view
+func (c *checker) readBoth(context, id string) (bookView, error) {
+	restView, restStatus, err := c.restGetBook(id)
+	if err != nil {
+		return bookView{}, err
+	}
+	grpcView, grpcErr := c.grpcGetBook(id)
+	c.expectOutcome(context+": get book", restStatus, grpcErr)
+	if restStatus == http.StatusOK && grpcErr == nil {
+		c.compareBooks(context+": gRPC read compared with REST read", restView, grpcView)
+	}
+	return restView, nil
+}
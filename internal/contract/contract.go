@@ -0,0 +1,184 @@
+// Package contract checks that the REST and gRPC APIs agree. Each scenario
+// performs the same operations on the same fixtures through both transports
+// and reports any difference in outcome (status and error class) or in the
+// field values returned, such as an update through one transport changing
+// fields the other leaves alone.
+package contract
+
+import (
+	"bookstore-api/internal/testutil"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Result is the outcome of a scenario
+type Result struct {
+	Scenario string   `json:"scenario"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Passed reports whether the scenario found no differences
+func (r Result) Passed() bool {
+	return len(r.Problems) == 0
+}
+
+// scenario is a contract check
+type scenario struct {
+	name string
+	run  func(c *checker) error
+}
+
+// scenarios lists the contract checks in the order they run
+var scenarios = []scenario{
+	{"book fields match across transports", checkBookFields},
+	{"partial book updates leave other fields unchanged", checkPartialUpdates},
+	{"book stock and price can be set to zero", checkZeroUpdates},
+	{"missing records are not found", checkNotFound},
+	{"invalid book input is rejected", checkInvalidInput},
+	{"duplicate isbns replay or conflict", checkDuplicateISBN},
+}
+
+// statusCodes maps REST statuses to the gRPC code expected for the same outcome
+var statusCodes = map[int]codes.Code{
+	http.StatusOK:                  codes.OK,
+	http.StatusCreated:             codes.OK,
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// checker runs scenarios against an environment
+type checker struct {
+	ctx      context.Context
+	env      *testutil.Env
+	token    string
+	fixtures *fixtures
+	problems []string
+	// isbns numbers the ISBNs handed out to fixture books
+	isbns int
+}
+
+// Run runs every scenario against env and returns their results. An error is
+// returned only if the fixtures cannot be created.
+func Run(ctx context.Context, env *testutil.Env) ([]Result, error) {
+	token, err := env.AdminKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin key: %w", err)
+	}
+
+	c := &checker{ctx: ctx, env: env, token: token}
+	if c.fixtures, err = c.createFixtures(); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(scenarios))
+	for _, sc := range scenarios {
+		c.problems = nil
+		if err := sc.run(c); err != nil {
+			c.problems = append(c.problems, err.Error())
+		}
+		results = append(results, Result{Scenario: sc.name, Problems: c.problems})
+	}
+	return results, nil
+}
+
+// problem records a difference found by the current scenario
+func (c *checker) problem(format string, args ...interface{}) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+// expectOutcome records a problem unless the REST status and gRPC error
+// describe the same outcome
+func (c *checker) expectOutcome(operation string, restStatus int, grpcErr error) {
+	want, ok := statusCodes[restStatus]
+	if !ok {
+		c.problem("%s: unexpected REST status %d", operation, restStatus)
+		return
+	}
+	if got := status.Code(grpcErr); got != want {
+		c.problem("%s: REST returned %d but gRPC returned %s (want %s)", operation, restStatus, got, want)
+	}
+}
+
+// nextISBN returns an unused valid ISBN-13
+func (c *checker) nextISBN() string {
+	c.isbns++
+	digits := fmt.Sprintf("979%09d", c.isbns)
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	return fmt.Sprintf("%s%d", digits, (10-sum%10)%10)
+}
+
+// compareBooks records a problem for each field that differs between books
+// read through different paths
+func (c *checker) compareBooks(context string, want, got bookView, ignore ...string) {
+	skip := make(map[string]bool, len(ignore))
+	for _, field := range ignore {
+		skip[field] = true
+	}
+
+	for _, f := range []struct {
+		name      string
+		want, got interface{}
+	}{
+		{"id", want.ID, got.ID},
+		{"title", want.Title, got.Title},
+		{"isbn", want.ISBN, got.ISBN},
+		{"description", want.Description, got.Description},
+		{"price", want.Price, got.Price},
+		{"effective_price", want.EffectivePrice, got.EffectivePrice},
+		{"stock", want.Stock, got.Stock},
+		{"published_at", want.PublishedAt, got.PublishedAt},
+		{"author_id", want.AuthorID, got.AuthorID},
+		{"category_id", want.CategoryID, got.CategoryID},
+	} {
+		if skip[f.name] {
+			continue
+		}
+		if !sameValue(f.want, f.got) {
+			c.problem("%s: %s is %v, expected %v", context, f.name, f.got, f.want)
+		}
+	}
+}
+
+// sameValue compares field values, allowing for decimal rounding of prices
+func sameValue(a, b interface{}) bool {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			return math.Abs(x-y) < 0.005
+		}
+	}
+	return a == b
+}
+
+// normalizeTime formats an RFC 3339 timestamp in UTC so equal instants
+// compare equal, or returns it unchanged if it does not parse, as dates do
+func normalizeTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// randomID returns an ID no fixture has
+func randomID() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,40 @@
+package contract
+
+import (
+	"bookstore-api/internal/testutil"
+	"context"
+	"os"
+	"testing"
+)
+
+// env serves the APIs the scenarios compare
+var env *testutil.Env
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.Main(m, &env))
+}
+
+// TestContract runs each scenario as a subtest, reporting every difference it
+// finds between the transports
+func TestContract(t *testing.T) {
+	token, err := env.AdminKey()
+	if err != nil {
+		t.Fatalf("failed to create admin key: %v", err)
+	}
+	c := &checker{ctx: context.Background(), env: env, token: token}
+	if c.fixtures, err = c.createFixtures(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			c.problems = nil
+			if err := sc.run(c); err != nil {
+				t.Fatal(err)
+			}
+			for _, problem := range c.problems {
+				t.Error(problem)
+			}
+		})
+	}
+}
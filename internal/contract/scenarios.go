@@ -0,0 +1,243 @@
+package contract
+
+import (
+	pb "bookstore-api/proto"
+	"fmt"
+	"net/http"
+)
+
+// transports names the APIs in problems
+var transports = []string{"REST", "gRPC"}
+
+// checkBookFields creates the same fixture through both transports and
+// compares what each returns on create and on read
+func checkBookFields(c *checker) error {
+	restIn, grpcIn := c.newBook(), c.newBook()
+
+	restView, restStatus, err := c.restCreateBook(restIn)
+	if err != nil {
+		return err
+	}
+	grpcView, grpcErr := c.grpcCreateBook(grpcIn)
+	c.expectOutcome("create book", restStatus, grpcErr)
+	if restStatus != http.StatusCreated || grpcErr != nil {
+		return nil
+	}
+
+	c.compareBooks("REST create response", c.inputView(restIn), restView, "id")
+	c.compareBooks("gRPC create response", c.inputView(grpcIn), grpcView, "id")
+
+	for i, created := range []bookView{restView, grpcView} {
+		context := fmt.Sprintf("book created through %s", transports[i])
+		read, err := c.readBoth(context, created.ID)
+		if err != nil {
+			return err
+		}
+		c.compareBooks(context+": read compared with create response", created, read)
+	}
+	return nil
+}
+
+// checkPartialUpdates changes only a book's description through each
+// transport and checks no other field changed
+func checkPartialUpdates(c *checker) error {
+	for _, transport := range transports {
+		created, restStatus, err := c.restCreateBook(c.newBook())
+		if err != nil {
+			return err
+		}
+		if restStatus != http.StatusCreated {
+			return fmt.Errorf("failed to create book fixture: %d", restStatus)
+		}
+
+		want := created
+		want.Description = "Description updated through " + transport
+		if err := c.updateBook(transport, created.ID, map[string]interface{}{"description": want.Description},
+			&pb.UpdateBookRequest{Id: created.ID, Description: want.Description}); err != nil {
+			return err
+		}
+
+		got, err := c.readBoth(transport+" description update", created.ID)
+		if err != nil {
+			return err
+		}
+		c.compareBooks(transport+" description update", want, got)
+	}
+	return nil
+}
+
+// checkZeroUpdates sets a book's price and stock to zero through each
+// transport and checks both were stored
+func checkZeroUpdates(c *checker) error {
+	for _, transport := range transports {
+		created, restStatus, err := c.restCreateBook(c.newBook())
+		if err != nil {
+			return err
+		}
+		if restStatus != http.StatusCreated {
+			return fmt.Errorf("failed to create book fixture: %d", restStatus)
+		}
+
+		want := created
+		want.Price, want.EffectivePrice, want.Stock = 0, 0, 0
+		zeroPrice, zeroStock := float64(0), int32(0)
+		if err := c.updateBook(transport, created.ID, map[string]interface{}{"price": 0, "stock": 0},
+			&pb.UpdateBookRequest{Id: created.ID, Price: &zeroPrice, Stock: &zeroStock}); err != nil {
+			return err
+		}
+
+		got, err := c.readBoth(transport+" zero update", created.ID)
+		if err != nil {
+			return err
+		}
+		c.compareBooks(transport+" zero update", want, got)
+	}
+	return nil
+}
+
+// checkNotFound reads, updates, and deletes records that do not exist
+func checkNotFound(c *checker) error {
+	id := randomID()
+
+	_, restStatus, err := c.restGetBook(id)
+	if err != nil {
+		return err
+	}
+	_, grpcErr := c.grpcGetBook(id)
+	c.expectOutcome("get missing book", restStatus, grpcErr)
+
+	restStatus, err = c.restUpdateBook(id, map[string]interface{}{"title": "Missing"})
+	if err != nil {
+		return err
+	}
+	_, grpcErr = c.env.Books().UpdateBook(c.ctx, &pb.UpdateBookRequest{Id: id, Title: "Missing"})
+	c.expectOutcome("update missing book", restStatus, grpcErr)
+
+	resp, err := c.env.Request(http.MethodDelete, "/books/"+id, nil, c.token)
+	if err != nil {
+		return err
+	}
+	_, grpcErr = c.env.Books().DeleteBook(c.ctx, &pb.DeleteBookRequest{Id: id})
+	c.expectOutcome("delete missing book", resp.StatusCode, grpcErr)
+
+	resp, err = c.env.Request(http.MethodGet, "/authors/"+id, nil, "")
+	if err != nil {
+		return err
+	}
+	_, grpcErr = c.env.Authors().GetAuthor(c.ctx, &pb.GetAuthorRequest{Id: id})
+	c.expectOutcome("get missing author", resp.StatusCode, grpcErr)
+
+	resp, err = c.env.Request(http.MethodGet, "/categories/"+id, nil, "")
+	if err != nil {
+		return err
+	}
+	_, grpcErr = c.env.Categories().GetCategory(c.ctx, &pb.GetCategoryRequest{Id: id})
+	c.expectOutcome("get missing category", resp.StatusCode, grpcErr)
+	return nil
+}
+
+// checkInvalidInput creates books with invalid fields through both transports
+func checkInvalidInput(c *checker) error {
+	for _, tc := range []struct {
+		name   string
+		modify func(rest map[string]interface{}, grpc *pb.CreateBookRequest)
+	}{
+		{"invalid isbn", func(rest map[string]interface{}, grpc *pb.CreateBookRequest) {
+			rest["isbn"], grpc.Isbn = "123", "123"
+		}},
+		{"invalid published_at", func(rest map[string]interface{}, grpc *pb.CreateBookRequest) {
+			rest["published_at"], grpc.PublishedAt = "yesterday", "yesterday"
+		}},
+		{"invalid author_id", func(rest map[string]interface{}, grpc *pb.CreateBookRequest) {
+			rest["author_id"], grpc.AuthorId = "not-a-uuid", "not-a-uuid"
+		}},
+	} {
+		in := c.newBook()
+		rest := map[string]interface{}{
+			"title":       in.Title,
+			"isbn":        in.ISBN,
+			"price":       in.Price,
+			"stock":       in.Stock,
+			"author_id":   c.fixtures.AuthorID,
+			"category_id": c.fixtures.CategoryID,
+		}
+		grpcReq := &pb.CreateBookRequest{
+			Title:      in.Title,
+			Isbn:       in.ISBN,
+			Price:      in.Price,
+			Stock:      int32(in.Stock),
+			AuthorId:   c.fixtures.AuthorID,
+			CategoryId: c.fixtures.CategoryID,
+		}
+		tc.modify(rest, grpcReq)
+
+		resp, err := c.env.Request(http.MethodPost, "/books", rest, c.token)
+		if err != nil {
+			return err
+		}
+		_, grpcErr := c.env.Books().CreateBook(c.ctx, grpcReq)
+		c.expectOutcome("create book with "+tc.name, resp.StatusCode, grpcErr)
+		if resp.StatusCode != http.StatusBadRequest {
+			c.problem("create book with %s: REST returned %d, expected %d", tc.name, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+	return nil
+}
+
+// checkDuplicateISBN repeats a book create, which returns the existing book,
+// and creates a different book with the same ISBN, which conflicts, through
+// both transports
+func checkDuplicateISBN(c *checker) error {
+	in := c.newBook()
+	created, restStatus, err := c.restCreateBook(in)
+	if err != nil {
+		return err
+	} else if restStatus != http.StatusCreated {
+		return fmt.Errorf("failed to create book fixture: %d", restStatus)
+	}
+
+	restView, restStatus, err := c.restCreateBook(in)
+	if err != nil {
+		return err
+	}
+	grpcView, grpcErr := c.grpcCreateBook(in)
+	c.expectOutcome("repeat book create", restStatus, grpcErr)
+	if restStatus != http.StatusOK {
+		c.problem("repeat book create: REST returned %d, expected %d", restStatus, http.StatusOK)
+	} else if grpcErr == nil {
+		c.compareBooks("repeat book create through REST", created, restView)
+		c.compareBooks("repeat book create through gRPC", created, grpcView)
+	}
+
+	in.Title += " (Second Edition)"
+	_, restStatus, err = c.restCreateBook(in)
+	if err != nil {
+		return err
+	}
+	_, grpcErr = c.grpcCreateBook(in)
+	c.expectOutcome("create book with duplicate isbn", restStatus, grpcErr)
+	if restStatus != http.StatusConflict {
+		c.problem("create book with duplicate isbn: REST returned %d, expected %d", restStatus, http.StatusConflict)
+	}
+	return nil
+}
+
+// updateBook applies an update through the named transport, recording a
+// problem if it fails
+func (c *checker) updateBook(transport, id string, rest map[string]interface{}, grpcReq *pb.UpdateBookRequest) error {
+	if transport == "REST" {
+		restStatus, err := c.restUpdateBook(id, rest)
+		if err != nil {
+			return err
+		}
+		if restStatus != http.StatusOK {
+			c.problem("REST update returned %d", restStatus)
+		}
+		return nil
+	}
+
+	if _, err := c.env.Books().UpdateBook(c.ctx, grpcReq); err != nil {
+		c.problem("gRPC update failed: %v", err)
+	}
+	return nil
+}
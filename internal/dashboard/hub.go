@@ -0,0 +1,341 @@
+package dashboard
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Message types pushed to dashboard clients
+const (
+	MessageMetrics       = "metrics"
+	MessageLowStockAlert = "low_stock_alert"
+)
+
+// maxLowStockBooks caps the number of low stock books included in each metrics message
+const maxLowStockBooks = 10
+
+// clientBuffer is the number of messages queued per client before it is dropped
+const clientBuffer = 16
+
+var (
+	hub  *Hub
+	once sync.Once
+)
+
+// Message is the envelope of every message sent to dashboard clients
+type Message struct {
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// LowStockBook is a book at or below the low stock threshold
+type LowStockBook struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Stock int       `json:"stock"`
+}
+
+// Metrics is a point-in-time snapshot of store activity
+type Metrics struct {
+	ActiveSessions    int            `json:"active_sessions"`
+	DashboardClients  int            `json:"dashboard_clients"`
+	StreamClients     int            `json:"stream_clients"`
+	EventsPerMinute   int64          `json:"events_per_minute"`
+	JobsPending       int64          `json:"jobs_pending"`
+	JobsFailed        int64          `json:"jobs_failed"`
+	LowStockThreshold int            `json:"low_stock_threshold"`
+	LowStockCount     int64          `json:"low_stock_count"`
+	LowStockBooks     []LowStockBook `json:"low_stock_books"`
+}
+
+// LowStockAlert is pushed when a stock change takes a book to or below the threshold
+type LowStockAlert struct {
+	BookID        uuid.UUID `json:"book_id"`
+	Title         string    `json:"title"`
+	PreviousStock int       `json:"previous_stock"`
+	Stock         int       `json:"stock"`
+	Threshold     int       `json:"threshold"`
+}
+
+// Client is a connected dashboard that receives encoded messages on Send
+type Client struct {
+	Send   chan []byte
+	tenant *models.Tenant
+}
+
+// Hub pushes periodic metrics and low stock alerts to connected dashboard clients
+type Hub struct {
+	db        *gorm.DB
+	interval  time.Duration
+	threshold int
+	stream    *events.Stream
+	mu        sync.Mutex
+	clients   map[*Client]struct{}
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewHub creates a new dashboard hub. The event stream, if set, is used to count SSE sessions.
+// db must be allowed to query across tenants; every query filters by tenant itself.
+func NewHub(db *gorm.DB, cfg *config.Config, stream *events.Stream) *Hub {
+	interval := cfg.Dashboard.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &Hub{
+		db:        db,
+		interval:  interval,
+		threshold: cfg.Scheduler.LowStockThreshold,
+		stream:    stream,
+		clients:   make(map[*Client]struct{}),
+	}
+}
+
+// InitializeHub initializes the shared dashboard hub on db
+func InitializeHub(db *gorm.DB, cfg *config.Config) {
+	once.Do(func() {
+		hub = NewHub(tenancy.System(db), cfg, events.GetStream())
+	})
+}
+
+// GetHub returns the shared dashboard hub
+func GetHub() *Hub {
+	if hub == nil {
+		log.Fatal("Dashboard hub not initialized. Call InitializeHub first.")
+	}
+	return hub
+}
+
+// Register adds a dashboard client for the tenant
+func (h *Hub) Register(tenant *models.Tenant) *Client {
+	client := &Client{Send: make(chan []byte, clientBuffer), tenant: tenant}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[client] = struct{}{}
+	return client
+}
+
+// Unregister removes a dashboard client and closes its channel
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.Send)
+	}
+}
+
+// Start begins pushing metrics on the configured interval
+func (h *Hub) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	h.wg.Add(1)
+	go h.loop(ctx)
+	log.Println("Started dashboard hub")
+}
+
+// Stop stops pushing metrics and disconnects all clients
+func (h *Hub) Stop() {
+	if h.cancel == nil {
+		return
+	}
+	log.Println("Stopping dashboard hub...")
+	h.cancel()
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		delete(h.clients, client)
+		close(client.Send)
+	}
+}
+
+// loop collects and broadcasts metrics while any client is connected
+func (h *Hub) loop(ctx context.Context) {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, tenant := range h.tenants() {
+			metrics, err := h.Metrics(tenant)
+			if err != nil {
+				log.Printf("Failed to collect dashboard metrics for tenant %s: %v", tenant.Slug, err)
+				continue
+			}
+			h.broadcast(tenant.ID, MessageMetrics, metrics)
+		}
+	}
+}
+
+// Metrics collects the current metrics snapshot for a tenant. Job counts cover
+// the shared job queue.
+func (h *Hub) Metrics(tenant *models.Tenant) (*Metrics, error) {
+	threshold := tenant.LowStockThresholdOr(h.threshold)
+	metrics := &Metrics{
+		DashboardClients:  h.clientCount(tenant.ID),
+		LowStockThreshold: threshold,
+		LowStockBooks:     []LowStockBook{},
+	}
+	if h.stream != nil {
+		metrics.StreamClients = h.stream.SubscriberCount(tenant.ID)
+	}
+	metrics.ActiveSessions = metrics.DashboardClients + metrics.StreamClients
+
+	if err := h.db.Model(&models.OutboxEvent{}).
+		Where("tenant_id = ? AND created_at >= ?", tenant.ID, time.Now().Add(-time.Minute)).
+		Count(&metrics.EventsPerMinute).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recent events: %w", err)
+	}
+	if err := h.db.Model(&models.Job{}).Where("status = ?", models.JobStatusPending).Count(&metrics.JobsPending).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pending jobs: %w", err)
+	}
+	if err := h.db.Model(&models.Job{}).Where("status = ?", models.JobStatusFailed).Count(&metrics.JobsFailed).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed jobs: %w", err)
+	}
+
+	lowStock := h.db.Model(&models.Book{}).Where("tenant_id = ? AND stock <= ?", tenant.ID, threshold)
+	if err := lowStock.Count(&metrics.LowStockCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count low stock books: %w", err)
+	}
+	if err := lowStock.Select("id", "title", "stock").
+		Order("stock ASC").
+		Limit(maxLowStockBooks).
+		Scan(&metrics.LowStockBooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get low stock books: %w", err)
+	}
+	return metrics, nil
+}
+
+// Consume pushes a low stock alert to the event tenant's clients when a stock
+// change crosses the tenant's threshold
+func (h *Hub) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	if event.EventType != events.BookStockChanged {
+		return nil
+	}
+	tenant := h.tenant(event.TenantID)
+	if tenant == nil {
+		return nil
+	}
+
+	var payload events.StockChangedPayload
+	if err := event.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid stock changed payload: %w", err)
+	}
+	threshold := tenant.LowStockThresholdOr(h.threshold)
+	if payload.Stock > threshold || payload.PreviousStock <= threshold {
+		return nil
+	}
+
+	var book models.Book
+	if err := h.db.WithContext(ctx).Select("id", "title").
+		First(&book, "tenant_id = ? AND id = ?", tenant.ID, payload.BookID).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to get book: %w", err)
+	}
+
+	h.broadcast(tenant.ID, MessageLowStockAlert, LowStockAlert{
+		BookID:        payload.BookID,
+		Title:         book.Title,
+		PreviousStock: payload.PreviousStock,
+		Stock:         payload.Stock,
+		Threshold:     threshold,
+	})
+	return nil
+}
+
+// Encode builds an encoded message for sending to a client
+func Encode(messageType string, data interface{}) ([]byte, error) {
+	return json.Marshal(Message{
+		Type:      messageType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	})
+}
+
+// broadcast sends a message to every client of the tenant, dropping clients that
+// have fallen behind
+func (h *Hub) broadcast(tenantID uuid.UUID, messageType string, data interface{}) {
+	msg, err := Encode(messageType, data)
+	if err != nil {
+		log.Printf("Failed to encode dashboard message: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		if client.tenant.ID != tenantID {
+			continue
+		}
+		select {
+		case client.Send <- msg:
+		default:
+			log.Printf("Dropping slow dashboard client")
+			delete(h.clients, client)
+			close(client.Send)
+		}
+	}
+}
+
+// clientCount returns the number of clients connected for the tenant
+func (h *Hub) clientCount(tenantID uuid.UUID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := 0
+	for client := range h.clients {
+		if client.tenant.ID == tenantID {
+			count++
+		}
+	}
+	return count
+}
+
+// tenants returns the tenants with at least one connected client
+func (h *Hub) tenants() []*models.Tenant {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	seen := make(map[uuid.UUID]bool)
+	var tenants []*models.Tenant
+	for client := range h.clients {
+		if !seen[client.tenant.ID] {
+			seen[client.tenant.ID] = true
+			tenants = append(tenants, client.tenant)
+		}
+	}
+	return tenants
+}
+
+// tenant returns the tenant of a connected client, or nil if the tenant has none
+func (h *Hub) tenant(tenantID uuid.UUID) *models.Tenant {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		if client.tenant.ID == tenantID {
+			return client.tenant
+		}
+	}
+	return nil
+}
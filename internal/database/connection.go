@@ -1,7 +1,11 @@
 package database
 
 import (
+	"bookstore-api/internal/access"
 	"bookstore-api/internal/config"
+	"bookstore-api/internal/slowquery"
+	"bookstore-api/internal/slug"
+	"bookstore-api/internal/tenancy"
 	"fmt"
 	"log"
 	"sync"
@@ -9,6 +13,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Connection pool limits
+const (
+	maxIdleConns = 10
+	maxOpenConns = 100
+)
+
 var (
 	db   *gorm.DB
 	once sync.Once
@@ -31,6 +41,28 @@ func InitializeDB(cfg *config.Config) error {
 			err = fmt.Errorf("failed to initialize database: %w", err)
 			return
 		}
+		// Scope queries on tenant-scoped models to the tenant in their context
+		if err = db.Use(tenancy.Plugin{}); err != nil {
+			err = fmt.Errorf("failed to register tenancy plugin: %w", err)
+			return
+		}
+		// Restrict queries to the rows the request's principal may see
+		if err = db.Use(access.Plugin{}); err != nil {
+			err = fmt.Errorf("failed to register access plugin: %w", err)
+			return
+		}
+		// Give new books, authors, and categories slugs
+		if err = db.Use(slug.Plugin{}); err != nil {
+			err = fmt.Errorf("failed to register slug plugin: %w", err)
+			return
+		}
+		// Log statements slower than the threshold
+		if cfg.SlowQuery.Threshold > 0 {
+			slowquery.Initialize(cfg)
+			if err = db.Use(slowquery.NewPlugin(cfg.SlowQuery, slowquery.GetLog())); err != nil {
+				err = fmt.Errorf("failed to register slow query plugin: %w", err)
+			}
+		}
 	})
 	return err
 }
@@ -52,7 +84,6 @@ func HealthCheck() error {
 	if db == nil {
 		return fmt.Errorf("database not initialized")
 	}
-
 	sqlDB, err := db.DB()
 	if err != nil {
 		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
@@ -270,7 +270,7 @@ func ValidateMigrations() error {
 func Connect(cfg *config.Config) (*gorm.DB, error) {
 	// First try to connect to the specific database
 	dsn := cfg.GetDSN()
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: NewQueryLogger(cfg)})
 	if err != nil {
 		// If database doesn't exist, try to create it
 		if strings.Contains(err.Error(), "does not exist") {
@@ -297,7 +297,7 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 			log.Printf("Database %s created successfully", cfg.Database.DBName)
 
 			// Now try to connect to the newly created database
-			db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+			db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: NewQueryLogger(cfg)})
 			if err != nil {
 				return nil, fmt.Errorf("failed to connect to newly created database: %w", err)
 			}
@@ -313,8 +313,8 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetMaxIdleConns(maxIdleConns)
+	sqlDB.SetMaxOpenConns(maxOpenConns)
 
 	log.Println("Database connection established successfully")
 	return db, nil
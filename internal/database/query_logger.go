@@ -0,0 +1,241 @@
+package database
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/tracing"
+	"bookstore-api/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// redactedParam replaces the values bound to redacted columns
+const redactedParam = "[REDACTED]"
+
+// defaultRedactColumns are the personal and secret columns whose values are
+// always masked in logged statements
+var defaultRedactColumns = []string{
+	"password", "password_hash", "secret", "token", "token_hash",
+	"refresh_token_hash", "previous_token_hash", "key_hash", "email",
+	"email_hash", "phone", "address", "ip_address", "user_agent",
+}
+
+var (
+	// insertPattern matches an INSERT's column list and what follows VALUES
+	insertPattern = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*(.*)$`)
+	// insertEndPattern matches the clauses that may follow an INSERT's values
+	insertEndPattern = regexp.MustCompile(`(?i)\s(ON\s+CONFLICT|RETURNING)\s`)
+	// comparisonPattern matches a column compared with or set to a
+	// placeholder, such as "email_hash" = $1
+	comparisonPattern = regexp.MustCompile(`(?i)"?([a-z_][a-z0-9_]*)"?\s*(?:=|<>|!=|>=|<=|>|<|(?:NOT\s+)?I?LIKE)\s*\$(\d+)`)
+	// inPattern matches a column compared with a list of placeholders, such
+	// as "token_hash" IN ($1,$2)
+	inPattern = regexp.MustCompile(`(?i)"?([a-z_][a-z0-9_]*)"?\s+(?:NOT\s+)?IN\s*\(([^)]*)\)`)
+	// placeholderPattern matches a placeholder
+	placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+)
+
+// QueryLogger is the GORM logger. Each statement is recorded on the span of
+// its context and logged at debug level with its rows affected and latency;
+// failed statements are logged at warn level, since callers decide whether
+// the failure is an error. Values bound to personal and secret columns are
+// masked wherever GORM renders a statement with its parameters, including the
+// slow query log.
+type QueryLogger struct {
+	level  logger.LogLevel
+	redact map[string]bool
+}
+
+// NewQueryLogger creates the GORM logger
+func NewQueryLogger(cfg *config.Config) *QueryLogger {
+	l := &QueryLogger{level: logger.Info, redact: make(map[string]bool)}
+	for _, column := range defaultRedactColumns {
+		l.redact[column] = true
+	}
+	for _, column := range strings.Split(cfg.Logging.QueryRedactColumns, ",") {
+		if column = strings.ToLower(strings.TrimSpace(column)); column != "" {
+			l.redact[column] = true
+		}
+	}
+	return l
+}
+
+// LogMode returns a copy of the logger at level, as sessions with their own
+// logging level use
+func (l *QueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs a GORM message at info level
+func (l *QueryLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, logger.Info, slog.LevelInfo, msg, data...)
+}
+
+// Warn logs a GORM message at warn level
+func (l *QueryLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, logger.Warn, slog.LevelWarn, msg, data...)
+}
+
+// Error logs a GORM message at error level
+func (l *QueryLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, logger.Error, slog.LevelError, msg, data...)
+}
+
+// log logs a message when the logger's GORM level includes level
+func (l *QueryLogger) log(ctx context.Context, level logger.LogLevel, slogLevel slog.Level, msg string, data ...interface{}) {
+	if l.level < level {
+		return
+	}
+	utils.Slog().Log(ctx, slogLevel, fmt.Sprintf(msg, data...))
+}
+
+// Trace records a statement on the span of ctx and logs it. The statement is
+// only rendered when it is traced or logged at the current log level.
+func (l *QueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	level := slog.LevelDebug
+	if failed {
+		level = slog.LevelWarn
+	}
+
+	span := tracing.FromContext(ctx)
+	logged := utils.Slog().Enabled(ctx, level)
+	if span == nil && !logged {
+		return
+	}
+
+	sql, rows := fc()
+	durationMs := float64(elapsed.Microseconds()) / 1000
+	if span != nil {
+		attributes := map[string]interface{}{
+			"db.statement":     sql,
+			"db.rows_affected": rows,
+			"db.duration_ms":   durationMs,
+		}
+		if failed {
+			attributes["error"] = err.Error()
+		}
+		span.AddEvent("db.query", attributes)
+	}
+	if !logged {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", durationMs),
+	}
+	if failed {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if span != nil {
+		attrs = append(attrs, span.LogAttrs()...)
+	}
+	utils.Slog().LogAttrs(ctx, level, "Database query", attrs...)
+}
+
+// ParamsFilter masks the parameters bound to redacted columns. GORM calls it
+// before rendering a statement with its parameters for the logger.
+func (l *QueryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	positions := l.redactedPositions(sql)
+	if len(positions) == 0 {
+		return sql, params
+	}
+	filtered := append([]interface{}(nil), params...)
+	for position := range positions {
+		if position >= 1 && position <= len(filtered) {
+			filtered[position-1] = redactedParam
+		}
+	}
+	return sql, filtered
+}
+
+// redactedPositions returns the positions of the placeholders bound to
+// redacted columns: values inserted into them, and values they are compared
+// with or set to
+func (l *QueryLogger) redactedPositions(sql string) map[int]bool {
+	positions := make(map[int]bool)
+	mark := func(column, placeholder string) {
+		if !l.redact[strings.ToLower(column)] {
+			return
+		}
+		if position, err := strconv.Atoi(placeholder); err == nil {
+			positions[position] = true
+		}
+	}
+
+	if match := insertPattern.FindStringSubmatch(sql); match != nil {
+		columns := strings.Split(match[1], ",")
+		for i := range columns {
+			columns[i] = strings.Trim(strings.TrimSpace(columns[i]), `"`)
+		}
+		values := match[2]
+		if end := insertEndPattern.FindStringIndex(values); end != nil {
+			values = values[:end[0]]
+		}
+		for _, row := range valueRows(values) {
+			for i, value := range row {
+				if i >= len(columns) {
+					break
+				}
+				if placeholder := placeholderPattern.FindStringSubmatch(value); placeholder != nil && strings.TrimSpace(value) == placeholder[0] {
+					mark(columns[i], placeholder[1])
+				}
+			}
+		}
+	}
+
+	for _, match := range comparisonPattern.FindAllStringSubmatch(sql, -1) {
+		mark(match[1], match[2])
+	}
+	for _, match := range inPattern.FindAllStringSubmatch(sql, -1) {
+		for _, placeholder := range placeholderPattern.FindAllStringSubmatch(match[2], -1) {
+			mark(match[1], placeholder[1])
+		}
+	}
+	return positions
+}
+
+// valueRows splits the rows of an INSERT's VALUES list into their values
+func valueRows(values string) [][]string {
+	var rows [][]string
+	var row []string
+	depth, start := 0, 0
+	for i, r := range values {
+		switch r {
+		case '(':
+			depth++
+			if depth == 1 {
+				row, start = nil, i+1
+			}
+		case ',':
+			if depth == 1 {
+				row = append(row, values[start:i])
+				start = i + 1
+			}
+		case ')':
+			if depth == 1 {
+				rows = append(rows, append(row, values[start:i]))
+			}
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return rows
+}
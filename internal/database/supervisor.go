@@ -0,0 +1,229 @@
+package database
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Database states reported by the supervisor
+const (
+	StateUp         = "up"
+	StateRecovering = "recovering"
+)
+
+// SupervisorStatus is the supervisor's view of the database connection
+type SupervisorStatus struct {
+	State string `json:"state"`
+	// ConsecutiveFailures is the number of failed pings in a row
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	DownSince           *time.Time `json:"down_since,omitempty"`
+	LastRecoveredAt     *time.Time `json:"last_recovered_at,omitempty"`
+	// Recoveries is the number of times the connection was restored
+	Recoveries int `json:"recoveries"`
+}
+
+// Supervisor pings the database in the background. After FailureThreshold
+// failed pings in a row it reports the database as down, so readiness checks
+// fail and the instance is taken out of load balancing, and recycles the
+// connection pool with exponential backoff until a ping succeeds. Recycling
+// closes the pool's idle connections, so connections left broken by a
+// restart or failover of the database are replaced without restarting the
+// process.
+type Supervisor struct {
+	cfg config.DatabaseConfig
+
+	mu     sync.RWMutex
+	status SupervisorStatus
+	// onStateChange, when set, is called when the database goes down and
+	// when it recovers
+	onStateChange func(SupervisorStatus)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	supervisor     *Supervisor
+	supervisorOnce sync.Once
+)
+
+// InitializeSupervisor creates the database supervisor
+func InitializeSupervisor(cfg *config.Config) {
+	supervisorOnce.Do(func() {
+		supervisor = &Supervisor{
+			cfg:    cfg.Database,
+			status: SupervisorStatus{State: StateUp},
+		}
+	})
+}
+
+// GetSupervisor returns the database supervisor, or nil when it was not
+// initialized
+func GetSupervisor() *Supervisor {
+	return supervisor
+}
+
+// Start begins supervising the connection
+func (s *Supervisor) Start() {
+	if s.cfg.HealthInterval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx)
+	log.Printf("Supervising database connection every %s", s.cfg.HealthInterval)
+}
+
+// Stop stops supervising and waits for the supervisor to return
+func (s *Supervisor) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// OnStateChange sets a function called with the new status when the
+// database goes down and when it recovers. It must be set before Start.
+func (s *Supervisor) OnStateChange(fn func(SupervisorStatus)) {
+	s.onStateChange = fn
+}
+
+// Status returns the current state of the connection
+func (s *Supervisor) Status() SupervisorStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Ready returns an error while the database is down
+func (s *Supervisor) Ready() error {
+	status := s.Status()
+	if status.State != StateUp {
+		return fmt.Errorf("database unavailable since %s: %s", status.DownSince.Format(time.RFC3339), status.LastError)
+	}
+	return nil
+}
+
+// run pings the database every interval, recovering the connection when it
+// goes down
+func (s *Supervisor) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.ping(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			s.mu.Lock()
+			s.status.ConsecutiveFailures = 0
+			s.mu.Unlock()
+			continue
+		}
+
+		s.mu.Lock()
+		s.status.ConsecutiveFailures++
+		s.status.LastError = err.Error()
+		failures := s.status.ConsecutiveFailures
+		s.mu.Unlock()
+
+		if failures >= s.cfg.FailureThreshold {
+			s.recover(ctx)
+		}
+	}
+}
+
+// recover marks the database down and recycles the connection pool with
+// exponential backoff until a ping succeeds or ctx is cancelled
+func (s *Supervisor) recover(ctx context.Context) {
+	now := time.Now()
+	s.mu.Lock()
+	s.status.State = StateRecovering
+	s.status.DownSince = &now
+	lastError := s.status.LastError
+	down := s.status
+	s.mu.Unlock()
+	log.Printf("Database unavailable after %d failed pings, reconnecting: %s", s.cfg.FailureThreshold, lastError)
+	if s.onStateChange != nil {
+		s.onStateChange(down)
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		err := s.reconnect(ctx)
+		if err == nil {
+			recovered := time.Now()
+			s.mu.Lock()
+			s.status.State = StateUp
+			s.status.ConsecutiveFailures = 0
+			s.status.DownSince = nil
+			s.status.LastRecoveredAt = &recovered
+			s.status.Recoveries++
+			up := s.status
+			s.mu.Unlock()
+			log.Printf("Database connection recovered after %d attempts (down %s)", attempt, recovered.Sub(now).Round(time.Second))
+			if s.onStateChange != nil {
+				s.onStateChange(up)
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.status.LastError = err.Error()
+		s.mu.Unlock()
+		log.Printf("Database reconnect attempt %d failed, retrying in %s: %v", attempt, backoff, err)
+
+		backoff *= 2
+		if backoff > s.cfg.ReconnectMaxBackoff {
+			backoff = s.cfg.ReconnectMaxBackoff
+		}
+	}
+}
+
+// reconnect closes the pool's idle connections, which may be broken, and
+// pings over a new one
+func (s *Supervisor) reconnect(ctx context.Context) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	sqlDB.SetMaxIdleConns(0)
+	sqlDB.SetMaxIdleConns(maxIdleConns)
+	return s.ping(ctx)
+}
+
+// ping checks the connection, bounded by the ping timeout
+func (s *Supervisor) ping(ctx context.Context) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.PingTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
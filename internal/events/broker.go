@@ -0,0 +1,103 @@
+package events
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the Envelope format published to brokers.
+// Bump it on any breaking change to the envelope or event payloads.
+const SchemaVersion = 1
+
+// Broker types
+const (
+	BrokerNone  = "none"
+	BrokerNATS  = "nats"
+	BrokerKafka = "kafka"
+)
+
+// Envelope wraps a domain event for publishing to external consumers
+type Envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	ID            uuid.UUID       `json:"id"`
+	TenantID      uuid.UUID       `json:"tenant_id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewEnvelope builds the published envelope for an outbox event
+func NewEnvelope(event *models.OutboxEvent) *Envelope {
+	return &Envelope{
+		SchemaVersion: SchemaVersion,
+		ID:            event.ID,
+		TenantID:      event.TenantID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.CreatedAt.UTC(),
+		Data:          json.RawMessage(event.Payload),
+	}
+}
+
+// Publisher sends messages to a message broker
+type Publisher interface {
+	Publish(ctx context.Context, topic, key string, data []byte) error
+	Close() error
+}
+
+// NewPublisher creates the publisher for the configured broker, or nil if publishing is disabled
+func NewPublisher(cfg config.BrokerConfig) (Publisher, error) {
+	switch cfg.Type {
+	case "", BrokerNone:
+		return nil, nil
+	case BrokerNATS:
+		return NewNATSPublisher(cfg.NATSURL, cfg.Timeout), nil
+	case BrokerKafka:
+		return NewKafkaPublisher(cfg.KafkaRESTURL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown broker type %q", cfg.Type)
+	}
+}
+
+// BrokerConsumer publishes dispatched events to a broker as versioned envelopes.
+// Topics are "<prefix>.<event type>", e.g. "bookstore.book.created", and messages
+// are keyed by aggregate ID so per-entity ordering is preserved.
+type BrokerConsumer struct {
+	publisher   Publisher
+	topicPrefix string
+}
+
+// NewBrokerConsumer creates a consumer that publishes to the given publisher
+func NewBrokerConsumer(publisher Publisher, topicPrefix string) *BrokerConsumer {
+	return &BrokerConsumer{
+		publisher:   publisher,
+		topicPrefix: topicPrefix,
+	}
+}
+
+// Consume publishes the event
+func (c *BrokerConsumer) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	data, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event envelope: %w", err)
+	}
+
+	topic := event.EventType
+	if c.topicPrefix != "" {
+		topic = c.topicPrefix + "." + topic
+	}
+
+	if err := c.publisher.Publish(ctx, topic, event.AggregateID.String(), data); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", topic, err)
+	}
+	return nil
+}
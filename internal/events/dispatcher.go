@@ -0,0 +1,233 @@
+package events
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/tracing"
+	"bookstore-api/internal/utils"
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	dispatcher *Dispatcher
+	once       sync.Once
+)
+
+// Consumer receives domain events from the dispatcher. Delivery is at-least-once:
+// if any consumer of an event fails, the event is redelivered to all of them.
+type Consumer interface {
+	Consume(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// ConsumerFunc adapts an ordinary function to the Consumer interface
+type ConsumerFunc func(ctx context.Context, event *models.OutboxEvent) error
+
+// Consume calls f(ctx, event)
+func (f ConsumerFunc) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// Dispatcher delivers outbox events to registered consumers
+type Dispatcher struct {
+	db        *gorm.DB
+	cfg       config.EventsConfig
+	mu        sync.RWMutex
+	consumers map[string][]Consumer
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher creates a new event dispatcher
+func NewDispatcher(db *gorm.DB, cfg config.EventsConfig) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &Dispatcher{
+		db:        db,
+		cfg:       cfg,
+		consumers: make(map[string][]Consumer),
+	}
+}
+
+// InitializeDispatcher initializes the shared event dispatcher on db
+func InitializeDispatcher(db *gorm.DB, cfg *config.Config) {
+	once.Do(func() {
+		dispatcher = NewDispatcher(tenancy.System(db), cfg.Events)
+	})
+}
+
+// GetDispatcher returns the shared event dispatcher
+func GetDispatcher() *Dispatcher {
+	if dispatcher == nil {
+		log.Fatal("Event dispatcher not initialized. Call InitializeDispatcher first.")
+	}
+	return dispatcher
+}
+
+// Subscribe registers a consumer for an event type, or for all events with AllEvents
+func (d *Dispatcher) Subscribe(eventType string, consumer Consumer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.consumers[eventType] = append(d.consumers[eventType], consumer)
+}
+
+// Start begins polling the outbox for pending events
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go d.loop(ctx)
+	log.Println("Started event dispatcher")
+}
+
+// Stop stops the dispatcher and waits for the current batch to finish
+func (d *Dispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	log.Println("Stopping event dispatcher...")
+	d.cancel()
+	d.wg.Wait()
+}
+
+// loop dispatches batches until the outbox is drained, then waits for the next tick
+func (d *Dispatcher) loop(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			published, err := d.dispatchBatch(ctx)
+			if err != nil {
+				log.Printf("Failed to dispatch events: %v", err)
+				break
+			}
+			// Stop draining on a short batch or failed deliveries so retries wait for the next tick
+			if published < d.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchBatch locks a batch of pending events, delivers them in creation order,
+// and returns the number published
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	var batch []models.OutboxEvent
+	published := 0
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND attempts < ?", d.cfg.MaxAttempts).
+			Order("created_at ASC").
+			Limit(d.cfg.BatchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+
+		for i := range batch {
+			event := &batch[i]
+			if err := d.deliver(ctx, event); err != nil {
+				event.Attempts++
+				log.Printf("Failed to deliver event %s (%s), attempt %d: %v", event.ID, event.EventType, event.Attempts, err)
+				if err := tx.Model(event).Updates(map[string]interface{}{
+					"attempts":   event.Attempts,
+					"last_error": err.Error(),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(event).Updates(map[string]interface{}{
+				"published_at": time.Now(),
+				"last_error":   "",
+			}).Error; err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+// deliver hands an event to every consumer subscribed to its type. Consumers
+// receive a context scoped to the event's tenant and continuing the trace of
+// the request that recorded the event.
+func (d *Dispatcher) deliver(ctx context.Context, event *models.OutboxEvent) (err error) {
+	ctx = tenancy.WithTenantID(ctx, event.TenantID)
+	ctx, span := tracing.Start(ctx, "event "+event.EventType, event.Traceparent)
+	defer func() {
+		span.End(ctx, slog.String("event_id", event.ID.String()), slog.Bool("failed", err != nil))
+	}()
+
+	d.mu.RLock()
+	consumers := append(append([]Consumer{}, d.consumers[event.EventType]...), d.consumers[AllEvents]...)
+	d.mu.RUnlock()
+
+	for _, consumer := range consumers {
+		if err := safeConsume(ctx, consumer, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgePublished deletes events published before the cutoff
+func PurgePublished(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("published_at IS NOT NULL AND published_at < ?", before).Delete(&models.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge published events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// LogEvent is a consumer that logs every dispatched event at debug level
+func LogEvent(ctx context.Context, event *models.OutboxEvent) error {
+	utils.LogDebug("Domain event dispatched", map[string]interface{}{
+		"id":             event.ID,
+		"event_type":     event.EventType,
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID,
+	})
+	return nil
+}
+
+// safeConsume runs a consumer, converting panics into errors
+func safeConsume(ctx context.Context, consumer Consumer, event *models.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("consumer panicked: %v", r)
+		}
+	}()
+	return consumer.Consume(ctx, event)
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tracing"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Aggregate types
+const (
+	AggregateAuthor    = "author"
+	AggregateCategory  = "category"
+	AggregateBook      = "book"
+	AggregatePromotion = "promotion"
+	AggregateRental    = "rental"
+)
+
+// Domain event types
+const (
+	AuthorCreated    = "author.created"
+	AuthorUpdated    = "author.updated"
+	AuthorDeleted    = "author.deleted"
+	AuthorMerged     = "author.merged"
+	CategoryCreated  = "category.created"
+	CategoryUpdated  = "category.updated"
+	CategoryDeleted  = "category.deleted"
+	BookCreated      = "book.created"
+	BookUpdated      = "book.updated"
+	BookDeleted      = "book.deleted"
+	BookStockChanged = "book.stock_changed"
+	BookPriceChanged = "book.price_changed"
+	PromotionStarted = "promotion.started"
+	PromotionEnded   = "promotion.ended"
+	RentalCreated    = "rental.created"
+	RentalReturned   = "rental.returned"
+	RentalOverdue    = "rental.overdue"
+	AllEvents        = "*"
+)
+
+// DeletedPayload is the payload of *.deleted events
+type DeletedPayload struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// StockChangedPayload is the payload of book.stock_changed events
+type StockChangedPayload struct {
+	BookID        uuid.UUID `json:"book_id"`
+	PreviousStock int       `json:"previous_stock"`
+	Stock         int       `json:"stock"`
+}
+
+// PriceChangedPayload is the payload of book.price_changed events
+type PriceChangedPayload struct {
+	BookID        uuid.UUID `json:"book_id"`
+	PreviousPrice float64   `json:"previous_price"`
+	Price         float64   `json:"price"`
+}
+
+// AuthorMergedPayload is the payload of author.merged events
+type AuthorMergedPayload struct {
+	AuthorID    uuid.UUID   `json:"author_id"`
+	DuplicateID uuid.UUID   `json:"duplicate_id"`
+	BookIDs     []uuid.UUID `json:"book_ids"`
+}
+
+// Entry is a domain event written with RecordBatch
+type Entry struct {
+	EventType     string
+	AggregateType string
+	AggregateID   uuid.UUID
+	Payload       interface{}
+}
+
+// RecordBatch writes domain events to the outbox with a single multi-row
+// insert, in order. Like Record, pass the transaction that makes the changes.
+func RecordBatch(tx *gorm.DB, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	traceparent := tracing.Traceparent(tx.Statement.Context)
+	outbox := make([]models.OutboxEvent, len(entries))
+	for i, entry := range entries {
+		data, err := json.Marshal(entry.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", entry.EventType, err)
+		}
+		outbox[i] = models.OutboxEvent{
+			EventType:     entry.EventType,
+			AggregateType: entry.AggregateType,
+			AggregateID:   entry.AggregateID,
+			Payload:       string(data),
+			Traceparent:   traceparent,
+		}
+	}
+	if err := tx.CreateInBatches(&outbox, len(outbox)).Error; err != nil {
+		return fmt.Errorf("failed to record events: %w", err)
+	}
+	return nil
+}
+
+// Record writes a domain event to the outbox. Pass the transaction that makes
+// the change so the event is only stored if the change commits; the event
+// keeps the trace of the transaction's context for its consumers to continue.
+func Record(tx *gorm.DB, eventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	event := &models.OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		Traceparent:   tracing.Traceparent(tx.Statement.Context),
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
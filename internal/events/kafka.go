@@ -0,0 +1,91 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// KafkaPublisher publishes messages to Kafka through a Kafka REST Proxy (v2 API)
+type KafkaPublisher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// kafkaRecord is a single record in a REST Proxy produce request
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaProduceResponse is the REST Proxy produce response
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaPublisher creates a Kafka publisher for a REST Proxy such as http://localhost:8082
+func NewKafkaPublisher(restURL string, timeout time.Duration) *KafkaPublisher {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &KafkaPublisher{
+		baseURL: strings.TrimRight(restURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish produces a single record to the topic
+func (p *KafkaPublisher) Publish(ctx context.Context, topic, key string, data []byte) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: key, Value: data}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Kafka REST proxy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Kafka REST proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produced); err != nil {
+		return fmt.Errorf("invalid Kafka REST proxy response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("Kafka rejected record (error code %d): %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections
+func (p *KafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,182 @@
+package events
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	stream     *Stream
+	streamOnce sync.Once
+)
+
+// StreamEvents are the event types that can be streamed to clients
+var StreamEvents = []string{BookStockChanged, BookPriceChanged}
+
+// Subscription receives events from a Stream matching its filter
+type Subscription struct {
+	Events   chan *models.OutboxEvent
+	tenantID uuid.UUID
+	types    map[string]bool
+	bookID   uuid.UUID
+}
+
+// matches reports whether the event passes the subscription filter
+func (s *Subscription) matches(event *models.OutboxEvent) bool {
+	if event.TenantID != s.tenantID || !s.types[event.EventType] {
+		return false
+	}
+	return s.bookID == uuid.Nil || event.AggregateID == s.bookID
+}
+
+// Stream is a consumer that broadcasts dispatched events to live subscribers,
+// such as Server-Sent Events clients. Subscribers that fall behind are dropped
+// rather than blocking the dispatcher; clients resume with Replay.
+type Stream struct {
+	db          *gorm.DB
+	cfg         config.StreamConfig
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewStream creates a new event stream
+func NewStream(db *gorm.DB, cfg config.StreamConfig) *Stream {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 64
+	}
+	if cfg.ReplayLimit <= 0 {
+		cfg.ReplayLimit = 500
+	}
+
+	return &Stream{
+		db:          db,
+		cfg:         cfg,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// InitializeStream initializes the shared event stream on db
+func InitializeStream(db *gorm.DB, cfg *config.Config) {
+	streamOnce.Do(func() {
+		stream = NewStream(tenancy.System(db), cfg.Stream)
+	})
+}
+
+// GetStream returns the shared event stream
+func GetStream() *Stream {
+	if stream == nil {
+		log.Fatal("Event stream not initialized. Call InitializeStream first.")
+	}
+	return stream
+}
+
+// Subscribe registers a subscriber for the tenant's events of the given types,
+// optionally limited to one book
+func (s *Stream) Subscribe(tenantID uuid.UUID, types []string, bookID uuid.UUID) *Subscription {
+	sub := &Subscription{
+		Events:   make(chan *models.OutboxEvent, s.cfg.BufferSize),
+		tenantID: tenantID,
+		types:    make(map[string]bool, len(types)),
+		bookID:   bookID,
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		close(sub.Events)
+		return sub
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (s *Stream) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.Events)
+	}
+}
+
+// Consume broadcasts the event to every matching subscriber
+func (s *Stream) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			log.Printf("Dropping slow event stream subscriber")
+			delete(s.subscribers, sub)
+			close(sub.Events)
+		}
+	}
+	return nil
+}
+
+// Replay returns published events of the given types that were recorded after
+// lastEventID, oldest first. Unknown or purged IDs return no events. ctx carries
+// the tenant whose events are replayed.
+func (s *Stream) Replay(ctx context.Context, lastEventID uuid.UUID, types []string, bookID uuid.UUID) ([]models.OutboxEvent, error) {
+	db := s.db.WithContext(ctx)
+	var last models.OutboxEvent
+	if err := db.Select("id", "created_at").First(&last, "id = ?", lastEventID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last event: %w", err)
+	}
+
+	query := db.Where("published_at IS NOT NULL AND created_at > ? AND event_type IN ?", last.CreatedAt, types)
+	if bookID != uuid.Nil {
+		query = query.Where("aggregate_id = ?", bookID)
+	}
+
+	var replay []models.OutboxEvent
+	if err := query.Order("created_at ASC").Limit(s.cfg.ReplayLimit).Find(&replay).Error; err != nil {
+		return nil, fmt.Errorf("failed to replay events: %w", err)
+	}
+	return replay, nil
+}
+
+// SubscriberCount returns the number of subscribers connected for the tenant
+func (s *Stream) SubscriberCount(tenantID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for sub := range s.subscribers {
+		if sub.tenantID == tenantID {
+			count++
+		}
+	}
+	return count
+}
+
+// Close disconnects all subscribers so open streams end during shutdown
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.Events)
+	}
+}
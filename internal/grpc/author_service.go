@@ -2,85 +2,60 @@ package grpc
 
 import (
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/timestamps"
 	pb "bookstore-api/proto"
 	"context"
 
 	"github.com/google/uuid"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 // CreateAuthor implements the CreateAuthor gRPC method
 func (s *GRPCServer) CreateAuthor(ctx context.Context, req *pb.CreateAuthorRequest) (*pb.CreateAuthorResponse, error) {
 	author := &models.Author{
-		Name:      req.Name,
-		Email:     req.Email,
-		Biography: req.Biography,
+		Name:          req.Name,
+		Email:         req.Email,
+		Biography:     req.Biography,
+		ExternalLinks: req.ExternalLinks,
 	}
 
-	if err := s.authorService.CreateAuthor(author); err != nil {
-		return &pb.CreateAuthorResponse{
-			Success: false,
-			Message: "Failed to create author: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+	if err := s.authorService.WithContext(ctx).CreateAuthor(author); err != nil {
+		if err.Error() == "author with this email already exists" {
+			return nil, alreadyExists("author", uuid.Nil, "An author with this email already exists")
+		}
+		return nil, internalError("create author", err)
 	}
 
-	return &pb.CreateAuthorResponse{
-		Success: true,
-		Message: "Author created successfully",
-		Author:  convertAuthorToProto(author),
-	}, nil
+	return &pb.CreateAuthorResponse{Author: convertAuthorToProto(author)}, nil
 }
 
 // GetAuthor implements the GetAuthor gRPC method
 func (s *GRPCServer) GetAuthor(ctx context.Context, req *pb.GetAuthorRequest) (*pb.GetAuthorResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "author")
 	if err != nil {
-		return &pb.GetAuthorResponse{
-			Success: false,
-			Message: "Invalid author ID",
-		}, status.Error(codes.InvalidArgument, "Invalid author ID")
+		return nil, err
 	}
 
-	author, err := s.authorService.GetAuthorByID(id)
+	author, err := s.authorService.WithContext(ctx).GetAuthorByID(id)
 	if err != nil {
 		if err.Error() == "author not found" {
-			return &pb.GetAuthorResponse{
-				Success: false,
-				Message: "Author not found",
-			}, status.Error(codes.NotFound, "Author not found")
+			return nil, notFound("author", req.Id)
 		}
-		return &pb.GetAuthorResponse{
-			Success: false,
-			Message: "Failed to get author: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get author", err)
 	}
 
-	return &pb.GetAuthorResponse{
-		Success: true,
-		Message: "Author retrieved successfully",
-		Author:  convertAuthorToProto(author),
-	}, nil
+	return &pb.GetAuthorResponse{Author: convertAuthorToProto(author)}, nil
 }
 
 // GetAllAuthors implements the GetAllAuthors gRPC method
 func (s *GRPCServer) GetAllAuthors(ctx context.Context, req *pb.GetAllAuthorsRequest) (*pb.GetAllAuthorsResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
+	list, err := pageOf(req, "authors")
+	if err != nil {
+		return nil, err
 	}
 
-	authors, total, err := s.authorService.GetAllAuthors(page, limit)
+	authors, total, err := s.authorService.WithContext(ctx).GetAllAuthors(list.page, list.limit)
 	if err != nil {
-		return &pb.GetAllAuthorsResponse{
-			Success: false,
-			Message: "Failed to get authors: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get authors", err)
 	}
 
 	var protoAuthors []*pb.Author
@@ -89,100 +64,70 @@ func (s *GRPCServer) GetAllAuthors(ctx context.Context, req *pb.GetAllAuthorsReq
 	}
 
 	return &pb.GetAllAuthorsResponse{
-		Success: true,
-		Message: "Authors retrieved successfully",
-		Authors: protoAuthors,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Authors:       protoAuthors,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(authors)),
 	}, nil
 }
 
 // UpdateAuthor implements the UpdateAuthor gRPC method
 func (s *GRPCServer) UpdateAuthor(ctx context.Context, req *pb.UpdateAuthorRequest) (*pb.UpdateAuthorResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "author")
 	if err != nil {
-		return &pb.UpdateAuthorResponse{
-			Success: false,
-			Message: "Invalid author ID",
-		}, status.Error(codes.InvalidArgument, "Invalid author ID")
+		return nil, err
 	}
 
 	updates := &models.Author{
-		Name:      req.Name,
-		Email:     req.Email,
-		Biography: req.Biography,
+		Name:          req.Name,
+		Email:         req.Email,
+		Biography:     req.Biography,
+		ExternalLinks: req.ExternalLinks,
 	}
 
-	if err := s.authorService.UpdateAuthor(id, updates); err != nil {
+	if err := s.authorService.WithContext(ctx).UpdateAuthor(id, updates); err != nil {
 		if err.Error() == "author not found" {
-			return &pb.UpdateAuthorResponse{
-				Success: false,
-				Message: "Author not found",
-			}, status.Error(codes.NotFound, "Author not found")
+			return nil, notFound("author", req.Id)
+		}
+		if err.Error() == "author with this email already exists" {
+			return nil, alreadyExists("author", uuid.Nil, "An author with this email already exists")
 		}
-		return &pb.UpdateAuthorResponse{
-			Success: false,
-			Message: "Failed to update author: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("update author", err)
 	}
 
-	return &pb.UpdateAuthorResponse{
-		Success: true,
-		Message: "Author updated successfully",
-	}, nil
+	return &pb.UpdateAuthorResponse{}, nil
 }
 
 // DeleteAuthor implements the DeleteAuthor gRPC method
 func (s *GRPCServer) DeleteAuthor(ctx context.Context, req *pb.DeleteAuthorRequest) (*pb.DeleteAuthorResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "author")
 	if err != nil {
-		return &pb.DeleteAuthorResponse{
-			Success: false,
-			Message: "Invalid author ID",
-		}, status.Error(codes.InvalidArgument, "Invalid author ID")
+		return nil, err
 	}
 
-	if err := s.authorService.DeleteAuthor(id); err != nil {
+	if err := s.authorService.WithContext(ctx).DeleteAuthor(id); err != nil {
 		if err.Error() == "author not found" {
-			return &pb.DeleteAuthorResponse{
-				Success: false,
-				Message: "Author not found",
-			}, status.Error(codes.NotFound, "Author not found")
+			return nil, notFound("author", req.Id)
 		}
-		return &pb.DeleteAuthorResponse{
-			Success: false,
-			Message: "Failed to delete author: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("delete author", err)
 	}
 
-	return &pb.DeleteAuthorResponse{
-		Success: true,
-		Message: "Author deleted successfully",
-	}, nil
+	return &pb.DeleteAuthorResponse{}, nil
 }
 
 // SearchAuthors implements the SearchAuthors gRPC method
 func (s *GRPCServer) SearchAuthors(ctx context.Context, req *pb.SearchAuthorsRequest) (*pb.SearchAuthorsResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-
-	if page <= 0 {
-		page = 1
+	list, err := pageOf(req, "authors.search", req.Query)
+	if err != nil {
+		return nil, err
 	}
-	if limit <= 0 {
-		limit = 10
+	query, err := parseSearchQuery(req.Query)
+	if err != nil {
+		return nil, err
 	}
 
-	authors, total, err := s.authorService.SearchAuthors(req.Query, page, limit)
+	authors, total, err := s.authorService.WithContext(ctx).SearchAuthors(query, list.page, list.limit)
 	if err != nil {
-		return &pb.SearchAuthorsResponse{
-			Success: false,
-			Message: "Failed to search authors: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("search authors", err)
 	}
 
 	var protoAuthors []*pb.Author
@@ -191,33 +136,32 @@ func (s *GRPCServer) SearchAuthors(ctx context.Context, req *pb.SearchAuthorsReq
 	}
 
 	return &pb.SearchAuthorsResponse{
-		Success: true,
-		Message: "Authors found successfully",
-		Authors: protoAuthors,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Authors:       protoAuthors,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(authors)),
 	}, nil
 }
 
 // convertAuthorToProto converts a models.Author to pb.Author
 func convertAuthorToProto(author *models.Author) *pb.Author {
 	protoAuthor := &pb.Author{
-		Id:        author.ID.String(),
-		Name:      author.Name,
-		Email:     author.Email,
-		Biography: author.Biography,
-		CreatedAt: author.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: author.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Id:            author.ID.String(),
+		Name:          author.Name,
+		Email:         author.Email,
+		Biography:     author.Biography,
+		CreatedAt:     timestamps.Format(author.CreatedAt),
+		UpdatedAt:     timestamps.Format(author.UpdatedAt),
+		Photos:        author.Photos,
+		ExternalLinks: author.ExternalLinks,
 	}
 
 	// Convert books if they exist
 	for _, book := range author.Books {
 		protoAuthor.Books = append(protoAuthor.Books, convertBookToProto(&book))
 	}
+	if author.BooksCount != nil {
+		protoAuthor.BooksCount = *author.BooksCount
+	}
 
 	return protoAuthor
 }
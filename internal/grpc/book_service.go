@@ -2,38 +2,35 @@ package grpc
 
 import (
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/timestamps"
 	pb "bookstore-api/proto"
 	"context"
-	"time"
+	"errors"
+	"strings"
 
 	"github.com/google/uuid"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 // CreateBook implements the CreateBook gRPC method
 func (s *GRPCServer) CreateBook(ctx context.Context, req *pb.CreateBookRequest) (*pb.CreateBookResponse, error) {
-	authorID, err := uuid.Parse(req.AuthorId)
+	authorID, err := parseID(req.AuthorId, "author_id", "author")
 	if err != nil {
-		return &pb.CreateBookResponse{
-			Success: false,
-			Message: "Invalid author ID",
-		}, status.Error(codes.InvalidArgument, "Invalid author ID")
+		return nil, err
 	}
 
-	categoryID, err := uuid.Parse(req.CategoryId)
+	categoryID, err := parseID(req.CategoryId, "category_id", "category")
 	if err != nil {
-		return &pb.CreateBookResponse{
-			Success: false,
-			Message: "Invalid category ID",
-		}, status.Error(codes.InvalidArgument, "Invalid category ID")
+		return nil, err
 	}
 
-	var publishedAt *time.Time
+	var publishedAt *models.DateOnly
 	if req.PublishedAt != "" {
-		if parsed, err := time.Parse("2006-01-02T15:04:05Z07:00", req.PublishedAt); err == nil {
-			publishedAt = &parsed
+		parsed, err := models.ParseDateOnly(req.PublishedAt)
+		if err != nil {
+			return nil, invalidField("published_at", "date", "published_at must be a date or an RFC 3339 timestamp")
 		}
+		publishedAt = &parsed
 	}
 
 	book := &models.Book{
@@ -47,69 +44,57 @@ func (s *GRPCServer) CreateBook(ctx context.Context, req *pb.CreateBookRequest)
 		CategoryID:  categoryID,
 	}
 
-	if err := s.bookService.CreateBook(book); err != nil {
-		return &pb.CreateBookResponse{
-			Success: false,
-			Message: "Failed to create book: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+	if err := s.bookService.WithContext(ctx).CreateBook(book); err != nil {
+		var duplicate *services.DuplicateBookError
+		if errors.As(err, &duplicate) {
+			if duplicate.Same {
+				return &pb.CreateBookResponse{Book: convertBookToProto(duplicate.Existing)}, nil
+			}
+			return nil, alreadyExists("book", duplicate.Existing.ID, "A book with this ISBN already exists")
+		}
+		if strings.HasPrefix(err.Error(), "invalid book: ") {
+			return nil, invalidArgument(err)
+		}
+		switch err.Error() {
+		case "invalid isbn":
+			return nil, invalidField("isbn", "isbn", "ISBN must be a valid ISBN-10 or ISBN-13")
+		case "book with this isbn already exists":
+			return nil, alreadyExists("book", uuid.Nil, "A book with this ISBN already exists")
+		}
+		return nil, internalError("create book", err)
 	}
 
-	return &pb.CreateBookResponse{
-		Success: true,
-		Message: "Book created successfully",
-		Book:    convertBookToProto(book),
-	}, nil
+	return &pb.CreateBookResponse{Book: convertBookToProto(book)}, nil
 }
 
 // GetBook implements the GetBook gRPC method
 func (s *GRPCServer) GetBook(ctx context.Context, req *pb.GetBookRequest) (*pb.GetBookResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "book")
 	if err != nil {
-		return &pb.GetBookResponse{
-			Success: false,
-			Message: "Invalid book ID",
-		}, status.Error(codes.InvalidArgument, "Invalid book ID")
+		return nil, err
 	}
 
-	book, err := s.bookService.GetBookByID(id)
+	book, err := s.bookService.WithContext(ctx).GetBookByID(id)
 	if err != nil {
 		if err.Error() == "book not found" {
-			return &pb.GetBookResponse{
-				Success: false,
-				Message: "Book not found",
-			}, status.Error(codes.NotFound, "Book not found")
+			return nil, notFound("book", req.Id)
 		}
-		return &pb.GetBookResponse{
-			Success: false,
-			Message: "Failed to get book: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get book", err)
 	}
 
-	return &pb.GetBookResponse{
-		Success: true,
-		Message: "Book retrieved successfully",
-		Book:    convertBookToProto(book),
-	}, nil
+	return &pb.GetBookResponse{Book: convertBookToProto(book)}, nil
 }
 
 // GetAllBooks implements the GetAllBooks gRPC method
 func (s *GRPCServer) GetAllBooks(ctx context.Context, req *pb.GetAllBooksRequest) (*pb.GetAllBooksResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-	
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
+	list, err := pageOf(req, "books")
+	if err != nil {
+		return nil, err
 	}
 
-	books, total, err := s.bookService.GetAllBooks(page, limit)
+	books, total, err := s.bookService.WithContext(ctx).GetAllBooks(list.page, list.limit)
 	if err != nil {
-		return &pb.GetAllBooksResponse{
-			Success: false,
-			Message: "Failed to get books: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get books", err)
 	}
 
 	var protoBooks []*pb.Book
@@ -118,131 +103,110 @@ func (s *GRPCServer) GetAllBooks(ctx context.Context, req *pb.GetAllBooksRequest
 	}
 
 	return &pb.GetAllBooksResponse{
-		Success: true,
-		Message: "Books retrieved successfully",
-		Books:   protoBooks,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Books:         protoBooks,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(books)),
 	}, nil
 }
 
 // UpdateBook implements the UpdateBook gRPC method
 func (s *GRPCServer) UpdateBook(ctx context.Context, req *pb.UpdateBookRequest) (*pb.UpdateBookResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "book")
 	if err != nil {
-		return &pb.UpdateBookResponse{
-			Success: false,
-			Message: "Invalid book ID",
-		}, status.Error(codes.InvalidArgument, "Invalid book ID")
+		return nil, err
 	}
 
-	updates := &models.Book{
-		Title:       req.Title,
-		ISBN:        req.Isbn,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       int(req.Stock),
+	updates := map[string]interface{}{}
+	if req.Title != "" {
+		updates["title"] = req.Title
+	}
+	if req.Isbn != "" {
+		updates["isbn"] = req.Isbn
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+	}
+	if req.Stock != nil {
+		updates["stock"] = int(*req.Stock)
 	}
 
 	// Parse optional fields
 	if req.AuthorId != "" {
-		authorID, err := uuid.Parse(req.AuthorId)
+		authorID, err := parseID(req.AuthorId, "author_id", "author")
 		if err != nil {
-			return &pb.UpdateBookResponse{
-				Success: false,
-				Message: "Invalid author ID",
-			}, status.Error(codes.InvalidArgument, "Invalid author ID")
+			return nil, err
 		}
-		updates.AuthorID = authorID
+		updates["author_id"] = authorID
 	}
 
 	if req.CategoryId != "" {
-		categoryID, err := uuid.Parse(req.CategoryId)
+		categoryID, err := parseID(req.CategoryId, "category_id", "category")
 		if err != nil {
-			return &pb.UpdateBookResponse{
-				Success: false,
-				Message: "Invalid category ID",
-			}, status.Error(codes.InvalidArgument, "Invalid category ID")
+			return nil, err
 		}
-		updates.CategoryID = categoryID
+		updates["category_id"] = categoryID
 	}
 
 	if req.PublishedAt != "" {
-		if parsed, err := time.Parse("2006-01-02T15:04:05Z07:00", req.PublishedAt); err == nil {
-			updates.PublishedAt = &parsed
+		parsed, err := models.ParseDateOnly(req.PublishedAt)
+		if err != nil {
+			return nil, invalidField("published_at", "date", "published_at must be a date or an RFC 3339 timestamp")
 		}
+		updates["published_at"] = parsed
 	}
 
-	if err := s.bookService.UpdateBook(id, updates); err != nil {
-		if err.Error() == "book not found" {
-			return &pb.UpdateBookResponse{
-				Success: false,
-				Message: "Book not found",
-			}, status.Error(codes.NotFound, "Book not found")
+	if err := s.bookService.WithContext(ctx).UpdateBook(id, updates); err != nil {
+		if strings.HasPrefix(err.Error(), "invalid book: ") {
+			return nil, invalidArgument(err)
+		}
+		switch err.Error() {
+		case "book not found":
+			return nil, notFound("book", req.Id)
+		case "invalid isbn":
+			return nil, invalidField("isbn", "isbn", "ISBN must be a valid ISBN-10 or ISBN-13")
+		case "book with this isbn already exists":
+			return nil, alreadyExists("book", uuid.Nil, "A book with this ISBN already exists")
 		}
-		return &pb.UpdateBookResponse{
-			Success: false,
-			Message: "Failed to update book: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("update book", err)
 	}
 
-	return &pb.UpdateBookResponse{
-		Success: true,
-		Message: "Book updated successfully",
-	}, nil
+	return &pb.UpdateBookResponse{}, nil
 }
 
 // DeleteBook implements the DeleteBook gRPC method
 func (s *GRPCServer) DeleteBook(ctx context.Context, req *pb.DeleteBookRequest) (*pb.DeleteBookResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "book")
 	if err != nil {
-		return &pb.DeleteBookResponse{
-			Success: false,
-			Message: "Invalid book ID",
-		}, status.Error(codes.InvalidArgument, "Invalid book ID")
+		return nil, err
 	}
 
-	if err := s.bookService.DeleteBook(id); err != nil {
+	if err := s.bookService.WithContext(ctx).DeleteBook(id); err != nil {
 		if err.Error() == "book not found" {
-			return &pb.DeleteBookResponse{
-				Success: false,
-				Message: "Book not found",
-			}, status.Error(codes.NotFound, "Book not found")
+			return nil, notFound("book", req.Id)
 		}
-		return &pb.DeleteBookResponse{
-			Success: false,
-			Message: "Failed to delete book: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("delete book", err)
 	}
 
-	return &pb.DeleteBookResponse{
-		Success: true,
-		Message: "Book deleted successfully",
-	}, nil
+	return &pb.DeleteBookResponse{}, nil
 }
 
 // SearchBooks implements the SearchBooks gRPC method
 func (s *GRPCServer) SearchBooks(ctx context.Context, req *pb.SearchBooksRequest) (*pb.SearchBooksResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-	
-	if page <= 0 {
-		page = 1
+	list, err := pageOf(req, "books.search", req.Query)
+	if err != nil {
+		return nil, err
 	}
-	if limit <= 0 {
-		limit = 10
+	query, err := parseSearchQuery(req.Query)
+	if err != nil {
+		return nil, err
 	}
 
-	books, total, err := s.bookService.SearchBooks(req.Query, page, limit)
+	books, total, err := s.bookService.WithContext(ctx).SearchBooks(query, list.page, list.limit)
 	if err != nil {
-		return &pb.SearchBooksResponse{
-			Success: false,
-			Message: "Failed to search books: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("search books", err)
 	}
 
 	var protoBooks []*pb.Book
@@ -251,44 +215,27 @@ func (s *GRPCServer) SearchBooks(ctx context.Context, req *pb.SearchBooksRequest
 	}
 
 	return &pb.SearchBooksResponse{
-		Success: true,
-		Message: "Books found successfully",
-		Books:   protoBooks,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Books:         protoBooks,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(books)),
 	}, nil
 }
 
 // GetBooksByAuthor implements the GetBooksByAuthor gRPC method
 func (s *GRPCServer) GetBooksByAuthor(ctx context.Context, req *pb.GetBooksByAuthorRequest) (*pb.GetBooksByAuthorResponse, error) {
-	authorID, err := uuid.Parse(req.AuthorId)
+	authorID, err := parseID(req.AuthorId, "author_id", "author")
 	if err != nil {
-		return &pb.GetBooksByAuthorResponse{
-			Success: false,
-			Message: "Invalid author ID",
-		}, status.Error(codes.InvalidArgument, "Invalid author ID")
+		return nil, err
 	}
 
-	page := int(req.Page)
-	limit := int(req.Limit)
-	
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
+	list, err := pageOf(req, "books.author", req.AuthorId)
+	if err != nil {
+		return nil, err
 	}
 
-	books, total, err := s.bookService.GetBooksByAuthor(authorID, page, limit)
+	books, total, err := s.bookService.WithContext(ctx).GetBooksByAuthor(authorID, list.page, list.limit)
 	if err != nil {
-		return &pb.GetBooksByAuthorResponse{
-			Success: false,
-			Message: "Failed to get books by author: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get books by author", err)
 	}
 
 	var protoBooks []*pb.Book
@@ -297,44 +244,27 @@ func (s *GRPCServer) GetBooksByAuthor(ctx context.Context, req *pb.GetBooksByAut
 	}
 
 	return &pb.GetBooksByAuthorResponse{
-		Success: true,
-		Message: "Books retrieved successfully",
-		Books:   protoBooks,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Books:         protoBooks,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(books)),
 	}, nil
 }
 
 // GetBooksByCategory implements the GetBooksByCategory gRPC method
 func (s *GRPCServer) GetBooksByCategory(ctx context.Context, req *pb.GetBooksByCategoryRequest) (*pb.GetBooksByCategoryResponse, error) {
-	categoryID, err := uuid.Parse(req.CategoryId)
+	categoryID, err := parseID(req.CategoryId, "category_id", "category")
 	if err != nil {
-		return &pb.GetBooksByCategoryResponse{
-			Success: false,
-			Message: "Invalid category ID",
-		}, status.Error(codes.InvalidArgument, "Invalid category ID")
+		return nil, err
 	}
 
-	page := int(req.Page)
-	limit := int(req.Limit)
-	
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
+	list, err := pageOf(req, "books.category", req.CategoryId)
+	if err != nil {
+		return nil, err
 	}
 
-	books, total, err := s.bookService.GetBooksByCategory(categoryID, page, limit)
+	books, total, err := s.bookService.WithContext(ctx).GetBooksByCategory(categoryID, list.page, list.limit)
 	if err != nil {
-		return &pb.GetBooksByCategoryResponse{
-			Success: false,
-			Message: "Failed to get books by category: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get books by category", err)
 	}
 
 	var protoBooks []*pb.Book
@@ -343,45 +273,27 @@ func (s *GRPCServer) GetBooksByCategory(ctx context.Context, req *pb.GetBooksByC
 	}
 
 	return &pb.GetBooksByCategoryResponse{
-		Success: true,
-		Message: "Books retrieved successfully",
-		Books:   protoBooks,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Books:         protoBooks,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(books)),
 	}, nil
 }
 
 // UpdateBookStock implements the UpdateBookStock gRPC method
 func (s *GRPCServer) UpdateBookStock(ctx context.Context, req *pb.UpdateBookStockRequest) (*pb.UpdateBookStockResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "book")
 	if err != nil {
-		return &pb.UpdateBookStockResponse{
-			Success: false,
-			Message: "Invalid book ID",
-		}, status.Error(codes.InvalidArgument, "Invalid book ID")
+		return nil, err
 	}
 
-	if err := s.bookService.UpdateBookStock(id, int(req.Stock)); err != nil {
+	if err := s.bookService.WithContext(ctx).UpdateBookStock(id, int(req.Stock)); err != nil {
 		if err.Error() == "book not found" {
-			return &pb.UpdateBookStockResponse{
-				Success: false,
-				Message: "Book not found",
-			}, status.Error(codes.NotFound, "Book not found")
+			return nil, notFound("book", req.Id)
 		}
-		return &pb.UpdateBookStockResponse{
-			Success: false,
-			Message: "Failed to update book stock: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("update book stock", err)
 	}
 
-	return &pb.UpdateBookStockResponse{
-		Success: true,
-		Message: "Book stock updated successfully",
-	}, nil
+	return &pb.UpdateBookStockResponse{}, nil
 }
 
 // convertBookToProto converts a models.Book to pb.Book
@@ -393,14 +305,19 @@ func convertBookToProto(book *models.Book) *pb.Book {
 		Description: book.Description,
 		Price:       book.Price,
 		Stock:       int32(book.Stock),
-		CreatedAt:   book.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   book.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:   timestamps.Format(book.CreatedAt),
+		UpdatedAt:   timestamps.Format(book.UpdatedAt),
 		AuthorId:    book.AuthorID.String(),
 		CategoryId:  book.CategoryID.String(),
 	}
 
 	if book.PublishedAt != nil {
-		protoBook.PublishedAt = book.PublishedAt.Format("2006-01-02T15:04:05Z07:00")
+		protoBook.PublishedAt = book.PublishedAt.String()
+	}
+
+	protoBook.EffectivePrice = book.Price
+	if book.EffectivePrice != nil {
+		protoBook.EffectivePrice = *book.EffectivePrice
 	}
 
 	// Convert author if it exists
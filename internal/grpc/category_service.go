@@ -2,12 +2,11 @@ package grpc
 
 import (
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/timestamps"
 	pb "bookstore-api/proto"
 	"context"
 
 	"github.com/google/uuid"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 // CreateCategory implements the CreateCategory gRPC method
@@ -17,69 +16,44 @@ func (s *GRPCServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryR
 		Description: req.Description,
 	}
 
-	if err := s.categoryService.CreateCategory(category); err != nil {
-		return &pb.CreateCategoryResponse{
-			Success: false,
-			Message: "Failed to create category: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+	if err := s.categoryService.WithContext(ctx).CreateCategory(category); err != nil {
+		if err.Error() == "category with this name already exists" {
+			return nil, alreadyExists("category", uuid.Nil, "A category with this name already exists")
+		}
+		return nil, internalError("create category", err)
 	}
 
-	return &pb.CreateCategoryResponse{
-		Success:  true,
-		Message:  "Category created successfully",
-		Category: convertCategoryToProto(category),
-	}, nil
+	return &pb.CreateCategoryResponse{Category: convertCategoryToProto(category)}, nil
 }
 
 // GetCategory implements the GetCategory gRPC method
 func (s *GRPCServer) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.GetCategoryResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "category")
 	if err != nil {
-		return &pb.GetCategoryResponse{
-			Success: false,
-			Message: "Invalid category ID",
-		}, status.Error(codes.InvalidArgument, "Invalid category ID")
+		return nil, err
 	}
 
-	category, err := s.categoryService.GetCategoryByID(id)
+	category, err := s.categoryService.WithContext(ctx).GetCategoryByID(id)
 	if err != nil {
 		if err.Error() == "category not found" {
-			return &pb.GetCategoryResponse{
-				Success: false,
-				Message: "Category not found",
-			}, status.Error(codes.NotFound, "Category not found")
+			return nil, notFound("category", req.Id)
 		}
-		return &pb.GetCategoryResponse{
-			Success: false,
-			Message: "Failed to get category: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get category", err)
 	}
 
-	return &pb.GetCategoryResponse{
-		Success:  true,
-		Message:  "Category retrieved successfully",
-		Category: convertCategoryToProto(category),
-	}, nil
+	return &pb.GetCategoryResponse{Category: convertCategoryToProto(category)}, nil
 }
 
 // GetAllCategories implements the GetAllCategories gRPC method
 func (s *GRPCServer) GetAllCategories(ctx context.Context, req *pb.GetAllCategoriesRequest) (*pb.GetAllCategoriesResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-	
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
+	list, err := pageOf(req, "categories")
+	if err != nil {
+		return nil, err
 	}
 
-	categories, total, err := s.categoryService.GetAllCategories(page, limit)
+	categories, total, err := s.categoryService.WithContext(ctx).GetAllCategories(list.page, list.limit)
 	if err != nil {
-		return &pb.GetAllCategoriesResponse{
-			Success: false,
-			Message: "Failed to get categories: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("get categories", err)
 	}
 
 	var protoCategories []*pb.Category
@@ -88,26 +62,17 @@ func (s *GRPCServer) GetAllCategories(ctx context.Context, req *pb.GetAllCategor
 	}
 
 	return &pb.GetAllCategoriesResponse{
-		Success:    true,
-		Message:    "Categories retrieved successfully",
-		Categories: protoCategories,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Categories:    protoCategories,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(categories)),
 	}, nil
 }
 
 // UpdateCategory implements the UpdateCategory gRPC method
 func (s *GRPCServer) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.UpdateCategoryResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "category")
 	if err != nil {
-		return &pb.UpdateCategoryResponse{
-			Success: false,
-			Message: "Invalid category ID",
-		}, status.Error(codes.InvalidArgument, "Invalid category ID")
+		return nil, err
 	}
 
 	updates := &models.Category{
@@ -115,72 +80,50 @@ func (s *GRPCServer) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryR
 		Description: req.Description,
 	}
 
-	if err := s.categoryService.UpdateCategory(id, updates); err != nil {
+	if err := s.categoryService.WithContext(ctx).UpdateCategory(id, updates); err != nil {
 		if err.Error() == "category not found" {
-			return &pb.UpdateCategoryResponse{
-				Success: false,
-				Message: "Category not found",
-			}, status.Error(codes.NotFound, "Category not found")
+			return nil, notFound("category", req.Id)
+		}
+		if err.Error() == "category with this name already exists" {
+			return nil, alreadyExists("category", uuid.Nil, "A category with this name already exists")
 		}
-		return &pb.UpdateCategoryResponse{
-			Success: false,
-			Message: "Failed to update category: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("update category", err)
 	}
 
-	return &pb.UpdateCategoryResponse{
-		Success: true,
-		Message: "Category updated successfully",
-	}, nil
+	return &pb.UpdateCategoryResponse{}, nil
 }
 
 // DeleteCategory implements the DeleteCategory gRPC method
 func (s *GRPCServer) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := parseID(req.Id, "id", "category")
 	if err != nil {
-		return &pb.DeleteCategoryResponse{
-			Success: false,
-			Message: "Invalid category ID",
-		}, status.Error(codes.InvalidArgument, "Invalid category ID")
+		return nil, err
 	}
 
-	if err := s.categoryService.DeleteCategory(id); err != nil {
+	if err := s.categoryService.WithContext(ctx).DeleteCategory(id); err != nil {
 		if err.Error() == "category not found" {
-			return &pb.DeleteCategoryResponse{
-				Success: false,
-				Message: "Category not found",
-			}, status.Error(codes.NotFound, "Category not found")
+			return nil, notFound("category", req.Id)
 		}
-		return &pb.DeleteCategoryResponse{
-			Success: false,
-			Message: "Failed to delete category: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("delete category", err)
 	}
 
-	return &pb.DeleteCategoryResponse{
-		Success: true,
-		Message: "Category deleted successfully",
-	}, nil
+	return &pb.DeleteCategoryResponse{}, nil
 }
 
 // SearchCategories implements the SearchCategories gRPC method
 func (s *GRPCServer) SearchCategories(ctx context.Context, req *pb.SearchCategoriesRequest) (*pb.SearchCategoriesResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-	
-	if page <= 0 {
-		page = 1
+	list, err := pageOf(req, "categories.search", req.Query)
+	if err != nil {
+		return nil, err
 	}
-	if limit <= 0 {
-		limit = 10
+	query, err := parseSearchQuery(req.Query)
+	if err != nil {
+		return nil, err
 	}
 
-	categories, total, err := s.categoryService.SearchCategories(req.Query, page, limit)
+	categories, total, err := s.categoryService.WithContext(ctx).SearchCategories(query, list.page, list.limit)
 	if err != nil {
-		return &pb.SearchCategoriesResponse{
-			Success: false,
-			Message: "Failed to search categories: " + err.Error(),
-		}, status.Error(codes.Internal, err.Error())
+		return nil, internalError("search categories", err)
 	}
 
 	var protoCategories []*pb.Category
@@ -189,15 +132,9 @@ func (s *GRPCServer) SearchCategories(ctx context.Context, req *pb.SearchCategor
 	}
 
 	return &pb.SearchCategoriesResponse{
-		Success:    true,
-		Message:    "Categories found successfully",
-		Categories: protoCategories,
-		Pagination: &pb.Pagination{
-			Page:       int32(page),
-			Limit:      int32(limit),
-			Total:      total,
-			TotalPages: (total + int64(limit) - 1) / int64(limit),
-		},
+		Categories:    protoCategories,
+		Pagination:    list.pagination(total),
+		NextPageToken: list.nextPageToken(len(categories)),
 	}, nil
 }
 
@@ -207,14 +144,17 @@ func convertCategoryToProto(category *models.Category) *pb.Category {
 		Id:          category.ID.String(),
 		Name:        category.Name,
 		Description: category.Description,
-		CreatedAt:   category.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   category.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:   timestamps.Format(category.CreatedAt),
+		UpdatedAt:   timestamps.Format(category.UpdatedAt),
 	}
 
 	// Convert books if they exist
 	for _, book := range category.Books {
 		protoCategory.Books = append(protoCategory.Books, convertBookToProto(&book))
 	}
+	if category.BooksCount != nil {
+		protoCategory.BooksCount = *category.BooksCount
+	}
 
 	return protoCategory
 }
@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reasons of the ErrorInfo details of calls cut short
+const (
+	reasonDeadlineExceeded = "DEADLINE_EXCEEDED"
+	reasonCanceled         = "CANCELED"
+)
+
+// deadlineInterceptor bounds each call to max, shortening the deadline the
+// client sent when it is later or missing, and reports calls whose context
+// ended as DEADLINE_EXCEEDED or CANCELLED rather than INTERNAL, since the
+// services fail with whatever error the database returned when their context
+// ended. A zero max leaves the client's deadline alone.
+func deadlineInterceptor(max time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if max > 0 {
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > max {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, max)
+				defer cancel()
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		switch status.Code(err) {
+		case codes.Internal, codes.Unknown:
+			if contextErr := contextError(ctx.Err()); contextErr != nil {
+				return nil, contextErr
+			}
+		}
+		return resp, err
+	}
+}
+
+// contextError returns the status of a call whose context ended with err, or
+// nil when err is not a context error
+func contextError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return statusError(codes.DeadlineExceeded, reasonDeadlineExceeded, "The call did not complete before its deadline", nil)
+	case errors.Is(err, context.Canceled):
+		return statusError(codes.Canceled, reasonCanceled, "The call was cancelled", nil)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the ErrorInfo detail every error carries
+const errorDomain = "bookstore-api"
+
+// Reasons of the ErrorInfo details. Resource-specific reasons are the
+// upper-cased resource type followed by _NOT_FOUND or _ALREADY_EXISTS.
+const (
+	reasonValidationFailed = "VALIDATION_FAILED"
+	reasonUnknownTenant    = "UNKNOWN_TENANT"
+	reasonInternal         = "INTERNAL"
+)
+
+// statusError returns a status error whose first detail is an ErrorInfo of
+// reason and metadata, followed by details, so clients can branch on the
+// reason rather than the message
+func statusError(code codes.Code, reason, message string, metadata map[string]string, details ...protoadapt.MessageV1) error {
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: errorDomain, Metadata: metadata}
+	st, err := status.New(code, message).WithDetails(append([]protoadapt.MessageV1{info}, details...)...)
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+// notFound returns a NotFound status for the resource with a ResourceInfo
+// detail naming it
+func notFound(resourceType, id string) error {
+	message := strings.ToUpper(resourceType[:1]) + resourceType[1:] + " not found"
+	return statusError(codes.NotFound, strings.ToUpper(resourceType)+"_NOT_FOUND", message,
+		map[string]string{"resource_type": resourceType, "id": id},
+		&errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: id, Description: message})
+}
+
+// invalidID returns an InvalidArgument status for a field holding a
+// malformed resource ID
+func invalidID(field, resourceType string) error {
+	return invalidField(field, "uuid", "Invalid "+resourceType+" ID")
+}
+
+// parseID parses value, held in field, as a resourceType ID, returning an
+// InvalidArgument status naming the field when it is malformed
+func parseID(value, field, resourceType string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, invalidID(field, resourceType)
+	}
+	return id, nil
+}
+
+// internalError returns an Internal status for an unexpected failure of
+// action, or the status of an ended context when the failure was caused by
+// one
+func internalError(action string, err error) error {
+	if contextErr := contextError(err); contextErr != nil {
+		return contextErr
+	}
+	return statusError(codes.Internal, reasonInternal, "Failed to "+action+": "+err.Error(), nil)
+}
+
+// alreadyExists returns an AlreadyExists status with a ResourceInfo detail
+// naming the existing resource, as REST conflicts carry its ID
+func alreadyExists(resourceType string, id uuid.UUID, message string) error {
+	metadata := map[string]string{"resource_type": resourceType}
+	resource := &errdetails.ResourceInfo{ResourceType: resourceType, Description: message}
+	if id != uuid.Nil {
+		metadata["id"] = id.String()
+		resource.ResourceName = id.String()
+	}
+	return statusError(codes.AlreadyExists, strings.ToUpper(resourceType)+"_ALREADY_EXISTS", message, metadata, resource)
+}
@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"bookstore-api/internal/tracing"
+	"bookstore-api/internal/utils"
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// loggingInterceptor logs each call with the structured logger: successful
+// calls at debug, client errors at warn, and server errors at error, so the
+// runtime log level decides which calls are logged
+func loggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelDebug
+		switch code {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+		if span := tracing.FromContext(ctx); span != nil {
+			attrs = append(attrs, span.LogAttrs()...)
+		}
+		utils.Slog().LogAttrs(ctx, level, "gRPC call", attrs...)
+		return resp, err
+	}
+}
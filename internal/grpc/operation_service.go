@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"bookstore-api/internal/operations"
+	"bookstore-api/internal/timestamps"
+	pb "bookstore-api/proto"
+	"context"
+	"strings"
+)
+
+// GetOperation implements the GetOperation gRPC method
+func (s *GRPCServer) GetOperation(ctx context.Context, req *pb.GetOperationRequest) (*pb.GetOperationResponse, error) {
+	id, err := parseID(strings.TrimPrefix(req.Name, "operations/"), "name", "operation")
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := s.operations.GetOperation(ctx, id)
+	if err != nil {
+		if err.Error() == "operation not found" {
+			return nil, notFound("operation", req.Name)
+		}
+		return nil, internalError("get operation", err)
+	}
+
+	return &pb.GetOperationResponse{Operation: convertOperationToProto(operation)}, nil
+}
+
+// convertOperationToProto converts an operation to its protobuf message
+func convertOperationToProto(operation *operations.Operation) *pb.Operation {
+	return &pb.Operation{
+		Name:   operation.Name,
+		Id:     operation.ID.String(),
+		Type:   operation.Type,
+		Status: operation.Status,
+		Done:   operation.Done,
+		Progress: &pb.OperationProgress{
+			Percent: int32(operation.Progress.Percent),
+			Step:    operation.Progress.Step,
+			Done:    int32(operation.Progress.Done),
+			Total:   int32(operation.Progress.Total),
+		},
+		Error:      operation.Error,
+		Links:      operation.Links,
+		ResultJson: string(operation.Result),
+		CreatedAt:  timestamps.Format(operation.CreatedAt),
+		UpdatedAt:  timestamps.Format(operation.UpdatedAt),
+	}
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	pb "bookstore-api/proto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// Page sizes of list calls. Page sizes and limits above the maximum are
+// lowered to it.
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// listRequest is implemented by the list requests, which page with
+// page_size and page_token or, for older clients, with page and limit
+type listRequest interface {
+	GetPage() int32
+	GetLimit() int32
+	GetPageSize() int32
+	GetPageToken() string
+}
+
+// pageToken is the content of a page token: where the next page starts, the
+// page size it was issued for, and a fingerprint of the request's other
+// parameters, which must not change between pages
+type pageToken struct {
+	Offset int    `json:"o"`
+	Size   int    `json:"s"`
+	Filter string `json:"f"`
+}
+
+// listPage is the page of a collection a list request asks for
+type listPage struct {
+	page   int
+	limit  int
+	filter string
+}
+
+// pageOf reads the page a list request asks for. filter names the collection
+// and holds the request's other parameters, such as the search query, so a
+// token is only accepted by requests for the same collection.
+func pageOf(req listRequest, filter ...string) (listPage, error) {
+	p := listPage{filter: fingerprint(filter)}
+	size := int(req.GetPageSize())
+	if size < 0 {
+		return p, invalidField("page_size", "min", "page_size must not be negative")
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	if encoded := req.GetPageToken(); encoded != "" {
+		token, ok := decodePageToken(encoded)
+		if !ok || token.Filter != p.filter {
+			return p, invalidField("page_token", "page_token", "page_token is invalid or was issued for a different request")
+		}
+		if size == 0 {
+			size = token.Size
+		}
+		if token.Offset%size != 0 {
+			return p, invalidField("page_size", "page_token", "page_size must divide the offset of the page token; keep the page size used for the first page")
+		}
+		p.page, p.limit = token.Offset/size+1, size
+		return p, nil
+	}
+
+	p.page = int(req.GetPage())
+	if p.page <= 0 {
+		p.page = 1
+	}
+	p.limit = size
+	if p.limit == 0 {
+		p.limit = int(req.GetLimit())
+	}
+	if p.limit <= 0 {
+		p.limit = defaultPageSize
+	}
+	if p.limit > maxPageSize {
+		p.limit = maxPageSize
+	}
+	return p, nil
+}
+
+// pagination describes the page for clients paging with page and limit
+func (p listPage) pagination(total int64) *pb.Pagination {
+	return &pb.Pagination{
+		Page:       int32(p.page),
+		Limit:      int32(p.limit),
+		Total:      total,
+		TotalPages: (total + int64(p.limit) - 1) / int64(p.limit),
+	}
+}
+
+// nextPageToken returns the token of the page after this one, given how many
+// items this page held, or "" when this page was the last. Totals may be
+// estimates, so only a short page ends the collection; a collection whose
+// size is a multiple of the page size ends with an empty page.
+func (p listPage) nextPageToken(count int) string {
+	if count < p.limit {
+		return ""
+	}
+	encoded, err := json.Marshal(pageToken{Offset: p.page * p.limit, Size: p.limit, Filter: p.filter})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodePageToken decodes a page token
+func decodePageToken(encoded string) (pageToken, bool) {
+	var token pageToken
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(raw, &token) != nil {
+		return token, false
+	}
+	return token, token.Offset >= 0 && token.Size > 0 && token.Size <= maxPageSize
+}
+
+// fingerprint returns a short digest of the request parameters a token is
+// bound to
+func fingerprint(parameters []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parameters, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
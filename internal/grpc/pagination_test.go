@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	pb "bookstore-api/proto"
+	"testing"
+)
+
+func TestPageOf(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		req         *pb.GetAllBooksRequest
+		page, limit int
+	}{
+		{"defaults", &pb.GetAllBooksRequest{}, 1, defaultPageSize},
+		{"page size", &pb.GetAllBooksRequest{PageSize: 25}, 1, 25},
+		{"page size over the cap", &pb.GetAllBooksRequest{PageSize: maxPageSize + 1}, 1, maxPageSize},
+		{"legacy page and limit", &pb.GetAllBooksRequest{Page: 3, Limit: 20}, 3, 20},
+		{"legacy limit over the cap", &pb.GetAllBooksRequest{Limit: 100000}, 1, maxPageSize},
+		{"page size wins over limit", &pb.GetAllBooksRequest{PageSize: 5, Limit: 50}, 1, 5},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := pageOf(tt.req, "books")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.page != tt.page || p.limit != tt.limit {
+				t.Fatalf("got page %d of %d, expected page %d of %d", p.page, p.limit, tt.page, tt.limit)
+			}
+		})
+	}
+}
+
+func TestPageOfToken(t *testing.T) {
+	first, err := pageOf(&pb.GetAllBooksRequest{PageSize: 20}, "books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := first.nextPageToken(20)
+	if token == "" {
+		t.Fatal("a full page has no next page token")
+	}
+
+	next, err := pageOf(&pb.GetAllBooksRequest{PageToken: token}, "books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.page != 2 || next.limit != 20 {
+		t.Fatalf("got page %d of %d, expected page 2 of 20", next.page, next.limit)
+	}
+
+	if _, err := pageOf(&pb.GetAllBooksRequest{PageToken: token}, "authors"); err == nil {
+		t.Fatal("a token for books was accepted for authors")
+	}
+	if _, err := pageOf(&pb.GetAllBooksRequest{PageSize: -1}, "books"); err == nil {
+		t.Fatal("a negative page size was accepted")
+	}
+}
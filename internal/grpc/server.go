@@ -2,11 +2,14 @@ package grpc
 
 import (
 	"bookstore-api/internal/config"
+	"bookstore-api/internal/listener"
+	"bookstore-api/internal/operations"
 	"bookstore-api/internal/services"
 	pb "bookstore-api/proto"
 	"context"
 	"log"
 	"net"
+	"sync"
 
 	"google.golang.org/grpc"
 )
@@ -16,39 +19,99 @@ type GRPCServer struct {
 	pb.UnimplementedAuthorServiceServer
 	pb.UnimplementedCategoryServiceServer
 	pb.UnimplementedBookServiceServer
+	pb.UnimplementedOperationsServiceServer
 	pb.UnimplementedHealthServiceServer
 
 	authorService   *services.AuthorService
 	categoryService *services.CategoryService
 	bookService     *services.BookService
+	operations      *operations.Manager
+
+	mu       sync.Mutex
+	server   *grpc.Server
+	shutdown bool
 }
 
-// NewGRPCServer creates a new gRPC server
-func NewGRPCServer() *GRPCServer {
+// NewGRPCServer creates a new gRPC server calling the services in svc
+func NewGRPCServer(svc *services.Services) *GRPCServer {
 	return &GRPCServer{
-		authorService:   services.NewAuthorService(),
-		categoryService: services.NewCategoryService(),
-		bookService:     services.NewBookService(),
+		authorService:   svc.Authors,
+		categoryService: svc.Categories,
+		bookService:     svc.Books,
+		operations:      operations.GetManager(),
 	}
 }
 
 // Start starts the gRPC server
 func (s *GRPCServer) Start(cfg *config.Config) error {
-	lis, err := net.Listen("tcp", cfg.GRPC.Host+":"+cfg.GRPC.Port)
+	lis, err := listener.Listen("grpc", cfg.GRPC.Host+":"+cfg.GRPC.Port, cfg.Server.ReusePort)
 	if err != nil {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	log.Printf("Starting gRPC server on %s", lis.Addr())
+	return s.Serve(cfg, lis)
+}
+
+// Serve serves the gRPC services on lis until it is closed
+func (s *GRPCServer) Serve(cfg *config.Config, lis net.Listener) error {
+	grpcServer := s.newServer(cfg)
+
+	s.mu.Lock()
+	if s.shutdown {
+		s.mu.Unlock()
+		return nil
+	}
+	s.server = grpcServer
+	s.mu.Unlock()
+
+	return grpcServer.Serve(lis)
+}
+
+// newServer creates a gRPC server with the interceptors and services
+// registered
+func (s *GRPCServer) newServer(cfg *config.Config) *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		tracingInterceptor(),
+		loggingInterceptor(),
+		deadlineInterceptor(cfg.GRPC.MaxDeadline),
+		tenantInterceptor(cfg.Tenancy.Header),
+	))
 
 	// Register services
 	pb.RegisterAuthorServiceServer(grpcServer, s)
 	pb.RegisterCategoryServiceServer(grpcServer, s)
 	pb.RegisterBookServiceServer(grpcServer, s)
+	pb.RegisterOperationsServiceServer(grpcServer, s)
 	pb.RegisterHealthServiceServer(grpcServer, s)
+	return grpcServer
+}
 
-	log.Printf("Starting gRPC server on %s:%s", cfg.GRPC.Host, cfg.GRPC.Port)
-	return grpcServer.Serve(lis)
+// Shutdown stops accepting connections and waits for in-flight RPCs to
+// finish, cancelling the remaining RPCs when ctx is done
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdown = true
+	grpcServer := s.server
+	s.mu.Unlock()
+	if grpcServer == nil {
+		return nil
+	}
+
+	log.Println("Shutting down gRPC server...")
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		grpcServer.Stop()
+		return ctx.Err()
+	}
 }
 
 // Health Check implementation
@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"bookstore-api/internal/tenancy"
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// tenantInterceptor resolves the tenant of each call from the tenant metadata
+// key or the :authority pseudo-header, falling back to the default tenant, and
+// scopes the call's context to it
+func tenantInterceptor(header string) grpc.UnaryServerInterceptor {
+	key := strings.ToLower(header)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identifier, host := "", ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(key); key != "" && len(values) > 0 {
+				identifier = values[0]
+			}
+			if values := md.Get(":authority"); len(values) > 0 {
+				host = values[0]
+			}
+		}
+
+		tenant, err := tenancy.GetResolver().Resolve(identifier, host)
+		if err != nil {
+			if errors.Is(err, tenancy.ErrUnknownTenant) {
+				return nil, statusError(codes.NotFound, reasonUnknownTenant, "unknown tenant", nil)
+			}
+			return nil, internalError("resolve tenant", err)
+		}
+		return handler(tenancy.WithTenant(ctx, tenant), req)
+	}
+}
@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"bookstore-api/internal/tracing"
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracingInterceptor starts a span for each call, continuing the caller's
+// trace when it sent traceparent metadata, and returns the span's traceparent
+// in the response header
+func tracingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var traceparent string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(tracing.Header); len(values) > 0 {
+				traceparent = values[0]
+			}
+		}
+		ctx, span := tracing.Start(ctx, info.FullMethod, traceparent)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(tracing.Header, span.Traceparent()))
+
+		resp, err := handler(ctx, req)
+		span.End(ctx, slog.String("code", status.Code(err).String()))
+		return resp, err
+	}
+}
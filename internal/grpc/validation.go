@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// invalidArgument returns an InvalidArgument status for a validation error,
+// with a BadRequest detail listing each failed field so clients can point at
+// the fields, as the REST API's fields list does
+func invalidArgument(err error) error {
+	fields, ok := utils.FieldErrors(err)
+	if !ok {
+		return statusError(codes.InvalidArgument, reasonValidationFailed, err.Error(), nil)
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(fields))
+	for i, field := range fields {
+		violations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+			Reason:      strings.ToUpper(field.Rule),
+		}
+	}
+	return statusError(codes.InvalidArgument, reasonValidationFailed, fields.Error(), nil,
+		&errdetails.BadRequest{FieldViolations: violations})
+}
+
+// invalidField returns an InvalidArgument status for a single field that
+// failed the rule
+func invalidField(field, rule, message string) error {
+	return invalidArgument(utils.ValidationErrors{{Field: field, Rule: rule, Message: message}})
+}
+
+// parseSearchQuery parses the query of a search request, returning an
+// InvalidArgument status when it is too long
+func parseSearchQuery(raw string) (services.SearchQuery, error) {
+	query, err := services.ParseSearchQuery(raw)
+	if err != nil {
+		return query, invalidField("query", "max", fmt.Sprintf("query must be at most %d characters", services.MaxSearchQueryLength))
+	}
+	return query, nil
+}
@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"bookstore-api/internal/config"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// gRPC-Web content types. The text variant carries base64-encoded frames, for
+// clients that cannot read binary response bodies.
+const (
+	webContentType     = "application/grpc-web"
+	webTextContentType = "application/grpc-web-text"
+)
+
+// webTrailerFlag marks the frame carrying the trailers in gRPC-Web responses
+const webTrailerFlag = 0x80
+
+// WebHandler returns a handler serving the services to gRPC-Web clients such
+// as browser apps, translating their HTTP/1.1 calls into gRPC calls served in
+// process by a server with the same interceptors, so no proxy is needed. Calls
+// are routed by their path, /bookstore.BookService/GetBook for example; only
+// unary methods are served.
+func (s *GRPCServer) WebHandler(cfg *config.Config) http.Handler {
+	grpcServer := s.newServer(cfg)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWeb(grpcServer, w, r)
+	})
+}
+
+// serveWeb serves a gRPC-Web call with grpcServer
+func serveWeb(grpcServer *grpc.Server, w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, webTextContentType)
+	if !text && !strings.HasPrefix(contentType, webContentType) {
+		http.Error(w, "gRPC-Web calls must be sent as "+webContentType+" or "+webTextContentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// The codec suffix, such as +proto, is kept for the gRPC call
+	codec := strings.TrimPrefix(strings.TrimPrefix(contentType, webTextContentType), webContentType)
+	var body io.Reader = r.Body
+	if text {
+		body = base64.NewDecoder(base64.StdEncoding, r.Body)
+	}
+
+	call := r.Clone(r.Context())
+	call.Proto, call.ProtoMajor, call.ProtoMinor = "HTTP/2.0", 2, 0
+	call.Header.Set("Content-Type", "application/grpc"+codec)
+	call.Header.Del("Content-Length")
+	call.ContentLength = -1
+	call.Body = io.NopCloser(body)
+
+	recorder := newWebRecorder()
+	grpcServer.ServeHTTP(recorder, call)
+	recorder.writeTo(w, contentType, text)
+}
+
+// webRecorder records the gRPC response of a call, telling its trailers from
+// its headers, to write it back as a gRPC-Web response
+type webRecorder struct {
+	header http.Header
+	// sent are the headers as they were when the response started; headers
+	// set later are trailers
+	sent   http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// newWebRecorder creates an empty recorder
+func newWebRecorder() *webRecorder {
+	return &webRecorder{header: make(http.Header)}
+}
+
+// Header returns the headers, and then the trailers, of the response
+func (r *webRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader starts the response
+func (r *webRecorder) WriteHeader(status int) {
+	if r.sent != nil {
+		return
+	}
+	r.status = status
+	r.sent = r.header.Clone()
+}
+
+// Write records response data
+func (r *webRecorder) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(data)
+}
+
+// Flush starts the response, as the gRPC server flushes before setting the
+// trailers
+func (r *webRecorder) Flush() {
+	r.WriteHeader(http.StatusOK)
+}
+
+// trailers returns the trailers of the response: those the headers declared
+// and those set with the http.TrailerPrefix prefix
+func (r *webRecorder) trailers() http.Header {
+	trailers := make(http.Header)
+	for _, declared := range r.sent.Values("Trailer") {
+		for _, name := range strings.Split(declared, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if values := r.header.Values(name); len(values) > 0 {
+				trailers[name] = values
+			}
+		}
+	}
+	for name, values := range r.header {
+		if strings.HasPrefix(name, http.TrailerPrefix) {
+			trailers[http.CanonicalHeaderKey(strings.TrimPrefix(name, http.TrailerPrefix))] = values
+		}
+	}
+	return trailers
+}
+
+// writeTo writes the recorded response as a gRPC-Web response: the message
+// frames followed by a frame holding the trailers, base64-encoded for the
+// text content type
+func (r *webRecorder) writeTo(w http.ResponseWriter, contentType string, text bool) {
+	if r.sent == nil {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.status != http.StatusOK {
+		// The call was refused before reaching a method
+		for name, values := range r.sent {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(r.status)
+		w.Write(r.body.Bytes())
+		return
+	}
+
+	for name, values := range r.sent {
+		switch name {
+		case "Trailer", "Content-Type", "Content-Length":
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	trailers := r.trailers()
+	names := make([]string, 0, len(trailers))
+	for name := range trailers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var block bytes.Buffer
+	for _, name := range names {
+		for _, value := range trailers[name] {
+			block.WriteString(strings.ToLower(name) + ": " + value + "\r\n")
+		}
+	}
+	frame := make([]byte, 5, 5+block.Len())
+	frame[0] = webTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(block.Len()))
+	frame = append(frame, block.Bytes()...)
+
+	if text {
+		io.WriteString(w, base64.StdEncoding.EncodeToString(r.body.Bytes()))
+		io.WriteString(w, base64.StdEncoding.EncodeToString(frame))
+		return
+	}
+	w.Write(r.body.Bytes())
+	w.Write(frame)
+}
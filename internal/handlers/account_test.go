@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStockAlerts(t *testing.T) {
+	body := bookRequest(t)
+	body["stock"] = 0
+	soldOut := createBook(t, body)
+	inStock := createBook(t, bookRequest(t))
+	_, token := userToken(t, "customer")
+
+	expectStatus(t, request(t, fiber.MethodPost, "/books/"+soldOut.String()+"/stock-alert", nil, token), fiber.StatusCreated)
+
+	t.Run("list", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/stock-alerts", nil, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var alerts []struct {
+			BookID string `json:"book_id"`
+		}
+		decode(t, resp, &alerts)
+		if len(alerts) != 1 || alerts[0].BookID != soldOut.String() {
+			t.Fatalf("listed %v, expected only an alert for %s", alerts, soldOut)
+		}
+	})
+	t.Run("book in stock", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/books/"+inStock.String()+"/stock-alert", nil, token), fiber.StatusConflict)
+	})
+	t.Run("api key without a user", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/books/"+soldOut.String()+"/stock-alert", nil, adminToken(t)), fiber.StatusUnauthorized)
+	})
+	t.Run("delete", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodDelete, "/books/"+soldOut.String()+"/stock-alert", nil, token), fiber.StatusOK)
+		expectStatus(t, request(t, fiber.MethodDelete, "/books/"+soldOut.String()+"/stock-alert", nil, token), fiber.StatusNotFound)
+	})
+}
+
+func TestSavedSearches(t *testing.T) {
+	_, token := userToken(t, "customer")
+
+	resp := request(t, fiber.MethodPost, "/saved-searches", map[string]string{"name": "Go books", "query": "golang"}, token)
+	expectStatus(t, resp, fiber.StatusCreated)
+	var search record
+	decode(t, resp, &search)
+
+	t.Run("list", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/saved-searches", nil, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var searches []record
+		decode(t, resp, &searches)
+		if len(searches) != 1 || searches[0].ID != search.ID {
+			t.Fatalf("listed %v, expected only %s", searches, search.ID)
+		}
+	})
+	t.Run("missing query", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/saved-searches", map[string]string{"name": "Nothing"}, token), fiber.StatusBadRequest)
+	})
+	t.Run("another user's search", func(t *testing.T) {
+		_, other := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodDelete, "/saved-searches/"+search.ID.String(), nil, other), fiber.StatusNotFound)
+	})
+	t.Run("delete", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodDelete, "/saved-searches/"+search.ID.String(), nil, token), fiber.StatusOK)
+	})
+	t.Run("requires auth", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/saved-searches", nil, ""), fiber.StatusUnauthorized)
+	})
+}
+
+func TestPreferences(t *testing.T) {
+	_, token := userToken(t, "customer")
+
+	t.Run("consent", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/preferences", map[string]interface{}{"email_marketing": true}, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var preferences struct {
+			EmailMarketing bool `json:"email_marketing"`
+		}
+		decode(t, resp, &preferences)
+		if !preferences.EmailMarketing {
+			t.Fatal("email marketing consent was not given")
+		}
+
+		resp = request(t, fiber.MethodGet, "/preferences/consents", nil, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var history []map[string]interface{}
+		decode(t, resp, &history)
+		if len(history) == 0 {
+			t.Fatal("consent history is empty, expected the consent just given")
+		}
+	})
+	t.Run("policy version too long", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/preferences", map[string]interface{}{
+			"email_marketing": false,
+			"policy_version":  strings.Repeat("v", 51),
+		}, token)
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+	t.Run("api key without a user", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/preferences", nil, adminToken(t)), fiber.StatusUnauthorized)
+	})
+}
+
+func TestNotificationSettings(t *testing.T) {
+	_, token := userToken(t, "customer")
+
+	t.Run("channels", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/preferences/notifications", map[string]interface{}{
+			"channels": map[string][]string{"back_in_stock": {"email", "push"}},
+		}, token)
+		expectStatus(t, resp, fiber.StatusOK)
+
+		resp = request(t, fiber.MethodGet, "/preferences/notifications", nil, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var settings struct {
+			Channels map[string][]string `json:"channels"`
+		}
+		decode(t, resp, &settings)
+		if got := strings.Join(settings.Channels["back_in_stock"], ","); got != "email,push" {
+			t.Fatalf("back_in_stock goes over %q, expected email,push", got)
+		}
+	})
+	t.Run("unknown channel", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/preferences/notifications", map[string]interface{}{
+			"channels": map[string][]string{"back_in_stock": {"pigeon"}},
+		}, token)
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+	t.Run("admin topic", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/preferences/notifications", map[string]interface{}{
+			"channels": map[string][]string{"low_stock": {"email"}},
+		}, token)
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+	t.Run("requires auth", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/preferences/notifications", nil, ""), fiber.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"bookstore-api/internal/backup"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/maintenance"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/scheduler"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/slowquery"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	scheduler         *scheduler.Scheduler
+	onixService       *services.ONIXService
+	statsService      *services.StatsService
+	maintenance       *services.MaintenanceService
+	queue             *jobs.Queue
+	lowStockThreshold int
+	importBatchSize   int
+}
+
+// SetLogLevelRequest represents the request body for changing the log level
+type SetLogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error"`
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(cfg *config.Config, svc *services.Services) *AdminHandler {
+	return &AdminHandler{
+		scheduler:         scheduler.GetScheduler(),
+		onixService:       svc.ONIX,
+		statsService:      svc.Stats,
+		maintenance:       svc.Maintenance,
+		queue:             jobs.GetQueue(),
+		lowStockThreshold: cfg.Scheduler.LowStockThreshold,
+		importBatchSize:   cfg.Import.BatchSize,
+	}
+}
+
+// GetScheduledTasks returns the schedule and last-run status of each scheduled task
+func (h *AdminHandler) GetScheduledTasks(c *fiber.Ctx) error {
+	return response.OK(c, "Scheduled tasks retrieved successfully", h.scheduler.Status())
+}
+
+// RunScheduledTask triggers a scheduled task immediately
+func (h *AdminHandler) RunScheduledTask(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	if err := h.scheduler.RunNow(name); err != nil {
+		switch err.Error() {
+		case "task not found":
+			return response.Error(c, fiber.StatusNotFound, "Scheduled task not found", nil)
+		case "task already running":
+			return response.Error(c, fiber.StatusConflict, "Scheduled task is already running", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to run scheduled task", err)
+	}
+
+	return response.Accepted(c, "Scheduled task started", nil)
+}
+
+// ReindexSearch rebuilds the search indexes of the catalog tables for every tenant
+func (h *AdminHandler) ReindexSearch(c *fiber.Ctx) error {
+	tables, err := h.maintenance.WithContext(c.UserContext()).ReindexSearch()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to reindex search", err)
+	}
+
+	return response.OK(c, "Search reindexed successfully", fiber.Map{"tables": tables})
+}
+
+// GetLogLevel returns the current log level
+func (h *AdminHandler) GetLogLevel(c *fiber.Ctx) error {
+	return response.OK(c, "Log level retrieved successfully", fiber.Map{"level": utils.GetLogLevel().String()})
+}
+
+// SetLogLevel changes the log level of this server process, including the
+// logging of gRPC calls, until it restarts
+func (h *AdminHandler) SetLogLevel(c *fiber.Ctx) error {
+	var req SetLogLevelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	level, err := utils.ParseLogLevel(req.Level)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid log level", err)
+	}
+	previous := utils.GetLogLevel()
+	utils.SetLogLevel(level)
+	utils.LogWarn("Log level changed", map[string]interface{}{
+		"from": previous.String(),
+		"to":   level.String(),
+	})
+
+	return response.OK(c, "Log level updated successfully", fiber.Map{"level": level.String()})
+}
+
+// EnqueueReindex schedules a background rebuild of the search indexes and,
+// when the read models are enabled, the book listings of every tenant. The
+// job's progress is reported at GET /admin/jobs/:id.
+func (h *AdminHandler) EnqueueReindex(c *fiber.Ctx) error {
+	return h.enqueueMaintenance(c, maintenance.ReindexJobType, "Reindex scheduled")
+}
+
+// EnqueueCacheFlush schedules a background flush of the in-memory caches.
+// The job's progress is reported at GET /admin/jobs/:id.
+func (h *AdminHandler) EnqueueCacheFlush(c *fiber.Ctx) error {
+	return h.enqueueMaintenance(c, maintenance.CacheFlushJobType, "Cache flush scheduled")
+}
+
+// enqueueMaintenance schedules a maintenance job and responds 202 with it
+func (h *AdminHandler) enqueueMaintenance(c *fiber.Ctx, jobType, message string) error {
+	job, err := h.queue.EnqueueContext(c.UserContext(), jobType, struct{}{})
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to schedule job", err)
+	}
+
+	return response.Accepted(c, message, job)
+}
+
+// GetJob returns a background job with its status and progress
+func (h *AdminHandler) GetJob(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "job")
+	if !ok {
+		return err
+	}
+
+	job, err := h.queue.GetJob(id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			return response.Error(c, fiber.StatusNotFound, "Job not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get job", err)
+	}
+
+	return response.OK(c, "Job retrieved successfully", job)
+}
+
+// RebuildStock recomputes the stock of every book from its stock movements for every tenant
+func (h *AdminHandler) RebuildStock(c *fiber.Ctx) error {
+	rebuilt, err := h.maintenance.WithContext(c.UserContext()).RebuildStock()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to rebuild stock", err)
+	}
+
+	return response.OK(c, "Stock rebuilt successfully", fiber.Map{"books_corrected": rebuilt})
+}
+
+// RefreshStatsViews recomputes the materialized views behind the aggregate statistics for every tenant
+func (h *AdminHandler) RefreshStatsViews(c *fiber.Ctx) error {
+	views, err := h.maintenance.WithContext(c.UserContext()).RefreshStatsViews()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to refresh statistics", err)
+	}
+
+	return response.OK(c, "Statistics refreshed successfully", fiber.Map{"views": views})
+}
+
+// GetPartitions lists the monthly partitions of the partitioned log tables
+func (h *AdminHandler) GetPartitions(c *fiber.Ctx) error {
+	partitions, err := h.maintenance.WithContext(c.UserContext()).GetPartitions()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to list partitions", err)
+	}
+
+	return response.OK(c, "Partitions retrieved successfully", partitions)
+}
+
+// GetSlowQueries lists the most recent statements slower than the slow query
+// threshold, newest first, with their parameters and captured plans
+func (h *AdminHandler) GetSlowQueries(c *fiber.Ctx) error {
+	entries := []slowquery.Entry{}
+	if log := slowquery.GetLog(); log != nil {
+		entries = log.Entries()
+	}
+
+	return response.OK(c, "Slow queries retrieved successfully", entries)
+}
+
+// ClearSlowQueries discards the slow statements kept
+func (h *AdminHandler) ClearSlowQueries(c *fiber.Ctx) error {
+	if log := slowquery.GetLog(); log != nil {
+		log.Clear()
+	}
+
+	return response.OK(c, "Slow queries cleared successfully", nil)
+}
+
+// ImportONIX imports an ONIX 3.0 feed sent as the multipart "file" field or as an XML request body
+func (h *AdminHandler) ImportONIX(c *fiber.Ctx) error {
+	var reader io.Reader
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read uploaded file", err)
+		}
+		defer file.Close()
+		reader = file
+	} else if len(c.Body()) > 0 && !strings.HasPrefix(c.Get("Content-Type"), "multipart/") {
+		reader = bytes.NewReader(c.Body())
+	} else {
+		return response.Error(c, fiber.StatusBadRequest, "ONIX file is required", nil)
+	}
+
+	opts := services.ONIXImportOptions{
+		DryRun:    c.QueryBool("dry_run", false),
+		BatchSize: c.QueryInt("batch_size", h.importBatchSize),
+	}
+	report, err := h.onixService.WithContext(c.UserContext()).Import(reader, opts)
+	if err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, response.Envelope{
+			Message: "Failed to import ONIX feed",
+			Details: err.Error(),
+			Data:    report,
+		})
+	}
+
+	return response.OK(c, "ONIX feed imported", report)
+}
+
+// DiffCatalog compares two catalog archives uploaded as the before and after
+// files, such as backups taken around an import, and returns the titles
+// added, removed, and changed with field-level differences
+func (h *AdminHandler) DiffCatalog(c *fiber.Ctx) error {
+	archives := make([]io.Reader, 0, 2)
+	for _, name := range []string{"before", "after"} {
+		fileHeader, err := c.FormFile(name)
+		if err != nil {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Catalog archives are required", "upload the archives to compare as the before and after files")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read uploaded file", err)
+		}
+		defer file.Close()
+		archives = append(archives, file)
+	}
+
+	diff, err := backup.Diff(archives[0], archives[1])
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Failed to compare catalog archives", err)
+	}
+
+	return response.OK(c, "Catalog archives compared successfully", diff)
+}
+
+// maxStatsTop caps the top parameter of the statistics endpoints
+const maxStatsTop = 50
+
+// GetStats returns catalog statistics for back-office dashboards. The optional
+// from and to parameters (YYYY-MM-DD or RFC 3339) bound the new title trend and
+// new counts, which default to the last 30 days grouped by day.
+func (h *AdminHandler) GetStats(c *fiber.Ctx) error {
+	top, err := getCountParam(c, "top", 0, maxStatsTop)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid statistics parameters", err)
+	}
+	opts := services.StatsOptions{
+		Period:   c.Query("period"),
+		TopLimit: top,
+		LowStock: tenancy.FromContext(c.UserContext()).LowStockThresholdOr(h.lowStockThreshold),
+	}
+
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &opts.From}, {"to", &opts.To}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		t, dateOnly, err := parseStatsDate(value)
+		if err != nil {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid "+param.name+" date", "use YYYY-MM-DD or an RFC 3339 timestamp")
+		}
+		// A date-only "to" includes the whole day
+		if dateOnly && param.name == "to" {
+			t = t.AddDate(0, 0, 1)
+		}
+		*param.target = t
+	}
+
+	report, err := h.statsService.WithContext(c.UserContext()).GetStats(opts)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid period") || err.Error() == "from must be before to" {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid statistics parameters", err)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to compute statistics", err)
+	}
+
+	return response.OK(c, "Statistics retrieved successfully", report)
+}
+
+// GetRentalStats returns the most rented books and daily rentals, read from
+// the rental statistics view refreshed by the stats_refresh task. The optional
+// from and to parameters (YYYY-MM-DD or RFC 3339) default to the last 30 days.
+func (h *AdminHandler) GetRentalStats(c *fiber.Ctx) error {
+	top, err := getCountParam(c, "top", 0, maxStatsTop)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid statistics parameters", err)
+	}
+	opts := services.RentalStatsOptions{
+		TopLimit: top,
+	}
+
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &opts.From}, {"to", &opts.To}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		t, dateOnly, err := parseStatsDate(value)
+		if err != nil {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid "+param.name+" date", "use YYYY-MM-DD or an RFC 3339 timestamp")
+		}
+		// A date-only "to" includes the whole day
+		if dateOnly && param.name == "to" {
+			t = t.AddDate(0, 0, 1)
+		}
+		*param.target = t
+	}
+
+	report, err := h.statsService.WithContext(c.UserContext()).GetRentalStats(opts)
+	if err != nil {
+		if err.Error() == "from must be before to" {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid statistics parameters", err)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to compute rental statistics", err)
+	}
+
+	return response.OK(c, "Rental statistics retrieved successfully", report)
+}
+
+// parseStatsDate parses a date or RFC 3339 timestamp, reporting whether only a date was given
+func parseStatsDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
@@ -0,0 +1,277 @@
+package handlers_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestAdminStats(t *testing.T) {
+	t.Run("top", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/stats?top=5", nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("top over the cap", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/stats?top=51", nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("invalid date", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/stats?from=yesterday", nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+}
+
+func TestUsers(t *testing.T) {
+	email := unique("user") + "@example.com"
+	body := map[string]interface{}{"email": email, "name": "Test User", "role": "customer"}
+	resp := request(t, fiber.MethodPost, "/admin/users", body, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var user record
+	decode(t, resp, &user)
+
+	t.Run("duplicate email", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/admin/users", body, adminToken(t))
+		expectStatus(t, resp, fiber.StatusConflict)
+		if resp.Code != "duplicate_email" || resp.Field != "email" {
+			t.Fatalf("conflict has code %q and field %q, expected duplicate_email on email", resp.Code, resp.Field)
+		}
+	})
+	t.Run("set role", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/admin/users/"+user.ID.String()+"/role", map[string]interface{}{"role": "admin"}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+	})
+	t.Run("invalid role", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/admin/users/"+user.ID.String()+"/role", map[string]interface{}{"role": "owner"}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+}
+
+func TestReportDownloads(t *testing.T) {
+	for _, tt := range []struct {
+		format      string
+		contentType string
+		prefix      string
+	}{
+		{"csv", "text/csv", ""},
+		{"pdf", "application/pdf", "%PDF-"},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			resp := request(t, fiber.MethodPost, "/admin/reports", map[string]interface{}{
+				"name":        unique("inventory"),
+				"report_type": "inventory",
+				"format":      tt.format,
+				"schedule":    "0 6 * * 1",
+				"recipients":  []string{"reports@example.com"},
+			}, adminToken(t))
+			expectStatus(t, resp, fiber.StatusCreated)
+			var report record
+			decode(t, resp, &report)
+
+			req := httptest.NewRequest(fiber.MethodGet, "/api/v1/admin/reports/"+report.ID.String()+"/download", nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken(t))
+			download, err := env.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer download.Body.Close()
+			content, err := io.ReadAll(download.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if download.StatusCode != fiber.StatusOK {
+				t.Fatalf("status is %d (%s), expected %d", download.StatusCode, content, fiber.StatusOK)
+			}
+			if got := download.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, tt.contentType) {
+				t.Fatalf("content type is %q, expected %s", got, tt.contentType)
+			}
+			if !bytes.HasPrefix(content, []byte(tt.prefix)) || len(content) == 0 {
+				t.Fatalf("report starts %q, expected %q", content[:min(len(content), 16)], tt.prefix)
+			}
+		})
+	}
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/admin/reports", map[string]interface{}{
+			"name":        unique("inventory"),
+			"report_type": "inventory",
+			"schedule":    "every monday",
+			"recipients":  []string{"reports@example.com"},
+		}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+}
+
+func TestSignedDownloadLinks(t *testing.T) {
+	resp := request(t, fiber.MethodPost, "/admin/reports", map[string]interface{}{
+		"name":        unique("inventory"),
+		"report_type": "inventory",
+		"format":      "csv",
+		"schedule":    "0 6 * * 1",
+		"recipients":  []string{"reports@example.com"},
+	}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var report record
+	decode(t, resp, &report)
+
+	resp = request(t, fiber.MethodPost, "/admin/reports/"+report.ID.String()+"/download-link", nil, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var link struct {
+		URL string `json:"url"`
+	}
+	decode(t, resp, &link)
+	signed, err := url.Parse(link.URL)
+	if err != nil {
+		t.Fatalf("link %q is not a URL: %v", link.URL, err)
+	}
+
+	t.Run("download without a token", func(t *testing.T) {
+		resp, body := download(t, signed.RequestURI(), "")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusOK)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, "text/csv") {
+			t.Fatalf("content type is %q, expected text/csv", got)
+		}
+	})
+	t.Run("altered link", func(t *testing.T) {
+		altered := strings.Replace(signed.RequestURI(), report.ID.String(), uuid.NewString(), 1)
+		resp, body := download(t, altered, "")
+		if resp.StatusCode != fiber.StatusForbidden {
+			t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusForbidden)
+		}
+	})
+	t.Run("unsigned", func(t *testing.T) {
+		resp, body := download(t, signed.Path, "")
+		if resp.StatusCode != fiber.StatusForbidden {
+			t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusForbidden)
+		}
+	})
+	t.Run("link requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/reports/"+report.ID.String()+"/download-link", nil, token), fiber.StatusForbidden)
+	})
+}
+
+func TestTenants(t *testing.T) {
+	body := map[string]interface{}{"slug": unique("shop"), "name": "Test Shop"}
+	resp := request(t, fiber.MethodPost, "/admin/tenants", body, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var tenant record
+	decode(t, resp, &tenant)
+
+	t.Run("get", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/tenants/"+tenant.ID.String(), nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("duplicate slug", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/tenants", body, adminToken(t)), fiber.StatusConflict)
+	})
+	t.Run("invalid slug", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/admin/tenants", map[string]interface{}{"slug": "test_shop", "name": "Test Shop"}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		if rules := fieldErrors(t, resp.Fields); rules["slug"] != "slug" {
+			t.Fatalf("failed fields are %v, expected slug to break slug", rules)
+		}
+	})
+	t.Run("invalid domain", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/admin/tenants", map[string]interface{}{
+			"slug":   unique("shop"),
+			"name":   "Test Shop",
+			"domain": "not a domain",
+		}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		if rules := fieldErrors(t, resp.Fields); rules["domain"] != "fqdn" {
+			t.Fatalf("failed fields are %v, expected domain to break fqdn", rules)
+		}
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/tenants", nil, token), fiber.StatusForbidden)
+	})
+}
+
+func TestDeadLetters(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/dead-letters", nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("invalid failed_before", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/dead-letters?failed_before=yesterday", nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("purge by type", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/admin/dead-letters/purge", map[string]interface{}{"type": unique("job")}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var purged struct {
+			Purged int64 `json:"purged"`
+		}
+		decode(t, resp, &purged)
+		if purged.Purged != 0 {
+			t.Fatalf("purged %d jobs of an unknown type, expected none", purged.Purged)
+		}
+	})
+	t.Run("retry without a selection", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/dead-letters/retry", map[string]interface{}{}, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("retry unknown job", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/dead-letters/"+uuid.NewString()+"/retry", nil, adminToken(t)), fiber.StatusNotFound)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/dead-letters", nil, ""), fiber.StatusUnauthorized)
+	})
+}
+
+func TestSecurityEvents(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/security-events", nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("invalid user", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/security-events?user_id=someone", nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/security-events", nil, token), fiber.StatusForbidden)
+	})
+}
+
+func TestArchives(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/archives", nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("not found", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/archives/"+uuid.NewString(), nil, adminToken(t)), fiber.StatusNotFound)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/archives", nil, token), fiber.StatusForbidden)
+	})
+}
+
+func TestOperations(t *testing.T) {
+	resp := request(t, fiber.MethodGet, "/books/export?async=true", nil, adminToken(t))
+	expectStatus(t, resp, fiber.StatusAccepted)
+	var operation record
+	decode(t, resp, &operation)
+
+	t.Run("get", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/operations/"+operation.ID.String(), nil, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var got record
+		decode(t, resp, &got)
+		if got.ID != operation.ID {
+			t.Fatalf("got operation %s, expected %s", got.ID, operation.ID)
+		}
+	})
+	t.Run("download before completion", func(t *testing.T) {
+		// No workers run in tests, so the export stays queued
+		expectStatus(t, request(t, fiber.MethodGet, "/operations/"+operation.ID.String()+"/download", nil, adminToken(t)), fiber.StatusConflict)
+	})
+	t.Run("not found", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/operations/"+uuid.NewString(), nil, adminToken(t)), fiber.StatusNotFound)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodGet, "/operations/"+operation.ID.String(), nil, token), fiber.StatusForbidden)
+	})
+}
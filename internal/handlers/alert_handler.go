@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AlertHandler handles the current user's saved searches and stock alerts
+type AlertHandler struct {
+	alertService *services.AlertService
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(svc *services.Services) *AlertHandler {
+	return &AlertHandler{
+		alertService: svc.Alerts,
+	}
+}
+
+// CreateSavedSearchRequest represents the request payload for saving a search.
+// The name defaults to the query.
+type CreateSavedSearchRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// CreateSavedSearch saves a book search; the user is emailed when books added
+// later match it
+func (h *AlertHandler) CreateSavedSearch(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	var req CreateSavedSearchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	search, err := h.alertService.WithContext(c.UserContext()).CreateSavedSearch(userID, req.Name, req.Query)
+	if err != nil {
+		return alertError(c, "Failed to save search", err)
+	}
+
+	return response.Created(c, "Search saved successfully", search)
+}
+
+// GetSavedSearches lists the current user's saved searches
+func (h *AlertHandler) GetSavedSearches(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	searches, err := h.alertService.WithContext(c.UserContext()).GetSavedSearches(userID)
+	if err != nil {
+		return alertError(c, "Failed to get saved searches", err)
+	}
+
+	return response.OK(c, "Saved searches retrieved successfully", searches)
+}
+
+// DeleteSavedSearch deletes one of the current user's saved searches
+func (h *AlertHandler) DeleteSavedSearch(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+	id, ok, err := parseUUIDParam(c, "id", "saved search")
+	if !ok {
+		return err
+	}
+
+	if err := h.alertService.WithContext(c.UserContext()).DeleteSavedSearch(id, userID); err != nil {
+		return alertError(c, "Failed to delete saved search", err)
+	}
+
+	return response.OK(c, "Saved search deleted successfully", nil)
+}
+
+// CreateStockAlert subscribes the current user to the out-of-stock book in the
+// :id route parameter coming back in stock
+func (h *AlertHandler) CreateStockAlert(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+	bookID, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	alert, err := h.alertService.WithContext(c.UserContext()).CreateStockAlert(bookID, userID)
+	if err != nil {
+		return alertError(c, "Failed to create stock alert", err)
+	}
+
+	return response.Created(c, "Stock alert created successfully", alert)
+}
+
+// GetStockAlerts lists the current user's stock alerts with their books
+func (h *AlertHandler) GetStockAlerts(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	alerts, err := h.alertService.WithContext(c.UserContext()).GetStockAlerts(userID)
+	if err != nil {
+		return alertError(c, "Failed to get stock alerts", err)
+	}
+
+	return response.OK(c, "Stock alerts retrieved successfully", alerts)
+}
+
+// DeleteStockAlert unsubscribes the current user from the book in the :id
+// route parameter coming back in stock
+func (h *AlertHandler) DeleteStockAlert(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+	bookID, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	if err := h.alertService.WithContext(c.UserContext()).DeleteStockAlert(bookID, userID); err != nil {
+		return alertError(c, "Failed to delete stock alert", err)
+	}
+
+	return response.OK(c, "Stock alert deleted successfully", nil)
+}
+
+// alertError responds to an alert service error, mapping invalid saved
+// searches to 400, missing records to 404, and books in stock to 409
+func alertError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid saved search: "):
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid saved search: "))
+	case err.Error() == "book not found", err.Error() == "saved search not found", err.Error() == "stock alert not found":
+		return response.Error(c, fiber.StatusNotFound, strings.ToUpper(err.Error()[:1])+err.Error()[1:], nil)
+	case err.Error() == "book is in stock":
+		return response.Error(c, fiber.StatusConflict, "Book is in stock", nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, message, err)
+}
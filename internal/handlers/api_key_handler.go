@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHandler handles API key management HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(svc *services.Services) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: svc.APIKeys,
+	}
+}
+
+// CreateAPIKeyRequest represents the request payload for creating an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=255"`
+	Role      string     `json:"role,omitempty" validate:"omitempty,oneof=customer admin"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyWithSecret is returned once on creation and rotation so the client can store the key
+type APIKeyWithSecret struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey creates an API key, acting as an admin unless another role is given
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return validationFailed(c, utils.ValidationErrors{{
+			Field:   "expires_at",
+			Rule:    "future",
+			Message: "expires_at must be in the future",
+		}})
+	}
+	if req.Role == "" {
+		req.Role = models.RoleAdmin
+	}
+
+	apiKey, key, err := h.apiKeyService.WithContext(c.UserContext()).CreateAPIKey(req.Name, req.Role, req.ExpiresAt)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create API key", err)
+	}
+
+	return response.Created(c, "API key created successfully", APIKeyWithSecret{APIKey: apiKey, Key: key})
+}
+
+// GetAllAPIKeys retrieves all API keys with pagination
+func (h *APIKeyHandler) GetAllAPIKeys(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	apiKeys, total, err := h.apiKeyService.WithContext(c.UserContext()).GetAllAPIKeys(page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get API keys", err)
+	}
+
+	return response.Paginated(c, "API keys retrieved successfully", apiKeys, page, limit, total)
+}
+
+// RotateAPIKey replaces an API key with a new one
+func (h *APIKeyHandler) RotateAPIKey(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "API key")
+	if !ok {
+		return err
+	}
+
+	apiKey, key, err := h.apiKeyService.WithContext(c.UserContext()).RotateAPIKey(id)
+	if err != nil {
+		switch err.Error() {
+		case "api key not found":
+			return response.Error(c, fiber.StatusNotFound, "API key not found", nil)
+		case "api key is revoked":
+			return response.Error(c, fiber.StatusConflict, "API key is revoked", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to rotate API key", err)
+	}
+
+	return response.OK(c, "API key rotated successfully", APIKeyWithSecret{APIKey: apiKey, Key: key})
+}
+
+// RevokeAPIKey permanently disables an API key
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "API key")
+	if !ok {
+		return err
+	}
+
+	if err := h.apiKeyService.WithContext(c.UserContext()).RevokeAPIKey(id); err != nil {
+		if err.Error() == "api key not found" {
+			return response.Error(c, fiber.StatusNotFound, "API key not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to revoke API key", err)
+	}
+
+	return response.OK(c, "API key revoked successfully", nil)
+}
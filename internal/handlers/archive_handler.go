@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ArchiveHandler handles archive manifest HTTP requests
+type ArchiveHandler struct {
+	archiveService *services.ArchiveService
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(svc *services.Services) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: svc.Archives,
+	}
+}
+
+// GetArchives lists the archives written to cold storage, optionally filtered
+// by the table query parameter
+func (h *ArchiveHandler) GetArchives(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	manifests, total, err := h.archiveService.WithContext(c.UserContext()).GetManifests(c.Query("table"), page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get archives", err)
+	}
+
+	return response.Paginated(c, "Archives retrieved successfully", manifests, page, limit, total)
+}
+
+// GetArchive retrieves the manifest of an archive by ID
+func (h *ArchiveHandler) GetArchive(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "archive")
+	if !ok {
+		return err
+	}
+
+	manifest, err := h.archiveService.WithContext(c.UserContext()).GetManifest(id)
+	if err != nil {
+		if err.Error() == "archive not found" {
+			return response.Error(c, fiber.StatusNotFound, "Archive not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get archive", err)
+	}
+
+	return response.OK(c, "Archive retrieved successfully", manifest)
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AuthHandler handles social login HTTP requests
+type AuthHandler struct {
+	userService     *services.UserService
+	sessionService  *services.SessionService
+	security        *services.SecurityService
+	issuer          *auth.Issuer
+	providers       map[string]auth.Provider
+	callbackBaseURL string
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(cfg *config.Config, svc *services.Services) *AuthHandler {
+	return &AuthHandler{
+		userService:     svc.Users,
+		sessionService:  svc.Sessions,
+		security:        svc.Security,
+		issuer:          auth.GetIssuer(),
+		providers:       auth.NewProviders(cfg.Auth),
+		callbackBaseURL: strings.TrimRight(cfg.Auth.CallbackBaseURL, "/"),
+	}
+}
+
+// LoginResponse is returned after a successful social login or token refresh
+type LoginResponse struct {
+	AccessToken      string       `json:"access_token"`
+	TokenType        string       `json:"token_type"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	SessionID        uuid.UUID    `json:"session_id"`
+	User             *models.User `json:"user"`
+}
+
+// RefreshRequest represents the request payload for refreshing an access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// SessionResponse is an active session as listed to its user
+type SessionResponse struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+// GetProviders lists the configured identity providers
+func (h *AuthHandler) GetProviders(c *fiber.Ctx) error {
+	return response.OK(c, "Providers retrieved successfully", auth.ProviderNames(h.providers))
+}
+
+// Login redirects the user to the identity provider's sign-in page
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	provider, ok := h.providers[c.Params("provider")]
+	if !ok {
+		return response.Error(c, fiber.StatusNotFound, "Identity provider not configured", nil)
+	}
+
+	state, err := auth.NewLoginState(provider.Name())
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to start login", err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     auth.StateCookie,
+		Value:    h.issuer.EncodeState(state),
+		Path:     "/api/v1/auth",
+		MaxAge:   int(auth.StateTTL.Seconds()),
+		Secure:   strings.HasPrefix(h.callbackBaseURL, "https://"),
+		HTTPOnly: true,
+		SameSite: "Lax",
+	})
+
+	return c.Redirect(provider.AuthCodeURL(state.State, state.Challenge(), h.redirectURI(provider)), fiber.StatusFound)
+}
+
+// Callback completes a social login and returns an access token for the local user
+func (h *AuthHandler) Callback(c *fiber.Ctx) error {
+	provider, ok := h.providers[c.Params("provider")]
+	if !ok {
+		return response.Error(c, fiber.StatusNotFound, "Identity provider not configured", nil)
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		return response.ErrorWithDetails(c, fiber.StatusUnauthorized, "Login was not completed", errCode)
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		return response.Error(c, fiber.StatusBadRequest, "Authorization code required", nil)
+	}
+
+	security := h.security.WithContext(c.UserContext())
+	attempt := loginAttempt(c)
+
+	state, err := h.issuer.DecodeState(c.Cookies(auth.StateCookie), provider.Name(), c.Query("state"))
+	c.ClearCookie(auth.StateCookie)
+	if err != nil {
+		security.Failed(models.SecurityEventLoginFailed, attempt, "invalid login state")
+		return response.Error(c, fiber.StatusBadRequest, "Invalid or expired login state", nil)
+	}
+
+	accessToken, err := provider.Exchange(c.Context(), code, state.Verifier, h.redirectURI(provider))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadGateway, "Failed to exchange authorization code", err)
+	}
+
+	identity, err := provider.Identity(c.Context(), accessToken)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadGateway, "Failed to fetch user identity", err)
+	}
+
+	user, err := h.userService.WithContext(c.UserContext()).LoginWithIdentity(provider.Name(), identity)
+	if err != nil {
+		if err.Error() == "user account is disabled" {
+			security.Failed(models.SecurityEventLoginFailed, attempt, "account disabled")
+			return response.Error(c, fiber.StatusForbidden, "User account is disabled", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to sign in", err)
+	}
+
+	attempt.UserID = &user.ID
+	if wait := security.LockedOut(attempt); wait > 0 {
+		security.Record(models.SecurityEventLockedOutAttempt, attempt, models.StringMap{"path": c.Path()})
+		return lockedOutResponse(c, wait)
+	}
+	security.LoginSucceeded(attempt)
+
+	session, refreshToken, err := h.sessionService.WithContext(c.UserContext()).CreateSession(user.ID, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create session", err)
+	}
+
+	return h.tokenResponse(c, "Signed in successfully", session, user, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	sessionService := h.sessionService.WithContext(c.UserContext())
+	security := h.security.WithContext(c.UserContext())
+	attempt := loginAttempt(c)
+
+	// Failures with a token that belongs to a session count against its user
+	userID, found, err := sessionService.RefreshTokenUser(req.RefreshToken)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to refresh session", err)
+	}
+	if found {
+		attempt.UserID = &userID
+		if wait := security.LockedOut(attempt); wait > 0 {
+			security.Record(models.SecurityEventLockedOutAttempt, attempt, models.StringMap{"path": c.Path()})
+			return lockedOutResponse(c, wait)
+		}
+	}
+
+	session, user, refreshToken, err := sessionService.RefreshSession(req.RefreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		switch err.Error() {
+		case "invalid refresh token", "refresh token reused":
+			eventType := models.SecurityEventRefreshFailed
+			if err.Error() == "refresh token reused" {
+				eventType = models.SecurityEventRefreshReused
+			}
+			security.Failed(eventType, attempt, err.Error())
+			return response.Error(c, fiber.StatusUnauthorized, "Invalid or expired refresh token", nil)
+		case "user account is disabled":
+			security.Failed(models.SecurityEventRefreshFailed, attempt, "account disabled")
+			return response.Error(c, fiber.StatusForbidden, "User account is disabled", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to refresh session", err)
+	}
+
+	security.RefreshSucceeded(attempt)
+
+	return h.tokenResponse(c, "Session refreshed successfully", session, user, refreshToken)
+}
+
+// GetSessions lists the signed-in user's active sessions
+func (h *AuthHandler) GetSessions(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	sessions, err := h.sessionService.WithContext(c.UserContext()).GetUserSessions(userID)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get sessions", err)
+	}
+
+	current, _ := c.Locals("session_id").(string)
+	result := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		result[i] = SessionResponse{Session: session, Current: session.ID.String() == current}
+	}
+
+	return response.OK(c, "Sessions retrieved successfully", result)
+}
+
+// RevokeSession signs one of the user's devices out
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	sessionID, ok, err := parseUUIDParam(c, "id", "session")
+	if !ok {
+		return err
+	}
+
+	if err := h.sessionService.WithContext(c.UserContext()).RevokeSession(userID, sessionID); err != nil {
+		if err.Error() == "session not found" {
+			return response.Error(c, fiber.StatusNotFound, "Session not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to revoke session", err)
+	}
+
+	return response.OK(c, "Session revoked successfully", nil)
+}
+
+// RevokeAllSessions signs the user out of every device, including the current one
+func (h *AuthHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	revoked, err := h.sessionService.WithContext(c.UserContext()).RevokeAllSessions(userID)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to revoke sessions", err)
+	}
+
+	return response.OK(c, "Sessions revoked successfully", fiber.Map{"revoked": revoked})
+}
+
+// Logout revokes the session of the presented access token
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	current, _ := c.Locals("session_id").(string)
+	sessionID, err := uuid.Parse(current)
+	if err != nil {
+		return notUserTokenResponse(c)
+	}
+
+	if err := h.sessionService.WithContext(c.UserContext()).RevokeSession(userID, sessionID); err != nil && err.Error() != "session not found" {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to sign out", err)
+	}
+
+	return response.OK(c, "Signed out successfully", nil)
+}
+
+// Me returns the signed-in user
+func (h *AuthHandler) Me(c *fiber.Ctx) error {
+	id, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	user, err := h.userService.WithContext(c.UserContext()).GetUserByID(id)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get user", err)
+	}
+
+	return response.OK(c, "User retrieved successfully", user)
+}
+
+// redirectURI returns the callback URL registered with the provider
+func (h *AuthHandler) redirectURI(provider auth.Provider) string {
+	return h.callbackBaseURL + "/api/v1/auth/" + provider.Name() + "/callback"
+}
+
+// tokenResponse issues an access token for the session and returns it with the refresh token
+func (h *AuthHandler) tokenResponse(c *fiber.Ctx, message string, session *models.Session, user *models.User, refreshToken string) error {
+	email := ""
+	if user.Email != nil {
+		email = *user.Email
+	}
+	token, expiresAt, err := h.issuer.Issue(user.ID, session.ID, user.TenantID, user.Role, email)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to issue access token", err)
+	}
+
+	return response.OK(c, message, LoginResponse{
+		AccessToken:      token,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
+		User:             user,
+	})
+}
+
+// loginAttempt describes the request as a sign-in attempt for the security service
+func loginAttempt(c *fiber.Ctx) services.LoginAttempt {
+	return services.LoginAttempt{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// lockedOutResponse rejects an attempt for a locked out account, telling the
+// client when it may try again
+func lockedOutResponse(c *fiber.Ctx, wait time.Duration) error {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return response.Fail(c, fiber.StatusTooManyRequests, response.Envelope{
+		Code:    "locked_out",
+		Message: "Too many failed attempts. Please try again later.",
+	})
+}
+
+// currentUserID returns the user of an access token issued by social login
+func currentUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	userID, _ := c.Locals("user_id").(string)
+	id, err := uuid.Parse(userID)
+	return id, err == nil
+}
+
+// isAdmin reports whether the request was authenticated with the admin role
+func isAdmin(c *fiber.Ctx) bool {
+	role, _ := c.Locals("user_role").(string)
+	return role == models.RoleAdmin
+}
+
+// notUserTokenResponse rejects legacy tokens that do not identify a user
+func notUserTokenResponse(c *fiber.Ctx) error {
+	return response.Error(c, fiber.StatusUnauthorized, "Token does not belong to a user", nil)
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHealth(t *testing.T) {
+	resp, err := env.Do(httptest.NewRequest(fiber.MethodGet, "/health", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status is %d, expected %d", resp.StatusCode, fiber.StatusOK)
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	body := map[string]interface{}{"name": "Unauthorized", "email": "unauthorized@example.com"}
+
+	t.Run("missing token", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/authors", body, ""), fiber.StatusUnauthorized)
+	})
+	t.Run("unknown token", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/authors", body, "not-a-token"), fiber.StatusUnauthorized)
+	})
+	t.Run("public routes need no token", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books", nil, ""), fiber.StatusOK)
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	resp := request(t, fiber.MethodPost, "/admin/api-keys", map[string]interface{}{
+		"name": unique("customer key"),
+		"role": "customer",
+	}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var created struct {
+		record
+		Role string `json:"role"`
+		Key  string `json:"key"`
+	}
+	decode(t, resp, &created)
+	if created.Key == "" || created.Role != "customer" {
+		t.Fatalf("created key has role %q and key %q, expected a customer key", created.Role, created.Key)
+	}
+
+	t.Run("customer keys read", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books", nil, created.Key), fiber.StatusOK)
+	})
+	t.Run("customer keys cannot write", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/authors", map[string]interface{}{
+			"name":  "Forbidden",
+			"email": "forbidden@example.com",
+		}, created.Key)
+		expectStatus(t, resp, fiber.StatusForbidden)
+	})
+	t.Run("customer keys cannot administer", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/api-keys", nil, created.Key), fiber.StatusForbidden)
+	})
+	t.Run("revoked keys are rejected", func(t *testing.T) {
+		resp := request(t, fiber.MethodDelete, "/admin/api-keys/"+created.ID.String(), nil, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		expectStatus(t, request(t, fiber.MethodGet, "/stock-alerts", nil, created.Key), fiber.StatusUnauthorized)
+		resp = request(t, fiber.MethodDelete, "/admin/api-keys/"+created.ID.String(), nil, adminToken(t))
+		expectStatus(t, resp, fiber.StatusNotFound)
+	})
+}
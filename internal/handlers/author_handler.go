@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
 	"bookstore-api/internal/services"
 	"bookstore-api/internal/utils"
+	"fmt"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,9 +18,9 @@ type AuthorHandler struct {
 }
 
 // NewAuthorHandler creates a new author handler
-func NewAuthorHandler() *AuthorHandler {
+func NewAuthorHandler(svc *services.Services) *AuthorHandler {
 	return &AuthorHandler{
-		authorService: services.NewAuthorService(),
+		authorService: svc.Authors,
 	}
 }
 
@@ -27,6 +29,8 @@ type CreateAuthorRequest struct {
 	Name      string `json:"name" validate:"required,min=2,max=255"`
 	Email     string `json:"email" validate:"required,email"`
 	Biography string `json:"biography,omitempty"`
+	// ExternalLinks maps a label such as website or twitter to an http(s) URL
+	ExternalLinks map[string]string `json:"external_links,omitempty" validate:"omitempty,max=10,dive,keys,min=1,max=30,endkeys,required,http_url"`
 }
 
 // UpdateAuthorRequest represents the request payload for updating an author
@@ -34,247 +38,415 @@ type UpdateAuthorRequest struct {
 	Name      string `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
 	Email     string `json:"email,omitempty" validate:"omitempty,email"`
 	Biography string `json:"biography,omitempty"`
+	// ExternalLinks replaces the author's links when present; {} removes them
+	ExternalLinks map[string]string `json:"external_links,omitempty" validate:"omitempty,max=10,dive,keys,min=1,max=30,endkeys,required,http_url"`
+}
+
+// SetAuthorOwnerRequest represents the request payload for assigning an author to a user
+type SetAuthorOwnerRequest struct {
+	UserID *string `json:"user_id" validate:"omitempty,uuid"`
+}
+
+// MergeAuthorRequest represents the request payload for merging a duplicate author
+type MergeAuthorRequest struct {
+	DuplicateID string `json:"duplicate_id" validate:"required,uuid"`
 }
 
 // CreateAuthor creates a new author
 func (h *AuthorHandler) CreateAuthor(c *fiber.Ctx) error {
 	var req CreateAuthorRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
 	}
 
 	author := &models.Author{
-		Name:      req.Name,
-		Email:     req.Email,
-		Biography: req.Biography,
+		Name:          req.Name,
+		Email:         req.Email,
+		Biography:     req.Biography,
+		ExternalLinks: req.ExternalLinks,
 	}
 
-	if err := h.authorService.CreateAuthor(author); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create author",
-			"details": err.Error(),
-		})
+	if err := h.authorService.WithContext(c.UserContext()).CreateAuthor(author); err != nil {
+		if resp, ok := conflictResponse(err); ok {
+			return response.Fail(c, fiber.StatusConflict, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create author", err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"error":   false,
-		"message": "Author created successfully",
-		"data":    author,
-	})
+	return response.Created(c, "Author created successfully", author)
 }
 
 // GetAuthor retrieves an author by ID
 func (h *AuthorHandler) GetAuthor(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
+	}
+
+	author, err := h.authorService.WithContext(c.UserContext()).GetAuthorByID(id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid author ID",
-			"details": err.Error(),
-		})
+		if err.Error() == "author not found" {
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get author", err)
 	}
 
-	author, err := h.authorService.GetAuthorByID(id)
+	return h.respondAuthor(c, author)
+}
+
+// GetAuthorBySlug retrieves an author by its slug. Slugs the author had before being
+// renamed redirect to its current slug.
+func (h *AuthorHandler) GetAuthorBySlug(c *fiber.Ctx) error {
+	value := c.Params("slug")
+	author, err := h.authorService.WithContext(c.UserContext()).GetAuthorBySlug(value)
 	if err != nil {
 		if err.Error() == "author not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Author not found",
-			})
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get author",
-			"details": err.Error(),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Author retrieved successfully",
-		"data":    author,
-	})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get author", err)
+	}
+
+	if author.Slug != value {
+		return c.RedirectToRoute(RouteAuthorsBySlug, fiber.Map{"slug": author.Slug}, fiber.StatusMovedPermanently)
+	}
+
+	return h.respondAuthor(c, author)
+}
+
+// respondAuthor responds with an author
+func (h *AuthorHandler) respondAuthor(c *fiber.Ctx, author *models.Author) error {
+	body := response.Envelope{
+		Message: "Author retrieved successfully",
+		Data:    author,
+	}
+	if wantJSONAPI(c) {
+		return jsonapiAuthor(c, author)
+	}
+	if wantLinks(c) {
+		body.Data = linkAuthor(c, author)
+	}
+	return c.JSON(body)
 }
 
 // GetAllAuthors retrieves all authors with pagination
 func (h *AuthorHandler) GetAllAuthors(c *fiber.Ctx) error {
-	page, limit := getPaginationParams(c)
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
 
-	authors, total, err := h.authorService.GetAllAuthors(page, limit)
+	authors, total, err := h.authorService.WithContext(c.UserContext()).GetAllAuthors(page, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get authors",
-			"details": err.Error(),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Authors retrieved successfully",
-		"data":    authors,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get authors", err)
+	}
+
+	body := response.Envelope{
+		Message:    "Authors retrieved successfully",
+		Data:       authors,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiAuthors(c, authors, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkAuthors(c, authors)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
 }
 
 // UpdateAuthor updates an existing author
 func (h *AuthorHandler) UpdateAuthor(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid author ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
 	}
 
 	var req UpdateAuthorRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
+	}
+
+	// Claims are verified against the author's email, so owners cannot change it
+	if req.Email != "" && !isAdmin(c) {
+		return response.Error(c, fiber.StatusForbidden, "Only administrators can change an author's email", nil)
 	}
 
 	updates := &models.Author{
-		Name:      req.Name,
-		Email:     req.Email,
-		Biography: req.Biography,
+		Name:          req.Name,
+		Email:         req.Email,
+		Biography:     req.Biography,
+		ExternalLinks: req.ExternalLinks,
 	}
 
-	if err := h.authorService.UpdateAuthor(id, updates); err != nil {
+	if err := h.authorService.WithContext(c.UserContext()).UpdateAuthor(id, updates); err != nil {
 		if err.Error() == "author not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Author not found",
-			})
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update author",
-			"details": err.Error(),
-		})
+		if resp, ok := conflictResponse(err); ok {
+			return response.Fail(c, fiber.StatusConflict, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update author", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Author updated successfully",
-	})
+	return response.OK(c, "Author updated successfully", nil)
 }
 
 // DeleteAuthor deletes an author
 func (h *AuthorHandler) DeleteAuthor(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid author ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
 	}
 
-	if err := h.authorService.DeleteAuthor(id); err != nil {
+	if err := h.authorService.WithContext(c.UserContext()).DeleteAuthor(id); err != nil {
 		if err.Error() == "author not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Author not found",
-			})
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete author",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete author", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Author deleted successfully",
-	})
+	return response.OK(c, "Author deleted successfully", nil)
 }
 
-// SearchAuthors searches authors by name or email
+// SearchAuthors searches authors by name or email, also matching names
+// similar to the query by trigrams at the similarity parameter's threshold
 func (h *AuthorHandler) SearchAuthors(c *fiber.Ctx) error {
-	query := c.Query("q")
-	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Search query is required",
-		})
+	query, ok, err := parseSearchQuery(c)
+	if !ok {
+		return err
+	}
+
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	authorService := h.authorService.WithContext(c.UserContext())
+	if similarityStr := c.Query("similarity"); similarityStr != "" {
+		similarity, err := strconv.ParseFloat(similarityStr, 64)
+		if err != nil || similarity <= 0 || similarity > 1 {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid similarity", "similarity must be a number greater than 0 and at most 1")
+		}
+		authorService = authorService.WithSimilarity(similarity)
+	}
+
+	authors, total, err := authorService.SearchAuthors(query, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to search authors", err)
+	}
+
+	body := response.Envelope{
+		Message:    "Authors found successfully",
+		Data:       authors,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiAuthors(c, authors, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkAuthors(c, authors)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
+}
+
+// FindDuplicateAuthors reports groups of authors with similar names
+func (h *AuthorHandler) FindDuplicateAuthors(c *fiber.Ctx) error {
+	threshold := services.DefaultDuplicateThreshold
+	if thresholdStr := c.Query("threshold"); thresholdStr != "" {
+		t, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || t <= 0 || t > 1 {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid threshold", "threshold must be a number greater than 0 and at most 1")
+		}
+		threshold = t
+	}
+
+	groups, err := h.authorService.WithContext(c.UserContext()).FindDuplicateAuthors(threshold)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to find duplicate authors", err)
 	}
 
-	page, limit := getPaginationParams(c)
+	return response.OK(c, "Duplicate authors retrieved successfully", groups)
+}
+
+// MergeAuthor merges a duplicate author into the author in the path
+func (h *AuthorHandler) MergeAuthor(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
+	}
+
+	var req MergeAuthorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+	duplicateID := uuid.MustParse(req.DuplicateID)
+
+	author, err := h.authorService.WithContext(c.UserContext()).MergeAuthors(id, duplicateID)
+	if err != nil {
+		switch err.Error() {
+		case "author not found":
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
+		case "duplicate author not found":
+			return response.Error(c, fiber.StatusNotFound, "Duplicate author not found", nil)
+		case "cannot merge an author into itself":
+			return response.Error(c, fiber.StatusBadRequest, "Cannot merge an author into itself", nil)
+		case "both authors are claimed by users":
+			return response.Error(c, fiber.StatusConflict, "Both authors are claimed by users; unassign one owner before merging", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to merge authors", err)
+	}
+
+	return response.OK(c, "Authors merged successfully", author)
+}
+
+// OwnsAuthor reports whether the user has claimed the author in the :id route parameter
+func (h *AuthorHandler) OwnsAuthor(c *fiber.Ctx, userID uuid.UUID) (bool, error) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return false, nil
+	}
+	return h.authorService.WithContext(c.UserContext()).IsAuthorOwner(id, userID)
+}
+
+// ClaimAuthor links the author to the signed-in user when their verified email matches
+func (h *AuthorHandler) ClaimAuthor(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	author, err := h.authorService.WithContext(c.UserContext()).ClaimAuthor(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "author not found", "user not found":
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
+		case "author already claimed":
+			return response.Error(c, fiber.StatusConflict, "Author already claimed", nil)
+		case "user already owns an author":
+			return response.Error(c, fiber.StatusConflict, "You already own an author profile", nil)
+		case "email does not match author":
+			return response.Error(c, fiber.StatusForbidden, "Your verified email does not match the author's email", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to claim author", err)
+	}
+
+	return response.OK(c, "Author claimed successfully", author)
+}
+
+// SetAuthorOwner assigns the author to a user, or unassigns it when user_id is null
+func (h *AuthorHandler) SetAuthorOwner(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
+	}
+
+	var req SetAuthorOwnerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	var userID *uuid.UUID
+	if req.UserID != nil {
+		parsed := uuid.MustParse(*req.UserID)
+		userID = &parsed
+	}
 
-	authors, total, err := h.authorService.SearchAuthors(query, page, limit)
+	author, err := h.authorService.WithContext(c.UserContext()).SetAuthorOwner(id, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to search authors",
-			"details": err.Error(),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Authors found successfully",
-		"data":    authors,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+		switch err.Error() {
+		case "author not found":
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
+		case "user not found":
+			return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+		case "user already owns an author":
+			return response.Error(c, fiber.StatusConflict, "User already owns an author", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update author owner", err)
+	}
+
+	return response.OK(c, "Author owner updated successfully", author)
 }
 
 // getPaginationParams extracts pagination parameters from the request
-func getPaginationParams(c *fiber.Ctx) (int, int) {
+func getPaginationParams(c *fiber.Ctx) (int, int, error) {
 	page := 1
-	limit := 10
+	limit, ok := c.Locals("pagination_default_limit").(int)
+	if !ok {
+		limit = 10
+	}
+	maxLimit, ok := c.Locals("pagination_max_limit").(int)
+	if !ok {
+		maxLimit = 100
+	}
 
 	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
+		p, err := strconv.Atoi(pageStr)
+		if err != nil || p < 1 {
+			return 0, 0, fmt.Errorf("invalid page: must be a positive integer")
 		}
+		page = p
 	}
 
 	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 {
+			return 0, 0, fmt.Errorf("invalid limit: must be a positive integer")
+		}
+		if l > maxLimit {
+			return 0, 0, fmt.Errorf("invalid limit: must be at most %d", maxLimit)
 		}
+		limit = l
 	}
 
-	return page, limit
+	return page, limit, nil
+}
+
+// invalidPagination responds 400 to a listing request whose page or limit
+// is out of range
+func invalidPagination(c *fiber.Ctx, err error) error {
+	return response.Error(c, fiber.StatusBadRequest, "Invalid pagination parameters", err)
+}
+
+// getCountParam reads the optional query parameter name, a count from 1 to
+// max defaulting to def. Out-of-range values are an error rather than being
+// clamped, as with limit.
+func getCountParam(c *fiber.Ctx, name string, def, max int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s: must be a positive integer", name)
+	}
+	if n > max {
+		return 0, fmt.Errorf("invalid %s: must be at most %d", name, max)
+	}
+	return n, nil
 }
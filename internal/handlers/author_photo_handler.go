@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthorPhotoHandler handles author photo uploads
+type AuthorPhotoHandler struct {
+	photoService *services.AuthorPhotoService
+}
+
+// NewAuthorPhotoHandler creates a new author photo handler
+func NewAuthorPhotoHandler(svc *services.Services) *AuthorPhotoHandler {
+	return &AuthorPhotoHandler{
+		photoService: svc.AuthorPhotos,
+	}
+}
+
+// SetAuthorPhoto replaces an author's photo with an image sent as the multipart
+// "photo" field or as an image request body
+func (h *AuthorPhotoHandler) SetAuthorPhoto(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
+	}
+
+	var data []byte
+	if fileHeader, err := c.FormFile("photo"); err == nil {
+		file, err := fileHeader.Open()
+		if err == nil {
+			data, err = io.ReadAll(file)
+			file.Close()
+		}
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read uploaded file", err)
+		}
+	} else if len(c.Body()) > 0 && strings.HasPrefix(c.Get("Content-Type"), "image/") {
+		data = c.Body()
+	} else {
+		return response.Error(c, fiber.StatusBadRequest, "Photo is required", nil)
+	}
+
+	author, err := h.photoService.WithContext(c.UserContext()).SetAuthorPhoto(id, data)
+	if err != nil {
+		if err.Error() == "author not found" {
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
+		}
+		if strings.HasPrefix(err.Error(), "invalid photo: ") {
+			return response.ErrorWithDetails(c, fiber.StatusUnsupportedMediaType, "Invalid photo", strings.TrimPrefix(err.Error(), "invalid photo: "))
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to store author photo", err)
+	}
+
+	return response.OK(c, "Author photo updated successfully", author)
+}
+
+// DeleteAuthorPhoto removes an author's photo
+func (h *AuthorPhotoHandler) DeleteAuthorPhoto(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "author")
+	if !ok {
+		return err
+	}
+
+	if err := h.photoService.WithContext(c.UserContext()).DeleteAuthorPhoto(id); err != nil {
+		switch err.Error() {
+		case "author not found":
+			return response.Error(c, fiber.StatusNotFound, "Author not found", nil)
+		case "author has no photo":
+			return response.Error(c, fiber.StatusNotFound, "Author has no photo", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete author photo", err)
+	}
+
+	return response.OK(c, "Author photo deleted successfully", nil)
+}
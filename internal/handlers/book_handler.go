@@ -1,9 +1,23 @@
 package handlers
 
 import (
+	"bookstore-api/internal/analytics"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/metadata"
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/operations"
+	"bookstore-api/internal/response"
 	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
 	"bookstore-api/internal/utils"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,38 +26,80 @@ import (
 
 // BookHandler handles book-related HTTP requests
 type BookHandler struct {
-	bookService *services.BookService
+	bookService        *services.BookService
+	authorService      *services.AuthorService
+	translationService *services.TranslationService
+	revisionService    *services.RevisionService
+	lookup             *metadata.Lookup
+	tracker            *analytics.Tracker
+	// listingService serves book listings from the read model when it is enabled
+	listingService  *services.ListingService
+	operations      *operations.Manager
+	importBatchSize int
+	// asyncImportSize is the upload size above which imports run as operations
+	asyncImportSize int
 }
 
 // NewBookHandler creates a new book handler
-func NewBookHandler() *BookHandler {
+func NewBookHandler(cfg *config.Config, svc *services.Services) *BookHandler {
 	return &BookHandler{
-		bookService: services.NewBookService(),
+		bookService:        svc.Books,
+		authorService:      svc.Authors,
+		translationService: svc.Translations,
+		revisionService:    svc.Revisions,
+		listingService:     svc.Listings,
+		lookup:             metadata.GetLookup(),
+		tracker:            analytics.GetTracker(),
+		operations:         operations.GetManager(),
+		importBatchSize:    cfg.Import.BatchSize,
+		asyncImportSize:    cfg.Operations.AsyncImportSize,
 	}
 }
 
+// LookupAuthor is an author from book metadata, with the matching catalog author if any
+type LookupAuthor struct {
+	Name     string     `json:"name"`
+	AuthorID *uuid.UUID `json:"author_id,omitempty"`
+}
+
+// BookLookupResponse is book metadata prepared for pre-filling a new catalog entry
+type BookLookupResponse struct {
+	*metadata.BookMetadata
+	Authors        []LookupAuthor `json:"authors"`
+	ExistingBookID *uuid.UUID     `json:"existing_book_id,omitempty"`
+}
+
 // CreateBookRequest represents the request payload for creating a book
 type CreateBookRequest struct {
-	Title       string     `json:"title" validate:"required,min=1,max=255"`
-	ISBN        string     `json:"isbn" validate:"required,len=13"`
-	Description string     `json:"description,omitempty"`
-	Price       float64    `json:"price" validate:"required,min=0"`
-	Stock       int        `json:"stock" validate:"min=0"`
-	PublishedAt *time.Time `json:"published_at,omitempty"`
-	AuthorID    string     `json:"author_id" validate:"required,uuid"`
-	CategoryID  string     `json:"category_id" validate:"required,uuid"`
+	Title       string           `json:"title" validate:"required,min=1,max=255"`
+	ISBN        string           `json:"isbn" validate:"required,isbn"`
+	Description string           `json:"description,omitempty"`
+	Price       float64          `json:"price" validate:"required,min=0"`
+	Stock       int              `json:"stock" validate:"min=0"`
+	PublishedAt *models.DateOnly `json:"published_at,omitempty" validate:"omitempty,max_years_ahead=2"`
+	AuthorID    string           `json:"author_id" validate:"required,uuid"`
+	CategoryID  string           `json:"category_id" validate:"required,uuid"`
+	Status      string           `json:"status,omitempty" validate:"omitempty,oneof=draft active archived"`
+	Format      string           `json:"format,omitempty" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	PageCount   *int             `json:"page_count,omitempty" validate:"omitempty,min=1"`
 }
 
-// UpdateBookRequest represents the request payload for updating a book
+// UpdateBookRequest represents the request payload for updating a book. Note
+// explains the change to the admin reviewing it when the change is submitted
+// as a revision.
 type UpdateBookRequest struct {
-	Title       string     `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
-	ISBN        string     `json:"isbn,omitempty" validate:"omitempty,len=13"`
-	Description string     `json:"description,omitempty"`
-	Price       *float64   `json:"price,omitempty" validate:"omitempty,min=0"`
-	Stock       *int       `json:"stock,omitempty" validate:"omitempty,min=0"`
-	PublishedAt *time.Time `json:"published_at,omitempty"`
-	AuthorID    string     `json:"author_id,omitempty" validate:"omitempty,uuid"`
-	CategoryID  string     `json:"category_id,omitempty" validate:"omitempty,uuid"`
+	Title       string           `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	ISBN        string           `json:"isbn,omitempty" validate:"omitempty,isbn"`
+	Description string           `json:"description,omitempty"`
+	Price       *float64         `json:"price,omitempty" validate:"omitempty,min=0"`
+	Stock       *int             `json:"stock,omitempty" validate:"omitempty,min=0"`
+	PublishedAt *models.DateOnly `json:"published_at,omitempty" validate:"omitempty,max_years_ahead=2"`
+	AuthorID    string           `json:"author_id,omitempty" validate:"omitempty,uuid"`
+	CategoryID  string           `json:"category_id,omitempty" validate:"omitempty,uuid"`
+	Status      string           `json:"status,omitempty" validate:"omitempty,oneof=draft active archived"`
+	Format      string           `json:"format,omitempty" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	PageCount   *int             `json:"page_count,omitempty" validate:"omitempty,min=1"`
+	Note        string           `json:"note,omitempty" validate:"max=1000"`
 }
 
 // UpdateStockRequest represents the request payload for updating book stock
@@ -51,43 +107,33 @@ type UpdateStockRequest struct {
 	Stock int `json:"stock" validate:"required,min=0"`
 }
 
+// AdjustStockRequest represents the request payload for adjusting book stock
+type AdjustStockRequest struct {
+	Delta int    `json:"delta" validate:"required"`
+	Note  string `json:"note" validate:"max=255"`
+}
+
 // CreateBook creates a new book
 func (h *BookHandler) CreateBook(c *fiber.Ctx) error {
 	var req CreateBookRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
 	}
 
 	// Parse UUIDs
 	authorID, err := uuid.Parse(req.AuthorID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid author ID",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid author ID", err)
 	}
 
 	categoryID, err := uuid.Parse(req.CategoryID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid category ID",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid category ID", err)
 	}
 
 	book := &models.Book{
@@ -99,357 +145,819 @@ func (h *BookHandler) CreateBook(c *fiber.Ctx) error {
 		PublishedAt: req.PublishedAt,
 		AuthorID:    authorID,
 		CategoryID:  categoryID,
+		Status:      req.Status,
+		Format:      req.Format,
+		PageCount:   req.PageCount,
 	}
 
-	if err := h.bookService.CreateBook(book); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create book",
-			"details": err.Error(),
-		})
+	if err := h.bookService.WithContext(c.UserContext()).CreateBook(book); err != nil {
+		// Repeating a create, as clients retrying after a timeout do,
+		// returns the book the first request created
+		var duplicate *services.DuplicateBookError
+		if errors.As(err, &duplicate) && duplicate.Same {
+			return response.OK(c, "Book already exists", duplicate.Existing)
+		}
+		if resp, status := isbnErrorResponse(err); status != 0 {
+			return response.Fail(c, status, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create book", err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"error":   false,
-		"message": "Book created successfully",
-		"data":    book,
-	})
+	return response.Created(c, "Book created successfully", book)
 }
 
-// GetBook retrieves a book by ID
+// GetBook retrieves a book by ID or short code. Books that are not active
+// are found only by admins and the book's owners.
 func (h *BookHandler) GetBook(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
+	bookService := h.bookService.WithContext(c.UserContext())
+	id, err := bookService.ResolveBookID(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid book ID",
-			"details": err.Error(),
-		})
+		return bookLookupError(c, err)
+	}
+
+	book, err := bookService.GetBookByID(id)
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	if err := h.checkBookVisible(c, book.ID, book.Status); err != nil {
+		return bookLookupError(c, err)
 	}
 
-	book, err := h.bookService.GetBookByID(id)
+	h.recordView(c, book)
+	return h.respondBook(c, book)
+}
+
+// bookLookupError responds to an error looking up a book, mapping malformed
+// identifiers to 400 and missing books to 404
+func bookLookupError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "invalid ID or short code":
+		return response.Error(c, fiber.StatusBadRequest, "Invalid book ID", err)
+	case "book not found":
+		return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "Failed to get book", err)
+}
+
+// GetBookBySlug retrieves a book by its slug. Slugs the book had before being
+// renamed redirect to its current slug. Books that are not active are found
+// only by admins and the book's owners.
+func (h *BookHandler) GetBookBySlug(c *fiber.Ctx) error {
+	value := c.Params("slug")
+	book, err := h.bookService.WithContext(c.UserContext()).GetBookBySlug(value)
+	if err == nil {
+		err = h.checkBookVisible(c, book.ID, book.Status)
+	}
 	if err != nil {
 		if err.Error() == "book not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Book not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get book",
-			"details": err.Error(),
-		})
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get book", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Book retrieved successfully",
-		"data":    book,
-	})
+	if book.Slug != value {
+		return c.RedirectToRoute(RouteBooksBySlug, fiber.Map{"slug": book.Slug}, fiber.StatusMovedPermanently)
+	}
+
+	h.recordView(c, book)
+	return h.respondBook(c, book)
+}
+
+// checkBookVisible returns a "book not found" error when the current user may
+// not see the book: drafts and archived books are seen only by admins and
+// the users who claimed the book's author
+func (h *BookHandler) checkBookVisible(c *fiber.Ctx, id uuid.UUID, status string) error {
+	if status == models.BookStatusActive || isAdmin(c) {
+		return nil
+	}
+	if userID, ok := currentUserID(c); ok {
+		owner, err := h.bookService.WithContext(c.UserContext()).IsBookOwner(id, userID)
+		if err != nil {
+			return err
+		}
+		if owner {
+			return nil
+		}
+	}
+	return fmt.Errorf("book not found")
+}
+
+// RequireVisibleBook responds 404, as GetBook does, for books the current user
+// may not see, so the routes under a book do not reveal the price, preview,
+// or translations of draft and archived books. It must run after
+// OptionalAuth.
+func (h *BookHandler) RequireVisibleBook(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+	status, err := h.bookService.WithContext(c.UserContext()).GetBookStatus(id)
+	if err == nil {
+		err = h.checkBookVisible(c, id, status)
+	}
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	return c.Next()
+}
+
+// recordView records a view of the book's page by the current user, if signed in
+func (h *BookHandler) recordView(c *fiber.Ctx, book *models.Book) {
+	tenantID, ok := tenancy.TenantID(c.UserContext())
+	if !ok {
+		return
+	}
+	var viewer *uuid.UUID
+	if userID, ok := currentUserID(c); ok {
+		viewer = &userID
+	}
+	h.tracker.RecordView(tenantID, book.ID, viewer, time.Now())
+}
+
+// respondBook responds with a book
+func (h *BookHandler) respondBook(c *fiber.Ctx, book *models.Book) error {
+	localizeBook(c, h.translationService, book)
+	body := response.Envelope{
+		Message: "Book retrieved successfully",
+		Data:    book,
+	}
+	if wantJSONAPI(c) {
+		return jsonapiBook(c, book)
+	}
+	if wantLinks(c) {
+		body.Data = linkBook(c, book)
+	}
+	return c.JSON(body)
 }
 
-// GetAllBooks retrieves all books with pagination
+// GetAllBooks retrieves all active books with pagination
 func (h *BookHandler) GetAllBooks(c *fiber.Ctx) error {
-	page, limit := getPaginationParams(c)
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
 
-	books, total, err := h.bookService.GetAllBooks(page, limit)
+	var books []models.Book
+	var total int64
+	if h.listingService != nil {
+		books, total, err = h.listingService.WithContext(c.UserContext()).GetListings(page, limit)
+	} else {
+		books, total, err = h.bookService.WithContext(c.UserContext()).GetAllBooks(page, limit)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get books",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get books", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Books retrieved successfully",
-		"data":    books,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+	localizeBooks(c, h.translationService, books)
+	body := response.Envelope{
+		Message:    "Books retrieved successfully",
+		Data:       books,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiBooks(c, books, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkBooks(c, books)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
 }
 
 // UpdateBook updates an existing book
 func (h *BookHandler) UpdateBook(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid book ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
 	}
 
 	var req UpdateBookRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
 	}
 
-	updates := &models.Book{
-		Title:       req.Title,
-		ISBN:        req.ISBN,
-		Description: req.Description,
-		PublishedAt: req.PublishedAt,
+	// Authors editing their own books submit revisions for an admin to
+	// approve, and cannot change stock
+	if !isAdmin(c) && req.Stock != nil {
+		return response.Error(c, fiber.StatusForbidden, "Only administrators can change stock", nil)
+	}
+
+	// Only the fields in the request are changed, so price and stock may be set to zero
+	updates := map[string]interface{}{}
+	if req.Title != "" {
+		updates["title"] = req.Title
+	}
+	if req.ISBN != "" {
+		updates["isbn"] = req.ISBN
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+	if req.PublishedAt != nil {
+		updates["published_at"] = *req.PublishedAt
+	}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+	}
+	if req.Stock != nil {
+		updates["stock"] = *req.Stock
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+	if req.Format != "" {
+		updates["format"] = req.Format
+	}
+	if req.PageCount != nil {
+		updates["page_count"] = *req.PageCount
 	}
 
 	// Parse UUIDs if provided
 	if req.AuthorID != "" {
 		authorID, err := uuid.Parse(req.AuthorID)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid author ID",
-				"details": err.Error(),
-			})
+			return response.Error(c, fiber.StatusBadRequest, "Invalid author ID", err)
 		}
-		updates.AuthorID = authorID
+		updates["author_id"] = authorID
 	}
 
 	if req.CategoryID != "" {
 		categoryID, err := uuid.Parse(req.CategoryID)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid category ID",
-				"details": err.Error(),
-			})
+			return response.Error(c, fiber.StatusBadRequest, "Invalid category ID", err)
 		}
-		updates.CategoryID = categoryID
+		updates["category_id"] = categoryID
 	}
 
-	// Set price and stock if provided
-	if req.Price != nil {
-		updates.Price = *req.Price
+	if !isAdmin(c) {
+		return h.submitRevision(c, id, updates, req.Note)
 	}
-	if req.Stock != nil {
-		updates.Stock = *req.Stock
+
+	if err := h.bookService.WithContext(c.UserContext()).UpdateBook(id, updates); err != nil {
+		if err.Error() == "book not found" {
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		if err.Error() == "insufficient stock" {
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Stock is held at warehouses", "stock cannot be set below the stock held at warehouses; lower their stock first")
+		}
+		if resp, status := isbnErrorResponse(err); status != 0 {
+			return response.Fail(c, status, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update book", err)
 	}
 
-	if err := h.bookService.UpdateBook(id, updates); err != nil {
+	return response.OK(c, "Book updated successfully", nil)
+}
+
+// submitRevision records a non-admin's changes to a book as a pending
+// revision, responding 202 Accepted until an admin reviews it
+func (h *BookHandler) submitRevision(c *fiber.Ctx, id uuid.UUID, updates map[string]interface{}, note string) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	revision, err := h.revisionService.WithContext(c.UserContext()).SubmitRevision(id, userID, updates, note)
+	if err != nil {
 		if err.Error() == "book not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Book not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update book",
-			"details": err.Error(),
-		})
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		if strings.HasPrefix(err.Error(), "invalid revision: ") {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid revision: "))
+		}
+		if resp, status := isbnErrorResponse(err); status != 0 {
+			return response.Fail(c, status, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to submit revision", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Book updated successfully",
-	})
+	return response.Accepted(c, "Changes submitted for review", revision)
 }
 
 // DeleteBook deletes a book
 func (h *BookHandler) DeleteBook(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid book ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
 	}
 
-	if err := h.bookService.DeleteBook(id); err != nil {
+	if err := h.bookService.WithContext(c.UserContext()).DeleteBook(id); err != nil {
 		if err.Error() == "book not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Book not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete book",
-			"details": err.Error(),
-		})
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete book", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Book deleted successfully",
-	})
+	return response.OK(c, "Book deleted successfully", nil)
 }
 
 // GetBooksByAuthor retrieves books by author ID
 func (h *BookHandler) GetBooksByAuthor(c *fiber.Ctx) error {
-	authorIDStr := c.Params("authorId")
-	authorID, err := uuid.Parse(authorIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid author ID",
-			"details": err.Error(),
-		})
+	authorID, ok, err := parseUUIDParam(c, "authorId", "author")
+	if !ok {
+		return err
 	}
 
-	page, limit := getPaginationParams(c)
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
 
-	books, total, err := h.bookService.GetBooksByAuthor(authorID, page, limit)
+	books, total, err := h.bookService.WithContext(c.UserContext()).GetBooksByAuthor(authorID, page, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get books by author",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get books by author", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Books retrieved successfully",
-		"data":    books,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+	localizeBooks(c, h.translationService, books)
+	body := response.Envelope{
+		Message:    "Books retrieved successfully",
+		Data:       books,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiBooks(c, books, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkBooks(c, books)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
 }
 
 // GetBooksByCategory retrieves books by category ID
 func (h *BookHandler) GetBooksByCategory(c *fiber.Ctx) error {
-	categoryIDStr := c.Params("categoryId")
-	categoryID, err := uuid.Parse(categoryIDStr)
+	categoryID, ok, err := parseUUIDParam(c, "categoryId", "category")
+	if !ok {
+		return err
+	}
+
+	page, limit, err := getPaginationParams(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid category ID",
-			"details": err.Error(),
-		})
+		return invalidPagination(c, err)
 	}
 
-	page, limit := getPaginationParams(c)
+	// include_descendants also lists the books of the category's subcategories
+	getBooks := h.bookService.WithContext(c.UserContext()).GetBooksByCategory
+	if c.QueryBool("include_descendants") {
+		getBooks = h.bookService.WithContext(c.UserContext()).GetBooksInCategoryTree
+	}
 
-	books, total, err := h.bookService.GetBooksByCategory(categoryID, page, limit)
+	books, total, err := getBooks(categoryID, page, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get books by category",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get books by category", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Books retrieved successfully",
-		"data":    books,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+	localizeBooks(c, h.translationService, books)
+	body := response.Envelope{
+		Message:    "Books retrieved successfully",
+		Data:       books,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiBooks(c, books, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkBooks(c, books)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
 }
 
-// SearchBooks searches books by title, ISBN, or description
+// SearchBooks searches active books by title, ISBN, or description
 func (h *BookHandler) SearchBooks(c *fiber.Ctx) error {
-	query := c.Query("q")
-	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Search query is required",
-		})
+	query, ok, err := parseSearchQuery(c)
+	if !ok {
+		return err
 	}
 
-	page, limit := getPaginationParams(c)
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
 
-	books, total, err := h.bookService.SearchBooks(query, page, limit)
+	books, total, err := h.bookService.WithContext(c.UserContext()).SearchBooks(query, page, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to search books",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to search books", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Books found successfully",
-		"data":    books,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+	localizeBooks(c, h.translationService, books)
+	body := response.Envelope{
+		Message:    "Books found successfully",
+		Data:       books,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiBooks(c, books, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkBooks(c, books)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
+}
+
+// CompareBooks compares the active books in the comma-separated ids query
+// parameter side by side: price, format, page count, rating, and
+// availability
+func (h *BookHandler) CompareBooks(c *fiber.Ctx) error {
+	var ids []uuid.UUID
+	for _, value := range strings.Split(c.Query("ids"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid book ID", err)
+		}
+		ids = append(ids, id)
+	}
+
+	comparison, err := h.bookService.WithContext(c.UserContext()).CompareBooks(ids)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid comparison: "):
+			return response.Error(c, fiber.StatusBadRequest, "Invalid comparison", err)
+		case err.Error() == "book not found":
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to compare books", err)
+	}
+
+	return response.OK(c, "Books compared successfully", comparison)
+}
+
+// GetAdminBooks lists books of every status for admins, filtered by status
+// and, when q is set, searched by title, ISBN, or description
+func (h *BookHandler) GetAdminBooks(c *fiber.Ctx) error {
+	status := c.Query("status")
+	if status != "" && !models.IsBookStatus(status) {
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid status", "status must be draft, active, or archived")
+	}
+	query, err := services.ParseSearchQuery(c.Query("q"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid search query", err)
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	bookService := h.bookService.WithContext(c.UserContext()).WithStatus(status)
+	var books []models.Book
+	var total int64
+	if query.Text != "" {
+		books, total, err = bookService.SearchBooks(query, page, limit)
+	} else {
+		books, total, err = bookService.GetAllBooks(page, limit)
+	}
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get books", err)
+	}
+
+	return response.Paginated(c, "Books retrieved successfully", books, page, limit, total)
+}
+
+// GetPriceHistory retrieves a book's price changes, newest first, with a summary
+// of its current price and the lowest prices over the last days (default 30)
+func (h *BookHandler) GetPriceHistory(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	days := c.QueryInt("days", 30)
+	if days < 1 || days > 365 {
+		return response.Error(c, fiber.StatusBadRequest, "days must be between 1 and 365", nil)
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	bookService := h.bookService.WithContext(c.UserContext())
+	summary, err := bookService.GetPriceSummary(id, days)
+	if err != nil {
+		return priceHistoryError(c, err)
+	}
+	history, total, err := bookService.GetPriceHistory(id, page, limit)
+	if err != nil {
+		return priceHistoryError(c, err)
+	}
+
+	return response.Paginated(c, "Price history retrieved successfully", fiber.Map{
+		"summary": summary,
+		"history": history,
+	}, page, limit, total)
+}
+
+// priceHistoryError responds to a price history service error
+func priceHistoryError(c *fiber.Ctx, err error) error {
+	if err.Error() == "book not found" {
+		return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "Failed to get price history", err)
+}
+
+// OwnsBook reports whether the user has claimed the author of the book in the :id route parameter
+func (h *BookHandler) OwnsBook(c *fiber.Ctx, userID uuid.UUID) (bool, error) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return false, nil
+	}
+	return h.bookService.WithContext(c.UserContext()).IsBookOwner(id, userID)
 }
 
 // UpdateBookStock updates book stock
 func (h *BookHandler) UpdateBookStock(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid book ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
 	}
 
 	var req UpdateStockRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
 	}
 
-	if err := h.bookService.UpdateBookStock(id, req.Stock); err != nil {
+	if err := h.bookService.WithContext(c.UserContext()).UpdateBookStock(id, req.Stock); err != nil {
 		if err.Error() == "book not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Book not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update book stock",
-			"details": err.Error(),
-		})
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		if err.Error() == "insufficient stock" {
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Stock is held at warehouses", "stock cannot be set below the stock held at warehouses; lower their stock first")
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update book stock", err)
+	}
+
+	return response.OK(c, "Book stock updated successfully", nil)
+}
+
+// AdjustBookStock adds a positive or negative delta to a book's stock
+func (h *BookHandler) AdjustBookStock(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	var req AdjustStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Book stock updated successfully",
+	movement, err := h.bookService.WithContext(c.UserContext()).AdjustBookStock(id, req.Delta, req.Note)
+	if err != nil {
+		switch err.Error() {
+		case "book not found":
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		case "insufficient stock":
+			return response.Error(c, fiber.StatusConflict, "Adjustment would make stock negative or less than the stock held at warehouses", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to adjust book stock", err)
+	}
+
+	return response.Created(c, "Book stock adjusted successfully", movement)
+}
+
+// GetStockMovements returns a book's stock movements, newest first
+func (h *BookHandler) GetStockMovements(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	movements, total, err := h.bookService.WithContext(c.UserContext()).GetStockMovements(id, page, limit)
+	if err != nil {
+		if err.Error() == "book not found" {
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get stock movements", err)
+	}
+
+	return response.Paginated(c, "Stock movements retrieved successfully", movements, page, limit, total)
+}
+
+// ExportBooks streams books as CSV, accepting the same filters as the list
+// endpoints. With async=true the export runs as an operation instead, and its
+// file is downloaded through the operation once it completes.
+func (h *BookHandler) ExportBooks(c *fiber.Ctx) error {
+	if format := c.Query("format", "csv"); format != "csv" {
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Unsupported export format", fmt.Sprintf("format %q is not supported, expected csv", format))
+	}
+
+	columns, err := services.ParseExportColumns(c.Query("columns"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid columns", err)
+	}
+
+	if _, err := services.ParseSearchQuery(c.Query("q")); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid search query", err)
+	}
+	filter := services.BookFilter{Query: c.Query("q")}
+	if authorIDStr := c.Query("author_id"); authorIDStr != "" {
+		if filter.AuthorID, err = uuid.Parse(authorIDStr); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid author ID", err)
+		}
+	}
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		if filter.CategoryID, err = uuid.Parse(categoryIDStr); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid category ID", err)
+		}
+	}
+
+	if c.QueryBool("async", false) {
+		operation, err := h.operations.StartExport(c.UserContext(), filter, columns)
+		if err != nil {
+			return response.Error(c, fiber.StatusInternalServerError, "Failed to start export", err)
+		}
+		return acceptedOperation(c, "Export started", operation)
+	}
+
+	filename := fmt.Sprintf("books-%s.csv", time.Now().UTC().Format("20060102"))
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The stream writer runs after the handler returns, when c may be reused
+	bookService := h.bookService.WithContext(c.UserContext())
+	release := holdBulkhead(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer release()
+
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return
+		}
+
+		written := 0
+		err := bookService.ExportBooks(filter, func(row *services.BookExportRow) error {
+			if err := writer.Write(row.CSVValues(columns)); err != nil {
+				return err
+			}
+			written++
+			if written%500 == 0 {
+				writer.Flush()
+				if err := writer.Error(); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+			return nil
+		})
+		writer.Flush()
+		// Headers are already sent, so failures can only be logged
+		if err != nil {
+			log.Printf("Book export failed after %d rows: %v", written, err)
+		}
 	})
+
+	return nil
+}
+
+// holdBulkhead takes over the bulkhead slot the request holds, if any, so a
+// streamed response keeps it until the returned function is called
+func holdBulkhead(c *fiber.Ctx) func() {
+	release, ok := c.Locals("bulkhead_release").(func())
+	if !ok {
+		return func() {}
+	}
+	c.Locals("bulkhead_release", nil)
+	return release
+}
+
+// ImportBooks imports books from an uploaded CSV file and returns a per-row report.
+// The file may be sent as the multipart "file" field or as a text/csv request body.
+// With async=true, or for files larger than OPERATIONS_ASYNC_IMPORT_SIZE, the
+// import runs as an operation whose result is the report.
+func (h *BookHandler) ImportBooks(c *fiber.Ctx) error {
+	opts := services.ImportOptions{
+		DryRun:    c.QueryBool("dry_run", false),
+		BatchSize: c.QueryInt("batch_size", h.importBatchSize),
+	}
+
+	var reader io.Reader
+	var size int64
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read uploaded file", err)
+		}
+		defer file.Close()
+		reader, size = file, fileHeader.Size
+	} else if len(c.Body()) > 0 && !strings.HasPrefix(c.Get("Content-Type"), "multipart/") {
+		reader, size = bytes.NewReader(c.Body()), int64(len(c.Body()))
+	} else {
+		return response.Error(c, fiber.StatusBadRequest, "CSV file is required", nil)
+	}
+
+	if c.QueryBool("async", false) || (h.asyncImportSize > 0 && size > int64(h.asyncImportSize)) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read uploaded file", err)
+		}
+		operation, err := h.operations.StartImport(c.UserContext(), data, opts)
+		if err != nil {
+			return response.Error(c, fiber.StatusInternalServerError, "Failed to start import", err)
+		}
+		return acceptedOperation(c, "Import started", operation)
+	}
+
+	report, err := h.bookService.WithContext(c.UserContext()).ImportBooks(reader, opts)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Failed to import books", err)
+	}
+
+	message := "Books imported"
+	if opts.DryRun {
+		message = "Import validated (dry run)"
+	}
+	if !opts.DryRun && report.Imported > 0 {
+		return response.Created(c, message, report)
+	}
+	return response.OK(c, message, report)
+}
+
+// LookupBook fetches metadata for an ISBN from external providers to pre-fill a
+// new book. Authors are matched to existing catalog authors by name, and the ID
+// of a book already using the ISBN is included.
+func (h *BookHandler) LookupBook(c *fiber.Ctx) error {
+	isbn, ok := utils.CanonicalISBN(c.Query("isbn"))
+	if !ok {
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", "isbn query parameter must be a valid ISBN-10 or ISBN-13")
+	}
+
+	book, err := h.lookup.Lookup(c.UserContext(), isbn)
+	if err != nil {
+		switch {
+		case errors.Is(err, metadata.ErrNotFound):
+			return response.Error(c, fiber.StatusNotFound, "No metadata found for this ISBN", nil)
+		case metadata.IsRateLimited(err):
+			return response.Error(c, fiber.StatusTooManyRequests, "Metadata providers are rate limited. Please try again later.", nil)
+		}
+		return response.Error(c, fiber.StatusBadGateway, "Failed to look up book metadata", err)
+	}
+
+	lookup := BookLookupResponse{BookMetadata: book, Authors: make([]LookupAuthor, len(book.Authors))}
+	names := make([]string, len(book.Authors))
+	for i, author := range book.Authors {
+		names[i] = author.Name
+	}
+	authorIDs, err := h.authorService.WithContext(c.UserContext()).FindAuthorIDsByName(names)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to match authors", err)
+	}
+	for i, author := range book.Authors {
+		lookup.Authors[i] = LookupAuthor{Name: author.Name}
+		if id, ok := authorIDs[strings.ToLower(author.Name)]; ok {
+			lookup.Authors[i].AuthorID = &id
+		}
+	}
+
+	if existing, err := h.bookService.WithContext(c.UserContext()).GetBookByISBN(isbn); err == nil {
+		lookup.ExistingBookID = &existing.ID
+	} else if err.Error() != "book not found" {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to check existing book", err)
+	}
+
+	return response.OK(c, "Book metadata retrieved successfully", lookup)
+}
+
+// isbnErrorResponse maps book validation and ISBN uniqueness errors to a
+// client error response and its status, which is 0 for other errors.
+// Conflicts with a known book carry its ID in their details.
+func isbnErrorResponse(err error) (response.Envelope, int) {
+	if strings.HasPrefix(err.Error(), "invalid book: ") {
+		return validationResponse(err), fiber.StatusBadRequest
+	}
+	if err.Error() == "invalid isbn" {
+		return validationResponse(utils.ValidationErrors{{
+			Field:   "isbn",
+			Rule:    "isbn",
+			Message: "ISBN must be a valid ISBN-10 or ISBN-13",
+		}}), fiber.StatusBadRequest
+	}
+	if resp, ok := conflictResponse(err); ok {
+		var duplicate *services.DuplicateBookError
+		if errors.As(err, &duplicate) {
+			resp.Details = fiber.Map{"book_id": duplicate.Existing.ID}
+		}
+		return resp, fiber.StatusConflict
+	}
+	return response.Envelope{}, 0
 }
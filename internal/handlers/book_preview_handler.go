@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// BookPreviewHandler handles book sample content
+type BookPreviewHandler struct {
+	previewService *services.BookPreviewService
+}
+
+// NewBookPreviewHandler creates a new book preview handler
+func NewBookPreviewHandler(svc *services.Services) *BookPreviewHandler {
+	return &BookPreviewHandler{
+		previewService: svc.BookPreviews,
+	}
+}
+
+// SetBookPreview replaces a book's preview with a PDF, EPUB, or plain text
+// excerpt sent as the multipart "file" field or as the request body
+func (h *BookPreviewHandler) SetBookPreview(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	var data []byte
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err == nil {
+			data, err = io.ReadAll(file)
+			file.Close()
+		}
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read uploaded file", err)
+		}
+	} else if len(c.Body()) > 0 && !strings.HasPrefix(c.Get("Content-Type"), "multipart/") {
+		data = c.Body()
+	} else {
+		return response.Error(c, fiber.StatusBadRequest, "Preview file is required", nil)
+	}
+
+	preview, err := h.previewService.WithContext(c.UserContext()).SetBookPreview(id, data)
+	if err != nil {
+		if err.Error() == "book not found" {
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		if strings.HasPrefix(err.Error(), "invalid preview: ") {
+			return response.ErrorWithDetails(c, fiber.StatusUnsupportedMediaType, "Invalid preview", strings.TrimPrefix(err.Error(), "invalid preview: "))
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to store book preview", err)
+	}
+
+	return response.OK(c, "Book preview updated successfully", preview)
+}
+
+// GetBookPreview serves a book's preview. Range requests are supported, so
+// readers can fetch a large excerpt page by page.
+func (h *BookPreviewHandler) GetBookPreview(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	service := h.previewService.WithContext(c.UserContext())
+	preview, err := service.GetBookPreview(id)
+	if err != nil {
+		if err.Error() == "book has no preview" {
+			return response.Error(c, fiber.StatusNotFound, "Book has no preview", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get book preview", err)
+	}
+	content, err := service.OpenBookPreview(preview)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to read book preview", err)
+	}
+	defer content.Close()
+
+	// Conditional and range requests are answered by http.ServeContent
+	return adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", preview.ContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"preview%s\"", path.Ext(preview.Key)))
+		w.Header().Set("ETag", fmt.Sprintf("\"%x-%x\"", preview.UpdatedAt.UnixNano(), preview.Size))
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		http.ServeContent(w, r, "", preview.UpdatedAt, content)
+	})(c)
+}
+
+// DeleteBookPreview removes a book's preview
+func (h *BookPreviewHandler) DeleteBookPreview(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	if err := h.previewService.WithContext(c.UserContext()).DeleteBookPreview(id); err != nil {
+		if err.Error() == "book has no preview" {
+			return response.Error(c, fiber.StatusNotFound, "Book has no preview", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete book preview", err)
+	}
+
+	return response.OK(c, "Book preview deleted successfully", nil)
+}
@@ -0,0 +1,360 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// fieldErrors decodes the failed fields of a validation error
+func fieldErrors(t *testing.T, raw json.RawMessage) map[string]string {
+	t.Helper()
+	var fields []struct {
+		Field string `json:"field"`
+		Rule  string `json:"rule"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatalf("failed to decode fields %s: %v", raw, err)
+	}
+	rules := make(map[string]string, len(fields))
+	for _, f := range fields {
+		rules[f.Field] = f.Rule
+	}
+	return rules
+}
+
+func TestAuthors(t *testing.T) {
+	id := createAuthor(t)
+
+	t.Run("get", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/authors/"+id.String(), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var author record
+		decode(t, resp, &author)
+		if author.ID != id {
+			t.Fatalf("got author %s, expected %s", author.ID, id)
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/authors/"+uuid.NewString(), nil, ""), fiber.StatusNotFound)
+	})
+	t.Run("duplicate email", func(t *testing.T) {
+		email := unique("twice") + "@example.com"
+		body := map[string]interface{}{"name": "First Author", "email": email}
+		expectStatus(t, request(t, fiber.MethodPost, "/authors", body, adminToken(t)), fiber.StatusCreated)
+
+		body["name"] = "Second Author"
+		resp := request(t, fiber.MethodPost, "/authors", body, adminToken(t))
+		expectStatus(t, resp, fiber.StatusConflict)
+		if resp.Code != "duplicate_email" || resp.Field != "email" {
+			t.Fatalf("conflict has code %q and field %q, expected duplicate_email on email", resp.Code, resp.Field)
+		}
+	})
+	t.Run("invalid input", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/authors", map[string]interface{}{"name": "A", "email": "not-an-email"}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		rules := fieldErrors(t, resp.Fields)
+		if rules["name"] != "min" || rules["email"] != "email" {
+			t.Fatalf("failed fields are %v, expected name to break min and email to break email", rules)
+		}
+	})
+}
+
+func TestCategories(t *testing.T) {
+	parent := createCategory(t, uuid.Nil)
+	child := createCategory(t, parent)
+
+	t.Run("duplicate name", func(t *testing.T) {
+		name := unique("category")
+		body := map[string]interface{}{"name": name}
+		expectStatus(t, request(t, fiber.MethodPost, "/categories", body, adminToken(t)), fiber.StatusCreated)
+
+		resp := request(t, fiber.MethodPost, "/categories", body, adminToken(t))
+		expectStatus(t, resp, fiber.StatusConflict)
+		if resp.Code != "duplicate_category_name" || resp.Field != "name" {
+			t.Fatalf("conflict has code %q and field %q, expected duplicate_category_name on name", resp.Code, resp.Field)
+		}
+	})
+	t.Run("unknown parent", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/categories", map[string]interface{}{
+			"name":      unique("orphan"),
+			"parent_id": uuid.NewString(),
+		}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+	t.Run("delete with subcategories", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodDelete, "/categories/"+parent.String(), nil, adminToken(t)), fiber.StatusConflict)
+		expectStatus(t, request(t, fiber.MethodDelete, "/categories/"+child.String(), nil, adminToken(t)), fiber.StatusOK)
+		expectStatus(t, request(t, fiber.MethodDelete, "/categories/"+parent.String(), nil, adminToken(t)), fiber.StatusOK)
+		expectStatus(t, request(t, fiber.MethodGet, "/categories/"+parent.String(), nil, ""), fiber.StatusNotFound)
+	})
+}
+
+func TestBooks(t *testing.T) {
+	body := bookRequest(t)
+	id := createBook(t, body)
+
+	t.Run("get", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/books/"+id.String(), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var book struct {
+			record
+			ISBN  string `json:"isbn"`
+			Stock int    `json:"stock"`
+		}
+		decode(t, resp, &book)
+		if book.ID != id || book.ISBN != body["isbn"] || book.Stock != body["stock"] {
+			t.Fatalf("got book %s with isbn %s and stock %d, expected %s with isbn %s and stock %d",
+				book.ID, book.ISBN, book.Stock, id, body["isbn"], body["stock"])
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/"+uuid.NewString(), nil, ""), fiber.StatusNotFound)
+	})
+	t.Run("repeated create", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/books", body, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var book record
+		decode(t, resp, &book)
+		if book.ID != id {
+			t.Fatalf("repeated create returned book %s, expected %s", book.ID, id)
+		}
+	})
+	t.Run("duplicate isbn", func(t *testing.T) {
+		other := bookRequest(t)
+		other["isbn"] = body["isbn"]
+		resp := request(t, fiber.MethodPost, "/books", other, adminToken(t))
+		expectStatus(t, resp, fiber.StatusConflict)
+		if resp.Code != "duplicate_isbn" || resp.Field != "isbn" {
+			t.Fatalf("conflict has code %q and field %q, expected duplicate_isbn on isbn", resp.Code, resp.Field)
+		}
+		details, _ := resp.Details.(map[string]interface{})
+		if details["book_id"] != id.String() {
+			t.Fatalf("conflict details are %v, expected book_id %s", resp.Details, id)
+		}
+	})
+	t.Run("invalid isbn", func(t *testing.T) {
+		invalid := bookRequest(t)
+		invalid["isbn"] = "9780000000000"
+		resp := request(t, fiber.MethodPost, "/books", invalid, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		if rules := fieldErrors(t, resp.Fields); rules["isbn"] != "isbn" {
+			t.Fatalf("failed fields are %v, expected isbn to break isbn", rules)
+		}
+	})
+	t.Run("search", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/books/search?q="+url.QueryEscape(body["title"].(string)), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var books []record
+		decode(t, resp, &books)
+		if len(books) != 1 || books[0].ID != id {
+			t.Fatalf("search found %v, expected only %s", books, id)
+		}
+	})
+	t.Run("empty search", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/search?q=", nil, ""), fiber.StatusBadRequest)
+	})
+}
+
+func TestCompareBooks(t *testing.T) {
+	first := createBook(t, bookRequest(t))
+	second := createBook(t, bookRequest(t))
+	compare := func(ids ...uuid.UUID) string {
+		values := make([]string, len(ids))
+		for i, id := range ids {
+			values[i] = id.String()
+		}
+		return "/books/compare?ids=" + strings.Join(values, ",")
+	}
+
+	t.Run("two books", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, compare(second, first), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var comparison struct {
+			Books []record `json:"books"`
+		}
+		decode(t, resp, &comparison)
+		if len(comparison.Books) != 2 || comparison.Books[0].ID != second || comparison.Books[1].ID != first {
+			t.Fatalf("compared %v, expected %s then %s", comparison.Books, second, first)
+		}
+	})
+	t.Run("one book", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, compare(first), nil, ""), fiber.StatusBadRequest)
+	})
+	t.Run("repeated book", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, compare(first, first), nil, ""), fiber.StatusBadRequest)
+	})
+	t.Run("unknown book", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, compare(first, uuid.New()), nil, ""), fiber.StatusNotFound)
+	})
+}
+
+func TestDraftBookDetails(t *testing.T) {
+	body := bookRequest(t)
+	body["status"] = "draft"
+	id := createBook(t, body)
+
+	// Admins see drafts; anyone else gets 404 as if the book did not exist
+	for _, tt := range []struct {
+		path  string
+		token string
+		want  int
+	}{
+		{"", "", fiber.StatusNotFound},
+		{"/price-history", "", fiber.StatusNotFound},
+		{"/preview", "", fiber.StatusNotFound},
+		{"/translations", "", fiber.StatusNotFound},
+		{"", adminToken(t), fiber.StatusOK},
+		{"/price-history", adminToken(t), fiber.StatusOK},
+		{"/translations", adminToken(t), fiber.StatusOK},
+	} {
+		name := "GET /books/:id" + tt.path
+		if tt.token != "" {
+			name += " as admin"
+		}
+		t.Run(name, func(t *testing.T) {
+			expectStatus(t, request(t, fiber.MethodGet, "/books/"+id.String()+tt.path, nil, tt.token), tt.want)
+		})
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	book := createBook(t, bookRequest(t))
+	path := "/books/" + book.String() + "/translations"
+
+	resp := request(t, fiber.MethodPut, path+"/fr", map[string]string{"title": "Le Livre"}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusOK)
+	var saved map[string]string
+	decode(t, resp, &saved)
+	if saved["title"] != "Le Livre" {
+		t.Fatalf("saved translations are %v, expected the French title", saved)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, path, nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var translations map[string]map[string]string
+		decode(t, resp, &translations)
+		if translations["fr"]["title"] != "Le Livre" {
+			t.Fatalf("translations are %v, expected the French title", translations)
+		}
+	})
+	t.Run("untranslatable field", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPut, path+"/fr", map[string]string{"isbn": "9780000000002"}, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("invalid locale", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPut, path+"/123", map[string]string{"title": "Das Buch"}, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPut, path+"/de", map[string]string{"title": "Das Buch"}, ""), fiber.StatusUnauthorized)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	body := bookRequest(t)
+	id := createBook(t, body)
+
+	t.Run("books", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/search?q="+url.QueryEscape(body["title"].(string)), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var results struct {
+			Books struct {
+				Items []record `json:"items"`
+			} `json:"books"`
+		}
+		decode(t, resp, &results)
+		if len(results.Books.Items) == 0 || results.Books.Items[0].ID != id {
+			t.Fatalf("search found books %v, expected %s first", results.Books.Items, id)
+		}
+	})
+	t.Run("missing query", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/search", nil, ""), fiber.StatusBadRequest)
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	author := createAuthor(t)
+	body := bookRequest(t)
+	body["author_id"] = author.String()
+	book := createBook(t, body)
+	userID, token := userToken(t, "customer")
+	resp := request(t, fiber.MethodPut, "/admin/authors/"+author.String()+"/owner", map[string]interface{}{"user_id": userID}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusOK)
+
+	// The author's own changes wait for an admin to approve them
+	resp = request(t, fiber.MethodPut, "/books/"+book.String(), map[string]interface{}{"price": 24.99, "note": "New edition"}, token)
+	expectStatus(t, resp, fiber.StatusAccepted)
+	var revision record
+	decode(t, resp, &revision)
+
+	t.Run("mine", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/revisions", nil, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var revisions []record
+		decode(t, resp, &revisions)
+		if len(revisions) != 1 || revisions[0].ID != revision.ID {
+			t.Fatalf("listed %v, expected only %s", revisions, revision.ID)
+		}
+	})
+	t.Run("stock change", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPut, "/books/"+book.String(), map[string]interface{}{"stock": 3}, token), fiber.StatusForbidden)
+	})
+	t.Run("invalid status", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/admin/revisions?status=done", nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("review requires admin", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/revisions/"+revision.ID.String()+"/approve", nil, token), fiber.StatusForbidden)
+	})
+	t.Run("approve", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/revisions/"+revision.ID.String()+"/approve", nil, adminToken(t)), fiber.StatusOK)
+
+		resp := request(t, fiber.MethodGet, "/books/"+book.String(), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var updated struct {
+			Price float64 `json:"price"`
+		}
+		decode(t, resp, &updated)
+		if updated.Price != 24.99 {
+			t.Fatalf("book price is %v, expected 24.99", updated.Price)
+		}
+	})
+	t.Run("approve twice", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/revisions/"+revision.ID.String()+"/approve", nil, adminToken(t)), fiber.StatusConflict)
+	})
+}
+
+func TestBookViews(t *testing.T) {
+	book := createBook(t, bookRequest(t))
+	_, token := userToken(t, "customer")
+
+	t.Run("stats", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/books/"+book.String()+"/stats", nil, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var stats struct {
+			BookID uuid.UUID `json:"book_id"`
+		}
+		decode(t, resp, &stats)
+		if stats.BookID != book {
+			t.Fatalf("got stats of book %s, expected %s", stats.BookID, book)
+		}
+	})
+	t.Run("stats of another's book", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/"+book.String()+"/stats", nil, token), fiber.StatusForbidden)
+	})
+	t.Run("recently viewed", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/recently-viewed", nil, token), fiber.StatusOK)
+	})
+	t.Run("recently viewed without a user", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/recently-viewed", nil, adminToken(t)), fiber.StatusUnauthorized)
+	})
+	t.Run("trending", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/trending", nil, ""), fiber.StatusOK)
+	})
+	t.Run("trending with invalid limit", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/trending?limit=0", nil, ""), fiber.StatusBadRequest)
+	})
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
 	"bookstore-api/internal/services"
 	"bookstore-api/internal/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -11,13 +13,15 @@ import (
 
 // CategoryHandler handles category-related HTTP requests
 type CategoryHandler struct {
-	categoryService *services.CategoryService
+	categoryService    *services.CategoryService
+	translationService *services.TranslationService
 }
 
 // NewCategoryHandler creates a new category handler
-func NewCategoryHandler() *CategoryHandler {
+func NewCategoryHandler(svc *services.Services) *CategoryHandler {
 	return &CategoryHandler{
-		categoryService: services.NewCategoryService(),
+		categoryService:    svc.Categories,
+		translationService: svc.Translations,
 	}
 }
 
@@ -25,142 +29,176 @@ func NewCategoryHandler() *CategoryHandler {
 type CreateCategoryRequest struct {
 	Name        string `json:"name" validate:"required,min=2,max=100"`
 	Description string `json:"description,omitempty"`
+	ParentID    string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
 }
 
 // UpdateCategoryRequest represents the request payload for updating a category
 type UpdateCategoryRequest struct {
 	Name        string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
 	Description string `json:"description,omitempty"`
+	// ParentID moves the category under another; an empty string moves it to
+	// the top level
+	ParentID *string `json:"parent_id,omitempty"`
 }
 
 // CreateCategory creates a new category
 func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
 	var req CreateCategoryRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
 	}
 
 	category := &models.Category{
 		Name:        req.Name,
 		Description: req.Description,
 	}
+	if req.ParentID != "" {
+		parentID, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid parent category ID", err)
+		}
+		category.ParentID = &parentID
+	}
 
-	if err := h.categoryService.CreateCategory(category); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create category",
-			"details": err.Error(),
-		})
+	if err := h.categoryService.WithContext(c.UserContext()).CreateCategory(category); err != nil {
+		if resp, ok := conflictResponse(err); ok {
+			return response.Fail(c, fiber.StatusConflict, resp)
+		}
+		if strings.HasPrefix(err.Error(), "invalid category: ") {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid category", strings.TrimPrefix(err.Error(), "invalid category: "))
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create category", err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"error":   false,
-		"message": "Category created successfully",
-		"data":    category,
-	})
+	return response.Created(c, "Category created successfully", category)
 }
 
 // GetCategory retrieves a category by ID
 func (h *CategoryHandler) GetCategory(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
+	id, ok, err := parseUUIDParam(c, "id", "category")
+	if !ok {
+		return err
+	}
+
+	category, err := h.categoryService.WithContext(c.UserContext()).GetCategoryByID(id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid category ID",
-			"details": err.Error(),
-		})
+		if err.Error() == "category not found" {
+			return response.Error(c, fiber.StatusNotFound, "Category not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get category", err)
 	}
 
-	category, err := h.categoryService.GetCategoryByID(id)
+	return h.respondCategory(c, category)
+}
+
+// GetCategoryBySlug retrieves a category by its slug. Slugs the category had before being
+// renamed redirect to its current slug.
+func (h *CategoryHandler) GetCategoryBySlug(c *fiber.Ctx) error {
+	value := c.Params("slug")
+	category, err := h.categoryService.WithContext(c.UserContext()).GetCategoryBySlug(value)
 	if err != nil {
 		if err.Error() == "category not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Category not found",
-			})
+			return response.Error(c, fiber.StatusNotFound, "Category not found", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get category",
-			"details": err.Error(),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Category retrieved successfully",
-		"data":    category,
-	})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get category", err)
+	}
+
+	if category.Slug != value {
+		return c.RedirectToRoute(RouteCategoriesBySlug, fiber.Map{"slug": category.Slug}, fiber.StatusMovedPermanently)
+	}
+
+	return h.respondCategory(c, category)
+}
+
+// respondCategory responds with a category
+func (h *CategoryHandler) respondCategory(c *fiber.Ctx, category *models.Category) error {
+	localizeCategory(c, h.translationService, category)
+	body := response.Envelope{
+		Message: "Category retrieved successfully",
+		Data:    category,
+	}
+	if wantJSONAPI(c) {
+		return jsonapiCategory(c, category)
+	}
+	if wantLinks(c) {
+		body.Data = linkCategory(c, category)
+	}
+	return c.JSON(body)
 }
 
 // GetAllCategories retrieves all categories with pagination
 func (h *CategoryHandler) GetAllCategories(c *fiber.Ctx) error {
-	page, limit := getPaginationParams(c)
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
 
-	categories, total, err := h.categoryService.GetAllCategories(page, limit)
+	categories, total, err := h.categoryService.WithContext(c.UserContext()).GetAllCategories(page, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get categories",
-			"details": err.Error(),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Categories retrieved successfully",
-		"data":    categories,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get categories", err)
+	}
+
+	localizeCategories(c, h.translationService, categories)
+	body := response.Envelope{
+		Message:    "Categories retrieved successfully",
+		Data:       categories,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiCategories(c, categories, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkCategories(c, categories)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
 }
 
 // UpdateCategory updates an existing category
 func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid category ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "category")
+	if !ok {
+		return err
 	}
 
 	var req UpdateCategoryRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
 	}
 
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Validation failed",
-			"details": err.Error(),
-		})
+		return validationFailed(c, err)
+	}
+
+	// An empty parent_id moves the category to the top level
+	var parentID *uuid.UUID
+	if req.ParentID != nil && *req.ParentID != "" {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid parent category ID", err)
+		}
+		parentID = &parsed
+	}
+
+	if req.ParentID != nil {
+		if err := h.categoryService.WithContext(c.UserContext()).MoveCategory(id, parentID); err != nil {
+			if err.Error() == "category not found" {
+				return response.Error(c, fiber.StatusNotFound, "Category not found", nil)
+			}
+			if strings.HasPrefix(err.Error(), "invalid category: ") {
+				return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid category", strings.TrimPrefix(err.Error(), "invalid category: "))
+			}
+			return response.Error(c, fiber.StatusInternalServerError, "Failed to move category", err)
+		}
+		if req.Name == "" && req.Description == "" {
+			return response.OK(c, "Category updated successfully", nil)
+		}
 	}
 
 	updates := &models.Category{
@@ -168,88 +206,79 @@ func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
 		Description: req.Description,
 	}
 
-	if err := h.categoryService.UpdateCategory(id, updates); err != nil {
+	if err := h.categoryService.WithContext(c.UserContext()).UpdateCategory(id, updates); err != nil {
 		if err.Error() == "category not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Category not found",
-			})
+			return response.Error(c, fiber.StatusNotFound, "Category not found", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update category",
-			"details": err.Error(),
-		})
+		if resp, ok := conflictResponse(err); ok {
+			return response.Fail(c, fiber.StatusConflict, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update category", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Category updated successfully",
-	})
+	return response.OK(c, "Category updated successfully", nil)
 }
 
 // DeleteCategory deletes a category
 func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid category ID",
-			"details": err.Error(),
-		})
+	id, ok, err := parseUUIDParam(c, "id", "category")
+	if !ok {
+		return err
 	}
 
-	if err := h.categoryService.DeleteCategory(id); err != nil {
+	if err := h.categoryService.WithContext(c.UserContext()).DeleteCategory(id); err != nil {
 		if err.Error() == "category not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "Category not found",
-			})
+			return response.Error(c, fiber.StatusNotFound, "Category not found", nil)
+		}
+		if err.Error() == "category has subcategories" {
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Category has subcategories", "Move or delete its subcategories first")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete category",
-			"details": err.Error(),
-		})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete category", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Category deleted successfully",
-	})
+	return response.OK(c, "Category deleted successfully", nil)
+}
+
+// GetCategoryTree retrieves every category nested under its parent
+func (h *CategoryHandler) GetCategoryTree(c *fiber.Ctx) error {
+	categories, err := h.categoryService.WithContext(c.UserContext()).GetCategoryHierarchy()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get category tree", err)
+	}
+
+	localizeCategories(c, h.translationService, categories)
+	return response.OK(c, "Category tree retrieved successfully", services.BuildCategoryTree(categories))
 }
 
 // SearchCategories searches categories by name or description
 func (h *CategoryHandler) SearchCategories(c *fiber.Ctx) error {
-	query := c.Query("q")
-	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Search query is required",
-		})
+	query, ok, err := parseSearchQuery(c)
+	if !ok {
+		return err
 	}
 
-	page, limit := getPaginationParams(c)
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
 
-	categories, total, err := h.categoryService.SearchCategories(query, page, limit)
+	categories, total, err := h.categoryService.WithContext(c.UserContext()).SearchCategories(query, page, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to search categories",
-			"details": err.Error(),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "Categories found successfully",
-		"data":    categories,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to search categories", err)
+	}
+
+	localizeCategories(c, h.translationService, categories)
+	body := response.Envelope{
+		Message:    "Categories found successfully",
+		Data:       categories,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantJSONAPI(c) {
+		return jsonapiCategories(c, categories, page, limit, total)
+	}
+	if wantLinks(c) {
+		body.Data = linkCategories(c, categories)
+		body.Links = pageLinks(c, page, limit, total)
+	}
+	return c.JSON(body)
 }
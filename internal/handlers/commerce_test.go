@@ -0,0 +1,140 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestRentals(t *testing.T) {
+	body := bookRequest(t)
+	body["stock"] = 1
+	book := createBook(t, body)
+	_, token := userToken(t, "customer")
+	_, other := userToken(t, "customer")
+
+	resp := request(t, fiber.MethodPost, "/books/"+book.String()+"/rentals", nil, token)
+	expectStatus(t, resp, fiber.StatusCreated)
+	var rental record
+	decode(t, resp, &rental)
+
+	t.Run("availability", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/"+book.String()+"/availability", nil, ""), fiber.StatusOK)
+	})
+	t.Run("invalid availability date", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/books/"+book.String()+"/availability?from=tomorrow", nil, ""), fiber.StatusBadRequest)
+	})
+	t.Run("no copies left", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/books/"+book.String()+"/rentals", nil, other), fiber.StatusConflict)
+	})
+	t.Run("start in the past", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/books/"+book.String()+"/rentals", map[string]interface{}{
+			"starts_at": time.Now().Add(-24 * time.Hour),
+		}, other)
+		expectStatus(t, resp, fiber.StatusBadRequest)
+	})
+	t.Run("list own", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/rentals", nil, token)
+		expectStatus(t, resp, fiber.StatusOK)
+		var rentals []record
+		decode(t, resp, &rentals)
+		if len(rentals) != 1 || rentals[0].ID != rental.ID {
+			t.Fatalf("listed %v, expected only %s", rentals, rental.ID)
+		}
+	})
+	t.Run("another user's rental", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/rentals/"+rental.ID.String(), nil, other), fiber.StatusForbidden)
+	})
+	t.Run("api key without a user", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/books/"+book.String()+"/rentals", nil, adminToken(t)), fiber.StatusUnauthorized)
+	})
+	t.Run("return", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/rentals/"+rental.ID.String()+"/return", nil, token), fiber.StatusOK)
+	})
+	t.Run("return twice", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/rentals/"+rental.ID.String()+"/return", nil, token), fiber.StatusConflict)
+	})
+	t.Run("requires auth", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/rentals", nil, ""), fiber.StatusUnauthorized)
+	})
+}
+
+func TestPromotions(t *testing.T) {
+	book := createBook(t, bookRequest(t))
+	promotion := func(bookID uuid.UUID, discount float64) map[string]interface{} {
+		return map[string]interface{}{
+			"name":             unique("sale"),
+			"book_id":          bookID,
+			"discount_percent": discount,
+			"starts_at":        time.Now().Add(-time.Hour),
+			"ends_at":          time.Now().Add(24 * time.Hour),
+		}
+	}
+
+	resp := request(t, fiber.MethodPost, "/promotions", promotion(book, 20), adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var created record
+	decode(t, resp, &created)
+
+	t.Run("get", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/promotions/"+created.ID.String(), nil, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var got record
+		decode(t, resp, &got)
+		if got.ID != created.ID {
+			t.Fatalf("got promotion %s, expected %s", got.ID, created.ID)
+		}
+	})
+	t.Run("whole price off", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/promotions", promotion(book, 100), adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		if rules := fieldErrors(t, resp.Fields); rules["discount_percent"] != "lt" {
+			t.Fatalf("failed fields are %v, expected discount_percent to break lt", rules)
+		}
+	})
+	t.Run("unknown book", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/promotions", promotion(uuid.New(), 20), adminToken(t)), fiber.StatusNotFound)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodGet, "/promotions", nil, token), fiber.StatusForbidden)
+	})
+}
+
+func TestSuppliers(t *testing.T) {
+	body := map[string]interface{}{
+		"name":     unique("supplier"),
+		"feed_url": "https://supplier.example.com/feed.csv",
+		"format":   "csv",
+	}
+	resp := request(t, fiber.MethodPost, "/suppliers", body, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var supplier record
+	decode(t, resp, &supplier)
+
+	t.Run("get", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/suppliers/"+supplier.ID.String(), nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("syncs", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/suppliers/"+supplier.ID.String()+"/syncs", nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("duplicate name", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/suppliers", body, adminToken(t)), fiber.StatusConflict)
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/suppliers", map[string]interface{}{
+			"name":     unique("supplier"),
+			"feed_url": "https://supplier.example.com/feed.xml",
+			"format":   "xml",
+		}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		if rules := fieldErrors(t, resp.Fields); rules["format"] != "oneof" {
+			t.Fatalf("failed fields are %v, expected format to break oneof", rules)
+		}
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/suppliers", nil, ""), fiber.StatusUnauthorized)
+	})
+}
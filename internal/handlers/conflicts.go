@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+)
+
+// conflict describes a unique field that a write collided with
+type conflict struct {
+	code    string
+	field   string
+	message string
+}
+
+// conflicts maps the uniqueness errors returned by the services to the
+// structured conflict reported to clients
+var conflicts = map[string]conflict{
+	"author with this email already exists":  {"duplicate_email", "email", "An author with this email already exists"},
+	"user with this email already exists":    {"duplicate_email", "email", "User with this email already exists"},
+	"book with this isbn already exists":     {"duplicate_isbn", "isbn", "A book with this ISBN already exists"},
+	"category with this name already exists": {"duplicate_category_name", "name", "A category with this name already exists"},
+}
+
+// conflictResponse maps a uniqueness error to the body of a 409 response
+// carrying a machine-readable code and the conflicting field. ok is false for
+// other errors.
+func conflictResponse(err error) (resp response.Envelope, ok bool) {
+	conflict, ok := conflicts[err.Error()]
+	if !ok {
+		return response.Envelope{}, false
+	}
+	return response.Envelope{Code: conflict.code, Field: conflict.field, Message: conflict.message}, true
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bookstore-api/internal/dashboard"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"log"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// dashboardPingInterval is how often idle dashboard connections are pinged
+const dashboardPingInterval = 30 * time.Second
+
+// DashboardHandler serves the admin dashboard WebSocket
+type DashboardHandler struct {
+	hub *dashboard.Hub
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler() *DashboardHandler {
+	return &DashboardHandler{
+		hub: dashboard.GetHub(),
+	}
+}
+
+// RequireUpgrade rejects requests that are not WebSocket upgrades
+func (h *DashboardHandler) RequireUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return response.Error(c, fiber.StatusUpgradeRequired, "WebSocket upgrade required", nil)
+	}
+	return c.Next()
+}
+
+// Dashboard pushes a metrics snapshot on connect, then periodic metrics and low stock alerts
+func (h *DashboardHandler) Dashboard() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		tenant, _ := conn.Locals("tenant").(*models.Tenant)
+		if tenant == nil {
+			return
+		}
+		client := h.hub.Register(tenant)
+		defer h.hub.Unregister(client)
+
+		if metrics, err := h.hub.Metrics(tenant); err != nil {
+			log.Printf("Failed to collect dashboard metrics: %v", err)
+		} else if msg, err := dashboard.Encode(dashboard.MessageMetrics, metrics); err == nil {
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		}
+
+		// Read until the client disconnects; the dashboard does not send commands
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ping := time.NewTicker(dashboardPingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case msg, ok := <-client.Send:
+				if !ok {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+					return
+				}
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
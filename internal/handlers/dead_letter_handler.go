@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DeadLetterHandler handles the dead-letter queue: background jobs, such as
+// webhook deliveries and report runs, that failed every attempt
+type DeadLetterHandler struct {
+	queue *jobs.Queue
+}
+
+// NewDeadLetterHandler creates a new dead-letter handler
+func NewDeadLetterHandler() *DeadLetterHandler {
+	return &DeadLetterHandler{queue: jobs.GetQueue()}
+}
+
+// DeadLettersRequest selects failed jobs to retry or purge in bulk. All must
+// be set to select every failed job.
+type DeadLettersRequest struct {
+	IDs          []uuid.UUID `json:"ids,omitempty"`
+	Type         string      `json:"type,omitempty" validate:"omitempty,max=100"`
+	FailedBefore *time.Time  `json:"failed_before,omitempty"`
+	All          bool        `json:"all,omitempty"`
+}
+
+// GetDeadLetters lists failed jobs with their payloads and last errors, most
+// recently failed first, with the number failed of each type
+func (h *DeadLetterHandler) GetDeadLetters(c *fiber.Ctx) error {
+	filter := jobs.DeadLetterFilter{Type: c.Query("type")}
+	if value := c.Query("failed_before"); value != "" {
+		failedBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid failed_before", "failed_before must be an RFC 3339 timestamp")
+		}
+		filter.FailedBefore = failedBefore
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	failed, total, err := h.queue.GetDeadLetters(filter, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get failed jobs", err)
+	}
+	counts, err := h.queue.CountDeadLetters()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to count failed jobs", err)
+	}
+
+	return c.JSON(response.Envelope{
+		Message:    "Failed jobs retrieved successfully",
+		Data:       failed,
+		Summary:    counts,
+		Pagination: response.NewPagination(page, limit, total),
+	})
+}
+
+// RetryDeadLetter returns a failed job to the queue with fresh attempts
+func (h *DeadLetterHandler) RetryDeadLetter(c *fiber.Ctx) error {
+	job, ok, err := h.findDeadLetter(c)
+	if !ok {
+		return err
+	}
+
+	if _, err := h.queue.RetryDeadLetters(jobs.DeadLetterFilter{IDs: []uuid.UUID{job.ID}}); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to retry job", err)
+	}
+	job, err = h.queue.GetJob(job.ID)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get job", err)
+	}
+
+	return response.OK(c, "Job scheduled for retry", job)
+}
+
+// DeleteDeadLetter purges a failed job
+func (h *DeadLetterHandler) DeleteDeadLetter(c *fiber.Ctx) error {
+	job, ok, err := h.findDeadLetter(c)
+	if !ok {
+		return err
+	}
+
+	if _, err := h.queue.PurgeDeadLetters(jobs.DeadLetterFilter{IDs: []uuid.UUID{job.ID}}); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to purge job", err)
+	}
+
+	return response.OK(c, "Job purged successfully", nil)
+}
+
+// RetryDeadLetters returns the failed jobs selected by the request body to
+// the queue
+func (h *DeadLetterHandler) RetryDeadLetters(c *fiber.Ctx) error {
+	filter, ok, err := h.parseDeadLettersRequest(c)
+	if !ok {
+		return err
+	}
+
+	retried, err := h.queue.RetryDeadLetters(filter)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to retry jobs", err)
+	}
+
+	return response.OK(c, "Jobs scheduled for retry", fiber.Map{"retried": retried})
+}
+
+// PurgeDeadLetters deletes the failed jobs selected by the request body
+func (h *DeadLetterHandler) PurgeDeadLetters(c *fiber.Ctx) error {
+	filter, ok, err := h.parseDeadLettersRequest(c)
+	if !ok {
+		return err
+	}
+
+	purged, err := h.queue.PurgeDeadLetters(filter)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to purge jobs", err)
+	}
+
+	return response.OK(c, "Jobs purged successfully", fiber.Map{"purged": purged})
+}
+
+// findDeadLetter loads the failed job in the path, writing an error response
+// when it cannot. The returned bool is false when a response has been
+// written.
+func (h *DeadLetterHandler) findDeadLetter(c *fiber.Ctx) (*models.Job, bool, error) {
+	id, ok, err := parseUUIDParam(c, "id", "job")
+	if !ok {
+		return nil, false, err
+	}
+
+	job, err := h.queue.GetJob(id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			return nil, false, response.Error(c, fiber.StatusNotFound, "Job not found", nil)
+		}
+		return nil, false, response.Error(c, fiber.StatusInternalServerError, "Failed to get job", err)
+	}
+	if job.Status != models.JobStatusFailed {
+		return nil, false, response.ErrorWithDetails(c, fiber.StatusConflict, "Job has not failed", "job is "+job.Status)
+	}
+	return job, true, nil
+}
+
+// parseDeadLettersRequest reads the selection of a bulk retry or purge,
+// writing an error response when it is invalid. The returned bool is false
+// when a response has been written.
+func (h *DeadLetterHandler) parseDeadLettersRequest(c *fiber.Ctx) (jobs.DeadLetterFilter, bool, error) {
+	var req DeadLettersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return jobs.DeadLetterFilter{}, false, response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return jobs.DeadLetterFilter{}, false, validationFailed(c, err)
+	}
+
+	filter := jobs.DeadLetterFilter{IDs: req.IDs, Type: req.Type}
+	if req.FailedBefore != nil {
+		filter.FailedBefore = *req.FailedBefore
+	}
+	if len(filter.IDs) == 0 && filter.Type == "" && filter.FailedBefore.IsZero() && !req.All {
+		return jobs.DeadLetterFilter{}, false, response.ErrorWithDetails(c, fiber.StatusBadRequest, "No jobs selected", "set ids, type, or failed_before, or all to select every failed job")
+	}
+	return filter, true, nil
+}
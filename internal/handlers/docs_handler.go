@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bookstore-api/internal/response"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -28,13 +29,13 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"path":        "/authors",
 						"description": "List all authors with pagination",
 						"parameters":  []string{"page", "limit"},
-						"response":    "List of authors with pagination info",
+						"response":    "List of authors, each with books_count instead of its books, with pagination info",
 					},
 					{
 						"method":      "POST",
 						"path":        "/authors",
 						"description": "Create a new author",
-						"body":        "Author data (name, email, biography)",
+						"body":        "Author data (name, email, biography, external_links such as {\"website\": \"https://...\"}). Duplicate emails return 409",
 						"response":    "Created author object",
 					},
 					{
@@ -44,10 +45,17 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"parameters":  []string{"id (UUID)"},
 						"response":    "Author object with books",
 					},
+					{
+						"method":      "GET",
+						"path":        "/authors/slug/:slug",
+						"description": "Get author by slug; a slug the author had before being renamed redirects with 301 to the current one",
+						"parameters":  []string{"slug"},
+						"response":    "Author object with books",
+					},
 					{
 						"method":      "PUT",
 						"path":        "/authors/:id",
-						"description": "Update author",
+						"description": "Update author (admin, or the user who claimed the author; owners cannot change the email)",
 						"parameters":  []string{"id (UUID)"},
 						"body":        "Updated author data",
 						"response":    "Success message",
@@ -62,9 +70,45 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 					{
 						"method":      "GET",
 						"path":        "/authors/search",
-						"description": "Search authors",
-						"parameters":  []string{"q (query string)"},
-						"response":    "List of matching authors",
+						"description": "Search authors by name or email; unless q is quoted, names with a word similar to q by trigrams also match, closest first, so \"Tolkein\" finds \"Tolkien\"",
+						"parameters":  []string{"q (query string)", "similarity (trigram word similarity from 0 to 1 for fuzzy name matches, default 0.4)"},
+						"response":    "List of matching authors, each with books_count instead of its books",
+					},
+					{
+						"method":      "GET",
+						"path":        "/authors/duplicates",
+						"description": "Report groups of authors with similar names, ignoring case, punctuation, and spacing between initials (admin role required)",
+						"parameters":  []string{"threshold (name similarity from 0 to 1, default 0.85)"},
+						"response":    "Duplicate groups with book counts, most books first",
+					},
+					{
+						"method":      "POST",
+						"path":        "/authors/:id/merge",
+						"description": "Merge a duplicate into this author: books are reassigned, a missing biography or placeholder email is filled from the duplicate, and the duplicate is soft deleted in one transaction (admin role required)",
+						"parameters":  []string{"id (UUID of the author to keep)"},
+						"body":        "duplicate_id (UUID of the author to merge)",
+						"response":    "Merged author object",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/authors/:id/photo",
+						"description": "Replace the author's photo with a JPEG, PNG, or GIF sent as the multipart \"photo\" field or an image/* body; it is stored as thumbnail (96px), medium (320px), and large (800px) JPEGs listed in the author's photos (admin, or the user who claimed the author)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Author object with photo URLs",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/authors/:id/photo",
+						"description": "Remove the author's photo (admin, or the user who claimed the author)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/authors/:id/claim",
+						"description": "Claim an author profile for the signed-in user; the user's verified email must match the author's email (authentication required)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Claimed author object",
 					},
 				},
 			},
@@ -76,13 +120,13 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"path":        "/categories",
 						"description": "List all categories with pagination",
 						"parameters":  []string{"page", "limit"},
-						"response":    "List of categories with pagination info",
+						"response":    "List of categories, each with books_count (books directly in the category) instead of its books, with pagination info",
 					},
 					{
 						"method":      "POST",
 						"path":        "/categories",
 						"description": "Create a new category",
-						"body":        "Category data (name, description)",
+						"body":        "Category data (name, description, parent_id optional). Duplicate names return 409",
 						"response":    "Created category object",
 					},
 					{
@@ -92,10 +136,17 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"parameters":  []string{"id (UUID)"},
 						"response":    "Category object with books",
 					},
+					{
+						"method":      "GET",
+						"path":        "/categories/slug/:slug",
+						"description": "Get category by slug; a slug the category had before being renamed redirects with 301 to the current one",
+						"parameters":  []string{"slug"},
+						"response":    "Category object with books",
+					},
 					{
 						"method":      "PUT",
 						"path":        "/categories/:id",
-						"description": "Update category",
+						"description": "Update category; parent_id moves it under another category (not one of its subcategories), or to the top level when empty",
 						"parameters":  []string{"id (UUID)"},
 						"body":        "Updated category data",
 						"response":    "Success message",
@@ -103,26 +154,54 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 					{
 						"method":      "DELETE",
 						"path":        "/categories/:id",
-						"description": "Delete category",
+						"description": "Delete category; categories with subcategories are rejected with 409",
 						"parameters":  []string{"id (UUID)"},
 						"response":    "Success message",
 					},
+					{
+						"method":      "GET",
+						"path":        "/categories/tree",
+						"description": "Get every category nested under its parent, siblings sorted by name",
+						"response":    "Top-level categories, each with its children",
+					},
 					{
 						"method":      "GET",
 						"path":        "/categories/search",
 						"description": "Search categories",
 						"parameters":  []string{"q (query string)"},
-						"response":    "List of matching categories",
+						"response":    "List of matching categories, each with books_count instead of its books",
+					},
+					{
+						"method":      "GET",
+						"path":        "/categories/:id/translations",
+						"description": "List the category's translations by locale",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Map of locale to translated fields",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/categories/:id/translations/:locale",
+						"description": "Create or replace the category's translated fields in a locale; an empty value removes that field (admin role required)",
+						"parameters":  []string{"id (UUID)", "locale (BCP 47 tag, e.g. fr or pt-BR)"},
+						"body":        "name, description",
+						"response":    "Translated fields in the locale",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/categories/:id/translations/:locale",
+						"description": "Delete the category's translations in a locale (admin role required)",
+						"parameters":  []string{"id (UUID)", "locale (BCP 47 tag)"},
+						"response":    "Success message",
 					},
 				},
 			},
 			"books": fiber.Map{
-				"description": "Book management endpoints",
+				"description": "Book management endpoints. Books read from any endpoint include effective_price, the price after the best active promotion, and the applied promotion",
 				"endpoints": []fiber.Map{
 					{
 						"method":      "GET",
 						"path":        "/books",
-						"description": "List all books with pagination",
+						"description": "List active books with pagination; drafts and archived books are listed at /admin/books. With READ_MODELS_ENABLED the list is served from the book_listings read model, trailing writes by about a second; authors and categories then carry only id, name, and slug, and books include average_rating and ratings_count",
 						"parameters":  []string{"page", "limit"},
 						"response":    "List of books with pagination info",
 					},
@@ -130,23 +209,52 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"method":      "POST",
 						"path":        "/books",
 						"description": "Create a new book",
-						"body":        "Book data (title, isbn, description, price, stock, author_id, category_id)",
+						"body":        "Book data (title, isbn, description, price, stock, author_id, category_id, status: draft, active (default), or archived, and optional format: hardcover, paperback, ebook, or audiobook, and page_count). ISBN-10 or ISBN-13, hyphens allowed; stored as ISBN-13. Duplicate ISBNs return 409",
 						"response":    "Created book object",
 					},
 					{
 						"method":      "GET",
 						"path":        "/books/:id",
-						"description": "Get book by ID",
-						"parameters":  []string{"id (UUID)"},
+						"description": "Get book by ID or short code; the view is recorded for book stats, trending books, and, when signed in, your recently viewed books",
+						"parameters":  []string{"id (UUID or 8-character short_code)"},
+						"response":    "Book object with author and category",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/slug/:slug",
+						"description": "Get book by slug; a slug the book had before being renamed redirects with 301 to the current one",
+						"parameters":  []string{"slug"},
 						"response":    "Book object with author and category",
 					},
 					{
 						"method":      "PUT",
 						"path":        "/books/:id",
-						"description": "Update book",
+						"description": "Update book (admin, or the user who claimed the book's author). Changes by owners, who may change any field but stock, are submitted as a pending revision and reach the catalog once an admin approves them at /admin/revisions",
 						"parameters":  []string{"id (UUID)"},
-						"body":        "Updated book data",
-						"response":    "Success message",
+						"body":        "Updated book data, and an optional note to the reviewer",
+						"response":    "Success message, or 202 with the pending revision for owners",
+					},
+					{
+						"method":      "GET",
+						"path":        "/revisions",
+						"description": "List the book revisions you submitted, with their review status and note (authentication required)",
+						"parameters":  []string{"status (pending, approved, or rejected)", "page", "limit"},
+						"response":    "Paginated revisions",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/:id/revisions",
+						"description": "List the history of a book, newest first: every change applied to it, with a snapshot of the book after it, and the revisions waiting for review (admin, or the user who claimed the book's author)",
+						"parameters":  []string{"id (UUID)", "status (pending, approved, or rejected)", "page", "limit"},
+						"response":    "Paginated revisions",
+					},
+					{
+						"method":      "POST",
+						"path":        "/books/:id/revisions/:revisionId/revert",
+						"description": "Revert an approved revision, setting the fields it changed back to their earlier values and recording the revert as a new revision. Fields changed again since conflict unless force is set (admin only)",
+						"parameters":  []string{"id (UUID)", "revisionId (UUID)"},
+						"body":        "Optional force",
+						"response":    "The revision recording the revert, or 409 on conflict",
 					},
 					{
 						"method":      "DELETE",
@@ -158,10 +266,38 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 					{
 						"method":      "GET",
 						"path":        "/books/search",
-						"description": "Search books",
+						"description": "Search active books",
 						"parameters":  []string{"q (query string)"},
 						"response":    "List of matching books",
 					},
+					{
+						"method":      "GET",
+						"path":        "/books/compare",
+						"description": "Compare 2 to 5 active books side by side, for storefront comparison widgets; every book has the same keys, null where it lacks the attribute. Unknown or inactive books return 404",
+						"parameters":  []string{"ids (comma-separated book UUIDs)"},
+						"response":    "books, in the order asked for, with price, effective_price, format, page_count, average_rating, ratings_count, stock, in_stock, and availability by warehouse as on the book's own page; and best, the IDs of the lowest_price, highest_rating, and most_pages books",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/export",
+						"description": "Export books as a streamed CSV download, or as an operation with async=true (admin role required)",
+						"parameters":  []string{"format (csv)", "columns (comma-separated, optional)", "q", "author_id", "category_id", "async (run as an operation)"},
+						"response":    "text/csv attachment, or 202 with the operation and a Location header",
+					},
+					{
+						"method":      "POST",
+						"path":        "/books/import",
+						"description": "Import books from CSV (multipart field \"file\" or text/csv body). Columns: title, isbn, price, description, stock, published_at, author_id or author, category_id or category (admin role required)",
+						"parameters":  []string{"dry_run (validate only)", "batch_size (rows per transaction, default IMPORT_BATCH_SIZE)", "async (run as an operation; files over OPERATIONS_ASYNC_IMPORT_SIZE always do)"},
+						"response":    "Per-row import report with failed batches, or 202 with the operation and a Location header",
+					},
+					{
+						"method":      "POST",
+						"path":        "/books/lookup",
+						"description": "Look up title, description, cover, and authors for an ISBN from Open Library or Google Books to pre-fill a new book. Authors are matched to existing authors by name (admin role required)",
+						"parameters":  []string{"isbn (ISBN-10 or ISBN-13)"},
+						"response":    "Book metadata with matched author IDs and the ID of any existing book with the ISBN",
+					},
 					{
 						"method":      "GET",
 						"path":        "/books/author/:authorId",
@@ -173,9 +309,58 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"method":      "GET",
 						"path":        "/books/category/:categoryId",
 						"description": "Get books by category",
-						"parameters":  []string{"categoryId (UUID)", "page", "limit"},
+						"parameters":  []string{"categoryId (UUID)", "page", "limit", "include_descendants (true to include books in subcategories)"},
 						"response":    "List of books by category",
 					},
+					{
+						"method":      "GET",
+						"path":        "/books/:id/price-history",
+						"description": "Get the book's price changes, newest first, with its current price and the lowest prices over the last days (EU price reduction disclosure)",
+						"parameters":  []string{"id (UUID)", "days (1-365, default 30)", "page", "limit"},
+						"response":    "Price summary and paginated price history",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/:id/stats",
+						"description": "Get the book's view counts: total, signed-in unique viewers, last 24 hours, 7 days, and 30 days, and per UTC day for the last 30 days (admin, or the user who claimed the book's author)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Book view stats",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/trending",
+						"description": "List the books viewed most within TRENDING_WINDOW (default 7 days), each with its views in the window",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated list of books with views",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/recently-viewed",
+						"description": "List the books you viewed, most recently viewed first (authentication required)",
+						"parameters":  []string{"limit (1-50, default 10)"},
+						"response":    "List of books",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/:id/preview",
+						"description": "Download the book's sample content (PDF, EPUB, or plain text); supports Range requests for partial content and If-None-Match/If-Modified-Since",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "The preview file, 206 with Content-Range for range requests, or 404 when the book has no preview",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/books/:id/preview",
+						"description": "Replace the book's preview with a PDF, EPUB, or UTF-8 plain text excerpt sent as the multipart \"file\" field or as the request body; other formats return 415 (admin, or the user who claimed the book's author)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Preview metadata (content_type, size)",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/books/:id/preview",
+						"description": "Remove the book's preview (admin, or the user who claimed the book's author)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
 					{
 						"method":      "PUT",
 						"path":        "/books/:id/stock",
@@ -184,52 +369,976 @@ func (h *DocsHandler) GetAPIDocs(c *fiber.Ctx) error {
 						"body":        "Stock data (stock: number)",
 						"response":    "Success message",
 					},
+					{
+						"method":      "POST",
+						"path":        "/books/:id/stock/adjustments",
+						"description": "Add a positive or negative delta to a book's stock; 409 if it would go negative (INVENTORY_MODE=event_sourced, admin only)",
+						"parameters":  []string{"id (UUID)"},
+						"body":        "Adjustment (delta: non-zero number, note: optional string)",
+						"response":    "Stock movement recorded",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/:id/stock/movements",
+						"description": "List a book's stock movements, newest first (INVENTORY_MODE=event_sourced, admin only)",
+						"parameters":  []string{"id (UUID)", "page", "limit"},
+						"response":    "List of stock movements with pagination info",
+					},
+					{
+						"method":      "GET",
+						"path":        "/books/:id/translations",
+						"description": "List the book's translations by locale",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Map of locale to translated fields",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/books/:id/translations/:locale",
+						"description": "Create or replace the book's translated fields in a locale; an empty value removes that field (admin role required)",
+						"parameters":  []string{"id (UUID)", "locale (BCP 47 tag, e.g. fr or pt-BR)"},
+						"body":        "title, description",
+						"response":    "Translated fields in the locale",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/books/:id/translations/:locale",
+						"description": "Delete the book's translations in a locale (admin role required)",
+						"parameters":  []string{"id (UUID)", "locale (BCP 47 tag)"},
+						"response":    "Success message",
+					},
 				},
 			},
-			"health": fiber.Map{
-				"description": "Health check endpoints",
+			"search": fiber.Map{
+				"description": "Search books, authors, and categories in one call. Every search's q, here and on /books/search, /authors/search, and /categories/search, matches anywhere in a field, ignoring case, and % and _ match only themselves; a query in double quotes, such as \"dune\", matches whole fields. Queries are at most 200 characters",
 				"endpoints": []fiber.Map{
 					{
 						"method":      "GET",
-						"path":        "/health",
-						"description": "Check application health",
-						"response":    "Health status",
+						"path":        "/search",
+						"description": "Search books (title, ISBN, description), authors (name, email), and categories (name, description) concurrently; each group is ranked with exact, then prefix, then other title or name matches first",
+						"parameters":  []string{"q (query string)", "page", "limit", "books_page, authors_page, categories_page (page of one group, default page)"},
+						"response":    "books, authors, and categories groups, each with items and pagination",
+					},
+				},
+			},
+			"alerts": fiber.Map{
+				"description": "Saved searches and back-in-stock alerts of the signed-in user. The alert_scan task emails users about books added since a search was saved or last checked, and once about each subscribed book coming back in stock",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "POST",
+						"path":        "/saved-searches",
+						"description": "Save a book search; only books added later are reported (authentication required, at most 50 per user)",
+						"body":        "Search data (query, matched like /books/search; optional name, default the query)",
+						"response":    "Created saved search",
 					},
 					{
 						"method":      "GET",
-						"path":        "/ready",
-						"description": "Check application readiness",
-						"response":    "Readiness status",
+						"path":        "/saved-searches",
+						"description": "List your saved searches (authentication required)",
+						"response":    "Saved searches",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/saved-searches/:id",
+						"description": "Delete one of your saved searches (authentication required)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/books/:id/stock-alert",
+						"description": "Get notified over your back_in_stock channels, email by default, when the out-of-stock book is back in stock; fails with 409 if it is in stock. Subscribing again after being notified arms the alert again (authentication required)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Stock alert",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/books/:id/stock-alert",
+						"description": "Unsubscribe from the book coming back in stock (authentication required)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "GET",
+						"path":        "/stock-alerts",
+						"description": "List your stock alerts with their books; notified_at is set on alerts that have fired (authentication required)",
+						"response":    "Stock alerts",
 					},
 				},
 			},
-		},
-		"authentication": fiber.Map{
-			"type":        "Bearer Token",
-			"description": "Include 'Authorization: Bearer <token>' header for protected endpoints",
-			"note":        "Currently using placeholder authentication",
-		},
-		"pagination": fiber.Map{
-			"parameters": []string{"page (default: 1)", "limit (default: 10, max: 100)"},
-			"response":   "Includes pagination info with total, total_pages, page, limit",
-		},
-		"error_format": fiber.Map{
-			"structure": fiber.Map{
-				"error":   "boolean",
-				"message": "string",
-				"details": "string (optional)",
+			"preferences": fiber.Map{
+				"description": "Marketing preferences and consents of the signed-in user. Each consent records when it was given or withdrawn and the privacy policy version it was given under; marketing email is only sent to users who consent to email_marketing",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/preferences",
+						"description": "Get your consents; users who never set any have every consent withheld (authentication required)",
+						"response":    "Preferences",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/preferences",
+						"description": "Give or withdraw consents; each change is added to your consent history with the request's IP address and user agent (authentication required)",
+						"body":        "Consents to change (email_marketing, data_processing; omitted ones are unchanged) and optional policy_version, default PRIVACY_POLICY_VERSION",
+						"response":    "Updated preferences",
+					},
+					{
+						"method":      "GET",
+						"path":        "/preferences/consents",
+						"description": "List the consents you gave and withdrew, newest first (authentication required)",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated consent history",
+					},
+					{
+						"method":      "GET",
+						"path":        "/preferences/notifications",
+						"description": "Get the channels (email, sms, push, slack) you receive each notification topic over, with your phone number, push tokens, and Slack webhook URL; topics are back_in_stock and saved_search, plus low_stock for admins (authentication required)",
+						"response":    "Notification settings",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/preferences/notifications",
+						"description": "Choose channels per topic and set contact details; channels the server has no credentials for are skipped when notifying (authentication required)",
+						"body":        "Optional channels (topic to channel list), phone (E.164), push_tokens (up to 10 FCM tokens), slack_webhook_url (https://hooks.slack.com/...); empty strings remove contact details",
+						"response":    "Updated notification settings",
+					},
+				},
 			},
-			"example": fiber.Map{
-				"error":   true,
-				"message": "Validation failed",
-				"details": "Name is required; Email must be a valid email address",
+			"auth": fiber.Map{
+				"description": "Social login with Google and GitHub. Signing in starts a session and returns a short-lived bearer access token plus a refresh token that rotates on every use; reusing an old refresh token revokes the session",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/auth/providers",
+						"description": "List the configured identity providers",
+						"response":    "Provider names",
+					},
+					{
+						"method":      "GET",
+						"path":        "/auth/:provider/login",
+						"description": "Redirect to the provider's sign-in page (google or github). After repeated failed logins from the client's IP a solved CAPTCHA is required",
+						"parameters":  []string{"captcha_token (when asked for one)"},
+						"response":    "302 redirect",
+					},
+					{
+						"method":      "GET",
+						"path":        "/auth/:provider/callback",
+						"description": "Provider redirect target; links the identity to a local user by verified email or creates one",
+						"parameters":  []string{"code", "state"},
+						"response":    "Access token, refresh token, session ID, and user",
+					},
+					{
+						"method":      "POST",
+						"path":        "/auth/refresh",
+						"description": "Exchange a refresh token for a new access token and refresh token. After repeated failures from the client's IP a solved CAPTCHA is required in the X-Captcha-Token header",
+						"body":        "refresh_token",
+						"response":    "Access token, rotated refresh token, session ID, and user",
+					},
+					{
+						"method":      "POST",
+						"path":        "/auth/logout",
+						"description": "Revoke the current session (authentication required)",
+						"response":    "Success message",
+					},
+					{
+						"method":      "GET",
+						"path":        "/auth/sessions",
+						"description": "List the signed-in user's active sessions (authentication required)",
+						"response":    "Sessions with device, IP, last use, and whether each is the current one",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/auth/sessions/:id",
+						"description": "Revoke one session (authentication required)",
+						"response":    "Success message",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/auth/sessions",
+						"description": "Revoke all of the user's sessions, including the current one (authentication required)",
+						"response":    "Number of revoked sessions",
+					},
+					{
+						"method":      "GET",
+						"path":        "/auth/me",
+						"description": "Get the signed-in user (authentication required)",
+						"response":    "User object with linked identities",
+					},
+				},
+			},
+			"events": fiber.Map{
+				"description": "Live book stock and price changes over Server-Sent Events",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/events",
+						"description": "Stream book.stock_changed and book.price_changed events. Send Last-Event-ID (or last_event_id) to resume after a disconnect",
+						"parameters":  []string{"types (comma-separated, optional)", "book_id (optional)", "last_event_id (optional)"},
+						"response":    "text/event-stream of event envelopes",
+					},
+				},
+			},
+			"feeds": fiber.Map{
+				"description": "Storefront product feeds of the tenant's active books for marketing tools, served at stable URLs at the server root rather than under /api/v1. The feed_generation task regenerates them; books link to STOREFRONT_URL + STOREFRONT_BOOK_PATH, or the tenant's domain",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/feeds/google-merchant.xml",
+						"description": "Google Merchant Center product feed of every active book, identified by ISBN, with sale prices under current promotions",
+						"response":    "RSS 2.0 XML with the g: namespace",
+					},
+					{
+						"method":      "GET",
+						"path":        "/feeds/new-releases.rss",
+						"description": "RSS feed of the most recently published books (FEEDS_NEW_RELEASES_LIMIT)",
+						"response":    "RSS 2.0 XML",
+					},
+					{
+						"method":      "GET",
+						"path":        "/feeds/new-releases.atom",
+						"description": "Atom feed of the most recently published books",
+						"response":    "Atom XML",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/feeds/generate",
+						"description": "Regenerate the tenant's feeds now (admin role required)",
+						"response":    "Number of books in each feed",
+					},
+				},
+			},
+			"sitemaps": fiber.Map{
+				"description": "Sitemaps of the storefront pages of the tenant's active books, authors, and categories, by slug with lastmod from when each was last updated, served at the server root. The sitemap_generation task regenerates them in pages of SITEMAP_PAGE_SIZE URLs; the index links to the pages under STOREFRONT_URL or the tenant's domain, so the storefront should proxy /sitemap.xml and /sitemaps/ to the API",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/sitemap.xml",
+						"description": "Sitemap index listing every sitemap page",
+						"response":    "Sitemap index XML",
+					},
+					{
+						"method":      "GET",
+						"path":        "/sitemaps/:name",
+						"description": "A sitemap page, named <section>-<page>.xml with section books, authors, or categories (e.g. books-1.xml)",
+						"response":    "Sitemap XML",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/sitemaps/generate",
+						"description": "Regenerate the tenant's sitemaps now (admin role required)",
+						"response":    "Number of URLs in each section",
+					},
+				},
+			},
+			"dashboard": fiber.Map{
+				"description": "Real-time admin dashboard over WebSocket (admin role required; pass the token as Authorization header or token query parameter)",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/ws",
+						"description": "Upgrade to a WebSocket that pushes metrics snapshots (active sessions, events per minute, job backlog, low stock books) and low_stock_alert messages",
+						"parameters":  []string{"token (optional, instead of Authorization header)"},
+						"response":    "JSON messages {type, timestamp, data}",
+					},
+				},
+			},
+			"webhooks": fiber.Map{
+				"description": "Outbound webhook subscriptions (admin role required). Deliveries are signed with X-Bookstore-Signature: sha256=HMAC-SHA256(secret, timestamp + \".\" + body), and carry the traceparent of the request that caused the event",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "POST",
+						"path":        "/webhooks",
+						"description": "Register a webhook",
+						"body":        "Webhook data (url, event_types, description); empty event_types or \"*\" subscribes to all events",
+						"response":    "Created webhook including its signing secret",
+					},
+					{
+						"method":      "GET",
+						"path":        "/webhooks",
+						"description": "List webhooks",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated list of webhooks",
+					},
+					{
+						"method":      "GET",
+						"path":        "/webhooks/:id",
+						"description": "Get a webhook",
+						"response":    "Webhook",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/webhooks/:id",
+						"description": "Update a webhook",
+						"body":        "Updated webhook data (url, event_types, description, active)",
+						"response":    "Success message",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/webhooks/:id",
+						"description": "Delete a webhook",
+						"response":    "Success message",
+					},
+					{
+						"method":      "GET",
+						"path":        "/webhooks/:id/deliveries",
+						"description": "List deliveries of a webhook",
+						"parameters":  []string{"page", "limit", "status (pending, succeeded, failed)"},
+						"response":    "Paginated delivery log",
+					},
+					{
+						"method":      "GET",
+						"path":        "/webhooks/:id/deliveries/:deliveryId",
+						"description": "Get a single delivery with its response",
+						"response":    "Webhook delivery",
+					},
+				},
+			},
+			"promotions": fiber.Map{
+				"description": "Scheduled discounts on a book or a whole category (admin role required). A promotion applies from starts_at until ends_at once the promotion_sync task activates it, recording promotion.started and promotion.ended events",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "POST",
+						"path":        "/promotions",
+						"description": "Create a promotion",
+						"body":        "Promotion data (name, book_id or category_id, price or discount_percent, starts_at, ends_at). A fixed price only applies to a book",
+						"response":    "Created promotion",
+					},
+					{
+						"method":      "GET",
+						"path":        "/promotions",
+						"description": "List promotions, latest starting first",
+						"parameters":  []string{"page", "limit", "active (true for promotions currently applied)"},
+						"response":    "Paginated list of promotions",
+					},
+					{
+						"method":      "GET",
+						"path":        "/promotions/:id",
+						"description": "Get a promotion",
+						"response":    "Promotion",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/promotions/:id",
+						"description": "Update a promotion; setting price or discount_percent replaces the other",
+						"body":        "Updated promotion data (name, price, discount_percent, starts_at, ends_at)",
+						"response":    "Updated promotion",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/promotions/:id",
+						"description": "Delete a promotion, ending it if active",
+						"response":    "Success message",
+					},
+				},
+			},
+			"warehouses": fiber.Map{
+				"description": "Stock held at several locations (admin role required). A book's stock stays its total on hand; the part not held at any warehouse is unallocated, and GET /books/:id shows where its stock is held as availability",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "POST",
+						"path":        "/warehouses",
+						"description": "Create a warehouse",
+						"body":        "Warehouse data (code, unique and stored upper case; name; address)",
+						"response":    "Created warehouse",
+					},
+					{
+						"method":      "GET",
+						"path":        "/warehouses",
+						"description": "List warehouses by code",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated list of warehouses",
+					},
+					{
+						"method":      "GET",
+						"path":        "/warehouses/:id",
+						"description": "Get a warehouse",
+						"response":    "Warehouse",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/warehouses/:id",
+						"description": "Update a warehouse",
+						"body":        "Updated warehouse data (code, name, address)",
+						"response":    "Updated warehouse",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/warehouses/:id",
+						"description": "Delete a warehouse; fails with 409 while it holds stock",
+						"response":    "Success message",
+					},
+					{
+						"method":      "GET",
+						"path":        "/warehouses/:id/stock",
+						"description": "List the books the warehouse holds stock of",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated stock levels with each book's title and ISBN",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/warehouses/:id/stock/:bookId",
+						"description": "Set the stock of a book held at the warehouse, after a count or a delivery; the book's total stock changes by the same amount",
+						"body":        "quantity and an optional note",
+						"response":    "Stock level",
+					},
+					{
+						"method":      "POST",
+						"path":        "/warehouses/transfers",
+						"description": "Move stock of a book between warehouses; leave out from_warehouse_id to allocate unallocated stock, or to_warehouse_id to return stock to unallocated. Fails with 409 when the source holds too little",
+						"body":        "Transfer data (book_id, from_warehouse_id, to_warehouse_id, quantity, note)",
+						"response":    "Created transfer",
+					},
+					{
+						"method":      "GET",
+						"path":        "/warehouses/transfers",
+						"description": "List stock transfers, newest first",
+						"parameters":  []string{"book_id", "warehouse_id (transfers from or to it)", "page", "limit"},
+						"response":    "Paginated list of transfers",
+					},
+				},
+			},
+			"suppliers": fiber.Map{
+				"description": "Dropshipping suppliers whose availability and price feeds (CSV or JSON over HTTP) are synced into the catalog by ISBN (admin role required). The supplier_sync task syncs enabled suppliers; each sync records the changes it applied. A supplier with a warehouse_id sets the books' stock at that warehouse rather than their total",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "POST",
+						"path":        "/suppliers",
+						"description": "Create a supplier. A CSV feed has a header row naming isbn, price, and stock columns; a JSON feed is an array of {isbn, price, stock} objects, or an object holding it as items. Blank or missing price and stock are left unchanged",
+						"body":        "Supplier data (name; feed_url; format, csv or json; auth_header and auth_value, a header sent with feed requests; warehouse_id; update_prices, update_stock, and enabled, default true)",
+						"response":    "Created supplier; auth_value is never returned",
+					},
+					{
+						"method":      "GET",
+						"path":        "/suppliers",
+						"description": "List suppliers by name",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated list of suppliers with their last sync time and error",
+					},
+					{
+						"method":      "GET",
+						"path":        "/suppliers/:id",
+						"description": "Get a supplier",
+						"response":    "Supplier",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/suppliers/:id",
+						"description": "Update a supplier; an empty warehouse_id makes the feed set the books' total stock again",
+						"body":        "Updated supplier data",
+						"response":    "Updated supplier",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/suppliers/:id",
+						"description": "Delete a supplier and its sync history; the prices and stock it set are kept",
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/suppliers/:id/sync",
+						"description": "Sync the supplier's feed now. Rows are matched to books by ISBN, and prices and stock that differ are applied with price history and stock movements. Responds 502 with the failed sync when the feed cannot be fetched or parsed",
+						"parameters":  []string{"dry_run (true to record the changes without applying them)"},
+						"response":    "Sync with row counts (rows, invalid, matched, unmatched, updated, failed) and its changes",
+					},
+					{
+						"method":      "GET",
+						"path":        "/suppliers/:id/syncs",
+						"description": "List the supplier's syncs, newest first, without their changes",
+						"parameters":  []string{"page", "limit"},
+						"response":    "Paginated list of syncs",
+					},
+					{
+						"method":      "GET",
+						"path":        "/suppliers/:id/syncs/:syncId",
+						"description": "Get a sync with each change it applied: book_id, isbn, field (price or stock), from, to, and error when the change could not be applied",
+						"response":    "Sync",
+					},
+				},
+			},
+			"rentals": fiber.Map{
+				"description": "Lending book copies, available when RENTALS_ENABLED is set. A book's stock is its number of copies; a copy is out from starts_at until it is returned, and rentals still out after due_at are flagged late by the rental_late_scan task",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/books/:id/availability",
+						"description": "Get the number of copies free on each day",
+						"parameters":  []string{"id (UUID)", "from (YYYY-MM-DD, default today)", "to (YYYY-MM-DD, inclusive, default 30 days; at most 180 days)"},
+						"response":    "Copies and per-day availability",
+					},
+					{
+						"method":      "POST",
+						"path":        "/books/:id/rentals",
+						"description": "Rent a copy of the book; fails with 409 if every copy is out at some point in the period (authentication required)",
+						"body":        "Optional rental data (starts_at, default now; due_at, default RENTAL_DEFAULT_PERIOD later; user_id, admin only)",
+						"response":    "Created rental",
+					},
+					{
+						"method":      "GET",
+						"path":        "/rentals",
+						"description": "List rentals; users see their own, admins see all (authentication required)",
+						"parameters":  []string{"page", "limit", "status (out, overdue, returned)", "book_id", "user_id (admin only)"},
+						"response":    "Paginated list of rentals",
+					},
+					{
+						"method":      "GET",
+						"path":        "/rentals/:id",
+						"description": "Get a rental by ID or short code (renter or admin)",
+						"response":    "Rental with its book",
+					},
+					{
+						"method":      "POST",
+						"path":        "/rentals/:id/return",
+						"description": "Return a rented copy, given by ID or short code; returns after due_at are flagged late (renter or admin)",
+						"response":    "Returned rental",
+					},
+				},
+			},
+			"operations": fiber.Map{
+				"description": "Long-running book imports and exports started with async=true (AIP-151). An operation is done once completed or failed; a completed export links its download and a completed import's result is its report (admin role required)",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/operations/:id",
+						"description": "Get an operation started by the tenant; also available as the gRPC OperationsService.GetOperation",
+						"response":    "Operation with name, type, status, done, progress (percent, step, done, total), links, and its result or error",
+					},
+					{
+						"method":      "GET",
+						"path":        "/operations/:id/download",
+						"description": "Download the CSV file of a completed export; 409 for other operations",
+						"response":    "text/csv attachment",
+					},
+				},
+			},
+			"admin": fiber.Map{
+				"description": "Administrative endpoints (admin role required)",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/admin/books",
+						"description": "List books of every status, including drafts and archived books that public listings and searches leave out",
+						"parameters":  []string{"status (draft, active, or archived)", "q (search title, ISBN, or description)", "page", "limit"},
+						"response":    "Paginated books",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/stats",
+						"description": "Catalog statistics: counts, stock value, top categories and authors, and new title trend",
+						"parameters":  []string{"from (YYYY-MM-DD or RFC 3339, default 30 days ago)", "to (default now)", "period (day, week, or month)", "top (number of top categories and authors, default 5, max 50)"},
+						"response":    "Statistics report",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/stats/rentals",
+						"description": "Most rented books and daily rentals, read from the rental statistics view as of its last refresh (RENTALS_ENABLED)",
+						"parameters":  []string{"from (YYYY-MM-DD or RFC 3339, default 30 days ago)", "to (default today, inclusive)", "top (number of top books, default 5, max 50)"},
+						"response":    "Rental statistics report",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/scheduler",
+						"description": "List scheduled tasks with last-run status (default tenant only)",
+						"response":    "List of scheduled task statuses",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/scheduler/:name/run",
+						"description": "Run a scheduled task immediately (default tenant only)",
+						"parameters":  []string{"name (task name)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/onix/import",
+						"description": "Import an ONIX 3.0 feed (reference tags) sent as multipart field \"file\" or an XML body. Products are upserted by ISBN; authors, categories, and publishers are matched by name or created",
+						"parameters":  []string{"dry_run (validate only)", "batch_size (new books per transaction, default IMPORT_BATCH_SIZE)"},
+						"response":    "Import summary with per-product errors and failed batches",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/catalog/diff",
+						"description": "Compare two catalog archives written by migrate -action backup, such as ones taken before and after an import, sent as multipart fields \"before\" and \"after\" (optionally gzipped). Titles are matched by tenant and ISBN; author, category, and publisher are compared by name. Default tenant only; larger archives can be compared offline with migrate -action diff",
+						"response":    "Archive summaries and the titles added, removed, and changed, with from and to values for each changed field",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/admin/authors/:id/owner",
+						"description": "Assign an author to a user account, or unassign it",
+						"body":        "user_id (UUID, or null to unassign)",
+						"response":    "Updated author object",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/reports",
+						"description": "List scheduled report definitions",
+						"parameters":  []string{"page", "limit"},
+						"response":    "List of report definitions with last run status",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/reports",
+						"description": "Create a scheduled report emailed as a CSV or PDF attachment. Types: inventory, low_stock, new_titles, catalog_summary, top_rentals (most rented books, from the rental_daily_stats view)",
+						"body":        "name, report_type, format (csv or pdf, default csv), schedule (cron expression), recipients (emails), period (week or month, default week), enabled",
+						"response":    "Created report definition with its next run",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/reports/:id",
+						"description": "Get a report definition",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Report definition",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/admin/reports/:id",
+						"description": "Update a report definition; changing the schedule or enabling it recomputes the next run",
+						"parameters":  []string{"id (UUID)"},
+						"body":        "Updated report fields",
+						"response":    "Success message",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/admin/reports/:id",
+						"description": "Delete a report definition",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/reports/:id/run",
+						"description": "Generate and email a report now as a background job",
+						"parameters":  []string{"id (UUID)"},
+						"body":        "Optional recipients overriding the definition's",
+						"response":    "Scheduled job",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/reports/:id/download",
+						"description": "Generate a report for its most recent period and download it in its format",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "CSV or PDF file",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/reports/:id/download-link",
+						"description": "Create a signed link to /reports/:id/download that downloads the report without an access token until it expires (SIGNED_URL_TTL)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "url and expires_at",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/tenants",
+						"description": "List tenants (default tenant only)",
+						"parameters":  []string{"page", "limit"},
+						"response":    "List of tenants",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/tenants",
+						"description": "Create a tenant (default tenant only)",
+						"body":        "slug (subdomain label), name, domain, rate_limit, strict_rate_limit, low_stock_threshold (0 uses the global value)",
+						"response":    "Created tenant object",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/tenants/:id",
+						"description": "Get a tenant (default tenant only)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Tenant object",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/admin/tenants/:id",
+						"description": "Update a tenant; an empty domain removes it (default tenant only)",
+						"parameters":  []string{"id (UUID)"},
+						"body":        "name, domain, active, rate_limit, strict_rate_limit, low_stock_threshold",
+						"response":    "Success message",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/admin/tenants/:id",
+						"description": "Delete a tenant; its data is kept but no longer served (default tenant only)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/users",
+						"description": "Create a user, admin by default",
+						"body":        "email, name, role (customer or admin)",
+						"response":    "Created user object",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/admin/users/:id/role",
+						"description": "Change a user's role",
+						"parameters":  []string{"id (UUID)"},
+						"body":        "role (customer or admin)",
+						"response":    "Success message",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/api-keys",
+						"description": "List API keys with their hints and last use",
+						"parameters":  []string{"page", "limit"},
+						"response":    "List of API keys",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/api-keys",
+						"description": "Create an API key for scripts and tools; the key is only returned once",
+						"body":        "name, role (customer or admin, default admin), expires_at (optional)",
+						"response":    "Created API key with its key",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/api-keys/:id/rotate",
+						"description": "Replace an API key; the previous key stops working at once",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "API key with its new key",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/admin/api-keys/:id",
+						"description": "Revoke an API key",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/maintenance/reindex",
+						"description": "Rebuild the catalog search indexes and refresh planner statistics (default tenant only)",
+						"response":    "Reindexed tables",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/maintenance/partitions",
+						"description": "List the monthly partitions of stock_movements, security_events, and book_views with their ranges, estimated rows, and sizes (default tenant only)",
+						"response":    "List of partitions",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/reindex",
+						"description": "Schedule a background rebuild of the search indexes and, with read models enabled, every tenant's book listings (default tenant only)",
+						"response":    "202 with the job; follow its progress at /admin/jobs/:id",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/cache/flush",
+						"description": "Schedule a background flush of the cached pagination counts, tenants, and metadata lookups of the instance running the job (default tenant only)",
+						"response":    "202 with the job; follow its progress at /admin/jobs/:id",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/jobs/:id",
+						"description": "Get a background job with its status and progress: the current step and units done of total (default tenant only)",
+						"response":    "Job",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/dead-letters",
+						"description": "List the dead-letter queue: background jobs, such as webhook deliveries, that failed every attempt, with their payloads and last errors, most recently failed first (default tenant only)",
+						"parameters":  []string{"type (job type, e.g. webhook.deliver)", "failed_before (RFC 3339)", "page", "limit"},
+						"response":    "Paginated failed jobs, with a summary of the number failed and the oldest and latest failure of each type",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/dead-letters/:id/retry",
+						"description": "Return a failed job to the queue with fresh attempts; 409 if the job has not failed (default tenant only)",
+						"response":    "Job",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/admin/dead-letters/:id",
+						"description": "Purge a failed job (default tenant only)",
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/dead-letters/retry",
+						"description": "Retry failed jobs in bulk (default tenant only)",
+						"body":        "ids, type, and failed_before select the jobs; all: true selects every failed job",
+						"response":    "Number of jobs retried",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/dead-letters/purge",
+						"description": "Purge failed jobs in bulk (default tenant only)",
+						"body":        "ids, type, and failed_before select the jobs; all: true selects every failed job",
+						"response":    "Number of jobs purged",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/revisions",
+						"description": "List book revisions submitted by editors who are not admins, oldest first",
+						"parameters":  []string{"status (pending, approved, or rejected)", "book_id (UUID)", "page", "limit"},
+						"response":    "Paginated revisions with each changed field's value before and after",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/revisions/:id",
+						"description": "Get a revision with its book as it is now",
+						"response":    "Revision",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/revisions/:id/approve",
+						"description": "Apply a pending revision to the live catalog; 409 if it is not pending or a field it changes was changed since it was submitted",
+						"body":        "Optional note, and force: true to apply it over later changes",
+						"response":    "Approved revision",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/revisions/:id/reject",
+						"description": "Reject a pending revision, leaving the book unchanged; 409 if it is not pending",
+						"body":        "Optional note explaining the rejection",
+						"response":    "Rejected revision",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/log-level",
+						"description": "Get the current log level (default tenant only)",
+						"response":    "Log level",
+					},
+					{
+						"method":      "PUT",
+						"path":        "/admin/log-level",
+						"description": "Change the log level of the server process, including gRPC call logging, until it restarts: debug, info, warn, or error (default tenant only)",
+						"body":        "level (debug, info, warn, or error)",
+						"response":    "Log level",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/slow-queries",
+						"description": "List this instance's most recent statements slower than SLOW_QUERY_THRESHOLD, newest first, with their parameters, duration, and EXPLAIN ANALYZE plan when SLOW_QUERY_EXPLAIN is set (default tenant only)",
+						"response":    "List of slow queries",
+					},
+					{
+						"method":      "DELETE",
+						"path":        "/admin/slow-queries",
+						"description": "Discard the slow queries kept by this instance (default tenant only)",
+						"response":    "Success message",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/maintenance/rebuild-stock",
+						"description": "Recompute every tenant's book stock from the stock movement log (INVENTORY_MODE=event_sourced, default tenant only)",
+						"response":    "Number of books corrected",
+					},
+					{
+						"method":      "POST",
+						"path":        "/admin/maintenance/refresh-stats",
+						"description": "Refresh the rental statistics views now instead of waiting for the stats_refresh task (RENTALS_ENABLED, default tenant only)",
+						"response":    "Refreshed views",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/archives",
+						"description": "List the archives of security events and book views moved to cold storage, newest rows first (ARCHIVE_ENABLED, default tenant only)",
+						"parameters":  []string{"table (security_events or book_views)", "page", "limit"},
+						"response":    "List of archive manifests with pagination info",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/archives/:id",
+						"description": "Get an archive's manifest: its storage key, row count, time range, size, and SHA-256 checksum (ARCHIVE_ENABLED, default tenant only)",
+						"parameters":  []string{"id (UUID)"},
+						"response":    "Archive manifest",
+					},
+					{
+						"method":      "GET",
+						"path":        "/admin/security-events",
+						"description": "List failed and successful logins and refreshes, lockouts, failed CAPTCHAs, refresh token reuse, and sign-ins from new devices, newest first",
+						"parameters":  []string{"type", "user_id (UUID)", "page", "limit"},
+						"response":    "List of security events with pagination info",
+					},
+				},
+			},
+			"health": fiber.Map{
+				"description": "Health check endpoints",
+				"endpoints": []fiber.Map{
+					{
+						"method":      "GET",
+						"path":        "/health",
+						"description": "Check application health, including the database supervisor's connection state",
+						"response":    "Health status",
+					},
+					{
+						"method":      "GET",
+						"path":        "/ready",
+						"description": "Check application readiness; returns 503 while the database connection is being recovered",
+						"response":    "Readiness status",
+					},
+				},
+			},
+		},
+		"tenancy": fiber.Map{
+			"description": "Every /api/v1 request belongs to one storefront, selected by the X-Tenant header (slug or ID), a tenant's custom domain, or its subdomain of TENANT_BASE_DOMAIN, falling back to TENANT_DEFAULT",
+			"note":        "Unknown tenants receive 404. Access tokens are only valid for the tenant that issued them. gRPC clients send the x-tenant metadata key",
+		},
+		"localization": fiber.Map{
+			"description": "Book and category responses are translated into the best match of the Accept-Language header (or the lang query parameter), falling back to the stored values in I18N_DEFAULT_LOCALE",
+			"note":        "Responses carry Content-Language with the locale served and Vary: Accept-Language",
+		},
+		"authentication": fiber.Map{
+			"type":        "Bearer Token",
+			"description": "Include 'Authorization: Bearer <token>' header for protected endpoints",
+			"api_keys":    "Scripts and tools may send an API key (bsk_...) created at /admin/api-keys in place of the token",
+			"note":        "Currently using placeholder authentication",
+			"brute_force": "Failed logins and token refreshes are counted per client IP and per account. After LOGIN_CAPTCHA_AFTER failures the IP gets 401 with code captcha_required until it sends a solved CAPTCHA; after LOGIN_LOCKOUT_AFTER the IP or account gets 429 with code locked_out and Retry-After, for a lockout that doubles with each further failure",
+		},
+		"timestamps": fiber.Map{
+			"description": "REST and gRPC responses give every timestamp in UTC as RFC 3339",
+			"note":        "published_at is a date (2006-01-02); requests may also send an RFC 3339 timestamp, whose date in PUBLICATION_TIMEZONE is kept",
+		},
+		"tracing": fiber.Map{
+			"description": "Each request gets a span, continuing the W3C traceparent header the client sent, and the response's traceparent header names its trace ID",
+			"propagation": "The trace follows the request into domain events, background jobs, gRPC-Web calls, webhook deliveries (traceparent header), and emails (X-Bookstore-Trace-Id header), so their log lines share the request's trace_id",
+		},
+		"modules": fiber.Map{
+			"description": "Routes are grouped into modules, such as books, authors, rentals, admin, webhooks, and admin-ui; SERVER_DISABLED_MODULES lists, comma-separated, the modules left out, whose routes answer 404",
+			"build_tags":  "Building with -tags nowebhooks or noadminui leaves the webhooks routes or the admin app out of the binary",
+		},
+		"pagination": fiber.Map{
+			"parameters": []string{"page (default: 1)", "limit (default: PAGINATION_DEFAULT_LIMIT, 10; max: PAGINATION_MAX_LIMIT, 100)"},
+			"response":   "Includes pagination info with total, total_pages, page, limit",
+			"limits":     "Endpoints may have their own default and max limits (PAGINATION_ROUTE_LIMITS). A page or limit that is not a positive integer, or a limit above the endpoint's max, is rejected with 400 rather than clamped",
+			"note":       "Totals may be cached or estimated depending on PAGINATION_COUNT_MODE (exact, cached, estimated)",
+		},
+		"links": fiber.Map{
+			"description": "Book, author, and category responses embed _links (self, author, category, books) in each resource when the request sets links=true or accepts application/hal+json",
+			"note":        "Listings also carry top-level _links with self, first, last, and where they exist prev and next pages, keeping the request's other query parameters",
+		},
+		"content_negotiation": fiber.Map{
+			"description": "GET requests are answered in the media type the Accept header prefers among application/json, application/xml, and application/msgpack, defaulting to JSON",
+			"note":        "XML responses wrap the JSON fields in a <response> element, with array items as <item> elements and keys that are not valid element names as <entry key=\"...\"> elements; streamed responses such as exports and events are not re-encoded",
+		},
+		"jsonapi": fiber.Map{
+			"description": "Book, author, and category reads answer with JSON:API documents (data, attributes, relationships, included, meta, links) when the request sends Accept: application/vnd.api+json or X-Response-Format: jsonapi, or RESPONSE_FORMAT is jsonapi",
+			"include":     "include=author,category adds a book's author and category to included; other paths are rejected with 400",
+			"fields":      "fields[books]=title,price (likewise fields[authors] and fields[categories]) limits the attributes and relationships returned for a type",
+			"errors":      "Error responses become {\"errors\": [{\"status\", \"code\", \"title\", \"detail\", \"source\"}]}; X-Response-Format: envelope restores the default format",
+		},
+		"error_format": fiber.Map{
+			"structure": fiber.Map{
+				"error":   "boolean",
+				"message": "string",
+				"details": "string (optional)",
+				"code":    "string (409 conflicts, captcha_required, locked_out)",
+				"field":   "string (409 conflicts only)",
+				"fields":  "array of {field, rule, message, param} (validation failures only)",
+			},
+			"example": fiber.Map{
+				"error":   true,
+				"message": "Validation failed",
+				"details": "name is required; email must be a valid email address",
+				"fields": []fiber.Map{
+					{"field": "name", "rule": "required", "message": "name is required"},
+					{"field": "email", "rule": "email", "message": "email must be a valid email address"},
+				},
 			},
+			"conflicts": "Writes that collide with a unique field return 409 with code duplicate_email (field email), duplicate_isbn (field isbn), or duplicate_category_name (field name). Creating a book whose ISBN is taken returns the existing book with 200 when the title, author, category, and price match, as when a create is retried, and otherwise 409 with the existing book_id",
 		},
 	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"message": "API documentation retrieved successfully",
-		"data":    docs,
-	})
+	return response.OK(c, "API documentation retrieved successfully", docs)
 }
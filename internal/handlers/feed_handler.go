@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/storefront"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeedHandler serves the generated storefront product feeds
+type FeedHandler struct {
+	feedService *services.FeedService
+}
+
+// NewFeedHandler creates a new feed handler
+func NewFeedHandler(svc *services.Services) *FeedHandler {
+	return &FeedHandler{
+		feedService: svc.Feeds,
+	}
+}
+
+// GetFeed serves a feed of the tenant as last generated
+func (h *FeedHandler) GetFeed(c *fiber.Ctx) error {
+	name := c.Params("name")
+	file, err := h.feedService.WithContext(c.UserContext()).OpenFeed(name)
+	if err != nil {
+		if err.Error() == "feed not found" {
+			return response.Error(c, fiber.StatusNotFound, "Feed not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get feed", err)
+	}
+
+	c.Set(fiber.HeaderContentType, storefront.ContentType(name))
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.SendStream(file)
+}
+
+// GenerateFeeds regenerates the tenant's feeds now rather than on the
+// feed_generation schedule
+func (h *FeedHandler) GenerateFeeds(c *fiber.Ctx) error {
+	counts, err := h.feedService.WithContext(c.UserContext()).GenerateFeeds()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to generate feeds", err)
+	}
+
+	return response.OK(c, "Feeds generated successfully", counts)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/services"
+)
+
+// Handlers holds the handler of every REST route group, for the HTTP server
+// to register
+type Handlers struct {
+	Admin        *AdminHandler
+	Alert        *AlertHandler
+	APIKey       *APIKeyHandler
+	Archive      *ArchiveHandler
+	Auth         *AuthHandler
+	Author       *AuthorHandler
+	AuthorPhoto  *AuthorPhotoHandler
+	Book         *BookHandler
+	BookPreview  *BookPreviewHandler
+	Category     *CategoryHandler
+	Dashboard    *DashboardHandler
+	DeadLetter   *DeadLetterHandler
+	Docs         *DocsHandler
+	Feed         *FeedHandler
+	Health       *HealthHandler
+	Notification *NotificationHandler
+	Operation    *OperationHandler
+	Preference   *PreferenceHandler
+	Promotion    *PromotionHandler
+	Rental       *RentalHandler
+	Report       *ReportHandler
+	Revision     *RevisionHandler
+	Search       *SearchHandler
+	Security     *SecurityHandler
+	Sitemap      *SitemapHandler
+	Stream       *StreamHandler
+	Supplier     *SupplierHandler
+	Tenant       *TenantHandler
+	Translation  *TranslationHandler
+	User         *UserHandler
+	View         *ViewHandler
+	Warehouse    *WarehouseHandler
+	Webhook      *WebhookHandler
+}
+
+// New builds every handler on the services in svc
+func New(cfg *config.Config, svc *services.Services) *Handlers {
+	return &Handlers{
+		Admin:        NewAdminHandler(cfg, svc),
+		Alert:        NewAlertHandler(svc),
+		APIKey:       NewAPIKeyHandler(svc),
+		Archive:      NewArchiveHandler(svc),
+		Auth:         NewAuthHandler(cfg, svc),
+		Author:       NewAuthorHandler(svc),
+		AuthorPhoto:  NewAuthorPhotoHandler(svc),
+		Book:         NewBookHandler(cfg, svc),
+		BookPreview:  NewBookPreviewHandler(svc),
+		Category:     NewCategoryHandler(svc),
+		Dashboard:    NewDashboardHandler(),
+		DeadLetter:   NewDeadLetterHandler(),
+		Docs:         NewDocsHandler(),
+		Feed:         NewFeedHandler(svc),
+		Health:       NewHealthHandler(),
+		Notification: NewNotificationHandler(svc),
+		Operation:    NewOperationHandler(),
+		Preference:   NewPreferenceHandler(svc),
+		Promotion:    NewPromotionHandler(svc),
+		Rental:       NewRentalHandler(cfg, svc),
+		Report:       NewReportHandler(cfg, svc),
+		Revision:     NewRevisionHandler(svc),
+		Search:       NewSearchHandler(svc),
+		Security:     NewSecurityHandler(svc),
+		Sitemap:      NewSitemapHandler(svc),
+		Stream:       NewStreamHandler(cfg.Stream.Heartbeat),
+		Supplier:     NewSupplierHandler(svc),
+		Tenant:       NewTenantHandler(svc),
+		Translation:  NewTranslationHandler(svc),
+		User:         NewUserHandler(svc),
+		View:         NewViewHandler(cfg, svc),
+		Warehouse:    NewWarehouseHandler(svc),
+		Webhook:      NewWebhookHandler(svc),
+	}
+}
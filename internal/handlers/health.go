@@ -15,26 +15,47 @@ func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{}
 }
 
-// Health returns the health status of the application
+// Health returns the health status of the application, with the database
+// supervisor's view of the connection when it is running
 func (h *HealthHandler) Health(c *fiber.Ctx) error {
 	// Check if database is available
 	if err := database.HealthCheck(); err != nil {
 		// If database is not available, return partial health
-		return c.JSON(fiber.Map{
+		response := fiber.Map{
 			"status":  "degraded",
 			"message": "Application running but database unavailable",
 			"error":   err.Error(),
-		})
+		}
+		if supervisor := database.GetSupervisor(); supervisor != nil {
+			response["database"] = supervisor.Status()
+		}
+		return c.JSON(response)
 	}
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"status":  "healthy",
 		"message": "All services are running",
-	})
+	}
+	if supervisor := database.GetSupervisor(); supervisor != nil {
+		response["database"] = supervisor.Status()
+	}
+	return c.JSON(response)
 }
 
-// Ready returns the readiness status of the application
+// Ready returns the readiness status of the application. It fails while the
+// database supervisor is recovering the connection.
 func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	if supervisor := database.GetSupervisor(); supervisor != nil {
+		if err := supervisor.Ready(); err != nil {
+			return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+				"status":   "not ready",
+				"message":  "Database connection is being recovered",
+				"error":    err.Error(),
+				"database": supervisor.Status(),
+			})
+		}
+	}
+
 	// Check if database is ready
 	if err := database.HealthCheck(); err != nil {
 		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
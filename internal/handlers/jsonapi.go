@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/render"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// JSON:API resource types
+const (
+	typeBooks      = "books"
+	typeAuthors    = "authors"
+	typeCategories = "categories"
+	typePublishers = "publishers"
+)
+
+// wantJSONAPI reports whether the request is answered with JSON:API
+// documents, as decided by the response format middleware
+func wantJSONAPI(c *fiber.Ctx) bool {
+	format, _ := c.Locals("response_format").(string)
+	return format == render.FormatJSONAPI
+}
+
+// sendJSONAPI responds with a JSON:API document
+func sendJSONAPI(c *fiber.Ctx, doc *render.Document) error {
+	if err := c.JSON(doc); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, render.JSONAPIMediaType)
+	return nil
+}
+
+// jsonapiError responds with a JSON:API error document
+func jsonapiError(c *fiber.Ctx, status int, title string, err error) error {
+	if err := c.Status(status).JSON(render.ErrorDocument{
+		Errors: []render.Error{{Status: fmt.Sprint(status), Title: title, Detail: err.Error()}},
+	}); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, render.JSONAPIMediaType)
+	return nil
+}
+
+// listMeta returns the pagination of a listing as document meta
+func listMeta(page, limit int, total int64) map[string]interface{} {
+	return map[string]interface{}{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	}
+}
+
+// toOne returns a to-one relationship to the resource with the given type and
+// ID, linked to the named route
+func toOne(c *fiber.Ctx, resourceType string, id uuid.UUID, route string) render.Relationship {
+	return render.Relationship{
+		Data:  &render.Identifier{Type: resourceType, ID: id.String()},
+		Links: routeLinks(c, map[string]routeRef{"related": {route, fiber.Map{"id": id.String()}}}),
+	}
+}
+
+// bookResource serializes a book, including its author and category when the
+// request asked for them
+func bookResource(c *fiber.Ctx, s *render.Serializer, book *models.Book) (render.Resource, error) {
+	attributes, err := render.Attributes(book, "author_id", "category_id", "publisher_id", "author", "category", "publisher")
+	if err != nil {
+		return render.Resource{}, err
+	}
+
+	relationships := map[string]render.Relationship{
+		"author":   toOne(c, typeAuthors, book.AuthorID, RouteAuthorsGet),
+		"category": toOne(c, typeCategories, book.CategoryID, RouteCategoriesGet),
+	}
+	if book.PublisherID != nil {
+		relationships["publisher"] = render.Relationship{
+			Data: &render.Identifier{Type: typePublishers, ID: book.PublisherID.String()},
+		}
+	}
+
+	if s.Includes("author") && book.Author.ID != uuid.Nil {
+		author, err := authorResource(c, &book.Author)
+		if err != nil {
+			return render.Resource{}, err
+		}
+		s.Include(author)
+	}
+	if s.Includes("category") && book.Category.ID != uuid.Nil {
+		category, err := categoryResource(c, &book.Category)
+		if err != nil {
+			return render.Resource{}, err
+		}
+		s.Include(category)
+	}
+
+	return s.Resource(render.Resource{
+		Identifier:    render.Identifier{Type: typeBooks, ID: book.ID.String()},
+		Attributes:    attributes,
+		Relationships: relationships,
+		Links:         routeLinks(c, map[string]routeRef{"self": {RouteBooksGet, fiber.Map{"id": book.ID.String()}}}),
+	}), nil
+}
+
+// authorResource serializes an author
+func authorResource(c *fiber.Ctx, author *models.Author) (render.Resource, error) {
+	attributes, err := render.Attributes(author, "books")
+	if err != nil {
+		return render.Resource{}, err
+	}
+
+	return render.Resource{
+		Identifier: render.Identifier{Type: typeAuthors, ID: author.ID.String()},
+		Attributes: attributes,
+		Relationships: map[string]render.Relationship{
+			"books": {Links: routeLinks(c, map[string]routeRef{
+				"related": {RouteBooksByAuthor, fiber.Map{"authorId": author.ID.String()}},
+			})},
+		},
+		Links: routeLinks(c, map[string]routeRef{"self": {RouteAuthorsGet, fiber.Map{"id": author.ID.String()}}}),
+	}, nil
+}
+
+// categoryResource serializes a category
+func categoryResource(c *fiber.Ctx, category *models.Category) (render.Resource, error) {
+	attributes, err := render.Attributes(category, "books", "parent_id")
+	if err != nil {
+		return render.Resource{}, err
+	}
+
+	relationships := map[string]render.Relationship{
+		"books": {Links: routeLinks(c, map[string]routeRef{
+			"related": {RouteBooksByCategory, fiber.Map{"categoryId": category.ID.String()}},
+		})},
+	}
+	if category.ParentID != nil {
+		relationships["parent"] = toOne(c, typeCategories, *category.ParentID, RouteCategoriesGet)
+	}
+
+	return render.Resource{
+		Identifier:    render.Identifier{Type: typeCategories, ID: category.ID.String()},
+		Attributes:    attributes,
+		Relationships: relationships,
+		Links:         routeLinks(c, map[string]routeRef{"self": {RouteCategoriesGet, fiber.Map{"id": category.ID.String()}}}),
+	}, nil
+}
+
+// jsonapiBook responds with a book as a JSON:API document
+func jsonapiBook(c *fiber.Ctx, book *models.Book) error {
+	s := render.NewSerializer(c.Queries())
+	if err := s.CheckIncludes("author", "category"); err != nil {
+		return jsonapiError(c, fiber.StatusBadRequest, "Invalid include", err)
+	}
+
+	resource, err := bookResource(c, s, book)
+	if err != nil {
+		return jsonapiError(c, fiber.StatusInternalServerError, "Failed to serialize book", err)
+	}
+	return sendJSONAPI(c, s.Document(resource))
+}
+
+// jsonapiBooks responds with a page of books as a JSON:API document
+func jsonapiBooks(c *fiber.Ctx, books []models.Book, page, limit int, total int64) error {
+	s := render.NewSerializer(c.Queries())
+	if err := s.CheckIncludes("author", "category"); err != nil {
+		return jsonapiError(c, fiber.StatusBadRequest, "Invalid include", err)
+	}
+
+	resources := make([]render.Resource, len(books))
+	for i := range books {
+		resource, err := bookResource(c, s, &books[i])
+		if err != nil {
+			return jsonapiError(c, fiber.StatusInternalServerError, "Failed to serialize books", err)
+		}
+		resources[i] = resource
+	}
+
+	doc := s.Document(resources)
+	doc.Meta = listMeta(page, limit, total)
+	doc.Links = pageLinks(c, page, limit, total)
+	return sendJSONAPI(c, doc)
+}
+
+// jsonapiAuthor responds with an author as a JSON:API document
+func jsonapiAuthor(c *fiber.Ctx, author *models.Author) error {
+	s := render.NewSerializer(c.Queries())
+	if err := s.CheckIncludes(); err != nil {
+		return jsonapiError(c, fiber.StatusBadRequest, "Invalid include", err)
+	}
+
+	resource, err := authorResource(c, author)
+	if err != nil {
+		return jsonapiError(c, fiber.StatusInternalServerError, "Failed to serialize author", err)
+	}
+	return sendJSONAPI(c, s.Document(s.Resource(resource)))
+}
+
+// jsonapiAuthors responds with a page of authors as a JSON:API document
+func jsonapiAuthors(c *fiber.Ctx, authors []models.Author, page, limit int, total int64) error {
+	s := render.NewSerializer(c.Queries())
+	if err := s.CheckIncludes(); err != nil {
+		return jsonapiError(c, fiber.StatusBadRequest, "Invalid include", err)
+	}
+
+	resources := make([]render.Resource, len(authors))
+	for i := range authors {
+		resource, err := authorResource(c, &authors[i])
+		if err != nil {
+			return jsonapiError(c, fiber.StatusInternalServerError, "Failed to serialize authors", err)
+		}
+		resources[i] = s.Resource(resource)
+	}
+
+	doc := s.Document(resources)
+	doc.Meta = listMeta(page, limit, total)
+	doc.Links = pageLinks(c, page, limit, total)
+	return sendJSONAPI(c, doc)
+}
+
+// jsonapiCategory responds with a category as a JSON:API document
+func jsonapiCategory(c *fiber.Ctx, category *models.Category) error {
+	s := render.NewSerializer(c.Queries())
+	if err := s.CheckIncludes(); err != nil {
+		return jsonapiError(c, fiber.StatusBadRequest, "Invalid include", err)
+	}
+
+	resource, err := categoryResource(c, category)
+	if err != nil {
+		return jsonapiError(c, fiber.StatusInternalServerError, "Failed to serialize category", err)
+	}
+	return sendJSONAPI(c, s.Document(s.Resource(resource)))
+}
+
+// jsonapiCategories responds with a page of categories as a JSON:API document
+func jsonapiCategories(c *fiber.Ctx, categories []models.Category, page, limit int, total int64) error {
+	s := render.NewSerializer(c.Queries())
+	if err := s.CheckIncludes(); err != nil {
+		return jsonapiError(c, fiber.StatusBadRequest, "Invalid include", err)
+	}
+
+	resources := make([]render.Resource, len(categories))
+	for i := range categories {
+		resource, err := categoryResource(c, &categories[i])
+		if err != nil {
+			return jsonapiError(c, fiber.StatusInternalServerError, "Failed to serialize categories", err)
+		}
+		resources[i] = s.Resource(resource)
+	}
+
+	doc := s.Document(resources)
+	doc.Meta = listMeta(page, limit, total)
+	doc.Links = pageLinks(c, page, limit, total)
+	return sendJSONAPI(c, doc)
+}
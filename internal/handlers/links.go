@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// halMediaType is the media type clients accept to ask for hypermedia links
+const halMediaType = "application/hal+json"
+
+// Route names used to generate links, assigned where the routes are registered
+const (
+	RouteBooksList       = "books.list"
+	RouteBooksGet        = "books.get"
+	RouteBooksByAuthor   = "books.by_author"
+	RouteBooksByCategory = "books.by_category"
+	RouteAuthorsList     = "authors.list"
+	RouteAuthorsGet      = "authors.get"
+	RouteCategoriesList  = "categories.list"
+	RouteCategoriesGet   = "categories.get"
+
+	RouteBooksBySlug      = "books.by_slug"
+	RouteAuthorsBySlug    = "authors.by_slug"
+	RouteCategoriesBySlug = "categories.by_slug"
+)
+
+// link is a hypermedia link
+type link struct {
+	Href string `json:"href"`
+}
+
+// linkedBook is a book with its links
+type linkedBook struct {
+	models.Book
+	Links map[string]link `json:"_links"`
+}
+
+// linkedAuthor is an author with its links
+type linkedAuthor struct {
+	models.Author
+	Links map[string]link `json:"_links"`
+}
+
+// linkedCategory is a category with its links
+type linkedCategory struct {
+	models.Category
+	Links map[string]link `json:"_links"`
+}
+
+// wantLinks reports whether the client asked for _links in the response,
+// with the links query parameter or by accepting application/hal+json
+func wantLinks(c *fiber.Ctx) bool {
+	if value := c.Query("links"); value != "" {
+		enabled, _ := strconv.ParseBool(value)
+		return enabled
+	}
+	return strings.Contains(c.Get(fiber.HeaderAccept), halMediaType)
+}
+
+// routeRef is a named route and the parameters to fill it with
+type routeRef struct {
+	name   string
+	params fiber.Map
+}
+
+// routeLinks builds links from named routes, skipping any whose route is not
+// registered
+func routeLinks(c *fiber.Ctx, routes map[string]routeRef) map[string]link {
+	links := make(map[string]link, len(routes))
+	for rel, route := range routes {
+		href, err := c.GetRouteURL(route.name, route.params)
+		if err != nil || href == "" {
+			continue
+		}
+		links[rel] = link{Href: href}
+	}
+	return links
+}
+
+// linkBook adds links to a book
+func linkBook(c *fiber.Ctx, book *models.Book) linkedBook {
+	return linkedBook{
+		Book: *book,
+		Links: routeLinks(c, map[string]routeRef{
+			"self":     {RouteBooksGet, fiber.Map{"id": book.ID.String()}},
+			"author":   {RouteAuthorsGet, fiber.Map{"id": book.AuthorID.String()}},
+			"category": {RouteCategoriesGet, fiber.Map{"id": book.CategoryID.String()}},
+		}),
+	}
+}
+
+// linkBooks adds links to each book in a listing
+func linkBooks(c *fiber.Ctx, books []models.Book) []linkedBook {
+	linked := make([]linkedBook, len(books))
+	for i := range books {
+		linked[i] = linkBook(c, &books[i])
+	}
+	return linked
+}
+
+// linkAuthor adds links to an author
+func linkAuthor(c *fiber.Ctx, author *models.Author) linkedAuthor {
+	return linkedAuthor{
+		Author: *author,
+		Links: routeLinks(c, map[string]routeRef{
+			"self":  {RouteAuthorsGet, fiber.Map{"id": author.ID.String()}},
+			"books": {RouteBooksByAuthor, fiber.Map{"authorId": author.ID.String()}},
+		}),
+	}
+}
+
+// linkAuthors adds links to each author in a listing
+func linkAuthors(c *fiber.Ctx, authors []models.Author) []linkedAuthor {
+	linked := make([]linkedAuthor, len(authors))
+	for i := range authors {
+		linked[i] = linkAuthor(c, &authors[i])
+	}
+	return linked
+}
+
+// linkCategory adds links to a category
+func linkCategory(c *fiber.Ctx, category *models.Category) linkedCategory {
+	return linkedCategory{
+		Category: *category,
+		Links: routeLinks(c, map[string]routeRef{
+			"self":  {RouteCategoriesGet, fiber.Map{"id": category.ID.String()}},
+			"books": {RouteBooksByCategory, fiber.Map{"categoryId": category.ID.String()}},
+		}),
+	}
+}
+
+// linkCategories adds links to each category in a listing
+func linkCategories(c *fiber.Ctx, categories []models.Category) []linkedCategory {
+	linked := make([]linkedCategory, len(categories))
+	for i := range categories {
+		linked[i] = linkCategory(c, &categories[i])
+	}
+	return linked
+}
+
+// pageLinks returns the self, first, last, and, where they exist, prev and
+// next links of a paginated listing, keeping the request's other query
+// parameters
+func pageLinks(c *fiber.Ctx, page, limit int, total int64) map[string]link {
+	lastPage := int((total + int64(limit) - 1) / int64(limit))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	pageURL := func(p int) link {
+		args := fiber.AcquireArgs()
+		defer fiber.ReleaseArgs(args)
+		c.Request().URI().QueryArgs().CopyTo(args)
+		args.Set("page", strconv.Itoa(p))
+		args.Set("limit", strconv.Itoa(limit))
+		return link{Href: c.Path() + "?" + args.String()}
+	}
+
+	links := map[string]link{
+		"self":  pageURL(page),
+		"first": pageURL(1),
+		"last":  pageURL(lastPage),
+	}
+	if page > 1 {
+		links["prev"] = pageURL(min(page-1, lastPage))
+	}
+	if page < lastPage {
+		links["next"] = pageURL(page + 1)
+	}
+	return links
+}
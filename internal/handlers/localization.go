@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bookstore-api/internal/i18n"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// localizeBooks translates books in place into the request's negotiated
+// language and sets Content-Language. Lookup failures are logged and the
+// stored values are served instead of failing the request.
+func localizeBooks(c *fiber.Ctx, translations *services.TranslationService, books []models.Book) {
+	negotiation := i18n.FromContext(c.UserContext())
+	if negotiation == nil {
+		return
+	}
+
+	locale, err := translations.WithContext(c.UserContext()).LocalizeBooks(books, negotiation.Candidates)
+	if err != nil {
+		log.Printf("Failed to localize books: %v", err)
+	}
+	setContentLanguage(c, negotiation, locale)
+}
+
+// localizeCategories translates categories in place like localizeBooks
+func localizeCategories(c *fiber.Ctx, translations *services.TranslationService, categories []models.Category) {
+	negotiation := i18n.FromContext(c.UserContext())
+	if negotiation == nil {
+		return
+	}
+
+	locale, err := translations.WithContext(c.UserContext()).LocalizeCategories(categories, negotiation.Candidates)
+	if err != nil {
+		log.Printf("Failed to localize categories: %v", err)
+	}
+	setContentLanguage(c, negotiation, locale)
+}
+
+// localizeBook translates a single book like localizeBooks
+func localizeBook(c *fiber.Ctx, translations *services.TranslationService, book *models.Book) {
+	books := []models.Book{*book}
+	localizeBooks(c, translations, books)
+	*book = books[0]
+}
+
+// localizeCategory translates a single category like localizeCategories
+func localizeCategory(c *fiber.Ctx, translations *services.TranslationService, category *models.Category) {
+	categories := []models.Category{*category}
+	localizeCategories(c, translations, categories)
+	*category = categories[0]
+}
+
+// setContentLanguage reports the language served, which is the default locale
+// when no translation applied
+func setContentLanguage(c *fiber.Ctx, negotiation *i18n.Negotiation, locale string) {
+	if locale == "" {
+		locale = negotiation.Default
+	}
+	c.Set(fiber.HeaderContentLanguage, locale)
+}
@@ -0,0 +1,193 @@
+package handlers_test
+
+import (
+	"bookstore-api/internal/testutil"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// env serves the APIs against a migrated database for every test in the
+// package
+var env *testutil.Env
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.Main(m, &env))
+}
+
+var (
+	adminKeyOnce sync.Once
+	adminKey     string
+	adminKeyErr  error
+	// isbns numbers the ISBNs handed out to test books
+	isbns atomic.Int64
+)
+
+// adminToken returns an admin API key for the default tenant, created on
+// first use
+func adminToken(t *testing.T) string {
+	t.Helper()
+	adminKeyOnce.Do(func() {
+		adminKey, adminKeyErr = env.AdminKey()
+	})
+	if adminKeyErr != nil {
+		t.Fatalf("failed to create admin key: %v", adminKeyErr)
+	}
+	return adminKey
+}
+
+// userToken creates a user with role and returns their ID and access token
+func userToken(t *testing.T, role string) (uuid.UUID, string) {
+	t.Helper()
+	id, token, err := env.UserToken(unique(role)+"@example.com", role)
+	if err != nil {
+		t.Fatalf("failed to sign in a %s: %v", role, err)
+	}
+	return id, token
+}
+
+// request sends a REST request to /api/v1 path, failing the test if it cannot
+// be sent or its response cannot be decoded
+func request(t *testing.T, method, path string, body interface{}, token string) *testutil.Response {
+	t.Helper()
+	resp, err := env.Request(method, path, body, token)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+// upload sends body as content of type contentType to /api/v1 path, failing
+// the test if it cannot be sent or its response cannot be decoded
+func upload(t *testing.T, method, path, contentType string, body []byte, token string) *testutil.Response {
+	t.Helper()
+	resp, err := env.Upload(method, path, contentType, body, token)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+// download sends a GET request for path, which is outside /api/v1 unless it
+// says otherwise, and returns the response with its body read, for routes
+// that do not respond with JSON
+func download(t *testing.T, path, token string) (*http.Response, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := env.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	return resp, body
+}
+
+// expectStatus fails the test unless resp has status want
+func expectStatus(t *testing.T, resp *testutil.Response, want int) {
+	t.Helper()
+	if resp.StatusCode != want {
+		t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, resp.Message, want)
+	}
+}
+
+// decode decodes the data of resp into v, failing the test if it cannot
+func decode(t *testing.T, resp *testutil.Response, v interface{}) {
+	t.Helper()
+	if err := resp.Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// record is the part of a created record the tests refer back to
+type record struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// unique returns prefix followed by a random suffix, for names and emails
+// that must not collide with other tests'
+func unique(prefix string) string {
+	return prefix + "-" + uuid.New().String()[:8]
+}
+
+// nextISBN returns an unused valid ISBN-13
+func nextISBN() string {
+	digits := fmt.Sprintf("978%09d", isbns.Add(1))
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	return fmt.Sprintf("%s%d", digits, (10-sum%10)%10)
+}
+
+// createAuthor creates an author and returns its ID
+func createAuthor(t *testing.T) uuid.UUID {
+	t.Helper()
+	name := unique("author")
+	resp := request(t, fiber.MethodPost, "/authors", map[string]interface{}{
+		"name":  name,
+		"email": name + "@example.com",
+	}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var author record
+	decode(t, resp, &author)
+	return author.ID
+}
+
+// createCategory creates a category under parent, or at the top level when
+// parent is uuid.Nil, and returns its ID
+func createCategory(t *testing.T, parent uuid.UUID) uuid.UUID {
+	t.Helper()
+	body := map[string]interface{}{"name": unique("category")}
+	if parent != uuid.Nil {
+		body["parent_id"] = parent.String()
+	}
+	resp := request(t, fiber.MethodPost, "/categories", body, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var category record
+	decode(t, resp, &category)
+	return category.ID
+}
+
+// bookRequest returns the body of a request creating an active book by a new
+// author in a new category
+func bookRequest(t *testing.T) map[string]interface{} {
+	t.Helper()
+	return map[string]interface{}{
+		"title":       unique("book"),
+		"isbn":        nextISBN(),
+		"price":       19.99,
+		"stock":       10,
+		"author_id":   createAuthor(t).String(),
+		"category_id": createCategory(t, uuid.Nil).String(),
+		"status":      "active",
+	}
+}
+
+// createBook creates a book from body and returns its ID
+func createBook(t *testing.T, body map[string]interface{}) uuid.UUID {
+	t.Helper()
+	resp := request(t, fiber.MethodPost, "/books", body, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var book record
+	decode(t, resp, &book)
+	return book.ID
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBookPreviews(t *testing.T) {
+	book := createBook(t, bookRequest(t))
+	path := "/books/" + book.String() + "/preview"
+	excerpt := "Chapter One\n\nIt was a bright cold day in April."
+
+	expectStatus(t, upload(t, fiber.MethodPut, path, "text/plain", []byte(excerpt), adminToken(t)), fiber.StatusOK)
+
+	t.Run("get", func(t *testing.T) {
+		resp, body := download(t, "/api/v1"+path, "")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusOK)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, "text/plain") {
+			t.Fatalf("content type is %q, expected text/plain", got)
+		}
+		if string(body) != excerpt {
+			t.Fatalf("preview is %q, expected %q", body, excerpt)
+		}
+	})
+	t.Run("no preview", func(t *testing.T) {
+		other := createBook(t, bookRequest(t))
+		expectStatus(t, request(t, fiber.MethodGet, "/books/"+other.String()+"/preview", nil, ""), fiber.StatusNotFound)
+	})
+	t.Run("not a document", func(t *testing.T) {
+		resp := upload(t, fiber.MethodPut, path, "application/octet-stream", []byte{0x00, 0x9f, 0xff}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusUnsupportedMediaType)
+	})
+	t.Run("no file", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPut, path, nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("requires owner", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, upload(t, fiber.MethodPut, path, "text/plain", []byte(excerpt), token), fiber.StatusForbidden)
+	})
+}
+
+func TestAuthorPhotos(t *testing.T) {
+	author := createAuthor(t)
+	path := "/authors/" + author.String() + "/photo"
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 120, 160))); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		resp := upload(t, fiber.MethodPut, path, "image/png", photo.Bytes(), adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var updated struct {
+			Photos map[string]string `json:"photos"`
+		}
+		decode(t, resp, &updated)
+		for _, size := range []string{"thumbnail", "medium", "large"} {
+			if updated.Photos[size] == "" {
+				t.Fatalf("photos are %v, expected a %s URL", updated.Photos, size)
+			}
+		}
+	})
+	t.Run("not an image", func(t *testing.T) {
+		resp := upload(t, fiber.MethodPut, path, "image/png", []byte("not a png"), adminToken(t))
+		expectStatus(t, resp, fiber.StatusUnsupportedMediaType)
+	})
+	t.Run("no photo", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPut, path, nil, adminToken(t)), fiber.StatusBadRequest)
+	})
+	t.Run("requires owner", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, upload(t, fiber.MethodPut, path, "image/png", photo.Bytes(), token), fiber.StatusForbidden)
+	})
+}
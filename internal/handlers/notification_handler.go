@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NotificationHandler handles the current user's notification channel
+// settings
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(svc *services.Services) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: svc.Notifications,
+	}
+}
+
+// UpdateNotificationSettingsRequest represents the request payload for
+// updating notification settings. channels maps topics to the channels they
+// are sent over; omitted topics and fields are left unchanged, and empty
+// strings remove contact details.
+type UpdateNotificationSettingsRequest struct {
+	Channels        map[string][]string `json:"channels,omitempty"`
+	Phone           *string             `json:"phone,omitempty"`
+	PushTokens      []string            `json:"push_tokens,omitempty"`
+	SlackWebhookURL *string             `json:"slack_webhook_url,omitempty"`
+}
+
+// GetNotificationSettings returns the current user's notification settings
+func (h *NotificationHandler) GetNotificationSettings(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	settings, err := h.notificationService.WithContext(c.UserContext()).GetSettings(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get notification settings", err)
+	}
+
+	return response.OK(c, "Notification settings retrieved successfully", settings)
+}
+
+// UpdateNotificationSettings changes the channels the current user receives
+// each topic over and their contact details
+func (h *NotificationHandler) UpdateNotificationSettings(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	var req UpdateNotificationSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	settings, err := h.notificationService.WithContext(c.UserContext()).UpdateSettings(userID, services.NotificationSettingsUpdate{
+		Channels:        req.Channels,
+		Phone:           req.Phone,
+		PushTokens:      req.PushTokens,
+		SlackWebhookURL: req.SlackWebhookURL,
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid notification settings: ") {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid notification settings: "))
+		}
+		if err.Error() == "user not found" {
+			return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update notification settings", err)
+	}
+
+	return response.OK(c, "Notification settings updated successfully", settings)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bookstore-api/internal/operations"
+	"bookstore-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OperationHandler handles the long-running operations of imports and exports
+type OperationHandler struct {
+	manager *operations.Manager
+}
+
+// NewOperationHandler creates a new operation handler
+func NewOperationHandler() *OperationHandler {
+	return &OperationHandler{manager: operations.GetManager()}
+}
+
+// GetOperation returns an operation with its status, progress percentage, and,
+// once done, its result and links or its error
+func (h *OperationHandler) GetOperation(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "operation")
+	if !ok {
+		return err
+	}
+
+	operation, err := h.manager.GetOperation(c.UserContext(), id)
+	if err != nil {
+		if err.Error() == "operation not found" {
+			return response.Error(c, fiber.StatusNotFound, "Operation not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get operation", err)
+	}
+
+	return response.OK(c, "Operation retrieved successfully", operation)
+}
+
+// DownloadOperation returns the CSV file written by a completed export
+func (h *OperationHandler) DownloadOperation(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "operation")
+	if !ok {
+		return err
+	}
+
+	file, filename, err := h.manager.OpenExport(c.UserContext(), id)
+	if err != nil {
+		switch err.Error() {
+		case "operation not found":
+			return response.Error(c, fiber.StatusNotFound, "Operation not found", nil)
+		case "operation has no download":
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Operation has no download", "only completed exports can be downloaded")
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to download operation result", err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.SendStream(file)
+}
+
+// acceptedOperation responds 202 Accepted with an operation that was started,
+// pointing the Location header at it
+func acceptedOperation(c *fiber.Ctx, message string, operation *operations.Operation) error {
+	c.Location(operation.Links["self"])
+	return response.Accepted(c, message, operation)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// uuidParamKey is the locals key a parsed UUID route parameter is kept under
+func uuidParamKey(name string) string {
+	return "uuid_param:" + name
+}
+
+// parseUUIDParam parses the route parameter name as a UUID, keeping it in the
+// request's locals so the owner check and the handler of a route parse it
+// once. When it is not a UUID, the 400 response naming resource has been sent
+// and ok is false.
+func parseUUIDParam(c *fiber.Ctx, name, resource string) (uuid.UUID, bool, error) {
+	key := uuidParamKey(name)
+	if id, ok := c.Locals(key).(uuid.UUID); ok {
+		return id, true, nil
+	}
+
+	id, err := uuid.Parse(c.Params(name))
+	if err != nil {
+		return uuid.Nil, false, response.Error(c, fiber.StatusBadRequest, "Invalid "+resource+" ID", err)
+	}
+	c.Locals(key, id)
+	return id, true, nil
+}
+
+// uuidParam returns the route parameter name as a UUID, or false when it is
+// not one, for checks that leave the response to the handler
+func uuidParam(c *fiber.Ctx, name string) (uuid.UUID, bool) {
+	key := uuidParamKey(name)
+	if id, ok := c.Locals(key).(uuid.UUID); ok {
+		return id, true
+	}
+
+	id, err := uuid.Parse(c.Params(name))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	c.Locals(key, id)
+	return id, true
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PreferenceHandler handles the current user's marketing preferences and consents
+type PreferenceHandler struct {
+	preferenceService *services.PreferenceService
+}
+
+// NewPreferenceHandler creates a new preference handler
+func NewPreferenceHandler(svc *services.Services) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferenceService: svc.Preferences,
+	}
+}
+
+// UpdatePreferencesRequest represents the request payload for updating
+// preferences. Omitted consents are left unchanged; policy_version defaults
+// to the current privacy policy.
+type UpdatePreferencesRequest struct {
+	EmailMarketing *bool  `json:"email_marketing,omitempty"`
+	DataProcessing *bool  `json:"data_processing,omitempty"`
+	PolicyVersion  string `json:"policy_version,omitempty"`
+}
+
+// GetPreferences returns the current user's preferences
+func (h *PreferenceHandler) GetPreferences(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	preferences, err := h.preferenceService.WithContext(c.UserContext()).GetPreferences(userID)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get preferences", err)
+	}
+
+	return response.OK(c, "Preferences retrieved successfully", preferences)
+}
+
+// UpdatePreferences gives or withdraws the current user's consents
+func (h *PreferenceHandler) UpdatePreferences(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	preferences, err := h.preferenceService.WithContext(c.UserContext()).UpdatePreferences(userID, services.PreferenceUpdate{
+		EmailMarketing: req.EmailMarketing,
+		DataProcessing: req.DataProcessing,
+		PolicyVersion:  req.PolicyVersion,
+		IPAddress:      c.IP(),
+		UserAgent:      c.Get(fiber.HeaderUserAgent),
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid preferences: ") {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid preferences: "))
+		}
+		if err.Error() == "user not found" {
+			return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update preferences", err)
+	}
+
+	return response.OK(c, "Preferences updated successfully", preferences)
+}
+
+// GetConsentHistory lists the consents the current user gave and withdrew
+func (h *PreferenceHandler) GetConsentHistory(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	records, total, err := h.preferenceService.WithContext(c.UserContext()).GetConsentHistory(userID, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get consent history", err)
+	}
+
+	return response.Paginated(c, "Consent history retrieved successfully", records, page, limit, total)
+}
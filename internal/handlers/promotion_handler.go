@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// PromotionHandler handles promotion HTTP requests
+type PromotionHandler struct {
+	promotionService *services.PromotionService
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(svc *services.Services) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: svc.Promotions,
+	}
+}
+
+// CreatePromotionRequest represents the request payload for creating a
+// promotion. Exactly one of book_id or category_id and one of price or
+// discount_percent must be set; a fixed price only applies to a book.
+type CreatePromotionRequest struct {
+	Name            string     `json:"name" validate:"required,min=1,max=255"`
+	BookID          *uuid.UUID `json:"book_id,omitempty"`
+	CategoryID      *uuid.UUID `json:"category_id,omitempty"`
+	Price           *float64   `json:"price,omitempty" validate:"omitempty,min=0"`
+	DiscountPercent *float64   `json:"discount_percent,omitempty" validate:"omitempty,gt=0,lt=100"`
+	StartsAt        time.Time  `json:"starts_at" validate:"required"`
+	EndsAt          time.Time  `json:"ends_at" validate:"required"`
+}
+
+// UpdatePromotionRequest represents the request payload for updating a
+// promotion. Setting price or discount_percent replaces the other.
+type UpdatePromotionRequest struct {
+	Name            string     `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Price           *float64   `json:"price,omitempty" validate:"omitempty,min=0"`
+	DiscountPercent *float64   `json:"discount_percent,omitempty" validate:"omitempty,gt=0,lt=100"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+}
+
+// CreatePromotion creates a new promotion
+func (h *PromotionHandler) CreatePromotion(c *fiber.Ctx) error {
+	var req CreatePromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	promotion := &models.Promotion{
+		Name:            req.Name,
+		BookID:          req.BookID,
+		CategoryID:      req.CategoryID,
+		Price:           req.Price,
+		DiscountPercent: req.DiscountPercent,
+		StartsAt:        req.StartsAt,
+		EndsAt:          req.EndsAt,
+	}
+
+	if err := h.promotionService.WithContext(c.UserContext()).CreatePromotion(promotion); err != nil {
+		return promotionError(c, "Failed to create promotion", err)
+	}
+
+	return response.Created(c, "Promotion created successfully", promotion)
+}
+
+// GetPromotion retrieves a promotion by ID
+func (h *PromotionHandler) GetPromotion(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "promotion")
+	if !ok {
+		return err
+	}
+
+	promotion, err := h.promotionService.WithContext(c.UserContext()).GetPromotionByID(id)
+	if err != nil {
+		return promotionError(c, "Failed to get promotion", err)
+	}
+
+	return response.OK(c, "Promotion retrieved successfully", promotion)
+}
+
+// GetAllPromotions retrieves all promotions with pagination. Pass active=true
+// for only the promotions currently applied.
+func (h *PromotionHandler) GetAllPromotions(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	promotions, total, err := h.promotionService.WithContext(c.UserContext()).GetAllPromotions(page, limit, c.QueryBool("active"))
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get promotions", err)
+	}
+
+	return response.Paginated(c, "Promotions retrieved successfully", promotions, page, limit, total)
+}
+
+// UpdatePromotion updates an existing promotion
+func (h *PromotionHandler) UpdatePromotion(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "promotion")
+	if !ok {
+		return err
+	}
+
+	var req UpdatePromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	if req.Price != nil && req.DiscountPercent != nil {
+		return validationFailed(c, utils.ValidationErrors{{
+			Field:   "price",
+			Rule:    "excluded_with",
+			Message: "only one of price or discount_percent may be set",
+			Param:   "discount_percent",
+		}})
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+		updates["discount_percent"] = nil
+	}
+	if req.DiscountPercent != nil {
+		updates["discount_percent"] = *req.DiscountPercent
+		updates["price"] = nil
+	}
+	if req.StartsAt != nil {
+		updates["starts_at"] = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		updates["ends_at"] = *req.EndsAt
+	}
+	if len(updates) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+	}
+
+	promotion, err := h.promotionService.WithContext(c.UserContext()).UpdatePromotion(id, updates)
+	if err != nil {
+		return promotionError(c, "Failed to update promotion", err)
+	}
+
+	return response.OK(c, "Promotion updated successfully", promotion)
+}
+
+// DeletePromotion deletes a promotion
+func (h *PromotionHandler) DeletePromotion(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "promotion")
+	if !ok {
+		return err
+	}
+
+	if err := h.promotionService.WithContext(c.UserContext()).DeletePromotion(id); err != nil {
+		return promotionError(c, "Failed to delete promotion", err)
+	}
+
+	return response.OK(c, "Promotion deleted successfully", nil)
+}
+
+// promotionError responds to a promotion service error, mapping invalid
+// promotions to 400 and a missing promotion or target to 404
+func promotionError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid promotion: "):
+		return validationFailed(c, err)
+	case err.Error() == "promotion not found":
+		return response.Error(c, fiber.StatusNotFound, "Promotion not found", nil)
+	case err.Error() == "book not found":
+		return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+	case err.Error() == "category not found":
+		return response.Error(c, fiber.StatusNotFound, "Category not found", nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, message, err)
+}
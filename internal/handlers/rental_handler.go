@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxAvailabilityDays bounds the availability calendar a single request may cover
+const maxAvailabilityDays = 180
+
+// RentalHandler handles book rental HTTP requests
+type RentalHandler struct {
+	rentalService *services.RentalService
+	defaultPeriod time.Duration
+}
+
+// NewRentalHandler creates a new rental handler
+func NewRentalHandler(cfg *config.Config, svc *services.Services) *RentalHandler {
+	return &RentalHandler{
+		rentalService: svc.Rentals,
+		defaultPeriod: cfg.Rentals.DefaultPeriod,
+	}
+}
+
+// RentBookRequest represents the request payload for renting a book. The
+// rental starts now and lasts the default period unless given. Only admins may
+// rent on behalf of another user.
+type RentBookRequest struct {
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// RentBook lends a copy of the book in the :id route parameter
+func (h *RentalHandler) RentBook(c *fiber.Ctx) error {
+	bookID, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	var req RentBookRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+		}
+	}
+
+	userID, ok := currentUserID(c)
+	if req.UserID != nil && *req.UserID != userID {
+		if !isAdmin(c) {
+			return response.Error(c, fiber.StatusForbidden, "Insufficient permissions", nil)
+		}
+		userID, ok = *req.UserID, true
+	}
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	dueAt := startsAt.Add(h.defaultPeriod)
+	if req.DueAt != nil {
+		dueAt = *req.DueAt
+	}
+
+	rental, err := h.rentalService.WithContext(c.UserContext()).RentBook(bookID, userID, startsAt, dueAt)
+	if err != nil {
+		return rentalError(c, "Failed to rent book", err)
+	}
+
+	return response.Created(c, "Book rented successfully", rental)
+}
+
+// GetAvailability returns the number of copies of the book in the :id route
+// parameter free on each day from the from date through the to date
+// (YYYY-MM-DD, default the next 30 days)
+func (h *RentalHandler) GetAvailability(c *fiber.Ctx) error {
+	bookID, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid from date", "use YYYY-MM-DD")
+		}
+	}
+	to := from.AddDate(0, 0, 30)
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid to date", "use YYYY-MM-DD")
+		}
+		// The to date is included
+		to = to.AddDate(0, 0, 1)
+	}
+	if !to.After(from) || to.Sub(from) > maxAvailabilityDays*24*time.Hour {
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid availability period", "to must not be before from, and the period may cover at most 180 days")
+	}
+
+	availability, err := h.rentalService.WithContext(c.UserContext()).GetAvailability(bookID, from, to)
+	if err != nil {
+		return rentalError(c, "Failed to get availability", err)
+	}
+
+	return response.OK(c, "Availability retrieved successfully", availability)
+}
+
+// GetRentals lists rentals with pagination. Admins see every rental and may
+// filter by user_id; other users see only their own. Both may filter by
+// book_id and status (out, overdue, returned).
+func (h *RentalHandler) GetRentals(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+	filter := services.RentalFilter{Status: c.Query("status")}
+
+	for _, param := range []struct {
+		name   string
+		target **uuid.UUID
+	}{{"book_id", &filter.BookID}, {"user_id", &filter.UserID}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid "+param.name, err)
+		}
+		*param.target = &id
+	}
+
+	if !isAdmin(c) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return notUserTokenResponse(c)
+		}
+		filter.UserID = &userID
+	}
+
+	rentals, total, err := h.rentalService.WithContext(c.UserContext()).GetRentals(filter, page, limit)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid rental status") {
+			return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid status", "status must be out, overdue, or returned")
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get rentals", err)
+	}
+
+	return response.Paginated(c, "Rentals retrieved successfully", rentals, page, limit, total)
+}
+
+// GetRental retrieves a rental by ID or short code
+func (h *RentalHandler) GetRental(c *fiber.Ctx) error {
+	rentalService := h.rentalService.WithContext(c.UserContext())
+	id, err := rentalService.ResolveRentalID(c.Params("id"))
+	if err != nil {
+		return rentalError(c, "Failed to get rental", err)
+	}
+
+	rental, err := rentalService.GetRentalByID(id)
+	if err != nil {
+		return rentalError(c, "Failed to get rental", err)
+	}
+
+	return response.OK(c, "Rental retrieved successfully", rental)
+}
+
+// ReturnRental records the return of a rented copy, given by ID or short code
+func (h *RentalHandler) ReturnRental(c *fiber.Ctx) error {
+	rentalService := h.rentalService.WithContext(c.UserContext())
+	id, err := rentalService.ResolveRentalID(c.Params("id"))
+	if err != nil {
+		return rentalError(c, "Failed to return rental", err)
+	}
+
+	rental, err := rentalService.ReturnRental(id)
+	if err != nil {
+		return rentalError(c, "Failed to return rental", err)
+	}
+
+	return response.OK(c, "Rental returned successfully", rental)
+}
+
+// OwnsRental reports whether the rental in the :id route parameter, an ID or
+// short code, was made for the user
+func (h *RentalHandler) OwnsRental(c *fiber.Ctx, userID uuid.UUID) (bool, error) {
+	rentalService := h.rentalService.WithContext(c.UserContext())
+	id, err := rentalService.ResolveRentalID(c.Params("id"))
+	if err != nil {
+		if err.Error() == "invalid ID or short code" || err.Error() == "rental not found" {
+			return false, nil
+		}
+		return false, err
+	}
+	return rentalService.IsRentalOwner(id, userID)
+}
+
+// rentalError responds to a rental service error, mapping invalid periods and
+// IDs to 400, missing records to 404, and unavailable copies to 409
+func rentalError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid rental: "):
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid rental: "))
+	case err.Error() == "book not found":
+		return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+	case err.Error() == "user not found":
+		return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+	case err.Error() == "invalid ID or short code":
+		return response.Error(c, fiber.StatusBadRequest, "Invalid rental ID", err)
+	case err.Error() == "rental not found":
+		return response.Error(c, fiber.StatusNotFound, "Rental not found", nil)
+	case err.Error() == "no copies available for the requested period", err.Error() == "rental already returned":
+		return response.Error(c, fiber.StatusConflict, strings.ToUpper(err.Error()[:1])+err.Error()[1:], nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/reports"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/scheduler"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"bookstore-api/internal/utils/signing"
+	"bytes"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReportHandler handles scheduled report HTTP requests
+type ReportHandler struct {
+	reportService *services.ReportService
+	queue         *jobs.Queue
+	signer        *signing.Signer
+	lowStock      int
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(cfg *config.Config, svc *services.Services) *ReportHandler {
+	return &ReportHandler{
+		reportService: svc.Reports,
+		queue:         jobs.GetQueue(),
+		signer:        signing.GetSigner(),
+		lowStock:      cfg.Scheduler.LowStockThreshold,
+	}
+}
+
+// CreateReportRequest represents the request payload for creating a report definition
+type CreateReportRequest struct {
+	Name       string   `json:"name" validate:"required,min=1,max=255"`
+	ReportType string   `json:"report_type" validate:"required,oneof=inventory low_stock new_titles catalog_summary top_rentals"`
+	Format     string   `json:"format,omitempty" validate:"omitempty,oneof=csv pdf"`
+	Period     string   `json:"period,omitempty" validate:"omitempty,oneof=week month"`
+	Schedule   string   `json:"schedule" validate:"required,max=100"`
+	Recipients []string `json:"recipients" validate:"required,min=1,dive,email"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateReportRequest represents the request payload for updating a report definition
+type UpdateReportRequest struct {
+	Name       string   `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	ReportType string   `json:"report_type,omitempty" validate:"omitempty,oneof=inventory low_stock new_titles catalog_summary top_rentals"`
+	Format     string   `json:"format,omitempty" validate:"omitempty,oneof=csv pdf"`
+	Period     string   `json:"period,omitempty" validate:"omitempty,oneof=week month"`
+	Schedule   string   `json:"schedule,omitempty" validate:"omitempty,max=100"`
+	Recipients []string `json:"recipients,omitempty" validate:"omitempty,dive,email"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+// RunReportRequest represents the optional request payload for an ad-hoc report run
+type RunReportRequest struct {
+	Recipients []string `json:"recipients,omitempty" validate:"omitempty,dive,email"`
+}
+
+// CreateReport creates a new report definition
+func (h *ReportHandler) CreateReport(c *fiber.Ctx) error {
+	var req CreateReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	nextRun, err := scheduler.NextRun(req.Schedule, time.Now())
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid schedule", err)
+	}
+
+	report := &models.ReportDefinition{
+		Name:       req.Name,
+		ReportType: req.ReportType,
+		Format:     req.Format,
+		Period:     req.Period,
+		Schedule:   req.Schedule,
+		Recipients: models.StringList(req.Recipients),
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		NextRunAt:  &nextRun,
+	}
+	if report.Format == "" {
+		report.Format = models.ReportFormatCSV
+	}
+	if report.Period == "" {
+		report.Period = models.ReportPeriodWeek
+	}
+
+	if err := h.reportService.WithContext(c.UserContext()).CreateReport(report); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create report", err)
+	}
+
+	return response.Created(c, "Report created successfully", report)
+}
+
+// GetReport retrieves a report definition by ID
+func (h *ReportHandler) GetReport(c *fiber.Ctx) error {
+	report, ok, err := h.findReport(c)
+	if !ok {
+		return err
+	}
+
+	return response.OK(c, "Report retrieved successfully", report)
+}
+
+// GetAllReports retrieves all report definitions with pagination
+func (h *ReportHandler) GetAllReports(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	reportList, total, err := h.reportService.WithContext(c.UserContext()).GetAllReports(page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get reports", err)
+	}
+
+	return response.Paginated(c, "Reports retrieved successfully", reportList, page, limit, total)
+}
+
+// UpdateReport updates a report definition. Changing the schedule or
+// re-enabling a report recomputes its next run.
+func (h *ReportHandler) UpdateReport(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "report")
+	if !ok {
+		return err
+	}
+
+	var req UpdateReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.ReportType != "" {
+		updates["report_type"] = req.ReportType
+	}
+	if req.Format != "" {
+		updates["format"] = req.Format
+	}
+	if req.Period != "" {
+		updates["period"] = req.Period
+	}
+	if req.Recipients != nil {
+		updates["recipients"] = models.StringList(req.Recipients)
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+	if req.Schedule != "" || (req.Enabled != nil && *req.Enabled) {
+		schedule := req.Schedule
+		if schedule == "" {
+			current, err := h.reportService.WithContext(c.UserContext()).GetReportByID(id)
+			if err != nil {
+				if err.Error() == "report not found" {
+					return response.Error(c, fiber.StatusNotFound, "Report not found", nil)
+				}
+				return response.Error(c, fiber.StatusInternalServerError, "Failed to get report", err)
+			}
+			schedule = current.Schedule
+		}
+		nextRun, err := scheduler.NextRun(schedule, time.Now())
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid schedule", err)
+		}
+		updates["schedule"] = schedule
+		updates["next_run_at"] = nextRun
+	}
+	if len(updates) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+	}
+
+	if err := h.reportService.WithContext(c.UserContext()).UpdateReport(id, updates); err != nil {
+		if err.Error() == "report not found" {
+			return response.Error(c, fiber.StatusNotFound, "Report not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update report", err)
+	}
+
+	return response.OK(c, "Report updated successfully", nil)
+}
+
+// DeleteReport deletes a report definition
+func (h *ReportHandler) DeleteReport(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "report")
+	if !ok {
+		return err
+	}
+
+	if err := h.reportService.WithContext(c.UserContext()).DeleteReport(id); err != nil {
+		if err.Error() == "report not found" {
+			return response.Error(c, fiber.StatusNotFound, "Report not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete report", err)
+	}
+
+	return response.OK(c, "Report deleted successfully", nil)
+}
+
+// RunReport enqueues an ad-hoc run of a report, optionally to other recipients
+func (h *ReportHandler) RunReport(c *fiber.Ctx) error {
+	report, ok, err := h.findReport(c)
+	if !ok {
+		return err
+	}
+
+	var req RunReportRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+		}
+		if err := utils.ValidateStruct(req); err != nil {
+			return validationFailed(c, err)
+		}
+	}
+
+	job, err := h.queue.EnqueueContext(c.UserContext(), reports.JobType, reports.Payload{
+		ReportID:   report.ID,
+		Recipients: req.Recipients,
+		RunAt:      time.Now(),
+	})
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to schedule report", err)
+	}
+
+	return response.Accepted(c, "Report generation scheduled", job)
+}
+
+// DownloadReport generates a report for its most recent period and returns it
+// in its format
+func (h *ReportHandler) DownloadReport(c *fiber.Ctx) error {
+	report, ok, err := h.findReport(c)
+	if !ok {
+		return err
+	}
+
+	var buf bytes.Buffer
+	from, to := services.ReportRange(report.Period, time.Now())
+	if err := h.reportService.WithContext(c.UserContext()).WriteReport(report, from, to, tenancy.FromContext(c.UserContext()).LowStockThresholdOr(h.lowStock), &buf); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to generate report", err)
+	}
+
+	c.Set(fiber.HeaderContentType, reports.ContentType(report))
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+reports.Filename(report, from, to)+`"`)
+	return c.Send(buf.Bytes())
+}
+
+// CreateDownloadLink returns a signed link to download a report's current
+// run without an access token, for sharing with recipients who are not
+// administrators. The link expires after SIGNED_URL_TTL.
+func (h *ReportHandler) CreateDownloadLink(c *fiber.Ctx) error {
+	report, ok, err := h.findReport(c)
+	if !ok {
+		return err
+	}
+
+	link := "/api/v1/reports/" + report.ID.String() + "/download"
+	if tenantID, ok := tenancy.TenantID(c.UserContext()); ok {
+		link += "?" + url.Values{signing.TenantParam: {tenantID.String()}}.Encode()
+	}
+	signed, expiresAt, err := h.signer.Sign(fiber.MethodGet, link)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to sign download link", err)
+	}
+
+	return response.Created(c, "Download link created successfully", fiber.Map{
+		"url":        c.BaseURL() + signed,
+		"expires_at": expiresAt,
+	})
+}
+
+// findReport loads the report in the path, writing an error response when it
+// cannot. The returned bool is false when a response has been written.
+func (h *ReportHandler) findReport(c *fiber.Ctx) (*models.ReportDefinition, bool, error) {
+	id, ok, err := parseUUIDParam(c, "id", "report")
+	if !ok {
+		return nil, false, err
+	}
+
+	report, err := h.reportService.WithContext(c.UserContext()).GetReportByID(id)
+	if err != nil {
+		if err.Error() == "report not found" {
+			return nil, false, response.Error(c, fiber.StatusNotFound, "Report not found", nil)
+		}
+		return nil, false, response.Error(c, fiber.StatusInternalServerError, "Failed to get report", err)
+	}
+	return report, true, nil
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RevisionHandler handles book revisions proposed by editors and their
+// review by admins
+type RevisionHandler struct {
+	revisionService *services.RevisionService
+}
+
+// NewRevisionHandler creates a new revision handler
+func NewRevisionHandler(svc *services.Services) *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: svc.Revisions,
+	}
+}
+
+// ReviewRevisionRequest represents the request payload for approving or
+// rejecting a revision. Force approves a revision even though the fields it
+// changes were changed since it was submitted.
+type ReviewRevisionRequest struct {
+	Note  string `json:"note,omitempty" validate:"max=1000"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// RevertRevisionRequest represents the request payload for reverting a
+// revision. Force reverts the fields even though they were changed since.
+type RevertRevisionRequest struct {
+	Force bool `json:"force,omitempty"`
+}
+
+// GetRevisions lists revisions for admins, oldest first, filtered by status
+// and book
+func (h *RevisionHandler) GetRevisions(c *fiber.Ctx) error {
+	filter, ok, err := parseRevisionFilter(c)
+	if !ok {
+		return err
+	}
+	if value := c.Query("book_id"); value != "" {
+		bookID, err := uuid.Parse(value)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid book ID", err)
+		}
+		filter.BookID = bookID
+	}
+	return h.respondRevisions(c, filter)
+}
+
+// GetMyRevisions lists the revisions the current user submitted
+func (h *RevisionHandler) GetMyRevisions(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+	filter, ok, err := parseRevisionFilter(c)
+	if !ok {
+		return err
+	}
+	filter.SubmittedBy = userID
+	return h.respondRevisions(c, filter)
+}
+
+// GetRevision returns a revision with its book as it is now
+func (h *RevisionHandler) GetRevision(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "revision")
+	if !ok {
+		return err
+	}
+
+	revision, err := h.revisionService.WithContext(c.UserContext()).GetRevisionByID(id)
+	if err != nil {
+		if err.Error() == "revision not found" {
+			return response.Error(c, fiber.StatusNotFound, "Revision not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get revision", err)
+	}
+
+	return response.OK(c, "Revision retrieved successfully", revision)
+}
+
+// ApproveRevision applies a pending revision to the live catalog
+func (h *RevisionHandler) ApproveRevision(c *fiber.Ctx) error {
+	return h.review(c, true)
+}
+
+// RejectRevision rejects a pending revision, leaving the book unchanged
+func (h *RevisionHandler) RejectRevision(c *fiber.Ctx) error {
+	return h.review(c, false)
+}
+
+// review approves or rejects the revision in the path
+func (h *RevisionHandler) review(c *fiber.Ctx, approve bool) error {
+	id, ok, err := parseUUIDParam(c, "id", "revision")
+	if !ok {
+		return err
+	}
+
+	var req ReviewRevisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+		}
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	// Admin API keys belong to no user, so their reviews record no reviewer
+	var reviewerID *uuid.UUID
+	if userID, ok := currentUserID(c); ok {
+		reviewerID = &userID
+	}
+
+	revisionService := h.revisionService.WithContext(c.UserContext())
+	var revision *models.BookRevision
+	message := "Revision approved and applied"
+	if approve {
+		revision, err = revisionService.ApproveRevision(id, reviewerID, req.Note, req.Force)
+	} else {
+		revision, err = revisionService.RejectRevision(id, reviewerID, req.Note)
+		message = "Revision rejected"
+	}
+	if err != nil {
+		switch {
+		case err.Error() == "revision not found":
+			return response.Error(c, fiber.StatusNotFound, "Revision not found", nil)
+		case err.Error() == "book not found":
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		case err.Error() == "revision is not pending":
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Revision is not pending", "only pending revisions can be approved or rejected")
+		case strings.HasPrefix(err.Error(), "revision conflicts with later changes"):
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Revision conflicts with later changes to the book", err.Error()+"; review the book and approve with force to apply it anyway")
+		}
+		if resp, status := isbnErrorResponse(err); status != 0 {
+			return response.Fail(c, status, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to review revision", err)
+	}
+
+	return response.OK(c, message, revision)
+}
+
+// GetBookRevisions lists the history of a book, newest first: every change
+// applied to it and the revisions waiting for review
+func (h *RevisionHandler) GetBookRevisions(c *fiber.Ctx) error {
+	bookID, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+	filter, ok, err := parseRevisionFilter(c)
+	if !ok {
+		return err
+	}
+	filter.BookID = bookID
+	filter.NewestFirst = true
+	return h.respondRevisions(c, filter)
+}
+
+// RevertRevision undoes an approved revision of a book, recording the revert
+// as a new revision
+func (h *RevisionHandler) RevertRevision(c *fiber.Ctx) error {
+	bookID, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+	revisionID, ok, err := parseUUIDParam(c, "revisionId", "revision")
+	if !ok {
+		return err
+	}
+
+	var req RevertRevisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+		}
+	}
+
+	revision, err := h.revisionService.WithContext(c.UserContext()).RevertRevision(bookID, revisionID, req.Force)
+	if err != nil {
+		switch {
+		case err.Error() == "revision not found":
+			return response.Error(c, fiber.StatusNotFound, "Revision not found", nil)
+		case err.Error() == "book not found":
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		case err.Error() == "revision is not approved":
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Revision is not approved", "only approved revisions can be reverted")
+		case strings.HasPrefix(err.Error(), "revision conflicts with later changes"):
+			return response.ErrorWithDetails(c, fiber.StatusConflict, "Revision conflicts with later changes to the book", err.Error()+"; review the book and revert with force to revert it anyway")
+		case strings.HasPrefix(err.Error(), "invalid "):
+			return response.Error(c, fiber.StatusBadRequest, "Revision cannot be reverted", err)
+		}
+		if resp, status := isbnErrorResponse(err); status != 0 {
+			return response.Fail(c, status, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to revert revision", err)
+	}
+
+	return response.OK(c, "Revision reverted", revision)
+}
+
+// respondRevisions responds with the page of revisions matching filter
+func (h *RevisionHandler) respondRevisions(c *fiber.Ctx, filter services.RevisionFilter) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	revisions, total, err := h.revisionService.WithContext(c.UserContext()).GetRevisions(filter, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get revisions", err)
+	}
+
+	return response.Paginated(c, "Revisions retrieved successfully", revisions, page, limit, total)
+}
+
+// parseRevisionFilter reads the status filter of a revision listing,
+// writing an error response when it is invalid. The returned bool is false
+// when a response has been written.
+func parseRevisionFilter(c *fiber.Ctx) (services.RevisionFilter, bool, error) {
+	status := c.Query("status")
+	switch status {
+	case "", models.RevisionStatusPending, models.RevisionStatusApproved, models.RevisionStatusRejected:
+		return services.RevisionFilter{Status: status}, true, nil
+	}
+	return services.RevisionFilter{}, false, response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid status", "status must be pending, approved, or rejected")
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SearchHandler handles searches across books, authors, and categories
+type SearchHandler struct {
+	searchService      *services.SearchService
+	translationService *services.TranslationService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(svc *services.Services) *SearchHandler {
+	return &SearchHandler{
+		searchService:      svc.Search,
+		translationService: svc.Translations,
+	}
+}
+
+// Search searches books, authors, and categories in one call and returns the
+// ranked matches grouped by entity. page and limit apply to every group;
+// books_page, authors_page, and categories_page page through one group.
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	query, ok, err := parseSearchQuery(c)
+	if !ok {
+		return err
+	}
+
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+	groupPage := func(name string) services.SearchPage {
+		if p := c.QueryInt(name+"_page", page); p > 0 {
+			return services.SearchPage{Page: p, Limit: limit}
+		}
+		return services.SearchPage{Page: page, Limit: limit}
+	}
+	opts := services.SearchOptions{
+		Books:      groupPage("books"),
+		Authors:    groupPage("authors"),
+		Categories: groupPage("categories"),
+	}
+
+	results, err := h.searchService.WithContext(c.UserContext()).Search(query, opts)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to search", err)
+	}
+
+	localizeBooks(c, h.translationService, results.Books)
+	localizeCategories(c, h.translationService, results.Categories)
+	var books, authors, categories interface{} = results.Books, results.Authors, results.Categories
+	if wantLinks(c) {
+		books = linkBooks(c, results.Books)
+		authors = linkAuthors(c, results.Authors)
+		categories = linkCategories(c, results.Categories)
+	}
+
+	return response.OK(c, "Search completed successfully", fiber.Map{
+		"books":      searchGroup(books, opts.Books, results.BooksTotal),
+		"authors":    searchGroup(authors, opts.Authors, results.AuthorsTotal),
+		"categories": searchGroup(categories, opts.Categories, results.CategoriesTotal),
+	})
+}
+
+// parseSearchQuery parses the q query parameter. When it is missing or too
+// long the 400 response has been sent and ok is false.
+func parseSearchQuery(c *fiber.Ctx) (services.SearchQuery, bool, error) {
+	query, err := services.ParseSearchQuery(c.Query("q"))
+	if err != nil {
+		return query, false, response.Error(c, fiber.StatusBadRequest, "Invalid search query", err)
+	}
+	if query.Text == "" {
+		return query, false, response.Error(c, fiber.StatusBadRequest, "Search query is required", nil)
+	}
+	return query, true, nil
+}
+
+// searchGroup formats one group of search results with its pagination
+func searchGroup(items interface{}, page services.SearchPage, total int64) fiber.Map {
+	return fiber.Map{
+		"items": items,
+		"pagination": fiber.Map{
+			"page":        page.Page,
+			"limit":       page.Limit,
+			"total":       total,
+			"total_pages": (total + int64(page.Limit) - 1) / int64(page.Limit),
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SecurityHandler handles security event log HTTP requests
+type SecurityHandler struct {
+	securityService *services.SecurityService
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(svc *services.Services) *SecurityHandler {
+	return &SecurityHandler{
+		securityService: svc.Security,
+	}
+}
+
+// GetSecurityEvents lists failed logins, lockouts, and other security events,
+// optionally filtered by the type and user_id query parameters
+func (h *SecurityHandler) GetSecurityEvents(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	var userID *uuid.UUID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", err)
+		}
+		userID = &id
+	}
+
+	events, total, err := h.securityService.WithContext(c.UserContext()).GetSecurityEvents(c.Query("type"), userID, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get security events", err)
+	}
+
+	return response.Paginated(c, "Security events retrieved successfully", events, page, limit, total)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/storefront"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SitemapHandler serves the generated sitemaps of storefront pages
+type SitemapHandler struct {
+	sitemapService *services.SitemapService
+}
+
+// NewSitemapHandler creates a new sitemap handler
+func NewSitemapHandler(svc *services.Services) *SitemapHandler {
+	return &SitemapHandler{
+		sitemapService: svc.Sitemaps,
+	}
+}
+
+// GetSitemapIndex serves the tenant's sitemap index as last generated
+func (h *SitemapHandler) GetSitemapIndex(c *fiber.Ctx) error {
+	return h.sendSitemap(c, storefront.SitemapIndex)
+}
+
+// GetSitemap serves a page of the tenant's sitemaps as last generated
+func (h *SitemapHandler) GetSitemap(c *fiber.Ctx) error {
+	return h.sendSitemap(c, c.Params("name"))
+}
+
+// GenerateSitemaps regenerates the tenant's sitemaps now rather than on the
+// sitemap_generation schedule
+func (h *SitemapHandler) GenerateSitemaps(c *fiber.Ctx) error {
+	counts, err := h.sitemapService.WithContext(c.UserContext()).GenerateSitemaps()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to generate sitemaps", err)
+	}
+
+	return response.OK(c, "Sitemaps generated successfully", counts)
+}
+
+// sendSitemap streams a stored sitemap
+func (h *SitemapHandler) sendSitemap(c *fiber.Ctx, name string) error {
+	file, err := h.sitemapService.WithContext(c.UserContext()).OpenSitemap(name)
+	if err != nil {
+		if err.Error() == "sitemap not found" {
+			return response.Error(c, fiber.StatusNotFound, "Sitemap not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get sitemap", err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.SendStream(file)
+}
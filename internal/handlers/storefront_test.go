@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestFeeds(t *testing.T) {
+	createBook(t, bookRequest(t))
+	expectStatus(t, request(t, fiber.MethodPost, "/admin/feeds/generate", nil, adminToken(t)), fiber.StatusOK)
+
+	for _, tt := range []struct {
+		name        string
+		contentType string
+	}{
+		{"google-merchant.xml", "application/xml"},
+		{"new-releases.rss", "application/rss+xml"},
+		{"new-releases.atom", "application/atom+xml"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := download(t, "/feeds/"+tt.name, "")
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusOK)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, tt.contentType) {
+				t.Fatalf("content type is %q, expected %s", got, tt.contentType)
+			}
+			if !bytes.HasPrefix(body, []byte("<?xml")) {
+				t.Fatalf("feed starts %q, expected an XML declaration", body[:min(len(body), 16)])
+			}
+		})
+	}
+	t.Run("unknown feed", func(t *testing.T) {
+		resp, _ := download(t, "/feeds/everything.xml", "")
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Fatalf("status is %d, expected %d", resp.StatusCode, fiber.StatusNotFound)
+		}
+	})
+	t.Run("generate requires admin", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/feeds/generate", nil, ""), fiber.StatusUnauthorized)
+	})
+}
+
+func TestSitemaps(t *testing.T) {
+	createBook(t, bookRequest(t))
+	expectStatus(t, request(t, fiber.MethodPost, "/admin/sitemaps/generate", nil, adminToken(t)), fiber.StatusOK)
+
+	t.Run("index", func(t *testing.T) {
+		resp, body := download(t, "/sitemap.xml", "")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusOK)
+		}
+		if !bytes.Contains(body, []byte("books-1.xml")) {
+			t.Fatalf("sitemap index %s does not list books-1.xml", body)
+		}
+	})
+	t.Run("page", func(t *testing.T) {
+		resp, body := download(t, "/sitemaps/books-1.xml", "")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("status is %d (%s), expected %d", resp.StatusCode, body, fiber.StatusOK)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, "application/xml") {
+			t.Fatalf("content type is %q, expected application/xml", got)
+		}
+	})
+	t.Run("page past the end", func(t *testing.T) {
+		resp, _ := download(t, "/sitemaps/books-100000.xml", "")
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Fatalf("status is %d, expected %d", resp.StatusCode, fiber.StatusNotFound)
+		}
+	})
+	t.Run("generate requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodPost, "/admin/sitemaps/generate", nil, token), fiber.StatusForbidden)
+	})
+}
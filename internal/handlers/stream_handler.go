@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/tenancy"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// StreamHandler serves domain events to clients over Server-Sent Events
+type StreamHandler struct {
+	stream    *events.Stream
+	heartbeat time.Duration
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &StreamHandler{
+		stream:    events.GetStream(),
+		heartbeat: heartbeat,
+	}
+}
+
+// StreamEvents streams book stock and price changes as Server-Sent Events.
+// Clients reconnecting with Last-Event-ID receive the events they missed first.
+func (h *StreamHandler) StreamEvents(c *fiber.Ctx) error {
+	types, err := parseStreamTypes(c.Query("types"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid event types", err)
+	}
+
+	var bookID uuid.UUID
+	if bookIDStr := c.Query("book_id"); bookIDStr != "" {
+		bookID, err = uuid.Parse(bookIDStr)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid book ID", err)
+		}
+	}
+
+	// Browsers send Last-Event-ID on reconnect; the query parameter allows resuming a new connection
+	lastEventIDStr := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	var lastEventID uuid.UUID
+	if lastEventIDStr != "" {
+		lastEventID, err = uuid.Parse(lastEventIDStr)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid Last-Event-ID", err)
+		}
+	}
+
+	// Subscribe before replaying so no events are missed in between
+	tenantID, _ := tenancy.TenantID(c.UserContext())
+	sub := h.stream.Subscribe(tenantID, types, bookID)
+
+	var replay []models.OutboxEvent
+	if lastEventID != uuid.Nil {
+		replay, err = h.stream.Replay(c.UserContext(), lastEventID, types, bookID)
+		if err != nil {
+			h.stream.Unsubscribe(sub)
+			return response.Error(c, fiber.StatusInternalServerError, "Failed to replay events", err)
+		}
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.stream.Unsubscribe(sub)
+
+		fmt.Fprintf(w, "retry: 3000\n\n")
+		seen := make(map[uuid.UUID]bool, len(replay))
+		for i := range replay {
+			seen[replay[i].ID] = true
+			if err := writeStreamEvent(w, &replay[i]); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				if seen[event.ID] {
+					continue
+				}
+				if err := writeStreamEvent(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				fmt.Fprintf(w, ": ping\n\n")
+			}
+			// Flush fails once the client has disconnected
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// parseStreamTypes parses a comma-separated list of event types, defaulting to all streamable types
+func parseStreamTypes(value string) ([]string, error) {
+	if value == "" {
+		return events.StreamEvents, nil
+	}
+
+	var types []string
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		valid := false
+		for _, allowed := range events.StreamEvents {
+			if t == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unsupported event type %q, expected one of %s", t, strings.Join(events.StreamEvents, ", "))
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return events.StreamEvents, nil
+	}
+	return types, nil
+}
+
+// writeStreamEvent writes an event as an SSE message carrying the published envelope
+func writeStreamEvent(w *bufio.Writer, event *models.OutboxEvent) error {
+	data, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, data)
+	return err
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SupplierHandler handles dropshipping suppliers and syncs of their feeds
+type SupplierHandler struct {
+	supplierService *services.SupplierService
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(svc *services.Services) *SupplierHandler {
+	return &SupplierHandler{
+		supplierService: svc.Suppliers,
+	}
+}
+
+// CreateSupplierRequest represents the request payload for creating a
+// supplier. update_prices, update_stock, and enabled default to true.
+type CreateSupplierRequest struct {
+	Name         string     `json:"name" validate:"required,min=1,max=255"`
+	FeedURL      string     `json:"feed_url" validate:"required,url,max=2048"`
+	Format       string     `json:"format" validate:"required,oneof=csv json"`
+	AuthHeader   string     `json:"auth_header,omitempty" validate:"max=100"`
+	AuthValue    string     `json:"auth_value,omitempty" validate:"max=2048"`
+	WarehouseID  *uuid.UUID `json:"warehouse_id,omitempty"`
+	UpdatePrices *bool      `json:"update_prices,omitempty"`
+	UpdateStock  *bool      `json:"update_stock,omitempty"`
+	Enabled      *bool      `json:"enabled,omitempty"`
+}
+
+// UpdateSupplierRequest represents the request payload for updating a
+// supplier
+type UpdateSupplierRequest struct {
+	Name       string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	FeedURL    string  `json:"feed_url,omitempty" validate:"omitempty,url,max=2048"`
+	Format     string  `json:"format,omitempty" validate:"omitempty,oneof=csv json"`
+	AuthHeader *string `json:"auth_header,omitempty" validate:"omitempty,max=100"`
+	AuthValue  *string `json:"auth_value,omitempty" validate:"omitempty,max=2048"`
+	// WarehouseID sets the warehouse the supplier's stock is held at; an
+	// empty string makes the feed set the books' total stock again
+	WarehouseID  *string `json:"warehouse_id,omitempty"`
+	UpdatePrices *bool   `json:"update_prices,omitempty"`
+	UpdateStock  *bool   `json:"update_stock,omitempty"`
+	Enabled      *bool   `json:"enabled,omitempty"`
+}
+
+// CreateSupplier creates a new supplier
+func (h *SupplierHandler) CreateSupplier(c *fiber.Ctx) error {
+	var req CreateSupplierRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	supplier := &models.Supplier{
+		Name:         req.Name,
+		FeedURL:      req.FeedURL,
+		Format:       req.Format,
+		AuthHeader:   req.AuthHeader,
+		AuthValue:    req.AuthValue,
+		WarehouseID:  req.WarehouseID,
+		UpdatePrices: req.UpdatePrices == nil || *req.UpdatePrices,
+		UpdateStock:  req.UpdateStock == nil || *req.UpdateStock,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+	if err := h.supplierService.WithContext(c.UserContext()).CreateSupplier(supplier); err != nil {
+		return supplierError(c, "Failed to create supplier", err)
+	}
+
+	return response.Created(c, "Supplier created successfully", supplier)
+}
+
+// GetAllSuppliers lists suppliers by name
+func (h *SupplierHandler) GetAllSuppliers(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	suppliers, total, err := h.supplierService.WithContext(c.UserContext()).GetAllSuppliers(page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get suppliers", err)
+	}
+
+	return response.Paginated(c, "Suppliers retrieved successfully", suppliers, page, limit, total)
+}
+
+// GetSupplier retrieves a supplier by ID
+func (h *SupplierHandler) GetSupplier(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "supplier")
+	if !ok {
+		return err
+	}
+
+	supplier, err := h.supplierService.WithContext(c.UserContext()).GetSupplierByID(id)
+	if err != nil {
+		return supplierError(c, "Failed to get supplier", err)
+	}
+
+	return response.OK(c, "Supplier retrieved successfully", supplier)
+}
+
+// UpdateSupplier updates an existing supplier
+func (h *SupplierHandler) UpdateSupplier(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "supplier")
+	if !ok {
+		return err
+	}
+
+	var req UpdateSupplierRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.FeedURL != "" {
+		updates["feed_url"] = req.FeedURL
+	}
+	if req.Format != "" {
+		updates["format"] = req.Format
+	}
+	if req.AuthHeader != nil {
+		updates["auth_header"] = *req.AuthHeader
+	}
+	if req.AuthValue != nil {
+		updates["auth_value"] = *req.AuthValue
+	}
+	if req.WarehouseID != nil {
+		var warehouseID *uuid.UUID
+		if *req.WarehouseID != "" {
+			parsed, err := uuid.Parse(*req.WarehouseID)
+			if err != nil {
+				return invalidWarehouseID(c, err)
+			}
+			warehouseID = &parsed
+		}
+		updates["warehouse_id"] = warehouseID
+	}
+	if req.UpdatePrices != nil {
+		updates["update_prices"] = *req.UpdatePrices
+	}
+	if req.UpdateStock != nil {
+		updates["update_stock"] = *req.UpdateStock
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+	if len(updates) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+	}
+
+	supplier, err := h.supplierService.WithContext(c.UserContext()).UpdateSupplier(id, updates)
+	if err != nil {
+		return supplierError(c, "Failed to update supplier", err)
+	}
+
+	return response.OK(c, "Supplier updated successfully", supplier)
+}
+
+// DeleteSupplier deletes a supplier and its sync history
+func (h *SupplierHandler) DeleteSupplier(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "supplier")
+	if !ok {
+		return err
+	}
+
+	if err := h.supplierService.WithContext(c.UserContext()).DeleteSupplier(id); err != nil {
+		return supplierError(c, "Failed to delete supplier", err)
+	}
+
+	return response.OK(c, "Supplier deleted successfully", nil)
+}
+
+// SyncSupplier pulls a supplier's feed now and applies its changes, or with
+// dry_run=true only reports them. A feed that cannot be read responds 502
+// with the failed sync.
+func (h *SupplierHandler) SyncSupplier(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "supplier")
+	if !ok {
+		return err
+	}
+
+	run, err := h.supplierService.WithContext(c.UserContext()).SyncSupplier(id, c.QueryBool("dry_run"))
+	if err != nil {
+		return supplierError(c, "Failed to sync supplier", err)
+	}
+	if run.Status == models.SupplierSyncFailed {
+		return response.Fail(c, fiber.StatusBadGateway, response.Envelope{
+			Message: "Supplier feed could not be synced",
+			Details: run.Error,
+			Data:    run,
+		})
+	}
+
+	return response.OK(c, "Supplier synced successfully", run)
+}
+
+// GetSupplierSyncs lists a supplier's syncs, newest first
+func (h *SupplierHandler) GetSupplierSyncs(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "supplier")
+	if !ok {
+		return err
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	syncs, total, err := h.supplierService.WithContext(c.UserContext()).GetSupplierSyncs(id, page, limit)
+	if err != nil {
+		return supplierError(c, "Failed to get supplier syncs", err)
+	}
+
+	return response.Paginated(c, "Supplier syncs retrieved successfully", syncs, page, limit, total)
+}
+
+// GetSupplierSync retrieves one of a supplier's syncs with its changes
+func (h *SupplierHandler) GetSupplierSync(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "supplier")
+	if !ok {
+		return err
+	}
+	syncID, ok, err := parseUUIDParam(c, "syncId", "sync")
+	if !ok {
+		return err
+	}
+
+	run, err := h.supplierService.WithContext(c.UserContext()).GetSupplierSync(id, syncID)
+	if err != nil {
+		return supplierError(c, "Failed to get supplier sync", err)
+	}
+
+	return response.OK(c, "Supplier sync retrieved successfully", run)
+}
+
+// supplierError responds to a supplier service error, mapping invalid
+// suppliers to 400, a missing supplier, sync, or warehouse to 404, and
+// duplicate names to 409
+func supplierError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid supplier: "):
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid supplier: "))
+	case err.Error() == "supplier not found":
+		return response.Error(c, fiber.StatusNotFound, "Supplier not found", nil)
+	case err.Error() == "supplier sync not found":
+		return response.Error(c, fiber.StatusNotFound, "Supplier sync not found", nil)
+	case err.Error() == "warehouse not found":
+		return response.Error(c, fiber.StatusNotFound, "Warehouse not found", nil)
+	case err.Error() == "supplier with this name already exists":
+		return response.Error(c, fiber.StatusConflict, "Supplier with this name already exists", nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, message, err)
+}
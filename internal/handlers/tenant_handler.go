@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tenantSlugPattern matches slugs usable as a subdomain label
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// TenantHandler handles tenant management HTTP requests
+type TenantHandler struct {
+	tenantService *services.TenantService
+	resolver      *tenancy.Resolver
+}
+
+// NewTenantHandler creates a new tenant handler
+func NewTenantHandler(svc *services.Services) *TenantHandler {
+	return &TenantHandler{
+		tenantService: svc.Tenants,
+		resolver:      tenancy.GetResolver(),
+	}
+}
+
+// CreateTenantRequest represents the request payload for creating a tenant
+type CreateTenantRequest struct {
+	Slug              string `json:"slug" validate:"required,max=63"`
+	Name              string `json:"name" validate:"required,min=1,max=255"`
+	Domain            string `json:"domain,omitempty" validate:"omitempty,fqdn,max=255"`
+	RateLimit         int    `json:"rate_limit,omitempty" validate:"min=0"`
+	StrictRateLimit   int    `json:"strict_rate_limit,omitempty" validate:"min=0"`
+	LowStockThreshold int    `json:"low_stock_threshold,omitempty" validate:"min=0"`
+}
+
+// UpdateTenantRequest represents the request payload for updating a tenant.
+// An empty domain removes the tenant's custom domain.
+type UpdateTenantRequest struct {
+	Name              string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Domain            *string `json:"domain,omitempty" validate:"omitempty,max=255"`
+	Active            *bool   `json:"active,omitempty"`
+	RateLimit         *int    `json:"rate_limit,omitempty" validate:"omitempty,min=0"`
+	StrictRateLimit   *int    `json:"strict_rate_limit,omitempty" validate:"omitempty,min=0"`
+	LowStockThreshold *int    `json:"low_stock_threshold,omitempty" validate:"omitempty,min=0"`
+}
+
+// CreateTenant creates a new tenant
+func (h *TenantHandler) CreateTenant(c *fiber.Ctx) error {
+	var req CreateTenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+	if !tenantSlugPattern.MatchString(req.Slug) {
+		return validationFailed(c, utils.ValidationErrors{{
+			Field:   "slug",
+			Rule:    "slug",
+			Message: "slug may only contain lowercase letters, digits, and inner hyphens",
+		}})
+	}
+
+	tenant := &models.Tenant{
+		Slug:              req.Slug,
+		Name:              req.Name,
+		Active:            true,
+		RateLimit:         req.RateLimit,
+		StrictRateLimit:   req.StrictRateLimit,
+		LowStockThreshold: req.LowStockThreshold,
+	}
+	if domain := strings.ToLower(req.Domain); domain != "" {
+		tenant.Domain = &domain
+	}
+
+	if err := h.tenantService.CreateTenant(tenant); err != nil {
+		if err.Error() == "tenant slug or domain already in use" {
+			return response.Error(c, fiber.StatusConflict, "Tenant slug or domain already in use", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create tenant", err)
+	}
+	h.resolver.Invalidate()
+
+	return response.Created(c, "Tenant created successfully", tenant)
+}
+
+// GetTenant retrieves a tenant by ID
+func (h *TenantHandler) GetTenant(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "tenant")
+	if !ok {
+		return err
+	}
+
+	tenant, err := h.tenantService.GetTenantByID(id)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			return response.Error(c, fiber.StatusNotFound, "Tenant not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get tenant", err)
+	}
+
+	return response.OK(c, "Tenant retrieved successfully", tenant)
+}
+
+// GetAllTenants retrieves all tenants with pagination
+func (h *TenantHandler) GetAllTenants(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	tenants, total, err := h.tenantService.GetAllTenants(page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get tenants", err)
+	}
+
+	return response.Paginated(c, "Tenants retrieved successfully", tenants, page, limit, total)
+}
+
+// UpdateTenant updates an existing tenant
+func (h *TenantHandler) UpdateTenant(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "tenant")
+	if !ok {
+		return err
+	}
+
+	var req UpdateTenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Domain != nil {
+		if domain := strings.ToLower(strings.TrimSpace(*req.Domain)); domain != "" {
+			updates["domain"] = domain
+		} else {
+			updates["domain"] = nil
+		}
+	}
+	if req.Active != nil {
+		if !*req.Active && id == models.DefaultTenantID {
+			return response.Error(c, fiber.StatusBadRequest, "Default tenant cannot be deactivated", nil)
+		}
+		updates["active"] = *req.Active
+	}
+	if req.RateLimit != nil {
+		updates["rate_limit"] = *req.RateLimit
+	}
+	if req.StrictRateLimit != nil {
+		updates["strict_rate_limit"] = *req.StrictRateLimit
+	}
+	if req.LowStockThreshold != nil {
+		updates["low_stock_threshold"] = *req.LowStockThreshold
+	}
+	if len(updates) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+	}
+
+	if err := h.tenantService.UpdateTenant(id, updates); err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			return response.Error(c, fiber.StatusNotFound, "Tenant not found", nil)
+		case "tenant slug or domain already in use":
+			return response.Error(c, fiber.StatusConflict, "Tenant slug or domain already in use", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update tenant", err)
+	}
+	h.resolver.Invalidate()
+
+	return response.OK(c, "Tenant updated successfully", nil)
+}
+
+// DeleteTenant soft deletes a tenant. Its data is kept but no longer served.
+func (h *TenantHandler) DeleteTenant(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "tenant")
+	if !ok {
+		return err
+	}
+
+	if err := h.tenantService.DeleteTenant(id); err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			return response.Error(c, fiber.StatusNotFound, "Tenant not found", nil)
+		case "default tenant cannot be deleted":
+			return response.Error(c, fiber.StatusBadRequest, "Default tenant cannot be deleted", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete tenant", err)
+	}
+	h.resolver.Invalidate()
+
+	return response.OK(c, "Tenant deleted successfully", nil)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bookstore-api/internal/i18n"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TranslationHandler handles translation management HTTP requests for books
+// and categories. Each method returns the handler for one entity type.
+type TranslationHandler struct {
+	translationService *services.TranslationService
+}
+
+// NewTranslationHandler creates a new translation handler
+func NewTranslationHandler(svc *services.Services) *TranslationHandler {
+	return &TranslationHandler{
+		translationService: svc.Translations,
+	}
+}
+
+// GetTranslations lists every translation of the entity in the :id route parameter, keyed by locale
+func (h *TranslationHandler) GetTranslations(entityType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, ok, err := parseUUIDParam(c, "id", entityType)
+		if !ok {
+			return err
+		}
+
+		translations, err := h.translationService.WithContext(c.UserContext()).GetTranslations(entityType, id)
+		if err != nil {
+			return translationError(c, entityType, "Failed to get translations", err)
+		}
+
+		return response.OK(c, "Translations retrieved successfully", translations)
+	}
+}
+
+// SetTranslations creates or replaces translated fields of the entity in the
+// :locale route parameter. The body maps field names to values; an empty value
+// removes that field's translation.
+func (h *TranslationHandler) SetTranslations(entityType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, ok, err := parseUUIDParam(c, "id", entityType)
+		if !ok {
+			return err
+		}
+		locale, ok, err := parseTranslationLocale(c)
+		if !ok {
+			return err
+		}
+
+		var values map[string]string
+		if err := c.BodyParser(&values); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+		}
+		if len(values) == 0 {
+			return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+		}
+
+		translationService := h.translationService.WithContext(c.UserContext())
+		if err := translationService.SetTranslations(entityType, id, locale, values); err != nil {
+			if strings.HasSuffix(err.Error(), "cannot be translated") {
+				return response.Error(c, fiber.StatusBadRequest, "Validation failed", err)
+			}
+			return translationError(c, entityType, "Failed to save translations", err)
+		}
+
+		translations, err := translationService.GetTranslations(entityType, id)
+		if err != nil {
+			return translationError(c, entityType, "Failed to get translations", err)
+		}
+
+		return response.OK(c, "Translations saved successfully", translations[locale])
+	}
+}
+
+// DeleteTranslations removes every translation of the entity in the :locale route parameter
+func (h *TranslationHandler) DeleteTranslations(entityType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, ok, err := parseUUIDParam(c, "id", entityType)
+		if !ok {
+			return err
+		}
+		locale, ok, err := parseTranslationLocale(c)
+		if !ok {
+			return err
+		}
+
+		if err := h.translationService.WithContext(c.UserContext()).DeleteTranslations(entityType, id, locale); err != nil {
+			if err.Error() == "translation not found" {
+				return response.Error(c, fiber.StatusNotFound, "Translation not found", nil)
+			}
+			return translationError(c, entityType, "Failed to delete translations", err)
+		}
+
+		return response.OK(c, "Translations deleted successfully", nil)
+	}
+}
+
+// parseTranslationLocale parses and normalizes the :locale route parameter.
+// When it is invalid the error response has been sent and ok is false.
+func parseTranslationLocale(c *fiber.Ctx) (string, bool, error) {
+	locale, err := i18n.Normalize(c.Params("locale"))
+	if err != nil {
+		return "", false, response.ErrorWithDetails(c, fiber.StatusBadRequest, "Invalid locale", "locale must be a BCP 47 language tag such as fr or pt-BR")
+	}
+	return locale, true, nil
+}
+
+// translationError responds to a translation service error, mapping a missing
+// book or category to 404
+func translationError(c *fiber.Ctx, entityType, message string, err error) error {
+	if err.Error() == entityType+" not found" {
+		return response.Error(c, fiber.StatusNotFound, strings.ToUpper(entityType[:1])+entityType[1:]+" not found", nil)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserHandler handles user administration HTTP requests
+type UserHandler struct {
+	userService *services.UserService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(svc *services.Services) *UserHandler {
+	return &UserHandler{
+		userService: svc.Users,
+	}
+}
+
+// CreateUserRequest represents the request payload for creating a user
+type CreateUserRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Name  string `json:"name,omitempty" validate:"max=255"`
+	Role  string `json:"role" validate:"required,oneof=customer admin"`
+}
+
+// SetUserRoleRequest represents the request payload for changing a user's role
+type SetUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=customer admin"`
+}
+
+// CreateUser creates a user who signs in later with a social login verifying the same email
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	var req CreateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	user, err := h.userService.WithContext(c.UserContext()).CreateUser(req.Email, req.Name, req.Role)
+	if err != nil {
+		if resp, ok := conflictResponse(err); ok {
+			return response.Fail(c, fiber.StatusConflict, resp)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create user", err)
+	}
+
+	return response.Created(c, "User created successfully", user)
+}
+
+// SetUserRole changes a user's role
+func (h *UserHandler) SetUserRole(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "user")
+	if !ok {
+		return err
+	}
+
+	var req SetUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	if err := h.userService.WithContext(c.UserContext()).SetUserRole(id, req.Role); err != nil {
+		if err.Error() == "user not found" {
+			return response.Error(c, fiber.StatusNotFound, "User not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update user role", err)
+	}
+
+	return response.OK(c, "User role updated successfully", nil)
+}
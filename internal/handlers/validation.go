@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// validationResponse is the body of the 400 response to a validation error.
+// Besides the joined details it lists each failed field, with the rule it
+// broke, so clients can highlight the fields in their forms.
+func validationResponse(err error) response.Envelope {
+	resp := response.Envelope{Message: "Validation failed", Details: err.Error()}
+	if fields, ok := utils.FieldErrors(err); ok {
+		resp.Details = fields.Error()
+		resp.Fields = fields
+	}
+	return resp
+}
+
+// validationFailed responds to a validation error with 400
+func validationFailed(c *fiber.Ctx, err error) error {
+	return response.Fail(c, fiber.StatusBadRequest, validationResponse(err))
+}
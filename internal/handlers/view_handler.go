@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxRecentlyViewed caps the books returned by GetRecentlyViewed
+const maxRecentlyViewed = 50
+
+// ViewHandler handles book view analytics
+type ViewHandler struct {
+	viewService        *services.ViewService
+	translationService *services.TranslationService
+	trendingWindow     time.Duration
+}
+
+// NewViewHandler creates a new view handler
+func NewViewHandler(cfg *config.Config, svc *services.Services) *ViewHandler {
+	return &ViewHandler{
+		viewService:        svc.Views,
+		translationService: svc.Translations,
+		trendingWindow:     cfg.Analytics.TrendingWindow,
+	}
+}
+
+// GetBookStats returns the view counts of the book in the :id route parameter
+func (h *ViewHandler) GetBookStats(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "book")
+	if !ok {
+		return err
+	}
+
+	stats, err := h.viewService.WithContext(c.UserContext()).GetBookStats(id, time.Now())
+	if err != nil {
+		if err.Error() == "book not found" {
+			return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get book stats", err)
+	}
+
+	return response.OK(c, "Book stats retrieved successfully", stats)
+}
+
+// GetRecentlyViewed lists the books the current user viewed, most recently
+// viewed first
+func (h *ViewHandler) GetRecentlyViewed(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return notUserTokenResponse(c)
+	}
+	limit, err := getCountParam(c, "limit", 10, maxRecentlyViewed)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	books, err := h.viewService.WithContext(c.UserContext()).GetRecentlyViewedBooks(userID, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get recently viewed books", err)
+	}
+
+	localizeBooks(c, h.translationService, books)
+	body := response.Envelope{
+		Message: "Recently viewed books retrieved successfully",
+		Data:    books,
+	}
+	if wantLinks(c) {
+		body.Data = linkBooks(c, books)
+	}
+	return c.JSON(body)
+}
+
+// GetTrendingBooks lists the books viewed most within the trending window,
+// with their number of views in it
+func (h *ViewHandler) GetTrendingBooks(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	books, total, err := h.viewService.WithContext(c.UserContext()).GetTrendingBooks(time.Now().Add(-h.trendingWindow), page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get trending books", err)
+	}
+
+	localizeBooks(c, h.translationService, books)
+	body := response.Envelope{
+		Message:    "Trending books retrieved successfully",
+		Data:       books,
+		Pagination: response.NewPagination(page, limit, total),
+	}
+	if wantLinks(c) {
+		body.Data = linkBooks(c, books)
+	}
+	return c.JSON(body)
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// WarehouseHandler handles warehouses, the stock of books held at each, and
+// transfers of stock between them
+type WarehouseHandler struct {
+	warehouseService *services.WarehouseService
+}
+
+// NewWarehouseHandler creates a new warehouse handler
+func NewWarehouseHandler(svc *services.Services) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseService: svc.Warehouses,
+	}
+}
+
+// CreateWarehouseRequest represents the request payload for creating a
+// warehouse
+type CreateWarehouseRequest struct {
+	Code    string `json:"code" validate:"required,min=1,max=50"`
+	Name    string `json:"name" validate:"required,min=1,max=255"`
+	Address string `json:"address,omitempty" validate:"max=1000"`
+}
+
+// UpdateWarehouseRequest represents the request payload for updating a
+// warehouse
+type UpdateWarehouseRequest struct {
+	Code    string  `json:"code,omitempty" validate:"omitempty,min=1,max=50"`
+	Name    string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Address *string `json:"address,omitempty" validate:"omitempty,max=1000"`
+}
+
+// SetWarehouseStockRequest represents the request payload for setting the
+// stock of a book held at a warehouse
+type SetWarehouseStockRequest struct {
+	Quantity *int   `json:"quantity" validate:"required,min=0"`
+	Note     string `json:"note,omitempty" validate:"max=255"`
+}
+
+// TransferStockRequest represents the request payload for transferring
+// stock. Leaving out from_warehouse_id allocates unallocated stock, and
+// leaving out to_warehouse_id returns stock to unallocated.
+type TransferStockRequest struct {
+	BookID          uuid.UUID  `json:"book_id" validate:"required"`
+	FromWarehouseID *uuid.UUID `json:"from_warehouse_id,omitempty"`
+	ToWarehouseID   *uuid.UUID `json:"to_warehouse_id,omitempty"`
+	Quantity        int        `json:"quantity" validate:"required,min=1"`
+	Note            string     `json:"note,omitempty" validate:"max=255"`
+}
+
+// CreateWarehouse creates a new warehouse
+func (h *WarehouseHandler) CreateWarehouse(c *fiber.Ctx) error {
+	var req CreateWarehouseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	warehouse := &models.Warehouse{
+		Code:    req.Code,
+		Name:    req.Name,
+		Address: req.Address,
+	}
+	if err := h.warehouseService.WithContext(c.UserContext()).CreateWarehouse(warehouse); err != nil {
+		return warehouseError(c, "Failed to create warehouse", err)
+	}
+
+	return response.Created(c, "Warehouse created successfully", warehouse)
+}
+
+// GetAllWarehouses lists warehouses by code
+func (h *WarehouseHandler) GetAllWarehouses(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	warehouses, total, err := h.warehouseService.WithContext(c.UserContext()).GetAllWarehouses(page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get warehouses", err)
+	}
+
+	return response.Paginated(c, "Warehouses retrieved successfully", warehouses, page, limit, total)
+}
+
+// GetWarehouse retrieves a warehouse by ID
+func (h *WarehouseHandler) GetWarehouse(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "warehouse")
+	if !ok {
+		return err
+	}
+
+	warehouse, err := h.warehouseService.WithContext(c.UserContext()).GetWarehouseByID(id)
+	if err != nil {
+		return warehouseError(c, "Failed to get warehouse", err)
+	}
+
+	return response.OK(c, "Warehouse retrieved successfully", warehouse)
+}
+
+// UpdateWarehouse updates an existing warehouse
+func (h *WarehouseHandler) UpdateWarehouse(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "warehouse")
+	if !ok {
+		return err
+	}
+
+	var req UpdateWarehouseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.Code != "" {
+		updates["code"] = req.Code
+	}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Address != nil {
+		updates["address"] = *req.Address
+	}
+	if len(updates) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+	}
+
+	warehouse, err := h.warehouseService.WithContext(c.UserContext()).UpdateWarehouse(id, updates)
+	if err != nil {
+		return warehouseError(c, "Failed to update warehouse", err)
+	}
+
+	return response.OK(c, "Warehouse updated successfully", warehouse)
+}
+
+// DeleteWarehouse deletes a warehouse that holds no stock
+func (h *WarehouseHandler) DeleteWarehouse(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "warehouse")
+	if !ok {
+		return err
+	}
+
+	if err := h.warehouseService.WithContext(c.UserContext()).DeleteWarehouse(id); err != nil {
+		return warehouseError(c, "Failed to delete warehouse", err)
+	}
+
+	return response.OK(c, "Warehouse deleted successfully", nil)
+}
+
+// GetWarehouseStock lists the books a warehouse holds stock of
+func (h *WarehouseHandler) GetWarehouseStock(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "warehouse")
+	if !ok {
+		return err
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	levels, total, err := h.warehouseService.WithContext(c.UserContext()).GetWarehouseStock(id, page, limit)
+	if err != nil {
+		return warehouseError(c, "Failed to get warehouse stock", err)
+	}
+
+	return response.Paginated(c, "Warehouse stock retrieved successfully", levels, page, limit, total)
+}
+
+// SetWarehouseStock sets the stock of a book held at a warehouse, changing
+// the book's total stock by the same amount
+func (h *WarehouseHandler) SetWarehouseStock(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "warehouse")
+	if !ok {
+		return err
+	}
+	bookID, ok, err := parseUUIDParam(c, "bookId", "book")
+	if !ok {
+		return err
+	}
+
+	var req SetWarehouseStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	level, err := h.warehouseService.WithContext(c.UserContext()).SetWarehouseStock(id, bookID, *req.Quantity, req.Note)
+	if err != nil {
+		return warehouseError(c, "Failed to set warehouse stock", err)
+	}
+
+	return response.OK(c, "Warehouse stock updated successfully", level)
+}
+
+// TransferStock moves stock of a book between warehouses
+func (h *WarehouseHandler) TransferStock(c *fiber.Ctx) error {
+	var req TransferStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	transfer := &models.StockTransfer{
+		BookID:          req.BookID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Note:            req.Note,
+	}
+	if err := h.warehouseService.WithContext(c.UserContext()).TransferStock(transfer); err != nil {
+		return warehouseError(c, "Failed to transfer stock", err)
+	}
+
+	return response.Created(c, "Stock transferred successfully", transfer)
+}
+
+// GetTransfers lists stock transfers, newest first, filtered by book and
+// warehouse
+func (h *WarehouseHandler) GetTransfers(c *fiber.Ctx) error {
+	var filter services.TransferFilter
+	if value := c.Query("book_id"); value != "" {
+		bookID, err := uuid.Parse(value)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid book ID", err)
+		}
+		filter.BookID = bookID
+	}
+	if value := c.Query("warehouse_id"); value != "" {
+		warehouseID, err := uuid.Parse(value)
+		if err != nil {
+			return invalidWarehouseID(c, err)
+		}
+		filter.WarehouseID = warehouseID
+	}
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	transfers, total, err := h.warehouseService.WithContext(c.UserContext()).GetTransfers(filter, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get transfers", err)
+	}
+
+	return response.Paginated(c, "Transfers retrieved successfully", transfers, page, limit, total)
+}
+
+// invalidWarehouseID responds 400 to a malformed warehouse ID
+func invalidWarehouseID(c *fiber.Ctx, err error) error {
+	return response.Error(c, fiber.StatusBadRequest, "Invalid warehouse ID", err)
+}
+
+// warehouseError responds to a warehouse service error, mapping invalid
+// transfers to 400, a missing warehouse or book to 404, and conflicts with
+// the stock held to 409
+func warehouseError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid transfer: "):
+		return response.ErrorWithDetails(c, fiber.StatusBadRequest, "Validation failed", strings.TrimPrefix(err.Error(), "invalid transfer: "))
+	case err.Error() == "warehouse not found":
+		return response.Error(c, fiber.StatusNotFound, "Warehouse not found", nil)
+	case err.Error() == "book not found":
+		return response.Error(c, fiber.StatusNotFound, "Book not found", nil)
+	case err.Error() == "warehouse with this code already exists":
+		return response.Error(c, fiber.StatusConflict, "Warehouse with this code already exists", nil)
+	case err.Error() == "warehouse holds stock":
+		return response.ErrorWithDetails(c, fiber.StatusConflict, "Warehouse holds stock", "transfer its stock to another warehouse before deleting it")
+	case err.Error() == "insufficient stock":
+		return response.ErrorWithDetails(c, fiber.StatusConflict, "Insufficient stock", "the warehouse, or the book's unallocated stock, does not hold enough stock")
+	}
+	return response.Error(c, fiber.StatusInternalServerError, message, err)
+}
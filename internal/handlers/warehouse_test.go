@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"bookstore-api/internal/testutil"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// createWarehouse creates a warehouse and returns its ID and code
+func createWarehouse(t *testing.T) (uuid.UUID, string) {
+	t.Helper()
+	code := strings.ToUpper(unique("wh"))
+	resp := request(t, fiber.MethodPost, "/warehouses", map[string]interface{}{"code": code, "name": "Warehouse " + code}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var warehouse record
+	decode(t, resp, &warehouse)
+	return warehouse.ID, code
+}
+
+func TestWarehouses(t *testing.T) {
+	book := createBook(t, bookRequest(t))
+	first, code := createWarehouse(t)
+	second, _ := createWarehouse(t)
+	transfer := func(t *testing.T, from *uuid.UUID, quantity int) *testutil.Response {
+		return request(t, fiber.MethodPost, "/warehouses/transfers", map[string]interface{}{
+			"book_id":           book,
+			"from_warehouse_id": from,
+			"to_warehouse_id":   second,
+			"quantity":          quantity,
+		}, adminToken(t))
+	}
+
+	t.Run("duplicate code", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/warehouses", map[string]interface{}{"code": code, "name": "Duplicate"}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusConflict)
+	})
+	t.Run("set stock", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/warehouses/"+first.String()+"/stock/"+book.String(), map[string]interface{}{"quantity": 6}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+
+		// Stock counted at a warehouse adds to the book's 10 unallocated
+		resp = request(t, fiber.MethodGet, "/books/"+book.String(), nil, "")
+		expectStatus(t, resp, fiber.StatusOK)
+		var stocked struct {
+			Stock int `json:"stock"`
+		}
+		decode(t, resp, &stocked)
+		if stocked.Stock != 16 {
+			t.Fatalf("book stock is %d, expected 16", stocked.Stock)
+		}
+	})
+	t.Run("set stock of unknown book", func(t *testing.T) {
+		resp := request(t, fiber.MethodPut, "/warehouses/"+first.String()+"/stock/"+uuid.NewString(), map[string]interface{}{"quantity": 1}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusNotFound)
+	})
+	t.Run("transfer", func(t *testing.T) {
+		expectStatus(t, transfer(t, &first, 4), fiber.StatusCreated)
+	})
+	t.Run("transfer more than held", func(t *testing.T) {
+		expectStatus(t, transfer(t, &first, 3), fiber.StatusConflict)
+	})
+	t.Run("transfer more than unallocated", func(t *testing.T) {
+		expectStatus(t, transfer(t, nil, 11), fiber.StatusConflict)
+	})
+	t.Run("delete holding stock", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodDelete, "/warehouses/"+second.String(), nil, adminToken(t)), fiber.StatusConflict)
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/warehouses", nil, ""), fiber.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler handles webhook subscription HTTP requests
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(svc *services.Services) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: svc.Webhooks,
+	}
+}
+
+// CreateWebhookRequest represents the request payload for creating a webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// UpdateWebhookRequest represents the request payload for updating a webhook
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url,omitempty" validate:"omitempty,url"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookWithSecret is returned once on creation so the client can verify signatures
+type WebhookWithSecret struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook registers a new webhook
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	webhook := &models.Webhook{
+		URL:         req.URL,
+		EventTypes:  models.StringList(req.EventTypes),
+		Description: req.Description,
+		Active:      true,
+	}
+
+	if err := h.webhookService.WithContext(c.UserContext()).CreateWebhook(webhook); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to create webhook", err)
+	}
+
+	return response.Created(c, "Webhook created successfully", WebhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// GetWebhook retrieves a webhook by ID
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "webhook")
+	if !ok {
+		return err
+	}
+
+	webhook, err := h.webhookService.WithContext(c.UserContext()).GetWebhookByID(id)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			return response.Error(c, fiber.StatusNotFound, "Webhook not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get webhook", err)
+	}
+
+	return response.OK(c, "Webhook retrieved successfully", webhook)
+}
+
+// GetAllWebhooks retrieves all webhooks with pagination
+func (h *WebhookHandler) GetAllWebhooks(c *fiber.Ctx) error {
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+
+	webhooks, total, err := h.webhookService.WithContext(c.UserContext()).GetAllWebhooks(page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get webhooks", err)
+	}
+
+	return response.Paginated(c, "Webhooks retrieved successfully", webhooks, page, limit, total)
+}
+
+// UpdateWebhook updates an existing webhook
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "webhook")
+	if !ok {
+		return err
+	}
+
+	var req UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.URL != "" {
+		updates["url"] = req.URL
+	}
+	if req.EventTypes != nil {
+		updates["event_types"] = models.StringList(req.EventTypes)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "No fields to update", nil)
+	}
+
+	if err := h.webhookService.WithContext(c.UserContext()).UpdateWebhook(id, updates); err != nil {
+		if err.Error() == "webhook not found" {
+			return response.Error(c, fiber.StatusNotFound, "Webhook not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to update webhook", err)
+	}
+
+	return response.OK(c, "Webhook updated successfully", nil)
+}
+
+// DeleteWebhook deletes a webhook
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "webhook")
+	if !ok {
+		return err
+	}
+
+	if err := h.webhookService.WithContext(c.UserContext()).DeleteWebhook(id); err != nil {
+		if err.Error() == "webhook not found" {
+			return response.Error(c, fiber.StatusNotFound, "Webhook not found", nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to delete webhook", err)
+	}
+
+	return response.OK(c, "Webhook deleted successfully", nil)
+}
+
+// GetWebhookDeliveries retrieves the delivery log of a webhook with pagination
+func (h *WebhookHandler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, ok, err := parseUUIDParam(c, "id", "webhook")
+	if !ok {
+		return err
+	}
+
+	page, limit, err := getPaginationParams(c)
+	if err != nil {
+		return invalidPagination(c, err)
+	}
+	status := c.Query("status")
+
+	deliveries, total, err := h.webhookService.WithContext(c.UserContext()).GetDeliveriesByWebhook(id, status, page, limit)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get webhook deliveries", err)
+	}
+
+	return response.Paginated(c, "Webhook deliveries retrieved successfully", deliveries, page, limit, total)
+}
+
+// GetWebhookDelivery retrieves a single delivery of a webhook
+func (h *WebhookHandler) GetWebhookDelivery(c *fiber.Ctx) error {
+	webhookID, ok, err := parseUUIDParam(c, "id", "webhook")
+	if !ok {
+		return err
+	}
+	deliveryID, ok, err := parseUUIDParam(c, "deliveryId", "delivery")
+	if !ok {
+		return err
+	}
+
+	delivery, err := h.webhookService.WithContext(c.UserContext()).GetDeliveryByID(deliveryID)
+	if err != nil && err.Error() != "delivery not found" {
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get webhook delivery", err)
+	}
+	if delivery == nil || delivery.WebhookID != webhookID {
+		return response.Error(c, fiber.StatusNotFound, "Webhook delivery not found", nil)
+	}
+
+	return response.OK(c, "Webhook delivery retrieved successfully", delivery)
+}
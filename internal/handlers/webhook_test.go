@@ -0,0 +1,52 @@
+//go:build !nowebhooks
+
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWebhooks(t *testing.T) {
+	resp := request(t, fiber.MethodPost, "/webhooks", map[string]interface{}{
+		"url":         "https://hooks.example.com/bookstore",
+		"event_types": []string{"book.created"},
+	}, adminToken(t))
+	expectStatus(t, resp, fiber.StatusCreated)
+	var webhook struct {
+		record
+		Secret string `json:"secret"`
+	}
+	decode(t, resp, &webhook)
+	if webhook.Secret == "" {
+		t.Fatal("created webhook has no secret")
+	}
+
+	t.Run("get hides the secret", func(t *testing.T) {
+		resp := request(t, fiber.MethodGet, "/webhooks/"+webhook.ID.String(), nil, adminToken(t))
+		expectStatus(t, resp, fiber.StatusOK)
+		var got struct {
+			record
+			Secret string `json:"secret"`
+		}
+		decode(t, resp, &got)
+		if got.ID != webhook.ID || got.Secret != "" {
+			t.Fatalf("got webhook %s with secret %q, expected %s without one", got.ID, got.Secret, webhook.ID)
+		}
+	})
+	t.Run("deliveries", func(t *testing.T) {
+		expectStatus(t, request(t, fiber.MethodGet, "/webhooks/"+webhook.ID.String()+"/deliveries", nil, adminToken(t)), fiber.StatusOK)
+	})
+	t.Run("invalid url", func(t *testing.T) {
+		resp := request(t, fiber.MethodPost, "/webhooks", map[string]interface{}{"url": "not a url"}, adminToken(t))
+		expectStatus(t, resp, fiber.StatusBadRequest)
+		if rules := fieldErrors(t, resp.Fields); rules["url"] != "url" {
+			t.Fatalf("failed fields are %v, expected url to break url", rules)
+		}
+	})
+	t.Run("requires admin", func(t *testing.T) {
+		_, token := userToken(t, "customer")
+		expectStatus(t, request(t, fiber.MethodGet, "/webhooks", nil, token), fiber.StatusForbidden)
+	})
+}
@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/text/language"
+)
+
+// ErrInvalidLocale is returned for locale tags that are not valid BCP 47
+var ErrInvalidLocale = errors.New("invalid locale")
+
+// maxPreferences bounds how many Accept-Language entries are honored
+const maxPreferences = 5
+
+// wildcard is the tag Accept-Language "*" parses to
+var wildcard = language.Make("mul")
+
+type contextKey struct{}
+
+// Negotiation is the outcome of matching a request's accepted languages
+// against the catalog's default locale
+type Negotiation struct {
+	// Default is the locale of the values stored on books and categories
+	Default string
+	// Candidates are the translation locales to try, most preferred first.
+	// It is empty when the stored values are preferred.
+	Candidates []string
+}
+
+// Normalize returns the canonical form of a BCP 47 locale tag, e.g. "pt-br" becomes "pt-BR"
+func Normalize(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return "", ErrInvalidLocale
+	}
+	return tag.String(), nil
+}
+
+// Negotiate matches an Accept-Language header against the default locale.
+// Each accepted tag is followed by its parents ("pt-BR" then "pt"), and
+// matching stops at the default locale since its values need no lookup.
+func Negotiate(acceptLanguage, defaultLocale string) *Negotiation {
+	n := &Negotiation{Default: defaultLocale}
+	if normalized, err := Normalize(defaultLocale); err == nil {
+		n.Default = normalized
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return n
+	}
+	if len(tags) > maxPreferences {
+		tags = tags[:maxPreferences]
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if tag == wildcard {
+			continue
+		}
+		for ; tag != language.Und; tag = tag.Parent() {
+			locale := tag.String()
+			if locale == n.Default {
+				return n
+			}
+			if !seen[locale] {
+				seen[locale] = true
+				n.Candidates = append(n.Candidates, locale)
+			}
+		}
+	}
+	return n
+}
+
+// WithNegotiation returns a context carrying the request's locale negotiation
+func WithNegotiation(ctx context.Context, n *Negotiation) context.Context {
+	return context.WithValue(ctx, contextKey{}, n)
+}
+
+// FromContext returns the locale negotiation set by WithNegotiation, or nil
+func FromContext(ctx context.Context) *Negotiation {
+	n, _ := ctx.Value(contextKey{}).(*Negotiation)
+	return n
+}
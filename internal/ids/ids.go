@@ -0,0 +1,108 @@
+// Package ids generates the primary keys of new records. Random UUIDv4 keys
+// scatter inserts across the whole primary key index, so by default keys are
+// UUIDv7, which start with a millisecond timestamp: new rows land at the end
+// of the index and keys sort roughly by creation time. The ULID strategy
+// produces keys with the ULID layout (a 48-bit millisecond timestamp and 80
+// random bits, incremented within a millisecond) in the same UUID columns.
+package ids
+
+import (
+	"bookstore-api/internal/config"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ID strategies
+const (
+	StrategyUUIDv4 = "uuidv4"
+	StrategyUUIDv7 = "uuidv7"
+	StrategyULID   = "ulid"
+)
+
+var (
+	generate = uuidV7
+	once     sync.Once
+)
+
+// Initialize selects the ID strategy of new records. Until it is called,
+// records get UUIDv7 keys.
+func Initialize(cfg *config.Config) error {
+	var err error
+	once.Do(func() {
+		switch cfg.IDs.Strategy {
+		case StrategyUUIDv4:
+			generate = uuid.New
+		case StrategyUUIDv7, "":
+			generate = uuidV7
+		case StrategyULID:
+			generate = newULID
+		default:
+			err = fmt.Errorf("invalid ID strategy %q: expected %s, %s, or %s", cfg.IDs.Strategy, StrategyUUIDv4, StrategyUUIDv7, StrategyULID)
+		}
+	})
+	return err
+}
+
+// New returns a new primary key
+func New() uuid.UUID {
+	return generate()
+}
+
+// uuidV7 returns a UUIDv7, falling back to a UUIDv4 if the random source fails
+func uuidV7() uuid.UUID {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.New()
+	}
+	return id
+}
+
+// ulidState makes ULIDs generated within the same millisecond increase, as
+// the ULID specification's monotonic mode does
+var ulidState struct {
+	sync.Mutex
+	ms     uint64
+	random [10]byte
+}
+
+// newULID returns a ULID as a UUID
+func newULID() uuid.UUID {
+	ulidState.Lock()
+	defer ulidState.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	if ms > ulidState.ms || !incrementRandom(&ulidState.random) {
+		// A new millisecond, or the random part overflowed, starts from fresh
+		// random bits
+		if _, err := rand.Read(ulidState.random[:]); err != nil {
+			return uuid.New()
+		}
+		if ms > ulidState.ms {
+			ulidState.ms = ms
+		}
+	}
+
+	var id uuid.UUID
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], ulidState.ms)
+	copy(id[:6], timestamp[2:])
+	copy(id[6:], ulidState.random[:])
+	return id
+}
+
+// incrementRandom adds one to the random part, reporting false when it
+// overflows
+func incrementRandom(random *[10]byte) bool {
+	for i := len(random) - 1; i >= 0; i-- {
+		random[i]++
+		if random[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
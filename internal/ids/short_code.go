@@ -0,0 +1,51 @@
+package ids
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// shortCodeAlphabet is base58: digits and letters without 0, O, I, and l,
+// which are easily mistaken for each other when read out or typed
+const shortCodeAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ShortCodeLength is the length of short codes, which gives 58^8, about
+// 1.3e14, possible codes
+const ShortCodeLength = 8
+
+// NewShortCode returns a random short code, a human-friendly public
+// identifier for customer-facing URLs and support calls
+func NewShortCode() string {
+	code := make([]byte, 0, ShortCodeLength)
+	buf := make([]byte, ShortCodeLength*2)
+	for len(code) < ShortCodeLength {
+		if _, err := rand.Read(buf); err != nil {
+			panic("ids: failed to read random bytes: " + err.Error())
+		}
+		for _, b := range buf {
+			// Rejecting bytes past the largest multiple of 58 keeps every
+			// character equally likely
+			if int(b) >= 256-256%len(shortCodeAlphabet) {
+				continue
+			}
+			code = append(code, shortCodeAlphabet[int(b)%len(shortCodeAlphabet)])
+			if len(code) == ShortCodeLength {
+				break
+			}
+		}
+	}
+	return string(code)
+}
+
+// IsShortCode reports whether value has the form of a short code
+func IsShortCode(value string) bool {
+	if len(value) != ShortCodeLength {
+		return false
+	}
+	for _, r := range value {
+		if !strings.ContainsRune(shortCodeAlphabet, r) {
+			return false
+		}
+	}
+	return true
+}
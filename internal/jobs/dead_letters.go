@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"bookstore-api/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeadLetterFilter selects failed jobs. IDs, when set, limit the selection to
+// those jobs; zero fields select every failed job.
+type DeadLetterFilter struct {
+	IDs          []uuid.UUID
+	Type         string
+	FailedBefore time.Time
+}
+
+// DeadLetterCount is the number of failed jobs of a type
+type DeadLetterCount struct {
+	Type           string     `json:"type"`
+	Count          int64      `json:"count"`
+	OldestFailedAt *time.Time `json:"oldest_failed_at,omitempty"`
+	LatestFailedAt *time.Time `json:"latest_failed_at,omitempty"`
+}
+
+// deadLetters returns the query of the failed jobs matching filter
+func (q *Queue) deadLetters(filter DeadLetterFilter) *gorm.DB {
+	query := q.db.Model(&models.Job{}).Where("status = ?", models.JobStatusFailed)
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if !filter.FailedBefore.IsZero() {
+		query = query.Where("failed_at < ?", filter.FailedBefore)
+	}
+	return query
+}
+
+// GetDeadLetters retrieves failed jobs with pagination, most recently failed
+// first
+func (q *Queue) GetDeadLetters(filter DeadLetterFilter, page, limit int) ([]models.Job, int64, error) {
+	var jobs []models.Job
+	var total int64
+
+	query := q.deadLetters(filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count failed jobs: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("failed_at DESC NULLS LAST").Offset(offset).Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get failed jobs: %w", err)
+	}
+	return jobs, total, nil
+}
+
+// CountDeadLetters counts the failed jobs of each type, with the oldest and
+// latest failure, for dashboards
+func (q *Queue) CountDeadLetters() ([]DeadLetterCount, error) {
+	counts := []DeadLetterCount{}
+	if err := q.deadLetters(DeadLetterFilter{}).
+		Select("type, COUNT(*) AS count, MIN(failed_at) AS oldest_failed_at, MAX(failed_at) AS latest_failed_at").
+		Group("type").
+		Order("count DESC").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed jobs: %w", err)
+	}
+	return counts, nil
+}
+
+// RetryDeadLetters returns the failed jobs matching filter to the queue with
+// fresh attempts, keeping their last error until they run again, and returns
+// how many were retried
+func (q *Queue) RetryDeadLetters(filter DeadLetterFilter) (int64, error) {
+	result := q.deadLetters(filter).Updates(map[string]interface{}{
+		"status":    models.JobStatusPending,
+		"attempts":  0,
+		"run_at":    time.Now(),
+		"locked_at": nil,
+		"failed_at": nil,
+		"retries":   gorm.Expr("retries + 1"),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to retry failed jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeDeadLetters deletes the failed jobs matching filter and returns how
+// many were deleted
+func (q *Queue) PurgeDeadLetters(filter DeadLetterFilter) (int64, error) {
+	result := q.deadLetters(filter).Delete(&models.Job{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge failed jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,371 @@
+package jobs
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxBackoff caps the delay between retries of a failing job
+const maxBackoff = time.Hour
+
+var (
+	queue *Queue
+	once  sync.Once
+)
+
+// Handler processes jobs of a single type
+type Handler interface {
+	Handle(ctx context.Context, job *models.Job) error
+}
+
+// HandlerFunc adapts an ordinary function to the Handler interface
+type HandlerFunc func(ctx context.Context, job *models.Job) error
+
+// Handle calls f(ctx, job)
+func (f HandlerFunc) Handle(ctx context.Context, job *models.Job) error {
+	return f(ctx, job)
+}
+
+// permanentError is a job failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as a failure that retrying cannot fix, such as a job
+// whose input is gone, so the job fails without using its remaining attempts
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Queue persists jobs in the database and runs them on a pool of workers
+type Queue struct {
+	db       *gorm.DB
+	cfg      config.JobsConfig
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewQueue creates a new job queue
+func NewQueue(db *gorm.DB, cfg config.JobsConfig) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &Queue{
+		db:       db,
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// InitializeQueue initializes the shared job queue on db
+func InitializeQueue(db *gorm.DB, cfg *config.Config) {
+	once.Do(func() {
+		queue = NewQueue(db, cfg.Jobs)
+	})
+}
+
+// GetQueue returns the shared job queue
+func GetQueue() *Queue {
+	if queue == nil {
+		log.Fatal("Job queue not initialized. Call InitializeQueue first.")
+	}
+	return queue
+}
+
+// Register registers the handler for a job type
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue persists a job to run as soon as a worker is available
+func (q *Queue) Enqueue(jobType string, payload interface{}) (*models.Job, error) {
+	return q.EnqueueAt(jobType, payload, time.Now())
+}
+
+// EnqueueAt persists a job to run at or after runAt
+func (q *Queue) EnqueueAt(jobType string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	return q.enqueue(q.db, jobType, payload, runAt)
+}
+
+// EnqueueContext persists a job to run as soon as a worker is available,
+// continuing the trace of ctx
+func (q *Queue) EnqueueContext(ctx context.Context, jobType string, payload interface{}) (*models.Job, error) {
+	return q.enqueue(q.db.WithContext(ctx), jobType, payload, time.Now())
+}
+
+// EnqueueTx persists a job within an existing transaction, so it only runs if the transaction commits
+func (q *Queue) EnqueueTx(tx *gorm.DB, jobType string, payload interface{}) (*models.Job, error) {
+	return q.enqueue(tx, jobType, payload, time.Now())
+}
+
+// enqueue inserts a pending job using db, keeping the trace of db's context
+func (q *Queue) enqueue(db *gorm.DB, jobType string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     string(data),
+		Status:      models.JobStatusPending,
+		MaxAttempts: q.cfg.MaxAttempts,
+		RunAt:       runAt,
+		Traceparent: tracing.Traceparent(db.Statement.Context),
+	}
+	if err := db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// GetJob retrieves a job by ID
+func (q *Queue) GetJob(id uuid.UUID) (*models.Job, error) {
+	var job models.Job
+	if err := q.db.First(&job, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("job not found")
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return &job, nil
+}
+
+// ReportProgress records how far a running job has got
+func (q *Queue) ReportProgress(job *models.Job, progress models.JobProgress) error {
+	job.Progress = &progress
+	if err := q.db.Model(job).Update("progress", progress).Error; err != nil {
+		return fmt.Errorf("failed to report job progress: %w", err)
+	}
+	return nil
+}
+
+// SetResult records what a job produced, for clients following it
+func (q *Queue) SetResult(job *models.Job, result models.JobResult) error {
+	job.Result = &result
+	if err := q.db.Model(job).Update("result", result).Error; err != nil {
+		return fmt.Errorf("failed to record job result: %w", err)
+	}
+	return nil
+}
+
+// Start launches the worker pool
+func (q *Queue) Start() {
+	if err := q.recoverStale(); err != nil {
+		log.Printf("Warning: Failed to recover stale jobs: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+	log.Printf("Started %d job workers", q.cfg.Workers)
+}
+
+// Stop signals the workers to stop and waits for running jobs to return
+func (q *Queue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+	log.Println("Stopping job workers...")
+	q.cancel()
+	q.wg.Wait()
+}
+
+// worker claims and runs due jobs until the context is cancelled
+func (q *Queue) worker(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain due jobs before waiting for the next tick
+		for ctx.Err() == nil {
+			job, err := q.claim()
+			if err != nil {
+				log.Printf("Failed to claim job: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim locks the next due job and marks it as running
+func (q *Queue) claim() (*models.Job, error) {
+	var job models.Job
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", models.JobStatusPending, time.Now()).
+			Order("run_at ASC").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":    job.Status,
+			"attempts":  job.Attempts,
+			"locked_at": now,
+		}).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// run executes a claimed job, continuing the trace it was enqueued in, and
+// records the outcome
+func (q *Queue) run(ctx context.Context, job *models.Job) {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	ctx, span := tracing.Start(ctx, "job "+job.Type, job.Traceparent)
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		err = safeHandle(ctx, handler, job)
+	}
+	span.End(ctx, slog.String("job_id", job.ID.String()), slog.Int("attempt", job.Attempts), slog.Bool("failed", err != nil))
+
+	if err == nil {
+		now := time.Now()
+		if err := q.db.Model(job).Updates(map[string]interface{}{
+			"status":       models.JobStatusCompleted,
+			"completed_at": now,
+			"locked_at":    nil,
+			"last_error":   "",
+		}).Error; err != nil {
+			log.Printf("Failed to mark job %s completed: %v", job.ID, err)
+		}
+		return
+	}
+
+	// Jobs without a handler will never succeed, so don't retry them
+	if !ok || job.Attempts >= job.MaxAttempts || IsPermanent(err) {
+		log.Printf("Job %s (%s) failed permanently after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+		if err := q.db.Model(job).Updates(map[string]interface{}{
+			"status":     models.JobStatusFailed,
+			"locked_at":  nil,
+			"last_error": err.Error(),
+			"failed_at":  time.Now(),
+		}).Error; err != nil {
+			log.Printf("Failed to mark job %s failed: %v", job.ID, err)
+		}
+		return
+	}
+
+	runAt := time.Now().Add(backoff(job.Attempts))
+	log.Printf("Job %s (%s) failed on attempt %d, retrying at %s: %v", job.ID, job.Type, job.Attempts, runAt.Format(time.RFC3339), err)
+	if err := q.db.Model(job).Updates(map[string]interface{}{
+		"status":     models.JobStatusPending,
+		"locked_at":  nil,
+		"last_error": err.Error(),
+		"run_at":     runAt,
+	}).Error; err != nil {
+		log.Printf("Failed to reschedule job %s: %v", job.ID, err)
+	}
+}
+
+// recoverStale returns jobs left running by a crashed worker to the queue
+func (q *Queue) recoverStale() error {
+	if q.cfg.StaleAfter <= 0 {
+		return nil
+	}
+
+	result := q.db.Model(&models.Job{}).
+		Where("status = ? AND locked_at < ?", models.JobStatusRunning, time.Now().Add(-q.cfg.StaleAfter)).
+		Updates(map[string]interface{}{
+			"status":    models.JobStatusPending,
+			"locked_at": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Recovered %d stale jobs", result.RowsAffected)
+	}
+	return nil
+}
+
+// safeHandle runs a handler, converting panics into errors
+func safeHandle(ctx context.Context, handler Handler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler.Handle(ctx, job)
+}
+
+// backoff returns the exponential delay before the next attempt
+func backoff(attempts int) time.Duration {
+	if attempts > 12 {
+		return maxBackoff
+	}
+	delay := time.Duration(1<<uint(attempts)) * time.Second
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
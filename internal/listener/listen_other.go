@@ -0,0 +1,22 @@
+//go:build !(linux || darwin || freebsd)
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// HandoverSignals are the signals that make a server hand its listeners to
+// a new process. Handover is not supported on this platform.
+var HandoverSignals []os.Signal
+
+// listen listens on the TCP address addr. SO_REUSEPORT is not supported on
+// this platform.
+func listen(addr string, reusePort bool) (net.Listener, error) {
+	if reusePort {
+		return nil, fmt.Errorf("SO_REUSEPORT is not supported on this platform")
+	}
+	return net.Listen("tcp", addr)
+}
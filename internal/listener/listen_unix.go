@@ -0,0 +1,34 @@
+//go:build linux || darwin || freebsd
+
+package listener
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// HandoverSignals are the signals that make a server hand its listeners to
+// a new process
+var HandoverSignals = []os.Signal{syscall.SIGUSR2}
+
+// listen listens on the TCP address addr, setting SO_REUSEPORT if reusePort
+// is true
+func listen(addr string, reusePort bool) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	if reusePort {
+		lc.Control = func(network, address string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		}
+	}
+	return lc.Listen(context.Background(), "tcp", addr)
+}
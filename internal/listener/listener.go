@@ -0,0 +1,206 @@
+// Package listener opens the HTTP and gRPC listeners so a new process can
+// take over the ports without refusing connections.
+//
+// Listeners are inherited using the systemd socket activation protocol
+// (LISTEN_FDS and LISTEN_FDNAMES), so the server can run behind systemd
+// socket units. A running server can also hand its listeners to a fresh copy
+// of its binary: Handover starts the new process with the listening sockets,
+// and once the new process is serving it calls NotifyParent, which sends the
+// old process SIGTERM so it drains and exits. The sockets stay open
+// throughout, so connections queue in the kernel instead of being refused.
+//
+// Alternatively, SO_REUSEPORT lets several processes bind the same port
+// independently. The kernel then spreads connections across them, but
+// connections still queued on a socket when its process closes it are reset,
+// so prefer handover where supported.
+package listener
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	// listenFDsStart is the first inherited file descriptor, after stdin,
+	// stdout, and stderr
+	listenFDsStart = 3
+
+	// parentEnv names the environment variable holding the PID of the
+	// process that handed its listeners over
+	parentEnv = "LISTEN_PARENT_PID"
+)
+
+var (
+	inheritOnce sync.Once
+	inherited   map[string]net.Listener
+	inheritErr  error
+
+	mu     sync.Mutex
+	opened []named
+)
+
+// named is a listener opened by Listen
+type named struct {
+	name     string
+	listener net.Listener
+}
+
+// Listen returns the inherited listener called name if there is one, or
+// listens on the TCP address addr, setting SO_REUSEPORT if reusePort is true
+func Listen(name, addr string, reusePort bool) (net.Listener, error) {
+	inheritOnce.Do(func() {
+		inherited, inheritErr = inherit()
+	})
+	if inheritErr != nil {
+		return nil, inheritErr
+	}
+
+	ln, ok := inherited[name]
+	if ok {
+		log.Printf("Using inherited %s listener on %s", name, ln.Addr())
+	} else {
+		var err error
+		if ln, err = listen(addr, reusePort); err != nil {
+			return nil, err
+		}
+	}
+
+	mu.Lock()
+	opened = append(opened, named{name: name, listener: ln})
+	mu.Unlock()
+	return ln, nil
+}
+
+// inherit returns the listeners passed to this process, keyed by name, and
+// removes the variables describing them from the environment so child
+// processes do not inherit them again
+func inherit() (map[string]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	count := os.Getenv("LISTEN_FDS")
+	if count == "" {
+		return nil, nil
+	}
+	// systemd sets LISTEN_PID to the process the sockets are meant for;
+	// Handover cannot know the PID in advance and leaves it unset
+	if pid := os.Getenv("LISTEN_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", count)
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make(map[string]net.Listener, n)
+	for i := 0; i < n; i++ {
+		name := strconv.Itoa(i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(listenFDsStart+i), name)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to use inherited listener %s: %w", name, err)
+		}
+		listeners[name] = ln
+	}
+	return listeners, nil
+}
+
+// Handover starts a new copy of this binary with the same arguments,
+// passing it the listeners opened so far. The new process is expected to
+// call NotifyParent once it is serving; until then this process keeps
+// serving too.
+func Handover() (*os.Process, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	mu.Lock()
+	listeners := append([]named(nil), opened...)
+	mu.Unlock()
+
+	files := make([]*os.File, 0, len(listeners))
+	names := make([]string, 0, len(listeners))
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, l := range listeners {
+		filer, ok := l.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, fmt.Errorf("%s listener cannot be handed over", l.name)
+		}
+		file, err := filer.File()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s listener file: %w", l.name, err)
+		}
+		files = append(files, file)
+		names = append(names, l.name)
+	}
+
+	env := make([]string, 0, len(os.Environ())+3)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "LISTEN_") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		"LISTEN_FDS="+strconv.Itoa(len(files)),
+		"LISTEN_FDNAMES="+strings.Join(names, ":"),
+		parentEnv+"="+strconv.Itoa(os.Getpid()),
+	)
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start new process: %w", err)
+	}
+
+	// Reap the process if it exits while this one is still running
+	go cmd.Wait()
+	return cmd.Process, nil
+}
+
+// NotifyParent tells the process that handed its listeners over, if any,
+// that this process is serving, so it can shut down
+func NotifyParent() error {
+	value := os.Getenv(parentEnv)
+	if value == "" {
+		return nil
+	}
+	os.Unsetenv(parentEnv)
+
+	pid, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", parentEnv, value)
+	}
+	parent, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find parent process %d: %w", pid, err)
+	}
+	if err := parent.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to signal parent process %d: %w", pid, err)
+	}
+	log.Printf("Took over listeners from process %d", pid)
+	return nil
+}
@@ -0,0 +1,176 @@
+package listener
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// http2Preface opens every HTTP/2 connection. gRPC clients speak HTTP/2 from
+// the first byte, while the HTTP server only speaks HTTP/1.1, so the preface
+// tells their connections apart.
+var http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// sniffTimeout bounds how long a new connection may take to send enough bytes
+// to tell which server it is for
+const sniffTimeout = 10 * time.Second
+
+// Mux shares one listener between the HTTP and gRPC servers, for deployments
+// behind load balancers that expose a single port. Connections opening with
+// the HTTP/2 preface go to gRPC and the others to HTTP. The load balancer must
+// pass connections through at the TCP level, or forward HTTP/2 as HTTP/2.
+type Mux struct {
+	root net.Listener
+	grpc *muxListener
+	http *muxListener
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewMux creates a mux over root. Serve must run for connections to be
+// handed out.
+func NewMux(root net.Listener) *Mux {
+	m := &Mux{root: root, closed: make(chan struct{})}
+	m.grpc = newMuxListener(m)
+	m.http = newMuxListener(m)
+	return m
+}
+
+// GRPC returns the listener of the gRPC server's connections
+func (m *Mux) GRPC() net.Listener {
+	return m.grpc
+}
+
+// HTTP returns the listener of the HTTP server's connections
+func (m *Mux) HTTP() net.Listener {
+	return m.http
+}
+
+// Serve accepts connections on the root listener and hands each to the
+// server it is for, until both servers have closed their listeners
+func (m *Mux) Serve() error {
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			select {
+			case <-m.closed:
+				return nil
+			default:
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		go m.route(conn)
+	}
+}
+
+// route reads the start of conn and queues it on the listener of the server
+// it is for
+func (m *Mux) route(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(sniffTimeout))
+	start := make([]byte, len(http2Preface))
+	n := 0
+	for n < len(start) && bytes.HasPrefix(http2Preface, start[:n]) {
+		read, err := conn.Read(start[n:])
+		n += read
+		if err != nil {
+			conn.Close()
+			return
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	target := m.http
+	if bytes.Equal(start[:n], http2Preface) {
+		target = m.grpc
+	}
+	target.queue(&sniffedConn{Conn: conn, reader: io.MultiReader(bytes.NewReader(start[:n]), conn)})
+}
+
+// childClosed closes the root listener once both servers have closed theirs
+func (m *Mux) childClosed() {
+	if m.grpc.isClosed() && m.http.isClosed() {
+		m.closeOnce.Do(func() {
+			close(m.closed)
+			m.root.Close()
+		})
+	}
+}
+
+// muxListener is the listener of one server sharing a mux
+type muxListener struct {
+	mux   *Mux
+	conns chan net.Conn
+
+	mu     sync.Mutex
+	done   chan struct{}
+	closed bool
+}
+
+// newMuxListener creates a listener of mux's connections
+func newMuxListener(mux *Mux) *muxListener {
+	return &muxListener{mux: mux, conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+// Accept returns the next connection for the server
+func (l *muxListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// queue hands conn to the server, closing it if the server has stopped
+// accepting
+func (l *muxListener) queue(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// Close stops the server's listener, and the root listener once the other
+// server's is closed too
+func (l *muxListener) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.done)
+	}
+	l.mu.Unlock()
+	l.mux.childClosed()
+	return nil
+}
+
+// isClosed reports whether the server closed its listener
+func (l *muxListener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// Addr returns the address of the shared listener
+func (l *muxListener) Addr() net.Addr {
+	return l.mux.root.Addr()
+}
+
+// sniffedConn replays the bytes read to route a connection before the rest
+type sniffedConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+// Read reads the replayed bytes, then the connection
+func (c *sniffedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,229 @@
+package loadtest
+
+import (
+	"bookstore-api/internal/services"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// benchPageSize is the page size the listing benchmarks request, the API's
+// default page size
+const benchPageSize = 10
+
+// Benchmark is a service call whose cost is measured
+type Benchmark struct {
+	Name string
+	// Run makes the call for iteration i with services querying db
+	Run func(ctx context.Context, db *gorm.DB, f *Fixtures, i int) error
+}
+
+// Benchmarks lists the hot service paths in the order they run
+var Benchmarks = []Benchmark{
+	{"BookService.GetAllBooks", func(ctx context.Context, db *gorm.DB, f *Fixtures, i int) error {
+		_, _, err := services.NewBookService(db).WithContext(ctx).GetAllBooks(page(i, len(f.BookIDs)), benchPageSize)
+		return err
+	}},
+	{"BookService.SearchBooks", func(ctx context.Context, db *gorm.DB, f *Fixtures, i int) error {
+		_, _, err := services.NewBookService(db).WithContext(ctx).SearchBooks(services.SearchQuery{Text: "Load Series"}, page(i, len(f.BookIDs)), benchPageSize)
+		return err
+	}},
+	{"BookService.GetBookByID", func(ctx context.Context, db *gorm.DB, f *Fixtures, i int) error {
+		_, err := services.NewBookService(db).WithContext(ctx).GetBookByID(f.BookIDs[i%len(f.BookIDs)])
+		return err
+	}},
+	{"AuthorService.GetAllAuthors", func(ctx context.Context, db *gorm.DB, f *Fixtures, i int) error {
+		_, _, err := services.NewAuthorService(db).WithContext(ctx).GetAllAuthors(page(i, len(f.AuthorIDs)), benchPageSize)
+		return err
+	}},
+	{"CategoryService.GetAllCategories", func(ctx context.Context, db *gorm.DB, f *Fixtures, i int) error {
+		_, _, err := services.NewCategoryService(db).WithContext(ctx).GetAllCategories(page(i, len(f.CategoryIDs)), benchPageSize)
+		return err
+	}},
+}
+
+// page cycles iteration i through the pages of a listing of total records
+func page(i, total int) int {
+	pages := (total + benchPageSize - 1) / benchPageSize
+	if pages < 1 {
+		pages = 1
+	}
+	return i%pages + 1
+}
+
+// Result is the measured cost of a benchmark
+type Result struct {
+	Name         string  `json:"name"`
+	N            int     `json:"n"`
+	NsPerOp      int64   `json:"ns_per_op"`
+	AllocsPerOp  int64   `json:"allocs_per_op"`
+	BytesPerOp   int64   `json:"bytes_per_op"`
+	QueriesPerOp float64 `json:"queries_per_op"`
+}
+
+// String formats a result as a go test -bench line with a queries/op column
+func (r Result) String() string {
+	return fmt.Sprintf("%-36s %10d %14d ns/op %10d B/op %8d allocs/op %8.2f queries/op",
+		r.Name, r.N, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp, r.QueriesPerOp)
+}
+
+// queryCounter counts the SQL statements run through a database
+type queryCounter struct {
+	count atomic.Int64
+}
+
+// counters holds the query counter registered on each database
+var counters sync.Map
+
+// countQueries returns the query counter for db, registering its callbacks
+// on first use
+func countQueries(db *gorm.DB) (*queryCounter, error) {
+	if c, ok := counters.Load(db.Config); ok {
+		return c.(*queryCounter), nil
+	}
+
+	counter := &queryCounter{}
+	increment := func(*gorm.DB) { counter.count.Add(1) }
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("loadtest:count", increment),
+		callbacks.Query().Before("gorm:query").Register("loadtest:count", increment),
+		callbacks.Update().Before("gorm:update").Register("loadtest:count", increment),
+		callbacks.Delete().Before("gorm:delete").Register("loadtest:count", increment),
+		callbacks.Row().Before("gorm:row").Register("loadtest:count", increment),
+		callbacks.Raw().Before("gorm:raw").Register("loadtest:count", increment),
+	} {
+		if err != nil {
+			return nil, fmt.Errorf("failed to register query counter: %w", err)
+		}
+	}
+	counters.Store(db.Config, counter)
+	return counter, nil
+}
+
+// RunBenchmarks runs every benchmark against the fixtures in the tenant in
+// ctx, for benchtime per benchmark as accepted by go test -benchtime
+func RunBenchmarks(ctx context.Context, db *gorm.DB, f *Fixtures, benchtime string) ([]Result, error) {
+	counter, err := countQueries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	testing.Init()
+	if err := flag.Set("test.benchtime", benchtime); err != nil {
+		return nil, fmt.Errorf("invalid benchtime %q: %w", benchtime, err)
+	}
+
+	results := make([]Result, 0, len(Benchmarks))
+	for _, bm := range Benchmarks {
+		// Warm up so one-off work such as preparing statements and filling
+		// the count cache is not measured
+		if err := bm.Run(ctx, db, f, 0); err != nil {
+			return nil, fmt.Errorf("%s failed: %w", bm.Name, err)
+		}
+
+		var runErr error
+		var queries int64
+		result := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			start := counter.count.Load()
+			for i := 0; i < b.N; i++ {
+				if err := bm.Run(ctx, db, f, i); err != nil {
+					runErr = err
+					b.FailNow()
+				}
+			}
+			queries = counter.count.Load() - start
+		})
+		if runErr != nil {
+			return nil, fmt.Errorf("%s failed: %w", bm.Name, runErr)
+		}
+		if result.N == 0 {
+			return nil, fmt.Errorf("%s did not run", bm.Name)
+		}
+
+		results = append(results, Result{
+			Name:         bm.Name,
+			N:            result.N,
+			NsPerOp:      result.NsPerOp(),
+			AllocsPerOp:  result.AllocsPerOp(),
+			BytesPerOp:   result.AllocedBytesPerOp(),
+			QueriesPerOp: float64(queries) / float64(result.N),
+		})
+	}
+	return results, nil
+}
+
+// Regression is a benchmark that got more expensive than its baseline
+type Regression struct {
+	Name   string
+	Metric string
+	Old    float64
+	New    float64
+}
+
+// String describes a regression
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s went from %.2f to %.2f", r.Name, r.Metric, r.Old, r.New)
+}
+
+// Compare returns the benchmarks in current that got more expensive than in
+// baseline. Any increase in queries per call is a regression, since query
+// counts do not vary between runs; time and allocations regress when they
+// grow by more than tolerance, a fraction of the baseline.
+func Compare(baseline, current []Result, tolerance float64) []Regression {
+	previous := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		previous[r.Name] = r
+	}
+
+	var regressions []Regression
+	for _, r := range current {
+		old, ok := previous[r.Name]
+		if !ok {
+			continue
+		}
+		if r.QueriesPerOp > old.QueriesPerOp+0.01 {
+			regressions = append(regressions, Regression{r.Name, "queries/op", old.QueriesPerOp, r.QueriesPerOp})
+		}
+		if float64(r.NsPerOp) > float64(old.NsPerOp)*(1+tolerance) {
+			regressions = append(regressions, Regression{r.Name, "ns/op", float64(old.NsPerOp), float64(r.NsPerOp)})
+		}
+		if float64(r.AllocsPerOp) > float64(old.AllocsPerOp)*(1+tolerance) {
+			regressions = append(regressions, Regression{r.Name, "allocs/op", float64(old.AllocsPerOp), float64(r.AllocsPerOp)})
+		}
+	}
+	return regressions
+}
+
+// LoadResults reads results saved by SaveResults
+func LoadResults(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark results: %w", err)
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark results: %w", err)
+	}
+	return results, nil
+}
+
+// SaveResults writes results to path as JSON, for use as a baseline
+func SaveResults(path string, results []Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode benchmark results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write benchmark results: %w", err)
+	}
+	return nil
+}
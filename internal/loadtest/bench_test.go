@@ -0,0 +1,99 @@
+package loadtest
+
+import (
+	"bookstore-api/internal/database"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/testutil"
+	"context"
+	"flag"
+	"os"
+	"sync"
+	"testing"
+)
+
+// benchSeed sizes the catalog the benchmarks run against, as the loadtest
+// command does by default
+var benchSeed = SeedOptions{Authors: 200, Categories: 50, Books: 5000}
+
+var (
+	// env is the database the benchmarks query, started only when
+	// benchmarks are run
+	env *testutil.Env
+
+	seedOnce sync.Once
+	fixtures *Fixtures
+	seedErr  error
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if flag.Lookup("test.bench").Value.String() == "" {
+		os.Exit(m.Run())
+	}
+	os.Exit(testutil.Main(m, &env))
+}
+
+// runBenchmark runs the benchmark named name against the seeded catalog,
+// reporting the SQL queries per call alongside time and allocations
+func runBenchmark(b *testing.B, name string) {
+	var bm *Benchmark
+	for i := range Benchmarks {
+		if Benchmarks[i].Name == name {
+			bm = &Benchmarks[i]
+		}
+	}
+	if bm == nil {
+		b.Fatalf("no benchmark named %s", name)
+	}
+
+	ctx := tenancy.WithTenantID(context.Background(), models.DefaultTenantID)
+	db := database.GetDB()
+	seedOnce.Do(func() {
+		fixtures, seedErr = Seed(ctx, db, benchSeed)
+	})
+	if seedErr != nil {
+		b.Fatal(seedErr)
+	}
+	counter, err := countQueries(db)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// Warm up so one-off work such as preparing statements and filling the
+	// count cache is not measured
+	if err := bm.Run(ctx, db, fixtures, 0); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := counter.count.Load()
+	for i := 0; i < b.N; i++ {
+		if err := bm.Run(ctx, db, fixtures, i); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(counter.count.Load()-start)/float64(b.N), "queries/op")
+}
+
+func BenchmarkBookServiceGetAllBooks(b *testing.B) {
+	runBenchmark(b, "BookService.GetAllBooks")
+}
+
+func BenchmarkBookServiceSearchBooks(b *testing.B) {
+	runBenchmark(b, "BookService.SearchBooks")
+}
+
+func BenchmarkBookServiceGetBookByID(b *testing.B) {
+	runBenchmark(b, "BookService.GetBookByID")
+}
+
+func BenchmarkAuthorServiceGetAllAuthors(b *testing.B) {
+	runBenchmark(b, "AuthorService.GetAllAuthors")
+}
+
+func BenchmarkCategoryServiceGetAllCategories(b *testing.B) {
+	runBenchmark(b, "CategoryService.GetAllCategories")
+}
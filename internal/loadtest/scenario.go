@@ -0,0 +1,272 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Scenario describes the traffic a load test sends to a running instance
+type Scenario struct {
+	// BaseURL is the instance's address, such as http://localhost:8080
+	BaseURL string
+	// Tenant is sent in TenantHeader when set
+	Tenant       string
+	TenantHeader string
+	// VUs and Duration size a k6 run
+	VUs      int
+	Duration time.Duration
+	// Requests is the number of targets in a vegeta target list
+	Requests int
+	// Sample holds the records requests are made for
+	Sample Sample
+}
+
+// Sample is the records of an instance a scenario requests
+type Sample struct {
+	BookIDs     []string `json:"book_ids"`
+	AuthorIDs   []string `json:"author_ids"`
+	CategoryIDs []string `json:"category_ids"`
+	SearchTerms []string `json:"search_terms"`
+}
+
+// endpoint is a read path and its share of a scenario's traffic
+type endpoint struct {
+	name   string
+	weight int
+	// path returns a request path below /api/v1, or "" if the sample has no
+	// records for the endpoint
+	path func(s Sample, r *rand.Rand) string
+}
+
+// endpoints weights the read paths roughly as the storefront calls them:
+// mostly listings and book pages, with the Preload-heavy author and category
+// listings kept in the mix
+var endpoints = []endpoint{
+	{"list books", 20, func(s Sample, r *rand.Rand) string {
+		return fmt.Sprintf("/books?page=%d&limit=10", r.Intn(5)+1)
+	}},
+	{"search books", 20, func(s Sample, r *rand.Rand) string {
+		if len(s.SearchTerms) == 0 {
+			return ""
+		}
+		return "/books/search?q=" + url.QueryEscape(s.SearchTerms[r.Intn(len(s.SearchTerms))])
+	}},
+	{"get book", 30, func(s Sample, r *rand.Rand) string {
+		if len(s.BookIDs) == 0 {
+			return ""
+		}
+		return "/books/" + s.BookIDs[r.Intn(len(s.BookIDs))]
+	}},
+	{"books by author", 5, func(s Sample, r *rand.Rand) string {
+		if len(s.AuthorIDs) == 0 {
+			return ""
+		}
+		return "/books/author/" + s.AuthorIDs[r.Intn(len(s.AuthorIDs))]
+	}},
+	{"books by category", 5, func(s Sample, r *rand.Rand) string {
+		if len(s.CategoryIDs) == 0 {
+			return ""
+		}
+		return "/books/category/" + s.CategoryIDs[r.Intn(len(s.CategoryIDs))]
+	}},
+	{"list authors", 10, func(s Sample, r *rand.Rand) string {
+		return fmt.Sprintf("/authors?page=%d&limit=10", r.Intn(3)+1)
+	}},
+	{"list categories", 10, func(s Sample, r *rand.Rand) string {
+		return fmt.Sprintf("/categories?page=%d&limit=10", r.Intn(3)+1)
+	}},
+}
+
+// WriteVegeta writes s as a vegeta target list in the HTTP format, the
+// endpoints drawn at random by weight
+func WriteVegeta(w io.Writer, s Scenario) error {
+	if s.Requests < 1 {
+		return fmt.Errorf("requests must be at least 1")
+	}
+	base := strings.TrimRight(s.BaseURL, "/") + "/api/v1"
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	total := 0
+	for _, e := range endpoints {
+		total += e.weight
+	}
+	for written := 0; written < s.Requests; {
+		pick := r.Intn(total)
+		for _, e := range endpoints {
+			if pick >= e.weight {
+				pick -= e.weight
+				continue
+			}
+			path := e.path(s.Sample, r)
+			if path == "" {
+				break
+			}
+			if _, err := fmt.Fprintf(w, "GET %s%s\n", base, path); err != nil {
+				return err
+			}
+			if s.Tenant != "" {
+				if _, err := fmt.Fprintf(w, "%s: %s\n", s.TenantHeader, s.Tenant); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+			written++
+			break
+		}
+	}
+	return nil
+}
+
+// WriteK6 writes s as a k6 script. Each iteration picks an endpoint by
+// weight, and the run fails if more than 1% of requests fail or the 95th
+// percentile latency of any endpoint exceeds 500ms.
+func WriteK6(w io.Writer, s Scenario) error {
+	if s.VUs < 1 {
+		return fmt.Errorf("vus must be at least 1")
+	}
+	// Encode empty lists as [] rather than null so the script can check
+	// their length
+	for _, list := range []*[]string{&s.Sample.BookIDs, &s.Sample.AuthorIDs, &s.Sample.CategoryIDs, &s.Sample.SearchTerms} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	sample, err := json.MarshalIndent(s.Sample, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sample: %w", err)
+	}
+	headers := map[string]string{}
+	if s.Tenant != "" {
+		headers[s.TenantHeader] = s.Tenant
+	}
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode headers: %w", err)
+	}
+
+	var script strings.Builder
+	fmt.Fprintf(&script, "// Generated by cmd/loadtest; regenerate rather than editing.\n")
+	fmt.Fprintf(&script, "import http from 'k6/http';\nimport { check } from 'k6';\n\n")
+	fmt.Fprintf(&script, "const baseURL = __ENV.BASE_URL || %q;\n", strings.TrimRight(s.BaseURL, "/")+"/api/v1")
+	fmt.Fprintf(&script, "const params = { headers: %s };\n", encodedHeaders)
+	fmt.Fprintf(&script, "const sample = %s;\n\n", sample)
+
+	fmt.Fprintf(&script, "export const options = {\n  vus: %d,\n  duration: '%s',\n  thresholds: {\n    http_req_failed: ['rate<0.01'],\n", s.VUs, s.Duration)
+	for _, e := range endpoints {
+		fmt.Fprintf(&script, "    'http_req_duration{endpoint:%s}': ['p(95)<500'],\n", e.name)
+	}
+	fmt.Fprintf(&script, "  },\n};\n\n")
+
+	fmt.Fprintf(&script, "const pick = (list) => list[Math.floor(Math.random() * list.length)];\n")
+	fmt.Fprintf(&script, "const page = (max) => Math.floor(Math.random() * max) + 1;\n\n")
+	fmt.Fprintf(&script, "const endpoints = [\n")
+	for _, e := range endpoints {
+		fmt.Fprintf(&script, "  { name: %q, weight: %d, path: %s },\n", e.name, e.weight, k6Paths[e.name])
+	}
+	fmt.Fprintf(&script, "].filter((e) => e.path() !== null);\n")
+	fmt.Fprintf(&script, "const totalWeight = endpoints.reduce((sum, e) => sum + e.weight, 0);\n\n")
+
+	fmt.Fprintf(&script, `export default function () {
+  let roll = Math.random() * totalWeight;
+  const endpoint = endpoints.find((e) => (roll -= e.weight) < 0) || endpoints[0];
+  const res = http.get(baseURL + endpoint.path(), Object.assign({ tags: { endpoint: endpoint.name } }, params));
+  check(res, { 'status is 200': (r) => r.status === 200 });
+}
+`)
+
+	_, err = io.WriteString(w, script.String())
+	return err
+}
+
+// k6Paths are the JavaScript equivalents of the endpoints' path functions,
+// returning null when the sample has no records for the endpoint
+var k6Paths = map[string]string{
+	"list books":        "() => `/books?page=${page(5)}&limit=10`",
+	"search books":      "() => sample.search_terms.length ? `/books/search?q=${encodeURIComponent(pick(sample.search_terms))}` : null",
+	"get book":          "() => sample.book_ids.length ? `/books/${pick(sample.book_ids)}` : null",
+	"books by author":   "() => sample.author_ids.length ? `/books/author/${pick(sample.author_ids)}` : null",
+	"books by category": "() => sample.category_ids.length ? `/books/category/${pick(sample.category_ids)}` : null",
+	"list authors":      "() => `/authors?page=${page(3)}&limit=10`",
+	"list categories":   "() => `/categories?page=${page(3)}&limit=10`",
+}
+
+// FetchSample reads up to limit books, authors, and categories from a
+// running instance, and takes search terms from the book titles
+func FetchSample(client *http.Client, s Scenario, limit int) (Sample, error) {
+	base := strings.TrimRight(s.BaseURL, "/") + "/api/v1"
+	var sample Sample
+
+	var books []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := fetch(client, s, fmt.Sprintf("%s/books?limit=%d", base, limit), &books); err != nil {
+		return sample, err
+	}
+	terms := make(map[string]bool)
+	for _, b := range books {
+		sample.BookIDs = append(sample.BookIDs, b.ID)
+		if words := strings.Fields(b.Title); len(words) > 0 && !terms[words[0]] {
+			terms[words[0]] = true
+			sample.SearchTerms = append(sample.SearchTerms, words[0])
+		}
+	}
+
+	for _, list := range []struct {
+		path string
+		ids  *[]string
+	}{
+		{"/authors", &sample.AuthorIDs},
+		{"/categories", &sample.CategoryIDs},
+	} {
+		var records []struct {
+			ID string `json:"id"`
+		}
+		if err := fetch(client, s, fmt.Sprintf("%s%s?limit=%d", base, list.path, limit), &records); err != nil {
+			return sample, err
+		}
+		for _, r := range records {
+			*list.ids = append(*list.ids, r.ID)
+		}
+	}
+	return sample, nil
+}
+
+// fetch gets a listing and decodes its data into v
+func fetch(client *http.Client, s Scenario, target string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	if s.Tenant != "" {
+		req.Header.Set(s.TenantHeader, s.Tenant)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: %s", target, resp.Status)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", target, err)
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package mail
+
+import (
+	"bookstore-api/internal/config"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// TraceHeader is the header carrying the trace ID of the request a message
+// is sent for, so support can find the request's log lines from the email
+const TraceHeader = "X-Bookstore-Trace-Id"
+
+// Message is an email with optional attachments
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+	// TraceID is sent as the TraceHeader header when set
+	TraceID string
+}
+
+// Mailer sends email through an SMTP server
+type Mailer struct {
+	cfg config.MailConfig
+}
+
+// NewMailer creates a new SMTP mailer
+func NewMailer(cfg config.MailConfig) *Mailer {
+	return &Mailer{cfg: cfg}
+}
+
+// Enabled reports whether an SMTP server is configured
+func (m *Mailer) Enabled() bool {
+	return m.cfg.SMTPHost != ""
+}
+
+// Send delivers a message to all recipients
+func (m *Mailer) Send(msg *Message) error {
+	if !m.Enabled() {
+		return fmt.Errorf("smtp is not configured")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+
+	data, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.cfg.SMTPHost, m.cfg.SMTPPort)
+	var auth smtp.Auth
+	if m.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+	}
+	if err := smtp.SendMail(addr, auth, m.cfg.From, msg.To, data); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// build encodes the message as MIME, using multipart/mixed when there are attachments
+func (m *Mailer) build(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	header("From", m.cfg.From)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	if msg.TraceID != "" {
+		header(TraceHeader, msg.TraceID)
+	}
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.Body))
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(&buf, []byte(msg.Body))
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		header("Content-Type", contentType)
+		header("Content-Transfer-Encoding", "base64")
+		header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+		buf.WriteString("\r\n")
+		writeBase64(&buf, attachment.Data)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64 encoded in 76 character lines
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+}
+
+// newBoundary returns a random MIME boundary
+func newBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate mime boundary: %w", err)
+	}
+	return "bookstore-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,135 @@
+// Package maintenance runs the admin maintenance operations that take too
+// long for a request, rebuilding search indexes and flushing caches, as
+// background jobs that report their progress.
+package maintenance
+
+import (
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/metadata"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"context"
+)
+
+// Job types of maintenance jobs. They take no payload.
+const (
+	ReindexJobType    = "maintenance.reindex"
+	CacheFlushJobType = "maintenance.cache_flush"
+)
+
+// Reindexer is the job handler that rebuilds the indexes of the searched
+// tables and, when the read models are enabled, every tenant's book listings
+type Reindexer struct {
+	queue       *jobs.Queue
+	maintenance *services.MaintenanceService
+	tenants     *services.TenantService
+	// listings is nil unless the read models are enabled
+	listings *services.ListingService
+}
+
+// NewReindexer creates a new reindexer
+func NewReindexer(queue *jobs.Queue, svc *services.Services) *Reindexer {
+	return &Reindexer{
+		queue:       queue,
+		maintenance: svc.Maintenance,
+		tenants:     svc.Tenants,
+		listings:    svc.Listings,
+	}
+}
+
+// Handle reindexes each searched table, then rebuilds the listings of each
+// active tenant, reporting progress as it goes
+func (r *Reindexer) Handle(ctx context.Context, job *models.Job) error {
+	var tenants []models.Tenant
+	if r.listings != nil {
+		var err error
+		tenants, err = r.tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+	}
+	progress := models.JobProgress{Step: "tables", Total: len(services.SearchTables) + len(tenants)}
+
+	maintenance := r.maintenance.WithContext(ctx)
+	for _, table := range services.SearchTables {
+		if err := r.queue.ReportProgress(job, progress); err != nil {
+			return err
+		}
+		if err := maintenance.ReindexTable(table); err != nil {
+			return err
+		}
+		progress.Done++
+	}
+
+	progress.Step = "listings"
+	for i := range tenants {
+		if err := r.queue.ReportProgress(job, progress); err != nil {
+			return err
+		}
+		tenant := &tenants[i]
+		if _, err := r.listings.WithContext(tenancy.WithTenant(ctx, tenant)).Rebuild(); err != nil {
+			return err
+		}
+		progress.Done++
+	}
+
+	progress.Step = "done"
+	return r.queue.ReportProgress(job, progress)
+}
+
+// CacheFlusher is the job handler that drops the in-memory caches: cached
+// pagination counts, resolved tenants, and book metadata lookups. Caches are
+// held by each server process, so the job flushes those of the process whose
+// worker runs it.
+type CacheFlusher struct {
+	queue  *jobs.Queue
+	counts *services.CountCache
+	lookup *metadata.Lookup
+}
+
+// NewCacheFlusher creates a new cache flusher. lookup may be nil when book
+// metadata lookups are not used.
+func NewCacheFlusher(queue *jobs.Queue, lookup *metadata.Lookup) *CacheFlusher {
+	return &CacheFlusher{
+		queue:  queue,
+		counts: services.GetCountCache(),
+		lookup: lookup,
+	}
+}
+
+// Handle flushes each cache in turn, reporting progress as it goes
+func (f *CacheFlusher) Handle(ctx context.Context, job *models.Job) error {
+	flushes := []struct {
+		step  string
+		flush func() int
+	}{
+		{"counts", f.counts.Flush},
+		{"tenants", func() int {
+			tenancy.GetResolver().Invalidate()
+			return 0
+		}},
+		{"metadata", func() int {
+			if f.lookup == nil {
+				return 0
+			}
+			return f.lookup.Flush()
+		}},
+	}
+
+	progress := models.JobProgress{Total: len(flushes)}
+	flushed := make(map[string]int)
+	for _, cache := range flushes {
+		progress.Step = cache.step
+		if err := f.queue.ReportProgress(job, progress); err != nil {
+			return err
+		}
+		flushed[cache.step] = cache.flush()
+		progress.Done++
+	}
+
+	utils.LogInfo("Caches flushed", flushed)
+	progress.Step = "done"
+	return f.queue.ReportProgress(job, progress)
+}
@@ -0,0 +1,102 @@
+package marketing
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/mail"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/tracing"
+	"bookstore-api/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobType is the background job type that sends a marketing email to a user
+const JobType = "marketing.email"
+
+// Payload is the payload of marketing email jobs
+type Payload struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+}
+
+// EnqueueTx queues a marketing email in the transaction tx. Marketing email
+// must only be sent this way: consent is checked when the job runs, so an
+// email queued before the user withdrew consent is not sent.
+func EnqueueTx(tx *gorm.DB, payload Payload) error {
+	_, err := jobs.GetQueue().EnqueueTx(tx, JobType, payload)
+	return err
+}
+
+// Sender is the job handler that sends marketing email to users who agreed to
+// receive it. Emails to users without consent are dropped and logged.
+type Sender struct {
+	userService       *services.UserService
+	preferenceService *services.PreferenceService
+	mailer            *mail.Mailer
+}
+
+// NewSender creates a new marketing email sender
+func NewSender(cfg *config.Config, svc *services.Services) *Sender {
+	return &Sender{
+		userService:       svc.Users,
+		preferenceService: svc.Preferences,
+		mailer:            mail.NewMailer(cfg.Mail),
+	}
+}
+
+// Handle sends the email in the job if its user currently consents to marketing email
+func (s *Sender) Handle(ctx context.Context, job *models.Job) error {
+	var payload Payload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid marketing job payload: %w", err)
+	}
+
+	tenant, err := tenancy.GetResolver().Get(payload.TenantID)
+	if err != nil {
+		if errors.Is(err, tenancy.ErrUnknownTenant) {
+			return nil
+		}
+		return err
+	}
+	tenantCtx := tenancy.WithTenant(ctx, tenant)
+
+	consent, err := s.preferenceService.WithContext(tenantCtx).HasMarketingConsent(payload.UserID)
+	if err != nil {
+		return err
+	}
+	if !consent {
+		utils.LogInfo("Marketing email not sent without consent", map[string]interface{}{
+			"tenant":  tenant.Slug,
+			"user_id": payload.UserID,
+			"subject": payload.Subject,
+		})
+		return nil
+	}
+
+	user, err := s.userService.WithContext(tenantCtx).GetUserByID(payload.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+	if user.Email == nil || *user.Email == "" {
+		return nil
+	}
+
+	return s.mailer.Send(&mail.Message{
+		To:      []string{*user.Email},
+		Subject: payload.Subject,
+		Body:    payload.Body,
+		TraceID: tracing.TraceID(ctx),
+	})
+}
@@ -0,0 +1,34 @@
+package media
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a recognized document format
+type Document struct {
+	ContentType string
+	// Extension is the file name extension of the format, with its dot
+	Extension string
+}
+
+// epubSignature is found at offset 30 of an EPUB file: the archive must start
+// with an uncompressed "mimetype" entry holding the EPUB media type
+const epubSignature = "mimetypeapplication/epub+zip"
+
+// DetectDocument recognizes a PDF, EPUB, or UTF-8 plain text document from
+// its content. ok is false for any other content.
+func DetectDocument(data []byte) (doc Document, ok bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return Document{ContentType: "application/pdf", Extension: ".pdf"}, true
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) && len(data) >= 30+len(epubSignature) &&
+		string(data[30:30+len(epubSignature)]) == epubSignature:
+		return Document{ContentType: "application/epub+zip", Extension: ".epub"}, true
+	case len(data) > 0 && utf8.Valid(data) && strings.HasPrefix(http.DetectContentType(data), "text/plain"):
+		return Document{ContentType: "text/plain; charset=utf-8", Extension: ".txt"}, true
+	}
+	return Document{}, false
+}
@@ -0,0 +1,111 @@
+// Package media checks uploaded media: it decodes images and renders the sizes
+// the API serves, and recognizes the document formats of book previews.
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+)
+
+// MaxPixels bounds the dimensions of an uploaded image, so a small file
+// cannot decode into an image too large to hold in memory
+const MaxPixels = 24_000_000
+
+// Size is a rendition of an image, scaled down to fit a square
+type Size struct {
+	Name string
+	// Max is the largest width or height of the rendition in pixels
+	Max int
+}
+
+// PhotoSizes are the sizes author photos are stored in
+var PhotoSizes = []Size{
+	{Name: "thumbnail", Max: 96},
+	{Name: "medium", Max: 320},
+	{Name: "large", Max: 800},
+}
+
+// Decode decodes a JPEG, PNG, or GIF image
+func Decode(data []byte) (image.Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: expected JPEG, PNG, or GIF")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("unsupported image: %dx%d %s exceeds %d pixels", config.Width, config.Height, format, MaxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: %w", err)
+	}
+	return img, nil
+}
+
+// Render scales img down to each size and encodes the results as JPEG, keyed
+// by size name. Images smaller than a size are not scaled up.
+func Render(img image.Image, sizes []Size) (map[string][]byte, error) {
+	flat := flatten(img)
+	renditions := make(map[string][]byte, len(sizes))
+	for _, size := range sizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, fit(flat, size.Max), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("failed to encode %s image: %w", size.Name, err)
+		}
+		renditions[size.Name] = buf.Bytes()
+	}
+	return renditions, nil
+}
+
+// flatten draws img over a white background, as JPEG has no transparency
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+	return flat
+}
+
+// fit scales src down to fit within limit x limit pixels, averaging the source
+// pixels that fall within each destination pixel
+func fit(src *image.RGBA, limit int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if sw <= limit && sh <= limit {
+		return src
+	}
+	w, h := limit, limit
+	if sw >= sh {
+		h = max(1, sh*limit/sw)
+	} else {
+		w = max(1, sw*limit/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					i += 4
+					n++
+				}
+			}
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = 0xff
+		}
+	}
+	return dst
+}
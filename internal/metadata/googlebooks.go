@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// googleBooksURL is the Google Books volumes search endpoint
+const googleBooksURL = "https://www.googleapis.com/books/v1/volumes"
+
+// GoogleBooksProvider looks up books with the Google Books API
+type GoogleBooksProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// googleBooksResponse is the subset of a Google Books volumes response used here
+type googleBooksResponse struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo struct {
+			Title         string   `json:"title"`
+			Subtitle      string   `json:"subtitle"`
+			Authors       []string `json:"authors"`
+			Publisher     string   `json:"publisher"`
+			PublishedDate string   `json:"publishedDate"`
+			Description   string   `json:"description"`
+			PageCount     int      `json:"pageCount"`
+			ImageLinks    struct {
+				SmallThumbnail string `json:"smallThumbnail"`
+				Thumbnail      string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+// NewGoogleBooksProvider creates a Google Books provider. The API key is optional
+// but raises the daily quota.
+func NewGoogleBooksProvider(apiKey string, timeout time.Duration) *GoogleBooksProvider {
+	return &GoogleBooksProvider{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the provider name
+func (p *GoogleBooksProvider) Name() string {
+	return ProviderGoogleBooks
+}
+
+// Lookup searches volumes by ISBN and returns the first match
+func (p *GoogleBooksProvider) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	query := url.Values{"q": {"isbn:" + isbn}, "maxResults": {"1"}}
+	if p.apiKey != "" {
+		query.Set("key", p.apiKey)
+	}
+
+	var response googleBooksResponse
+	if err := getJSON(ctx, p.client, googleBooksURL+"?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+	if response.TotalItems == 0 || len(response.Items) == 0 || response.Items[0].VolumeInfo.Title == "" {
+		return nil, ErrNotFound
+	}
+
+	info := response.Items[0].VolumeInfo
+	metadata := &BookMetadata{
+		ISBN:        isbn,
+		Title:       info.Title,
+		Subtitle:    info.Subtitle,
+		Description: strings.TrimSpace(info.Description),
+		Authors:     []Author{},
+		Publisher:   info.Publisher,
+		PublishedAt: parseDate(info.PublishedDate),
+		PageCount:   info.PageCount,
+		Source:      ProviderGoogleBooks,
+	}
+	for _, name := range info.Authors {
+		if name = strings.TrimSpace(name); name != "" {
+			metadata.Authors = append(metadata.Authors, Author{Name: name})
+		}
+	}
+
+	cover := info.ImageLinks.Thumbnail
+	if cover == "" {
+		cover = info.ImageLinks.SmallThumbnail
+	}
+	metadata.CoverURL = strings.Replace(cover, "http://", "https://", 1)
+	return metadata, nil
+}
@@ -0,0 +1,250 @@
+package metadata
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider names
+const (
+	ProviderOpenLibrary = "openlibrary"
+	ProviderGoogleBooks = "googlebooks"
+)
+
+// maxCacheEntries bounds the number of cached lookups
+const maxCacheEntries = 1000
+
+// ErrNotFound is returned when no provider knows the ISBN
+var ErrNotFound = errors.New("book metadata not found")
+
+// errRateLimited is returned by a provider whose request budget is spent
+var errRateLimited = errors.New("provider rate limit reached")
+
+var (
+	lookup *Lookup
+	once   sync.Once
+)
+
+// Author is an author credited in book metadata
+type Author struct {
+	Name string `json:"name"`
+}
+
+// BookMetadata is catalog data for a book fetched from an external provider
+type BookMetadata struct {
+	ISBN        string     `json:"isbn"`
+	Title       string     `json:"title"`
+	Subtitle    string     `json:"subtitle,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Authors     []Author   `json:"authors"`
+	Publisher   string     `json:"publisher,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	PageCount   int        `json:"page_count,omitempty"`
+	CoverURL    string     `json:"cover_url,omitempty"`
+	Source      string     `json:"source"`
+}
+
+// Provider fetches book metadata by ISBN-13 from an external service
+type Provider interface {
+	Name() string
+	Lookup(ctx context.Context, isbn string) (*BookMetadata, error)
+}
+
+// cacheEntry is a cached lookup result; a nil metadata caches a miss
+type cacheEntry struct {
+	metadata  *BookMetadata
+	expiresAt time.Time
+}
+
+// Lookup queries providers in order, returning the first match. Results, including
+// misses, are cached so repeated lookups do not hit external APIs, and each provider
+// is rate limited to stay within its usage policy.
+type Lookup struct {
+	providers []Provider
+	limiters  map[string]*limiter
+	ttl       time.Duration
+	mu        sync.Mutex
+	cache     map[string]cacheEntry
+}
+
+// NewLookup creates a lookup for the configured providers
+func NewLookup(cfg config.MetadataConfig) (*Lookup, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	l := &Lookup{
+		limiters: make(map[string]*limiter),
+		ttl:      ttl,
+		cache:    make(map[string]cacheEntry),
+	}
+	for _, name := range strings.Split(cfg.Providers, ",") {
+		var provider Provider
+		switch name = strings.TrimSpace(strings.ToLower(name)); name {
+		case "":
+			continue
+		case ProviderOpenLibrary:
+			provider = NewOpenLibraryProvider(timeout)
+		case ProviderGoogleBooks:
+			provider = NewGoogleBooksProvider(cfg.GoogleBooksAPIKey, timeout)
+		default:
+			return nil, fmt.Errorf("unknown metadata provider %q", name)
+		}
+		l.providers = append(l.providers, provider)
+		l.limiters[provider.Name()] = newLimiter(cfg.RateLimit, time.Minute)
+	}
+	return l, nil
+}
+
+// InitializeLookup initializes the shared metadata lookup from configuration
+func InitializeLookup(cfg *config.Config) error {
+	var err error
+	once.Do(func() {
+		lookup, err = NewLookup(cfg.Metadata)
+	})
+	return err
+}
+
+// GetLookup returns the shared metadata lookup
+func GetLookup() *Lookup {
+	if lookup == nil {
+		log.Fatal("Metadata lookup not initialized. Call InitializeLookup first.")
+	}
+	return lookup
+}
+
+// Lookup returns metadata for a normalized ISBN-13. Providers that fail or are
+// rate limited are skipped; if none answered, the last error is returned.
+func (l *Lookup) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	if entry, ok := l.cached(isbn); ok {
+		if entry.metadata == nil {
+			return nil, ErrNotFound
+		}
+		return entry.metadata, nil
+	}
+	if len(l.providers) == 0 {
+		return nil, fmt.Errorf("no metadata providers configured")
+	}
+
+	var lastErr error
+	answered := false
+	for _, provider := range l.providers {
+		if !l.limiters[provider.Name()].Allow() {
+			lastErr = fmt.Errorf("%s: %w", provider.Name(), errRateLimited)
+			continue
+		}
+
+		metadata, err := provider.Lookup(ctx, isbn)
+		if err == nil {
+			l.store(isbn, metadata)
+			return metadata, nil
+		}
+		if errors.Is(err, ErrNotFound) {
+			answered = true
+			continue
+		}
+		log.Printf("Metadata lookup via %s failed for %s: %v", provider.Name(), isbn, err)
+		lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+	}
+
+	// Only cache a miss when every provider answered, so outages are retried
+	if answered && lastErr == nil {
+		l.store(isbn, nil)
+		return nil, ErrNotFound
+	}
+	if lastErr == nil {
+		return nil, ErrNotFound
+	}
+	return nil, lastErr
+}
+
+// IsRateLimited reports whether err means all providers were rate limited
+func IsRateLimited(err error) bool {
+	return errors.Is(err, errRateLimited)
+}
+
+// cached returns the unexpired cache entry for the ISBN
+func (l *Lookup) cached(isbn string) (cacheEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.cache[isbn]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Flush drops every cached lookup and returns the number dropped
+func (l *Lookup) Flush() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	flushed := len(l.cache)
+	l.cache = make(map[string]cacheEntry)
+	return flushed
+}
+
+// store caches a lookup result, evicting expired entries when the cache is full
+func (l *Lookup) store(isbn string, metadata *BookMetadata) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if len(l.cache) >= maxCacheEntries {
+		for key, entry := range l.cache {
+			if now.After(entry.expiresAt) {
+				delete(l.cache, key)
+			}
+		}
+		if len(l.cache) >= maxCacheEntries {
+			l.cache = make(map[string]cacheEntry)
+		}
+	}
+	l.cache[isbn] = cacheEntry{metadata: metadata, expiresAt: now.Add(l.ttl)}
+}
+
+// limiter allows at most max requests per window
+type limiter struct {
+	max         int
+	window      time.Duration
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+}
+
+// newLimiter creates a fixed-window limiter; a non-positive max disables limiting
+func newLimiter(max int, window time.Duration) *limiter {
+	return &limiter{max: max, window: window}
+}
+
+// Allow reports whether a request may be made now, counting it if so
+func (l *limiter) Allow() bool {
+	if l.max <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.windowStart) >= l.window {
+		l.windowStart = now
+		l.count = 0
+	}
+	if l.count >= l.max {
+		return false
+	}
+	l.count++
+	return true
+}
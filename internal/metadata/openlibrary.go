@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// openLibraryURL is the Open Library Books API endpoint
+const openLibraryURL = "https://openlibrary.org/api/books"
+
+// OpenLibraryProvider looks up books with the Open Library Books API
+type OpenLibraryProvider struct {
+	client *http.Client
+}
+
+// openLibraryBook is the subset of an Open Library details response used here
+type openLibraryBook struct {
+	Details struct {
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"`
+		Authors  []struct {
+			Name string `json:"name"`
+		} `json:"authors"`
+		Publishers    []string        `json:"publishers"`
+		PublishDate   string          `json:"publish_date"`
+		NumberOfPages int             `json:"number_of_pages"`
+		Description   json.RawMessage `json:"description"`
+		Covers        []int           `json:"covers"`
+	} `json:"details"`
+}
+
+// NewOpenLibraryProvider creates an Open Library provider
+func NewOpenLibraryProvider(timeout time.Duration) *OpenLibraryProvider {
+	return &OpenLibraryProvider{client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns the provider name
+func (p *OpenLibraryProvider) Name() string {
+	return ProviderOpenLibrary
+}
+
+// Lookup fetches book details by ISBN
+func (p *OpenLibraryProvider) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"details"}}
+
+	var response map[string]openLibraryBook
+	if err := getJSON(ctx, p.client, openLibraryURL+"?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+	book, ok := response[key]
+	if !ok || book.Details.Title == "" {
+		return nil, ErrNotFound
+	}
+
+	details := book.Details
+	metadata := &BookMetadata{
+		ISBN:        isbn,
+		Title:       details.Title,
+		Subtitle:    details.Subtitle,
+		Description: openLibraryText(details.Description),
+		Authors:     []Author{},
+		PublishedAt: parseDate(details.PublishDate),
+		PageCount:   details.NumberOfPages,
+		Source:      ProviderOpenLibrary,
+	}
+	for _, author := range details.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			metadata.Authors = append(metadata.Authors, Author{Name: name})
+		}
+	}
+	if len(details.Publishers) > 0 {
+		metadata.Publisher = details.Publishers[0]
+	}
+	if len(details.Covers) > 0 {
+		metadata.CoverURL = fmt.Sprintf("https://covers.openlibrary.org/b/isbn/%s-L.jpg", isbn)
+	}
+	return metadata, nil
+}
+
+// openLibraryText decodes an Open Library text field, which is either a plain
+// string or a {"type": "/type/text", "value": "..."} object
+func openLibraryText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var typed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &typed); err == nil {
+		return strings.TrimSpace(typed.Value)
+	}
+	return ""
+}
@@ -1,78 +1,224 @@
 package middleware
 
 import (
+	"bookstore-api/internal/access"
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
+// OwnerFunc reports whether the user owns the resource addressed by the request
+type OwnerFunc func(c *fiber.Ctx, userID uuid.UUID) (bool, error)
+
 // AuthMiddleware handles authentication
-type AuthMiddleware struct{}
+type AuthMiddleware struct {
+	issuer   *auth.Issuer
+	sessions *services.SessionService
+	apiKeys  *services.APIKeyService
+}
 
-// NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware() *AuthMiddleware {
-	return &AuthMiddleware{}
+// NewAuthMiddleware creates a new auth middleware checking sessions and API
+// keys with svc
+func NewAuthMiddleware(svc *services.Services) *AuthMiddleware {
+	return &AuthMiddleware{
+		issuer:   auth.GetIssuer(),
+		sessions: svc.Sessions,
+		apiKeys:  svc.APIKeys,
+	}
 }
 
-// RequireAuth middleware that requires authentication
+// RequireAuth requires an access token issued by this service or an API key;
+// any other bearer token is rejected
 func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// For now, this is a placeholder - in a real app you'd validate JWT tokens
-		// or session cookies here
-		
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Authorization header required",
-			})
+			return response.Error(c, fiber.StatusUnauthorized, "Authorization header required", nil)
 		}
 
 		// Check if it's a Bearer token
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid authorization format. Expected 'Bearer <token>'",
-			})
+			return response.Error(c, fiber.StatusUnauthorized, "Invalid authorization format. Expected 'Bearer <token>'", nil)
 		}
 
 		// Extract token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Token required",
-			})
+			return response.Error(c, fiber.StatusUnauthorized, "Token required", nil)
 		}
 
-		// TODO: Validate token with your auth service
-		// For now, we'll just check if it's not empty
-		if len(token) < 10 {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid token",
-			})
+		// Tokens issued by social login carry the signed-in user
+		if auth.LooksLikeToken(token) {
+			claims, err := m.verify(c, token)
+			if err != nil {
+				if err == auth.ErrInvalidToken {
+					return response.Error(c, fiber.StatusUnauthorized, "Invalid, expired, or revoked token", nil)
+				}
+				return response.Error(c, fiber.StatusInternalServerError, "Failed to verify token", err)
+			}
+			setUser(c, claims)
+			return c.Next()
 		}
 
-		// Store user info in context (placeholder)
-		c.Locals("user_id", "user_123")
-		c.Locals("user_role", "admin")
+		// API keys act with the role they were created with
+		if auth.LooksLikeAPIKey(token) {
+			apiKey, err := m.verifyAPIKey(c, token)
+			if err != nil {
+				if err == auth.ErrInvalidToken {
+					return response.Error(c, fiber.StatusUnauthorized, "Invalid, expired, or revoked API key", nil)
+				}
+				return response.Error(c, fiber.StatusInternalServerError, "Failed to verify API key", err)
+			}
+			setAPIKey(c, apiKey)
+			return c.Next()
+		}
 
-		return c.Next()
+		return response.Error(c, fiber.StatusUnauthorized, "Invalid token", nil)
 	}
 }
 
-// OptionalAuth middleware that optionally validates authentication
+// OptionalAuth identifies the user or API key of a valid bearer token; requests
+// without one, or with any other token, continue unauthenticated
 func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
 			token := strings.TrimPrefix(authHeader, "Bearer ")
-			if len(token) >= 10 {
-				c.Locals("user_id", "user_123")
-				c.Locals("user_role", "admin")
+			if auth.LooksLikeToken(token) {
+				if claims, err := m.verify(c, token); err == nil {
+					setUser(c, claims)
+				}
+			} else if auth.LooksLikeAPIKey(token) {
+				if apiKey, err := m.verifyAPIKey(c, token); err == nil {
+					setAPIKey(c, apiKey)
+				}
 			}
 		}
 		return c.Next()
 	}
 }
+
+// RequireRole allows only users with one of the given roles. It must run after RequireAuth.
+func (m *AuthMiddleware) RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("user_role").(string)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+		return response.Error(c, fiber.StatusForbidden, "Insufficient permissions", nil)
+	}
+}
+
+// RequireAdminOrOwner allows admins, and other users only for resources they
+// own. It must run after RequireAuth.
+func (m *AuthMiddleware) RequireAdminOrOwner(owns OwnerFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if role, _ := c.Locals("user_role").(string); role == models.RoleAdmin {
+			return c.Next()
+		}
+
+		// API keys carry no user, so they cannot own anything
+		subject, _ := c.Locals("user_id").(string)
+		userID, err := uuid.Parse(subject)
+		if err == nil {
+			var owner bool
+			owner, err = owns(c, userID)
+			if err != nil {
+				return response.Error(c, fiber.StatusInternalServerError, "Failed to check permissions", err)
+			}
+			if owner {
+				return c.Next()
+			}
+		}
+
+		return response.Error(c, fiber.StatusForbidden, "Insufficient permissions", nil)
+	}
+}
+
+// RequireWebSocketAuth requires authentication for WebSocket upgrades. Browsers
+// cannot set headers on WebSocket requests, so the token may also be passed as
+// the "token" query parameter.
+func (m *AuthMiddleware) RequireWebSocketAuth() fiber.Handler {
+	requireAuth := m.RequireAuth()
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request().Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		return requireAuth(c)
+	}
+}
+
+// verify parses an issued access token and rejects it if it was issued by
+// another tenant or its session was revoked
+func (m *AuthMiddleware) verify(c *fiber.Ctx, token string) (*auth.Claims, error) {
+	claims, err := m.issuer.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := claims.Session()
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+	tenantID, err := claims.Tenant()
+	if current, ok := tenancy.TenantID(c.UserContext()); err != nil || !ok || tenantID != current {
+		return nil, auth.ErrInvalidToken
+	}
+
+	revoked, err := m.sessions.WithContext(c.UserContext()).IsRevoked(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// verifyAPIKey looks up an API key, rejecting it if it was created in another
+// tenant, revoked, or has expired, as verify rejects tokens of other tenants
+// and revoked sessions. Both are checked on every request, so revoking a
+// credential takes effect immediately.
+func (m *AuthMiddleware) verifyAPIKey(c *fiber.Ctx, key string) (*models.APIKey, error) {
+	apiKey, err := m.apiKeys.WithContext(c.UserContext()).Authenticate(key)
+	if err != nil {
+		if err.Error() == "invalid api key" {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if current, ok := tenancy.TenantID(c.UserContext()); !ok || apiKey.TenantID != current {
+		return nil, auth.ErrInvalidToken
+	}
+	return apiKey, nil
+}
+
+// setUser stores the token's user in the request context, and makes it the
+// principal whose access rules restrict the request's queries
+func setUser(c *fiber.Ctx, claims *auth.Claims) {
+	c.Locals("user_id", claims.Subject)
+	c.Locals("session_id", claims.SessionID)
+	c.Locals("user_role", claims.Role)
+	c.Locals("user_email", claims.Email)
+
+	userID, _ := uuid.Parse(claims.Subject)
+	c.SetUserContext(access.WithPrincipal(c.UserContext(), &access.Principal{UserID: userID, Role: claims.Role}))
+}
+
+// setAPIKey stores the API key's role in the request context. API keys do not
+// belong to a user.
+func setAPIKey(c *fiber.Ctx, apiKey *models.APIKey) {
+	c.Locals("api_key_id", apiKey.ID.String())
+	c.Locals("user_role", apiKey.Role)
+
+	c.SetUserContext(access.WithPrincipal(c.UserContext(), &access.Principal{Role: apiKey.Role, APIKeyID: apiKey.ID}))
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/utils"
+	"bookstore-api/internal/utils/signing"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// redactedValue replaces the values of redacted fields
+const redactedValue = "[REDACTED]"
+
+// defaultRedactFields are the personal and secret fields always masked in
+// sampled bodies and query strings
+var defaultRedactFields = []string{
+	"password", "current_password", "new_password", "token", "access_token",
+	"refresh_token", "id_token", "secret", "client_secret", "api_key", "key",
+	"authorization", "captcha_token", "email", "phone", "address",
+	"date_of_birth", "card_number", "cvv", "iban",
+	// Signed URLs can be replayed until they expire
+	signing.SignatureParam, signing.ExpiresParam,
+}
+
+// BodySampleMiddleware logs the bodies of a sample of requests and their
+// responses, with personal and secret fields masked, to help reproduce
+// issues clients report without logging every body
+type BodySampleMiddleware struct {
+	percent  float64
+	maxBytes int
+	redact   map[string]bool
+}
+
+// NewBodySampleMiddleware creates a new body sampling middleware
+func NewBodySampleMiddleware(cfg *config.Config) *BodySampleMiddleware {
+	m := &BodySampleMiddleware{
+		percent:  cfg.BodySample.Percent,
+		maxBytes: cfg.BodySample.MaxBytes,
+		redact:   make(map[string]bool),
+	}
+	for _, field := range defaultRedactFields {
+		m.redact[field] = true
+	}
+	for _, field := range strings.Split(cfg.BodySample.RedactFields, ",") {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			m.redact[field] = true
+		}
+	}
+	return m
+}
+
+// Sample logs the redacted request and response bodies of the configured
+// percentage of requests once they are handled. It is registered outside the
+// response format middleware so the logged response is the one the client
+// received. Streamed responses are logged without their body.
+func (m *BodySampleMiddleware) Sample() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if m.percent <= 0 || rand.Float64()*100 >= m.percent {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+
+		entry := map[string]interface{}{
+			"method":   c.Method(),
+			"path":     c.Path(),
+			"query":    m.redactQuery(c),
+			"status":   c.Response().StatusCode(),
+			"duration": time.Since(start).String(),
+			"request":  m.body(c.Body(), string(c.Request().Header.ContentType())),
+		}
+		if c.Response().IsBodyStream() {
+			entry["response"] = "[stream]"
+		} else {
+			entry["response"] = m.body(c.Response().Body(), string(c.Response().Header.ContentType()))
+		}
+		if err != nil {
+			entry["error"] = err.Error()
+		}
+		utils.LogInfo("Sampled request", entry)
+
+		return err
+	}
+}
+
+// redactQuery returns the query string with the values of redacted
+// parameters masked
+func (m *BodySampleMiddleware) redactQuery(c *fiber.Ctx) map[string]string {
+	query := make(map[string]string)
+	for name, value := range c.Queries() {
+		if m.redact[strings.ToLower(name)] {
+			value = redactedValue
+		}
+		query[name] = value
+	}
+	return query
+}
+
+// body returns a loggable form of a body: redacted JSON when the body is
+// JSON, otherwise a description of its size and type
+func (m *BodySampleMiddleware) body(raw []byte, contentType string) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	if !strings.Contains(contentType, "json") {
+		return describeBody(len(raw), contentType)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return describeBody(len(raw), contentType)
+	}
+	redacted, err := json.Marshal(m.redactValue(value))
+	if err != nil {
+		return describeBody(len(raw), contentType)
+	}
+	if m.maxBytes > 0 && len(redacted) > m.maxBytes {
+		return string(redacted[:m.maxBytes]) + "...[truncated]"
+	}
+	return json.RawMessage(redacted)
+}
+
+// redactValue masks the redacted fields of JSON objects at any depth
+func (m *BodySampleMiddleware) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			if m.redact[strings.ToLower(field)] {
+				v[field] = redactedValue
+			} else {
+				v[field] = m.redactValue(fieldValue)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = m.redactValue(v[i])
+		}
+	}
+	return value
+}
+
+// describeBody describes a body that is not logged
+func describeBody(size int, contentType string) string {
+	if contentType == "" {
+		contentType = "unknown type"
+	}
+	return "[" + strconv.Itoa(size) + " bytes of " + contentType + "]"
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/response"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bulkheadReleaseKey is the Locals key holding the function that frees the
+// bulkhead slot a request holds. A handler whose streamed response outlives
+// it takes the function, clearing the key, and calls it when the stream ends.
+const bulkheadReleaseKey = "bulkhead_release"
+
+// BulkheadMiddleware caps the requests expensive endpoints serve at once,
+// independently of rate limits, so a burst of slow requests cannot take
+// every database connection
+type BulkheadMiddleware struct {
+	search fiber.Handler
+	export fiber.Handler
+	imp    fiber.Handler
+}
+
+// NewBulkheadMiddleware creates a new bulkhead middleware
+func NewBulkheadMiddleware(cfg *config.Config) *BulkheadMiddleware {
+	return &BulkheadMiddleware{
+		search: bulkhead(cfg.Bulkheads.Search, "Too many searches in progress. Please try again shortly."),
+		export: bulkhead(cfg.Bulkheads.Export, "Too many exports in progress. Please try again shortly."),
+		imp:    bulkhead(cfg.Bulkheads.Import, "Too many imports in progress. Please try again shortly."),
+	}
+}
+
+// Search returns the bulkhead shared by the search endpoints
+func (m *BulkheadMiddleware) Search() fiber.Handler {
+	return m.search
+}
+
+// Export returns the bulkhead shared by the export endpoints
+func (m *BulkheadMiddleware) Export() fiber.Handler {
+	return m.export
+}
+
+// Import returns the bulkhead shared by the import endpoints
+func (m *BulkheadMiddleware) Import() fiber.Handler {
+	return m.imp
+}
+
+// bulkhead returns a middleware admitting at most max requests at once and
+// rejecting the rest with 429 Too Many Requests
+func bulkhead(max int, message string) fiber.Handler {
+	if max <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	slots := make(chan struct{}, max)
+	return func(c *fiber.Ctx) error {
+		select {
+		case slots <- struct{}{}:
+		default:
+			c.Set(fiber.HeaderRetryAfter, "1")
+			return response.Error(c, fiber.StatusTooManyRequests, message, nil)
+		}
+
+		var once sync.Once
+		release := func() {
+			once.Do(func() { <-slots })
+		}
+		c.Locals(bulkheadReleaseKey, release)
+
+		// Keep the slot if the handler took it for a streamed response
+		defer func() {
+			if _, held := c.Locals(bulkheadReleaseKey).(func()); held {
+				release()
+			}
+		}()
+		return c.Next()
+	}
+}
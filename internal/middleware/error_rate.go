@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"bookstore-api/internal/opsalerts"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorRateMiddleware feeds response statuses to the ops alert error rate
+// monitor
+type ErrorRateMiddleware struct {
+	monitor *opsalerts.ErrorRateMonitor
+}
+
+// NewErrorRateMiddleware creates a new error rate middleware
+func NewErrorRateMiddleware() *ErrorRateMiddleware {
+	return &ErrorRateMiddleware{monitor: opsalerts.GetErrorRateMonitor()}
+}
+
+// Track records the status of each response. Errors returned by handlers
+// have not been written yet, so their status is taken from the error, as the
+// error handler will.
+func (m *ErrorRateMiddleware) Track() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+		m.monitor.Record(status)
+		return err
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocaleMiddleware negotiates the language of catalog responses
+type LocaleMiddleware struct {
+	defaultLocale string
+}
+
+// NewLocaleMiddleware creates a new locale middleware
+func NewLocaleMiddleware(cfg *config.Config) *LocaleMiddleware {
+	return &LocaleMiddleware{
+		defaultLocale: cfg.I18n.DefaultLocale,
+	}
+}
+
+// Locale matches the Accept-Language header, or the lang query parameter when
+// set, against the default locale and stores the result in the request context
+func (m *LocaleMiddleware) Locale() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		accept := c.Get(fiber.HeaderAcceptLanguage)
+		if lang := c.Query("lang"); lang != "" {
+			accept = lang
+		}
+
+		c.Vary(fiber.HeaderAcceptLanguage)
+		c.SetUserContext(i18n.WithNegotiation(c.UserContext(), i18n.Negotiate(accept, m.defaultLocale)))
+		return c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CaptchaHeader carries the CAPTCHA response solved by the client. It may
+// also be sent as the captcha_token query parameter.
+const CaptchaHeader = "X-Captcha-Token"
+
+// LoginGuardMiddleware protects the login and token refresh endpoints from
+// brute force. The handlers report each attempt's outcome to the security
+// service; this middleware turns away the IPs those failures locked out.
+type LoginGuardMiddleware struct {
+	security *services.SecurityService
+}
+
+// NewLoginGuardMiddleware creates a new login guard middleware checking
+// attempts with svc
+func NewLoginGuardMiddleware(svc *services.Services) *LoginGuardMiddleware {
+	return &LoginGuardMiddleware{
+		security: svc.Security,
+	}
+}
+
+// Protect rejects attempts from a locked out IP with 429 Too Many Requests,
+// and requires a solved CAPTCHA from an IP with repeated failures
+func (m *LoginGuardMiddleware) Protect() fiber.Handler {
+	return m.guard(true)
+}
+
+// Lockout rejects attempts from a locked out IP without asking for a CAPTCHA,
+// for redirects back from identity providers, which cannot carry one
+func (m *LoginGuardMiddleware) Lockout() fiber.Handler {
+	return m.guard(false)
+}
+
+// guard returns the middleware, checking CAPTCHAs when captcha is set
+func (m *LoginGuardMiddleware) guard(captcha bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		security := m.security.WithContext(c.UserContext())
+		attempt := services.LoginAttempt{
+			IPAddress: c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}
+
+		if wait := security.LockedOut(attempt); wait > 0 {
+			security.Record(models.SecurityEventLockedOutAttempt, attempt, models.StringMap{"path": c.Path()})
+			return lockedOutResponse(c, wait)
+		}
+
+		if !captcha || !security.CaptchaRequired(attempt) {
+			return c.Next()
+		}
+
+		captcha := c.Get(CaptchaHeader)
+		if captcha == "" {
+			captcha = c.Query("captcha_token")
+		}
+		if err := security.VerifyCaptcha(attempt, captcha); err != nil {
+			if errors.Is(err, auth.ErrCaptchaFailed) {
+				if captcha != "" {
+					security.Failed(models.SecurityEventCaptchaFailed, attempt, "invalid captcha")
+				}
+				return response.Fail(c, fiber.StatusUnauthorized, response.Envelope{
+					Code:    "captcha_required",
+					Message: "Too many failed attempts. Solve the CAPTCHA and send it in the " + CaptchaHeader + " header.",
+				})
+			}
+			return response.Error(c, fiber.StatusServiceUnavailable, "Failed to verify CAPTCHA", err)
+		}
+		return c.Next()
+	}
+}
+
+// lockedOutResponse rejects an attempt from a locked out IP, telling the
+// client when it may try again
+func lockedOutResponse(c *fiber.Ctx, wait time.Duration) error {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return response.Fail(c, fiber.StatusTooManyRequests, response.Envelope{
+		Code:    "locked_out",
+		Message: "Too many failed attempts. Please try again later.",
+	})
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bookstore-api/internal/render"
+	"bytes"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NegotiationMiddleware encodes JSON responses to read requests in the media
+// type the client prefers, using the encoders registered with render
+type NegotiationMiddleware struct{}
+
+// NewNegotiationMiddleware creates a new content negotiation middleware
+func NewNegotiationMiddleware() *NegotiationMiddleware {
+	return &NegotiationMiddleware{}
+}
+
+// Negotiate matches the Accept header of GET and HEAD requests against JSON
+// and the registered media types. When the client prefers another media type,
+// the JSON response the handler wrote is re-encoded in it; streamed responses
+// and responses that are not JSON are left as they are.
+func (m *NegotiationMiddleware) Negotiate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		c.Vary(fiber.HeaderAccept)
+		if err := c.Next(); err != nil {
+			// Write the error response now so it is encoded like the others
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		mediaType := c.Accepts(append([]string{fiber.MIMEApplicationJSON}, render.MediaTypes()...)...)
+		encoder, ok := render.Lookup(mediaType)
+		if !ok {
+			return nil
+		}
+
+		response := c.Response()
+		contentType := string(response.Header.ContentType())
+		if response.IsBodyStream() || !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+			return nil
+		}
+
+		var body bytes.Buffer
+		if err := render.Transcode(&body, encoder, response.Body()); err != nil {
+			log.Printf("Failed to encode response as %s: %v", mediaType, err)
+			return nil
+		}
+		c.Set(fiber.HeaderContentType, mediaType)
+		return c.Send(body.Bytes())
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// paginationLimits are the default and largest page sizes of listings
+type paginationLimits struct {
+	defaultLimit int
+	maxLimit     int
+}
+
+// routeLimits are the page sizes for requests whose path starts with prefix
+type routeLimits struct {
+	prefix string
+	limits paginationLimits
+}
+
+// PaginationMiddleware sets the page sizes listings default to and allow
+type PaginationMiddleware struct {
+	limits paginationLimits
+	// routes are sorted longest prefix first
+	routes []routeLimits
+}
+
+// NewPaginationMiddleware creates a new pagination middleware. Invalid route
+// limits are logged and skipped.
+func NewPaginationMiddleware(cfg *config.Config) *PaginationMiddleware {
+	m := &PaginationMiddleware{
+		limits: paginationLimits{defaultLimit: cfg.Pagination.DefaultLimit, maxLimit: cfg.Pagination.MaxLimit},
+	}
+	if m.limits.maxLimit < 1 || m.limits.defaultLimit < 1 || m.limits.defaultLimit > m.limits.maxLimit {
+		log.Printf("Invalid pagination limits %d:%d, using 10:100", m.limits.defaultLimit, m.limits.maxLimit)
+		m.limits = paginationLimits{defaultLimit: 10, maxLimit: 100}
+	}
+
+	for _, entry := range strings.Split(cfg.Pagination.Routes, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, value, ok := strings.Cut(entry, "=")
+		limits, valid := parsePaginationLimits(value)
+		if !ok || !valid {
+			log.Printf("Invalid route pagination limits %q, ignoring", entry)
+			continue
+		}
+		m.routes = append(m.routes, routeLimits{prefix: strings.TrimSpace(prefix), limits: limits})
+	}
+	sort.SliceStable(m.routes, func(i, j int) bool {
+		return len(m.routes[i].prefix) > len(m.routes[j].prefix)
+	})
+	return m
+}
+
+// parsePaginationLimits parses default:max page sizes
+func parsePaginationLimits(value string) (paginationLimits, bool) {
+	defaultValue, maxValue, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return paginationLimits{}, false
+	}
+	defaultLimit, err := strconv.Atoi(defaultValue)
+	if err != nil {
+		return paginationLimits{}, false
+	}
+	maxLimit, err := strconv.Atoi(maxValue)
+	if err != nil || defaultLimit < 1 || defaultLimit > maxLimit {
+		return paginationLimits{}, false
+	}
+	return paginationLimits{defaultLimit: defaultLimit, maxLimit: maxLimit}, true
+}
+
+// Limits stores the request's default and largest page sizes for handlers
+// reading page and limit parameters
+func (m *PaginationMiddleware) Limits() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limits := m.limitsFor(c.Path())
+		c.Locals("pagination_default_limit", limits.defaultLimit)
+		c.Locals("pagination_max_limit", limits.maxLimit)
+		return c.Next()
+	}
+}
+
+// limitsFor returns the page sizes for requests to path
+func (m *PaginationMiddleware) limitsFor(path string) paginationLimits {
+	for _, route := range m.routes {
+		if strings.HasPrefix(path, route.prefix) {
+			return route.limits
+		}
+	}
+	return m.limits
+}
@@ -1,13 +1,23 @@
 package middleware
 
 import (
+	"bookstore-api/internal/response"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
-// RateLimitMiddleware handles rate limiting
+// Default limits used when the tenant does not override them
+const (
+	defaultRateLimit       = 100
+	defaultStrictRateLimit = 10
+)
+
+// RateLimitMiddleware handles rate limiting. Limits are counted per tenant and
+// client IP, and tenants may override the default limits.
 type RateLimitMiddleware struct{}
 
 // NewRateLimitMiddleware creates a new rate limit middleware
@@ -17,36 +27,57 @@ func NewRateLimitMiddleware() *RateLimitMiddleware {
 
 // RateLimit returns a rate limiting middleware
 func (m *RateLimitMiddleware) RateLimit() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        100,                // Maximum number of requests
-		Expiration: 1 * time.Minute,    // Time window
-		KeyGenerator: func(c *fiber.Ctx) string {
-			// Use IP address as key
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error":   true,
-				"message": "Rate limit exceeded. Please try again later.",
-			})
-		},
-	})
+	return perTenant(func(c *fiber.Ctx) int {
+		if tenant := CurrentTenant(c); tenant != nil && tenant.RateLimit > 0 {
+			return tenant.RateLimit
+		}
+		return defaultRateLimit
+	}, "Rate limit exceeded. Please try again later.")
 }
 
 // StrictRateLimit returns a stricter rate limiting middleware for sensitive endpoints
 func (m *RateLimitMiddleware) StrictRateLimit() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        10,                 // Maximum number of requests
-		Expiration: 1 * time.Minute,    // Time window
-		KeyGenerator: func(c *fiber.Ctx) string {
-			// Use IP address as key
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error":   true,
-				"message": "Rate limit exceeded for this endpoint. Please try again later.",
+	return perTenant(func(c *fiber.Ctx) int {
+		if tenant := CurrentTenant(c); tenant != nil && tenant.StrictRateLimit > 0 {
+			return tenant.StrictRateLimit
+		}
+		return defaultStrictRateLimit
+	}, "Rate limit exceeded for this endpoint. Please try again later.")
+}
+
+// perTenant returns a middleware that applies a limiter sized by max for the
+// request's tenant. The limiter middleware has a fixed maximum, so one is
+// created per tenant and limit on first use.
+func perTenant(max func(c *fiber.Ctx) int, message string) fiber.Handler {
+	var mu sync.Mutex
+	limiters := make(map[string]fiber.Handler)
+
+	return func(c *fiber.Ctx) error {
+		tenant := ""
+		if t := CurrentTenant(c); t != nil {
+			tenant = t.ID.String()
+		}
+		limit := max(c)
+		key := fmt.Sprintf("%s:%d", tenant, limit)
+
+		mu.Lock()
+		handler, ok := limiters[key]
+		if !ok {
+			handler = limiter.New(limiter.Config{
+				Max:        limit,           // Maximum number of requests
+				Expiration: 1 * time.Minute, // Time window
+				KeyGenerator: func(c *fiber.Ctx) string {
+					// Use tenant and IP address as key
+					return tenant + "|" + c.IP()
+				},
+				LimitReached: func(c *fiber.Ctx) error {
+					return response.Error(c, fiber.StatusTooManyRequests, message, nil)
+				},
 			})
-		},
-	})
+			limiters[key] = handler
+		}
+		mu.Unlock()
+
+		return handler(c)
+	}
 }
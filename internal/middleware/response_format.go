@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/render"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// responseFormatHeader lets a request choose its response format explicitly
+const responseFormatHeader = "X-Response-Format"
+
+// ResponseFormatMiddleware chooses between the response envelope and JSON:API
+// documents for each request
+type ResponseFormatMiddleware struct {
+	defaultFormat string
+}
+
+// NewResponseFormatMiddleware creates a new response format middleware
+func NewResponseFormatMiddleware(cfg *config.Config) *ResponseFormatMiddleware {
+	format := render.FormatEnvelope
+	if cfg.Responses.Format == render.FormatJSONAPI {
+		format = render.FormatJSONAPI
+	}
+	return &ResponseFormatMiddleware{defaultFormat: format}
+}
+
+// ResponseFormat stores the format the request is answered in, taken from the
+// X-Response-Format header, an Accept header listing the JSON:API media type,
+// or the configured default, in that order. Error envelopes written in
+// JSON:API requests are converted to JSON:API error documents.
+func (m *ResponseFormatMiddleware) ResponseFormat() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		format := m.defaultFormat
+		switch header := strings.ToLower(c.Get(responseFormatHeader)); {
+		case header == render.FormatEnvelope || header == render.FormatJSONAPI:
+			format = header
+		case strings.Contains(c.Get(fiber.HeaderAccept), render.JSONAPIMediaType):
+			format = render.FormatJSONAPI
+		}
+
+		c.Vary(fiber.HeaderAccept, responseFormatHeader)
+		c.Locals("response_format", format)
+		if format != render.FormatJSONAPI {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		return jsonapiErrors(c)
+	}
+}
+
+// jsonapiErrors rewrites an error envelope in the response as a JSON:API
+// error document
+func jsonapiErrors(c *fiber.Ctx) error {
+	response := c.Response()
+	status := response.StatusCode()
+	if status < fiber.StatusBadRequest || response.IsBodyStream() ||
+		!strings.HasPrefix(string(response.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return nil
+	}
+
+	var envelope struct {
+		Error   bool            `json:"error"`
+		Code    string          `json:"code"`
+		Field   string          `json:"field"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+		Fields  []struct {
+			Field   string `json:"field"`
+			Rule    string `json:"rule"`
+			Message string `json:"message"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(response.Body(), &envelope); err != nil || !envelope.Error {
+		return nil
+	}
+
+	apiError := render.Error{Status: strconv.Itoa(status), Code: envelope.Code, Title: envelope.Message}
+	if envelope.Field != "" {
+		apiError.Source = &render.ErrorSource{Pointer: "/data/attributes/" + envelope.Field}
+	}
+	if len(envelope.Details) > 0 && string(envelope.Details) != "null" {
+		if err := json.Unmarshal(envelope.Details, &apiError.Detail); err != nil {
+			apiError.Detail = string(envelope.Details)
+		}
+	}
+	apiErrors := []render.Error{apiError}
+	// Validation failures become an error per failed field
+	if len(envelope.Fields) > 0 {
+		apiErrors = apiErrors[:0]
+		for _, field := range envelope.Fields {
+			apiErrors = append(apiErrors, render.Error{
+				Status: apiError.Status,
+				Code:   field.Rule,
+				Title:  envelope.Message,
+				Detail: field.Message,
+				Source: &render.ErrorSource{Pointer: "/data/attributes/" + field.Field},
+			})
+		}
+	}
+	if err := c.JSON(render.ErrorDocument{Errors: apiErrors}); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, render.JSONAPIMediaType)
+	return nil
+}
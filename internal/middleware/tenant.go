@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/tenancy"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TenantMiddleware resolves the storefront a request belongs to
+type TenantMiddleware struct {
+	resolver *tenancy.Resolver
+	header   string
+}
+
+// NewTenantMiddleware creates a new tenant middleware
+func NewTenantMiddleware(cfg *config.Config) *TenantMiddleware {
+	return &TenantMiddleware{
+		resolver: tenancy.GetResolver(),
+		header:   cfg.Tenancy.Header,
+	}
+}
+
+// ResolveTenant resolves the tenant from the tenant header or the request host
+// and scopes the request context to it. Requests that match no tenant continue
+// without one; RequireTenant rejects them where a tenant is needed.
+func (m *TenantMiddleware) ResolveTenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		identifier := ""
+		if m.header != "" {
+			identifier = c.Get(m.header)
+		}
+
+		tenant, err := m.resolver.Resolve(identifier, c.Hostname())
+		if err != nil {
+			if errors.Is(err, tenancy.ErrUnknownTenant) {
+				return c.Next()
+			}
+			return response.Error(c, fiber.StatusInternalServerError, "Failed to resolve tenant", err)
+		}
+
+		c.Locals("tenant", tenant)
+		c.SetUserContext(tenancy.WithTenant(c.UserContext(), tenant))
+		return c.Next()
+	}
+}
+
+// RequireTenant rejects requests that ResolveTenant matched to no tenant
+func (m *TenantMiddleware) RequireTenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if CurrentTenant(c) == nil {
+			return response.Error(c, fiber.StatusNotFound, "Unknown tenant", nil)
+		}
+		return c.Next()
+	}
+}
+
+// RequireDefaultTenant allows only requests to the default tenant, for
+// operations that affect every tenant
+func (m *TenantMiddleware) RequireDefaultTenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if tenant := CurrentTenant(c); tenant == nil || tenant.ID != models.DefaultTenantID {
+			return response.Error(c, fiber.StatusForbidden, "Only available to the default tenant", nil)
+		}
+		return c.Next()
+	}
+}
+
+// CurrentTenant returns the tenant resolved for the request, or nil
+func CurrentTenant(c *fiber.Ctx) *models.Tenant {
+	tenant, _ := c.Locals("tenant").(*models.Tenant)
+	return tenant
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/response"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// routeTimeout is the timeout for requests whose path starts with prefix
+type routeTimeout struct {
+	prefix  string
+	timeout time.Duration
+}
+
+// TimeoutMiddleware bounds how long requests may run
+type TimeoutMiddleware struct {
+	timeout time.Duration
+	// routes are sorted longest prefix first
+	routes []routeTimeout
+}
+
+// NewTimeoutMiddleware creates a new timeout middleware. Invalid route
+// timeouts are logged and skipped.
+func NewTimeoutMiddleware(cfg *config.Config) *TimeoutMiddleware {
+	m := &TimeoutMiddleware{timeout: cfg.Timeouts.Request}
+
+	for _, entry := range strings.Split(cfg.Timeouts.Routes, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, value, ok := strings.Cut(entry, "=")
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || err != nil || timeout < 0 {
+			log.Printf("Invalid route timeout %q, ignoring", entry)
+			continue
+		}
+		m.routes = append(m.routes, routeTimeout{prefix: strings.TrimSpace(prefix), timeout: timeout})
+	}
+	sort.SliceStable(m.routes, func(i, j int) bool {
+		return len(m.routes[i].prefix) > len(m.routes[j].prefix)
+	})
+	return m
+}
+
+// Timeout cancels the request context when the request's timeout passes, so
+// database queries using it are aborted. A handler that failed after its
+// context was cancelled is answered with 504 Gateway Timeout in place of its
+// error, while responses the handler completed are kept, even when it
+// finished after the timeout. Streamed responses keep their context until
+// the timeout, since their body is written after the handler returns.
+func (m *TimeoutMiddleware) Timeout() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		timeout := m.timeoutFor(c.Path())
+		if timeout == 0 {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if c.Response().IsBodyStream() {
+			deadline, _ := ctx.Deadline()
+			time.AfterFunc(time.Until(deadline), cancel)
+			return err
+		}
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if timedOut && (err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError) {
+			return response.ErrorWithDetails(c, fiber.StatusGatewayTimeout, "Request timed out", "the request did not complete within "+timeout.String())
+		}
+		return err
+	}
+}
+
+// timeoutFor returns the timeout for requests to path, zero meaning none
+func (m *TimeoutMiddleware) timeoutFor(path string) time.Duration {
+	for _, route := range m.routes {
+		if strings.HasPrefix(path, route.prefix) {
+			return route.timeout
+		}
+	}
+	return m.timeout
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"bookstore-api/internal/tracing"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TracingMiddleware starts a span for each request
+type TracingMiddleware struct{}
+
+// NewTracingMiddleware creates a new tracing middleware
+func NewTracingMiddleware() *TracingMiddleware {
+	return &TracingMiddleware{}
+}
+
+// Trace starts a span for the request, continuing the caller's trace when it
+// sent a traceparent header, puts it in the request context, and returns its
+// traceparent in the response so clients can find the request's log lines
+func (m *TracingMiddleware) Trace() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, span := tracing.Start(c.UserContext(), c.Method()+" "+c.Path(), c.Get(tracing.Header))
+		c.SetUserContext(ctx)
+		c.Set(tracing.Header, span.Traceparent())
+
+		err := c.Next()
+		span.End(ctx, slog.Int("status", c.Response().StatusCode()))
+		return err
+	}
+}
+
+// Forward sets the traceparent header of the request to its span, so a
+// handler passing the request on, such as the gRPC-Web bridge, continues the
+// request's trace
+func (m *TracingMiddleware) Forward() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if traceparent := tracing.Traceparent(c.UserContext()); traceparent != "" {
+			c.Request().Header.Set(tracing.Header, traceparent)
+		}
+		return c.Next()
+	}
+}
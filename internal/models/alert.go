@@ -0,0 +1,69 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedSearch is a book search a user saved to be told about new matches.
+// Books created after LastCheckedAt that match Query are reported by the next
+// alert scan, which then advances LastCheckedAt.
+type SavedSearch struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID      uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name          string    `json:"name" gorm:"not null;size:100"`
+	Query         string    `json:"query" gorm:"not null;size:255"`
+	LastCheckedAt time.Time `json:"last_checked_at" gorm:"not null;index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the SavedSearch model
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *SavedSearch) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = ids.New()
+	}
+	return nil
+}
+
+// StockAlert is a user's request to be told when an out-of-stock book is back
+// in stock. NotifiedAt is set once the user has been told; subscribing again
+// clears it.
+type StockAlert struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID   uuid.UUID  `json:"-" gorm:"type:uuid;not null"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_alerts_user_book"`
+	BookID     uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_alerts_user_book;index"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relationships
+	Book Book `json:"book,omitempty" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the StockAlert model
+func (StockAlert) TableName() string {
+	return "stock_alerts"
+}
+
+// BeforeCreate hook to generate UUID
+func (a *StockAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = ids.New()
+	}
+	return nil
+}
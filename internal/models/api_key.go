@@ -0,0 +1,39 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey is a long-lived credential for scripts and operations tools. Only a
+// hash of the key is stored; Hint holds its last characters to tell keys apart.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID   uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"not null;size:255"`
+	Role       string     `json:"role" gorm:"not null;size:20"`
+	KeyHash    string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Hint       string     `json:"hint" gorm:"not null;size:8"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// BeforeCreate hook to generate UUID
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = ids.New()
+	}
+	return nil
+}
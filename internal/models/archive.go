@@ -0,0 +1,36 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ArchiveManifest describes one archive of rows moved out of the database
+// into cold storage. Archives hold every tenant's rows, so manifests are not
+// scoped to a tenant.
+type ArchiveManifest struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	// SourceTable is the table the rows were archived from
+	SourceTable string `json:"source_table" gorm:"not null;size:100;index:idx_archive_manifests_table_newest"`
+	// Key is the archive's key in the archive storage
+	Key string `json:"key" gorm:"not null;size:255;uniqueIndex"`
+	// Rows is the number of rows in the archive, one JSON object per line
+	Rows     int64     `json:"rows" gorm:"not null"`
+	OldestAt time.Time `json:"oldest_at" gorm:"not null"`
+	NewestAt time.Time `json:"newest_at" gorm:"not null;index:idx_archive_manifests_table_newest"`
+	// SizeBytes and Checksum (hex SHA-256) describe the compressed archive
+	SizeBytes int64     `json:"size_bytes" gorm:"not null"`
+	Checksum  string    `json:"checksum" gorm:"not null;size:64"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (m *ArchiveManifest) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = ids.New()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bookstore-api/internal/ids"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,16 +10,34 @@ import (
 
 // Author represents an author in the bookstore
 type Author struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name      string         `json:"name" gorm:"not null;size:255" validate:"required,min=2,max=255"`
-	Email     string         `json:"email" gorm:"uniqueIndex:uni_authors_email;not null;size:255" validate:"required,email"`
-	Biography string         `json:"biography" gorm:"type:text"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID  uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_authors_tenant_email;uniqueIndex:uni_authors_tenant_slug"`
+	Name      string    `json:"name" gorm:"not null;size:255" validate:"required,min=2,max=255"`
+	Slug      string    `json:"slug" gorm:"not null;size:255;uniqueIndex:uni_authors_tenant_slug"`
+	Email     string    `json:"email" gorm:"uniqueIndex:uni_authors_tenant_email;not null;size:255" validate:"required,email"`
+	Biography string    `json:"biography" gorm:"type:text"`
+	// Photos maps each photo size to its URL; PhotoKey is the storage key
+	// prefix the sizes are stored under
+	Photos        StringMap      `json:"photos,omitempty" gorm:"type:jsonb;not null;default:'{}'"`
+	PhotoKey      string         `json:"-" gorm:"not null;size:255;default:''"`
+	ExternalLinks StringMap      `json:"external_links" gorm:"type:jsonb;not null;default:'{}'"`
+	UserID        *uuid.UUID     `json:"user_id,omitempty" gorm:"type:uuid;uniqueIndex:uni_authors_user_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	// BooksCount is the number of the author's books, set by listings, which
+	// leave Books unloaded
+	BooksCount *int64 `json:"books_count,omitempty" gorm:"-"`
 
 	// Relationships
 	Books []Book `json:"books,omitempty" gorm:"foreignKey:AuthorID"`
+	User  *User  `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// SlugSource returns the text the author's slug derives from
+func (a *Author) SlugSource() string {
+	return a.Name
 }
 
 // TableName returns the table name for the Author model
@@ -29,7 +48,7 @@ func (Author) TableName() string {
 // BeforeCreate hook to generate UUID
 func (a *Author) BeforeCreate(tx *gorm.DB) error {
 	if a.ID == uuid.Nil {
-		a.ID = uuid.New()
+		a.ID = ids.New()
 	}
 	return nil
 }
@@ -1,32 +1,85 @@
 package models
 
 import (
+	"bookstore-api/internal/ids"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Book statuses. Only active books are listed and searched publicly; drafts
+// are books being prepared and archived books are withdrawn from sale.
+const (
+	BookStatusDraft    = "draft"
+	BookStatusActive   = "active"
+	BookStatusArchived = "archived"
+)
+
+// IsBookStatus reports whether status is a book status
+func IsBookStatus(status string) bool {
+	switch status {
+	case BookStatusDraft, BookStatusActive, BookStatusArchived:
+		return true
+	}
+	return false
+}
+
+// Book formats. A book's format is optional.
+const (
+	BookFormatHardcover = "hardcover"
+	BookFormatPaperback = "paperback"
+	BookFormatEbook     = "ebook"
+	BookFormatAudiobook = "audiobook"
+)
+
 // Book represents a book in the bookstore
 type Book struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID    uuid.UUID      `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_books_tenant_isbn;uniqueIndex:uni_books_tenant_slug;uniqueIndex:uni_books_tenant_short_code"`
 	Title       string         `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
-	ISBN        string         `json:"isbn" gorm:"uniqueIndex;not null;size:20" validate:"required,len=13"`
+	Slug        string         `json:"slug" gorm:"not null;size:255;uniqueIndex:uni_books_tenant_slug"`
+	ShortCode   string         `json:"short_code" gorm:"not null;size:16;uniqueIndex:uni_books_tenant_short_code"`
+	ISBN        string         `json:"isbn" gorm:"uniqueIndex:uni_books_tenant_isbn;not null;size:20" validate:"required,isbn13"`
 	Description string         `json:"description" gorm:"type:text"`
 	Price       float64        `json:"price" gorm:"not null;type:decimal(10,2)" validate:"required,min=0"`
 	Stock       int            `json:"stock" gorm:"not null;default:0" validate:"min=0"`
-	PublishedAt *time.Time     `json:"published_at"`
+	PublishedAt *DateOnly      `json:"published_at" gorm:"type:date" validate:"omitempty,max_years_ahead=2"`
+	Status      string         `json:"status" gorm:"not null;size:20;default:active;index" validate:"omitempty,oneof=draft active archived"`
+	Format      string         `json:"format,omitempty" gorm:"size:20" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	PageCount   *int           `json:"page_count,omitempty" validate:"omitempty,min=1"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
+	// EffectivePrice is the price after the best active promotion, set when the
+	// book is read
+	EffectivePrice *float64          `json:"effective_price,omitempty" gorm:"-"`
+	Promotion      *AppliedPromotion `json:"promotion,omitempty" gorm:"-"`
+	// Views is the number of recent views, set on trending books
+	Views *int64 `json:"views,omitempty" gorm:"-"`
+	// AverageRating and RatingsCount summarize the book's ratings, set on
+	// books read from the book_listings read model
+	AverageRating *float64 `json:"average_rating,omitempty" gorm:"-"`
+	RatingsCount  *int64   `json:"ratings_count,omitempty" gorm:"-"`
+	// Availability is where the book's stock is held, set when a single
+	// book is read
+	Availability *StockAvailability `json:"availability,omitempty" gorm:"-"`
+
 	// Foreign Keys
-	AuthorID   uuid.UUID `json:"author_id" gorm:"not null;type:uuid" validate:"required"`
-	CategoryID uuid.UUID `json:"category_id" gorm:"not null;type:uuid" validate:"required"`
+	AuthorID    uuid.UUID  `json:"author_id" gorm:"not null;type:uuid" validate:"required"`
+	CategoryID  uuid.UUID  `json:"category_id" gorm:"not null;type:uuid" validate:"required"`
+	PublisherID *uuid.UUID `json:"publisher_id,omitempty" gorm:"type:uuid"`
 
 	// Relationships
-	Author   Author   `json:"author,omitempty" gorm:"foreignKey:AuthorID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
-	Category Category `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Author    Author     `json:"author,omitempty" gorm:"foreignKey:AuthorID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Category  Category   `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Publisher *Publisher `json:"publisher,omitempty" gorm:"foreignKey:PublisherID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// SlugSource returns the text the book's slug derives from
+func (b *Book) SlugSource() string {
+	return b.Title
 }
 
 // TableName returns the table name for the Book model
@@ -34,10 +87,13 @@ func (Book) TableName() string {
 	return "books"
 }
 
-// BeforeCreate hook to generate UUID
+// BeforeCreate hook to generate UUID and short code
 func (b *Book) BeforeCreate(tx *gorm.DB) error {
 	if b.ID == uuid.Nil {
-		b.ID = uuid.New()
+		b.ID = ids.New()
+	}
+	if b.ShortCode == "" {
+		b.ShortCode = ids.NewShortCode()
 	}
 	return nil
 }
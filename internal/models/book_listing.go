@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookListing is the storefront read model of a book: the book's columns with
+// its author and category names, rating, and effective price denormalized
+// into one row, maintained from domain events
+type BookListing struct {
+	BookID      uuid.UUID  `json:"book_id" gorm:"type:uuid;primary_key"`
+	TenantID    uuid.UUID  `json:"-" gorm:"type:uuid;not null;index:idx_book_listings_tenant_created_at"`
+	Title       string     `json:"title" gorm:"not null;size:255"`
+	Slug        string     `json:"slug" gorm:"not null;size:255"`
+	ShortCode   string     `json:"short_code" gorm:"not null;size:16"`
+	ISBN        string     `json:"isbn" gorm:"not null;size:20"`
+	Description string     `json:"description" gorm:"type:text"`
+	Price       float64    `json:"price" gorm:"not null;type:decimal(10,2)"`
+	Stock       int        `json:"stock" gorm:"not null"`
+	PublishedAt *DateOnly  `json:"published_at" gorm:"type:date"`
+	PublisherID *uuid.UUID `json:"publisher_id,omitempty" gorm:"type:uuid"`
+	Status      string     `json:"status" gorm:"not null;size:20;default:active"`
+
+	AuthorID     uuid.UUID `json:"author_id" gorm:"type:uuid;not null;index"`
+	AuthorName   string    `json:"author_name" gorm:"not null;size:255"`
+	AuthorSlug   string    `json:"author_slug" gorm:"not null;size:255"`
+	CategoryID   uuid.UUID `json:"category_id" gorm:"type:uuid;not null;index"`
+	CategoryName string    `json:"category_name" gorm:"not null;size:100"`
+	CategorySlug string    `json:"category_slug" gorm:"not null;size:255"`
+
+	// AverageRating is nil for books nobody has rated
+	AverageRating *float64 `json:"average_rating" gorm:"type:decimal(3,2)"`
+	RatingsCount  int64    `json:"ratings_count" gorm:"not null;default:0"`
+
+	EffectivePrice  float64    `json:"effective_price" gorm:"not null;type:decimal(10,2)"`
+	PromotionID     *uuid.UUID `json:"promotion_id,omitempty" gorm:"type:uuid"`
+	PromotionName   string     `json:"promotion_name,omitempty" gorm:"size:255"`
+	PromotionEndsAt *time.Time `json:"promotion_ends_at,omitempty"`
+
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;index:idx_book_listings_tenant_created_at"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+	RefreshedAt time.Time `json:"refreshed_at" gorm:"not null"`
+}
+
+// TableName returns the table name for the BookListing model
+func (BookListing) TableName() string {
+	return "book_listings"
+}
+
+// Book returns the listing as a book whose author and category carry their
+// ID, name, and slug
+func (l *BookListing) Book() Book {
+	book := Book{
+		ID:          l.BookID,
+		TenantID:    l.TenantID,
+		Title:       l.Title,
+		Slug:        l.Slug,
+		ShortCode:   l.ShortCode,
+		ISBN:        l.ISBN,
+		Description: l.Description,
+		Price:       l.Price,
+		Stock:       l.Stock,
+		PublishedAt: l.PublishedAt,
+		Status:      l.Status,
+		CreatedAt:   l.CreatedAt,
+		UpdatedAt:   l.UpdatedAt,
+		AuthorID:    l.AuthorID,
+		CategoryID:  l.CategoryID,
+		PublisherID: l.PublisherID,
+		Author:      Author{ID: l.AuthorID, TenantID: l.TenantID, Name: l.AuthorName, Slug: l.AuthorSlug},
+		Category:    Category{ID: l.CategoryID, TenantID: l.TenantID, Name: l.CategoryName, Slug: l.CategorySlug},
+	}
+
+	effectivePrice := l.EffectivePrice
+	book.EffectivePrice = &effectivePrice
+	if l.PromotionID != nil && l.PromotionEndsAt != nil {
+		book.Promotion = &AppliedPromotion{ID: *l.PromotionID, Name: l.PromotionName, EndsAt: *l.PromotionEndsAt}
+	}
+	book.AverageRating = l.AverageRating
+	ratingsCount := l.RatingsCount
+	book.RatingsCount = &ratingsCount
+	return book
+}
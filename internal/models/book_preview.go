@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookPreview is a book's sample content, such as an excerpt or a sample
+// chapter, stored in the media storage
+type BookPreview struct {
+	BookID      uuid.UUID `json:"book_id" gorm:"type:uuid;primary_key"`
+	TenantID    uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Key         string    `json:"-" gorm:"not null;size:255"`
+	ContentType string    `json:"content_type" gorm:"not null;size:100"`
+	Size        int64     `json:"size" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Book Book `json:"-" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the BookPreview model
+func (BookPreview) TableName() string {
+	return "book_previews"
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Book revision statuses
+const (
+	RevisionStatusPending  = "pending"
+	RevisionStatusApproved = "approved"
+	RevisionStatusRejected = "rejected"
+)
+
+// FieldChange is a book field's value before and after a revision, as JSON
+// values
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// FieldChanges maps book fields to their changes, stored as a JSONB object
+type FieldChanges map[string]FieldChange
+
+// Value implements driver.Valuer
+func (f FieldChanges) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string]FieldChange(f))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (f *FieldChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*map[string]FieldChange)(f))
+	case string:
+		return json.Unmarshal([]byte(v), (*map[string]FieldChange)(f))
+	default:
+		return fmt.Errorf("cannot scan %T into FieldChanges", value)
+	}
+}
+
+// FieldValues maps book fields to JSON values, stored as a JSONB object
+type FieldValues map[string]interface{}
+
+// Value implements driver.Valuer
+func (f FieldValues) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(map[string]interface{}(f))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (f *FieldValues) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*map[string]interface{})(f))
+	case string:
+		return json.Unmarshal([]byte(v), (*map[string]interface{})(f))
+	default:
+		return fmt.Errorf("cannot scan %T into FieldValues", value)
+	}
+}
+
+// BookRevision is a change to a book. Changes by editors who are not admins
+// wait as pending revisions and are applied once approved; every applied
+// change, including direct edits by admins and reverts, is an approved
+// revision in the book's history with a snapshot of its fields after it.
+type BookRevision struct {
+	ID       uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID uuid.UUID    `json:"-" gorm:"type:uuid;not null;index:idx_book_revisions_tenant_status"`
+	BookID   uuid.UUID    `json:"book_id" gorm:"type:uuid;not null;index"`
+	Status   string       `json:"status" gorm:"not null;size:20;default:pending;index:idx_book_revisions_tenant_status"`
+	Changes  FieldChanges `json:"changes" gorm:"type:jsonb;not null"`
+	Note     string       `json:"note,omitempty" gorm:"size:1000"`
+	// SubmittedBy is nil for changes made with admin API keys
+	SubmittedBy *uuid.UUID `json:"submitted_by,omitempty" gorm:"type:uuid;index"`
+	ReviewedBy  *uuid.UUID `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewNote  string     `json:"review_note,omitempty" gorm:"size:1000"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	// Snapshot is the book's revisable fields after the revision was applied
+	Snapshot FieldValues `json:"snapshot,omitempty" gorm:"type:jsonb"`
+	// RevertedFrom is the revision this one reverted
+	RevertedFrom *uuid.UUID `json:"reverted_from,omitempty" gorm:"type:uuid"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relationships
+	Book *Book `json:"book,omitempty" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the BookRevision model
+func (BookRevision) TableName() string {
+	return "book_revisions"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *BookRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookView is one view of a book's page. UserID is set when the viewer was
+// signed in. Views are recorded in batches, so ID is a bigserial rather than
+// a UUID. The table is partitioned by month on viewed_at.
+type BookView struct {
+	ID       int64      `json:"id" gorm:"primary_key;autoIncrement"`
+	TenantID uuid.UUID  `json:"-" gorm:"type:uuid;not null;index:idx_book_views_tenant_viewed_at"`
+	BookID   uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index:idx_book_views_book_viewed_at"`
+	UserID   *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index:idx_book_views_user_viewed_at"`
+	ViewedAt time.Time  `json:"viewed_at" gorm:"not null;index:idx_book_views_tenant_viewed_at;index:idx_book_views_book_viewed_at;index:idx_book_views_user_viewed_at"`
+
+	// Relationships
+	Book Book `json:"-" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the BookView model
+func (BookView) TableName() string {
+	return "book_views"
+}
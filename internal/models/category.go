@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bookstore-api/internal/ids"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,15 +10,28 @@ import (
 
 // Category represents a book category in the bookstore
 type Category struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name        string         `json:"name" gorm:"not null;size:100;uniqueIndex" validate:"required,min=2,max=100"`
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID    uuid.UUID      `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_categories_tenant_name;uniqueIndex:uni_categories_tenant_slug"`
+	Name        string         `json:"name" gorm:"not null;size:100;uniqueIndex:uni_categories_tenant_name" validate:"required,min=2,max=100"`
+	Slug        string         `json:"slug" gorm:"not null;size:255;uniqueIndex:uni_categories_tenant_slug"`
 	Description string         `json:"description" gorm:"type:text"`
+	ParentID    *uuid.UUID     `json:"parent_id" gorm:"type:uuid;index"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
+	// BooksCount is the number of books in the category, set by listings,
+	// which leave Books unloaded
+	BooksCount *int64 `json:"books_count,omitempty" gorm:"-"`
+
 	// Relationships
-	Books []Book `json:"books,omitempty" gorm:"foreignKey:CategoryID"`
+	Parent *Category `json:"-" gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Books  []Book    `json:"books,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+// SlugSource returns the text the category's slug derives from
+func (c *Category) SlugSource() string {
+	return c.Name
 }
 
 // TableName returns the table name for the Category model
@@ -28,7 +42,7 @@ func (Category) TableName() string {
 // BeforeCreate hook to generate UUID
 func (c *Category) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == uuid.Nil {
-		c.ID = uuid.New()
+		c.ID = ids.New()
 	}
 	return nil
 }
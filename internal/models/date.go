@@ -0,0 +1,85 @@
+package models
+
+import (
+	"bookstore-api/internal/timestamps"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateOnly is a calendar date without a time of day or time zone, such as a
+// publication date. It is stored in DATE columns and written as 2006-01-02 in
+// JSON; requests may also send an RFC 3339 timestamp, whose date in the
+// publication time zone is kept.
+type DateOnly struct {
+	time.Time
+}
+
+// NewDateOnly returns the date of t in t's location
+func NewDateOnly(t time.Time) DateOnly {
+	year, month, day := t.Date()
+	return DateOnly{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDateOnly parses a date or an RFC 3339 timestamp
+func ParseDateOnly(value string) (DateOnly, error) {
+	t, err := timestamps.ParsePublication(value)
+	if err != nil {
+		return DateOnly{}, err
+	}
+	return NewDateOnly(t), nil
+}
+
+// String returns the date as 2006-01-02
+func (d DateOnly) String() string {
+	return d.Format(timestamps.DateLayout)
+}
+
+// MarshalJSON writes the date as 2006-01-02
+func (d DateOnly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a date or an RFC 3339 timestamp
+func (d *DateOnly) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	parsed, err := ParseDateOnly(value)
+	if err != nil {
+		return fmt.Errorf("date must be YYYY-MM-DD or an RFC 3339 timestamp")
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan reads a DATE column
+func (d *DateOnly) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = NewDateOnly(v)
+		return nil
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	}
+	return fmt.Errorf("cannot scan %T into DateOnly", value)
+}
+
+// scanString reads a date the driver returned as text
+func (d *DateOnly) scanString(value string) error {
+	t, err := time.Parse(timestamps.DateLayout, value)
+	if err != nil {
+		return err
+	}
+	*d = NewDateOnly(t)
+	return nil
+}
+
+// Value writes the date for a DATE column
+func (d DateOnly) Value() (driver.Value, error) {
+	return d.String(), nil
+}
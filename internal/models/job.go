@@ -0,0 +1,116 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job represents a persisted background job. Jobs that use up their attempts
+// stay failed, with FailedAt set, as the dead-letter queue; Retries counts the
+// times one was retried from there.
+type Job struct {
+	ID          uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	Type        string       `json:"type" gorm:"not null;size:100;index"`
+	Payload     string       `json:"payload" gorm:"type:jsonb;not null;default:'{}'"`
+	Status      string       `json:"status" gorm:"not null;size:20;default:pending"`
+	Attempts    int          `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int          `json:"max_attempts" gorm:"not null;default:5"`
+	LastError   string       `json:"last_error,omitempty" gorm:"type:text"`
+	Progress    *JobProgress `json:"progress,omitempty" gorm:"type:jsonb"`
+	Result      *JobResult   `json:"result,omitempty" gorm:"type:jsonb"`
+	RunAt       time.Time    `json:"run_at" gorm:"not null"`
+	LockedAt    *time.Time   `json:"locked_at,omitempty"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	FailedAt    *time.Time   `json:"failed_at,omitempty"`
+	Retries     int          `json:"retries" gorm:"not null;default:0"`
+	Traceparent string       `json:"traceparent,omitempty" gorm:"size:55"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// JobProgress reports how far a running job has got: the step it is on and
+// how many of its units of work are done
+type JobProgress struct {
+	Step  string `json:"step"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// Scan reads progress from a JSONB column
+func (p *JobProgress) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return fmt.Errorf("cannot scan %T into JobProgress", value)
+}
+
+// Value writes progress to a JSONB column
+func (p JobProgress) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// JobResult is what a completed job produced: links to the files it wrote and
+// a summary of its outcome
+type JobResult struct {
+	Links   map[string]string `json:"links,omitempty"`
+	Summary json.RawMessage   `json:"summary,omitempty"`
+}
+
+// Scan reads a result from a JSONB column
+func (r *JobResult) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	}
+	return fmt.Errorf("cannot scan %T into JobResult", value)
+}
+
+// Value writes a result to a JSONB column
+func (r JobResult) Value() (driver.Value, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// TableName returns the table name for the Job model
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// BeforeCreate hook to generate UUID
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = ids.New()
+	}
+	return nil
+}
+
+// DecodePayload unmarshals the job payload into v
+func (j *Job) DecodePayload(v interface{}) error {
+	return json.Unmarshal([]byte(j.Payload), v)
+}
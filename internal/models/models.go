@@ -7,9 +7,42 @@ import (
 // AllModels returns a slice of all model structs for auto-migration
 func AllModels() []interface{} {
 	return []interface{}{
+		&Tenant{},
 		&Author{},
 		&Category{},
+		&Publisher{},
 		&Book{},
+		&Job{},
+		&OutboxEvent{},
+		&Webhook{},
+		&WebhookDelivery{},
+		&ReportDefinition{},
+		&User{},
+		&UserIdentity{},
+		&Session{},
+		&APIKey{},
+		&Translation{},
+		&PriceHistory{},
+		&StockMovement{},
+		&Warehouse{},
+		&WarehouseStock{},
+		&StockTransfer{},
+		&Supplier{},
+		&SupplierSync{},
+		&BookListing{},
+		&Promotion{},
+		&RentalPeriod{},
+		&SlugHistory{},
+		&BookPreview{},
+		&SavedSearch{},
+		&StockAlert{},
+		&BookView{},
+		&UserPreferences{},
+		&ConsentRecord{},
+		&NotificationSettings{},
+		&BookRevision{},
+		&SecurityEvent{},
+		&ArchiveManifest{},
 	}
 }
 
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification topics
+const (
+	TopicBackInStock = "back_in_stock"
+	TopicSavedSearch = "saved_search"
+	TopicLowStock    = "low_stock"
+)
+
+// Notification channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+	ChannelSlack = "slack"
+)
+
+// NotificationTopics lists the topics every user can choose channels for
+var NotificationTopics = []string{TopicBackInStock, TopicSavedSearch}
+
+// AdminNotificationTopics lists the topics only admins receive
+var AdminNotificationTopics = []string{TopicLowStock}
+
+// NotificationChannels lists every notification channel
+var NotificationChannels = []string{ChannelEmail, ChannelSMS, ChannelPush, ChannelSlack}
+
+// defaultTopicChannels are the channels of topics a user has not chosen
+// channels for. Customer alerts go out by email as they always have; admins
+// opt in to low stock alerts.
+var defaultTopicChannels = map[string][]string{
+	TopicBackInStock: {ChannelEmail},
+	TopicSavedSearch: {ChannelEmail},
+	TopicLowStock:    {},
+}
+
+// TopicChannels maps notification topics to the channels they are sent
+// over, stored as a JSONB object
+type TopicChannels map[string][]string
+
+// Value implements driver.Valuer
+func (t TopicChannels) Value() (driver.Value, error) {
+	if t == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string][]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (t *TopicChannels) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*map[string][]string)(t))
+	case string:
+		return json.Unmarshal([]byte(v), (*map[string][]string)(t))
+	default:
+		return fmt.Errorf("cannot scan %T into TopicChannels", value)
+	}
+}
+
+// NotificationSettings holds the channels a user receives each notification
+// topic over and the contact details those channels need. Users without a
+// row get the default channels.
+type NotificationSettings struct {
+	UserID          uuid.UUID     `json:"user_id" gorm:"type:uuid;primary_key"`
+	TenantID        uuid.UUID     `json:"-" gorm:"type:uuid;not null;index"`
+	Channels        TopicChannels `json:"channels" gorm:"type:jsonb;not null;default:'{}'"`
+	Phone           string        `json:"phone,omitempty" gorm:"size:512;serializer:pii"`
+	PushTokens      StringList    `json:"push_tokens" gorm:"type:jsonb;not null;default:'[]'"`
+	SlackWebhookURL string        `json:"slack_webhook_url,omitempty" gorm:"size:4096;serializer:pii"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the NotificationSettings model
+func (NotificationSettings) TableName() string {
+	return "notification_settings"
+}
+
+// ChannelsFor returns the channels the topic is sent over
+func (s *NotificationSettings) ChannelsFor(topic string) []string {
+	if channels, ok := s.Channels[topic]; ok {
+		return channels
+	}
+	return defaultTopicChannels[topic]
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEvent represents a domain event stored in the transactional outbox
+type OutboxEvent struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID      uuid.UUID  `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	EventType     string     `json:"event_type" gorm:"not null;size:100"`
+	AggregateType string     `json:"aggregate_type" gorm:"not null;size:50"`
+	AggregateID   uuid.UUID  `json:"aggregate_id" gorm:"not null;type:uuid"`
+	Payload       string     `json:"payload" gorm:"type:jsonb;not null;default:'{}'"`
+	Traceparent   string     `json:"traceparent,omitempty" gorm:"size:55"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error,omitempty" gorm:"type:text"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for the OutboxEvent model
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// BeforeCreate hook to generate UUID
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = ids.New()
+	}
+	return nil
+}
+
+// DecodePayload unmarshals the event payload into v
+func (e *OutboxEvent) DecodePayload(v interface{}) error {
+	return json.Unmarshal([]byte(e.Payload), v)
+}
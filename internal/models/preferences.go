@@ -0,0 +1,72 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Consent purposes
+const (
+	ConsentEmailMarketing = "email_marketing"
+	ConsentDataProcessing = "data_processing"
+)
+
+// ConsentPurposes lists every consent purpose
+var ConsentPurposes = []string{ConsentEmailMarketing, ConsentDataProcessing}
+
+// UserPreferences holds a customer's current consents. Each consent records
+// when it was last given or withdrawn and the version of the privacy policy it
+// was given under. Users without a row have given no consent.
+type UserPreferences struct {
+	UserID               uuid.UUID  `json:"user_id" gorm:"type:uuid;primary_key"`
+	TenantID             uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	EmailMarketing       bool       `json:"email_marketing" gorm:"not null;default:false"`
+	EmailMarketingAt     *time.Time `json:"email_marketing_at,omitempty"`
+	EmailMarketingPolicy string     `json:"email_marketing_policy,omitempty" gorm:"size:50"`
+	DataProcessing       bool       `json:"data_processing" gorm:"not null;default:false"`
+	DataProcessingAt     *time.Time `json:"data_processing_at,omitempty"`
+	DataProcessingPolicy string     `json:"data_processing_policy,omitempty" gorm:"size:50"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the UserPreferences model
+func (UserPreferences) TableName() string {
+	return "user_preferences"
+}
+
+// ConsentRecord is an entry in the append-only history of a customer giving
+// or withdrawing a consent, kept as evidence of what was agreed to and when
+type ConsentRecord struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID      uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_consent_records_user_recorded_at"`
+	Purpose       string    `json:"purpose" gorm:"not null;size:50"`
+	Granted       bool      `json:"granted" gorm:"not null"`
+	PolicyVersion string    `json:"policy_version" gorm:"not null;size:50"`
+	IPAddress     string    `json:"ip_address,omitempty" gorm:"size:64"`
+	UserAgent     string    `json:"user_agent,omitempty" gorm:"size:512"`
+	RecordedAt    time.Time `json:"recorded_at" gorm:"not null;index:idx_consent_records_user_recorded_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the ConsentRecord model
+func (ConsentRecord) TableName() string {
+	return "consent_records"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *ConsentRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Price change sources
+const (
+	PriceSourceInitial  = "initial"
+	PriceSourceAPI      = "api"
+	PriceSourceImport   = "import"
+	PriceSourceONIX     = "onix"
+	PriceSourceSupplier = "supplier"
+)
+
+// PriceHistory records one price a book has had and when it took effect
+type PriceHistory struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID      uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	BookID        uuid.UUID `json:"book_id" gorm:"type:uuid;not null;index:idx_price_history_book_changed_at"`
+	Price         float64   `json:"price" gorm:"not null;type:decimal(10,2)"`
+	PreviousPrice *float64  `json:"previous_price,omitempty" gorm:"type:decimal(10,2)"`
+	Source        string    `json:"source" gorm:"not null;size:20"`
+	ChangedAt     time.Time `json:"changed_at" gorm:"not null;index:idx_price_history_book_changed_at"`
+
+	// Relationships
+	Book Book `json:"-" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the PriceHistory model
+func (PriceHistory) TableName() string {
+	return "price_history"
+}
+
+// BeforeCreate hook to generate UUID
+func (p *PriceHistory) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Promotion is a discount on a book or on every book in a category between
+// StartsAt and EndsAt. The discount is either a fixed promotional Price, for
+// book promotions only, or a DiscountPercent off the book's price. Active is
+// maintained by the promotion sync task and decides whether the discount applies.
+type Promotion struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID        uuid.UUID  `json:"-" gorm:"type:uuid;not null"`
+	Name            string     `json:"name" gorm:"not null;size:255"`
+	BookID          *uuid.UUID `json:"book_id,omitempty" gorm:"type:uuid;index"`
+	CategoryID      *uuid.UUID `json:"category_id,omitempty" gorm:"type:uuid;index"`
+	Price           *float64   `json:"price,omitempty" gorm:"type:decimal(10,2)"`
+	DiscountPercent *float64   `json:"discount_percent,omitempty" gorm:"type:decimal(5,2)"`
+	StartsAt        time.Time  `json:"starts_at" gorm:"not null"`
+	EndsAt          time.Time  `json:"ends_at" gorm:"not null"`
+	Active          bool       `json:"active" gorm:"not null;default:false;index"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relationships
+	Book     *Book     `json:"-" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Category *Category `json:"-" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the Promotion model
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// BeforeCreate hook to generate UUID
+func (p *Promotion) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = ids.New()
+	}
+	return nil
+}
+
+// InEffect reports whether the promotion's schedule covers t
+func (p *Promotion) InEffect(t time.Time) bool {
+	return !t.Before(p.StartsAt) && t.Before(p.EndsAt)
+}
+
+// PriceFor returns the promotional price of a book whose regular price is
+// price, rounded to cents
+func (p *Promotion) PriceFor(price float64) float64 {
+	if p.Price != nil {
+		return *p.Price
+	}
+	if p.DiscountPercent != nil {
+		return math.Round(price*(100-*p.DiscountPercent)) / 100
+	}
+	return price
+}
+
+// AppliedPromotion identifies the promotion that sets a book's effective price
+type AppliedPromotion struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	EndsAt time.Time `json:"ends_at"`
+}
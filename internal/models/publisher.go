@@ -0,0 +1,32 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Publisher represents a book publisher
+type Publisher struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID  uuid.UUID      `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_publishers_tenant_name"`
+	Name      string         `json:"name" gorm:"not null;size:255;uniqueIndex:uni_publishers_tenant_name" validate:"required,min=1,max=255"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for the Publisher model
+func (Publisher) TableName() string {
+	return "publishers"
+}
+
+// BeforeCreate hook to generate UUID
+func (p *Publisher) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RentalPeriod is the loan of one copy of a book to a user from StartsAt until
+// DueAt. A copy stays out until ReturnedAt is set, even past its due date, and
+// Late flags rentals returned or still out after DueAt.
+type RentalPeriod struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID   uuid.UUID  `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_rental_periods_tenant_short_code"`
+	ShortCode  string     `json:"short_code" gorm:"not null;size:16;uniqueIndex:uni_rental_periods_tenant_short_code"`
+	BookID     uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index:idx_rental_periods_book_starts_at"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null;index:idx_rental_periods_book_starts_at"`
+	DueAt      time.Time  `json:"due_at" gorm:"not null"`
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+	Late       bool       `json:"late" gorm:"not null;default:false"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relationships
+	Book Book `json:"book,omitempty" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// TableName returns the table name for the RentalPeriod model
+func (RentalPeriod) TableName() string {
+	return "rental_periods"
+}
+
+// BeforeCreate hook to generate UUID and short code
+func (r *RentalPeriod) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = ids.New()
+	}
+	if r.ShortCode == "" {
+		r.ShortCode = ids.NewShortCode()
+	}
+	return nil
+}
+
+// OutUntil returns when the rented copy is back as of now: its return time,
+// its due date while not yet due, or nil while it is out past its due date
+func (r *RentalPeriod) OutUntil(now time.Time) *time.Time {
+	if r.ReturnedAt != nil {
+		return r.ReturnedAt
+	}
+	if r.DueAt.After(now) {
+		return &r.DueAt
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Report types
+const (
+	ReportTypeInventory      = "inventory"
+	ReportTypeLowStock       = "low_stock"
+	ReportTypeNewTitles      = "new_titles"
+	ReportTypeCatalogSummary = "catalog_summary"
+	ReportTypeTopRentals     = "top_rentals"
+)
+
+// Report formats
+const (
+	ReportFormatCSV = "csv"
+	ReportFormatPDF = "pdf"
+)
+
+// Report periods, which set the date range covered by each run
+const (
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// Report run statuses
+const (
+	ReportRunSucceeded = "succeeded"
+	ReportRunFailed    = "failed"
+)
+
+// ReportDefinition is a recurring report emailed to a list of recipients
+type ReportDefinition struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID   uuid.UUID      `json:"-" gorm:"type:uuid;not null;index"`
+	Name       string         `json:"name" gorm:"not null;size:255"`
+	ReportType string         `json:"report_type" gorm:"not null;size:50"`
+	Format     string         `json:"format" gorm:"not null;size:10;default:csv"`
+	Period     string         `json:"period" gorm:"not null;size:10;default:week"`
+	Schedule   string         `json:"schedule" gorm:"not null;size:100"`
+	Recipients StringList     `json:"recipients" gorm:"type:jsonb;not null;default:'[]'"`
+	Enabled    bool           `json:"enabled" gorm:"not null;default:true"`
+	NextRunAt  *time.Time     `json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time     `json:"last_run_at,omitempty"`
+	LastStatus string         `json:"last_status,omitempty" gorm:"size:20"`
+	LastError  string         `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for the ReportDefinition model
+func (ReportDefinition) TableName() string {
+	return "report_definitions"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *ReportDefinition) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"bookstore-api/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// The business rules of models, shared by every path that validates them
+func init() {
+	utils.RegisterMessage("one_of_fields", "exactly one of %[2]s is required")
+	utils.RegisterMessage("book_only", "%[1]s can only apply to a book")
+	utils.RegisterMessage("between", "%[1]s must be between %[2]s")
+	utils.RegisterStructRule(promotionRule, Promotion{})
+}
+
+// promotionRule checks the promotion's target, discount, and schedule: it
+// applies to a book or a category, with a fixed price, for books only, or a
+// discount percentage, and ends after it starts
+func promotionRule(sl validator.StructLevel) {
+	promotion := sl.Current().Interface().(Promotion)
+
+	if (promotion.BookID == nil) == (promotion.CategoryID == nil) {
+		sl.ReportError(promotion.BookID, "book_id", "BookID", "one_of_fields", "book_id or category_id")
+	}
+	if (promotion.Price == nil) == (promotion.DiscountPercent == nil) {
+		sl.ReportError(promotion.Price, "price", "Price", "one_of_fields", "price or discount_percent")
+	}
+	if promotion.Price != nil {
+		if promotion.BookID == nil {
+			sl.ReportError(*promotion.Price, "price", "Price", "book_only", "")
+		}
+		if *promotion.Price < 0 {
+			sl.ReportError(*promotion.Price, "price", "Price", "min", "0")
+		}
+	}
+	if promotion.DiscountPercent != nil && (*promotion.DiscountPercent <= 0 || *promotion.DiscountPercent >= 100) {
+		sl.ReportError(*promotion.DiscountPercent, "discount_percent", "DiscountPercent", "between", "0 and 100")
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		sl.ReportError(promotion.EndsAt, "ends_at", "EndsAt", "gtfield", "starts_at")
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Security event types
+const (
+	SecurityEventLoginSucceeded   = "login_succeeded"
+	SecurityEventLoginFailed      = "login_failed"
+	SecurityEventRefreshFailed    = "refresh_failed"
+	SecurityEventRefreshReused    = "refresh_token_reused"
+	SecurityEventLockout          = "lockout"
+	SecurityEventLockedOutAttempt = "locked_out_attempt"
+	SecurityEventCaptchaFailed    = "captcha_failed"
+	SecurityEventNewDevice        = "login_new_device"
+)
+
+// SecurityEvent is an entry in the append-only log of authentication events,
+// such as failed logins, lockouts, and sign-ins from a new device. UserID is
+// set when the event could be tied to an account. The table is partitioned by
+// month on created_at.
+type SecurityEvent struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID  uuid.UUID  `json:"-" gorm:"type:uuid;not null;index:idx_security_events_tenant_created_at"`
+	Type      string     `json:"type" gorm:"not null;size:50"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index:idx_security_events_user_created_at"`
+	IPAddress string     `json:"ip_address,omitempty" gorm:"size:64"`
+	UserAgent string     `json:"user_agent,omitempty" gorm:"size:512"`
+	Details   StringMap  `json:"details" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_security_events_tenant_created_at;index:idx_security_events_user_created_at"`
+}
+
+// TableName returns the table name for the SecurityEvent model
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
+
+// BeforeCreate hook to generate UUID
+func (e *SecurityEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SlugHistory is a slug a book, author, or category had before it was
+// renamed, kept so links using it still resolve
+type SlugHistory struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_slug_history_slug"`
+	// EntityTable is the table of the entity: books, authors, or categories
+	EntityTable string    `json:"entity_table" gorm:"not null;size:20;uniqueIndex:uni_slug_history_slug"`
+	EntityID    uuid.UUID `json:"entity_id" gorm:"type:uuid;not null;index"`
+	Slug        string    `json:"slug" gorm:"not null;size:255;uniqueIndex:uni_slug_history_slug"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for the SlugHistory model
+func (SlugHistory) TableName() string {
+	return "slug_history"
+}
+
+// BeforeCreate hook to generate UUID
+func (h *SlugHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Stock movement reasons
+const (
+	StockReasonOpeningBalance = "opening_balance"
+	StockReasonInitial        = "initial"
+	StockReasonSet            = "set"
+	StockReasonAdjustment     = "adjustment"
+	StockReasonImport         = "import"
+	StockReasonReconciliation = "reconciliation"
+	StockReasonWarehouse      = "warehouse"
+	StockReasonSupplier       = "supplier"
+)
+
+// StockMovement records one change to a book's stock. Movements are only
+// appended; in the event-sourced inventory mode a book's stock is the sum of
+// its movements' deltas. The table is partitioned by month on created_at.
+type StockMovement struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	BookID   uuid.UUID `json:"book_id" gorm:"type:uuid;not null;index:idx_stock_movements_book_created_at"`
+	Delta    int       `json:"delta" gorm:"not null"`
+	// StockAfter is the book's stock once the movement was applied
+	StockAfter int       `json:"stock_after" gorm:"not null"`
+	Reason     string    `json:"reason" gorm:"not null;size:30"`
+	Note       string    `json:"note,omitempty" gorm:"size:255"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index:idx_stock_movements_book_created_at"`
+
+	// Relationships
+	Book Book `json:"-" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = ids.New()
+	}
+	return nil
+}
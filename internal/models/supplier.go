@@ -0,0 +1,147 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supplier feed formats
+const (
+	SupplierFormatCSV  = "csv"
+	SupplierFormatJSON = "json"
+)
+
+// Supplier sync statuses
+const (
+	SupplierSyncSucceeded = "succeeded"
+	SupplierSyncFailed    = "failed"
+)
+
+// Supplier is a dropshipping supplier whose availability and price feed is
+// pulled over HTTP and applied to the books it lists by ISBN
+type Supplier struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_suppliers_tenant_name"`
+	Name     string    `json:"name" gorm:"not null;size:255;uniqueIndex:uni_suppliers_tenant_name"`
+	FeedURL  string    `json:"feed_url" gorm:"not null;size:2048"`
+	Format   string    `json:"format" gorm:"not null;size:10"`
+	// AuthHeader and AuthValue are a header sent with feed requests, such as
+	// an API key. The value is never returned.
+	AuthHeader string `json:"auth_header,omitempty" gorm:"size:100"`
+	AuthValue  string `json:"-" gorm:"size:4096;serializer:pii"`
+	// WarehouseID, when set, is the warehouse the supplier's stock is held
+	// at, and the feed sets the books' stock there rather than their total
+	WarehouseID  *uuid.UUID `json:"warehouse_id,omitempty" gorm:"type:uuid"`
+	UpdatePrices bool       `json:"update_prices" gorm:"not null;default:true"`
+	UpdateStock  bool       `json:"update_stock" gorm:"not null;default:true"`
+	// Enabled suppliers are synced on the scheduler's supplier_sync schedule
+	Enabled      bool       `json:"enabled" gorm:"not null;default:true"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relationships
+	Warehouse *Warehouse `json:"-" gorm:"foreignKey:WarehouseID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// TableName returns the table name for the Supplier model
+func (Supplier) TableName() string {
+	return "suppliers"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Supplier) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = ids.New()
+	}
+	return nil
+}
+
+// SupplierChange is a change a supplier sync made, or would make in a dry
+// run, to a book's price or stock
+type SupplierChange struct {
+	BookID uuid.UUID   `json:"book_id"`
+	ISBN   string      `json:"isbn"`
+	Field  string      `json:"field"`
+	From   interface{} `json:"from"`
+	To     interface{} `json:"to"`
+	// Error is why the change could not be applied
+	Error string `json:"error,omitempty"`
+}
+
+// SupplierChanges is a list of supplier changes, stored as a JSONB array
+type SupplierChanges []SupplierChange
+
+// Value implements driver.Valuer
+func (c SupplierChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]SupplierChange(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (c *SupplierChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]SupplierChange)(c))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]SupplierChange)(c))
+	default:
+		return fmt.Errorf("cannot scan %T into SupplierChanges", value)
+	}
+}
+
+// SupplierSync is a run of a supplier's feed against the catalog: the rows
+// it read, how many matched a book, and each change it applied
+type SupplierSync struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID   uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	SupplierID uuid.UUID `json:"supplier_id" gorm:"type:uuid;not null;index:idx_supplier_syncs_supplier_started"`
+	Status     string    `json:"status" gorm:"not null;size:20"`
+	// DryRun syncs record the changes they would make without applying them
+	DryRun bool `json:"dry_run" gorm:"not null;default:false"`
+	Rows   int  `json:"rows" gorm:"not null;default:0"`
+	// Invalid rows have no valid ISBN, or a negative price or stock
+	Invalid   int `json:"invalid" gorm:"not null;default:0"`
+	Matched   int `json:"matched" gorm:"not null;default:0"`
+	Unmatched int `json:"unmatched" gorm:"not null;default:0"`
+	// Updated is the number of books changed, and Failed the number whose
+	// changes could not be applied
+	Updated    int             `json:"updated" gorm:"not null;default:0"`
+	Failed     int             `json:"failed" gorm:"not null;default:0"`
+	Changes    SupplierChanges `json:"changes" gorm:"type:jsonb;not null;default:'[]'"`
+	Error      string          `json:"error,omitempty" gorm:"type:text"`
+	StartedAt  time.Time       `json:"started_at" gorm:"not null;index:idx_supplier_syncs_supplier_started"`
+	FinishedAt time.Time       `json:"finished_at" gorm:"not null"`
+
+	// Relationships
+	Supplier *Supplier `json:"-" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the SupplierSync model
+func (SupplierSync) TableName() string {
+	return "supplier_syncs"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *SupplierSync) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultTenantID is the tenant that owns all data created before multi-tenancy
+var DefaultTenantID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Tenant is a storefront whose data is isolated from every other storefront
+type Tenant struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	Slug   string    `json:"slug" gorm:"not null;size:63;uniqueIndex:uni_tenants_slug"`
+	Name   string    `json:"name" gorm:"not null;size:255"`
+	Domain *string   `json:"domain,omitempty" gorm:"size:255;uniqueIndex:uni_tenants_domain"`
+	Active bool      `json:"active" gorm:"not null;default:true"`
+
+	// Per-tenant overrides of global configuration; zero uses the global value
+	RateLimit         int `json:"rate_limit,omitempty" gorm:"not null;default:0"`
+	StrictRateLimit   int `json:"strict_rate_limit,omitempty" gorm:"not null;default:0"`
+	LowStockThreshold int `json:"low_stock_threshold,omitempty" gorm:"not null;default:0"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for the Tenant model
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *Tenant) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = ids.New()
+	}
+	return nil
+}
+
+// LowStockThresholdOr returns the tenant's low stock threshold, or fallback when unset
+func (t *Tenant) LowStockThresholdOr(fallback int) int {
+	if t != nil && t.LowStockThreshold > 0 {
+		return t.LowStockThreshold
+	}
+	return fallback
+}
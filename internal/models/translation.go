@@ -0,0 +1,47 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Translatable entity types
+const (
+	TranslationEntityBook     = "book"
+	TranslationEntityCategory = "category"
+)
+
+// TranslatableFields lists the fields of each entity type that may be translated
+var TranslatableFields = map[string][]string{
+	TranslationEntityBook:     {"title", "description"},
+	TranslationEntityCategory: {"name", "description"},
+}
+
+// Translation is the value of one field of a book or category in one locale
+type Translation struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID   uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_translations_entity_locale_field"`
+	EntityType string    `json:"entity_type" gorm:"not null;size:20;uniqueIndex:uni_translations_entity_locale_field"`
+	EntityID   uuid.UUID `json:"entity_id" gorm:"type:uuid;not null;uniqueIndex:uni_translations_entity_locale_field;index:idx_translations_entity"`
+	Locale     string    `json:"locale" gorm:"not null;size:35;uniqueIndex:uni_translations_entity_locale_field;index:idx_translations_entity"`
+	Field      string    `json:"field" gorm:"not null;size:50;uniqueIndex:uni_translations_entity_locale_field"`
+	Value      string    `json:"value" gorm:"type:text;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the Translation model
+func (Translation) TableName() string {
+	return "translations"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *Translation) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/pii"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// User roles
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+// User is a storefront customer or staff member
+type User struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID    uuid.UUID      `json:"-" gorm:"type:uuid;not null;index"`
+	Email       *string        `json:"email,omitempty" gorm:"size:512;serializer:pii"`
+	EmailHash   *string        `json:"-" gorm:"size:64"`
+	Name        string         `json:"name" gorm:"size:255"`
+	AvatarURL   string         `json:"avatar_url,omitempty" gorm:"size:2048"`
+	Role        string         `json:"role" gorm:"not null;size:20;default:customer"`
+	LastLoginAt *time.Time     `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Relationships
+	Identities []UserIdentity `json:"identities,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TableName returns the table name for the User model
+func (User) TableName() string {
+	return "users"
+}
+
+// BeforeCreate hook to generate UUID and the email's blind index
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = ids.New()
+	}
+	if u.Email != nil {
+		u.EmailHash = pii.GetKeyring().Hash(*u.Email)
+	}
+	return nil
+}
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID  uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_user_identities_provider_subject"`
+	UserID    uuid.UUID `json:"user_id" gorm:"not null;type:uuid;index"`
+	Provider  string    `json:"provider" gorm:"not null;size:50;uniqueIndex:uni_user_identities_provider_subject"`
+	Subject   string    `json:"subject" gorm:"not null;size:255;uniqueIndex:uni_user_identities_provider_subject"`
+	Email     string    `json:"email,omitempty" gorm:"size:512;serializer:pii"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the UserIdentity model
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// BeforeCreate hook to generate UUID
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = ids.New()
+	}
+	return nil
+}
+
+// Session is a signed-in device. The refresh token is stored only as a hash and
+// rotates on every refresh; presenting the previous token again revokes the session.
+type Session struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID          uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"not null;type:uuid;index"`
+	RefreshTokenHash  string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	PreviousTokenHash *string    `json:"-" gorm:"size:64;index"`
+	UserAgent         string     `json:"user_agent,omitempty" gorm:"size:512"`
+	IPAddress         string     `json:"ip_address,omitempty" gorm:"size:64"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	ExpiresAt         time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the Session model
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = ids.New()
+	}
+	return nil
+}
+
+// Active reports whether the session can still be used at t
+func (s *Session) Active(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
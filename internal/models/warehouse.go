@@ -0,0 +1,115 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Warehouse is a location stock is held at and orders are fulfilled from
+type Warehouse struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:uni_warehouses_tenant_code"`
+	// Code is the short name of the warehouse, unique per tenant
+	Code      string    `json:"code" gorm:"not null;size:50;uniqueIndex:uni_warehouses_tenant_code"`
+	Name      string    `json:"name" gorm:"not null;size:255"`
+	Address   string    `json:"address,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the Warehouse model
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// BeforeCreate hook to generate UUID
+func (w *Warehouse) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = ids.New()
+	}
+	return nil
+}
+
+// WarehouseStock is the stock of a book held at a warehouse. A book's stock
+// is its total on hand; the part not held at any warehouse is unallocated.
+type WarehouseStock struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID    uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	WarehouseID uuid.UUID `json:"warehouse_id" gorm:"type:uuid;not null;uniqueIndex:uni_warehouse_stocks_warehouse_book"`
+	BookID      uuid.UUID `json:"book_id" gorm:"type:uuid;not null;uniqueIndex:uni_warehouse_stocks_warehouse_book;index"`
+	Quantity    int       `json:"quantity" gorm:"not null;default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Warehouse *Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Book      *Book      `json:"book,omitempty" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the WarehouseStock model
+func (WarehouseStock) TableName() string {
+	return "warehouse_stocks"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *WarehouseStock) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = ids.New()
+	}
+	return nil
+}
+
+// StockTransfer moves stock of a book from one warehouse to another. A
+// transfer without a source allocates unallocated stock to its destination,
+// and one without a destination returns stock to unallocated. Transfers
+// never change the book's total stock.
+type StockTransfer struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID        uuid.UUID  `json:"-" gorm:"type:uuid;not null"`
+	BookID          uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index"`
+	FromWarehouseID *uuid.UUID `json:"from_warehouse_id,omitempty" gorm:"type:uuid;index"`
+	ToWarehouseID   *uuid.UUID `json:"to_warehouse_id,omitempty" gorm:"type:uuid;index"`
+	Quantity        int        `json:"quantity" gorm:"not null"`
+	Note            string     `json:"note,omitempty" gorm:"size:255"`
+	// CreatedBy is nil for transfers made with admin API keys
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index"`
+
+	// Relationships
+	Book          *Book      `json:"-" gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	FromWarehouse *Warehouse `json:"-" gorm:"foreignKey:FromWarehouseID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	ToWarehouse   *Warehouse `json:"-" gorm:"foreignKey:ToWarehouseID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// TableName returns the table name for the StockTransfer model
+func (StockTransfer) TableName() string {
+	return "stock_transfers"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *StockTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = ids.New()
+	}
+	return nil
+}
+
+// StockAvailability is where a book's stock is held: its total stock, the
+// part held at warehouses, and each warehouse holding some
+type StockAvailability struct {
+	Total       int                     `json:"total"`
+	Allocated   int                     `json:"allocated"`
+	Unallocated int                     `json:"unallocated"`
+	Warehouses  []WarehouseAvailability `json:"warehouses"`
+}
+
+// WarehouseAvailability is the stock of a book held at one warehouse
+type WarehouseAvailability struct {
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Quantity    int       `json:"quantity"`
+}
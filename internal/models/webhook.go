@@ -0,0 +1,81 @@
+package models
+
+import (
+	"bookstore-api/internal/ids"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook represents an outbound webhook subscription
+type Webhook struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID    uuid.UUID      `json:"-" gorm:"type:uuid;not null;index"`
+	URL         string         `json:"url" gorm:"not null;size:2048" validate:"required,url"`
+	Secret      string         `json:"-" gorm:"not null;size:255"`
+	EventTypes  StringList     `json:"event_types" gorm:"type:jsonb;not null;default:'[]'"`
+	Description string         `json:"description" gorm:"type:text"`
+	Active      bool           `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// BeforeCreate hook to generate UUID
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = ids.New()
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook wants events of the given type.
+// An empty event type list or "*" subscribes to all events.
+func (w *Webhook) Subscribes(eventType string) bool {
+	return len(w.EventTypes) == 0 || w.EventTypes.Contains("*") || w.EventTypes.Contains(eventType)
+}
+
+// WebhookDelivery records delivery of one event to one webhook
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v7()"`
+	TenantID       uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	WebhookID      uuid.UUID  `json:"webhook_id" gorm:"not null;type:uuid;index"`
+	EventID        uuid.UUID  `json:"event_id" gorm:"not null;type:uuid"`
+	EventType      string     `json:"event_type" gorm:"not null;size:100"`
+	Payload        string     `json:"payload" gorm:"type:jsonb;not null"`
+	Status         string     `json:"status" gorm:"not null;size:20;default:pending"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `json:"response_body,omitempty" gorm:"type:text"`
+	Error          string     `json:"error,omitempty" gorm:"type:text"`
+	DurationMs     int64      `json:"duration_ms"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate hook to generate UUID
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package notifications sends user and admin notifications, such as stock
+// alerts, over the channels each user chose for the notification's topic:
+// email, SMS through Twilio, push through Firebase Cloud Messaging, and
+// Slack incoming webhooks.
+package notifications
+
+import (
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/mail"
+	"bookstore-api/internal/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrNoAddress is returned by channels when the recipient has no address on
+// the channel, such as a phone number for SMS
+var ErrNoAddress = errors.New("recipient has no address on the channel")
+
+// Notification is a message on a topic
+type Notification struct {
+	Topic   string `json:"topic"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Recipient is where a user receives notifications on each channel
+type Recipient struct {
+	Email           string
+	Phone           string
+	PushTokens      []string
+	SlackWebhookURL string
+}
+
+// Channel delivers notifications over one medium
+type Channel interface {
+	// Name is the channel name users choose in their settings
+	Name() string
+	// Enabled reports whether the channel is configured to send
+	Enabled() bool
+	// Send delivers the notification to the recipient. Errors wrapped with
+	// jobs.Permanent will not succeed on retry.
+	Send(ctx context.Context, recipient *Recipient, notification *Notification) error
+}
+
+// EmailChannel sends notifications by SMTP email
+type EmailChannel struct {
+	mailer *mail.Mailer
+}
+
+// NewEmailChannel creates an email channel sending with mailer
+func NewEmailChannel(mailer *mail.Mailer) *EmailChannel {
+	return &EmailChannel{mailer: mailer}
+}
+
+// Name implements Channel
+func (c *EmailChannel) Name() string { return "email" }
+
+// Enabled implements Channel
+func (c *EmailChannel) Enabled() bool { return c.mailer.Enabled() }
+
+// Send implements Channel
+func (c *EmailChannel) Send(ctx context.Context, recipient *Recipient, notification *Notification) error {
+	if recipient.Email == "" {
+		return ErrNoAddress
+	}
+	return c.mailer.Send(&mail.Message{
+		To:      []string{recipient.Email},
+		Subject: notification.Subject,
+		Body:    notification.Body,
+		TraceID: tracing.TraceID(ctx),
+	})
+}
+
+// SlackChannel posts notifications to each user's own Slack incoming webhook
+type SlackChannel struct {
+	client *http.Client
+}
+
+// NewSlackChannel creates a Slack channel posting with client
+func NewSlackChannel(client *http.Client) *SlackChannel {
+	return &SlackChannel{client: client}
+}
+
+// Name implements Channel
+func (c *SlackChannel) Name() string { return "slack" }
+
+// Enabled implements Channel. Slack needs no server configuration; each user
+// brings their own webhook URL.
+func (c *SlackChannel) Enabled() bool { return true }
+
+// Send implements Channel
+func (c *SlackChannel) Send(ctx context.Context, recipient *Recipient, notification *Notification) error {
+	if recipient.SlackWebhookURL == "" {
+		return ErrNoAddress
+	}
+	body, err := json.Marshal(map[string]string{
+		"text": "*" + notification.Subject + "*\n" + notification.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient.SlackWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("failed to build slack request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(c.client, req, "slack")
+}
+
+// statusError is an unsuccessful response from a notification service
+type statusError struct {
+	service string
+	status  int
+	body    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s responded with status %d: %s", e.service, e.status, e.body)
+}
+
+// do sends req and checks its response. Client errors other than rate
+// limiting are permanent: the address or credentials are wrong and retrying
+// will not help.
+func do(client *http.Client, req *http.Request, service string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = &statusError{service: service, status: resp.StatusCode, body: string(bytes.TrimSpace(body))}
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return jobs.Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package notifications
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/mail"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobType is the background job type that delivers a notification over one
+// channel
+const JobType = "notification.deliver"
+
+var (
+	dispatcher *Dispatcher
+	once       sync.Once
+)
+
+// deliverPayload is the payload of notification delivery jobs
+type deliverPayload struct {
+	TenantID     uuid.UUID    `json:"tenant_id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	Channel      string       `json:"channel"`
+	Notification Notification `json:"notification"`
+}
+
+// Dispatcher fans notifications out to the channels each user chose for
+// their topic, delivering each channel in its own job so a failing channel
+// is retried without repeating the others
+type Dispatcher struct {
+	queue               *jobs.Queue
+	notificationService *services.NotificationService
+	channels            map[string]Channel
+}
+
+// NewDispatcher creates a dispatcher delivering over channels on queue, to
+// the channels users chose in svc
+func NewDispatcher(queue *jobs.Queue, svc *services.Services, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{
+		queue:               queue,
+		notificationService: svc.Notifications,
+		channels:            make(map[string]Channel),
+	}
+	for _, channel := range channels {
+		d.channels[channel.Name()] = channel
+	}
+	return d
+}
+
+// InitializeDispatcher initializes the shared dispatcher with every channel
+// the configuration enables
+func InitializeDispatcher(cfg *config.Config, svc *services.Services) {
+	once.Do(func() {
+		timeout := cfg.Notifications.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		client := &http.Client{Timeout: timeout}
+
+		push, err := NewPushChannel(client, cfg.Notifications.FCMProjectID, cfg.Notifications.FCMCredentialsFile)
+		if err != nil {
+			log.Printf("Push notifications disabled: %v", err)
+			push, _ = NewPushChannel(client, "", "")
+		}
+		dispatcher = NewDispatcher(jobs.GetQueue(), svc,
+			NewEmailChannel(mail.NewMailer(cfg.Mail)),
+			NewSMSChannel(client, cfg.Notifications.TwilioAccountSID, cfg.Notifications.TwilioAuthToken, cfg.Notifications.TwilioFromNumber),
+			push,
+			NewSlackChannel(client),
+		)
+	})
+}
+
+// GetDispatcher returns the shared dispatcher
+func GetDispatcher() *Dispatcher {
+	if dispatcher == nil {
+		log.Fatal("Notification dispatcher not initialized. Call InitializeDispatcher first.")
+	}
+	return dispatcher
+}
+
+// Notify schedules delivery of the notification to the user over each
+// channel the user chose for its topic. ctx carries the user's tenant.
+// Channels the server does not have configured are skipped.
+func (d *Dispatcher) Notify(ctx context.Context, userID uuid.UUID, notification Notification) error {
+	tenantID, ok := tenancy.TenantID(ctx)
+	if !ok {
+		return tenancy.ErrNoTenant
+	}
+	settings, err := d.notificationService.WithContext(ctx).GetSettings(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+
+	for _, name := range settings.Channels[notification.Topic] {
+		channel, ok := d.channels[name]
+		if !ok || !channel.Enabled() {
+			utils.LogWarn("Notification channel not configured", map[string]interface{}{
+				"user_id": userID,
+				"topic":   notification.Topic,
+				"channel": name,
+			})
+			continue
+		}
+		if _, err := d.queue.EnqueueContext(ctx, JobType, deliverPayload{
+			TenantID:     tenantID,
+			UserID:       userID,
+			Channel:      name,
+			Notification: notification,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliverer is the job handler that sends a notification over one channel.
+// Notifications the user has no address for on the channel are logged and
+// dropped rather than retried.
+type Deliverer struct {
+	dispatcher          *Dispatcher
+	userService         *services.UserService
+	notificationService *services.NotificationService
+}
+
+// NewDeliverer creates a deliverer sending over the dispatcher's channels
+func NewDeliverer(dispatcher *Dispatcher, svc *services.Services) *Deliverer {
+	return &Deliverer{
+		dispatcher:          dispatcher,
+		userService:         svc.Users,
+		notificationService: svc.Notifications,
+	}
+}
+
+// Handle sends the notification in the job to its user's current address on
+// the job's channel
+func (d *Deliverer) Handle(ctx context.Context, job *models.Job) error {
+	var payload deliverPayload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid notification job payload: %w", err)
+	}
+	channel, ok := d.dispatcher.channels[payload.Channel]
+	if !ok || !channel.Enabled() {
+		return jobs.Permanent(fmt.Errorf("notification channel %q is not configured", payload.Channel))
+	}
+
+	tenant, err := tenancy.GetResolver().Get(payload.TenantID)
+	if err != nil {
+		if errors.Is(err, tenancy.ErrUnknownTenant) {
+			return nil
+		}
+		return err
+	}
+	ctx = tenancy.WithTenant(ctx, tenant)
+	user, err := d.userService.WithContext(ctx).GetUserByID(payload.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+	settings, err := d.notificationService.WithContext(ctx).GetSettings(user.ID)
+	if err != nil {
+		return err
+	}
+
+	recipient := &Recipient{
+		Phone:           settings.Phone,
+		PushTokens:      settings.PushTokens,
+		SlackWebhookURL: settings.SlackWebhookURL,
+	}
+	if user.Email != nil {
+		recipient.Email = *user.Email
+	}
+	err = channel.Send(ctx, recipient, &payload.Notification)
+	if errors.Is(err, ErrNoAddress) {
+		utils.LogWarn("Notification dropped", map[string]interface{}{
+			"tenant":  tenant.Slug,
+			"user_id": user.ID,
+			"topic":   payload.Notification.Topic,
+			"channel": payload.Channel,
+			"reason":  err.Error(),
+		})
+		return nil
+	}
+	return err
+}
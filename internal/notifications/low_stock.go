@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// LowStockConsumer notifies a tenant's admins who subscribed to low stock
+// alerts when a stock change takes a book to or below the threshold
+type LowStockConsumer struct {
+	dispatcher          *Dispatcher
+	bookService         *services.BookService
+	notificationService *services.NotificationService
+	threshold           int
+}
+
+// NewLowStockConsumer creates a low stock consumer. threshold applies to
+// tenants without their own.
+func NewLowStockConsumer(dispatcher *Dispatcher, svc *services.Services, threshold int) *LowStockConsumer {
+	return &LowStockConsumer{
+		dispatcher:          dispatcher,
+		bookService:         svc.Books,
+		notificationService: svc.Notifications,
+		threshold:           threshold,
+	}
+}
+
+// Consume notifies the event tenant's admins of a stock change crossing the
+// tenant's low stock threshold
+func (c *LowStockConsumer) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	if event.EventType != events.BookStockChanged {
+		return nil
+	}
+	var payload events.StockChangedPayload
+	if err := event.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid stock changed payload: %w", err)
+	}
+
+	tenant, err := tenancy.GetResolver().Get(event.TenantID)
+	if err != nil {
+		if errors.Is(err, tenancy.ErrUnknownTenant) {
+			return nil
+		}
+		return err
+	}
+	threshold := tenant.LowStockThresholdOr(c.threshold)
+	if payload.Stock > threshold || payload.PreviousStock <= threshold {
+		return nil
+	}
+
+	ctx = tenancy.WithTenant(ctx, tenant)
+	book, err := c.bookService.WithContext(ctx).GetBookByID(payload.BookID)
+	if err != nil {
+		if err.Error() == "book not found" {
+			return nil
+		}
+		return err
+	}
+	adminIDs, err := c.notificationService.WithContext(ctx).GetAdminIDs()
+	if err != nil {
+		return err
+	}
+
+	notification := Notification{
+		Topic:   models.TopicLowStock,
+		Subject: fmt.Sprintf("Low stock: %s", book.Title),
+		Body: fmt.Sprintf("%q (ISBN %s) is down to %d in stock, at or below the low stock threshold of %d.\r\n",
+			book.Title, book.ISBN, payload.Stock, threshold),
+	}
+	for _, adminID := range adminIDs {
+		if err := c.dispatcher.Notify(ctx, adminID, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
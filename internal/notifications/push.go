@@ -0,0 +1,224 @@
+package notifications
+
+import (
+	"bookstore-api/internal/utils"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fcmScope is the OAuth scope of the FCM HTTP v1 API
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// fcmAPI is the base URL of the FCM HTTP v1 API
+const fcmAPI = "https://fcm.googleapis.com/v1/projects/"
+
+// serviceAccount holds the fields of a Google service account key file used
+// to sign token requests
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// PushChannel sends notifications to users' devices through Firebase Cloud
+// Messaging, authenticating as a service account
+type PushChannel struct {
+	client    *http.Client
+	projectID string
+	account   *serviceAccount
+	key       *rsa.PrivateKey
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewPushChannel creates a push channel for the Firebase project, signing
+// in with the service account key file at credentialsFile. The project ID
+// defaults to the key file's. Without a key file the channel is disabled.
+func NewPushChannel(client *http.Client, projectID, credentialsFile string) (*PushChannel, error) {
+	channel := &PushChannel{client: client, projectID: projectID}
+	if credentialsFile == "" {
+		return channel, nil
+	}
+
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FCM credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid FCM credentials: private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid FCM credentials: private_key is not an RSA key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	if channel.projectID == "" {
+		channel.projectID = account.ProjectID
+	}
+
+	channel.account = &account
+	channel.key = key
+	return channel, nil
+}
+
+// Name implements Channel
+func (c *PushChannel) Name() string { return "push" }
+
+// Enabled implements Channel
+func (c *PushChannel) Enabled() bool {
+	return c.key != nil && c.projectID != ""
+}
+
+// Send implements Channel. The notification goes to each of the recipient's
+// devices; devices FCM no longer knows are skipped.
+func (c *PushChannel) Send(ctx context.Context, recipient *Recipient, notification *Notification) error {
+	if len(recipient.PushTokens) == 0 {
+		return ErrNoAddress
+	}
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fcmAPI + url.PathEscape(c.projectID) + "/messages:send"
+	for _, device := range recipient.PushTokens {
+		body, err := json.Marshal(map[string]interface{}{
+			"message": map[string]interface{}{
+				"token": device,
+				"notification": map[string]string{
+					"title": notification.Subject,
+					"body":  notification.Body,
+				},
+				"data": map[string]string{
+					"topic": notification.Topic,
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to build fcm request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if err := do(c.client, req, "fcm"); err != nil {
+			// FCM rejects tokens of uninstalled apps with 404 and malformed
+			// tokens with 400; the other devices still get the notification
+			var status *statusError
+			if errors.As(err, &status) && (status.status == http.StatusNotFound || status.status == http.StatusBadRequest) {
+				utils.LogWarn("Push notification not delivered to device", map[string]interface{}{
+					"topic": notification.Topic,
+					"error": err.Error(),
+				})
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// accessToken returns an OAuth access token for FCM, requesting a new one
+// with a signed service account assertion shortly before the last expires
+func (c *PushChannel) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	assertion, err := c.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	c.token = result.AccessToken
+	c.expires = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
+
+// assertion returns the RS256-signed JWT the service account presents for
+// an access token
+func (c *PushChannel) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   c.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   c.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
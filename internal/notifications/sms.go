@@ -0,0 +1,62 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// twilioAPI is the base URL of the Twilio REST API
+const twilioAPI = "https://api.twilio.com/2010-04-01/Accounts/"
+
+// maxSMSLength is the number of characters Twilio accepts in a message body
+const maxSMSLength = 1600
+
+// SMSChannel sends notifications as text messages through Twilio
+type SMSChannel struct {
+	client     *http.Client
+	accountSID string
+	authToken  string
+	from       string
+}
+
+// NewSMSChannel creates an SMS channel sending from the given number with
+// the Twilio account's credentials
+func NewSMSChannel(client *http.Client, accountSID, authToken, from string) *SMSChannel {
+	return &SMSChannel{client: client, accountSID: accountSID, authToken: authToken, from: from}
+}
+
+// Name implements Channel
+func (c *SMSChannel) Name() string { return "sms" }
+
+// Enabled implements Channel
+func (c *SMSChannel) Enabled() bool {
+	return c.accountSID != "" && c.authToken != "" && c.from != ""
+}
+
+// Send implements Channel
+func (c *SMSChannel) Send(ctx context.Context, recipient *Recipient, notification *Notification) error {
+	if recipient.Phone == "" {
+		return ErrNoAddress
+	}
+	text := []rune(notification.Subject + "\n\n" + strings.ReplaceAll(notification.Body, "\r\n", "\n"))
+	if len(text) > maxSMSLength {
+		text = append(text[:maxSMSLength-1], '…')
+	}
+	form := url.Values{
+		"To":   {recipient.Phone},
+		"From": {c.from},
+		"Body": {string(text)},
+	}
+
+	endpoint := twilioAPI + url.PathEscape(c.accountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build twilio request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	return do(c.client, req, "twilio")
+}
@@ -0,0 +1,367 @@
+package onix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ONIX code list values used by the importer
+const (
+	ProductIDTypeGTIN13    = "03"
+	ProductIDTypeISBN13    = "15"
+	NotificationDelete     = "05"
+	TitleTypeDistinctive   = "01"
+	ContributorRoleAuthor  = "A01"
+	TextTypeDescription    = "03"
+	TextTypeShortDesc      = "02"
+	PublishingRolePrimary  = "01"
+	PublishingDateRolePub  = "01"
+	PriceTypeRRPExclTax    = "01"
+	PriceTypeRRPInclTax    = "02"
+	TitleElementLevelTitle = "01"
+)
+
+// Product is an ONIX product record
+type Product struct {
+	RecordReference    string              `xml:"RecordReference"`
+	NotificationType   string              `xml:"NotificationType"`
+	ProductIdentifiers []ProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  DescriptiveDetail   `xml:"DescriptiveDetail"`
+	CollateralDetail   CollateralDetail    `xml:"CollateralDetail"`
+	PublishingDetail   PublishingDetail    `xml:"PublishingDetail"`
+	ProductSupply      []ProductSupply     `xml:"ProductSupply"`
+}
+
+// ProductIdentifier is a typed product identifier such as an ISBN
+type ProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+// DescriptiveDetail holds titles, contributors, and subjects
+type DescriptiveDetail struct {
+	TitleDetails []TitleDetail `xml:"TitleDetail"`
+	Contributors []Contributor `xml:"Contributor"`
+	Subjects     []Subject     `xml:"Subject"`
+}
+
+// TitleDetail is a typed title of the product
+type TitleDetail struct {
+	TitleType     string         `xml:"TitleType"`
+	TitleElements []TitleElement `xml:"TitleElement"`
+}
+
+// TitleElement is one level of a title
+type TitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText"`
+	TitlePrefix        string `xml:"TitlePrefix"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix"`
+	Subtitle           string `xml:"Subtitle"`
+}
+
+// Contributor is a person or corporate body credited on the product
+type Contributor struct {
+	SequenceNumber   int      `xml:"SequenceNumber"`
+	ContributorRoles []string `xml:"ContributorRole"`
+	PersonName       string   `xml:"PersonName"`
+	NamesBeforeKey   string   `xml:"NamesBeforeKey"`
+	KeyNames         string   `xml:"KeyNames"`
+	CorporateName    string   `xml:"CorporateName"`
+	BiographicalNote string   `xml:"BiographicalNote"`
+}
+
+// Subject is a subject classification of the product
+type Subject struct {
+	MainSubject             *struct{} `xml:"MainSubject"`
+	SubjectSchemeIdentifier string    `xml:"SubjectSchemeIdentifier"`
+	SubjectCode             string    `xml:"SubjectCode"`
+	SubjectHeadingText      string    `xml:"SubjectHeadingText"`
+}
+
+// CollateralDetail holds descriptive texts
+type CollateralDetail struct {
+	TextContents []TextContent `xml:"TextContent"`
+}
+
+// TextContent is a typed descriptive text
+type TextContent struct {
+	TextType string `xml:"TextType"`
+	Text     string `xml:"Text"`
+}
+
+// PublishingDetail holds publishers and publishing dates
+type PublishingDetail struct {
+	Publishers      []Publisher      `xml:"Publisher"`
+	PublishingDates []PublishingDate `xml:"PublishingDate"`
+}
+
+// Publisher is a publisher of the product
+type Publisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+// PublishingDate is a typed date such as the publication date
+type PublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               Date   `xml:"Date"`
+}
+
+// Date is an ONIX date with an optional dateformat attribute
+type Date struct {
+	Format string `xml:"dateformat,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// ProductSupply holds supply details for a market
+type ProductSupply struct {
+	SupplyDetails []SupplyDetail `xml:"SupplyDetail"`
+}
+
+// SupplyDetail holds prices from a supplier
+type SupplyDetail struct {
+	Prices []Price `xml:"Price"`
+}
+
+// Price is a typed price in a currency
+type Price struct {
+	PriceType    string `xml:"PriceType"`
+	PriceAmount  string `xml:"PriceAmount"`
+	CurrencyCode string `xml:"CurrencyCode"`
+}
+
+// Author is a contributor credited as author
+type Author struct {
+	Name      string
+	Biography string
+}
+
+// Parse reads an ONIX message and calls fn for each product in document order.
+// Products are decoded one at a time, so large feeds are not held in memory.
+func Parse(r io.Reader, fn func(product *Product) error) error {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+
+	sawMessage := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse onix: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "ONIXMessage":
+			sawMessage = true
+		case "ONIXmessage":
+			return fmt.Errorf("short tag ONIX messages are not supported, use reference tags")
+		case "Product":
+			var product Product
+			if err := decoder.DecodeElement(&product, &start); err != nil {
+				return fmt.Errorf("failed to parse onix product: %w", err)
+			}
+			if err := fn(&product); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !sawMessage {
+		return fmt.Errorf("document is not an ONIX message")
+	}
+	return nil
+}
+
+// ISBN returns the product's ISBN-13, falling back to a 978/979 GTIN-13
+func (p *Product) ISBN() string {
+	for _, id := range p.ProductIdentifiers {
+		if id.ProductIDType == ProductIDTypeISBN13 {
+			return strings.TrimSpace(id.IDValue)
+		}
+	}
+	for _, id := range p.ProductIdentifiers {
+		value := strings.TrimSpace(id.IDValue)
+		if id.ProductIDType == ProductIDTypeGTIN13 && (strings.HasPrefix(value, "978") || strings.HasPrefix(value, "979")) {
+			return value
+		}
+	}
+	return ""
+}
+
+// IsDelete reports whether the record notifies deletion of the product
+func (p *Product) IsDelete() bool {
+	return p.NotificationType == NotificationDelete
+}
+
+// Title returns the distinctive title of the product, including any subtitle
+func (p *Product) Title() string {
+	for _, detail := range p.DescriptiveDetail.TitleDetails {
+		if detail.TitleType != TitleTypeDistinctive {
+			continue
+		}
+		for _, element := range detail.TitleElements {
+			if element.TitleElementLevel != TitleElementLevelTitle && element.TitleElementLevel != "" {
+				continue
+			}
+			title := strings.TrimSpace(element.TitleText)
+			if title == "" {
+				title = strings.TrimSpace(strings.TrimSpace(element.TitlePrefix) + " " + strings.TrimSpace(element.TitleWithoutPrefix))
+			}
+			if subtitle := strings.TrimSpace(element.Subtitle); subtitle != "" && title != "" {
+				title += ": " + subtitle
+			}
+			if title != "" {
+				return title
+			}
+		}
+	}
+	return ""
+}
+
+// Authors returns the contributors credited as authors, in the order listed
+func (p *Product) Authors() []Author {
+	var authors []Author
+	for _, contributor := range p.DescriptiveDetail.Contributors {
+		isAuthor := false
+		for _, role := range contributor.ContributorRoles {
+			if role == ContributorRoleAuthor {
+				isAuthor = true
+				break
+			}
+		}
+		if !isAuthor {
+			continue
+		}
+
+		name := strings.TrimSpace(contributor.PersonName)
+		if name == "" {
+			name = strings.TrimSpace(strings.TrimSpace(contributor.NamesBeforeKey) + " " + strings.TrimSpace(contributor.KeyNames))
+		}
+		if name == "" {
+			name = strings.TrimSpace(contributor.CorporateName)
+		}
+		if name == "" {
+			continue
+		}
+		authors = append(authors, Author{
+			Name:      name,
+			Biography: strings.TrimSpace(contributor.BiographicalNote),
+		})
+	}
+	return authors
+}
+
+// Subject returns the heading of the main subject, or of the first subject with a heading
+func (p *Product) Subject() string {
+	var first string
+	for _, subject := range p.DescriptiveDetail.Subjects {
+		heading := strings.TrimSpace(subject.SubjectHeadingText)
+		if heading == "" {
+			continue
+		}
+		if subject.MainSubject != nil {
+			return heading
+		}
+		if first == "" {
+			first = heading
+		}
+	}
+	return first
+}
+
+// Description returns the main description, falling back to the short description
+func (p *Product) Description() string {
+	var short string
+	for _, text := range p.CollateralDetail.TextContents {
+		switch text.TextType {
+		case TextTypeDescription:
+			return strings.TrimSpace(text.Text)
+		case TextTypeShortDesc:
+			short = strings.TrimSpace(text.Text)
+		}
+	}
+	return short
+}
+
+// PublisherName returns the name of the main publisher
+func (p *Product) PublisherName() string {
+	for _, publisher := range p.PublishingDetail.Publishers {
+		if publisher.PublishingRole == PublishingRolePrimary || publisher.PublishingRole == "" {
+			return strings.TrimSpace(publisher.PublisherName)
+		}
+	}
+	return ""
+}
+
+// PublicationDate returns the publication date, if present and parseable
+func (p *Product) PublicationDate() *time.Time {
+	for _, date := range p.PublishingDetail.PublishingDates {
+		if date.PublishingDateRole != PublishingDateRolePub {
+			continue
+		}
+		if t, ok := date.Date.Time(); ok {
+			return &t
+		}
+	}
+	return nil
+}
+
+// Time parses the date in the YYYYMMDD, YYYYMM, or YYYY formats
+func (d Date) Time() (time.Time, bool) {
+	value := strings.TrimSpace(d.Value)
+	for _, layout := range []string{"20060102", "200601", "2006"} {
+		if len(value) != len(layout) {
+			continue
+		}
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Price returns the recommended retail price in the currency, falling back to
+// the first recommended price in any currency
+func (p *Product) Price(currency string) (float64, bool) {
+	var fallback *Price
+	for _, supply := range p.ProductSupply {
+		for _, detail := range supply.SupplyDetails {
+			for i := range detail.Prices {
+				price := &detail.Prices[i]
+				if price.PriceType != PriceTypeRRPExclTax && price.PriceType != PriceTypeRRPInclTax && price.PriceType != "" {
+					continue
+				}
+				if strings.EqualFold(price.CurrencyCode, currency) {
+					return parsePrice(price.PriceAmount)
+				}
+				if fallback == nil {
+					fallback = price
+				}
+			}
+		}
+	}
+	if fallback == nil {
+		return 0, false
+	}
+	return parsePrice(fallback.PriceAmount)
+}
+
+// parsePrice parses a price amount
+func parsePrice(amount string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,178 @@
+package operations
+
+import (
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// progressInterval is how many rows an export writes between progress
+// reports
+const progressInterval = 500
+
+// Exporter is the job handler that writes a book export to storage for
+// download through its operation
+type Exporter struct {
+	manager *Manager
+	books   *services.BookService
+}
+
+// NewExporter creates a new book exporter
+func NewExporter(manager *Manager, svc *services.Services) *Exporter {
+	return &Exporter{manager: manager, books: svc.Books}
+}
+
+// Handle exports the tenant's books matching the job's filter as CSV,
+// reporting progress every progressInterval rows
+func (e *Exporter) Handle(ctx context.Context, job *models.Job) error {
+	var payload ExportPayload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid export job payload: %w", err)
+	}
+	ctx, err := tenantContext(ctx, payload.TenantID)
+	if err != nil {
+		return err
+	}
+
+	filter := services.BookFilter{Query: payload.Query, AuthorID: payload.AuthorID, CategoryID: payload.CategoryID}
+	bookService := e.books.WithContext(ctx)
+	total, err := bookService.CountExportBooks(filter)
+	if err != nil {
+		return err
+	}
+	progress := models.JobProgress{Step: "exporting", Total: int(total)}
+	if err := e.manager.queue.ReportProgress(job, progress); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(payload.Columns); err != nil {
+		return err
+	}
+	err = bookService.ExportBooks(filter, func(row *services.BookExportRow) error {
+		if err := writer.Write(row.CSVValues(payload.Columns)); err != nil {
+			return err
+		}
+		progress.Done++
+		if progress.Done%progressInterval == 0 {
+			return e.manager.queue.ReportProgress(job, progress)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	if err := e.manager.store.Put(ctx, exportKey(job.ID), "text/csv; charset=utf-8", buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to store export: %w", err)
+	}
+	progress.Step = "done"
+	if err := e.manager.queue.ReportProgress(job, progress); err != nil {
+		return err
+	}
+	summary, err := json.Marshal(map[string]interface{}{
+		"rows":     progress.Done,
+		"filename": exportFilename(job),
+	})
+	if err != nil {
+		return err
+	}
+	return e.manager.queue.SetResult(job, models.JobResult{
+		Links:   map[string]string{"download": Path(job.ID) + "/download"},
+		Summary: summary,
+	})
+}
+
+// Importer is the job handler that imports an uploaded CSV file of books
+type Importer struct {
+	manager *Manager
+	books   *services.BookService
+}
+
+// NewImporter creates a new book importer
+func NewImporter(manager *Manager, svc *services.Services) *Importer {
+	return &Importer{manager: manager, books: svc.Books}
+}
+
+// Handle imports the uploaded file and records the per-row report as the
+// operation's result. The upload is deleted once the import has run or
+// will not be retried.
+func (i *Importer) Handle(ctx context.Context, job *models.Job) error {
+	var payload ImportPayload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid import job payload: %w", err)
+	}
+
+	err := i.run(ctx, job, payload)
+	if err == nil || job.Attempts >= job.MaxAttempts || jobs.IsPermanent(err) {
+		i.manager.store.Delete(ctx, payload.UploadKey)
+	}
+	return err
+}
+
+// run imports the uploaded file of payload
+func (i *Importer) run(ctx context.Context, job *models.Job, payload ImportPayload) error {
+	ctx, err := tenantContext(ctx, payload.TenantID)
+	if err != nil {
+		return err
+	}
+
+	file, err := i.manager.store.Open(ctx, payload.UploadKey)
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("failed to open upload: %w", err))
+	}
+	defer file.Close()
+
+	progress := models.JobProgress{Step: "validating"}
+	if err := i.manager.queue.ReportProgress(job, progress); err != nil {
+		return err
+	}
+	report, err := i.books.WithContext(ctx).ImportBooks(file, services.ImportOptions{
+		DryRun:    payload.DryRun,
+		BatchSize: payload.BatchSize,
+		Progress: func(done, total int) {
+			progress = models.JobProgress{Step: "inserting", Done: done, Total: total}
+			i.manager.queue.ReportProgress(job, progress)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	progress.Step = "done"
+	if err := i.manager.queue.ReportProgress(job, progress); err != nil {
+		return err
+	}
+	summary, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return i.manager.queue.SetResult(job, models.JobResult{Summary: summary})
+}
+
+// tenantContext returns ctx scoped to the tenant an operation was started
+// for. Operations of a tenant that no longer exists fail for good.
+func tenantContext(ctx context.Context, tenantID uuid.UUID) (context.Context, error) {
+	tenant, err := tenancy.GetResolver().Get(tenantID)
+	if err != nil {
+		if errors.Is(err, tenancy.ErrUnknownTenant) {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	return tenancy.WithTenant(ctx, tenant), nil
+}
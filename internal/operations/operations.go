@@ -0,0 +1,261 @@
+// Package operations runs book imports and exports too large for a request
+// as background jobs, and presents those jobs as long-running operations
+// (AIP-151) that clients poll for their progress and results.
+package operations
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/storage"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job types of operations
+const (
+	ExportJobType = "operations.books_export"
+	ImportJobType = "operations.books_import"
+)
+
+// jobTypePrefix is trimmed from job types to give operation types
+const jobTypePrefix = "operations."
+
+// pathPrefix is the path of the operations REST resource
+const pathPrefix = "/api/v1/operations/"
+
+var (
+	manager *Manager
+	once    sync.Once
+)
+
+// ExportPayload is the payload of book export jobs
+type ExportPayload struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	Columns    []string  `json:"columns"`
+	Query      string    `json:"q,omitempty"`
+	AuthorID   uuid.UUID `json:"author_id,omitempty"`
+	CategoryID uuid.UUID `json:"category_id,omitempty"`
+}
+
+// ImportPayload is the payload of book import jobs. The CSV file waits in
+// storage under UploadKey until the import has run.
+type ImportPayload struct {
+	TenantID  uuid.UUID `json:"tenant_id"`
+	UploadKey string    `json:"upload_key"`
+	DryRun    bool      `json:"dry_run"`
+	BatchSize int       `json:"batch_size"`
+}
+
+// Operation is a long-running import or export, named operations/<id>
+type Operation struct {
+	Name   string    `json:"name"`
+	ID     uuid.UUID `json:"id"`
+	Type   string    `json:"type"`
+	Status string    `json:"status"`
+	// Done is true once the operation completed or failed for good
+	Done     bool     `json:"done"`
+	Progress Progress `json:"progress"`
+	// Error is why a failed operation failed
+	Error string `json:"error,omitempty"`
+	// Links holds the operation's own path and, once it completed, the paths
+	// of what it produced, such as the download of an export
+	Links map[string]string `json:"links"`
+	// Result summarizes the outcome of a completed operation
+	Result    json.RawMessage `json:"result,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Progress is how far an operation has got
+type Progress struct {
+	Percent int    `json:"percent"`
+	Step    string `json:"step,omitempty"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+}
+
+// Manager starts operations and looks them up for the tenant that started
+// them
+type Manager struct {
+	queue *jobs.Queue
+	store storage.Storage
+}
+
+// NewManager creates a manager running operations on queue and keeping their
+// files in store
+func NewManager(queue *jobs.Queue, store storage.Storage) *Manager {
+	return &Manager{queue: queue, store: store}
+}
+
+// InitializeManager initializes the shared operation manager
+func InitializeManager(cfg *config.Config) {
+	once.Do(func() {
+		manager = NewManager(jobs.GetQueue(), storage.NewLocal(cfg.Operations.Dir, ""))
+	})
+}
+
+// GetManager returns the shared operation manager
+func GetManager() *Manager {
+	if manager == nil {
+		log.Fatal("Operation manager not initialized. Call InitializeManager first.")
+	}
+	return manager
+}
+
+// Path returns the REST path of the operation with the given ID
+func Path(id uuid.UUID) string {
+	return pathPrefix + id.String()
+}
+
+// StartExport starts exporting the context tenant's books matching filter
+// with the given columns
+func (m *Manager) StartExport(ctx context.Context, filter services.BookFilter, columns []string) (*Operation, error) {
+	tenantID, ok := tenancy.TenantID(ctx)
+	if !ok {
+		return nil, tenancy.ErrNoTenant
+	}
+
+	job, err := m.queue.EnqueueContext(ctx, ExportJobType, ExportPayload{
+		TenantID:   tenantID,
+		Columns:    columns,
+		Query:      filter.Query,
+		AuthorID:   filter.AuthorID,
+		CategoryID: filter.CategoryID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newOperation(job), nil
+}
+
+// StartImport starts importing books from a CSV file for the context tenant
+func (m *Manager) StartImport(ctx context.Context, data []byte, opts services.ImportOptions) (*Operation, error) {
+	tenantID, ok := tenancy.TenantID(ctx)
+	if !ok {
+		return nil, tenancy.ErrNoTenant
+	}
+
+	key := "uploads/" + ids.New().String() + ".csv"
+	if err := m.store.Put(ctx, key, "text/csv", data); err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	job, err := m.queue.EnqueueContext(ctx, ImportJobType, ImportPayload{
+		TenantID:  tenantID,
+		UploadKey: key,
+		DryRun:    opts.DryRun,
+		BatchSize: opts.BatchSize,
+	})
+	if err != nil {
+		m.store.Delete(ctx, key)
+		return nil, err
+	}
+	return newOperation(job), nil
+}
+
+// GetOperation returns the operation with the given ID if the context tenant
+// started it
+func (m *Manager) GetOperation(ctx context.Context, id uuid.UUID) (*Operation, error) {
+	job, err := m.getJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newOperation(job), nil
+}
+
+// OpenExport opens the file written by the completed export with the given
+// ID, returning it with its download file name
+func (m *Manager) OpenExport(ctx context.Context, id uuid.UUID) (io.ReadSeekCloser, string, error) {
+	job, err := m.getJob(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if job.Type != ExportJobType || job.Status != models.JobStatusCompleted {
+		return nil, "", fmt.Errorf("operation has no download")
+	}
+
+	file, err := m.store.Open(ctx, exportKey(job.ID))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open export: %w", err)
+	}
+	return file, exportFilename(job), nil
+}
+
+// getJob returns the job of an operation, hiding jobs that are not
+// operations or belong to another tenant
+func (m *Manager) getJob(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	job, err := m.queue.GetJob(id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			return nil, fmt.Errorf("operation not found")
+		}
+		return nil, err
+	}
+	if !strings.HasPrefix(job.Type, jobTypePrefix) {
+		return nil, fmt.Errorf("operation not found")
+	}
+
+	var payload struct {
+		TenantID uuid.UUID `json:"tenant_id"`
+	}
+	tenantID, ok := tenancy.TenantID(ctx)
+	if err := job.DecodePayload(&payload); err != nil || !ok || payload.TenantID != tenantID {
+		return nil, fmt.Errorf("operation not found")
+	}
+	return job, nil
+}
+
+// newOperation describes a job as an operation
+func newOperation(job *models.Job) *Operation {
+	op := &Operation{
+		Name:      "operations/" + job.ID.String(),
+		ID:        job.ID,
+		Type:      strings.TrimPrefix(job.Type, jobTypePrefix),
+		Status:    job.Status,
+		Done:      job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed,
+		Links:     map[string]string{"self": Path(job.ID)},
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if job.Progress != nil {
+		op.Progress = Progress{Step: job.Progress.Step, Done: job.Progress.Done, Total: job.Progress.Total}
+		if job.Progress.Total > 0 {
+			op.Progress.Percent = min(100, job.Progress.Done*100/job.Progress.Total)
+		}
+	}
+
+	switch job.Status {
+	case models.JobStatusCompleted:
+		op.Progress.Percent = 100
+		if job.Result != nil {
+			for rel, href := range job.Result.Links {
+				op.Links[rel] = href
+			}
+			op.Result = job.Result.Summary
+		}
+	case models.JobStatusFailed:
+		op.Error = job.LastError
+	}
+	return op
+}
+
+// exportKey is the storage key of the file an export writes
+func exportKey(id uuid.UUID) string {
+	return "exports/" + id.String() + ".csv"
+}
+
+// exportFilename is the download file name of an export
+func exportFilename(job *models.Job) string {
+	return fmt.Sprintf("books-%s.csv", job.CreatedAt.UTC().Format("20060102"))
+}
@@ -0,0 +1,44 @@
+package opsalerts
+
+import (
+	"bookstore-api/internal/database"
+	"fmt"
+	"time"
+)
+
+// MigrationFailed returns the alert for database migrations that failed,
+// leaving the service unable to start
+func MigrationFailed(err error) Alert {
+	return Alert{
+		Key:      "migration_failed",
+		Severity: SeverityCritical,
+		Title:    "Database migration failed",
+		Text:     fmt.Sprintf("Migrations stopped with: %v\nThe service will not start until the migration is fixed.", err),
+	}
+}
+
+// DatabaseStateChanged posts an alert when the database supervisor opens
+// the circuit to the database, failing readiness checks while it reconnects,
+// and when it closes the circuit again
+func (a *Alerter) DatabaseStateChanged(status database.SupervisorStatus) {
+	if status.State == database.StateUp {
+		text := "The database connection was restored."
+		if status.LastRecoveredAt != nil {
+			text = fmt.Sprintf("The database connection was restored at %s.", status.LastRecoveredAt.UTC().Format(time.RFC3339))
+		}
+		a.Fire(Alert{
+			Key:      "database_circuit_open",
+			Severity: SeverityResolved,
+			Title:    "Database circuit closed",
+			Text:     text,
+		})
+		return
+	}
+	a.Fire(Alert{
+		Key:      "database_circuit_open",
+		Severity: SeverityCritical,
+		Title:    "Database circuit open",
+		Text: fmt.Sprintf("The database failed %d health checks in a row; the instance reports not ready while it reconnects.\nLast error: %s",
+			status.ConsecutiveFailures, status.LastError),
+	})
+}
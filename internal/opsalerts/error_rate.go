@@ -0,0 +1,141 @@
+package opsalerts
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	monitor     *ErrorRateMonitor
+	monitorOnce sync.Once
+)
+
+// ErrorRateMonitor counts requests and server errors over fixed windows and
+// alerts when a window's error rate reaches the threshold
+type ErrorRateMonitor struct {
+	alerter     *Alerter
+	threshold   float64
+	minRequests int64
+	window      time.Duration
+
+	requests atomic.Int64
+	errors   atomic.Int64
+	// spiking is whether the last window alerted, so recovery is reported
+	spiking bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewErrorRateMonitor creates an error rate monitor alerting through alerter
+func NewErrorRateMonitor(alerter *Alerter, cfg config.OpsAlertsConfig) *ErrorRateMonitor {
+	window := cfg.ErrorRateWindow
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &ErrorRateMonitor{
+		alerter:     alerter,
+		threshold:   cfg.ErrorRateThreshold,
+		minRequests: int64(cfg.ErrorRateMinRequests),
+		window:      window,
+	}
+}
+
+// InitializeErrorRateMonitor initializes the shared error rate monitor
+func InitializeErrorRateMonitor(cfg *config.Config) {
+	monitorOnce.Do(func() {
+		monitor = NewErrorRateMonitor(GetAlerter(), cfg.OpsAlerts)
+	})
+}
+
+// GetErrorRateMonitor returns the shared error rate monitor
+func GetErrorRateMonitor() *ErrorRateMonitor {
+	if monitor == nil {
+		log.Fatal("Error rate monitor not initialized. Call InitializeErrorRateMonitor first.")
+	}
+	return monitor
+}
+
+// Record counts a finished request with the given response status
+func (m *ErrorRateMonitor) Record(status int) {
+	m.requests.Add(1)
+	if status >= 500 {
+		m.errors.Add(1)
+	}
+}
+
+// Start begins checking the error rate every window. It does nothing when
+// no webhook is configured or the threshold is not positive.
+func (m *ErrorRateMonitor) Start() {
+	if !m.alerter.Enabled() || m.threshold <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx)
+	log.Printf("Alerting on error rates of %.1f%% or more over %s", m.threshold*100, m.window)
+}
+
+// Stop stops checking and waits for the monitor to return
+func (m *ErrorRateMonitor) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+// run checks each window's error rate until ctx is cancelled
+func (m *ErrorRateMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// check alerts on the window just ended and starts the next one. Windows
+// with fewer than the minimum requests neither alert nor resolve.
+func (m *ErrorRateMonitor) check() {
+	requests := m.requests.Swap(0)
+	failed := m.errors.Swap(0)
+	if requests < m.minRequests || requests == 0 {
+		return
+	}
+
+	rate := float64(failed) / float64(requests)
+	switch {
+	case rate >= m.threshold:
+		m.spiking = true
+		m.alerter.Fire(Alert{
+			Key:      "error_rate",
+			Severity: SeverityWarning,
+			Title:    "Error rate spike",
+			Text: fmt.Sprintf("%.1f%% of requests failed with a server error over the last %s (%d of %d), above the %.1f%% threshold.",
+				rate*100, m.window, failed, requests, m.threshold*100),
+		})
+	case m.spiking:
+		m.spiking = false
+		m.alerter.Fire(Alert{
+			Key:      "error_rate",
+			Severity: SeverityResolved,
+			Title:    "Error rate back to normal",
+			Text: fmt.Sprintf("%.1f%% of requests failed with a server error over the last %s (%d of %d).",
+				rate*100, m.window, failed, requests),
+		})
+	}
+}
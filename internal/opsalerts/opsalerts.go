@@ -0,0 +1,195 @@
+// Package opsalerts posts operational alerts, such as error rate spikes,
+// failed migrations, and the database circuit opening, to the Slack and
+// Microsoft Teams incoming webhooks of the team running the service.
+package opsalerts
+
+import (
+	"bookstore-api/internal/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Alert severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityResolved = "resolved"
+)
+
+// severityColors are the Teams card colors of each severity
+var severityColors = map[string]string{
+	SeverityCritical: "D73A49",
+	SeverityWarning:  "F9C513",
+	SeverityResolved: "28A745",
+}
+
+var (
+	alerter *Alerter
+	once    sync.Once
+)
+
+// Alert is an operational event worth telling the on-call team about
+type Alert struct {
+	// Key identifies the condition, so repeats of it within the cooldown
+	// are not posted again
+	Key      string
+	Severity string
+	Title    string
+	Text     string
+}
+
+// Alerter posts alerts to the configured webhooks, at most once per alert
+// key and cooldown
+type Alerter struct {
+	slackURL string
+	teamsURL string
+	cooldown time.Duration
+	source   string
+	client   *http.Client
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// NewAlerter creates an alerter for the configured webhooks
+func NewAlerter(cfg *config.Config) *Alerter {
+	timeout := cfg.OpsAlerts.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	source := "bookstore-api"
+	if host, err := os.Hostname(); err == nil {
+		source += " on " + host
+	}
+	if cfg.Profile != "" {
+		source += " (" + cfg.Profile + ")"
+	}
+	return &Alerter{
+		slackURL: cfg.OpsAlerts.SlackWebhookURL,
+		teamsURL: cfg.OpsAlerts.TeamsWebhookURL,
+		cooldown: cfg.OpsAlerts.Cooldown,
+		source:   source,
+		client:   &http.Client{Timeout: timeout},
+		sent:     make(map[string]time.Time),
+	}
+}
+
+// Initialize initializes the shared alerter
+func Initialize(cfg *config.Config) {
+	once.Do(func() {
+		alerter = NewAlerter(cfg)
+	})
+}
+
+// GetAlerter returns the shared alerter
+func GetAlerter() *Alerter {
+	if alerter == nil {
+		log.Fatal("Ops alerter not initialized. Call Initialize first.")
+	}
+	return alerter
+}
+
+// Enabled reports whether any webhook is configured
+func (a *Alerter) Enabled() bool {
+	return a.slackURL != "" || a.teamsURL != ""
+}
+
+// Send posts the alert to every configured webhook and waits for them,
+// unless an alert with the same key was posted within the cooldown.
+// Resolved alerts always go out and reset the cooldown.
+func (a *Alerter) Send(ctx context.Context, alert Alert) error {
+	if !a.Enabled() || !a.due(alert) {
+		return nil
+	}
+
+	var errs []error
+	if a.slackURL != "" {
+		errs = append(errs, a.post(ctx, a.slackURL, a.slackMessage(alert)))
+	}
+	if a.teamsURL != "" {
+		errs = append(errs, a.post(ctx, a.teamsURL, a.teamsMessage(alert)))
+	}
+	return errors.Join(errs...)
+}
+
+// Fire posts the alert in the background, logging failures, for callers
+// that must not wait on the webhooks
+func (a *Alerter) Fire(alert Alert) {
+	if !a.Enabled() {
+		return
+	}
+	go func() {
+		if err := a.Send(context.Background(), alert); err != nil {
+			log.Printf("Failed to post ops alert %q: %v", alert.Key, err)
+		}
+	}()
+}
+
+// due reports whether the alert should be posted, recording it if so
+func (a *Alerter) due(alert Alert) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if alert.Severity == SeverityResolved {
+		delete(a.sent, alert.Key)
+		return true
+	}
+	if last, ok := a.sent[alert.Key]; ok && time.Since(last) < a.cooldown {
+		return false
+	}
+	a.sent[alert.Key] = time.Now()
+	return true
+}
+
+// slackMessage is the incoming webhook payload of an alert for Slack
+func (a *Alerter) slackMessage(alert Alert) interface{} {
+	return map[string]string{
+		"text": fmt.Sprintf("*[%s] %s*\n%s\n_%s_", alert.Severity, alert.Title, alert.Text, a.source),
+	}
+}
+
+// teamsMessage is the incoming webhook payload of an alert for Microsoft
+// Teams, as a message card
+func (a *Alerter) teamsMessage(alert Alert) interface{} {
+	return map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": severityColors[alert.Severity],
+		"summary":    alert.Title,
+		"title":      fmt.Sprintf("[%s] %s", alert.Severity, alert.Title),
+		"text":       alert.Text + "\n\n" + a.source,
+	}
+}
+
+// post sends a webhook payload
+func (a *Alerter) post(ctx context.Context, url string, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alert request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
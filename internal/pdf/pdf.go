@@ -0,0 +1,248 @@
+// Package pdf renders tables as PDF documents, for reports read by people
+// rather than imported into spreadsheets. It draws with the standard
+// Helvetica fonts, so it embeds no font files, and prints the Latin-1
+// characters those fonts encode; other characters print as "?".
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page layout, in points, of landscape A4 pages
+const (
+	pageWidth   = 842
+	pageHeight  = 595
+	margin      = 36
+	titleSize   = 14
+	fontSize    = 8
+	rowHeight   = 12
+	cellPadding = 3
+	// maxColumnWidth caps the width of a column, so one long column does
+	// not squeeze the others
+	maxColumnWidth = 220
+)
+
+// Table is a titled table whose first row is its header
+type Table struct {
+	title string
+	rows  [][]string
+}
+
+// NewTable creates an empty table titled title
+func NewTable(title string) *Table {
+	return &Table{title: title}
+}
+
+// Write appends row to the table. The first row written is the header,
+// repeated at the top of every page.
+func (t *Table) Write(row []string) error {
+	t.rows = append(t.rows, append([]string(nil), row...))
+	return nil
+}
+
+// Render writes the table to w as a PDF document of as many pages as its
+// rows need
+func (t *Table) Render(w io.Writer) error {
+	var header []string
+	var body [][]string
+	if len(t.rows) > 0 {
+		header, body = t.rows[0], t.rows[1:]
+	}
+	widths := columnWidths(t.rows)
+
+	// The title and a gap take the top of the first page, and the page
+	// number the bottom of every page
+	top := float64(pageHeight - margin - titleSize - rowHeight)
+	perPage := int((top-margin-rowHeight)/rowHeight) - 1
+	var pages []string
+	for start := 0; start == 0 || start < len(body); start += perPage {
+		end := start + perPage
+		if end > len(body) {
+			end = len(body)
+		}
+		pages = append(pages, t.page(len(pages) == 0, header, body[start:end], widths))
+	}
+	for i := range pages {
+		pages[i] += text("F1", fontSize, margin, margin-fontSize, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	return writeDocument(w, pages)
+}
+
+// page returns the content stream drawing rows under header, and the title
+// on the first page
+func (t *Table) page(first bool, header []string, rows [][]string, widths []float64) string {
+	var content strings.Builder
+	y := float64(pageHeight - margin - titleSize)
+	if first {
+		content.WriteString(text("F2", titleSize, margin, y, t.title))
+	}
+	y -= rowHeight * 2
+	if header != nil {
+		content.WriteString(row(header, widths, "F1", y))
+		fmt.Fprintf(&content, "0.5 w %d %.2f m %.2f %.2f l S\n", margin, y-cellPadding, margin+sum(widths), y-cellPadding)
+		y -= rowHeight
+	}
+	for _, cells := range rows {
+		content.WriteString(row(cells, widths, "F1", y))
+		y -= rowHeight
+	}
+	return content.String()
+}
+
+// row returns the drawing of a row's cells in font at baseline y, each cut
+// to fit its column
+func row(cells []string, widths []float64, font string, y float64) string {
+	var content strings.Builder
+	x := float64(margin)
+	for i, width := range widths {
+		if i < len(cells) {
+			content.WriteString(text(font, fontSize, x+cellPadding, y, fit(cells[i], width-2*cellPadding)))
+		}
+		x += width
+	}
+	return content.String()
+}
+
+// columnWidths sizes the columns to their widest cells, shrinking them all
+// alike when together they are wider than the page
+func columnWidths(rows [][]string) []float64 {
+	var widths []float64
+	for _, cells := range rows {
+		for i, cell := range cells {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			width := textWidth(cell) + 2*cellPadding
+			if width > maxColumnWidth {
+				width = maxColumnWidth
+			}
+			if width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+	if total, available := sum(widths), float64(pageWidth-2*margin); total > available {
+		for i := range widths {
+			widths[i] *= available / total
+		}
+	}
+	return widths
+}
+
+// fit cuts s with an ellipsis to fit width
+func fit(s string, width float64) string {
+	if textWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) == 0 {
+		return ""
+	}
+	return string(runes) + "..."
+}
+
+// textWidth returns the width of s set in Helvetica at fontSize
+func textWidth(s string) float64 {
+	units := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			units += helveticaWidths[r-' ']
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * fontSize / 1000
+}
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0 to 9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A to M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N to Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a to m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n to z
+	334, 260, 334, 584, // { to ~
+}
+
+// text returns the drawing of s in font at size with its baseline starting
+// at x, y
+func text(font string, size int, x, y float64, s string) string {
+	return fmt.Sprintf("BT /%s %d Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, encode(s))
+}
+
+// encode returns s as the body of a PDF string in WinAnsiEncoding, which
+// matches Latin-1 for the characters it prints
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r <= '~', r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		case r == '\t' || r == '\n' || r == '\r':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// sum returns the sum of values
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// writeDocument writes a PDF document of pages, given as content streams,
+// to w. Objects 1 to 4 are the catalog, the page tree, and the regular and
+// bold fonts; each page is followed by its content stream.
+func writeDocument(w io.Writer, pages []string) error {
+	var doc bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	doc.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
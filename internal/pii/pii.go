@@ -0,0 +1,224 @@
+package pii
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// prefix marks encrypted values, which are stored as pii:<key id>:<base64
+// nonce and ciphertext>. Values without it are plaintext written before
+// encryption was enabled.
+const prefix = "pii:"
+
+// ErrUnknownKey is returned when decrypting a value encrypted with a key that
+// is no longer configured
+var ErrUnknownKey = errors.New("pii: value encrypted with an unknown key")
+
+var (
+	keyring *Keyring
+	once    sync.Once
+)
+
+// Keyring encrypts personal data with AES-256-GCM and computes the blind
+// indexes used to look it up. A nil keyring leaves data unencrypted.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+	index   []byte
+}
+
+// NewKeyring creates a keyring from the configured keys. It returns nil when no
+// encryption keys are configured.
+func NewKeyring(cfg config.PIIConfig) (*Keyring, error) {
+	if strings.TrimSpace(cfg.EncryptionKeys) == "" {
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(cfg.EncryptionKeys, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid PII encryption key %q: expected id:base64", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid PII encryption key %q: expected 32 base64-encoded bytes", id)
+		}
+		if _, exists := k.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate PII encryption key %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+		if k.current == "" {
+			k.current = id
+		}
+	}
+
+	index, err := base64.StdEncoding.DecodeString(cfg.IndexKey)
+	if err != nil || len(index) < 32 {
+		return nil, fmt.Errorf("invalid PII index key: expected at least 32 base64-encoded bytes")
+	}
+	k.index = index
+	return k, nil
+}
+
+// InitializeKeyring initializes the shared keyring used by the pii serializer
+func InitializeKeyring(cfg *config.Config) error {
+	var err error
+	once.Do(func() {
+		keyring, err = NewKeyring(cfg.PII)
+		if err == nil && keyring == nil {
+			log.Println("PII_ENCRYPTION_KEYS is not set; personal data is stored unencrypted")
+		}
+	})
+	return err
+}
+
+// GetKeyring returns the shared keyring, or nil when encryption is disabled
+func GetKeyring() *Keyring {
+	return keyring
+}
+
+// CurrentKey returns the ID of the key new values are encrypted with
+func (k *Keyring) CurrentKey() string {
+	if k == nil {
+		return ""
+	}
+	return k.current
+}
+
+// Encrypt encrypts value with the current key. Empty values and values on a
+// nil keyring are returned as they are.
+func (k *Keyring) Encrypt(value string) (string, error) {
+	if k == nil || value == "" {
+		return value, nil
+	}
+	aead := k.keys[k.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("pii: failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return prefix + k.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a stored value. Plaintext values are returned as they are.
+func (k *Keyring) Decrypt(stored string) (string, error) {
+	if !strings.HasPrefix(stored, prefix) {
+		return stored, nil
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(stored, prefix), ":")
+	if !ok {
+		return "", fmt.Errorf("pii: malformed encrypted value")
+	}
+	if k == nil {
+		return "", ErrUnknownKey
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("pii: malformed encrypted value")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("pii: failed to decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or encrypted with
+// a key other than the current one
+func (k *Keyring) NeedsRotation(stored string) bool {
+	if k == nil || stored == "" {
+		return false
+	}
+	return !strings.HasPrefix(stored, prefix+k.current+":")
+}
+
+// Hash returns the blind index of value, an HMAC of its trimmed, lowercased
+// form, so equal values can be found without decrypting them. It returns nil
+// for empty values and on a nil keyring.
+func (k *Keyring) Hash(value string) *string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if k == nil || value == "" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write([]byte(value))
+	hash := hex.EncodeToString(mac.Sum(nil))
+	return &hash
+}
+
+func init() {
+	schema.RegisterSerializer("pii", Serializer{})
+}
+
+// Serializer encrypts string and *string model fields tagged
+// serializer:pii with the shared keyring when they are written and decrypts
+// them when they are read. Map updates bypass serializers, so encrypted
+// columns must be updated from a model.
+type Serializer struct{}
+
+// Scan decrypts a stored value into the field
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+		return field.Set(ctx, dst, reflect.Zero(field.FieldType).Interface())
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("pii: unsupported column value %T", dbValue)
+	}
+
+	value, err := GetKeyring().Decrypt(stored)
+	if err != nil {
+		return err
+	}
+	if field.FieldType.Kind() == reflect.Ptr {
+		return field.Set(ctx, dst, &value)
+	}
+	return field.Set(ctx, dst, value)
+}
+
+// Value encrypts the field's value for storage
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case string:
+		return GetKeyring().Encrypt(v)
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		return GetKeyring().Encrypt(*v)
+	default:
+		return nil, fmt.Errorf("pii: unsupported field type %T", fieldValue)
+	}
+}
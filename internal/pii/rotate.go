@@ -0,0 +1,115 @@
+package pii
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// rotateBatchSize is the number of rows read at a time while rotating
+const rotateBatchSize = 500
+
+// encryptedColumn is a column holding personal data, with the column holding
+// its blind index, if any, and the table's UUID key when it is not id
+type encryptedColumn struct {
+	table  string
+	column string
+	hash   string
+	key    string
+}
+
+// encryptedColumns are the columns of models tagged serializer:pii
+var encryptedColumns = []encryptedColumn{
+	{table: "users", column: "email", hash: "email_hash"},
+	{table: "user_identities", column: "email"},
+	{table: "notification_settings", column: "phone", key: "user_id"},
+	{table: "notification_settings", column: "slack_webhook_url", key: "user_id"},
+	{table: "suppliers", column: "auth_value"},
+}
+
+// Rotate re-encrypts every value not yet encrypted with the keyring's current
+// key, including plaintext stored before encryption was enabled, and
+// recomputes blind indexes that do not match the index key. It covers every
+// tenant and soft deleted rows, and returns the rows rewritten per table. Keys
+// other than the current one can be removed once it has run.
+func Rotate(db *gorm.DB, k *Keyring) (map[string]int64, error) {
+	if k == nil {
+		return nil, errors.New("PII_ENCRYPTION_KEYS is not set")
+	}
+
+	rotated := make(map[string]int64)
+	for _, col := range encryptedColumns {
+		n, err := rotateColumn(db, k, col)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rotate %s.%s: %w", col.table, col.column, err)
+		}
+		rotated[col.table] += n
+	}
+	return rotated, nil
+}
+
+func rotateColumn(db *gorm.DB, k *Keyring, col encryptedColumn) (int64, error) {
+	key := col.key
+	if key == "" {
+		key = "id"
+	}
+	selects := key + " AS id, " + col.column + " AS value"
+	if col.hash != "" {
+		selects += ", " + col.hash + " AS hash"
+	}
+
+	var rotated int64
+	last := uuid.Nil
+	for {
+		// Read the table directly so rows are neither scoped to a tenant nor
+		// decrypted by the serializer
+		var rows []struct {
+			ID    uuid.UUID
+			Value string
+			Hash  *string
+		}
+		err := db.Table(col.table).Select(selects).
+			Where(key+" > ? AND "+col.column+" IS NOT NULL AND "+col.column+" <> ''", last).
+			Order(key).Limit(rotateBatchSize).Scan(&rows).Error
+		if err != nil {
+			return rotated, fmt.Errorf("failed to read rows: %w", err)
+		}
+
+		for _, row := range rows {
+			last = row.ID
+			value, err := k.Decrypt(row.Value)
+			if err != nil {
+				return rotated, fmt.Errorf("failed to decrypt row %s: %w", row.ID, err)
+			}
+
+			updates := make(map[string]interface{})
+			if k.NeedsRotation(row.Value) {
+				encrypted, err := k.Encrypt(value)
+				if err != nil {
+					return rotated, err
+				}
+				updates[col.column] = encrypted
+			}
+			if col.hash != "" {
+				hash := k.Hash(value)
+				if (row.Hash == nil) != (hash == nil) || (hash != nil && *row.Hash != *hash) {
+					updates[col.hash] = hash
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			if err := db.Table(col.table).Where(key+" = ?", row.ID).Updates(updates).Error; err != nil {
+				return rotated, fmt.Errorf("failed to update row %s: %w", row.ID, err)
+			}
+			rotated++
+		}
+
+		if len(rows) < rotateBatchSize {
+			return rotated, nil
+		}
+	}
+}
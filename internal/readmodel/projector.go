@@ -0,0 +1,74 @@
+// Package readmodel keeps the denormalized read models, such as the
+// book_listings table served by storefront listings, up to date from domain
+// events.
+package readmodel
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// EventTypes are the domain events that change book listings
+var EventTypes = []string{
+	events.BookCreated,
+	events.BookUpdated,
+	events.BookDeleted,
+	events.BookStockChanged,
+	events.BookPriceChanged,
+	events.AuthorUpdated,
+	events.AuthorMerged,
+	events.CategoryUpdated,
+	events.PromotionStarted,
+	events.PromotionEnded,
+}
+
+// Projector refreshes the book listings affected by each domain event it
+// consumes. Refreshes rewrite listings from the current state of the catalog
+// rather than applying the event, so redelivered and out-of-order events
+// leave them correct.
+type Projector struct {
+	listings *services.ListingService
+}
+
+// NewProjector creates a new read model projector refreshing listings
+func NewProjector(listings *services.ListingService) *Projector {
+	return &Projector{
+		listings: listings,
+	}
+}
+
+// Consume refreshes the listings of the books the event concerns
+func (p *Projector) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	listings := p.listings.WithContext(ctx)
+
+	switch event.EventType {
+	case events.BookCreated, events.BookUpdated, events.BookDeleted, events.BookStockChanged, events.BookPriceChanged:
+		return listings.RefreshBooks([]uuid.UUID{event.AggregateID})
+
+	case events.AuthorUpdated, events.AuthorMerged:
+		// Merged books are moved to the aggregate author
+		return listings.RefreshAuthor(event.AggregateID)
+
+	case events.CategoryUpdated:
+		return listings.RefreshCategory(event.AggregateID)
+
+	case events.PromotionStarted, events.PromotionEnded:
+		var promotion models.Promotion
+		if err := json.Unmarshal([]byte(event.Payload), &promotion); err != nil {
+			return fmt.Errorf("failed to decode %s event: %w", event.EventType, err)
+		}
+		if promotion.BookID != nil {
+			return listings.RefreshBooks([]uuid.UUID{*promotion.BookID})
+		}
+		if promotion.CategoryID != nil {
+			return listings.RefreshCategory(*promotion.CategoryID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JSONAPIMediaType is the media type of JSON:API documents
+const JSONAPIMediaType = "application/vnd.api+json"
+
+// Response formats a request can be answered in
+const (
+	FormatEnvelope = "envelope"
+	FormatJSONAPI  = "jsonapi"
+)
+
+// Document is a JSON:API top-level document holding resources
+type Document struct {
+	Data     interface{}            `json:"data"`
+	Included []Resource             `json:"included,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+	Links    interface{}            `json:"links,omitempty"`
+}
+
+// ErrorDocument is a JSON:API top-level document holding errors
+type ErrorDocument struct {
+	Errors []Error `json:"errors"`
+}
+
+// Error is a JSON:API error object
+type Error struct {
+	Status string       `json:"status"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// ErrorSource points at the request member an error is about
+type ErrorSource struct {
+	Pointer string `json:"pointer"`
+}
+
+// Identifier identifies a resource
+type Identifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Resource is a JSON:API resource object
+type Resource struct {
+	Identifier
+	Attributes    map[string]interface{}  `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         interface{}             `json:"links,omitempty"`
+}
+
+// Relationship is a JSON:API relationship object. Data is an Identifier for
+// to-one relationships and is left nil for to-many relationships, which are
+// only linked.
+type Relationship struct {
+	Data  *Identifier `json:"data,omitempty"`
+	Links interface{} `json:"links,omitempty"`
+}
+
+// Attributes returns the JSON fields of v as resource attributes, leaving out
+// id and the fields named in omit
+func Attributes(v interface{}, omit ...string) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return nil, err
+	}
+	delete(attributes, "id")
+	for _, field := range omit {
+		delete(attributes, field)
+	}
+	return attributes, nil
+}
+
+// Serializer builds a JSON:API document for one request, applying the sparse
+// fieldsets (fields[type]=a,b) and included relationships (include=a,b) it
+// asked for
+type Serializer struct {
+	fields   map[string]map[string]bool
+	include  map[string]bool
+	included []Resource
+	seen     map[Identifier]bool
+}
+
+// NewSerializer creates a serializer from a request's query parameters
+func NewSerializer(query map[string]string) *Serializer {
+	s := &Serializer{
+		fields:  make(map[string]map[string]bool),
+		include: make(map[string]bool),
+		seen:    make(map[Identifier]bool),
+	}
+	for key, value := range query {
+		if !strings.HasPrefix(key, "fields[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		fields := make(map[string]bool)
+		for _, field := range splitList(value) {
+			fields[field] = true
+		}
+		s.fields[key[len("fields["):len(key)-1]] = fields
+	}
+	for _, path := range splitList(query["include"]) {
+		s.include[path] = true
+	}
+	return s
+}
+
+// CheckIncludes returns an error naming the requested includes that are not
+// among allowed
+func (s *Serializer) CheckIncludes(allowed ...string) error {
+	var unsupported []string
+	for path := range s.include {
+		supported := false
+		for _, a := range allowed {
+			supported = supported || path == a
+		}
+		if !supported {
+			unsupported = append(unsupported, path)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("unsupported include: %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// Includes reports whether the request asked to include the relationship path
+func (s *Serializer) Includes(path string) bool {
+	return s.include[path]
+}
+
+// Resource returns r restricted to the fieldset requested for its type
+func (s *Serializer) Resource(r Resource) Resource {
+	fields, ok := s.fields[r.Type]
+	if !ok {
+		return r
+	}
+
+	attributes := make(map[string]interface{}, len(fields))
+	for name, value := range r.Attributes {
+		if fields[name] {
+			attributes[name] = value
+		}
+	}
+	relationships := make(map[string]Relationship, len(fields))
+	for name, relationship := range r.Relationships {
+		if fields[name] {
+			relationships[name] = relationship
+		}
+	}
+	r.Attributes, r.Relationships = attributes, relationships
+	return r
+}
+
+// Include adds r to the document's included resources unless it is already
+// there
+func (s *Serializer) Include(r Resource) {
+	if s.seen[r.Identifier] {
+		return
+	}
+	s.seen[r.Identifier] = true
+	s.included = append(s.included, s.Resource(r))
+}
+
+// Document returns a document with data and the included resources
+func (s *Serializer) Document(data interface{}) *Document {
+	return &Document{Data: data, Included: s.included}
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,17 @@
+package render
+
+import (
+	"io"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// encodeMsgpack writes v as MessagePack. Integers are written as integers
+// and other numbers as floats.
+func encodeMsgpack(w io.Writer, v interface{}) error {
+	writer := msgp.NewWriter(w)
+	if err := writer.WriteIntf(v); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
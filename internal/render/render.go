@@ -0,0 +1,77 @@
+// Package render encodes API responses in media types other than JSON.
+//
+// Handlers build their responses as JSON. Encoders registered here receive
+// the decoded JSON value, so every alternative representation uses the same
+// field names, omissions, and formatting as the JSON one.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Encoder writes a decoded JSON value in a media type. Values are nil,
+// bool, json.Number, string, []interface{}, or map[string]interface{}.
+type Encoder interface {
+	Encode(w io.Writer, v interface{}) error
+}
+
+// EncoderFunc adapts a function to Encoder
+type EncoderFunc func(w io.Writer, v interface{}) error
+
+// Encode calls f
+func (f EncoderFunc) Encode(w io.Writer, v interface{}) error {
+	return f(w, v)
+}
+
+var (
+	mu         sync.RWMutex
+	encoders   = make(map[string]Encoder)
+	mediaTypes []string
+)
+
+// Register makes an encoder available for a media type, replacing any
+// encoder already registered for it
+func Register(mediaType string, encoder Encoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := encoders[mediaType]; !exists {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	encoders[mediaType] = encoder
+}
+
+// Lookup returns the encoder registered for a media type
+func Lookup(mediaType string) (Encoder, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	encoder, ok := encoders[mediaType]
+	return encoder, ok
+}
+
+// MediaTypes returns the registered media types in registration order
+func MediaTypes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string(nil), mediaTypes...)
+}
+
+// Transcode decodes a JSON document and writes it with encoder
+func Transcode(w io.Writer, encoder Encoder, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return encoder.Encode(w, value)
+}
+
+func init() {
+	Register("application/xml", EncoderFunc(encodeXML))
+	Register("application/msgpack", EncoderFunc(encodeMsgpack))
+	Register("application/x-msgpack", EncoderFunc(encodeMsgpack))
+}
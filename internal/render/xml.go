@@ -0,0 +1,99 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"unicode"
+)
+
+// xmlRoot is the element enclosing an XML response
+const xmlRoot = "response"
+
+// encodeXML writes v as an XML document. Object fields become elements
+// named after their keys, in key order; keys that are not valid element
+// names become entry elements with a key attribute. Array items become item
+// elements, and nulls become empty elements with nil="true".
+func encodeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if err := encodeXMLElement(encoder, xml.StartElement{Name: xml.Name{Local: xmlRoot}}, v); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// encodeXMLElement writes v as the element start
+func encodeXMLElement(encoder *xml.Encoder, start xml.StartElement, v interface{}) error {
+	if v == nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := encodeXMLElement(encoder, xmlField(key), v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXMLElement(encoder, xml.StartElement{Name: xml.Name{Local: "item"}}, item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := encoder.EncodeToken(xml.CharData(v)); err != nil {
+			return err
+		}
+	case json.Number, bool:
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("render: unsupported value %T", v)
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlField returns the element for an object field
+func xmlField(key string) xml.StartElement {
+	if validXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+// validXMLName reports whether name can be used as an element name as is.
+// Names starting with "xml" are reserved.
+func validXMLName(name string) bool {
+	if name == "" || (len(name) >= 3 && (name[0]|0x20) == 'x' && (name[1]|0x20) == 'm' && (name[2]|0x20) == 'l') {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
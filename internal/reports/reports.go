@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/mail"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/tracing"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobType is the background job type that generates and emails a report
+const JobType = "report.generate"
+
+// Payload is the payload of report generation jobs. Recipients, when set,
+// replace the definition's recipients for an ad-hoc run.
+type Payload struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	Recipients []string  `json:"recipients,omitempty"`
+	RunAt      time.Time `json:"run_at"`
+}
+
+// Generator is the job handler that builds a report and emails it as a CSV or PDF attachment.
+// Failures are recorded on the definition and returned so the job queue retries them.
+type Generator struct {
+	reportService *services.ReportService
+	mailer        *mail.Mailer
+	lowStock      int
+}
+
+// NewGenerator creates a new report generator
+func NewGenerator(cfg *config.Config, svc *services.Services) *Generator {
+	return &Generator{
+		reportService: svc.Reports,
+		mailer:        mail.NewMailer(cfg.Mail),
+		lowStock:      cfg.Scheduler.LowStockThreshold,
+	}
+}
+
+// Handle generates and sends the report referenced by the job. Jobs are not
+// tenant scoped, so the report is looked up by ID and then built from its
+// tenant's data only.
+func (g *Generator) Handle(ctx context.Context, job *models.Job) error {
+	var payload Payload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid report job payload: %w", err)
+	}
+
+	report, err := g.reportService.WithContext(tenancy.WithSystem(ctx)).GetReportByID(payload.ReportID)
+	if err != nil {
+		if err.Error() == "report not found" {
+			return nil
+		}
+		return err
+	}
+	tenant, err := tenancy.GetResolver().Get(report.TenantID)
+	if err != nil {
+		if errors.Is(err, tenancy.ErrUnknownTenant) {
+			return nil
+		}
+		return err
+	}
+	reportService := g.reportService.WithContext(tenancy.WithTenant(ctx, tenant))
+
+	runAt := payload.RunAt
+	if runAt.IsZero() {
+		runAt = job.CreatedAt
+	}
+	err = g.run(ctx, reportService, report, payload.Recipients, runAt, tenant.LowStockThresholdOr(g.lowStock))
+	if recordErr := reportService.RecordRun(report.ID, time.Now(), err); recordErr != nil && err == nil {
+		return recordErr
+	}
+	return err
+}
+
+// run builds the report for the period ending at runAt and emails it
+func (g *Generator) run(ctx context.Context, reportService *services.ReportService, report *models.ReportDefinition, recipients []string, runAt time.Time, lowStock int) error {
+	if len(recipients) == 0 {
+		recipients = report.Recipients
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("report has no recipients")
+	}
+
+	from, to := services.ReportRange(report.Period, runAt)
+	var buf bytes.Buffer
+	if err := reportService.WriteReport(report, from, to, lowStock, &buf); err != nil {
+		return err
+	}
+
+	return g.mailer.Send(&mail.Message{
+		To:      recipients,
+		Subject: services.ReportTitle(report, from, to),
+		Body: fmt.Sprintf("Attached is the %s report %q covering %s to %s.\r\n",
+			strings.ReplaceAll(report.ReportType, "_", " "), report.Name,
+			from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02")),
+		Attachments: []mail.Attachment{{
+			Filename:    Filename(report, from, to),
+			ContentType: ContentType(report),
+			Data:        buf.Bytes(),
+		}},
+		TraceID: tracing.TraceID(ctx),
+	})
+}
+
+// Filename returns the attachment or download file name of a report run
+func Filename(report *models.ReportDefinition, from, to time.Time) string {
+	return fmt.Sprintf("%s-%s-%s.%s", report.ReportType, from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"), report.Format)
+}
+
+// ContentType returns the content type of a report's file
+func ContentType(report *models.ReportDefinition) string {
+	if report.Format == models.ReportFormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
@@ -0,0 +1,87 @@
+// Package response writes the JSON envelope every REST response shares:
+// error and message, with data and pagination on success and details on
+// failure.
+package response
+
+import "github.com/gofiber/fiber/v2"
+
+// Envelope is the body of a JSON response
+type Envelope struct {
+	Error bool `json:"error"`
+	// Code identifies an error to clients, and Field names the request
+	// field the error concerns
+	Code    string      `json:"code,omitempty"`
+	Field   string      `json:"field,omitempty"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+	// Fields lists the failed fields of a validation error
+	Fields     interface{} `json:"fields,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	// Summary holds totals over the whole listing, beside its page
+	Summary interface{} `json:"summary,omitempty"`
+	// Links holds the HAL links of listings answered with links
+	Links interface{} `json:"_links,omitempty"`
+}
+
+// Pagination describes the page of a listing
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// NewPagination describes page of a listing of total items, limit per page
+func NewPagination(page, limit int, total int64) *Pagination {
+	return &Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	}
+}
+
+// OK responds 200 with data, which is left out when nil
+func OK(c *fiber.Ctx, message string, data interface{}) error {
+	return c.JSON(Envelope{Message: message, Data: data})
+}
+
+// Created responds 201 with the created resource
+func Created(c *fiber.Ctx, message string, data interface{}) error {
+	return c.Status(fiber.StatusCreated).JSON(Envelope{Message: message, Data: data})
+}
+
+// Accepted responds 202 with data, which is left out when nil, for work that
+// continues after the response
+func Accepted(c *fiber.Ctx, message string, data interface{}) error {
+	return c.Status(fiber.StatusAccepted).JSON(Envelope{Message: message, Data: data})
+}
+
+// Paginated responds 200 with a page of a listing
+func Paginated(c *fiber.Ctx, message string, data interface{}, page, limit int, total int64) error {
+	return c.JSON(Envelope{Message: message, Data: data, Pagination: NewPagination(page, limit, total)})
+}
+
+// Error responds with status and an error message, detailed by err when it
+// is not nil
+func Error(c *fiber.Ctx, status int, message string, err error) error {
+	envelope := Envelope{Error: true, Message: message}
+	if err != nil {
+		envelope.Details = err.Error()
+	}
+	return c.Status(status).JSON(envelope)
+}
+
+// ErrorWithDetails responds with status and an error message, detailed by
+// details, which may be structured
+func ErrorWithDetails(c *fiber.Ctx, status int, message string, details interface{}) error {
+	return c.Status(status).JSON(Envelope{Error: true, Message: message, Details: details})
+}
+
+// Fail responds with status and envelope as an error, for errors carrying a
+// code, failed fields, or data besides their details
+func Fail(c *fiber.Ctx, status int, envelope Envelope) error {
+	envelope.Error = true
+	return c.Status(status).JSON(envelope)
+}
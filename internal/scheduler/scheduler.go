@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	scheduler *Scheduler
+	once      sync.Once
+)
+
+// TaskFunc is the function run on each tick of a scheduled task
+type TaskFunc func(ctx context.Context) error
+
+// TaskStatus reports the schedule and last run of a task
+type TaskStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	Running      bool       `json:"running"`
+	RunCount     int        `json:"run_count"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastStatus   string     `json:"last_status"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+}
+
+// task is a registered recurring task
+type task struct {
+	name     string
+	schedule *Schedule
+	run      TaskFunc
+	next     time.Time
+	status   TaskStatus
+}
+
+// Scheduler runs registered tasks on their cron schedules
+type Scheduler struct {
+	mu     sync.Mutex
+	tasks  map[string]*task
+	wake   chan struct{}
+	cancel context.CancelFunc
+	ctx    context.Context
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		tasks: make(map[string]*task),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// InitializeScheduler initializes the shared scheduler
+func InitializeScheduler() {
+	once.Do(func() {
+		scheduler = NewScheduler()
+	})
+}
+
+// GetScheduler returns the shared scheduler
+func GetScheduler() *Scheduler {
+	if scheduler == nil {
+		log.Fatal("Scheduler not initialized. Call InitializeScheduler first.")
+	}
+	return scheduler
+}
+
+// Register adds a task with a cron expression. An empty spec or "-" disables the task.
+func (s *Scheduler) Register(name, spec string, run TaskFunc) error {
+	if spec == "" || spec == "-" {
+		log.Printf("Scheduled task %s is disabled", name)
+		return nil
+	}
+
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("failed to register task %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[name] = &task{
+		name:     name,
+		schedule: schedule,
+		run:      run,
+		next:     schedule.Next(time.Now()),
+		status: TaskStatus{
+			Name:       name,
+			Schedule:   spec,
+			LastStatus: "never",
+		},
+	}
+	s.notify()
+	return nil
+}
+
+// Start begins running tasks on their schedules
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.wg.Add(1)
+	go s.loop()
+	log.Printf("Started scheduler with %d tasks", len(s.tasks))
+}
+
+// Stop stops the scheduler and waits for running tasks to return
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	log.Println("Stopping scheduler...")
+	s.cancel()
+	s.wg.Wait()
+}
+
+// RunNow triggers a task immediately, outside its schedule
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[name]
+	if !ok {
+		return fmt.Errorf("task not found")
+	}
+	if t.status.Running {
+		return fmt.Errorf("task already running")
+	}
+	if s.ctx == nil {
+		return fmt.Errorf("scheduler not started")
+	}
+	s.launch(t)
+	return nil
+}
+
+// Status returns the status of all registered tasks sorted by name
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		status := t.status
+		next := t.next
+		status.NextRunAt = &next
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// loop sleeps until the next task is due and launches it
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		var next time.Time
+		for _, t := range s.tasks {
+			if !t.next.After(now) {
+				if !t.status.Running {
+					s.launch(t)
+				}
+				t.next = t.schedule.Next(now)
+			}
+			if next.IsZero() || t.next.Before(next) {
+				next = t.next
+			}
+		}
+		s.mu.Unlock()
+
+		wait := time.Minute
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// launch runs a task in its own goroutine. The caller must hold s.mu.
+func (s *Scheduler) launch(t *task) {
+	t.status.Running = true
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		start := time.Now()
+		err := safeRun(s.ctx, t.run)
+		duration := time.Since(start)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		t.status.Running = false
+		t.status.RunCount++
+		t.status.LastRunAt = &start
+		t.status.LastDuration = duration.String()
+		if err != nil {
+			t.status.LastStatus = "failed"
+			t.status.LastError = err.Error()
+			log.Printf("Scheduled task %s failed after %s: %v", t.name, duration, err)
+			return
+		}
+		t.status.LastStatus = "success"
+		t.status.LastError = ""
+		log.Printf("Scheduled task %s completed in %s", t.name, duration)
+	}()
+}
+
+// notify wakes the loop so it picks up schedule changes
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// safeRun runs a task, converting panics into errors
+func safeRun(ctx context.Context, run TaskFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
@@ -0,0 +1,461 @@
+package scheduler
+
+import (
+	"bookstore-api/internal/alerts"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/reports"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/storefront"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RegisterDefaultTasks registers the built-in recurring tasks using schedules
+// from config. The tasks run on svc, and on db for the outbox they purge.
+func RegisterDefaultTasks(s *Scheduler, cfg *config.Config, db *gorm.DB, svc *services.Services) error {
+	if err := s.Register("low_stock_scan", cfg.Scheduler.LowStockScan, lowStockScan(svc, cfg.Scheduler.LowStockThreshold)); err != nil {
+		return err
+	}
+	if err := s.Register("soft_delete_purge", cfg.Scheduler.SoftDeletePurge, softDeletePurge(svc, cfg.Scheduler.SoftDeleteRetention)); err != nil {
+		return err
+	}
+	if err := s.Register("outbox_purge", cfg.Scheduler.OutboxPurge, outboxPurge(db, cfg.Events.Retention)); err != nil {
+		return err
+	}
+	if err := s.Register("report_dispatch", cfg.Scheduler.ReportDispatch, reportDispatch(svc)); err != nil {
+		return err
+	}
+	if err := s.Register("session_purge", cfg.Scheduler.SessionPurge, sessionPurge(svc)); err != nil {
+		return err
+	}
+	if err := s.Register("promotion_sync", cfg.Scheduler.PromotionSync, promotionSync(svc)); err != nil {
+		return err
+	}
+	if err := s.Register("alert_scan", cfg.Scheduler.AlertScan, alertScan(svc)); err != nil {
+		return err
+	}
+	if err := s.Register("partition_sync", cfg.Scheduler.PartitionSync, partitionSync(svc, cfg.Scheduler.PartitionsAhead)); err != nil {
+		return err
+	}
+	if err := s.Register("supplier_sync", cfg.Scheduler.SupplierSync, supplierSync(svc)); err != nil {
+		return err
+	}
+	if err := s.Register("feed_generation", cfg.Scheduler.FeedGeneration, feedGeneration(svc)); err != nil {
+		return err
+	}
+	if err := s.Register("sitemap_generation", cfg.Scheduler.SitemapGeneration, sitemapGeneration(svc)); err != nil {
+		return err
+	}
+	if cfg.Archive.Enabled {
+		if err := s.Register("archive", cfg.Scheduler.Archive, archive(svc, cfg.Archive.Retention)); err != nil {
+			return err
+		}
+	}
+	if cfg.Rentals.Enabled {
+		if err := s.Register("rental_late_scan", cfg.Scheduler.RentalLateScan, rentalLateScan(svc)); err != nil {
+			return err
+		}
+		if err := s.Register("stats_refresh", cfg.Scheduler.StatsRefresh, statsRefresh(svc)); err != nil {
+			return err
+		}
+	}
+	if cfg.ReadModels.Enabled {
+		if err := s.Register("listing_rebuild", cfg.Scheduler.ListingRebuild, listingRebuild(svc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lowStockScan logs each active tenant's books whose stock is at or below the
+// tenant's threshold, or the global threshold when the tenant sets none
+func lowStockScan(svc *services.Services, threshold int) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		for i := range tenants {
+			tenant := &tenants[i]
+			tenantThreshold := tenant.LowStockThresholdOr(threshold)
+			books, err := svc.Books.WithContext(tenancy.WithTenant(ctx, tenant)).GetLowStockBooks(tenantThreshold)
+			if err != nil {
+				return err
+			}
+
+			for _, book := range books {
+				utils.LogWarn("Low stock", map[string]interface{}{
+					"tenant":  tenant.Slug,
+					"book_id": book.ID,
+					"title":   book.Title,
+					"stock":   book.Stock,
+				})
+			}
+			utils.LogInfo("Low stock scan completed", map[string]interface{}{
+				"tenant":    tenant.Slug,
+				"threshold": tenantThreshold,
+				"count":     len(books),
+			})
+		}
+		return nil
+	}
+}
+
+// softDeletePurge permanently removes rows soft deleted longer ago than the retention period
+func softDeletePurge(svc *services.Services, retention time.Duration) TaskFunc {
+	return func(ctx context.Context) error {
+		purged, err := svc.Maintenance.WithContext(tenancy.WithSystem(ctx)).PurgeSoftDeleted(time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+
+		utils.LogInfo("Soft delete purge completed", purged)
+		return nil
+	}
+}
+
+// outboxPurge deletes published domain events older than the retention period
+func outboxPurge(db *gorm.DB, retention time.Duration) TaskFunc {
+	return func(ctx context.Context) error {
+		purged, err := events.PurgePublished(tenancy.System(db), time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+
+		utils.LogInfo("Outbox purge completed", map[string]interface{}{
+			"purged": purged,
+		})
+		return nil
+	}
+}
+
+// reportDispatch enqueues a generation job for each report definition whose schedule is due
+func reportDispatch(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		queue := jobs.GetQueue()
+		now := time.Now()
+
+		scheduled, err := svc.Reports.WithContext(tenancy.WithSystem(ctx)).ScheduleDue(now,
+			func(report *models.ReportDefinition) (time.Time, error) {
+				return NextRun(report.Schedule, now)
+			},
+			func(tx *gorm.DB, report *models.ReportDefinition) error {
+				_, err := queue.EnqueueTx(tx, reports.JobType, reports.Payload{ReportID: report.ID, RunAt: now})
+				return err
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if scheduled > 0 {
+			utils.LogInfo("Report dispatch completed", map[string]interface{}{
+				"scheduled": scheduled,
+			})
+		}
+		return nil
+	}
+}
+
+// sessionPurge deletes login sessions whose refresh tokens have expired
+func sessionPurge(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		purged, err := svc.Sessions.WithContext(tenancy.WithSystem(ctx)).PurgeExpiredSessions(time.Now())
+		if err != nil {
+			return err
+		}
+
+		utils.LogInfo("Session purge completed", map[string]interface{}{
+			"purged": purged,
+		})
+		return nil
+	}
+}
+
+// promotionSync activates each active tenant's promotions whose schedule has
+// started and deactivates those that have ended. It runs per tenant so the
+// promotion events it records belong to the promotion's tenant.
+func promotionSync(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range tenants {
+			tenant := &tenants[i]
+			started, ended, err := svc.Promotions.WithContext(tenancy.WithTenant(ctx, tenant)).SyncPromotions(now)
+			if err != nil {
+				return err
+			}
+
+			if started > 0 || ended > 0 {
+				utils.LogInfo("Promotion sync completed", map[string]interface{}{
+					"tenant":  tenant.Slug,
+					"started": started,
+					"ended":   ended,
+				})
+			}
+		}
+		return nil
+	}
+}
+
+// supplierSync pulls the feeds of each active tenant's enabled dropshipping
+// suppliers and applies their prices and stock. A feed that cannot be read
+// is recorded as a failed sync and does not stop the others.
+func supplierSync(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		for i := range tenants {
+			tenant := &tenants[i]
+			syncs, err := svc.Suppliers.WithContext(tenancy.WithTenant(ctx, tenant)).SyncEnabledSuppliers()
+			if err != nil {
+				return err
+			}
+
+			for _, run := range syncs {
+				fields := map[string]interface{}{
+					"tenant":    tenant.Slug,
+					"supplier":  run.SupplierID,
+					"rows":      run.Rows,
+					"matched":   run.Matched,
+					"unmatched": run.Unmatched,
+					"updated":   run.Updated,
+					"failed":    run.Failed,
+				}
+				if run.Status == models.SupplierSyncFailed {
+					fields["error"] = run.Error
+					utils.LogWarn("Supplier sync failed", fields)
+				} else {
+					utils.LogInfo("Supplier sync completed", fields)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// feedGeneration regenerates each active tenant's storefront product feeds
+func feedGeneration(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		for i := range tenants {
+			tenant := &tenants[i]
+			counts, err := svc.Feeds.WithContext(tenancy.WithTenant(ctx, tenant)).GenerateFeeds()
+			if err != nil {
+				return err
+			}
+
+			utils.LogInfo("Feeds generated", map[string]interface{}{
+				"tenant":       tenant.Slug,
+				"products":     counts[storefront.FeedGoogleMerchant],
+				"new_releases": counts[storefront.FeedNewReleasesRSS],
+			})
+		}
+		return nil
+	}
+}
+
+// sitemapGeneration regenerates each active tenant's sitemaps
+func sitemapGeneration(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		for i := range tenants {
+			tenant := &tenants[i]
+			counts, err := svc.Sitemaps.WithContext(tenancy.WithTenant(ctx, tenant)).GenerateSitemaps()
+			if err != nil {
+				return err
+			}
+
+			utils.LogInfo("Sitemaps generated", map[string]interface{}{
+				"tenant":     tenant.Slug,
+				"books":      counts[storefront.SitemapBooks],
+				"authors":    counts[storefront.SitemapAuthors],
+				"categories": counts[storefront.SitemapCategories],
+			})
+		}
+		return nil
+	}
+}
+
+// rentalLateScan flags each active tenant's rentals still out past their due
+// date as late, recording a rental.overdue event for each
+func rentalLateScan(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range tenants {
+			tenant := &tenants[i]
+			flagged, err := svc.Rentals.WithContext(tenancy.WithTenant(ctx, tenant)).FlagLateRentals(now)
+			if err != nil {
+				return err
+			}
+
+			if flagged > 0 {
+				utils.LogWarn("Late rentals flagged", map[string]interface{}{
+					"tenant":  tenant.Slug,
+					"flagged": flagged,
+				})
+			}
+		}
+		return nil
+	}
+}
+
+// alertScan enqueues a notification for each active tenant's stock alerts
+// whose books are back in stock and saved searches matched by new books
+func alertScan(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		queue := jobs.GetQueue()
+		now := time.Now()
+		for i := range tenants {
+			tenant := &tenants[i]
+			alertService := svc.Alerts.WithContext(tenancy.WithTenant(ctx, tenant))
+			inStock, err := alertService.NotifyStockAlerts(now, func(tx *gorm.DB, alert *models.StockAlert) error {
+				_, err := queue.EnqueueTx(tx, alerts.JobType, alerts.BackInStock(alert))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			matched, err := alertService.NotifySavedSearches(now, func(tx *gorm.DB, search *models.SavedSearch, books []models.Book) error {
+				_, err := queue.EnqueueTx(tx, alerts.JobType, alerts.NewMatches(search, books))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			if inStock > 0 || matched > 0 {
+				utils.LogInfo("Alert scan completed", map[string]interface{}{
+					"tenant":         tenant.Slug,
+					"back_in_stock":  inStock,
+					"saved_searches": matched,
+				})
+			}
+		}
+		return nil
+	}
+}
+
+// partitionSync creates the monthly partitions of the partitioned log
+// tables ahead of time, so rows rarely land in their default partitions
+func partitionSync(svc *services.Services, ahead int) TaskFunc {
+	return func(ctx context.Context) error {
+		created, err := svc.Maintenance.WithContext(tenancy.WithSystem(ctx)).CreatePartitions(ahead)
+		if err != nil {
+			return err
+		}
+		if len(created) > 0 {
+			utils.LogInfo("Partitions created", map[string]interface{}{
+				"partitions": created,
+			})
+		}
+		return nil
+	}
+}
+
+// archive moves security events and book views older than the archive
+// retention, in every tenant, to cold storage
+func archive(svc *services.Services, retention time.Duration) TaskFunc {
+	return func(ctx context.Context) error {
+		manifests, err := svc.Archives.
+			WithContext(tenancy.WithSystem(ctx)).
+			Archive(time.Now().Add(-retention))
+		archived := make(map[string]int64)
+		for _, manifest := range manifests {
+			archived[manifest.SourceTable] += manifest.Rows
+		}
+		if len(manifests) > 0 {
+			utils.LogInfo("Archive completed", map[string]interface{}{
+				"archives": len(manifests),
+				"rows":     archived,
+			})
+		}
+		return err
+	}
+}
+
+// statsRefresh refreshes the materialized views behind the aggregate
+// statistics, which hold every tenant's data
+func statsRefresh(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		views, err := svc.Maintenance.WithContext(tenancy.WithSystem(ctx)).RefreshStatsViews()
+		if err != nil {
+			return err
+		}
+		utils.LogInfo("Statistics views refreshed", map[string]interface{}{
+			"views": views,
+		})
+		return nil
+	}
+}
+
+// listingRebuild rebuilds each active tenant's book listings, which corrects
+// listings that missed an event and picks up rating changes, which record none
+func listingRebuild(svc *services.Services) TaskFunc {
+	return func(ctx context.Context) error {
+		tenants, err := svc.Tenants.GetActiveTenants()
+		if err != nil {
+			return err
+		}
+
+		for i := range tenants {
+			tenant := &tenants[i]
+			listed, err := svc.Listings.WithContext(tenancy.WithTenant(ctx, tenant)).Rebuild()
+			if err != nil {
+				return err
+			}
+			utils.LogInfo("Listing rebuild completed", map[string]interface{}{
+				"tenant": tenant.Slug,
+				"books":  listed,
+			})
+		}
+		return nil
+	}
+}
+
+// NextRun returns the first time after t matching the cron expression
+func NextRun(spec string, t time.Time) (time.Time, error) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := schedule.Next(t)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never matches", spec)
+	}
+	return next, nil
+}
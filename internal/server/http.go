@@ -3,8 +3,15 @@ package server
 import (
 	"bookstore-api/internal/config"
 	"bookstore-api/internal/handlers"
+	"bookstore-api/internal/listener"
 	"bookstore-api/internal/middleware"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/response"
+	"bookstore-api/internal/services"
+	"context"
 	"log"
+	"net"
+	"net/http"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -14,12 +21,17 @@ import (
 
 // HTTPServer represents the HTTP server
 type HTTPServer struct {
-	app    *fiber.App
-	config *config.Config
+	app      *fiber.App
+	config   *config.Config
+	services *services.Services
+	handlers *handlers.Handlers
+	// grpcWeb serves gRPC-Web calls when set
+	grpcWeb http.Handler
 }
 
-// NewHTTPServer creates a new HTTP server instance
-func NewHTTPServer(cfg *config.Config) *HTTPServer {
+// NewHTTPServer creates a new HTTP server instance serving the routes of h,
+// authenticating requests with svc
+func NewHTTPServer(cfg *config.Config, svc *services.Services, h *handlers.Handlers) *HTTPServer {
 	// Create Fiber app with config
 	app := fiber.New(fiber.Config{
 		AppName: "Bookstore API v1.0.0",
@@ -29,18 +41,25 @@ func NewHTTPServer(cfg *config.Config) *HTTPServer {
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
 			}
-			return c.Status(code).JSON(fiber.Map{
-				"error":   true,
-				"message": err.Error(),
-			})
+			return response.Error(c, code, err.Error(), nil)
 		},
 	})
 
 	// Initialize middleware
+	tenantMiddleware := middleware.NewTenantMiddleware(cfg)
 	rateLimitMiddleware := middleware.NewRateLimitMiddleware()
 	requestLoggerMiddleware := middleware.NewRequestLoggerMiddleware()
+	timeoutMiddleware := middleware.NewTimeoutMiddleware(cfg)
+	paginationMiddleware := middleware.NewPaginationMiddleware(cfg)
+	negotiationMiddleware := middleware.NewNegotiationMiddleware()
+	responseFormatMiddleware := middleware.NewResponseFormatMiddleware(cfg)
+	bodySampleMiddleware := middleware.NewBodySampleMiddleware(cfg)
+	tracingMiddleware := middleware.NewTracingMiddleware()
+	errorRateMiddleware := middleware.NewErrorRateMiddleware()
 
-	// Global middleware
+	// Global middleware. Error rates are tracked outside recover so requests
+	// that panicked count as server errors.
+	app.Use(errorRateMiddleware.Track())
 	app.Use(recover.New())
 	app.Use(logger.New(logger.Config{
 		Format: "[${time}] ${status} - ${method} ${path} (${latency})\n",
@@ -48,93 +67,87 @@ func NewHTTPServer(cfg *config.Config) *HTTPServer {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With",
+		AllowHeaders:     "Origin,Content-Type,Accept,Accept-Language,Authorization,X-Requested-With,X-Response-Format,X-Captcha-Token,X-Grpc-Web,X-User-Agent,Grpc-Timeout," + cfg.Tenancy.Header,
+		ExposeHeaders:    "Grpc-Status,Grpc-Message,Grpc-Status-Details-Bin",
 		AllowCredentials: false,
 	}))
+	app.Use(tracingMiddleware.Trace())
+	app.Use(bodySampleMiddleware.Sample())
+	app.Use(timeoutMiddleware.Timeout())
+	app.Use(paginationMiddleware.Limits())
+	app.Use(negotiationMiddleware.Negotiate())
+	app.Use(responseFormatMiddleware.ResponseFormat())
+	app.Use(tenantMiddleware.ResolveTenant())
 	app.Use(rateLimitMiddleware.RateLimit())
 	app.Use(requestLoggerMiddleware.RequestLogger())
 
 	return &HTTPServer{
-		app:    app,
-		config: cfg,
+		app:      app,
+		config:   cfg,
+		services: svc,
+		handlers: h,
 	}
 }
 
-// SetupRoutes configures all the routes
+// SetupRoutes registers the routes of every enabled module
 func (s *HTTPServer) SetupRoutes() {
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware()
-	rateLimitMiddleware := middleware.NewRateLimitMiddleware()
-
-	// Health check routes
-	healthHandler := handlers.NewHealthHandler()
-	s.app.Get("/health", healthHandler.Health)
-	s.app.Get("/ready", healthHandler.Ready)
+	authMiddleware := middleware.NewAuthMiddleware(s.services)
+	tenantMiddleware := middleware.NewTenantMiddleware(s.config)
+	localeMiddleware := middleware.NewLocaleMiddleware(s.config)
+	deps := &Deps{
+		Config:       s.config,
+		Handlers:     s.handlers,
+		Auth:         authMiddleware,
+		Tenant:       tenantMiddleware,
+		RateLimit:    middleware.NewRateLimitMiddleware(),
+		Bulkhead:     middleware.NewBulkheadMiddleware(s.config),
+		LoginGuard:   middleware.NewLoginGuardMiddleware(s.services),
+		RequireAdmin: authMiddleware.RequireRole(models.RoleAdmin),
+		GRPCWeb:      s.grpcWeb,
+	}
 
-	// API documentation
-	docsHandler := handlers.NewDocsHandler()
-	s.app.Get("/docs", docsHandler.GetAPIDocs)
-	s.app.Get("/api/docs", docsHandler.GetAPIDocs)
+	// API v1 routes, with the groups modules share
+	api := s.app.Group("/api/v1", tenantMiddleware.RequireTenant(), localeMiddleware.Locale())
+	router := &Router{
+		App:   s.app,
+		API:   api,
+		Admin: api.Group("/admin", authMiddleware.RequireAuth(), deps.RequireAdmin),
+		Books: api.Group("/books"),
+	}
 
-	// API v1 routes
-	api := s.app.Group("/api/v1")
-	
-	// Initialize handlers
-	authorHandler := handlers.NewAuthorHandler()
-	categoryHandler := handlers.NewCategoryHandler()
-	bookHandler := handlers.NewBookHandler()
-	
-	// Author routes
-	authors := api.Group("/authors")
-	authors.Post("/", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), authorHandler.CreateAuthor)
-	authors.Get("/", authorHandler.GetAllAuthors)
-	authors.Get("/search", authorHandler.SearchAuthors)
-	authors.Get("/:id", authorHandler.GetAuthor)
-	authors.Put("/:id", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), authorHandler.UpdateAuthor)
-	authors.Delete("/:id", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), authorHandler.DeleteAuthor)
-	
-	// Category routes
-	categories := api.Group("/categories")
-	categories.Post("/", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), categoryHandler.CreateCategory)
-	categories.Get("/", categoryHandler.GetAllCategories)
-	categories.Get("/search", categoryHandler.SearchCategories)
-	categories.Get("/:id", categoryHandler.GetCategory)
-	categories.Put("/:id", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), categoryHandler.UpdateCategory)
-	categories.Delete("/:id", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), categoryHandler.DeleteCategory)
-	
-	// Book routes
-	books := api.Group("/books")
-	books.Post("/", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), bookHandler.CreateBook)
-	books.Get("/", bookHandler.GetAllBooks)
-	books.Get("/search", bookHandler.SearchBooks)
-	books.Get("/author/:authorId", bookHandler.GetBooksByAuthor)
-	books.Get("/category/:categoryId", bookHandler.GetBooksByCategory)
-	books.Get("/:id", bookHandler.GetBook)
-	books.Put("/:id", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), bookHandler.UpdateBook)
-	books.Put("/:id/stock", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), bookHandler.UpdateBookStock)
-	books.Delete("/:id", rateLimitMiddleware.StrictRateLimit(), authMiddleware.RequireAuth(), bookHandler.DeleteBook)
+	for _, m := range modules(s.config.Server.DisabledModules) {
+		m.RegisterRoutes(router, deps)
+	}
+}
 
-	// Root route
-	s.app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Welcome to Bookstore API",
-			"version": "1.0.0",
-			"status":  "running",
-		})
-	})
+// SetGRPCWebHandler serves handler, the gRPC-Web handler of the gRPC server,
+// under /grpc-web. It must be called before SetupRoutes.
+func (s *HTTPServer) SetGRPCWebHandler(handler http.Handler) {
+	s.grpcWeb = handler
 }
 
 // Start starts the HTTP server
 func (s *HTTPServer) Start() error {
 	addr := s.config.Server.Host + ":" + s.config.Server.Port
-	log.Printf("Starting HTTP server on %s", addr)
-	return s.app.Listen(addr)
+	ln, err := listener.Listen("http", addr, s.config.Server.ReusePort)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve serves the HTTP API on ln until the server is shut down
+func (s *HTTPServer) Serve(ln net.Listener) error {
+	log.Printf("Starting HTTP server on %s", ln.Addr())
+	return s.app.Listener(ln)
 }
 
-// Shutdown gracefully shuts down the HTTP server
-func (s *HTTPServer) Shutdown() error {
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish, closing the remaining connections when ctx is done
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down HTTP server...")
-	return s.app.Shutdown()
+	return s.app.ShutdownWithContext(ctx)
 }
 
 // GetApp returns the Fiber app instance (for testing)
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/handlers"
+	"bookstore-api/internal/middleware"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Module is a feature whose routes the HTTP server registers. Core modules
+// are listed in coreModules; optional ones, such as webhooks and the admin
+// app, add themselves with RegisterModule from files behind build tags, so
+// building with -tags nowebhooks or noadminui leaves them out. Any module
+// can also be switched off with SERVER_DISABLED_MODULES.
+type Module interface {
+	// Name identifies the module in SERVER_DISABLED_MODULES
+	Name() string
+	// RegisterRoutes adds the module's routes to r
+	RegisterRoutes(r *Router, d *Deps)
+}
+
+// Router holds the app and the route groups modules add their routes to
+type Router struct {
+	App *fiber.App
+	// API is /api/v1, which requires a tenant and negotiates the locale
+	API fiber.Router
+	// Admin is /api/v1/admin, limited to admins
+	Admin fiber.Router
+	// Books is /api/v1/books, which other modules extend with book routes
+	Books fiber.Router
+}
+
+// Deps holds what modules need to register their routes
+type Deps struct {
+	Config       *config.Config
+	Handlers     *handlers.Handlers
+	Auth         *middleware.AuthMiddleware
+	Tenant       *middleware.TenantMiddleware
+	RateLimit    *middleware.RateLimitMiddleware
+	Bulkhead     *middleware.BulkheadMiddleware
+	LoginGuard   *middleware.LoginGuardMiddleware
+	RequireAdmin fiber.Handler
+	// GRPCWeb serves gRPC-Web calls, and is nil unless set with
+	// SetGRPCWebHandler
+	GRPCWeb http.Handler
+}
+
+// routeModule is a Module registering its routes with a function
+type routeModule struct {
+	name     string
+	register func(r *Router, d *Deps)
+}
+
+func (m routeModule) Name() string { return m.name }
+
+func (m routeModule) RegisterRoutes(r *Router, d *Deps) { m.register(r, d) }
+
+// coreModules are the modules always compiled in, in the order their routes
+// are registered
+var coreModules = []Module{
+	routeModule{"system", registerSystemRoutes},
+	routeModule{"authors", registerAuthorRoutes},
+	routeModule{"categories", registerCategoryRoutes},
+	routeModule{"books", registerBookRoutes},
+	routeModule{"search", registerSearchRoutes},
+	routeModule{"alerts", registerAlertRoutes},
+	routeModule{"preferences", registerPreferenceRoutes},
+	routeModule{"auth", registerAuthRoutes},
+	routeModule{"events", registerEventRoutes},
+	routeModule{"promotions", registerPromotionRoutes},
+	routeModule{"warehouses", registerWarehouseRoutes},
+	routeModule{"suppliers", registerSupplierRoutes},
+	routeModule{"rentals", registerRentalRoutes},
+	routeModule{"admin", registerAdminRoutes},
+	routeModule{"dead-letters", registerDeadLetterRoutes},
+	routeModule{"revisions", registerRevisionRoutes},
+	routeModule{"users", registerUserRoutes},
+	routeModule{"security", registerSecurityRoutes},
+	routeModule{"archives", registerArchiveRoutes},
+	routeModule{"reports", registerReportRoutes},
+	routeModule{"operations", registerOperationRoutes},
+	routeModule{"tenants", registerTenantRoutes},
+	routeModule{"feeds", registerFeedRoutes},
+	routeModule{"sitemaps", registerSitemapRoutes},
+	routeModule{"dashboard", registerDashboardRoutes},
+	routeModule{"grpc-web", registerGRPCWebRoutes},
+}
+
+// optionalModules are the modules added with RegisterModule
+var optionalModules []Module
+
+// RegisterModule adds m to the modules the HTTP server registers, after the
+// core modules. It is meant to be called from init.
+func RegisterModule(m Module) {
+	optionalModules = append(optionalModules, m)
+}
+
+// modules returns the modules to register, leaving out those named in
+// disabled, a comma-separated list
+func modules(disabled string) []Module {
+	all := append(append([]Module(nil), coreModules...), optionalModules...)
+
+	skip := make(map[string]bool)
+	for _, name := range strings.Split(disabled, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			skip[name] = true
+		}
+	}
+
+	enabled := make([]Module, 0, len(all))
+	for _, m := range all {
+		if skip[m.Name()] {
+			delete(skip, m.Name())
+			log.Printf("Module %s is disabled", m.Name())
+			continue
+		}
+		enabled = append(enabled, m)
+	}
+	for name := range skip {
+		log.Printf("Warning: SERVER_DISABLED_MODULES names unknown module %q", name)
+	}
+	return enabled
+}
@@ -0,0 +1,46 @@
+package server
+
+// registerAlertRoutes registers the saved searches and back-in-stock alerts
+// of the current user
+func registerAlertRoutes(r *Router, d *Deps) {
+	alertHandler := d.Handlers.Alert
+	r.Books.Post("/:id/stock-alert", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), alertHandler.CreateStockAlert)
+	r.Books.Delete("/:id/stock-alert", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), alertHandler.DeleteStockAlert)
+	r.API.Get("/stock-alerts", d.Auth.RequireAuth(), alertHandler.GetStockAlerts)
+	savedSearches := r.API.Group("/saved-searches", d.Auth.RequireAuth())
+	savedSearches.Get("/", alertHandler.GetSavedSearches)
+	savedSearches.Post("/", d.RateLimit.StrictRateLimit(), alertHandler.CreateSavedSearch)
+	savedSearches.Delete("/:id", d.RateLimit.StrictRateLimit(), alertHandler.DeleteSavedSearch)
+}
+
+// registerPreferenceRoutes registers the marketing preferences, consents,
+// and notification channels of the current user
+func registerPreferenceRoutes(r *Router, d *Deps) {
+	// Marketing preferences and consents of the current user
+	preferenceHandler := d.Handlers.Preference
+	preferences := r.API.Group("/preferences", d.Auth.RequireAuth())
+	preferences.Get("/", preferenceHandler.GetPreferences)
+	preferences.Put("/", d.RateLimit.StrictRateLimit(), preferenceHandler.UpdatePreferences)
+	preferences.Get("/consents", preferenceHandler.GetConsentHistory)
+
+	// Notification channels of the current user
+	notificationHandler := d.Handlers.Notification
+	preferences.Get("/notifications", notificationHandler.GetNotificationSettings)
+	preferences.Put("/notifications", d.RateLimit.StrictRateLimit(), notificationHandler.UpdateNotificationSettings)
+}
+
+// registerAuthRoutes registers social login and the sessions of the current
+// user
+func registerAuthRoutes(r *Router, d *Deps) {
+	authHandler := d.Handlers.Auth
+	authRoutes := r.API.Group("/auth")
+	authRoutes.Get("/providers", authHandler.GetProviders)
+	authRoutes.Get("/me", d.Auth.RequireAuth(), authHandler.Me)
+	authRoutes.Post("/refresh", d.RateLimit.StrictRateLimit(), d.LoginGuard.Protect(), authHandler.Refresh)
+	authRoutes.Post("/logout", d.Auth.RequireAuth(), authHandler.Logout)
+	authRoutes.Get("/sessions", d.Auth.RequireAuth(), authHandler.GetSessions)
+	authRoutes.Delete("/sessions", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), authHandler.RevokeAllSessions)
+	authRoutes.Delete("/sessions/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), authHandler.RevokeSession)
+	authRoutes.Get("/:provider/login", d.RateLimit.StrictRateLimit(), d.LoginGuard.Protect(), authHandler.Login)
+	authRoutes.Get("/:provider/callback", d.RateLimit.StrictRateLimit(), d.LoginGuard.Lockout(), authHandler.Callback)
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bookstore-api/internal/middleware"
+	"bookstore-api/internal/services"
+)
+
+// registerAdminRoutes registers the admin routes for stats, scheduled tasks,
+// imports, and maintenance
+func registerAdminRoutes(r *Router, d *Deps) {
+	adminHandler := d.Handlers.Admin
+	authorHandler := d.Handlers.Author
+	bookHandler := d.Handlers.Book
+	r.Admin.Get("/stats", adminHandler.GetStats)
+	r.Admin.Get("/books", bookHandler.GetAdminBooks)
+	if d.Config.Rentals.Enabled {
+		r.Admin.Get("/stats/rentals", adminHandler.GetRentalStats)
+	}
+	r.Admin.Get("/scheduler", d.Tenant.RequireDefaultTenant(), adminHandler.GetScheduledTasks)
+	r.Admin.Post("/scheduler/:name/run", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.RunScheduledTask)
+	r.Admin.Post("/onix/import", d.RateLimit.StrictRateLimit(), d.Bulkhead.Import(), adminHandler.ImportONIX)
+	r.Admin.Post("/catalog/diff", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.DiffCatalog)
+	r.Admin.Put("/authors/:id/owner", d.RateLimit.StrictRateLimit(), authorHandler.SetAuthorOwner)
+	r.Admin.Post("/maintenance/reindex", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.ReindexSearch)
+	r.Admin.Get("/maintenance/partitions", d.Tenant.RequireDefaultTenant(), adminHandler.GetPartitions)
+	r.Admin.Post("/reindex", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.EnqueueReindex)
+	r.Admin.Post("/cache/flush", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.EnqueueCacheFlush)
+	r.Admin.Get("/jobs/:id", d.Tenant.RequireDefaultTenant(), adminHandler.GetJob)
+	r.Admin.Get("/log-level", d.Tenant.RequireDefaultTenant(), adminHandler.GetLogLevel)
+	r.Admin.Put("/log-level", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.SetLogLevel)
+	if d.Config.SlowQuery.Threshold > 0 {
+		r.Admin.Get("/slow-queries", d.Tenant.RequireDefaultTenant(), adminHandler.GetSlowQueries)
+		r.Admin.Delete("/slow-queries", d.Tenant.RequireDefaultTenant(), adminHandler.ClearSlowQueries)
+	}
+	if d.Config.Inventory.Mode == services.InventoryModeEventSourced {
+		r.Admin.Post("/maintenance/rebuild-stock", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.RebuildStock)
+	}
+	if d.Config.Rentals.Enabled {
+		r.Admin.Post("/maintenance/refresh-stats", d.Tenant.RequireDefaultTenant(), d.RateLimit.StrictRateLimit(), adminHandler.RefreshStatsViews)
+	}
+}
+
+// registerDeadLetterRoutes registers the dead-letter queue of background
+// jobs that failed every attempt
+func registerDeadLetterRoutes(r *Router, d *Deps) {
+	deadLetterHandler := d.Handlers.DeadLetter
+	deadLetters := r.Admin.Group("/dead-letters", d.Tenant.RequireDefaultTenant())
+	deadLetters.Get("/", deadLetterHandler.GetDeadLetters)
+	deadLetters.Post("/retry", d.RateLimit.StrictRateLimit(), deadLetterHandler.RetryDeadLetters)
+	deadLetters.Post("/purge", d.RateLimit.StrictRateLimit(), deadLetterHandler.PurgeDeadLetters)
+	deadLetters.Post("/:id/retry", d.RateLimit.StrictRateLimit(), deadLetterHandler.RetryDeadLetter)
+	deadLetters.Delete("/:id", d.RateLimit.StrictRateLimit(), deadLetterHandler.DeleteDeadLetter)
+}
+
+// registerUserRoutes registers user and API key management
+func registerUserRoutes(r *Router, d *Deps) {
+	userHandler := d.Handlers.User
+	r.Admin.Post("/users", d.RateLimit.StrictRateLimit(), userHandler.CreateUser)
+	r.Admin.Put("/users/:id/role", d.RateLimit.StrictRateLimit(), userHandler.SetUserRole)
+	apiKeyHandler := d.Handlers.APIKey
+	r.Admin.Get("/api-keys", apiKeyHandler.GetAllAPIKeys)
+	r.Admin.Post("/api-keys", d.RateLimit.StrictRateLimit(), apiKeyHandler.CreateAPIKey)
+	r.Admin.Post("/api-keys/:id/rotate", d.RateLimit.StrictRateLimit(), apiKeyHandler.RotateAPIKey)
+	r.Admin.Delete("/api-keys/:id", d.RateLimit.StrictRateLimit(), apiKeyHandler.RevokeAPIKey)
+}
+
+// registerSecurityRoutes registers failed logins, lockouts, and other
+// security events
+func registerSecurityRoutes(r *Router, d *Deps) {
+	securityHandler := d.Handlers.Security
+	r.Admin.Get("/security-events", securityHandler.GetSecurityEvents)
+}
+
+// registerArchiveRoutes registers the security events and book views moved
+// to cold storage, if archiving is enabled
+func registerArchiveRoutes(r *Router, d *Deps) {
+	if d.Config.Archive.Enabled {
+		archiveHandler := d.Handlers.Archive
+		r.Admin.Get("/archives", d.Tenant.RequireDefaultTenant(), archiveHandler.GetArchives)
+		r.Admin.Get("/archives/:id", d.Tenant.RequireDefaultTenant(), archiveHandler.GetArchive)
+	}
+}
+
+// registerReportRoutes registers scheduled reports and their downloads
+func registerReportRoutes(r *Router, d *Deps) {
+	reportHandler := d.Handlers.Report
+	r.Admin.Get("/reports", reportHandler.GetAllReports)
+	r.Admin.Post("/reports", d.RateLimit.StrictRateLimit(), reportHandler.CreateReport)
+	r.Admin.Get("/reports/:id", reportHandler.GetReport)
+	r.Admin.Put("/reports/:id", d.RateLimit.StrictRateLimit(), reportHandler.UpdateReport)
+	r.Admin.Delete("/reports/:id", d.RateLimit.StrictRateLimit(), reportHandler.DeleteReport)
+	r.Admin.Post("/reports/:id/run", d.RateLimit.StrictRateLimit(), reportHandler.RunReport)
+	r.Admin.Get("/reports/:id/download", d.RateLimit.StrictRateLimit(), reportHandler.DownloadReport)
+	r.Admin.Post("/reports/:id/download-link", d.RateLimit.StrictRateLimit(), reportHandler.CreateDownloadLink)
+
+	// Private downloads through signed links, in place of an access token
+	signedURLMiddleware := middleware.NewSignedURLMiddleware()
+	r.API.Get("/reports/:id/download", d.RateLimit.StrictRateLimit(), signedURLMiddleware.RequireSignature(), reportHandler.DownloadReport)
+}
+
+// registerOperationRoutes registers the long-running operations of async
+// book imports and exports
+func registerOperationRoutes(r *Router, d *Deps) {
+	operationHandler := d.Handlers.Operation
+	r.API.Get("/operations/:id", d.Auth.RequireAuth(), d.RequireAdmin, operationHandler.GetOperation)
+	r.API.Get("/operations/:id/download", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, operationHandler.DownloadOperation)
+}
+
+// registerTenantRoutes registers tenant management, limited to the default
+// tenant's admins
+func registerTenantRoutes(r *Router, d *Deps) {
+	tenantHandler := d.Handlers.Tenant
+	tenants := r.Admin.Group("/tenants", d.Tenant.RequireDefaultTenant())
+	tenants.Get("/", tenantHandler.GetAllTenants)
+	tenants.Post("/", d.RateLimit.StrictRateLimit(), tenantHandler.CreateTenant)
+	tenants.Get("/:id", tenantHandler.GetTenant)
+	tenants.Put("/:id", d.RateLimit.StrictRateLimit(), tenantHandler.UpdateTenant)
+	tenants.Delete("/:id", d.RateLimit.StrictRateLimit(), tenantHandler.DeleteTenant)
+}
+
+// registerDashboardRoutes registers the admin dashboard WebSocket
+func registerDashboardRoutes(r *Router, d *Deps) {
+	dashboardHandler := d.Handlers.Dashboard
+	r.App.Get("/ws", d.Tenant.RequireTenant(), d.Auth.RequireWebSocketAuth(), d.RequireAdmin, dashboardHandler.RequireUpgrade, dashboardHandler.Dashboard())
+}
@@ -0,0 +1,20 @@
+//go:build !noadminui
+
+package server
+
+import (
+	"bookstore-api/internal/adminui"
+)
+
+func init() {
+	RegisterModule(routeModule{"admin-ui", registerAdminUIRoutes})
+}
+
+// registerAdminUIRoutes serves the admin app, calling the API from the
+// browser, if it is enabled. Builds tagged noadminui leave the app and its
+// assets out.
+func registerAdminUIRoutes(r *Router, d *Deps) {
+	if d.Config.AdminUI.Enabled {
+		r.App.Use("/admin", adminui.Handler())
+	}
+}
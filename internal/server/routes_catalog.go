@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bookstore-api/internal/handlers"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+)
+
+// registerAuthorRoutes registers the author routes, with author photos
+func registerAuthorRoutes(r *Router, d *Deps) {
+	authorHandler := d.Handlers.Author
+	authorPhotoHandler := d.Handlers.AuthorPhoto
+	authors := r.API.Group("/authors")
+	authors.Post("/", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, authorHandler.CreateAuthor)
+	authors.Get("/", authorHandler.GetAllAuthors).Name(handlers.RouteAuthorsList)
+	authors.Get("/search", d.Bulkhead.Search(), authorHandler.SearchAuthors)
+	authors.Get("/duplicates", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, authorHandler.FindDuplicateAuthors)
+	authors.Get("/slug/:slug", authorHandler.GetAuthorBySlug).Name(handlers.RouteAuthorsBySlug)
+	authors.Get("/:id", authorHandler.GetAuthor).Name(handlers.RouteAuthorsGet)
+	authors.Put("/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(authorHandler.OwnsAuthor), authorHandler.UpdateAuthor)
+	authors.Delete("/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, authorHandler.DeleteAuthor)
+	authors.Post("/:id/claim", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), authorHandler.ClaimAuthor)
+	authors.Post("/:id/merge", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, authorHandler.MergeAuthor)
+	authors.Put("/:id/photo", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(authorHandler.OwnsAuthor), authorPhotoHandler.SetAuthorPhoto)
+	authors.Delete("/:id/photo", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(authorHandler.OwnsAuthor), authorPhotoHandler.DeleteAuthorPhoto)
+}
+
+// registerCategoryRoutes registers the category routes, with their translations
+func registerCategoryRoutes(r *Router, d *Deps) {
+	categoryHandler := d.Handlers.Category
+	translationHandler := d.Handlers.Translation
+	categories := r.API.Group("/categories")
+	categories.Post("/", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, categoryHandler.CreateCategory)
+	categories.Get("/", categoryHandler.GetAllCategories).Name(handlers.RouteCategoriesList)
+	categories.Get("/search", d.Bulkhead.Search(), categoryHandler.SearchCategories)
+	categories.Get("/tree", categoryHandler.GetCategoryTree)
+	categories.Get("/slug/:slug", categoryHandler.GetCategoryBySlug).Name(handlers.RouteCategoriesBySlug)
+	categories.Get("/:id", categoryHandler.GetCategory).Name(handlers.RouteCategoriesGet)
+	categories.Put("/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, categoryHandler.UpdateCategory)
+	categories.Delete("/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, categoryHandler.DeleteCategory)
+	categories.Get("/:id/translations", translationHandler.GetTranslations(models.TranslationEntityCategory))
+	categories.Put("/:id/translations/:locale", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, translationHandler.SetTranslations(models.TranslationEntityCategory))
+	categories.Delete("/:id/translations/:locale", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, translationHandler.DeleteTranslations(models.TranslationEntityCategory))
+}
+
+// registerBookRoutes registers the book routes, with their views, previews,
+// stock, and translations
+func registerBookRoutes(r *Router, d *Deps) {
+	bookHandler := d.Handlers.Book
+	viewHandler := d.Handlers.View
+	bookPreviewHandler := d.Handlers.BookPreview
+	translationHandler := d.Handlers.Translation
+	r.Books.Post("/", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, bookHandler.CreateBook)
+	r.Books.Get("/", bookHandler.GetAllBooks).Name(handlers.RouteBooksList)
+	r.Books.Get("/search", d.Bulkhead.Search(), bookHandler.SearchBooks)
+	r.Books.Get("/compare", bookHandler.CompareBooks)
+	r.Books.Get("/trending", viewHandler.GetTrendingBooks)
+	r.Books.Get("/recently-viewed", d.Auth.RequireAuth(), viewHandler.GetRecentlyViewed)
+	r.Books.Get("/export", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, d.Bulkhead.Export(), bookHandler.ExportBooks)
+	r.Books.Post("/import", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, d.Bulkhead.Import(), bookHandler.ImportBooks)
+	r.Books.Post("/lookup", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, bookHandler.LookupBook)
+	r.Books.Get("/author/:authorId", bookHandler.GetBooksByAuthor).Name(handlers.RouteBooksByAuthor)
+	r.Books.Get("/category/:categoryId", bookHandler.GetBooksByCategory).Name(handlers.RouteBooksByCategory)
+	r.Books.Get("/slug/:slug", d.Auth.OptionalAuth(), bookHandler.GetBookBySlug).Name(handlers.RouteBooksBySlug)
+	r.Books.Get("/:id", d.Auth.OptionalAuth(), bookHandler.GetBook).Name(handlers.RouteBooksGet)
+	r.Books.Put("/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(bookHandler.OwnsBook), bookHandler.UpdateBook)
+	r.Books.Get("/:id/price-history", d.Auth.OptionalAuth(), bookHandler.RequireVisibleBook, bookHandler.GetPriceHistory)
+	r.Books.Get("/:id/stats", d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(bookHandler.OwnsBook), viewHandler.GetBookStats)
+	r.Books.Get("/:id/preview", d.Auth.OptionalAuth(), bookHandler.RequireVisibleBook, bookPreviewHandler.GetBookPreview)
+	r.Books.Put("/:id/preview", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(bookHandler.OwnsBook), bookPreviewHandler.SetBookPreview)
+	r.Books.Delete("/:id/preview", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(bookHandler.OwnsBook), bookPreviewHandler.DeleteBookPreview)
+	r.Books.Put("/:id/stock", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, bookHandler.UpdateBookStock)
+	if d.Config.Inventory.Mode == services.InventoryModeEventSourced {
+		r.Books.Get("/:id/stock/movements", d.Auth.RequireAuth(), d.RequireAdmin, bookHandler.GetStockMovements)
+		r.Books.Post("/:id/stock/adjustments", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, bookHandler.AdjustBookStock)
+	}
+	r.Books.Delete("/:id", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, bookHandler.DeleteBook)
+	r.Books.Get("/:id/translations", d.Auth.OptionalAuth(), bookHandler.RequireVisibleBook, translationHandler.GetTranslations(models.TranslationEntityBook))
+	r.Books.Put("/:id/translations/:locale", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, translationHandler.SetTranslations(models.TranslationEntityBook))
+	r.Books.Delete("/:id/translations/:locale", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, translationHandler.DeleteTranslations(models.TranslationEntityBook))
+}
+
+// registerSearchRoutes registers search across books, authors, and categories
+func registerSearchRoutes(r *Router, d *Deps) {
+	searchHandler := d.Handlers.Search
+	r.API.Get("/search", d.Bulkhead.Search(), searchHandler.Search)
+}
+
+// registerRevisionRoutes registers the review of book changes submitted by
+// editors who are not admins
+func registerRevisionRoutes(r *Router, d *Deps) {
+	bookHandler := d.Handlers.Book
+	revisionHandler := d.Handlers.Revision
+	r.API.Get("/revisions", d.Auth.RequireAuth(), revisionHandler.GetMyRevisions)
+	revisions := r.Admin.Group("/revisions")
+	revisions.Get("/", revisionHandler.GetRevisions)
+	revisions.Get("/:id", revisionHandler.GetRevision)
+	revisions.Post("/:id/approve", d.RateLimit.StrictRateLimit(), revisionHandler.ApproveRevision)
+	revisions.Post("/:id/reject", d.RateLimit.StrictRateLimit(), revisionHandler.RejectRevision)
+	r.Books.Get("/:id/revisions", d.Auth.RequireAuth(), d.Auth.RequireAdminOrOwner(bookHandler.OwnsBook), revisionHandler.GetBookRevisions)
+	r.Books.Post("/:id/revisions/:revisionId/revert", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, revisionHandler.RevertRevision)
+}
@@ -0,0 +1,57 @@
+package server
+
+// registerPromotionRoutes registers the promotion routes
+func registerPromotionRoutes(r *Router, d *Deps) {
+	promotionHandler := d.Handlers.Promotion
+	promotions := r.API.Group("/promotions", d.Auth.RequireAuth(), d.RequireAdmin)
+	promotions.Post("/", d.RateLimit.StrictRateLimit(), promotionHandler.CreatePromotion)
+	promotions.Get("/", promotionHandler.GetAllPromotions)
+	promotions.Get("/:id", promotionHandler.GetPromotion)
+	promotions.Put("/:id", d.RateLimit.StrictRateLimit(), promotionHandler.UpdatePromotion)
+	promotions.Delete("/:id", d.RateLimit.StrictRateLimit(), promotionHandler.DeletePromotion)
+}
+
+// registerWarehouseRoutes registers the warehouse routes, for stock held at
+// several locations
+func registerWarehouseRoutes(r *Router, d *Deps) {
+	warehouseHandler := d.Handlers.Warehouse
+	warehouses := r.API.Group("/warehouses", d.Auth.RequireAuth(), d.RequireAdmin)
+	warehouses.Post("/", d.RateLimit.StrictRateLimit(), warehouseHandler.CreateWarehouse)
+	warehouses.Get("/", warehouseHandler.GetAllWarehouses)
+	warehouses.Post("/transfers", d.RateLimit.StrictRateLimit(), warehouseHandler.TransferStock)
+	warehouses.Get("/transfers", warehouseHandler.GetTransfers)
+	warehouses.Get("/:id", warehouseHandler.GetWarehouse)
+	warehouses.Put("/:id", d.RateLimit.StrictRateLimit(), warehouseHandler.UpdateWarehouse)
+	warehouses.Delete("/:id", d.RateLimit.StrictRateLimit(), warehouseHandler.DeleteWarehouse)
+	warehouses.Get("/:id/stock", warehouseHandler.GetWarehouseStock)
+	warehouses.Put("/:id/stock/:bookId", d.RateLimit.StrictRateLimit(), warehouseHandler.SetWarehouseStock)
+}
+
+// registerSupplierRoutes registers the supplier routes, for dropshipping
+// suppliers whose feeds are synced into the catalog
+func registerSupplierRoutes(r *Router, d *Deps) {
+	supplierHandler := d.Handlers.Supplier
+	suppliers := r.API.Group("/suppliers", d.Auth.RequireAuth(), d.RequireAdmin)
+	suppliers.Post("/", d.RateLimit.StrictRateLimit(), supplierHandler.CreateSupplier)
+	suppliers.Get("/", supplierHandler.GetAllSuppliers)
+	suppliers.Get("/:id", supplierHandler.GetSupplier)
+	suppliers.Put("/:id", d.RateLimit.StrictRateLimit(), supplierHandler.UpdateSupplier)
+	suppliers.Delete("/:id", d.RateLimit.StrictRateLimit(), supplierHandler.DeleteSupplier)
+	suppliers.Post("/:id/sync", d.RateLimit.StrictRateLimit(), supplierHandler.SyncSupplier)
+	suppliers.Get("/:id/syncs", supplierHandler.GetSupplierSyncs)
+	suppliers.Get("/:id/syncs/:syncId", supplierHandler.GetSupplierSync)
+}
+
+// registerRentalRoutes registers the rental routes, for libraries lending
+// book copies, if rentals are enabled
+func registerRentalRoutes(r *Router, d *Deps) {
+	if d.Config.Rentals.Enabled {
+		rentalHandler := d.Handlers.Rental
+		r.Books.Get("/:id/availability", d.Auth.OptionalAuth(), d.Handlers.Book.RequireVisibleBook, rentalHandler.GetAvailability)
+		r.Books.Post("/:id/rentals", d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), rentalHandler.RentBook)
+		rentals := r.API.Group("/rentals", d.Auth.RequireAuth())
+		rentals.Get("/", rentalHandler.GetRentals)
+		rentals.Get("/:id", d.Auth.RequireAdminOrOwner(rentalHandler.OwnsRental), rentalHandler.GetRental)
+		rentals.Post("/:id/return", d.RateLimit.StrictRateLimit(), d.Auth.RequireAdminOrOwner(rentalHandler.OwnsRental), rentalHandler.ReturnRental)
+	}
+}
@@ -0,0 +1,18 @@
+package server
+
+// registerFeedRoutes registers the storefront product feeds, regenerated by
+// the feed_generation task
+func registerFeedRoutes(r *Router, d *Deps) {
+	feedHandler := d.Handlers.Feed
+	r.App.Get("/feeds/:name", d.Tenant.RequireTenant(), feedHandler.GetFeed)
+	r.Admin.Post("/feeds/generate", d.RateLimit.StrictRateLimit(), feedHandler.GenerateFeeds)
+}
+
+// registerSitemapRoutes registers the sitemaps of storefront pages,
+// regenerated by the sitemap_generation task
+func registerSitemapRoutes(r *Router, d *Deps) {
+	sitemapHandler := d.Handlers.Sitemap
+	r.App.Get("/sitemap.xml", d.Tenant.RequireTenant(), sitemapHandler.GetSitemapIndex)
+	r.App.Get("/sitemaps/:name", d.Tenant.RequireTenant(), sitemapHandler.GetSitemap)
+	r.Admin.Post("/sitemaps/generate", d.RateLimit.StrictRateLimit(), sitemapHandler.GenerateSitemaps)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bookstore-api/internal/middleware"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// grpcWebAdminMethods are the gRPC-Web methods that change the catalog or
+// read operations, which only admins may call, as with the matching REST
+// routes
+var grpcWebAdminMethods = []string{
+	"/bookstore.AuthorService/CreateAuthor",
+	"/bookstore.AuthorService/UpdateAuthor",
+	"/bookstore.AuthorService/DeleteAuthor",
+	"/bookstore.CategoryService/CreateCategory",
+	"/bookstore.CategoryService/UpdateCategory",
+	"/bookstore.CategoryService/DeleteCategory",
+	"/bookstore.BookService/CreateBook",
+	"/bookstore.BookService/UpdateBook",
+	"/bookstore.BookService/DeleteBook",
+	"/bookstore.BookService/UpdateBookStock",
+	"/bookstore.OperationsService/GetOperation",
+}
+
+// registerSystemRoutes registers health checks, API documentation, uploaded
+// media, and the root route
+func registerSystemRoutes(r *Router, d *Deps) {
+	// Health check routes
+	healthHandler := d.Handlers.Health
+	r.App.Get("/health", healthHandler.Health)
+	r.App.Get("/ready", healthHandler.Ready)
+
+	// API documentation
+	docsHandler := d.Handlers.Docs
+	r.App.Get("/docs", docsHandler.GetAPIDocs)
+	r.App.Get("/api/docs", docsHandler.GetAPIDocs)
+
+	// Uploaded media such as author photos and book previews
+	if strings.HasPrefix(d.Config.Storage.URLPrefix, "/") {
+		r.App.Static(d.Config.Storage.URLPrefix, d.Config.Storage.Dir, fiber.Static{MaxAge: 31536000})
+	}
+
+	// Root route
+	r.App.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"message": "Welcome to Bookstore API",
+			"version": "1.0.0",
+			"status":  "running",
+		})
+	})
+}
+
+// registerEventRoutes registers the event stream (Server-Sent Events)
+func registerEventRoutes(r *Router, d *Deps) {
+	streamHandler := d.Handlers.Stream
+	r.API.Get("/events", streamHandler.StreamEvents)
+}
+
+// registerGRPCWebRoutes registers gRPC-Web calls from browser apps,
+// authenticated like the REST API and continuing the request's trace, if a
+// gRPC-Web handler is set
+func registerGRPCWebRoutes(r *Router, d *Deps) {
+	if d.GRPCWeb != nil {
+		grpcWebHandler := adaptor.HTTPHandler(http.StripPrefix("/grpc-web", d.GRPCWeb))
+		grpcWeb := r.App.Group("/grpc-web", d.Tenant.RequireTenant(), middleware.NewTracingMiddleware().Forward())
+		for _, method := range grpcWebAdminMethods {
+			grpcWeb.Post(method, d.RateLimit.StrictRateLimit(), d.Auth.RequireAuth(), d.RequireAdmin, grpcWebHandler)
+		}
+		grpcWeb.Post("/*", grpcWebHandler)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !nowebhooks
+
+package server
+
+func init() {
+	RegisterModule(routeModule{"webhooks", registerWebhookRoutes})
+}
+
+// registerWebhookRoutes registers the webhook routes, left out of builds
+// tagged nowebhooks
+func registerWebhookRoutes(r *Router, d *Deps) {
+	webhookHandler := d.Handlers.Webhook
+	webhooks := r.API.Group("/webhooks", d.Auth.RequireAuth(), d.RequireAdmin)
+	webhooks.Post("/", d.RateLimit.StrictRateLimit(), webhookHandler.CreateWebhook)
+	webhooks.Get("/", webhookHandler.GetAllWebhooks)
+	webhooks.Get("/:id", webhookHandler.GetWebhook)
+	webhooks.Put("/:id", d.RateLimit.StrictRateLimit(), webhookHandler.UpdateWebhook)
+	webhooks.Delete("/:id", d.RateLimit.StrictRateLimit(), webhookHandler.DeleteWebhook)
+	webhooks.Get("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	webhooks.Get("/:id/deliveries/:deliveryId", webhookHandler.GetWebhookDelivery)
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"bookstore-api/internal/access"
+	"bookstore-api/internal/database"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestOwnedAuthors(t *testing.T) {
+	access.Register("authors", access.OwnedBy("user_id", "publisher"))
+
+	ctx := tenancy.WithTenantID(context.Background(), models.DefaultTenantID)
+	db := database.GetDB().WithContext(ctx)
+	suffix := uuid.NewString()[:8]
+	user, err := services.NewUserService(db).CreateUser("publisher-"+suffix+"@example.com", "Publisher", models.RoleCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors := services.NewAuthorService(db)
+	owned := &models.Author{Name: "Owned Author", Email: "owned-" + suffix + "@example.com", UserID: &user.ID}
+	other := &models.Author{Name: "Other Author", Email: "other-" + suffix + "@example.com"}
+	for _, author := range []*models.Author{owned, other} {
+		if err := authors.CreateAuthor(author); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publisher := access.WithPrincipal(ctx, &access.Principal{UserID: user.ID, Role: "publisher"})
+	admin := access.WithPrincipal(ctx, &access.Principal{UserID: uuid.New(), Role: models.RoleAdmin})
+
+	t.Run("list", func(t *testing.T) {
+		list, total, err := authors.WithContext(publisher).GetAllAuthors(1, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].ID != owned.ID || total != 1 {
+			t.Fatalf("publisher sees %d authors of %d, expected only %s", len(list), total, owned.ID)
+		}
+	})
+	t.Run("cached count", func(t *testing.T) {
+		cache := services.NewCountCache(services.CountModeCached, time.Minute)
+		count := func(ctx context.Context) int64 {
+			t.Helper()
+			total, err := cache.Count("authors", db.WithContext(ctx).Model(&models.Author{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return total
+		}
+		if total := count(publisher); total != 1 {
+			t.Fatalf("publisher counts %d authors, expected 1", total)
+		}
+		if total := count(admin); total < 2 {
+			t.Fatalf("admin counts %d authors, expected the publisher's count not to be reused", total)
+		}
+	})
+	t.Run("hidden email", func(t *testing.T) {
+		err := authors.WithContext(publisher).CreateAuthor(&models.Author{Name: "Copy Author", Email: other.Email})
+		if err == nil || !strings.Contains(err.Error(), "email") {
+			t.Fatalf("creating an author with a hidden author's email returned %v, expected a duplicate email error", err)
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxSavedSearches caps the number of searches a user may save
+	maxSavedSearches = 50
+	// maxDueAlerts caps the number of saved searches or stock alerts checked
+	// per scan; the rest are picked up by the next scan
+	maxDueAlerts = 500
+	// MaxAlertMatches caps the number of new books reported for one saved search
+	MaxAlertMatches = 20
+)
+
+// AlertService manages users' saved searches and back-in-stock alerts
+type AlertService struct {
+	db *gorm.DB
+}
+
+// NewAlertService creates a new alert service
+func NewAlertService(db *gorm.DB) *AlertService {
+	return &AlertService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *AlertService) WithContext(ctx context.Context) *AlertService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreateSavedSearch saves a book search for the user. Only books added from
+// now on are reported as matches.
+func (s *AlertService) CreateSavedSearch(userID uuid.UUID, name, query string) (*models.SavedSearch, error) {
+	query = strings.TrimSpace(query)
+	name = strings.TrimSpace(name)
+	switch {
+	case query == "":
+		return nil, fmt.Errorf("invalid saved search: query is required")
+	case utf8.RuneCountInString(query) > 255:
+		return nil, fmt.Errorf("invalid saved search: query must be at most 255 characters")
+	case utf8.RuneCountInString(name) > 100:
+		return nil, fmt.Errorf("invalid saved search: name must be at most 100 characters")
+	}
+	if name == "" {
+		name = truncate(query, 100)
+	}
+
+	var count int64
+	if err := s.db.Model(&models.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	if count >= maxSavedSearches {
+		return nil, fmt.Errorf("invalid saved search: at most %d searches may be saved", maxSavedSearches)
+	}
+
+	search := &models.SavedSearch{
+		UserID:        userID,
+		Name:          name,
+		Query:         query,
+		LastCheckedAt: time.Now(),
+	}
+	if err := s.db.Create(search).Error; err != nil {
+		return nil, fmt.Errorf("failed to save search: %w", err)
+	}
+	return search, nil
+}
+
+// GetSavedSearches lists the user's saved searches, newest first
+func (s *AlertService) GetSavedSearches(userID uuid.UUID) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&searches).Error; err != nil {
+		return nil, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch deletes one of the user's saved searches
+func (s *AlertService) DeleteSavedSearch(id, userID uuid.UUID) error {
+	result := s.db.Where("user_id = ?", userID).Delete(&models.SavedSearch{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete saved search: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("saved search not found")
+	}
+	return nil
+}
+
+// CreateStockAlert subscribes the user to the book coming back in stock. A
+// book in stock cannot be subscribed to. Subscribing again after being
+// notified arms the alert again.
+func (s *AlertService) CreateStockAlert(bookID, userID uuid.UUID) (*models.StockAlert, error) {
+	var book models.Book
+	if err := s.db.Select("id", "stock").First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+	if book.Stock > 0 {
+		return nil, fmt.Errorf("book is in stock")
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"notified_at": nil, "updated_at": time.Now()}),
+	}).Create(&models.StockAlert{UserID: userID, BookID: bookID}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stock alert: %w", err)
+	}
+
+	// An existing alert keeps its ID, so read back the stored row
+	var alert models.StockAlert
+	if err := s.db.First(&alert, "user_id = ? AND book_id = ?", userID, bookID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stock alert: %w", err)
+	}
+	return &alert, nil
+}
+
+// GetStockAlerts lists the user's stock alerts with their books, newest first
+func (s *AlertService) GetStockAlerts(userID uuid.UUID) ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	if err := s.db.Preload("Book").Where("user_id = ?", userID).Order("created_at DESC").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stock alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// DeleteStockAlert unsubscribes the user from the book coming back in stock
+func (s *AlertService) DeleteStockAlert(bookID, userID uuid.UUID) error {
+	result := s.db.Where("user_id = ?", userID).Delete(&models.StockAlert{}, "book_id = ?", bookID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete stock alert: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("stock alert not found")
+	}
+	return nil
+}
+
+// NotifyStockAlerts calls notify, in the transaction that marks them notified,
+// for the pending alerts whose books are back in stock. Alerts locked by a
+// concurrent scan are skipped, so each is notified once.
+func (s *AlertService) NotifyStockAlerts(now time.Time, notify func(tx *gorm.DB, alert *models.StockAlert) error) (int, error) {
+	notified := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var alerts []models.StockAlert
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("notified_at IS NULL AND book_id IN (?)", tx.Model(&models.Book{}).Select("id").Where("stock > 0")).
+			Order("created_at ASC").
+			Limit(maxDueAlerts).
+			Find(&alerts).Error; err != nil {
+			return fmt.Errorf("failed to get due stock alerts: %w", err)
+		}
+		if len(alerts) == 0 {
+			return nil
+		}
+
+		bookIDs := make([]uuid.UUID, len(alerts))
+		for i := range alerts {
+			bookIDs[i] = alerts[i].BookID
+		}
+		var books []models.Book
+		if err := tx.Where("id IN ?", bookIDs).Find(&books).Error; err != nil {
+			return fmt.Errorf("failed to get books: %w", err)
+		}
+		byID := make(map[uuid.UUID]models.Book, len(books))
+		for _, book := range books {
+			byID[book.ID] = book
+		}
+
+		ids := make([]uuid.UUID, len(alerts))
+		for i := range alerts {
+			alert := &alerts[i]
+			alert.Book = byID[alert.BookID]
+			if err := notify(tx, alert); err != nil {
+				return err
+			}
+			ids[i] = alert.ID
+		}
+		if err := tx.Model(&models.StockAlert{}).Where("id IN ?", ids).Update("notified_at", now).Error; err != nil {
+			return fmt.Errorf("failed to mark stock alerts notified: %w", err)
+		}
+		notified = len(alerts)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return notified, nil
+}
+
+// NotifySavedSearches calls notify, in the transaction that advances their
+// last check to now, for the saved searches matched by books added since they
+// were last checked, with up to MaxAlertMatches of those books, oldest first.
+// Searches without new matches are advanced too.
+func (s *AlertService) NotifySavedSearches(now time.Time, notify func(tx *gorm.DB, search *models.SavedSearch, books []models.Book) error) (int, error) {
+	notified := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var searches []models.SavedSearch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("last_checked_at < ?", now).
+			Order("last_checked_at ASC").
+			Limit(maxDueAlerts).
+			Find(&searches).Error; err != nil {
+			return fmt.Errorf("failed to get due saved searches: %w", err)
+		}
+
+		ids := make([]uuid.UUID, len(searches))
+		for i := range searches {
+			search := &searches[i]
+			ids[i] = search.ID
+			pattern := newSearchQuery(search.Query).Pattern()
+			var books []models.Book
+			if err := tx.Where("created_at > ? AND created_at <= ?", search.LastCheckedAt, now).
+				Where("title ILIKE ? OR isbn ILIKE ? OR description ILIKE ?", pattern, pattern, pattern).
+				Order("created_at ASC").
+				Limit(MaxAlertMatches).
+				Find(&books).Error; err != nil {
+				return fmt.Errorf("failed to match saved search: %w", err)
+			}
+			if len(books) == 0 {
+				continue
+			}
+			if err := notify(tx, search, books); err != nil {
+				return err
+			}
+			notified++
+		}
+		if len(ids) > 0 {
+			if err := tx.Model(&models.SavedSearch{}).Where("id IN ?", ids).Update("last_checked_at", now).Error; err != nil {
+				return fmt.Errorf("failed to advance saved searches: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return notified, nil
+}
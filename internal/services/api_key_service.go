@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiKeyTouchInterval limits how often a key's last use is written
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService handles API keys for scripts and operations tools
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *APIKeyService) WithContext(ctx context.Context) *APIKeyService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreateAPIKey creates an API key acting with role and returns it with the
+// key itself, which is not stored and cannot be retrieved again
+func (s *APIKeyService) CreateAPIKey(name, role string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := &models.APIKey{
+		Name:      name,
+		Role:      role,
+		KeyHash:   hash,
+		Hint:      key[len(key)-4:],
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.Create(apiKey).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+	return apiKey, key, nil
+}
+
+// GetAllAPIKeys retrieves API keys, newest first, with pagination
+func (s *APIKeyService) GetAllAPIKeys(page, limit int) ([]models.APIKey, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.APIKey{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+
+	var apiKeys []models.APIKey
+	offset := (page - 1) * limit
+	if err := s.db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&apiKeys).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get api keys: %w", err)
+	}
+	return apiKeys, total, nil
+}
+
+// RotateAPIKey replaces an API key with a new one, which is returned. The
+// previous key stops working at once.
+func (s *APIKeyService) RotateAPIKey(id uuid.UUID) (*models.APIKey, string, error) {
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var apiKey models.APIKey
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&apiKey, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("api key not found")
+			}
+			return fmt.Errorf("failed to get api key: %w", err)
+		}
+		if apiKey.RevokedAt != nil {
+			return fmt.Errorf("api key is revoked")
+		}
+
+		now := time.Now()
+		apiKey.KeyHash = hash
+		apiKey.Hint = key[len(key)-4:]
+		apiKey.RotatedAt = &now
+		if err := tx.Model(&apiKey).Select("key_hash", "hint", "rotated_at").Updates(&apiKey).Error; err != nil {
+			return fmt.Errorf("failed to rotate api key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &apiKey, key, nil
+}
+
+// RevokeAPIKey permanently disables an API key
+func (s *APIKeyService) RevokeAPIKey(id uuid.UUID) error {
+	result := s.db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("api key not found")
+	}
+	return nil
+}
+
+// Authenticate returns the API key matching key, or "invalid api key" if there
+// is none or it is revoked or expired
+func (s *APIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := s.db.First(&apiKey, "key_hash = ?", auth.HashAPIKey(key)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("invalid api key")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)) {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.db.Model(&apiKey).UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to update api key: %w", err)
+		}
+	}
+	return &apiKey, nil
+}
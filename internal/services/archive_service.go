@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/storage"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// archiveSource is a table whose old rows are archived, and the timestamp
+// column the retention applies to
+type archiveSource struct {
+	table  string
+	column string
+}
+
+// archiveSources are the tables archived, in order
+var archiveSources = []archiveSource{
+	{table: "security_events", column: "created_at"},
+	{table: "book_views", column: "viewed_at"},
+}
+
+// ArchiveService moves old log rows out of the database into gzipped JSON
+// Lines archives in cold storage and lists the archives written
+type ArchiveService struct {
+	db        *gorm.DB
+	ctx       context.Context
+	store     storage.Storage
+	batchSize int
+}
+
+// NewArchiveService creates a new archive service writing archives of up to
+// batchSize rows to store
+func NewArchiveService(db *gorm.DB, store storage.Storage, batchSize int) *ArchiveService {
+	if batchSize <= 0 {
+		batchSize = 10000
+	}
+	return &ArchiveService{
+		db:        db,
+		ctx:       context.Background(),
+		store:     store,
+		batchSize: batchSize,
+	}
+}
+
+// WithContext returns a copy of the service whose queries and storage calls
+// run with ctx
+func (s *ArchiveService) WithContext(ctx context.Context) *ArchiveService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// Archive moves the rows of every archived table, in every tenant, older than
+// before into archives and returns their manifests. Each archive is written
+// in the transaction deleting its rows, so rows are only removed once their
+// archive is stored.
+func (s *ArchiveService) Archive(before time.Time) ([]models.ArchiveManifest, error) {
+	manifests := []models.ArchiveManifest{}
+	for _, source := range archiveSources {
+		for {
+			manifest, err := s.archiveBatch(source, before)
+			if err != nil {
+				return manifests, err
+			}
+			if manifest == nil {
+				break
+			}
+			manifests = append(manifests, *manifest)
+		}
+	}
+	return manifests, nil
+}
+
+// GetManifests retrieves archive manifests, newest rows first, optionally
+// filtered by source table, with pagination
+func (s *ArchiveService) GetManifests(table string, page, limit int) ([]models.ArchiveManifest, int64, error) {
+	query := s.db.Model(&models.ArchiveManifest{})
+	if table != "" {
+		query = query.Where("source_table = ?", table)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count archives: %w", err)
+	}
+
+	var manifests []models.ArchiveManifest
+	offset := (page - 1) * limit
+	if err := query.Order("newest_at DESC, id").Offset(offset).Limit(limit).Find(&manifests).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get archives: %w", err)
+	}
+
+	return manifests, total, nil
+}
+
+// GetManifest retrieves an archive manifest by ID
+func (s *ArchiveService) GetManifest(id uuid.UUID) (*models.ArchiveManifest, error) {
+	var manifest models.ArchiveManifest
+	if err := s.db.First(&manifest, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("archive not found")
+		}
+		return nil, fmt.Errorf("failed to get archive: %w", err)
+	}
+	return &manifest, nil
+}
+
+// archivedRow is a deleted row as JSON and its timestamp
+type archivedRow struct {
+	Data string
+	At   time.Time
+}
+
+// archiveBatch archives up to batchSize of the oldest rows of source older
+// than before, returning nil when there are none left
+func (s *ArchiveService) archiveBatch(source archiveSource, before time.Time) (*models.ArchiveManifest, error) {
+	var manifest *models.ArchiveManifest
+	stored := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Deleting and returning the rows in one statement makes the archive
+		// hold exactly the rows removed
+		var rows []archivedRow
+		query := fmt.Sprintf(`WITH batch AS (
+	SELECT id, %[2]s FROM %[1]s WHERE %[2]s < ? ORDER BY %[2]s LIMIT ?
+)
+DELETE FROM %[1]s AS archived USING batch
+WHERE archived.id = batch.id AND archived.%[2]s = batch.%[2]s
+RETURNING to_jsonb(archived)::text AS data, archived.%[2]s AS at`, source.table, source.column)
+		if err := tx.Raw(query, before, s.batchSize).Scan(&rows).Error; err != nil {
+			return fmt.Errorf("failed to archive %s: %w", source.table, err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i].At.Before(rows[j].At) })
+
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		for _, row := range rows {
+			if _, err := writer.Write([]byte(row.Data + "\n")); err != nil {
+				return fmt.Errorf("failed to write archive: %w", err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		sum := sha256.Sum256(buf.Bytes())
+
+		oldest := rows[0].At.UTC()
+		manifest = &models.ArchiveManifest{
+			ID:          ids.New(),
+			SourceTable: source.table,
+			Rows:        int64(len(rows)),
+			OldestAt:    oldest,
+			NewestAt:    rows[len(rows)-1].At.UTC(),
+			SizeBytes:   int64(buf.Len()),
+			Checksum:    hex.EncodeToString(sum[:]),
+		}
+		manifest.Key = fmt.Sprintf("%s/%s/%s.jsonl.gz", source.table, oldest.Format("2006-01"), manifest.ID)
+
+		if err := s.store.Put(s.ctx, manifest.Key, "application/gzip", buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to store archive: %w", err)
+		}
+		stored = true
+
+		if err := tx.Create(manifest).Error; err != nil {
+			return fmt.Errorf("failed to record archive: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// The rows were not deleted, so the stored archive is discarded
+		if stored {
+			s.store.Delete(s.ctx, manifest.Key)
+		}
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/slug"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultDuplicateThreshold is the minimum name similarity for authors to be reported as duplicates
+const DefaultDuplicateThreshold = 0.85
+
+// placeholderEmailDomain marks generated author emails, e.g. from ONIX imports
+const placeholderEmailDomain = "@import.invalid"
+
+// DuplicateAuthor is an author in a duplicate group
+type DuplicateAuthor struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	BookCount int64     `json:"book_count"`
+}
+
+// DuplicateAuthorGroup is a set of authors whose names are likely the same person
+type DuplicateAuthorGroup struct {
+	Similarity float64           `json:"similarity"`
+	Authors    []DuplicateAuthor `json:"authors"`
+}
+
+// FindDuplicateAuthors groups authors whose normalized names are at least
+// threshold similar. Names are normalized so that punctuation, case, and spacing
+// between initials do not matter ("J. K. Rowling" and "J.K. Rowling" are equal),
+// then compared by edit distance within groups sharing a surname prefix.
+func (s *AuthorService) FindDuplicateAuthors(threshold float64) ([]DuplicateAuthorGroup, error) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultDuplicateThreshold
+	}
+
+	var authors []DuplicateAuthor
+	if err := s.db.Model(&models.Author{}).
+		Select("authors.id, authors.name, authors.email, COUNT(books.id) AS book_count").
+		Joins("LEFT JOIN books ON books.author_id = authors.id AND books.deleted_at IS NULL").
+		Group("authors.id").
+		Order("authors.created_at ASC").
+		Scan(&authors).Error; err != nil {
+		return nil, fmt.Errorf("failed to get authors: %w", err)
+	}
+
+	normalized := make([]string, len(authors))
+	blocks := make(map[string][]int)
+	for i, author := range authors {
+		normalized[i] = normalizeAuthorName(author.Name)
+		key := surnameKey(normalized[i])
+		blocks[key] = append(blocks[key], i)
+	}
+
+	// Union-find over authors, linking each similar pair
+	parent := make([]int, len(authors))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, members := range blocks {
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				i, j := members[a], members[b]
+				if nameSimilarity(normalized[i], normalized[j]) < threshold {
+					continue
+				}
+				if ri, rj := find(i), find(j); ri != rj {
+					parent[rj] = ri
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range authors {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	result := []DuplicateAuthorGroup{}
+	for _, root := range roots {
+		indexes := members[root]
+		if len(indexes) < 2 {
+			continue
+		}
+		// Report the weakest pairwise similarity, since groups can form through chains
+		group := &DuplicateAuthorGroup{Similarity: 1}
+		for a, i := range indexes {
+			group.Authors = append(group.Authors, authors[i])
+			for _, j := range indexes[a+1:] {
+				if similarity := nameSimilarity(normalized[i], normalized[j]); similarity < group.Similarity {
+					group.Similarity = similarity
+				}
+			}
+		}
+		// Authors with the most books first, as they are the likely merge targets
+		sort.SliceStable(group.Authors, func(a, b int) bool {
+			return group.Authors[a].BookCount > group.Authors[b].BookCount
+		})
+		result = append(result, *group)
+	}
+	sort.SliceStable(result, func(a, b int) bool {
+		return result[a].Similarity > result[b].Similarity
+	})
+	return result, nil
+}
+
+// MergeAuthors merges the duplicate author into the target: the duplicate's books
+// are reassigned, missing biography and contact details are copied over, and the
+// duplicate is soft deleted, all in one transaction.
+func (s *AuthorService) MergeAuthors(targetID, duplicateID uuid.UUID) (*models.Author, error) {
+	if targetID == duplicateID {
+		return nil, fmt.Errorf("cannot merge an author into itself")
+	}
+
+	var target models.Author
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var authors []models.Author
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{targetID, duplicateID}).
+			Find(&authors).Error; err != nil {
+			return fmt.Errorf("failed to get authors: %w", err)
+		}
+		var duplicate models.Author
+		for _, author := range authors {
+			if author.ID == targetID {
+				target = author
+			} else {
+				duplicate = author
+			}
+		}
+		if target.ID == uuid.Nil {
+			return fmt.Errorf("author not found")
+		}
+		if duplicate.ID == uuid.Nil {
+			return fmt.Errorf("duplicate author not found")
+		}
+		if target.UserID != nil && duplicate.UserID != nil {
+			return fmt.Errorf("both authors are claimed by users")
+		}
+
+		// Reassign every book, including soft-deleted ones, so none is left pointing at the duplicate
+		var bookIDs []uuid.UUID
+		if err := tx.Unscoped().Model(&models.Book{}).Where("author_id = ?", duplicate.ID).Pluck("id", &bookIDs).Error; err != nil {
+			return fmt.Errorf("failed to get books: %w", err)
+		}
+		if len(bookIDs) > 0 {
+			if err := tx.Unscoped().Model(&models.Book{}).Where("id IN ?", bookIDs).Update("author_id", target.ID).Error; err != nil {
+				return fmt.Errorf("failed to reassign books: %w", err)
+			}
+		}
+
+		updates := map[string]interface{}{}
+		if strings.TrimSpace(target.Biography) == "" && strings.TrimSpace(duplicate.Biography) != "" {
+			updates["biography"] = duplicate.Biography
+		}
+		if strings.HasSuffix(target.Email, placeholderEmailDomain) && !strings.HasSuffix(duplicate.Email, placeholderEmailDomain) {
+			// Emails are unique even among soft-deleted authors, so release the duplicate's first
+			if err := tx.Model(&models.Author{}).Where("id = ?", duplicate.ID).
+				Update("email", fmt.Sprintf("merged-%s%s", duplicate.ID, placeholderEmailDomain)).Error; err != nil {
+				return fmt.Errorf("failed to release duplicate email: %w", err)
+			}
+			updates["email"] = duplicate.Email
+		}
+		if target.UserID == nil && duplicate.UserID != nil {
+			// The owner follows their profile; release it first as user_id is unique
+			if err := tx.Model(&models.Author{}).Where("id = ?", duplicate.ID).Update("user_id", nil).Error; err != nil {
+				return fmt.Errorf("failed to release duplicate owner: %w", err)
+			}
+			updates["user_id"] = *duplicate.UserID
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Author{}).Where("id = ?", target.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update author: %w", err)
+			}
+		}
+
+		if err := tx.Delete(&models.Author{}, "id = ?", duplicate.ID).Error; err != nil {
+			return fmt.Errorf("failed to delete duplicate author: %w", err)
+		}
+		if err := slug.Forward(tx, "authors", target.TenantID, duplicate.ID, target.ID, duplicate.Slug); err != nil {
+			return err
+		}
+
+		if err := tx.First(&target, "id = ?", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to get author: %w", err)
+		}
+		if err := events.Record(tx, events.AuthorMerged, events.AggregateAuthor, target.ID, events.AuthorMergedPayload{
+			AuthorID:    target.ID,
+			DuplicateID: duplicate.ID,
+			BookIDs:     bookIDs,
+		}); err != nil {
+			return err
+		}
+		if len(updates) > 0 {
+			if err := events.Record(tx, events.AuthorUpdated, events.AggregateAuthor, target.ID, &target); err != nil {
+				return err
+			}
+		}
+		return events.Record(tx, events.AuthorDeleted, events.AggregateAuthor, duplicate.ID, events.DeletedPayload{ID: duplicate.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.counts.Invalidate("authors")
+	s.counts.Invalidate("books")
+	return &target, nil
+}
+
+// normalizeAuthorName lower-cases a name, drops punctuation, and joins runs of
+// initials so "J. K. Rowling" and "J.K. Rowling" both become "jk rowling".
+// Inverted names such as "Rowling, J.K." are put in natural order first.
+func normalizeAuthorName(name string) string {
+	if last, first, ok := strings.Cut(name, ","); ok && strings.TrimSpace(first) != "" {
+		name = first + " " + last
+	}
+
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	initials := ""
+	for _, field := range fields {
+		if len([]rune(field)) == 1 {
+			initials += field
+			continue
+		}
+		if initials != "" {
+			tokens = append(tokens, initials)
+			initials = ""
+		}
+		tokens = append(tokens, field)
+	}
+	if initials != "" {
+		tokens = append(tokens, initials)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// surnameKey buckets a normalized name by the start of its last word, so only
+// plausible pairs are compared
+func surnameKey(normalized string) string {
+	words := strings.Fields(normalized)
+	if len(words) == 0 {
+		return ""
+	}
+	last := []rune(words[len(words)-1])
+	if len(last) > 3 {
+		last = last[:3]
+	}
+	return string(last)
+}
+
+// nameSimilarity returns 1 minus the edit distance relative to the longer name
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
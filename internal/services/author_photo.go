@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/media"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/storage"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthorPhotoService stores author photos
+type AuthorPhotoService struct {
+	db    *gorm.DB
+	ctx   context.Context
+	store storage.Storage
+}
+
+// NewAuthorPhotoService creates a new author photo service storing photos in store
+func NewAuthorPhotoService(db *gorm.DB, store storage.Storage) *AuthorPhotoService {
+	return &AuthorPhotoService{
+		db:    db,
+		ctx:   context.Background(),
+		store: store,
+	}
+}
+
+// WithContext returns a copy of the service whose queries and storage calls
+// run with ctx, which carries the tenant they are scoped to
+func (s *AuthorPhotoService) WithContext(ctx context.Context) *AuthorPhotoService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// SetAuthorPhoto stores data, a JPEG, PNG, or GIF image, as the author's photo
+// in every media.PhotoSizes size, replacing any previous photo
+func (s *AuthorPhotoService) SetAuthorPhoto(id uuid.UUID, data []byte) (*models.Author, error) {
+	img, err := media.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid photo: %w", err)
+	}
+	renditions, err := media.Render(img, media.PhotoSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	var author models.Author
+	if err := s.db.First(&author, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("author not found")
+		}
+		return nil, fmt.Errorf("failed to get author: %w", err)
+	}
+
+	// Every upload gets its own key prefix, so cached copies of the previous
+	// photo are never served for the new one
+	key := fmt.Sprintf("authors/%s/%d", id, time.Now().UnixNano())
+	photos := models.StringMap{}
+	for _, size := range media.PhotoSizes {
+		if err := s.store.Put(s.ctx, photoKey(key, size), "image/jpeg", renditions[size.Name]); err != nil {
+			s.deletePhoto(key)
+			return nil, err
+		}
+		photos[size.Name] = s.store.URL(photoKey(key, size))
+	}
+
+	previousKey := author.PhotoKey
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return s.updatePhoto(tx, &author, photos, key)
+	})
+	if err != nil {
+		s.deletePhoto(key)
+		return nil, err
+	}
+	s.deletePhoto(previousKey)
+	return &author, nil
+}
+
+// DeleteAuthorPhoto removes the author's photo
+func (s *AuthorPhotoService) DeleteAuthorPhoto(id uuid.UUID) error {
+	var author models.Author
+	if err := s.db.First(&author, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("author not found")
+		}
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+	if author.PhotoKey == "" {
+		return fmt.Errorf("author has no photo")
+	}
+
+	previousKey := author.PhotoKey
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.updatePhoto(tx, &author, models.StringMap{}, "")
+	})
+	if err != nil {
+		return err
+	}
+	s.deletePhoto(previousKey)
+	return nil
+}
+
+// updatePhoto points the author at the photo stored under key and records the change
+func (s *AuthorPhotoService) updatePhoto(tx *gorm.DB, author *models.Author, photos models.StringMap, key string) error {
+	if err := tx.Model(author).Updates(map[string]interface{}{"photos": photos, "photo_key": key}).Error; err != nil {
+		return fmt.Errorf("failed to update author photo: %w", err)
+	}
+	author.Photos = photos
+	author.PhotoKey = key
+	return events.Record(tx, events.AuthorUpdated, events.AggregateAuthor, author.ID, author)
+}
+
+// deletePhoto removes every size of the photo stored under key. Failures are
+// only logged: the photo is no longer referenced, so they leave orphaned files
+// rather than broken links.
+func (s *AuthorPhotoService) deletePhoto(key string) {
+	if key == "" {
+		return
+	}
+	for _, size := range media.PhotoSizes {
+		if err := s.store.Delete(s.ctx, photoKey(key, size)); err != nil {
+			log.Printf("Failed to delete author photo %s: %v", photoKey(key, size), err)
+		}
+	}
+}
+
+// photoKey returns the storage key of one size of the photo stored under key
+func photoKey(key string, size media.Size) string {
+	return key + "/" + size.Name + ".jpg"
+}
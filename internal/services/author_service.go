@@ -1,31 +1,66 @@
 package services
 
 import (
-	"bookstore-api/internal/database"
+	"bookstore-api/internal/events"
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/slug"
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// DefaultSearchSimilarity is the minimum trigram word similarity for author
+// searches to match a name the query does not appear in
+const DefaultSearchSimilarity = 0.4
+
 // AuthorService handles author-related business logic
 type AuthorService struct {
-	db *gorm.DB
+	db         *gorm.DB
+	counts     *CountCache
+	similarity float64
 }
 
 // NewAuthorService creates a new author service
-func NewAuthorService() *AuthorService {
+func NewAuthorService(db *gorm.DB) *AuthorService {
 	return &AuthorService{
-		db: database.GetDB(),
+		db:         db,
+		counts:     GetCountCache(),
+		similarity: DefaultSearchSimilarity,
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *AuthorService) WithContext(ctx context.Context) *AuthorService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
 // CreateAuthor creates a new author
 func (s *AuthorService) CreateAuthor(author *models.Author) error {
-	if err := s.db.Create(author).Error; err != nil {
-		return fmt.Errorf("failed to create author: %w", err)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkEmailAvailable(tx, author.Email, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Create(author).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("author with this email already exists")
+			}
+			return fmt.Errorf("failed to create author: %w", err)
+		}
+		return events.Record(tx, events.AuthorCreated, events.AggregateAuthor, author.ID, author)
+	})
+	if err != nil {
+		return err
 	}
+	s.counts.Invalidate("authors")
 	return nil
 }
 
@@ -38,16 +73,32 @@ func (s *AuthorService) GetAuthorByID(id uuid.UUID) (*models.Author, error) {
 		}
 		return nil, fmt.Errorf("failed to get author: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(author.Books)); err != nil {
+		return nil, err
+	}
 	return &author, nil
 }
 
+// GetAuthorBySlug retrieves an author by its current slug or one it had before
+// being renamed
+func (s *AuthorService) GetAuthorBySlug(value string) (*models.Author, error) {
+	id, err := slugID(s.db, &models.Author{}, "authors", value)
+	if err != nil {
+		return nil, err
+	}
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("author not found")
+	}
+	return s.GetAuthorByID(id)
+}
+
 // GetAllAuthors retrieves all authors with pagination
 func (s *AuthorService) GetAllAuthors(page, limit int) ([]models.Author, int64, error) {
 	var authors []models.Author
-	var total int64
 
 	// Count total records
-	if err := s.db.Model(&models.Author{}).Count(&total).Error; err != nil {
+	total, err := s.counts.CountTable(s.db, "authors", &models.Author{})
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count authors: %w", err)
 	}
 
@@ -55,34 +106,167 @@ func (s *AuthorService) GetAllAuthors(page, limit int) ([]models.Author, int64,
 	offset := (page - 1) * limit
 
 	// Get authors with pagination
-	if err := s.db.Preload("Books").Offset(offset).Limit(limit).Find(&authors).Error; err != nil {
+	if err := s.db.Offset(offset).Limit(limit).Find(&authors).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get authors: %w", err)
 	}
+	if err := setAuthorBooksCounts(s.db, authors); err != nil {
+		return nil, 0, err
+	}
 
 	return authors, total, nil
 }
 
 // UpdateAuthor updates an existing author
 func (s *AuthorService) UpdateAuthor(id uuid.UUID, updates *models.Author) error {
-	result := s.db.Model(&models.Author{}).Where("id = ?", id).Updates(updates)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update author: %w", result.Error)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if updates.Email != "" {
+			if err := checkEmailAvailable(tx, updates.Email, id); err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&models.Author{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("author with this email already exists")
+			}
+			return fmt.Errorf("failed to update author: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("author not found")
+		}
+
+		var author models.Author
+		if err := tx.First(&author, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to get author: %w", err)
+		}
+		authorSlug, err := slug.Rename(tx, "authors", author.TenantID, author.ID, author.Slug, author.Name)
+		if err != nil {
+			return err
+		}
+		author.Slug = authorSlug
+		return events.Record(tx, events.AuthorUpdated, events.AggregateAuthor, id, &author)
+	})
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("author not found")
+	s.counts.Invalidate("authors")
+	return nil
+}
+
+// checkEmailAvailable ensures no other author, including soft-deleted ones, uses the email
+func checkEmailAvailable(tx *gorm.DB, email string, excludeID uuid.UUID) error {
+	var count int64
+	if err := unrestricted(tx).Unscoped().Model(&models.Author{}).Where("email = ? AND id <> ?", email, excludeID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("author with this email already exists")
 	}
 	return nil
 }
 
+// IsAuthorOwner reports whether the user has claimed the author
+func (s *AuthorService) IsAuthorOwner(authorID, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.Author{}).Where("id = ? AND user_id = ?", authorID, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check author owner: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ClaimAuthor links an unclaimed author to the user. The user's verified email
+// must match the author's email.
+func (s *AuthorService) ClaimAuthor(authorID, userID uuid.UUID) (*models.Author, error) {
+	var author models.Author
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&author, "id = ?", authorID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("author not found")
+			}
+			return fmt.Errorf("failed to get author: %w", err)
+		}
+		if author.UserID != nil {
+			if *author.UserID == userID {
+				return nil
+			}
+			return fmt.Errorf("author already claimed")
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("user not found")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		// User emails are only stored once an identity provider has verified them
+		if user.Email == nil || !strings.EqualFold(*user.Email, author.Email) {
+			return fmt.Errorf("email does not match author")
+		}
+
+		return s.setOwner(tx, &author, &userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// SetAuthorOwner assigns the author to a user, or unassigns it when userID is nil
+func (s *AuthorService) SetAuthorOwner(authorID uuid.UUID, userID *uuid.UUID) (*models.Author, error) {
+	var author models.Author
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&author, "id = ?", authorID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("author not found")
+			}
+			return fmt.Errorf("failed to get author: %w", err)
+		}
+		if userID != nil {
+			var count int64
+			if err := tx.Model(&models.User{}).Where("id = ?", *userID).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to get user: %w", err)
+			}
+			if count == 0 {
+				return fmt.Errorf("user not found")
+			}
+		}
+		return s.setOwner(tx, &author, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// setOwner updates the author's owning user and records the change
+func (s *AuthorService) setOwner(tx *gorm.DB, author *models.Author, userID *uuid.UUID) error {
+	author.UserID = userID
+	if err := tx.Model(author).Update("user_id", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("user already owns an author")
+		}
+		return fmt.Errorf("failed to update author owner: %w", err)
+	}
+	return events.Record(tx, events.AuthorUpdated, events.AggregateAuthor, author.ID, author)
+}
+
 // DeleteAuthor soft deletes an author
 func (s *AuthorService) DeleteAuthor(id uuid.UUID) error {
-	result := s.db.Delete(&models.Author{}, "id = ?", id)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete author: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("author not found")
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Author{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete author: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("author not found")
+		}
+		return events.Record(tx, events.AuthorDeleted, events.AggregateAuthor, id, events.DeletedPayload{ID: id})
+	})
+	if err != nil {
+		return err
 	}
+	s.counts.Invalidate("authors")
 	return nil
 }
 
@@ -95,18 +279,91 @@ func (s *AuthorService) GetAuthorByEmail(email string) (*models.Author, error) {
 		}
 		return nil, fmt.Errorf("failed to get author: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(author.Books)); err != nil {
+		return nil, err
+	}
 	return &author, nil
 }
 
-// SearchAuthors searches authors by name or email
-func (s *AuthorService) SearchAuthors(query string, page, limit int) ([]models.Author, int64, error) {
+// FindAuthorIDsByName matches authors by case-insensitive name, returning IDs
+// keyed by the lower-cased name. Names without a match are omitted.
+func (s *AuthorService) FindAuthorIDsByName(names []string) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID)
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+
+	var authors []models.Author
+	if err := s.db.Select("id", "name").Where("LOWER(name) IN ?", lowered).Order("created_at ASC").Find(&authors).Error; err != nil {
+		return nil, fmt.Errorf("failed to find authors: %w", err)
+	}
+	for _, author := range authors {
+		key := strings.ToLower(author.Name)
+		if _, ok := ids[key]; !ok {
+			ids[key] = author.ID
+		}
+	}
+	return ids, nil
+}
+
+// WithSimilarity returns a copy of the service whose author searches also
+// match names with a word at least similarity alike the query, from 0 to 1
+func (s *AuthorService) WithSimilarity(similarity float64) *AuthorService {
+	clone := *s
+	clone.similarity = similarity
+	return &clone
+}
+
+// SearchAuthors searches authors by name or email. Unless the query is
+// exact, names with a word similar to it by trigrams match too, so
+// misspellings still find the author, and the closest names come first.
+func (s *AuthorService) SearchAuthors(query SearchQuery, page, limit int) ([]models.Author, int64, error) {
+	if query.Exact {
+		return s.searchAuthors(s.db, query, s.db.Where("name ILIKE ? OR email ILIKE ?", query.Pattern(), query.Pattern()), nil, page, limit)
+	}
+
 	var authors []models.Author
 	var total int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// <% compares the query with the closest extent of the name at the
+		// threshold set for this transaction, and can use the trigram index
+		threshold := strconv.FormatFloat(s.similarity, 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return fmt.Errorf("failed to set search similarity: %w", err)
+		}
+		where := s.db.Where("name ILIKE ? OR email ILIKE ? OR ? <% name", query.Pattern(), query.Pattern(), query.Text)
+		rank := clause.OrderBy{Expression: clause.Expr{
+			SQL:                "word_similarity(?, name) DESC, name, id",
+			Vars:               []interface{}{query.Text},
+			WithoutParentheses: true,
+		}}
+		var err error
+		authors, total, err = s.searchAuthors(tx, query, where, rank, page, limit)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return authors, total, nil
+}
 
-	searchQuery := "%" + query + "%"
+// searchAuthors returns a page of the authors matching where, in order when
+// it is set
+func (s *AuthorService) searchAuthors(db *gorm.DB, query SearchQuery, where *gorm.DB, order clause.Expression, page, limit int) ([]models.Author, int64, error) {
+	var authors []models.Author
 
 	// Count total records
-	if err := s.db.Model(&models.Author{}).Where("name ILIKE ? OR email ILIKE ?", searchQuery, searchQuery).Count(&total).Error; err != nil {
+	key := "authors:search:" + query.String()
+	if !query.Exact {
+		key += ":" + strconv.FormatFloat(s.similarity, 'f', -1, 64)
+	}
+	total, err := s.counts.Count(key, db.Model(&models.Author{}).Where(where))
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count authors: %w", err)
 	}
 
@@ -114,9 +371,16 @@ func (s *AuthorService) SearchAuthors(query string, page, limit int) ([]models.A
 	offset := (page - 1) * limit
 
 	// Search authors with pagination
-	if err := s.db.Preload("Books").Where("name ILIKE ? OR email ILIKE ?", searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&authors).Error; err != nil {
+	find := db.Where(where)
+	if order != nil {
+		find = find.Order(order)
+	}
+	if err := find.Offset(offset).Limit(limit).Find(&authors).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to search authors: %w", err)
 	}
+	if err := setAuthorBooksCounts(db, authors); err != nil {
+		return nil, 0, err
+	}
 
 	return authors, total, nil
 }
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxCompareBooks caps the books compared at once
+const MaxCompareBooks = 5
+
+// BookComparison compares books side by side, in the order they were asked
+// for
+type BookComparison struct {
+	Books []ComparedBook `json:"books"`
+	Best  ComparisonBest `json:"best"`
+}
+
+// ComparedBook holds the compared attributes of a book. Every book has every
+// key, and attributes a book lacks are null, so comparison widgets can lay
+// the books out in columns.
+type ComparedBook struct {
+	ID             uuid.UUID `json:"id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	Author         string    `json:"author"`
+	Price          float64   `json:"price"`
+	EffectivePrice float64   `json:"effective_price"`
+	Format         *string   `json:"format"`
+	PageCount      *int      `json:"page_count"`
+	AverageRating  *float64  `json:"average_rating"`
+	RatingsCount   int64     `json:"ratings_count"`
+	Stock          int       `json:"stock"`
+	InStock        bool      `json:"in_stock"`
+	// Availability is where the stock is held, as on the book's own page
+	Availability *models.StockAvailability `json:"availability"`
+}
+
+// ComparisonBest names the compared books that stand out: the cheapest after
+// promotions, the best rated, and the longest. Each is null when no compared
+// book has the attribute, and ties go to the book asked for first.
+type ComparisonBest struct {
+	LowestPrice   *uuid.UUID `json:"lowest_price"`
+	HighestRating *uuid.UUID `json:"highest_rating"`
+	MostPages     *uuid.UUID `json:"most_pages"`
+}
+
+// CompareBooks compares the books with ids, which must be 2 to
+// MaxCompareBooks distinct books the service lists
+func (s *BookService) CompareBooks(ids []uuid.UUID) (*BookComparison, error) {
+	if len(ids) < 2 || len(ids) > MaxCompareBooks {
+		return nil, fmt.Errorf("invalid comparison: between 2 and %d books can be compared", MaxCompareBooks)
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, fmt.Errorf("invalid comparison: book %s is listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	var books []models.Book
+	if err := s.db.Scopes(s.listed).Preload("Author").Where("id IN ?", ids).Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+	if len(books) != len(ids) {
+		return nil, fmt.Errorf("book not found")
+	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, err
+	}
+	for i := range books {
+		if err := setAvailability(s.db, &books[i]); err != nil {
+			return nil, err
+		}
+	}
+	ratings, err := ratingSummaries(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.Book, len(books))
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+	}
+	compared := make([]ComparedBook, len(ids))
+	for i, id := range ids {
+		compared[i] = compareBook(byID[id], ratings[id])
+	}
+	return &BookComparison{Books: compared, Best: bestOf(compared)}, nil
+}
+
+// compareBook returns the compared attributes of book with its ratings and
+// the availability set by setAvailability
+func compareBook(book *models.Book, rating ratingSummary) ComparedBook {
+	compared := ComparedBook{
+		ID:             book.ID,
+		Title:          book.Title,
+		Slug:           book.Slug,
+		Author:         book.Author.Name,
+		Price:          book.Price,
+		EffectivePrice: book.Price,
+		PageCount:      book.PageCount,
+		RatingsCount:   rating.Count,
+		Stock:          book.Availability.Total,
+		InStock:        book.Availability.Total > 0,
+		Availability:   book.Availability,
+	}
+	if book.EffectivePrice != nil {
+		compared.EffectivePrice = *book.EffectivePrice
+	}
+	if book.Format != "" {
+		format := book.Format
+		compared.Format = &format
+	}
+	if rating.Count > 0 {
+		average := rating.Average
+		compared.AverageRating = &average
+	}
+	return compared
+}
+
+// bestOf returns the standouts among books
+func bestOf(books []ComparedBook) ComparisonBest {
+	var cheapest, bestRated, longest *ComparedBook
+	for i := range books {
+		book := &books[i]
+		if cheapest == nil || book.EffectivePrice < cheapest.EffectivePrice {
+			cheapest = book
+		}
+		if book.AverageRating != nil && (bestRated == nil || *book.AverageRating > *bestRated.AverageRating) {
+			bestRated = book
+		}
+		if book.PageCount != nil && (longest == nil || *book.PageCount > *longest.PageCount) {
+			longest = book
+		}
+	}
+
+	var best ComparisonBest
+	if cheapest != nil {
+		best.LowestPrice = &cheapest.ID
+	}
+	if bestRated != nil {
+		best.HighestRating = &bestRated.ID
+	}
+	if longest != nil {
+		best.MostPages = &longest.ID
+	}
+	return best
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookExportColumns lists the columns available for book exports, in default order
+var BookExportColumns = []string{
+	"id", "title", "isbn", "description", "price", "stock", "published_at",
+	"author_id", "author_name", "category_id", "category_name", "created_at", "updated_at",
+}
+
+// BookFilter holds the filters shared by book list and export endpoints
+type BookFilter struct {
+	AuthorID   uuid.UUID
+	CategoryID uuid.UUID
+	Query      string
+}
+
+// BookExportRow is a book flattened with its author and category names
+type BookExportRow struct {
+	ID           uuid.UUID
+	Title        string
+	ISBN         string
+	Description  string
+	Price        float64
+	Stock        int
+	PublishedAt  *models.DateOnly
+	AuthorID     uuid.UUID
+	AuthorName   string
+	CategoryID   uuid.UUID
+	CategoryName string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ParseExportColumns validates a comma-separated column list, defaulting to all columns
+func ParseExportColumns(value string) ([]string, error) {
+	if value == "" {
+		return BookExportColumns, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		valid := false
+		for _, allowed := range BookExportColumns {
+			if column == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return BookExportColumns, nil
+	}
+	return columns, nil
+}
+
+// Values returns the row formatted for the given columns
+func (r *BookExportRow) Values(columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "id":
+			values[i] = r.ID.String()
+		case "title":
+			values[i] = r.Title
+		case "isbn":
+			values[i] = r.ISBN
+		case "description":
+			values[i] = r.Description
+		case "price":
+			values[i] = strconv.FormatFloat(r.Price, 'f', 2, 64)
+		case "stock":
+			values[i] = strconv.Itoa(r.Stock)
+		case "published_at":
+			if r.PublishedAt != nil {
+				values[i] = r.PublishedAt.String()
+			}
+		case "author_id":
+			values[i] = r.AuthorID.String()
+		case "author_name":
+			values[i] = r.AuthorName
+		case "category_id":
+			values[i] = r.CategoryID.String()
+		case "category_name":
+			values[i] = r.CategoryName
+		case "created_at":
+			values[i] = r.CreatedAt.UTC().Format(time.RFC3339)
+		case "updated_at":
+			values[i] = r.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return values
+}
+
+// CSVValues returns the row formatted for the given columns, with values that
+// spreadsheets would evaluate as formulas prefixed so they stay text
+func (r *BookExportRow) CSVValues(columns []string) []string {
+	values := r.Values(columns)
+	for i := range values {
+		values[i] = escapeCSVFormula(values[i])
+	}
+	return values
+}
+
+// escapeCSVFormula prefixes values that spreadsheets would evaluate as formulas
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ExportBooks streams books matching the filter to fn one row at a time, so the
+// full result set is never held in memory
+func (s *BookService) ExportBooks(filter BookFilter, fn func(row *BookExportRow) error) error {
+	query := s.exportQuery(filter).
+		Select(`books.id, books.title, books.isbn, books.description, books.price, books.stock, books.published_at,
+			books.author_id, authors.name AS author_name, books.category_id, categories.name AS category_name,
+			books.created_at, books.updated_at`).
+		Joins("LEFT JOIN authors ON authors.id = books.author_id").
+		Joins("LEFT JOIN categories ON categories.id = books.category_id")
+
+	rows, err := query.Order("books.title ASC").Rows()
+	if err != nil {
+		return fmt.Errorf("failed to export books: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row BookExportRow
+		if err := s.db.ScanRows(rows, &row); err != nil {
+			return fmt.Errorf("failed to read book row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export books: %w", err)
+	}
+	return nil
+}
+
+// CountExportBooks counts the books ExportBooks would export for the filter
+func (s *BookService) CountExportBooks(filter BookFilter) (int64, error) {
+	var count int64
+	if err := s.exportQuery(filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count books: %w", err)
+	}
+	return count, nil
+}
+
+// exportQuery returns the query of the books matching an export filter
+func (s *BookService) exportQuery(filter BookFilter) *gorm.DB {
+	// Model rather than Table so the query is tenant-scoped
+	query := s.db.Model(&models.Book{}).Where("books.deleted_at IS NULL")
+	if filter.AuthorID != uuid.Nil {
+		query = query.Where("books.author_id = ?", filter.AuthorID)
+	}
+	if filter.CategoryID != uuid.Nil {
+		query = query.Where("books.category_id = ?", filter.CategoryID)
+	}
+	if filter.Query != "" {
+		searchQuery := newSearchQuery(filter.Query).Pattern()
+		query = query.Where("(books.title ILIKE ? OR books.isbn ILIKE ? OR books.description ILIKE ?)", searchQuery, searchQuery, searchQuery)
+	}
+	return query
+}
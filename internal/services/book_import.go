@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/utils"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Import row statuses
+const (
+	ImportRowValid    = "valid"
+	ImportRowImported = "imported"
+	ImportRowError    = "error"
+)
+
+// defaultImportBatchSize is the number of books inserted per transaction when
+// no batch size is configured
+const defaultImportBatchSize = 100
+
+// ImportOptions controls a book import
+type ImportOptions struct {
+	DryRun    bool
+	BatchSize int
+	// Progress, when not nil, is called with the number of valid rows written
+	// so far and in all as the rows are inserted
+	Progress func(done, total int)
+}
+
+// ImportRowResult is the outcome of a single CSV row
+type ImportRowResult struct {
+	Row    int        `json:"row"`
+	ISBN   string     `json:"isbn,omitempty"`
+	Status string     `json:"status"`
+	BookID *uuid.UUID `json:"book_id,omitempty"`
+	Errors []string   `json:"errors,omitempty"`
+}
+
+// ImportReport summarizes a book import
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	TotalRows int               `json:"total_rows"`
+	Valid     int               `json:"valid"`
+	Imported  int               `json:"imported"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+	// BatchErrors lists the batches that failed; their rows are not imported
+	BatchErrors []BatchError `json:"batch_errors,omitempty"`
+}
+
+// importRow is a validated row waiting to be inserted
+type importRow struct {
+	result *ImportRowResult
+	book   *models.Book
+}
+
+// importResolver resolves author and category references by ID or name, caching lookups
+type importResolver struct {
+	db         *gorm.DB
+	authors    map[string]uuid.UUID
+	categories map[string]uuid.UUID
+}
+
+// ImportBooks reads books from CSV, validates every row, and inserts the valid
+// rows in batches. The header row names the columns; author and category may be
+// given by ID (author_id, category_id) or name (author, author_name, category,
+// category_name). In dry-run mode rows are validated but nothing is written.
+func (s *BookService) ImportBooks(r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("csv file is empty")
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"title", "isbn", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing required column %q", required)
+		}
+	}
+
+	resolver := &importResolver{
+		db:         s.db,
+		authors:    make(map[string]uuid.UUID),
+		categories: make(map[string]uuid.UUID),
+	}
+	report := &ImportReport{DryRun: opts.DryRun, Rows: []ImportRowResult{}}
+	seenISBNs := make(map[string]int)
+	var valid []importRow
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		report.TotalRows++
+		report.Rows = append(report.Rows, ImportRowResult{Row: line})
+		result := &report.Rows[len(report.Rows)-1]
+
+		if err != nil {
+			result.Status = ImportRowError
+			result.Errors = []string{fmt.Sprintf("malformed csv row: %v", err)}
+			continue
+		}
+
+		book, rowErrors := resolver.parseRow(record, columns)
+		result.ISBN = book.ISBN
+		if book.ISBN != "" {
+			if first, ok := seenISBNs[book.ISBN]; ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("duplicate isbn, first seen on row %d", first))
+			} else {
+				seenISBNs[book.ISBN] = line
+			}
+		}
+		if len(rowErrors) > 0 {
+			result.Status = ImportRowError
+			result.Errors = rowErrors
+			continue
+		}
+		result.Status = ImportRowValid
+		valid = append(valid, importRow{book: book})
+	}
+
+	// Row results may have moved while the slice grew, so link them once reading is done
+	v := 0
+	for i := range report.Rows {
+		if report.Rows[i].Status == ImportRowValid {
+			valid[v].result = &report.Rows[i]
+			v++
+		}
+	}
+
+	if err := s.markExistingISBNs(valid); err != nil {
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		report.BatchErrors = s.insertImportRows(valid, opts.BatchSize, opts.Progress)
+		s.counts.Invalidate("books")
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case ImportRowValid:
+			report.Valid++
+		case ImportRowImported:
+			report.Valid++
+			report.Imported++
+		default:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// markExistingISBNs flags rows whose ISBN already belongs to a book
+func (s *BookService) markExistingISBNs(rows []importRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	isbns := make([]string, len(rows))
+	for i, row := range rows {
+		isbns[i] = row.book.ISBN
+	}
+
+	var existing []string
+	if err := s.db.Unscoped().Model(&models.Book{}).Where("isbn IN ?", isbns).Pluck("isbn", &existing).Error; err != nil {
+		return fmt.Errorf("failed to check existing isbns: %w", err)
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, isbn := range existing {
+		exists[isbn] = true
+	}
+
+	for _, row := range rows {
+		if exists[row.book.ISBN] {
+			row.result.Status = ImportRowError
+			row.result.Errors = append(row.result.Errors, "a book with this isbn already exists")
+		}
+	}
+	return nil
+}
+
+// insertImportRows inserts the rows still valid batchSize at a time,
+// recording the outcome on each row, and returns the batches that failed
+func (s *BookService) insertImportRows(rows []importRow, batchSize int, progress func(done, total int)) []BatchError {
+	var books []*models.Book
+	var inserted []importRow
+	for _, row := range rows {
+		if row.result.Status == ImportRowValid {
+			books = append(books, row.book)
+			inserted = append(inserted, row)
+		}
+	}
+
+	var batchProgress func(done int)
+	if progress != nil {
+		batchProgress = func(done int) {
+			progress(done, len(books))
+		}
+	}
+	failed := createInBatches(s.db, books, batchSize, func(tx *gorm.DB, batch []*models.Book) error {
+		return recordCreatedBooks(tx, batch, models.PriceSourceImport, models.StockReasonImport)
+	}, batchProgress)
+
+	for _, row := range inserted {
+		row.result.Status = ImportRowImported
+		row.result.BookID = &row.book.ID
+	}
+	for _, batch := range failed {
+		for _, row := range inserted[batch.First : batch.First+batch.Rows] {
+			row.result.Status = ImportRowError
+			row.result.BookID = nil
+			row.result.Errors = append(row.result.Errors, batch.Error)
+		}
+	}
+	return failed
+}
+
+// parseRow validates a CSV record and builds the book it describes
+func (r *importResolver) parseRow(record []string, columns map[string]int) (*models.Book, []string) {
+	field := func(names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				if value := strings.TrimSpace(record[i]); value != "" {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+
+	var rowErrors []string
+	book := &models.Book{
+		Title:       field("title"),
+		ISBN:        utils.NormalizeISBN(field("isbn")),
+		Description: field("description"),
+	}
+
+	if book.Title == "" {
+		rowErrors = append(rowErrors, "title is required")
+	} else if len(book.Title) > 255 {
+		rowErrors = append(rowErrors, "title must be at most 255 characters long")
+	}
+
+	if book.ISBN == "" {
+		rowErrors = append(rowErrors, "isbn is required")
+	} else if isbn, ok := utils.CanonicalISBN(book.ISBN); !ok {
+		rowErrors = append(rowErrors, "isbn must be a valid ISBN-10 or ISBN-13")
+	} else {
+		book.ISBN = isbn
+	}
+
+	if price, err := strconv.ParseFloat(field("price"), 64); err != nil {
+		rowErrors = append(rowErrors, "price must be a number")
+	} else if price < 0 {
+		rowErrors = append(rowErrors, "price must not be negative")
+	} else {
+		book.Price = price
+	}
+
+	if stockStr := field("stock"); stockStr != "" {
+		if stock, err := strconv.Atoi(stockStr); err != nil || stock < 0 {
+			rowErrors = append(rowErrors, "stock must be a non-negative integer")
+		} else {
+			book.Stock = stock
+		}
+	}
+
+	if publishedStr := field("published_at"); publishedStr != "" {
+		if publishedAt, err := parseImportDate(publishedStr); err != nil {
+			rowErrors = append(rowErrors, "published_at must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+		} else {
+			book.PublishedAt = &publishedAt
+		}
+	}
+
+	authorID, err := r.resolve(&models.Author{}, r.authors, field("author_id"), field("author", "author_name"))
+	if err != nil {
+		rowErrors = append(rowErrors, "author: "+err.Error())
+	}
+	book.AuthorID = authorID
+
+	categoryID, err := r.resolve(&models.Category{}, r.categories, field("category_id"), field("category", "category_name"))
+	if err != nil {
+		rowErrors = append(rowErrors, "category: "+err.Error())
+	}
+	book.CategoryID = categoryID
+
+	return book, rowErrors
+}
+
+// resolve finds a row of model by ID, or by case-insensitive name when no ID is given
+func (r *importResolver) resolve(model interface{}, cache map[string]uuid.UUID, idStr, name string) (uuid.UUID, error) {
+	key := "id:" + idStr
+	if idStr == "" {
+		if name == "" {
+			return uuid.Nil, errors.New("id or name is required")
+		}
+		key = "name:" + strings.ToLower(name)
+	}
+	if id, ok := cache[key]; ok {
+		if id == uuid.Nil {
+			return uuid.Nil, errors.New("not found")
+		}
+		return id, nil
+	}
+
+	var ids []uuid.UUID
+	if idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return uuid.Nil, errors.New("invalid id")
+		}
+		if err := r.db.Model(model).Where("id = ?", id).Pluck("id", &ids).Error; err != nil {
+			return uuid.Nil, fmt.Errorf("lookup failed: %w", err)
+		}
+	} else {
+		if err := r.db.Model(model).Where("LOWER(name) = LOWER(?)", name).Limit(2).Pluck("id", &ids).Error; err != nil {
+			return uuid.Nil, fmt.Errorf("lookup failed: %w", err)
+		}
+		if len(ids) > 1 {
+			return uuid.Nil, fmt.Errorf("name %q is ambiguous, use the id instead", name)
+		}
+	}
+
+	if len(ids) == 0 {
+		cache[key] = uuid.Nil
+		return uuid.Nil, errors.New("not found")
+	}
+	cache[key] = ids[0]
+	return ids[0], nil
+}
+
+// parseImportDate parses a date or RFC 3339 timestamp
+func parseImportDate(value string) (models.DateOnly, error) {
+	return models.ParseDateOnly(value)
+}
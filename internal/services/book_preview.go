@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bookstore-api/internal/media"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/storage"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookPreviewService stores books' sample content
+type BookPreviewService struct {
+	db    *gorm.DB
+	ctx   context.Context
+	store storage.Storage
+}
+
+// NewBookPreviewService creates a new book preview service storing previews in store
+func NewBookPreviewService(db *gorm.DB, store storage.Storage) *BookPreviewService {
+	return &BookPreviewService{
+		db:    db,
+		ctx:   context.Background(),
+		store: store,
+	}
+}
+
+// WithContext returns a copy of the service whose queries and storage calls
+// run with ctx, which carries the tenant they are scoped to
+func (s *BookPreviewService) WithContext(ctx context.Context) *BookPreviewService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// SetBookPreview stores data, a PDF, EPUB, or plain text excerpt, as the
+// book's preview, replacing any previous one
+func (s *BookPreviewService) SetBookPreview(bookID uuid.UUID, data []byte) (*models.BookPreview, error) {
+	doc, ok := media.DetectDocument(data)
+	if !ok {
+		return nil, fmt.Errorf("invalid preview: expected PDF, EPUB, or UTF-8 plain text")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Book{}).Where("id = ?", bookID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check book: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("book not found")
+	}
+	previous, err := s.GetBookPreview(bookID)
+	if err != nil && err.Error() != "book has no preview" {
+		return nil, err
+	}
+
+	// Every upload gets its own key, so cached copies of the previous preview
+	// are never served for the new one
+	preview := &models.BookPreview{
+		BookID:      bookID,
+		Key:         fmt.Sprintf("books/%s/preview-%d%s", bookID, time.Now().UnixNano(), doc.Extension),
+		ContentType: doc.ContentType,
+		Size:        int64(len(data)),
+	}
+	if err := s.store.Put(s.ctx, preview.Key, preview.ContentType, data); err != nil {
+		return nil, err
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"key", "content_type", "size", "updated_at"}),
+	}).Create(preview).Error
+	if err != nil {
+		s.deleteFile(preview.Key)
+		return nil, fmt.Errorf("failed to save book preview: %w", err)
+	}
+	if previous != nil {
+		s.deleteFile(previous.Key)
+	}
+	return preview, nil
+}
+
+// GetBookPreview retrieves a book's preview
+func (s *BookPreviewService) GetBookPreview(bookID uuid.UUID) (*models.BookPreview, error) {
+	var preview models.BookPreview
+	if err := s.db.First(&preview, "book_id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("book has no preview")
+		}
+		return nil, fmt.Errorf("failed to get book preview: %w", err)
+	}
+	return &preview, nil
+}
+
+// OpenBookPreview opens the content of a preview for reading
+func (s *BookPreviewService) OpenBookPreview(preview *models.BookPreview) (io.ReadSeekCloser, error) {
+	return s.store.Open(s.ctx, preview.Key)
+}
+
+// DeleteBookPreview removes a book's preview
+func (s *BookPreviewService) DeleteBookPreview(bookID uuid.UUID) error {
+	preview, err := s.GetBookPreview(bookID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(&models.BookPreview{}, "book_id = ?", bookID).Error; err != nil {
+		return fmt.Errorf("failed to delete book preview: %w", err)
+	}
+	s.deleteFile(preview.Key)
+	return nil
+}
+
+// deleteFile removes a preview's stored content. Failures are only logged: the
+// file is no longer referenced, so they leave an orphaned file.
+func (s *BookPreviewService) deleteFile(key string) {
+	if err := s.store.Delete(s.ctx, key); err != nil {
+		log.Printf("Failed to delete book preview %s: %v", key, err)
+	}
+}
@@ -1,40 +1,211 @@
 package services
 
 import (
-	"bookstore-api/internal/database"
+	"bookstore-api/internal/events"
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/slug"
+	"bookstore-api/internal/utils"
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BookService handles book-related business logic
 type BookService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	counts *CountCache
+	// status is the status of the books listed and searched, or empty for
+	// books of every status
+	status string
 }
 
-// NewBookService creates a new book service
-func NewBookService() *BookService {
+// NewBookService creates a new book service listing active books
+func NewBookService(db *gorm.DB) *BookService {
 	return &BookService{
-		db: database.GetDB(),
+		db:     db,
+		counts: GetCountCache(),
+		status: models.BookStatusActive,
 	}
 }
 
-// CreateBook creates a new book
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *BookService) WithContext(ctx context.Context) *BookService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// WithStatus returns a copy of the service whose listings and searches
+// include only books with status, or books of every status when status is
+// empty, as admins see them
+func (s *BookService) WithStatus(status string) *BookService {
+	clone := *s
+	clone.status = status
+	return &clone
+}
+
+// listed scopes a books query to the status the service lists
+func (s *BookService) listed(db *gorm.DB) *gorm.DB {
+	if s.status == "" {
+		return db
+	}
+	return db.Where("status = ?", s.status)
+}
+
+// countKey returns the count cache key of a books listing, which is kept per
+// status
+func (s *BookService) countKey(key string) string {
+	if s.status == "" {
+		return key + ":all"
+	}
+	return key + ":" + s.status
+}
+
+// DuplicateBookError reports that a book being created has the ISBN of an
+// existing book. Its message matches the other ISBN uniqueness errors.
+type DuplicateBookError struct {
+	// Existing is the book holding the ISBN
+	Existing *models.Book
+	// Same is set when the existing book has the title, author, category, and
+	// price of the one being created, so the request repeats an earlier one,
+	// such as a retry or a double submission, rather than colliding with it
+	Same bool
+}
+
+func (e *DuplicateBookError) Error() string {
+	return "book with this isbn already exists"
+}
+
+// errISBNTaken is returned from the create transaction when another request
+// inserted the ISBN first
+var errISBNTaken = errors.New("isbn taken")
+
+// CreateBook creates a new book. The ISBN may be given as ISBN-10 or ISBN-13,
+// with or without hyphens, and is stored as a normalized ISBN-13. A book with
+// the same ISBN, including one created concurrently, fails it with a
+// DuplicateBookError.
 func (s *BookService) CreateBook(book *models.Book) error {
+	isbn, ok := utils.CanonicalISBN(book.ISBN)
+	if !ok {
+		return fmt.Errorf("invalid isbn")
+	}
+	book.ISBN = isbn
+	if book.Status == "" {
+		book.Status = models.BookStatusActive
+	}
+	if err := utils.ValidateFields(book, "Title", "Stock", "PublishedAt", "Status", "Format", "PageCount"); err != nil {
+		return fmt.Errorf("invalid book: %w", err)
+	}
+
 	// Validate that author and category exist
 	if err := s.validateAuthorAndCategory(book.AuthorID, book.CategoryID); err != nil {
 		return err
 	}
+	if err := s.checkISBNAvailable(isbn, uuid.Nil); err != nil {
+		return s.duplicateBook(book, err)
+	}
 
-	if err := s.db.Create(book).Error; err != nil {
-		return fmt.Errorf("failed to create book: %w", err)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// A concurrent request with the same ISBN may have passed the check
+		// above too; the loser inserts nothing instead of failing on the
+		// unique index
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "isbn"}},
+			DoNothing: true,
+		}).Create(book)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("book with this isbn already exists")
+			}
+			return fmt.Errorf("failed to create book: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errISBNTaken
+		}
+		if err := recordPrice(tx, book.ID, nil, book.Price, models.PriceSourceAPI); err != nil {
+			return err
+		}
+		if err := recordInitialStock(tx, book, models.StockReasonInitial); err != nil {
+			return err
+		}
+		return events.Record(tx, events.BookCreated, events.AggregateBook, book.ID, book)
+	})
+	if errors.Is(err, errISBNTaken) {
+		return s.duplicateBook(book, s.checkISBNAvailable(isbn, uuid.Nil))
 	}
+	if err != nil {
+		return err
+	}
+	s.counts.Invalidate("books")
 	return nil
 }
 
-// GetBookByID retrieves a book by ID
+// duplicateBook turns the error of an unavailable ISBN into a
+// DuplicateBookError carrying the book holding it
+func (s *BookService) duplicateBook(book *models.Book, err error) error {
+	if err == nil || err.Error() != "book with this isbn already exists" {
+		return err
+	}
+
+	var existing models.Book
+	if err := unrestricted(s.db).Unscoped().Preload("Author").Preload("Category").First(&existing, "isbn = ?", book.ISBN).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// The conflicting book was deleted in the meantime
+			return fmt.Errorf("book with this isbn already exists")
+		}
+		return fmt.Errorf("failed to get book: %w", err)
+	}
+	same := !existing.DeletedAt.Valid &&
+		existing.Title == book.Title &&
+		existing.AuthorID == book.AuthorID &&
+		existing.CategoryID == book.CategoryID &&
+		existing.Price == book.Price
+	return &DuplicateBookError{Existing: &existing, Same: same}
+}
+
+// validateBookUpdates checks updated fields against the rules of books
+func validateBookUpdates(updates map[string]interface{}) error {
+	var candidate models.Book
+	var fields []string
+	if title, ok := updates["title"].(string); ok {
+		candidate.Title = title
+		fields = append(fields, "Title")
+	}
+	if stock, ok := updates["stock"].(int); ok {
+		candidate.Stock = stock
+		fields = append(fields, "Stock")
+	}
+	if publishedAt, ok := updates["published_at"].(models.DateOnly); ok {
+		candidate.PublishedAt = &publishedAt
+		fields = append(fields, "PublishedAt")
+	}
+	if status, ok := updates["status"].(string); ok {
+		candidate.Status = status
+		fields = append(fields, "Status")
+	}
+	if format, ok := updates["format"].(string); ok {
+		candidate.Format = format
+		fields = append(fields, "Format")
+	}
+	if pageCount, ok := updates["page_count"].(int); ok {
+		candidate.PageCount = &pageCount
+		fields = append(fields, "PageCount")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if err := utils.ValidateFields(&candidate, fields...); err != nil {
+		return fmt.Errorf("invalid book: %w", err)
+	}
+	return nil
+}
+
+// GetBookByID retrieves a book by ID with where its stock is held
 func (s *BookService) GetBookByID(id uuid.UUID) (*models.Book, error) {
 	var book models.Book
 	if err := s.db.Preload("Author").Preload("Category").First(&book, "id = ?", id).Error; err != nil {
@@ -43,16 +214,90 @@ func (s *BookService) GetBookByID(id uuid.UUID) (*models.Book, error) {
 		}
 		return nil, fmt.Errorf("failed to get book: %w", err)
 	}
+	if err := applyPromotions(s.db, []*models.Book{&book}); err != nil {
+		return nil, err
+	}
+	if err := setAvailability(s.db, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// GetBookBySlug retrieves a book by its current slug or one it had before
+// being renamed
+func (s *BookService) GetBookBySlug(value string) (*models.Book, error) {
+	id, err := slugID(s.db, &models.Book{}, "books", value)
+	if err != nil {
+		return nil, err
+	}
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("book not found")
+	}
+	return s.GetBookByID(id)
+}
+
+// ResolveBookID returns the ID of the book value names, by ID or short code
+func (s *BookService) ResolveBookID(value string) (uuid.UUID, error) {
+	id, err := resolveID(s.db, &models.Book{}, value)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("book not found")
+	}
+	return id, nil
+}
+
+// GetBookStatus returns the status of a book without loading the rest of it
+func (s *BookService) GetBookStatus(id uuid.UUID) (string, error) {
+	var book models.Book
+	if err := s.db.Select("id", "status").First(&book, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", fmt.Errorf("book not found")
+		}
+		return "", fmt.Errorf("failed to get book: %w", err)
+	}
+	return book.Status, nil
+}
+
+// IsBookOwner reports whether the user has claimed the book's author
+func (s *BookService) IsBookOwner(bookID, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.Book{}).
+		Joins("JOIN authors ON authors.id = books.author_id AND authors.deleted_at IS NULL").
+		Where("books.id = ? AND authors.user_id = ?", bookID, userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check book owner: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetBookByISBN retrieves a book by its normalized ISBN-13
+func (s *BookService) GetBookByISBN(isbn string) (*models.Book, error) {
+	var book models.Book
+	if err := s.db.First(&book, "isbn = ?", isbn).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
 	return &book, nil
 }
 
-// GetAllBooks retrieves all books with pagination
+// GetAllBooks retrieves all books with the status the service lists, with
+// pagination
 func (s *BookService) GetAllBooks(page, limit int) ([]models.Book, int64, error) {
 	var books []models.Book
-	var total int64
 
 	// Count total records
-	if err := s.db.Model(&models.Book{}).Count(&total).Error; err != nil {
+	var total int64
+	var err error
+	if s.status == "" {
+		total, err = s.counts.CountTable(s.db, "books", &models.Book{})
+	} else {
+		total, err = s.counts.Count(s.countKey("books:status"), s.db.Model(&models.Book{}).Scopes(s.listed))
+	}
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count books: %w", err)
 	}
 
@@ -60,17 +305,45 @@ func (s *BookService) GetAllBooks(page, limit int) ([]models.Book, int64, error)
 	offset := (page - 1) * limit
 
 	// Get books with pagination
-	if err := s.db.Preload("Author").Preload("Category").Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+	if err := s.db.Scopes(s.listed).Preload("Author").Preload("Category").Offset(offset).Limit(limit).Find(&books).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get books: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, 0, err
+	}
 
 	return books, total, nil
 }
 
-// UpdateBook updates an existing book
-func (s *BookService) UpdateBook(id uuid.UUID, updates *models.Book) error {
+// UpdateBook sets the columns in updates on an existing book, leaving the
+// others unchanged, and records the change in the book's revision history
+func (s *BookService) UpdateBook(id uuid.UUID, updates map[string]interface{}) error {
+	return s.updateBook(id, updates, nil)
+}
+
+// updateBook updates a book as UpdateBook does. revision, when set, is the
+// revision the update applies: a pending revision being approved, which
+// gets the snapshot, or a new revision to record, such as a revert. A nil
+// revision records a new one for the context's principal.
+func (s *BookService) updateBook(id uuid.UUID, updates map[string]interface{}, revision *models.BookRevision) error {
+	if value, ok := updates["isbn"].(string); ok {
+		isbn, ok := utils.CanonicalISBN(value)
+		if !ok {
+			return fmt.Errorf("invalid isbn")
+		}
+		updates["isbn"] = isbn
+		if err := s.checkISBNAvailable(isbn, id); err != nil {
+			return err
+		}
+	}
+	if err := validateBookUpdates(updates); err != nil {
+		return err
+	}
+
 	// If updating author or category, validate they exist
-	if updates.AuthorID != uuid.Nil || updates.CategoryID != uuid.Nil {
+	newAuthorID, updatingAuthor := updates["author_id"].(uuid.UUID)
+	newCategoryID, updatingCategory := updates["category_id"].(uuid.UUID)
+	if updatingAuthor || updatingCategory {
 		// Get current book to check existing values
 		var currentBook models.Book
 		if err := s.db.First(&currentBook, "id = ?", id).Error; err != nil {
@@ -83,11 +356,11 @@ func (s *BookService) UpdateBook(id uuid.UUID, updates *models.Book) error {
 		authorID := currentBook.AuthorID
 		categoryID := currentBook.CategoryID
 
-		if updates.AuthorID != uuid.Nil {
-			authorID = updates.AuthorID
+		if updatingAuthor {
+			authorID = newAuthorID
 		}
-		if updates.CategoryID != uuid.Nil {
-			categoryID = updates.CategoryID
+		if updatingCategory {
+			categoryID = newCategoryID
 		}
 
 		if err := s.validateAuthorAndCategory(authorID, categoryID); err != nil {
@@ -95,35 +368,118 @@ func (s *BookService) UpdateBook(id uuid.UUID, updates *models.Book) error {
 		}
 	}
 
-	result := s.db.Model(&models.Book{}).Where("id = ?", id).Updates(updates)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update book: %w", result.Error)
+	// In the event-sourced inventory mode a new stock is applied as a movement
+	// rather than written with the other columns
+	newStock, settingStock := updates["stock"].(int)
+	if settingStock && EventSourcedInventory() {
+		delete(updates, "stock")
+	} else {
+		settingStock = false
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("book not found")
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var previous models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("book not found")
+			}
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+
+		if settingStock && newStock != previous.Stock {
+			if _, err := applyStockMovement(tx, id, newStock-previous.Stock, models.StockReasonSet, ""); err != nil {
+				return err
+			}
+		} else if stock, ok := updates["stock"].(int); ok {
+			if err := checkAllocatedStock(tx, id, stock); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&models.Book{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("book with this isbn already exists")
+			}
+			return fmt.Errorf("failed to update book: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("book not found")
+		}
+
+		var book models.Book
+		if err := tx.First(&book, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		bookSlug, err := slug.Rename(tx, "books", book.TenantID, book.ID, book.Slug, book.Title)
+		if err != nil {
+			return err
+		}
+		book.Slug = bookSlug
+		if err := recordRevision(tx, &previous, &book, revision); err != nil {
+			return err
+		}
+		if err := events.Record(tx, events.BookUpdated, events.AggregateBook, id, &book); err != nil {
+			return err
+		}
+
+		if book.Stock != previous.Stock {
+			if err := events.Record(tx, events.BookStockChanged, events.AggregateBook, id, events.StockChangedPayload{
+				BookID:        id,
+				PreviousStock: previous.Stock,
+				Stock:         book.Stock,
+			}); err != nil {
+				return err
+			}
+		}
+		if book.Price != previous.Price {
+			if err := recordPrice(tx, id, &previous.Price, book.Price, models.PriceSourceAPI); err != nil {
+				return err
+			}
+			if err := events.Record(tx, events.BookPriceChanged, events.AggregateBook, id, events.PriceChangedPayload{
+				BookID:        id,
+				PreviousPrice: previous.Price,
+				Price:         book.Price,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	s.counts.Invalidate("books")
 	return nil
 }
 
 // DeleteBook soft deletes a book
 func (s *BookService) DeleteBook(id uuid.UUID) error {
-	result := s.db.Delete(&models.Book{}, "id = ?", id)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete book: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("book not found")
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Book{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete book: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("book not found")
+		}
+		return events.Record(tx, events.BookDeleted, events.AggregateBook, id, events.DeletedPayload{ID: id})
+	})
+	if err != nil {
+		return err
 	}
+	s.counts.Invalidate("books")
 	return nil
 }
 
-// GetBooksByAuthor retrieves books by author ID
+// GetBooksByAuthor retrieves books by author ID with the status the service
+// lists
 func (s *BookService) GetBooksByAuthor(authorID uuid.UUID, page, limit int) ([]models.Book, int64, error) {
 	var books []models.Book
-	var total int64
 
 	// Count total records
-	if err := s.db.Model(&models.Book{}).Where("author_id = ?", authorID).Count(&total).Error; err != nil {
+	total, err := s.counts.Count(s.countKey("books:author:"+authorID.String()), s.db.Model(&models.Book{}).Scopes(s.listed).Where("author_id = ?", authorID))
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count books: %w", err)
 	}
 
@@ -131,20 +487,24 @@ func (s *BookService) GetBooksByAuthor(authorID uuid.UUID, page, limit int) ([]m
 	offset := (page - 1) * limit
 
 	// Get books with pagination
-	if err := s.db.Preload("Author").Preload("Category").Where("author_id = ?", authorID).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+	if err := s.db.Scopes(s.listed).Preload("Author").Preload("Category").Where("author_id = ?", authorID).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get books: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, 0, err
+	}
 
 	return books, total, nil
 }
 
-// GetBooksByCategory retrieves books by category ID
+// GetBooksByCategory retrieves books by category ID with the status the
+// service lists
 func (s *BookService) GetBooksByCategory(categoryID uuid.UUID, page, limit int) ([]models.Book, int64, error) {
 	var books []models.Book
-	var total int64
 
 	// Count total records
-	if err := s.db.Model(&models.Book{}).Where("category_id = ?", categoryID).Count(&total).Error; err != nil {
+	total, err := s.counts.Count(s.countKey("books:category:"+categoryID.String()), s.db.Model(&models.Book{}).Scopes(s.listed).Where("category_id = ?", categoryID))
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count books: %w", err)
 	}
 
@@ -152,22 +512,56 @@ func (s *BookService) GetBooksByCategory(categoryID uuid.UUID, page, limit int)
 	offset := (page - 1) * limit
 
 	// Get books with pagination
-	if err := s.db.Preload("Author").Preload("Category").Where("category_id = ?", categoryID).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+	if err := s.db.Scopes(s.listed).Preload("Author").Preload("Category").Where("category_id = ?", categoryID).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get books: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, 0, err
+	}
 
 	return books, total, nil
 }
 
-// SearchBooks searches books by title, ISBN, or description
-func (s *BookService) SearchBooks(query string, page, limit int) ([]models.Book, int64, error) {
+// GetBooksInCategoryTree retrieves books in a category or any of its
+// subcategories
+func (s *BookService) GetBooksInCategoryTree(categoryID uuid.UUID, page, limit int) ([]models.Book, int64, error) {
 	var books []models.Book
-	var total int64
 
-	searchQuery := "%" + query + "%"
+	categoryIDs, err := categoryTreeIDs(s.db, categoryID)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// Count total records
-	if err := s.db.Model(&models.Book{}).Where("title ILIKE ? OR isbn ILIKE ? OR description ILIKE ?", searchQuery, searchQuery, searchQuery).Count(&total).Error; err != nil {
+	total, err := s.counts.Count(s.countKey("books:category_tree:"+categoryID.String()), s.db.Model(&models.Book{}).Scopes(s.listed).Where("category_id IN ?", categoryIDs))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count books: %w", err)
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Get books with pagination
+	if err := s.db.Scopes(s.listed).Preload("Author").Preload("Category").Where("category_id IN ?", categoryIDs).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get books: %w", err)
+	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, 0, err
+	}
+
+	return books, total, nil
+}
+
+// SearchBooks searches books with the status the service lists by title,
+// ISBN, or description
+func (s *BookService) SearchBooks(query SearchQuery, page, limit int) ([]models.Book, int64, error) {
+	var books []models.Book
+
+	searchQuery := query.Pattern()
+
+	// Count total records
+	total, err := s.counts.Count(s.countKey("books:search:"+query.String()), s.db.Model(&models.Book{}).Scopes(s.listed).Where("title ILIKE ? OR isbn ILIKE ? OR description ILIKE ?", searchQuery, searchQuery, searchQuery))
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count books: %w", err)
 	}
 
@@ -175,27 +569,65 @@ func (s *BookService) SearchBooks(query string, page, limit int) ([]models.Book,
 	offset := (page - 1) * limit
 
 	// Search books with pagination
-	if err := s.db.Preload("Author").Preload("Category").Where("title ILIKE ? OR isbn ILIKE ? OR description ILIKE ?", searchQuery, searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+	if err := s.db.Scopes(s.listed).Preload("Author").Preload("Category").Where("title ILIKE ? OR isbn ILIKE ? OR description ILIKE ?", searchQuery, searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to search books: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, 0, err
+	}
 
 	return books, total, nil
 }
 
-// UpdateBookStock updates book stock
+// UpdateBookStock sets a book's stock. In the event-sourced inventory mode
+// the difference from the current stock is recorded as a movement.
 func (s *BookService) UpdateBookStock(id uuid.UUID, newStock int) error {
 	if newStock < 0 {
 		return fmt.Errorf("stock cannot be negative")
 	}
 
-	result := s.db.Model(&models.Book{}).Where("id = ?", id).Update("stock", newStock)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update book stock: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("book not found")
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var book models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "stock").First(&book, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("book not found")
+			}
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+
+		if EventSourcedInventory() {
+			if book.Stock != newStock {
+				if _, err := applyStockMovement(tx, id, newStock-book.Stock, models.StockReasonSet, ""); err != nil {
+					return err
+				}
+			}
+		} else {
+			if err := checkAllocatedStock(tx, id, newStock); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Book{}).Where("id = ?", id).Update("stock", newStock).Error; err != nil {
+				return fmt.Errorf("failed to update book stock: %w", err)
+			}
+		}
+
+		if book.Stock == newStock {
+			return nil
+		}
+		return events.Record(tx, events.BookStockChanged, events.AggregateBook, id, events.StockChangedPayload{
+			BookID:        id,
+			PreviousStock: book.Stock,
+			Stock:         newStock,
+		})
+	})
+}
+
+// GetLowStockBooks retrieves books whose stock is at or below the threshold
+func (s *BookService) GetLowStockBooks(threshold int) ([]models.Book, error) {
+	var books []models.Book
+	if err := s.db.Where("stock <= ?", threshold).Order("stock ASC").Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("failed to get low stock books: %w", err)
 	}
-	return nil
+	return books, nil
 }
 
 // validateAuthorAndCategory validates that author and category exist
@@ -220,3 +652,15 @@ func (s *BookService) validateAuthorAndCategory(authorID, categoryID uuid.UUID)
 
 	return nil
 }
+
+// checkISBNAvailable ensures no other book, including soft-deleted ones, uses the ISBN
+func (s *BookService) checkISBNAvailable(isbn string, excludeID uuid.UUID) error {
+	var count int64
+	if err := unrestricted(s.db).Unscoped().Model(&models.Book{}).Where("isbn = ? AND id <> ?", isbn, excludeID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check isbn: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("book with this isbn already exists")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// setAuthorBooksCounts sets BooksCount on every author with one aggregated query
+func setAuthorBooksCounts(db *gorm.DB, authors []models.Author) error {
+	ids := make([]uuid.UUID, len(authors))
+	for i := range authors {
+		ids[i] = authors[i].ID
+	}
+	counts, err := countBooksBy(db, "author_id", ids)
+	if err != nil {
+		return err
+	}
+	for i := range authors {
+		count := counts[authors[i].ID]
+		authors[i].BooksCount = &count
+	}
+	return nil
+}
+
+// setCategoryBooksCounts sets BooksCount on every category with one aggregated
+// query. Books in subcategories are not counted.
+func setCategoryBooksCounts(db *gorm.DB, categories []models.Category) error {
+	ids := make([]uuid.UUID, len(categories))
+	for i := range categories {
+		ids[i] = categories[i].ID
+	}
+	counts, err := countBooksBy(db, "category_id", ids)
+	if err != nil {
+		return err
+	}
+	for i := range categories {
+		count := counts[categories[i].ID]
+		categories[i].BooksCount = &count
+	}
+	return nil
+}
+
+// countBooksBy returns the number of active books whose column, author_id or
+// category_id, is each of ids. IDs without books are left out.
+func countBooksBy(db *gorm.DB, column string, ids []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ID    uuid.UUID
+		Count int64
+	}
+	err := db.Model(&models.Book{}).
+		Select(column+" AS id, COUNT(*) AS count").
+		Where(column+" IN ? AND status = ?", ids, models.BookStatusActive).
+		Group(column).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count books: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BatchError describes a batch of a bulk write that failed and was rolled back
+type BatchError struct {
+	// Batch is the zero-based number of the batch
+	Batch int `json:"batch"`
+	// First is the index of the batch's first row among the rows written
+	First int    `json:"first"`
+	Rows  int    `json:"rows"`
+	Error string `json:"error"`
+}
+
+// createInBatches inserts rows batchSize at a time, each batch with one
+// multi-row insert in its own transaction. then, when not nil, writes the
+// rows depending on the batch in the same transaction. A failed batch is
+// rolled back and reported, and the following batches are still written.
+// progress, when not nil, is called with the number of rows written or
+// failed after each batch.
+func createInBatches[T any](db *gorm.DB, rows []T, batchSize int, then func(tx *gorm.DB, batch []T) error, progress func(done int)) []BatchError {
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	var failed []BatchError
+	for first := 0; first < len(rows); first += batchSize {
+		batch := rows[first:min(first+batchSize, len(rows))]
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.CreateInBatches(batch, len(batch)).Error; err != nil {
+				return fmt.Errorf("failed to insert batch: %w", err)
+			}
+			if then != nil {
+				return then(tx, batch)
+			}
+			return nil
+		})
+		if err != nil {
+			failed = append(failed, BatchError{
+				Batch: first / batchSize,
+				First: first,
+				Rows:  len(batch),
+				Error: err.Error(),
+			})
+		}
+		if progress != nil {
+			progress(first + len(batch))
+		}
+	}
+	return failed
+}
+
+// recordCreatedBooks writes the price history, initial stock movements, and
+// book.created events of newly inserted books with one insert each
+func recordCreatedBooks(tx *gorm.DB, books []*models.Book, priceSource, stockReason string) error {
+	now := time.Now()
+	prices := make([]models.PriceHistory, 0, len(books))
+	var movements []models.StockMovement
+	entries := make([]events.Entry, 0, len(books))
+
+	for _, book := range books {
+		prices = append(prices, models.PriceHistory{
+			BookID:    book.ID,
+			Price:     book.Price,
+			Source:    priceSource,
+			ChangedAt: now,
+		})
+		if EventSourcedInventory() && book.Stock != 0 {
+			movements = append(movements, models.StockMovement{
+				TenantID:   book.TenantID,
+				BookID:     book.ID,
+				Delta:      book.Stock,
+				StockAfter: book.Stock,
+				Reason:     stockReason,
+			})
+		}
+		entries = append(entries, events.Entry{
+			EventType:     events.BookCreated,
+			AggregateType: events.AggregateBook,
+			AggregateID:   book.ID,
+			Payload:       book,
+		})
+	}
+
+	if err := tx.CreateInBatches(&prices, len(prices)).Error; err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+	if len(movements) > 0 {
+		if err := tx.CreateInBatches(&movements, len(movements)).Error; err != nil {
+			return fmt.Errorf("failed to record stock movements: %w", err)
+		}
+	}
+	return events.RecordBatch(tx, entries)
+}
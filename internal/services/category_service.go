@@ -1,31 +1,67 @@
 package services
 
 import (
-	"bookstore-api/internal/database"
+	"bookstore-api/internal/events"
 	"bookstore-api/internal/models"
+	"bookstore-api/internal/slug"
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// CategoryNode is a category with its subcategories
+type CategoryNode struct {
+	models.Category
+	Children []*CategoryNode `json:"children"`
+}
+
 // CategoryService handles category-related business logic
 type CategoryService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	counts *CountCache
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService() *CategoryService {
+func NewCategoryService(db *gorm.DB) *CategoryService {
 	return &CategoryService{
-		db: database.GetDB(),
+		db:     db,
+		counts: GetCountCache(),
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *CategoryService) WithContext(ctx context.Context) *CategoryService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
 // CreateCategory creates a new category
 func (s *CategoryService) CreateCategory(category *models.Category) error {
-	if err := s.db.Create(category).Error; err != nil {
-		return fmt.Errorf("failed to create category: %w", err)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkParent(tx, category.ID, category.ParentID); err != nil {
+			return err
+		}
+		if err := checkNameAvailable(tx, category.Name, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Create(category).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("category with this name already exists")
+			}
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+		return events.Record(tx, events.CategoryCreated, events.AggregateCategory, category.ID, category)
+	})
+	if err != nil {
+		return err
 	}
+	s.counts.Invalidate("categories")
 	return nil
 }
 
@@ -38,16 +74,32 @@ func (s *CategoryService) GetCategoryByID(id uuid.UUID) (*models.Category, error
 		}
 		return nil, fmt.Errorf("failed to get category: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(category.Books)); err != nil {
+		return nil, err
+	}
 	return &category, nil
 }
 
+// GetCategoryBySlug retrieves a category by its current slug or one it had before
+// being renamed
+func (s *CategoryService) GetCategoryBySlug(value string) (*models.Category, error) {
+	id, err := slugID(s.db, &models.Category{}, "categories", value)
+	if err != nil {
+		return nil, err
+	}
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("category not found")
+	}
+	return s.GetCategoryByID(id)
+}
+
 // GetAllCategories retrieves all categories with pagination
 func (s *CategoryService) GetAllCategories(page, limit int) ([]models.Category, int64, error) {
 	var categories []models.Category
-	var total int64
 
 	// Count total records
-	if err := s.db.Model(&models.Category{}).Count(&total).Error; err != nil {
+	total, err := s.counts.CountTable(s.db, "categories", &models.Category{})
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count categories: %w", err)
 	}
 
@@ -55,37 +107,211 @@ func (s *CategoryService) GetAllCategories(page, limit int) ([]models.Category,
 	offset := (page - 1) * limit
 
 	// Get categories with pagination
-	if err := s.db.Preload("Books").Offset(offset).Limit(limit).Find(&categories).Error; err != nil {
+	if err := s.db.Offset(offset).Limit(limit).Find(&categories).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get categories: %w", err)
 	}
+	if err := setCategoryBooksCounts(s.db, categories); err != nil {
+		return nil, 0, err
+	}
 
 	return categories, total, nil
 }
 
 // UpdateCategory updates an existing category
 func (s *CategoryService) UpdateCategory(id uuid.UUID, updates *models.Category) error {
-	result := s.db.Model(&models.Category{}).Where("id = ?", id).Updates(updates)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update category: %w", result.Error)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if updates.Name != "" {
+			if err := checkNameAvailable(tx, updates.Name, id); err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&models.Category{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("category with this name already exists")
+			}
+			return fmt.Errorf("failed to update category: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("category not found")
+		}
+
+		var category models.Category
+		if err := tx.First(&category, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+		categorySlug, err := slug.Rename(tx, "categories", category.TenantID, category.ID, category.Slug, category.Name)
+		if err != nil {
+			return err
+		}
+		category.Slug = categorySlug
+		return events.Record(tx, events.CategoryUpdated, events.AggregateCategory, id, &category)
+	})
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("category not found")
+	s.counts.Invalidate("categories")
+	return nil
+}
+
+// MoveCategory moves a category under another, or to the top level when
+// parentID is nil. A category cannot be moved under one of its descendants.
+func (s *CategoryService) MoveCategory(id uuid.UUID, parentID *uuid.UUID) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.First(&category, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("category not found")
+			}
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+		if err := checkParent(tx, id, parentID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&category).Update("parent_id", parentID).Error; err != nil {
+			return fmt.Errorf("failed to move category: %w", err)
+		}
+		category.ParentID = parentID
+		return events.Record(tx, events.CategoryUpdated, events.AggregateCategory, id, &category)
+	})
+	if err != nil {
+		return err
 	}
+	s.counts.Invalidate("categories")
+	// Counts of books in a category's subtree change with its shape
+	s.counts.Invalidate("books")
 	return nil
 }
 
 // DeleteCategory soft deletes a category
 func (s *CategoryService) DeleteCategory(id uuid.UUID) error {
-	result := s.db.Delete(&models.Category{}, "id = ?", id)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete category: %w", result.Error)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return fmt.Errorf("failed to count subcategories: %w", err)
+		}
+		if children > 0 {
+			return fmt.Errorf("category has subcategories")
+		}
+
+		result := tx.Delete(&models.Category{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete category: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("category not found")
+		}
+		return events.Record(tx, events.CategoryDeleted, events.AggregateCategory, id, events.DeletedPayload{ID: id})
+	})
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("category not found")
+	s.counts.Invalidate("categories")
+	return nil
+}
+
+// GetCategoryHierarchy retrieves every category, without books, for
+// BuildCategoryTree
+func (s *CategoryService) GetCategoryHierarchy() ([]models.Category, error) {
+	var categories []models.Category
+	if err := s.db.Order("name").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	return categories, nil
+}
+
+// BuildCategoryTree nests categories under their parents, returning the
+// top-level ones. Siblings are sorted by name. Categories whose parent is not
+// in the list are returned at the top level.
+func BuildCategoryTree(categories []models.Category) []*CategoryNode {
+	nodes := make(map[uuid.UUID]*CategoryNode, len(categories))
+	ordered := make([]*CategoryNode, len(categories))
+	for i := range categories {
+		node := &CategoryNode{Category: categories[i], Children: []*CategoryNode{}}
+		nodes[node.ID] = node
+		ordered[i] = node
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Name < ordered[j].Name
+	})
+
+	roots := []*CategoryNode{}
+	for _, node := range ordered {
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok && parent != node {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// checkNameAvailable ensures no other category, including soft-deleted ones, uses the name
+func checkNameAvailable(tx *gorm.DB, name string, excludeID uuid.UUID) error {
+	var count int64
+	if err := unrestricted(tx).Unscoped().Model(&models.Category{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check name: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("category with this name already exists")
 	}
 	return nil
 }
 
+// checkParent verifies that parentID, when set, names an existing category
+// that is neither category id nor one of its descendants
+func checkParent(tx *gorm.DB, id uuid.UUID, parentID *uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool)
+	for current := parentID; current != nil && !seen[*current]; {
+		if *current == id {
+			return fmt.Errorf("invalid category: a category cannot be moved under itself or its subcategories")
+		}
+		seen[*current] = true
+
+		var ancestor models.Category
+		if err := tx.Select("id", "parent_id").First(&ancestor, "id = ?", *current).Error; err != nil {
+			if err == gorm.ErrRecordNotFound && current == parentID {
+				return fmt.Errorf("invalid category: parent category not found")
+			}
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+		current = ancestor.ParentID
+	}
+	return nil
+}
+
+// categoryTreeIDs returns the ID of a category followed by the IDs of all its
+// descendants
+func categoryTreeIDs(db *gorm.DB, id uuid.UUID) ([]uuid.UUID, error) {
+	var categories []models.Category
+	if err := db.Select("id", "parent_id").Where("parent_id IS NOT NULL").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subcategories: %w", err)
+	}
+
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, category := range categories {
+		children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+	}
+
+	ids := []uuid.UUID{id}
+	seen := map[uuid.UUID]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
 // GetCategoryByName retrieves a category by name
 func (s *CategoryService) GetCategoryByName(name string) (*models.Category, error) {
 	var category models.Category
@@ -95,18 +321,21 @@ func (s *CategoryService) GetCategoryByName(name string) (*models.Category, erro
 		}
 		return nil, fmt.Errorf("failed to get category: %w", err)
 	}
+	if err := applyPromotions(s.db, bookRefs(category.Books)); err != nil {
+		return nil, err
+	}
 	return &category, nil
 }
 
 // SearchCategories searches categories by name or description
-func (s *CategoryService) SearchCategories(query string, page, limit int) ([]models.Category, int64, error) {
+func (s *CategoryService) SearchCategories(query SearchQuery, page, limit int) ([]models.Category, int64, error) {
 	var categories []models.Category
-	var total int64
 
-	searchQuery := "%" + query + "%"
+	searchQuery := query.Pattern()
 
 	// Count total records
-	if err := s.db.Model(&models.Category{}).Where("name ILIKE ? OR description ILIKE ?", searchQuery, searchQuery).Count(&total).Error; err != nil {
+	total, err := s.counts.Count("categories:search:"+query.String(), s.db.Model(&models.Category{}).Where("name ILIKE ? OR description ILIKE ?", searchQuery, searchQuery))
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count categories: %w", err)
 	}
 
@@ -114,9 +343,12 @@ func (s *CategoryService) SearchCategories(query string, page, limit int) ([]mod
 	offset := (page - 1) * limit
 
 	// Search categories with pagination
-	if err := s.db.Preload("Books").Where("name ILIKE ? OR description ILIKE ?", searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&categories).Error; err != nil {
+	if err := s.db.Where("name ILIKE ? OR description ILIKE ?", searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&categories).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to search categories: %w", err)
 	}
+	if err := setCategoryBooksCounts(s.db, categories); err != nil {
+		return nil, 0, err
+	}
 
 	return categories, total, nil
 }
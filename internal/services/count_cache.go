@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bookstore-api/internal/access"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/tenancy"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Count modes for paginated list queries
+const (
+	CountModeExact     = "exact"
+	CountModeCached    = "cached"
+	CountModeEstimated = "estimated"
+)
+
+const (
+	// minEstimatedCount is the planner estimate below which an exact count is cheap enough to run
+	minEstimatedCount = 10000
+	// maxCountCacheEntries bounds the number of cached counts (search queries create many keys)
+	maxCountCacheEntries = 1000
+)
+
+var (
+	countCache     *CountCache
+	countCacheOnce sync.Once
+)
+
+// countEntry is a cached count with its expiry time
+type countEntry struct {
+	total     int64
+	expiresAt time.Time
+}
+
+// CountCache caches COUNT(*) results used for pagination totals
+type CountCache struct {
+	mode    string
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]countEntry
+}
+
+// NewCountCache creates a new count cache
+func NewCountCache(mode string, ttl time.Duration) *CountCache {
+	switch mode {
+	case CountModeExact, CountModeCached, CountModeEstimated:
+	default:
+		log.Printf("Unknown pagination count mode %q, using %q", mode, CountModeExact)
+		mode = CountModeExact
+	}
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+
+	return &CountCache{
+		mode:    mode,
+		ttl:     ttl,
+		entries: make(map[string]countEntry),
+	}
+}
+
+// InitializeCountCache initializes the shared count cache from configuration
+func InitializeCountCache(cfg *config.Config) {
+	countCacheOnce.Do(func() {
+		countCache = NewCountCache(cfg.Pagination.CountMode, cfg.Pagination.CountCacheTTL)
+	})
+}
+
+// GetCountCache returns the shared count cache, defaulting to exact counts
+func GetCountCache() *CountCache {
+	countCacheOnce.Do(func() {
+		countCache = NewCountCache(CountModeExact, 0)
+	})
+	return countCache
+}
+
+// Count returns the number of rows matched by query. Keys are prefixed with the
+// table name (e.g. "books:author:<id>") so writes can invalidate them, and are
+// kept per tenant and, for principals whose access rules hide rows of the
+// table, per principal.
+func (c *CountCache) Count(key string, query *gorm.DB) (int64, error) {
+	if c.mode == CountModeExact {
+		var total int64
+		err := query.Count(&total).Error
+		return total, err
+	}
+
+	ctx := query.Statement.Context
+	table, _, _ := strings.Cut(key, ":")
+	if tenantID, ok := tenancy.TenantID(ctx); ok {
+		key += "@" + tenantID.String()
+	}
+	if scope := access.Scope(ctx, table); scope != "" {
+		key += "#" + scope
+	}
+
+	if total, ok := c.get(key); ok {
+		return total, nil
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	c.set(key, total)
+	return total, nil
+}
+
+// CountTable returns the number of rows in a table. In estimated mode the
+// planner's row estimate is used for large tables instead of a full scan; the
+// estimate covers every tenant and principal, so tenant-scoped counts and
+// counts restricted by access rules are cached instead.
+func (c *CountCache) CountTable(db *gorm.DB, table string, model interface{}) (int64, error) {
+	ctx := db.Statement.Context
+	_, scoped := tenancy.TenantID(ctx)
+	scoped = scoped || access.Scope(ctx, table) != ""
+	if c.mode == CountModeEstimated && !scoped {
+		var estimate int64
+		err := db.Raw("SELECT reltuples::bigint FROM pg_class WHERE relname = ?", table).Scan(&estimate).Error
+		if err == nil && estimate >= minEstimatedCount {
+			return estimate, nil
+		}
+	}
+	return c.Count(table, db.Model(model))
+}
+
+// Invalidate drops all cached counts for a table, for every tenant and
+// principal
+func (c *CountCache) Invalidate(table string) {
+	if c.mode == CountModeExact {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		base := key
+		if i := strings.IndexAny(key, "@#"); i >= 0 {
+			base = key[:i]
+		}
+		if base == table || strings.HasPrefix(base, table+":") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Flush drops every cached count
+func (c *CountCache) Flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushed := len(c.entries)
+	c.entries = make(map[string]countEntry)
+	return flushed
+}
+
+// get returns a cached count if present and not expired
+func (c *CountCache) get(key string) (int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.total, true
+}
+
+// set stores a count, evicting expired entries when the cache is full
+func (c *CountCache) set(key string, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCountCacheEntries {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCountCacheEntries {
+			c.entries = make(map[string]countEntry)
+		}
+	}
+
+	c.entries[key] = countEntry{
+		total:     total,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
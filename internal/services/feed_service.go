@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/storage"
+	"bookstore-api/internal/storefront"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// feedBatchSize is the number of books read at a time for the product feed
+const feedBatchSize = 500
+
+// FeedService generates the storefront product feeds of the context's tenant
+// and keeps them in storage until they are next generated
+type FeedService struct {
+	db               *gorm.DB
+	ctx              context.Context
+	store            storage.Storage
+	links            *storefront.Links
+	currency         string
+	newReleasesLimit int
+}
+
+// NewFeedService creates a new feed service keeping feeds under the
+// configured directory
+func NewFeedService(db *gorm.DB, cfg *config.Config) *FeedService {
+	return &FeedService{
+		db:               db,
+		ctx:              context.Background(),
+		store:            storage.NewLocal(cfg.Feeds.Dir, ""),
+		links:            storefront.NewLinks(cfg.Storefront),
+		currency:         cfg.Storefront.Currency,
+		newReleasesLimit: cfg.Feeds.NewReleasesLimit,
+	}
+}
+
+// WithContext returns a copy of the service whose queries and storage calls
+// run with ctx, which carries the tenant they are scoped to
+func (s *FeedService) WithContext(ctx context.Context) *FeedService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// GenerateFeeds renders every feed of the tenant from its active books and
+// stores them, returning the number of books in each
+func (s *FeedService) GenerateFeeds() (map[string]int, error) {
+	tenant := tenancy.FromContext(s.ctx)
+	if tenant == nil {
+		return nil, fmt.Errorf("failed to generate feeds: no tenant")
+	}
+	now := time.Now()
+
+	products, err := s.productItems(tenant)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := s.newReleaseItems(tenant, now)
+	if err != nil {
+		return nil, err
+	}
+
+	base := s.links.BaseURL(tenant)
+	catalog := storefront.Channel{
+		Title:       tenant.Name,
+		Description: tenant.Name + " catalog",
+		Link:        base,
+		Currency:    s.currency,
+		Updated:     now,
+	}
+	newReleases := storefront.Channel{
+		ID:          fmt.Sprintf("urn:bookstore:%s:new-releases", tenant.Slug),
+		Title:       tenant.Name + " new releases",
+		Description: "The latest books published at " + tenant.Name,
+		Link:        base,
+		Currency:    s.currency,
+		Updated:     now,
+	}
+
+	counts := make(map[string]int, len(storefront.Feeds))
+	for _, feed := range []struct {
+		name   string
+		render func(storefront.Channel, []storefront.Item) ([]byte, error)
+		ch     storefront.Channel
+		items  []storefront.Item
+	}{
+		{storefront.FeedGoogleMerchant, storefront.GoogleMerchant, catalog, products},
+		{storefront.FeedNewReleasesRSS, storefront.RSS, newReleases, releases},
+		{storefront.FeedNewReleasesAtom, storefront.Atom, newReleases, releases},
+	} {
+		data, err := feed.render(feed.ch, feed.items)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.store.Put(s.ctx, feedKey(tenant, feed.name), storefront.ContentType(feed.name), data); err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", feed.name, err)
+		}
+		counts[feed.name] = len(feed.items)
+	}
+	return counts, nil
+}
+
+// OpenFeed opens a stored feed of the tenant, generating the tenant's feeds
+// first when they have not been generated yet
+func (s *FeedService) OpenFeed(name string) (io.ReadSeekCloser, error) {
+	if !storefront.IsFeed(name) {
+		return nil, fmt.Errorf("feed not found")
+	}
+	tenant := tenancy.FromContext(s.ctx)
+	if tenant == nil {
+		return nil, fmt.Errorf("feed not found")
+	}
+
+	file, err := s.store.Open(s.ctx, feedKey(tenant, name))
+	if errors.Is(err, os.ErrNotExist) {
+		if _, err := s.GenerateFeeds(); err != nil {
+			return nil, err
+		}
+		file, err = s.store.Open(s.ctx, feedKey(tenant, name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// productItems returns the tenant's active books, with their prices under
+// current promotions
+func (s *FeedService) productItems(tenant *models.Tenant) ([]storefront.Item, error) {
+	items := []storefront.Item{}
+	var batch []models.Book
+	err := s.feedBooks().FindInBatches(&batch, feedBatchSize, func(tx *gorm.DB, _ int) error {
+		if err := applyPromotions(s.db, bookRefs(batch)); err != nil {
+			return err
+		}
+		for i := range batch {
+			items = append(items, s.feedItem(tenant, &batch[i]))
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+	return items, nil
+}
+
+// newReleaseItems returns the tenant's most recently published active books
+func (s *FeedService) newReleaseItems(tenant *models.Tenant, now time.Time) ([]storefront.Item, error) {
+	var books []models.Book
+	if err := s.feedBooks().
+		Where("books.published_at <= ?", now.Format("2006-01-02")).
+		Order("books.published_at DESC, books.created_at DESC").
+		Limit(s.newReleasesLimit).
+		Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("failed to get new releases: %w", err)
+	}
+
+	items := make([]storefront.Item, 0, len(books))
+	for i := range books {
+		items = append(items, s.feedItem(tenant, &books[i]))
+	}
+	return items, nil
+}
+
+// feedBooks selects the tenant's active books with their author, category,
+// and publisher
+func (s *FeedService) feedBooks() *gorm.DB {
+	return s.db.Model(&models.Book{}).
+		Where("books.status = ?", models.BookStatusActive).
+		Preload("Author").Preload("Category").Preload("Publisher")
+}
+
+// feedItem describes a book for the feeds
+func (s *FeedService) feedItem(tenant *models.Tenant, book *models.Book) storefront.Item {
+	item := storefront.Item{
+		ISBN:        book.ISBN,
+		Title:       book.Title,
+		Description: book.Description,
+		Link:        s.links.Book(tenant, book.Slug),
+		Author:      book.Author.Name,
+		Category:    book.Category.Name,
+		Price:       book.Price,
+		InStock:     book.Stock > 0,
+		UpdatedAt:   book.UpdatedAt,
+	}
+	if book.Publisher != nil {
+		item.Publisher = book.Publisher.Name
+	}
+	if book.PublishedAt != nil {
+		published := book.PublishedAt.Time
+		item.PublishedAt = &published
+	}
+	if book.Promotion != nil && book.EffectivePrice != nil && *book.EffectivePrice < book.Price {
+		item.SalePrice = book.EffectivePrice
+		item.SaleEndsAt = book.Promotion.EndsAt
+	}
+	return item
+}
+
+// feedKey returns the storage key of a tenant's feed
+func feedKey(tenant *models.Tenant, name string) string {
+	return tenant.ID.String() + "/" + name
+}
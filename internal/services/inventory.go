@@ -0,0 +1,261 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Inventory modes
+const (
+	// InventoryModeState writes stock in place
+	InventoryModeState = "state"
+	// InventoryModeEventSourced appends every stock change to the stock
+	// movement log and keeps books.stock as its projection
+	InventoryModeEventSourced = "event_sourced"
+)
+
+var (
+	inventoryMode     string
+	inventoryModeOnce sync.Once
+)
+
+// InitializeInventory sets the inventory mode from configuration
+func InitializeInventory(cfg *config.Config) error {
+	switch cfg.Inventory.Mode {
+	case InventoryModeState, InventoryModeEventSourced:
+	default:
+		return fmt.Errorf("unknown inventory mode %q", cfg.Inventory.Mode)
+	}
+	inventoryModeOnce.Do(func() {
+		inventoryMode = cfg.Inventory.Mode
+	})
+	return nil
+}
+
+// EventSourcedInventory reports whether stock changes are recorded as stock
+// movements, defaulting to the state mode when the inventory mode was not
+// initialized
+func EventSourcedInventory() bool {
+	inventoryModeOnce.Do(func() {
+		inventoryMode = InventoryModeState
+	})
+	return inventoryMode == InventoryModeEventSourced
+}
+
+// AdjustBookStock changes a book's stock by delta, which may be negative, and
+// returns the movement recorded. The change is applied to the current stock
+// in the database, so concurrent adjustments never overwrite each other. It
+// requires the event-sourced inventory mode.
+func (s *BookService) AdjustBookStock(id uuid.UUID, delta int, note string) (*models.StockMovement, error) {
+	if !EventSourcedInventory() {
+		return nil, fmt.Errorf("stock adjustments require event-sourced inventory")
+	}
+	if delta == 0 {
+		return nil, fmt.Errorf("delta must not be zero")
+	}
+
+	var movement *models.StockMovement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		movement, err = applyStockMovement(tx, id, delta, models.StockReasonAdjustment, note)
+		if err != nil {
+			return err
+		}
+		return events.Record(tx, events.BookStockChanged, events.AggregateBook, id, events.StockChangedPayload{
+			BookID:        id,
+			PreviousStock: movement.StockAfter - movement.Delta,
+			Stock:         movement.StockAfter,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// GetStockMovements retrieves a book's stock movements, newest first, with pagination
+func (s *BookService) GetStockMovements(bookID uuid.UUID, page, limit int) ([]models.StockMovement, int64, error) {
+	if err := s.checkBookExists(bookID); err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	query := s.db.Model(&models.StockMovement{}).Where("book_id = ?", bookID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+
+	var movements []models.StockMovement
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&movements).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get stock movements: %w", err)
+	}
+
+	return movements, total, nil
+}
+
+// allocatedStock is an SQL expression summing the stock of the book row held
+// at warehouses
+const allocatedStock = "(SELECT COALESCE(SUM(quantity), 0) FROM warehouse_stocks WHERE warehouse_stocks.book_id = books.id)"
+
+// applyStockMovement adds delta to a book's stock projection and appends the
+// movement to the log. The stock is changed with an increment rather than
+// written, so it does not depend on a value read earlier, and a change that
+// would take it below zero, or below the stock held at warehouses, is
+// refused.
+func applyStockMovement(tx *gorm.DB, bookID uuid.UUID, delta int, reason, note string) (*models.StockMovement, error) {
+	book, err := incrementStock(tx, bookID, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := &models.StockMovement{
+		TenantID:   book.TenantID,
+		BookID:     bookID,
+		Delta:      delta,
+		StockAfter: book.Stock,
+		Reason:     reason,
+		Note:       note,
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return nil, fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return movement, nil
+}
+
+// incrementStock adds delta to a book's stock, refusing a change that would
+// take it below the stock held at warehouses, and returns the book's tenant
+// and new stock
+func incrementStock(tx *gorm.DB, bookID uuid.UUID, delta int) (*models.Book, error) {
+	var book models.Book
+	result := tx.Model(&book).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "tenant_id"}, {Name: "stock"}}}).
+		Where("id = ? AND stock + ? >= "+allocatedStock, bookID, delta).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update book stock: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := tx.Model(&models.Book{}).Where("id = ?", bookID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to get book: %w", err)
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("insufficient stock")
+	}
+	return &book, nil
+}
+
+// changeStock adds delta to a book's stock in either inventory mode,
+// recording a movement in the event-sourced mode, and records the change
+func changeStock(tx *gorm.DB, bookID uuid.UUID, delta int, reason, note string) error {
+	var stock int
+	if EventSourcedInventory() {
+		movement, err := applyStockMovement(tx, bookID, delta, reason, note)
+		if err != nil {
+			return err
+		}
+		stock = movement.StockAfter
+	} else {
+		book, err := incrementStock(tx, bookID, delta)
+		if err != nil {
+			return err
+		}
+		stock = book.Stock
+	}
+	return events.Record(tx, events.BookStockChanged, events.AggregateBook, bookID, events.StockChangedPayload{
+		BookID:        bookID,
+		PreviousStock: stock - delta,
+		Stock:         stock,
+	})
+}
+
+// checkAllocatedStock refuses to set a book's stock below the stock held at
+// warehouses
+func checkAllocatedStock(tx *gorm.DB, bookID uuid.UUID, stock int) error {
+	var allocated int
+	if err := tx.Model(&models.WarehouseStock{}).Select("COALESCE(SUM(quantity), 0)").Where("book_id = ?", bookID).Scan(&allocated).Error; err != nil {
+		return fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+	if stock < allocated {
+		return fmt.Errorf("insufficient stock")
+	}
+	return nil
+}
+
+// recordInitialStock appends the stock a new book was created with to the
+// log in the event-sourced inventory mode
+func recordInitialStock(tx *gorm.DB, book *models.Book, reason string) error {
+	if !EventSourcedInventory() || book.Stock == 0 {
+		return nil
+	}
+	movement := &models.StockMovement{
+		TenantID:   book.TenantID,
+		BookID:     book.ID,
+		Delta:      book.Stock,
+		StockAfter: book.Stock,
+		Reason:     reason,
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return nil
+}
+
+// RebuildStock recomputes the stock of every book, in every tenant, as the sum
+// of its stock movements, and returns the number of books whose stock was
+// corrected. New movements wait until the rebuild commits.
+func RebuildStock(db *gorm.DB) (int64, error) {
+	var rebuilt int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE stock_movements IN SHARE MODE").Error; err != nil {
+			return fmt.Errorf("failed to lock stock movements: %w", err)
+		}
+		result := tx.Exec(`UPDATE books SET stock = projected.stock, updated_at = CURRENT_TIMESTAMP
+FROM (
+	SELECT b.id, COALESCE(SUM(m.delta), 0) AS stock
+	FROM books b LEFT JOIN stock_movements m ON m.book_id = b.id
+	GROUP BY b.id
+) projected
+WHERE books.id = projected.id AND books.stock <> projected.stock`)
+		if result.Error != nil {
+			return fmt.Errorf("failed to rebuild stock: %w", result.Error)
+		}
+		rebuilt = result.RowsAffected
+		return nil
+	})
+	return rebuilt, err
+}
+
+// ReconcileStock appends a reconciliation movement for every book, in every
+// tenant, whose stock differs from the sum of its movements, so the log
+// accounts for changes made in the state inventory mode. It returns the number
+// of movements appended.
+func ReconcileStock(db *gorm.DB) (int64, error) {
+	var reconciled int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE books IN SHARE MODE").Error; err != nil {
+			return fmt.Errorf("failed to lock books: %w", err)
+		}
+		result := tx.Exec(`INSERT INTO stock_movements (tenant_id, book_id, delta, stock_after, reason)
+SELECT b.tenant_id, b.id, b.stock - COALESCE(SUM(m.delta), 0), b.stock, ?
+FROM books b LEFT JOIN stock_movements m ON m.book_id = b.id
+GROUP BY b.id, b.tenant_id, b.stock
+HAVING b.stock <> COALESCE(SUM(m.delta), 0)`, models.StockReasonReconciliation)
+		if result.Error != nil {
+			return fmt.Errorf("failed to reconcile stock: %w", result.Error)
+		}
+		reconciled = result.RowsAffected
+		return nil
+	})
+	return reconciled, err
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// listingBatchSize is the number of books refreshed per query when a change
+// touches many listings
+const listingBatchSize = 500
+
+// ListingService maintains and reads the book_listings read model, which
+// serves storefront listings from one table instead of preloading authors,
+// categories, and promotions per request
+type ListingService struct {
+	db     *gorm.DB
+	counts *CountCache
+	// status is the status of the books listed, or empty for books of every
+	// status
+	status string
+}
+
+// NewListingService creates a new listing service listing active books
+func NewListingService(db *gorm.DB) *ListingService {
+	return &ListingService{
+		db:     db,
+		counts: GetCountCache(),
+		status: models.BookStatusActive,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *ListingService) WithContext(ctx context.Context) *ListingService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// WithStatus returns a copy of the service listing only books with status,
+// or books of every status when status is empty
+func (s *ListingService) WithStatus(status string) *ListingService {
+	clone := *s
+	clone.status = status
+	return &clone
+}
+
+// GetListings retrieves a page of the listings of books with the status the
+// service lists as books, oldest first
+func (s *ListingService) GetListings(page, limit int) ([]models.Book, int64, error) {
+	query := s.db.Model(&models.BookListing{})
+	var total int64
+	var err error
+	if s.status == "" {
+		total, err = s.counts.CountTable(s.db, "books", &models.Book{})
+	} else {
+		query = query.Where("status = ?", s.status)
+		total, err = s.counts.Count("books:status:"+s.status, s.db.Model(&models.Book{}).Where("status = ?", s.status))
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count books: %w", err)
+	}
+
+	var listings []models.BookListing
+	offset := (page - 1) * limit
+	if err := query.Order("created_at, book_id").Offset(offset).Limit(limit).Find(&listings).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get book listings: %w", err)
+	}
+
+	books := make([]models.Book, len(listings))
+	for i := range listings {
+		books[i] = listings[i].Book()
+	}
+	return books, total, nil
+}
+
+// RefreshBooks rewrites the listings of the books from their current state,
+// removing those of books that were deleted
+func (s *ListingService) RefreshBooks(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var books []models.Book
+	if err := s.db.Preload("Author").Preload("Category").Where("id IN ?", ids).Find(&books).Error; err != nil {
+		return fmt.Errorf("failed to get books: %w", err)
+	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return err
+	}
+	ratings, err := ratingSummaries(s.db, ids)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	listings := make([]models.BookListing, len(books))
+	found := make(map[uuid.UUID]bool, len(books))
+	for i := range books {
+		listings[i] = newListing(&books[i], ratings[books[i].ID], now)
+		found[books[i].ID] = true
+	}
+	var gone []uuid.UUID
+	for _, id := range ids {
+		if !found[id] {
+			gone = append(gone, id)
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(listings) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "book_id"}},
+				UpdateAll: true,
+			}).Create(&listings).Error; err != nil {
+				return fmt.Errorf("failed to write book listings: %w", err)
+			}
+		}
+		if len(gone) > 0 {
+			if err := tx.Where("book_id IN ?", gone).Delete(&models.BookListing{}).Error; err != nil {
+				return fmt.Errorf("failed to remove book listings: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// RefreshAuthor refreshes the listings of the author's books
+func (s *ListingService) RefreshAuthor(authorID uuid.UUID) error {
+	return s.refreshWhere("author_id = ?", authorID)
+}
+
+// RefreshCategory refreshes the listings of the books in the category
+func (s *ListingService) RefreshCategory(categoryID uuid.UUID) error {
+	return s.refreshWhere("category_id = ?", categoryID)
+}
+
+// Rebuild refreshes the listing of every book in the tenant and removes
+// listings left by deleted books. It returns the number of books listed.
+func (s *ListingService) Rebuild() (int, error) {
+	var ids []uuid.UUID
+	if err := s.db.Model(&models.Book{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to get books: %w", err)
+	}
+	if err := s.refreshBatches(ids); err != nil {
+		return 0, err
+	}
+
+	if err := s.db.Where("NOT EXISTS (SELECT 1 FROM books WHERE books.id = book_listings.book_id AND books.deleted_at IS NULL)").
+		Delete(&models.BookListing{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to remove book listings: %w", err)
+	}
+	return len(ids), nil
+}
+
+// refreshWhere refreshes the listings of the books matching the condition
+func (s *ListingService) refreshWhere(query string, args ...interface{}) error {
+	var ids []uuid.UUID
+	if err := s.db.Model(&models.Book{}).Where(query, args...).Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to get books: %w", err)
+	}
+	return s.refreshBatches(ids)
+}
+
+// refreshBatches refreshes the listings of the books listingBatchSize at a time
+func (s *ListingService) refreshBatches(ids []uuid.UUID) error {
+	for start := 0; start < len(ids); start += listingBatchSize {
+		end := min(start+listingBatchSize, len(ids))
+		if err := s.RefreshBooks(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ratingSummary is the average and number of a book's ratings
+type ratingSummary struct {
+	BookID  uuid.UUID
+	Average float64
+	Count   int64
+}
+
+// ratingSummaries returns the rating summaries of the books that have
+// ratings
+func ratingSummaries(db *gorm.DB, ids []uuid.UUID) (map[uuid.UUID]ratingSummary, error) {
+	var rows []ratingSummary
+	if err := db.Table("book_ratings").
+		Select("book_id, AVG(rating) AS average, COUNT(*) AS count").
+		Where("book_id IN ? AND deleted_at IS NULL", ids).
+		Group("book_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ratings: %w", err)
+	}
+
+	summaries := make(map[uuid.UUID]ratingSummary, len(rows))
+	for _, row := range rows {
+		summaries[row.BookID] = row
+	}
+	return summaries, nil
+}
+
+// newListing builds the listing of a book loaded with its author, category,
+// and effective price
+func newListing(book *models.Book, rating ratingSummary, now time.Time) models.BookListing {
+	listing := models.BookListing{
+		BookID:         book.ID,
+		TenantID:       book.TenantID,
+		Title:          book.Title,
+		Slug:           book.Slug,
+		ShortCode:      book.ShortCode,
+		ISBN:           book.ISBN,
+		Description:    book.Description,
+		Price:          book.Price,
+		Stock:          book.Stock,
+		PublishedAt:    book.PublishedAt,
+		PublisherID:    book.PublisherID,
+		Status:         book.Status,
+		AuthorID:       book.AuthorID,
+		AuthorName:     book.Author.Name,
+		AuthorSlug:     book.Author.Slug,
+		CategoryID:     book.CategoryID,
+		CategoryName:   book.Category.Name,
+		CategorySlug:   book.Category.Slug,
+		RatingsCount:   rating.Count,
+		EffectivePrice: book.Price,
+		CreatedAt:      book.CreatedAt,
+		UpdatedAt:      book.UpdatedAt,
+		RefreshedAt:    now,
+	}
+	if rating.Count > 0 {
+		average := rating.Average
+		listing.AverageRating = &average
+	}
+	if book.EffectivePrice != nil {
+		listing.EffectivePrice = *book.EffectivePrice
+	}
+	if book.Promotion != nil {
+		listing.PromotionID = &book.Promotion.ID
+		listing.PromotionName = book.Promotion.Name
+		listing.PromotionEndsAt = &book.Promotion.EndsAt
+	}
+	return listing
+}
@@ -0,0 +1,14 @@
+package services_test
+
+import (
+	"bookstore-api/internal/testutil"
+	"os"
+	"testing"
+)
+
+// env is the migrated database the services run against
+var env *testutil.Env
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.Main(m, &env))
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceService handles housekeeping operations on the catalog
+type MaintenanceService struct {
+	db     *gorm.DB
+	counts *CountCache
+}
+
+// NewMaintenanceService creates a new maintenance service
+func NewMaintenanceService(db *gorm.DB) *MaintenanceService {
+	return &MaintenanceService{
+		db:     db,
+		counts: GetCountCache(),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *MaintenanceService) WithContext(ctx context.Context) *MaintenanceService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// PurgeSoftDeleted permanently removes rows soft deleted before the cutoff.
+// Authors and categories still referenced by books are kept, and translations
+// of purged books and categories are removed with them.
+func (s *MaintenanceService) PurgeSoftDeleted(before time.Time) (map[string]int64, error) {
+	purged := make(map[string]int64)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.Book{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to purge books: %w", result.Error)
+		}
+		purged["books"] = result.RowsAffected
+
+		result = tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Where("NOT EXISTS (SELECT 1 FROM books WHERE books.author_id = authors.id)").
+			Delete(&models.Author{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to purge authors: %w", result.Error)
+		}
+		purged["authors"] = result.RowsAffected
+
+		result = tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Where("NOT EXISTS (SELECT 1 FROM books WHERE books.category_id = categories.id)").
+			Delete(&models.Category{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to purge categories: %w", result.Error)
+		}
+		purged["categories"] = result.RowsAffected
+
+		// Translations have no foreign key, so drop those whose book or category is gone
+		result = tx.Where("(entity_type = ? AND NOT EXISTS (SELECT 1 FROM books WHERE books.id = translations.entity_id)) OR "+
+			"(entity_type = ? AND NOT EXISTS (SELECT 1 FROM categories WHERE categories.id = translations.entity_id))",
+			models.TranslationEntityBook, models.TranslationEntityCategory).
+			Delete(&models.Translation{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to purge translations: %w", result.Error)
+		}
+		purged["translations"] = result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for table := range purged {
+		s.counts.Invalidate(table)
+	}
+	return purged, nil
+}
+
+// SearchTables are the tables whose indexes serve catalog search
+var SearchTables = []string{"books", "authors", "categories", "translations"}
+
+// ReindexSearch rebuilds the indexes of the tables searched by the catalog,
+// refreshes their planner statistics, and drops cached counts. It covers every
+// tenant and returns the tables reindexed.
+func (s *MaintenanceService) ReindexSearch() ([]string, error) {
+	for _, table := range SearchTables {
+		if err := s.ReindexTable(table); err != nil {
+			return nil, err
+		}
+	}
+	return SearchTables, nil
+}
+
+// ReindexTable rebuilds the indexes of one table for every tenant, refreshes
+// its planner statistics, and drops its cached counts
+func (s *MaintenanceService) ReindexTable(table string) error {
+	// CONCURRENTLY keeps the table writable while its indexes are rebuilt; it
+	// cannot run inside a transaction
+	if err := s.db.Exec("REINDEX TABLE CONCURRENTLY " + table).Error; err != nil {
+		return fmt.Errorf("failed to reindex %s: %w", table, err)
+	}
+	if err := s.db.Exec("ANALYZE " + table).Error; err != nil {
+		return fmt.Errorf("failed to analyze %s: %w", table, err)
+	}
+	s.counts.Invalidate(table)
+	return nil
+}
+
+// RebuildStock recomputes every tenant's stock projections from the stock
+// movement log and returns the number of books corrected
+func (s *MaintenanceService) RebuildStock() (int64, error) {
+	return RebuildStock(s.db)
+}
+
+// RefreshStatsViews recomputes the materialized views behind the aggregate
+// statistics for every tenant and returns the views refreshed
+func (s *MaintenanceService) RefreshStatsViews() ([]string, error) {
+	return RefreshStatsViews(s.db)
+}
+
+// CreatePartitions creates the monthly partitions of the partitioned tables
+// through ahead months from now and returns the partitions created
+func (s *MaintenanceService) CreatePartitions(ahead int) ([]string, error) {
+	return CreatePartitions(s.db, time.Now(), ahead)
+}
+
+// GetPartitions lists the partitions of the partitioned tables
+func (s *MaintenanceService) GetPartitions() ([]Partition, error) {
+	return GetPartitions(s.db)
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxPushTokens is the number of devices a user can receive push
+// notifications on
+const maxPushTokens = 10
+
+// slackWebhookPrefix is the prefix of Slack incoming webhook URLs
+const slackWebhookPrefix = "https://hooks.slack.com/"
+
+// phonePattern matches E.164 phone numbers, as Twilio expects them
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NotificationSettingsUpdate changes a user's notification settings. Nil
+// fields are left as they are; topics missing from Channels keep their
+// channels. Empty contact details remove them.
+type NotificationSettingsUpdate struct {
+	Channels        map[string][]string
+	Phone           *string
+	PushTokens      []string
+	SlackWebhookURL *string
+}
+
+// NotificationService manages the channels users receive notifications over
+type NotificationService struct {
+	db *gorm.DB
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *NotificationService) WithContext(ctx context.Context) *NotificationService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// GetSettings returns the user's notification settings with the channels of
+// every topic the user can receive, defaults included
+func (s *NotificationService) GetSettings(userID uuid.UUID) (*models.NotificationSettings, error) {
+	user, err := s.getUser(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := getNotificationSettings(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return withDefaultChannels(settings, user), nil
+}
+
+// UpdateSettings validates and saves changes to the user's notification
+// settings
+func (s *NotificationService) UpdateSettings(userID uuid.UUID, update NotificationSettingsUpdate) (*models.NotificationSettings, error) {
+	var settings *models.NotificationSettings
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		current, err := getNotificationSettings(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if err != nil {
+			return err
+		}
+
+		topics := notificationTopics(user)
+		for topic, channels := range update.Channels {
+			if !slices.Contains(topics, topic) {
+				return fmt.Errorf("invalid notification settings: unknown topic %q", topic)
+			}
+			unique := []string{}
+			for _, channel := range channels {
+				if !slices.Contains(models.NotificationChannels, channel) {
+					return fmt.Errorf("invalid notification settings: unknown channel %q", channel)
+				}
+				if !slices.Contains(unique, channel) {
+					unique = append(unique, channel)
+				}
+			}
+			if current.Channels == nil {
+				current.Channels = models.TopicChannels{}
+			}
+			current.Channels[topic] = unique
+		}
+
+		if update.Phone != nil {
+			phone := strings.ReplaceAll(strings.TrimSpace(*update.Phone), " ", "")
+			if phone != "" && !phonePattern.MatchString(phone) {
+				return fmt.Errorf("invalid notification settings: phone must be an E.164 number such as +14155550123")
+			}
+			current.Phone = phone
+		}
+		if update.PushTokens != nil {
+			tokens := models.StringList{}
+			for _, token := range update.PushTokens {
+				token = strings.TrimSpace(token)
+				if token == "" || tokens.Contains(token) {
+					continue
+				}
+				if len(token) > 4096 {
+					return fmt.Errorf("invalid notification settings: push tokens must be at most 4096 characters")
+				}
+				tokens = append(tokens, token)
+			}
+			if len(tokens) > maxPushTokens {
+				return fmt.Errorf("invalid notification settings: at most %d push tokens are allowed", maxPushTokens)
+			}
+			current.PushTokens = tokens
+		}
+		if update.SlackWebhookURL != nil {
+			url := strings.TrimSpace(*update.SlackWebhookURL)
+			if url != "" && (!strings.HasPrefix(url, slackWebhookPrefix) || len(url) > 2048) {
+				return fmt.Errorf("invalid notification settings: slack_webhook_url must be a Slack incoming webhook URL")
+			}
+			current.SlackWebhookURL = url
+		}
+
+		if err := tx.Omit(clause.Associations).Save(current).Error; err != nil {
+			return fmt.Errorf("failed to save notification settings: %w", err)
+		}
+		settings = withDefaultChannels(current, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetAdminIDs returns the IDs of the tenant's admins, who receive the admin
+// notification topics
+func (s *NotificationService) GetAdminIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := s.db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get admins: %w", err)
+	}
+	return ids, nil
+}
+
+// getUser returns the user whose settings are read or changed
+func (s *NotificationService) getUser(db *gorm.DB, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := db.Select("id", "role").First(&user, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// notificationTopics returns the topics the user can receive
+func notificationTopics(user *models.User) []string {
+	if user.Role == models.RoleAdmin {
+		return append(slices.Clone(models.NotificationTopics), models.AdminNotificationTopics...)
+	}
+	return models.NotificationTopics
+}
+
+// withDefaultChannels returns a copy of settings listing the channels of
+// every topic the user can receive, and only those
+func withDefaultChannels(settings *models.NotificationSettings, user *models.User) *models.NotificationSettings {
+	clone := *settings
+	clone.Channels = models.TopicChannels{}
+	for _, topic := range notificationTopics(user) {
+		clone.Channels[topic] = settings.ChannelsFor(topic)
+	}
+	if clone.PushTokens == nil {
+		clone.PushTokens = models.StringList{}
+	}
+	return &clone
+}
+
+// getNotificationSettings returns the user's stored notification settings or
+// the defaults
+func getNotificationSettings(db *gorm.DB, userID uuid.UUID) (*models.NotificationSettings, error) {
+	var settings models.NotificationSettings
+	if err := db.First(&settings, "user_id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &models.NotificationSettings{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	return &settings, nil
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/onix"
+	"bookstore-api/internal/slug"
+	"bookstore-api/internal/utils"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ONIX import outcomes
+const (
+	ONIXCreated = "created"
+	ONIXUpdated = "updated"
+	ONIXDeleted = "deleted"
+	ONIXSkipped = "skipped"
+)
+
+// maxONIXReportErrors caps the number of product errors kept in a report
+const maxONIXReportErrors = 100
+
+// onixQueued is the outcome of a new product waiting in the batch of books to
+// create
+const onixQueued = "queued"
+
+// ONIXImportOptions controls an ONIX import
+type ONIXImportOptions struct {
+	DryRun bool
+	// BatchSize is the number of new books inserted per transaction
+	BatchSize int
+}
+
+// ONIXImportError describes a product that could not be imported
+type ONIXImportError struct {
+	RecordReference string `json:"record_reference,omitempty"`
+	ISBN            string `json:"isbn,omitempty"`
+	Error           string `json:"error"`
+}
+
+// ONIXImportReport summarizes an ONIX import
+type ONIXImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Products  int               `json:"products"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Deleted   int               `json:"deleted"`
+	Skipped   int               `json:"skipped"`
+	Failed    int               `json:"failed"`
+	Errors    []ONIXImportError `json:"errors"`
+	Truncated bool              `json:"errors_truncated,omitempty"`
+	// BatchErrors lists the batches of new books that failed; their products
+	// are counted as failed
+	BatchErrors []BatchError `json:"batch_errors,omitempty"`
+}
+
+// addError counts a failed product and keeps its error while the report has room
+func (r *ONIXImportReport) addError(importErr ONIXImportError) {
+	r.Failed++
+	if len(r.Errors) < maxONIXReportErrors {
+		r.Errors = append(r.Errors, importErr)
+	} else {
+		r.Truncated = true
+	}
+}
+
+// onixBatch holds the new books of an import waiting to be inserted
+type onixBatch struct {
+	books    []*models.Book
+	products []ONIXImportError
+	isbns    map[string]bool
+}
+
+// ONIXService imports ONIX product feeds into the catalog
+type ONIXService struct {
+	db     *gorm.DB
+	cfg    config.ONIXConfig
+	counts *CountCache
+}
+
+// NewONIXService creates a new ONIX import service
+func NewONIXService(db *gorm.DB, cfg config.ONIXConfig) *ONIXService {
+	return &ONIXService{
+		db:     db,
+		cfg:    cfg,
+		counts: GetCountCache(),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *ONIXService) WithContext(ctx context.Context) *ONIXService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// Import parses an ONIX 3.0 message and upserts each product by ISBN. Authors,
+// categories (from the main subject), and publishers are matched by name and
+// created when missing, so re-importing the same feed is idempotent. Products
+// that fail are reported and do not stop the import.
+func (s *ONIXService) Import(r io.Reader, opts ONIXImportOptions) (*ONIXImportReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	report := &ONIXImportReport{DryRun: opts.DryRun, Errors: []ONIXImportError{}}
+	batch := &onixBatch{isbns: make(map[string]bool)}
+
+	err := onix.Parse(r, func(product *onix.Product) error {
+		report.Products++
+
+		// A product updating or deleting a book still waiting to be created
+		// must see it in the database
+		if isbn, ok := utils.CanonicalISBN(product.ISBN()); ok && batch.isbns[isbn] {
+			s.flushBatch(batch, opts.BatchSize, report)
+		}
+
+		outcome, err := s.importProduct(product, opts, batch)
+		if err != nil {
+			report.addError(ONIXImportError{
+				RecordReference: product.RecordReference,
+				ISBN:            product.ISBN(),
+				Error:           err.Error(),
+			})
+			return nil
+		}
+
+		switch outcome {
+		case onixQueued:
+			if len(batch.books) >= opts.BatchSize {
+				s.flushBatch(batch, opts.BatchSize, report)
+			}
+		case ONIXCreated:
+			report.Created++
+		case ONIXUpdated:
+			report.Updated++
+		case ONIXDeleted:
+			report.Deleted++
+		default:
+			report.Skipped++
+		}
+		return nil
+	})
+	s.flushBatch(batch, opts.BatchSize, report)
+
+	if !opts.DryRun && report.Created+report.Updated+report.Deleted > 0 {
+		s.counts.Invalidate("books")
+		s.counts.Invalidate("authors")
+		s.counts.Invalidate("categories")
+	}
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// flushBatch inserts the books waiting in the batch and counts them as created
+// or, for batches that fail, as failed
+func (s *ONIXService) flushBatch(batch *onixBatch, batchSize int, report *ONIXImportReport) {
+	if len(batch.books) == 0 {
+		return
+	}
+
+	failed := createInBatches(s.db, batch.books, batchSize, func(tx *gorm.DB, books []*models.Book) error {
+		return recordCreatedBooks(tx, books, models.PriceSourceONIX, models.StockReasonImport)
+	}, nil)
+	report.Created += len(batch.books)
+	for _, failure := range failed {
+		failure.Batch = len(report.BatchErrors)
+		report.BatchErrors = append(report.BatchErrors, failure)
+		report.Created -= failure.Rows
+		for _, product := range batch.products[failure.First : failure.First+failure.Rows] {
+			product.Error = failure.Error
+			report.addError(product)
+		}
+	}
+
+	batch.books = batch.books[:0]
+	batch.products = batch.products[:0]
+	clear(batch.isbns)
+}
+
+// importProduct validates and upserts a single product. New books are added
+// to the batch rather than created.
+func (s *ONIXService) importProduct(product *onix.Product, opts ONIXImportOptions, batch *onixBatch) (string, error) {
+	isbn, ok := utils.CanonicalISBN(product.ISBN())
+	if !ok {
+		return "", fmt.Errorf("product has no valid ISBN")
+	}
+
+	var existing models.Book
+	err := s.db.Unscoped().Where("isbn = ?", isbn).First(&existing).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", fmt.Errorf("failed to look up book: %w", err)
+	}
+	exists := err == nil
+
+	if product.IsDelete() {
+		if !exists || existing.DeletedAt.Valid {
+			return ONIXSkipped, nil
+		}
+		if opts.DryRun {
+			return ONIXDeleted, nil
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&models.Book{}, "id = ?", existing.ID).Error; err != nil {
+				return fmt.Errorf("failed to delete book: %w", err)
+			}
+			return events.Record(tx, events.BookDeleted, events.AggregateBook, existing.ID, events.DeletedPayload{ID: existing.ID})
+		})
+		if err != nil {
+			return "", err
+		}
+		return ONIXDeleted, nil
+	}
+
+	title := product.Title()
+	if title == "" {
+		return "", fmt.Errorf("product has no title")
+	}
+	title = truncate(title, 255)
+	authors := product.Authors()
+	if len(authors) == 0 {
+		return "", fmt.Errorf("product has no author contributor")
+	}
+	price, hasPrice := product.Price(s.cfg.Currency)
+	if !hasPrice && !exists {
+		return "", fmt.Errorf("product has no price")
+	}
+
+	outcome := ONIXCreated
+	if exists {
+		outcome = ONIXUpdated
+	}
+	if opts.DryRun {
+		return outcome, nil
+	}
+
+	if !exists {
+		var book *models.Book
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			authorID, categoryID, publisherID, err := s.resolveReferences(tx, product, authors[0])
+			if err != nil {
+				return err
+			}
+			book = &models.Book{
+				Title:       title,
+				ISBN:        isbn,
+				Description: product.Description(),
+				Price:       price,
+				PublishedAt: publicationDate(product),
+				AuthorID:    authorID,
+				CategoryID:  categoryID,
+				PublisherID: publisherID,
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		batch.books = append(batch.books, book)
+		batch.products = append(batch.products, ONIXImportError{RecordReference: product.RecordReference, ISBN: product.ISBN()})
+		batch.isbns[isbn] = true
+		return onixQueued, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		authorID, categoryID, publisherID, err := s.resolveReferences(tx, product, authors[0])
+		if err != nil {
+			return err
+		}
+
+		// Stock is managed by the store, so updates leave it untouched
+		updates := map[string]interface{}{
+			"title":        title,
+			"author_id":    authorID,
+			"category_id":  categoryID,
+			"publisher_id": publisherID,
+			"deleted_at":   nil,
+		}
+		if description := product.Description(); description != "" {
+			updates["description"] = description
+		}
+		if publishedAt := publicationDate(product); publishedAt != nil {
+			updates["published_at"] = publishedAt
+		}
+		if hasPrice {
+			updates["price"] = price
+		}
+		if err := tx.Unscoped().Model(&models.Book{}).Where("id = ?", existing.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update book: %w", err)
+		}
+
+		var book models.Book
+		if err := tx.First(&book, "id = ?", existing.ID).Error; err != nil {
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		bookSlug, err := slug.Rename(tx, "books", book.TenantID, book.ID, book.Slug, book.Title)
+		if err != nil {
+			return err
+		}
+		book.Slug = bookSlug
+		if err := events.Record(tx, events.BookUpdated, events.AggregateBook, book.ID, &book); err != nil {
+			return err
+		}
+		if book.Price != existing.Price {
+			if err := recordPrice(tx, book.ID, &existing.Price, book.Price, models.PriceSourceONIX); err != nil {
+				return err
+			}
+			return events.Record(tx, events.BookPriceChanged, events.AggregateBook, book.ID, events.PriceChangedPayload{
+				BookID:        book.ID,
+				PreviousPrice: existing.Price,
+				Price:         book.Price,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return outcome, nil
+}
+
+// resolveReferences finds or creates the product's author, category (from its
+// main subject, or the default category), and publisher
+func (s *ONIXService) resolveReferences(tx *gorm.DB, product *onix.Product, contributor onix.Author) (uuid.UUID, uuid.UUID, *uuid.UUID, error) {
+	author, err := s.findOrCreateAuthor(tx, contributor)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, nil, err
+	}
+	categoryName := product.Subject()
+	if categoryName == "" {
+		categoryName = s.cfg.DefaultCategory
+	}
+	category, err := s.findOrCreateCategory(tx, categoryName)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, nil, err
+	}
+	var publisherID *uuid.UUID
+	if name := product.PublisherName(); name != "" {
+		publisher, err := s.findOrCreatePublisher(tx, name)
+		if err != nil {
+			return uuid.Nil, uuid.Nil, nil, err
+		}
+		publisherID = &publisher.ID
+	}
+	return author.ID, category.ID, publisherID, nil
+}
+
+// findOrCreateAuthor matches an author by name, creating one with a placeholder
+// email when missing since ONIX feeds carry no contact details
+func (s *ONIXService) findOrCreateAuthor(tx *gorm.DB, contributor onix.Author) (*models.Author, error) {
+	var author models.Author
+	err := tx.Where("LOWER(name) = LOWER(?)", contributor.Name).First(&author).Error
+	if err == nil {
+		return &author, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to look up author: %w", err)
+	}
+
+	author = models.Author{
+		Name:      contributor.Name,
+		Email:     fmt.Sprintf("onix-%x@import.invalid", sha1.Sum([]byte(strings.ToLower(contributor.Name)))),
+		Biography: contributor.Biography,
+	}
+	if err := tx.Create(&author).Error; err != nil {
+		return nil, fmt.Errorf("failed to create author: %w", err)
+	}
+	if err := events.Record(tx, events.AuthorCreated, events.AggregateAuthor, author.ID, &author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// findOrCreateCategory matches a category by name, creating it when missing
+func (s *ONIXService) findOrCreateCategory(tx *gorm.DB, name string) (*models.Category, error) {
+	name = truncate(name, 100)
+
+	var category models.Category
+	err := tx.Where("LOWER(name) = LOWER(?)", name).First(&category).Error
+	if err == nil {
+		return &category, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to look up category: %w", err)
+	}
+
+	category = models.Category{Name: name}
+	if err := tx.Create(&category).Error; err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	if err := events.Record(tx, events.CategoryCreated, events.AggregateCategory, category.ID, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// findOrCreatePublisher matches a publisher by name, creating it when missing
+func (s *ONIXService) findOrCreatePublisher(tx *gorm.DB, name string) (*models.Publisher, error) {
+	name = truncate(name, 255)
+
+	var publisher models.Publisher
+	err := tx.Where("LOWER(name) = LOWER(?)", name).First(&publisher).Error
+	if err == nil {
+		return &publisher, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to look up publisher: %w", err)
+	}
+
+	publisher = models.Publisher{Name: name}
+	if err := tx.Create(&publisher).Error; err != nil {
+		return nil, fmt.Errorf("failed to create publisher: %w", err)
+	}
+	return &publisher, nil
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// publicationDate returns the product's publication date, if it has one
+func publicationDate(product *onix.Product) *models.DateOnly {
+	t := product.PublicationDate()
+	if t == nil {
+		return nil
+	}
+	date := models.NewDateOnly(*t)
+	return &date
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PartitionedTables are the append-only tables partitioned by month
+var PartitionedTables = []string{"stock_movements", "security_events", "book_views"}
+
+// Partition is one partition of a partitioned table
+type Partition struct {
+	Table     string `json:"table"`
+	Partition string `json:"partition"`
+	// Bounds is the partition's range, or DEFAULT for the default partition
+	Bounds string `json:"bounds"`
+	// EstimatedRows is the planner's row estimate, -1 before the partition
+	// is first analyzed
+	EstimatedRows int64 `json:"estimated_rows"`
+	SizeBytes     int64 `json:"size_bytes"`
+}
+
+// CreatePartitions creates the monthly partitions of every partitioned table,
+// in every tenant, from the month of now through ahead months later, and
+// returns the partitions it created. Existing partitions are left alone, and
+// rows that were written to a default partition for a new month are moved
+// into it.
+func CreatePartitions(db *gorm.DB, now time.Time, ahead int) ([]string, error) {
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, ahead, 0)
+
+	created := []string{}
+	for _, table := range PartitionedTables {
+		var partitions []string
+		if err := db.Raw("SELECT create_monthly_partitions(?, ?::date, ?::date)",
+			table, first.Format("2006-01-02"), last.Format("2006-01-02"),
+		).Scan(&partitions).Error; err != nil {
+			return nil, fmt.Errorf("failed to create %s partitions: %w", table, err)
+		}
+		created = append(created, partitions...)
+	}
+	return created, nil
+}
+
+// GetPartitions lists the partitions of every partitioned table in order
+func GetPartitions(db *gorm.DB) ([]Partition, error) {
+	partitions := []Partition{}
+	err := db.Raw(`
+		SELECT parent.relname AS "table", child.relname AS partition,
+			pg_get_expr(child.relpartbound, child.oid) AS bounds,
+			child.reltuples::bigint AS estimated_rows,
+			pg_total_relation_size(child.oid) AS size_bytes
+		FROM pg_inherits
+		JOIN pg_class parent ON parent.oid = pg_inherits.inhparent
+		JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+		WHERE parent.relname IN ?
+		ORDER BY parent.relname, child.relname`,
+		PartitionedTables,
+	).Scan(&partitions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+	return partitions, nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferenceUpdate changes a user's consents. Nil consents are left as they
+// are. PolicyVersion is the privacy policy version the user agreed to, the
+// current one when empty; IPAddress and UserAgent are recorded as evidence.
+type PreferenceUpdate struct {
+	EmailMarketing *bool
+	DataProcessing *bool
+	PolicyVersion  string
+	IPAddress      string
+	UserAgent      string
+}
+
+// PreferenceService manages customers' marketing preferences and consents
+type PreferenceService struct {
+	db            *gorm.DB
+	policyVersion string
+}
+
+// NewPreferenceService creates a new preference service
+func NewPreferenceService(db *gorm.DB, cfg *config.Config) *PreferenceService {
+	return &PreferenceService{
+		db:            db,
+		policyVersion: cfg.Privacy.PolicyVersion,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *PreferenceService) WithContext(ctx context.Context) *PreferenceService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// GetPreferences returns the user's preferences. Users who never set any get
+// the defaults, with every consent withheld.
+func (s *PreferenceService) GetPreferences(userID uuid.UUID) (*models.UserPreferences, error) {
+	return getPreferences(s.db, userID)
+}
+
+// UpdatePreferences changes the user's consents, recording each consent given
+// or withdrawn in the consent history
+func (s *PreferenceService) UpdatePreferences(userID uuid.UUID, update PreferenceUpdate) (*models.UserPreferences, error) {
+	version := strings.TrimSpace(update.PolicyVersion)
+	if version == "" {
+		version = s.policyVersion
+	}
+	if len(version) > 50 {
+		return nil, fmt.Errorf("invalid preferences: policy_version must be at most 50 characters")
+	}
+
+	var preferences *models.UserPreferences
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check user: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("user not found")
+		}
+
+		current, err := getPreferences(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		changes := []struct {
+			purpose string
+			value   *bool
+			granted *bool
+			at      **time.Time
+			policy  *string
+		}{
+			{models.ConsentEmailMarketing, update.EmailMarketing, &current.EmailMarketing, &current.EmailMarketingAt, &current.EmailMarketingPolicy},
+			{models.ConsentDataProcessing, update.DataProcessing, &current.DataProcessing, &current.DataProcessingAt, &current.DataProcessingPolicy},
+		}
+		for _, change := range changes {
+			// Giving a consent again under a new policy version is recorded too
+			if change.value == nil || (*change.value == *change.granted && (!*change.value || *change.policy == version)) {
+				continue
+			}
+			*change.granted = *change.value
+			*change.at = &now
+			*change.policy = version
+			record := &models.ConsentRecord{
+				UserID:        userID,
+				Purpose:       change.purpose,
+				Granted:       *change.value,
+				PolicyVersion: version,
+				IPAddress:     update.IPAddress,
+				UserAgent:     truncate(update.UserAgent, 512),
+				RecordedAt:    now,
+			}
+			if err := tx.Create(record).Error; err != nil {
+				return fmt.Errorf("failed to record consent: %w", err)
+			}
+		}
+
+		if err := tx.Omit(clause.Associations).Save(current).Error; err != nil {
+			return fmt.Errorf("failed to save preferences: %w", err)
+		}
+		preferences = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// GetConsentHistory lists the consents the user gave and withdrew, newest first
+func (s *PreferenceService) GetConsentHistory(userID uuid.UUID, page, limit int) ([]models.ConsentRecord, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.ConsentRecord{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count consent records: %w", err)
+	}
+
+	var records []models.ConsentRecord
+	if err := s.db.Where("user_id = ?", userID).
+		Order("recorded_at DESC, id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get consent records: %w", err)
+	}
+	return records, total, nil
+}
+
+// HasMarketingConsent reports whether the user agreed to receive marketing email
+func (s *PreferenceService) HasMarketingConsent(userID uuid.UUID) (bool, error) {
+	preferences, err := getPreferences(s.db, userID)
+	if err != nil {
+		return false, err
+	}
+	return preferences.EmailMarketing, nil
+}
+
+// getPreferences returns the user's stored preferences or the defaults
+func getPreferences(db *gorm.DB, userID uuid.UUID) (*models.UserPreferences, error) {
+	var preferences models.UserPreferences
+	if err := db.First(&preferences, "user_id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &models.UserPreferences{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return &preferences, nil
+}
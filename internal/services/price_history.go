@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PriceSummary is a book's current price with the lowest prices used for
+// EU price reduction disclosures
+type PriceSummary struct {
+	BookID       uuid.UUID `json:"book_id"`
+	CurrentPrice float64   `json:"current_price"`
+	CurrentSince time.Time `json:"current_since"`
+	WindowDays   int       `json:"window_days"`
+	// LowestPrice is the lowest price in effect at any time in the last WindowDays days
+	LowestPrice float64 `json:"lowest_price"`
+	// PriorLowestPrice is the lowest price in effect in the WindowDays days before
+	// the current price took effect, the reference price for a reduction. It is
+	// unset when the book had no earlier price.
+	PriorLowestPrice *float64 `json:"prior_lowest_price,omitempty"`
+}
+
+// recordPrice appends a price to a book's history. previous is the price it
+// replaced, or nil for a new book. Call it in the transaction that sets the price.
+func recordPrice(tx *gorm.DB, bookID uuid.UUID, previous *float64, price float64, source string) error {
+	entry := &models.PriceHistory{
+		BookID:        bookID,
+		Price:         price,
+		PreviousPrice: previous,
+		Source:        source,
+		ChangedAt:     time.Now(),
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+	return nil
+}
+
+// GetPriceHistory retrieves a book's price changes, newest first, with pagination
+func (s *BookService) GetPriceHistory(bookID uuid.UUID, page, limit int) ([]models.PriceHistory, int64, error) {
+	if err := s.checkBookExists(bookID); err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	query := s.db.Model(&models.PriceHistory{}).Where("book_id = ?", bookID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count price history: %w", err)
+	}
+
+	var history []models.PriceHistory
+	offset := (page - 1) * limit
+	if err := query.Order("changed_at DESC").Offset(offset).Limit(limit).Find(&history).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get price history: %w", err)
+	}
+	return history, total, nil
+}
+
+// GetPriceSummary returns a book's current price and its lowest prices over
+// windows of the given number of days
+func (s *BookService) GetPriceSummary(bookID uuid.UUID, days int) (*PriceSummary, error) {
+	var book models.Book
+	if err := s.db.Select("id", "price", "created_at").First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	summary := &PriceSummary{
+		BookID:       book.ID,
+		CurrentPrice: book.Price,
+		CurrentSince: book.CreatedAt,
+		WindowDays:   days,
+		LowestPrice:  book.Price,
+	}
+
+	var latest models.PriceHistory
+	err := s.db.Where("book_id = ?", bookID).Order("changed_at DESC").First(&latest).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	if err == nil {
+		summary.CurrentSince = latest.ChangedAt
+	}
+
+	now := time.Now()
+	window := time.Duration(days) * 24 * time.Hour
+	lowest, err := s.lowestPrice(bookID, now.Add(-window), now)
+	if err != nil {
+		return nil, err
+	}
+	if lowest != nil && *lowest < summary.LowestPrice {
+		summary.LowestPrice = *lowest
+	}
+
+	if summary.PriorLowestPrice, err = s.lowestPrice(bookID, summary.CurrentSince.Add(-window), summary.CurrentSince); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// lowestPrice returns the lowest price in effect between from and to: prices
+// set in the range and the one already in effect at from. It returns nil if
+// the book had no price in the range.
+func (s *BookService) lowestPrice(bookID uuid.UUID, from, to time.Time) (*float64, error) {
+	var lowest sql.NullFloat64
+	if err := s.db.Model(&models.PriceHistory{}).
+		Select("MIN(price)").
+		Where("book_id = ? AND changed_at >= ? AND changed_at < ?", bookID, from, to).
+		Scan(&lowest).Error; err != nil {
+		return nil, fmt.Errorf("failed to get lowest price: %w", err)
+	}
+
+	var inEffect models.PriceHistory
+	err := s.db.Where("book_id = ? AND changed_at < ?", bookID, from).Order("changed_at DESC").First(&inEffect).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	if err == nil && (!lowest.Valid || inEffect.Price < lowest.Float64) {
+		lowest = sql.NullFloat64{Float64: inEffect.Price, Valid: true}
+	}
+
+	if !lowest.Valid {
+		return nil, nil
+	}
+	return &lowest.Float64, nil
+}
+
+// checkBookExists returns "book not found" unless the book exists
+func (s *BookService) checkBookExists(id uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.Book{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get book: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("book not found")
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromotionService handles scheduled book and category promotions
+type PromotionService struct {
+	db *gorm.DB
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(db *gorm.DB) *PromotionService {
+	return &PromotionService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *PromotionService) WithContext(ctx context.Context) *PromotionService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreatePromotion creates a new promotion, active at once if its schedule has started
+func (s *PromotionService) CreatePromotion(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	if err := s.checkTarget(promotion); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		promotion.Active = false
+		if err := tx.Create(promotion).Error; err != nil {
+			return fmt.Errorf("failed to create promotion: %w", err)
+		}
+		return syncPromotion(tx, promotion, time.Now())
+	})
+}
+
+// GetPromotionByID retrieves a promotion by ID
+func (s *PromotionService) GetPromotionByID(id uuid.UUID) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := s.db.First(&promotion, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("promotion not found")
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return &promotion, nil
+}
+
+// GetAllPromotions retrieves promotions, latest starting first, with pagination.
+// When activeOnly is set only promotions currently applied are returned.
+func (s *PromotionService) GetAllPromotions(page, limit int, activeOnly bool) ([]models.Promotion, int64, error) {
+	query := s.db.Model(&models.Promotion{})
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count promotions: %w", err)
+	}
+
+	var promotions []models.Promotion
+	offset := (page - 1) * limit
+	if err := query.Order("starts_at DESC").Offset(offset).Limit(limit).Find(&promotions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get promotions: %w", err)
+	}
+	return promotions, total, nil
+}
+
+// UpdatePromotion updates an existing promotion and activates or deactivates
+// it if its new schedule requires
+func (s *PromotionService) UpdatePromotion(id uuid.UUID, updates map[string]interface{}) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("promotion not found")
+			}
+			return fmt.Errorf("failed to get promotion: %w", err)
+		}
+
+		if err := tx.Model(&promotion).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update promotion: %w", err)
+		}
+		if err := tx.First(&promotion, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to get promotion: %w", err)
+		}
+		if err := validatePromotion(&promotion); err != nil {
+			return err
+		}
+		return syncPromotion(tx, &promotion, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// DeletePromotion deletes a promotion, ending it first if it is active
+func (s *PromotionService) DeletePromotion(id uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var promotion models.Promotion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("promotion not found")
+			}
+			return fmt.Errorf("failed to get promotion: %w", err)
+		}
+
+		if promotion.Active {
+			if err := events.Record(tx, events.PromotionEnded, events.AggregatePromotion, promotion.ID, &promotion); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&promotion).Error; err != nil {
+			return fmt.Errorf("failed to delete promotion: %w", err)
+		}
+		return nil
+	})
+}
+
+// SyncPromotions activates promotions whose schedule has started and
+// deactivates those that have ended, returning how many of each changed
+func (s *PromotionService) SyncPromotions(now time.Time) (started, ended int, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var due []models.Promotion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(active = ? AND starts_at <= ? AND ends_at > ?) OR (active = ? AND (starts_at > ? OR ends_at <= ?))",
+				false, now, now, true, now, now).
+			Find(&due).Error; err != nil {
+			return fmt.Errorf("failed to get due promotions: %w", err)
+		}
+
+		for i := range due {
+			if err := syncPromotion(tx, &due[i], now); err != nil {
+				return err
+			}
+			if due[i].Active {
+				started++
+			} else {
+				ended++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return started, ended, nil
+}
+
+// syncPromotion sets whether the promotion is active from its schedule at now,
+// recording a promotion.started or promotion.ended event when that changes
+func syncPromotion(tx *gorm.DB, promotion *models.Promotion, now time.Time) error {
+	active := promotion.InEffect(now)
+	if active == promotion.Active {
+		return nil
+	}
+
+	if err := tx.Model(promotion).Update("active", active).Error; err != nil {
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+	eventType := events.PromotionEnded
+	if active {
+		eventType = events.PromotionStarted
+	}
+	return events.Record(tx, eventType, events.AggregatePromotion, promotion.ID, promotion)
+}
+
+// applyPromotions sets the effective price of books to their lowest price
+// under an active promotion on the book or its category
+func applyPromotions(db *gorm.DB, books []*models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	bookIDs := make([]uuid.UUID, 0, len(books))
+	categoryIDs := make([]uuid.UUID, 0, len(books))
+	for _, book := range books {
+		bookIDs = append(bookIDs, book.ID)
+		categoryIDs = append(categoryIDs, book.CategoryID)
+	}
+
+	var promotions []models.Promotion
+	if err := db.Where("active = ? AND (book_id IN ? OR category_id IN ?)", true, bookIDs, categoryIDs).
+		Find(&promotions).Error; err != nil {
+		return fmt.Errorf("failed to get promotions: %w", err)
+	}
+
+	byBook := make(map[uuid.UUID][]*models.Promotion)
+	byCategory := make(map[uuid.UUID][]*models.Promotion)
+	for i := range promotions {
+		promotion := &promotions[i]
+		if promotion.BookID != nil {
+			byBook[*promotion.BookID] = append(byBook[*promotion.BookID], promotion)
+		} else if promotion.CategoryID != nil {
+			byCategory[*promotion.CategoryID] = append(byCategory[*promotion.CategoryID], promotion)
+		}
+	}
+
+	for _, book := range books {
+		price := book.Price
+		book.Promotion = nil
+		for _, candidates := range [][]*models.Promotion{byBook[book.ID], byCategory[book.CategoryID]} {
+			for _, promotion := range candidates {
+				if promoted := promotion.PriceFor(book.Price); promoted < price {
+					price = promoted
+					book.Promotion = &models.AppliedPromotion{
+						ID:     promotion.ID,
+						Name:   promotion.Name,
+						EndsAt: promotion.EndsAt,
+					}
+				}
+			}
+		}
+		book.EffectivePrice = &price
+	}
+	return nil
+}
+
+// bookRefs returns pointers to the elements of books
+func bookRefs(books []models.Book) []*models.Book {
+	refs := make([]*models.Book, len(books))
+	for i := range books {
+		refs[i] = &books[i]
+	}
+	return refs
+}
+
+// validatePromotion checks the promotion against the rules of promotions:
+// its target, discount, and schedule
+func validatePromotion(promotion *models.Promotion) error {
+	if err := utils.ValidateStruct(promotion); err != nil {
+		return fmt.Errorf("invalid promotion: %w", err)
+	}
+	return nil
+}
+
+// checkTarget verifies that the promoted book or category exists
+func (s *PromotionService) checkTarget(promotion *models.Promotion) error {
+	var count int64
+	if promotion.BookID != nil {
+		if err := s.db.Model(&models.Book{}).Where("id = ?", *promotion.BookID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("book not found")
+		}
+		return nil
+	}
+
+	if err := s.db.Model(&models.Category{}).Where("id = ?", *promotion.CategoryID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("category not found")
+	}
+	return nil
+}
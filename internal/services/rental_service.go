@@ -0,0 +1,344 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rental statuses accepted by GetRentals
+const (
+	RentalStatusOut      = "out"
+	RentalStatusOverdue  = "overdue"
+	RentalStatusReturned = "returned"
+)
+
+// RentalService handles lending book copies. A book's stock is the number of
+// copies that can be out at the same time.
+type RentalService struct {
+	db        *gorm.DB
+	maxPeriod time.Duration
+}
+
+// NewRentalService creates a new rental service
+func NewRentalService(db *gorm.DB, cfg *config.Config) *RentalService {
+	return &RentalService{
+		db:        db,
+		maxPeriod: cfg.Rentals.MaxPeriod,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *RentalService) WithContext(ctx context.Context) *RentalService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// RentalFilter narrows the rentals returned by GetRentals
+type RentalFilter struct {
+	UserID *uuid.UUID
+	BookID *uuid.UUID
+	Status string
+}
+
+// DayAvailability is the number of copies of a book free for the whole of a day
+type DayAvailability struct {
+	Date      string `json:"date"`
+	Available int    `json:"available"`
+}
+
+// Availability is a book's rental calendar
+type Availability struct {
+	BookID uuid.UUID         `json:"book_id"`
+	Copies int               `json:"copies"`
+	Days   []DayAvailability `json:"days"`
+}
+
+// RentBook lends a copy of a book to a user from startsAt until dueAt. It
+// fails if every copy is out at some point in that period.
+func (s *RentalService) RentBook(bookID, userID uuid.UUID, startsAt, dueAt time.Time) (*models.RentalPeriod, error) {
+	now := time.Now()
+	switch {
+	case !dueAt.After(startsAt):
+		return nil, fmt.Errorf("invalid rental: due_at must be after starts_at")
+	case startsAt.Before(now.Add(-time.Minute)):
+		return nil, fmt.Errorf("invalid rental: starts_at cannot be in the past")
+	case s.maxPeriod > 0 && dueAt.Sub(startsAt) > s.maxPeriod:
+		return nil, fmt.Errorf("invalid rental: rental period cannot exceed %s", s.maxPeriod)
+	}
+
+	rental := &models.RentalPeriod{
+		BookID:   bookID,
+		UserID:   userID,
+		StartsAt: startsAt,
+		DueAt:    dueAt,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the book so concurrent rentals of it are checked one at a time
+		var book models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "stock").First(&book, "id = ?", bookID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("book not found")
+			}
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+
+		var users int64
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Count(&users).Error; err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if users == 0 {
+			return fmt.Errorf("user not found")
+		}
+
+		rentals, err := overlappingRentals(tx, bookID, startsAt, dueAt, now)
+		if err != nil {
+			return err
+		}
+		if peakOut(rentals, startsAt, dueAt, now) >= book.Stock {
+			return fmt.Errorf("no copies available for the requested period")
+		}
+
+		if err := tx.Create(rental).Error; err != nil {
+			return fmt.Errorf("failed to create rental: %w", err)
+		}
+		return events.Record(tx, events.RentalCreated, events.AggregateRental, rental.ID, rental)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rental, nil
+}
+
+// ReturnRental records the return of a rented copy, flagging it late if it is
+// past its due date
+func (s *RentalService) ReturnRental(id uuid.UUID) (*models.RentalPeriod, error) {
+	var rental models.RentalPeriod
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rental, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("rental not found")
+			}
+			return fmt.Errorf("failed to get rental: %w", err)
+		}
+		if rental.ReturnedAt != nil {
+			return fmt.Errorf("rental already returned")
+		}
+
+		now := time.Now()
+		rental.ReturnedAt = &now
+		rental.Late = rental.Late || now.After(rental.DueAt)
+		if err := tx.Model(&rental).Select("returned_at", "late").Updates(&rental).Error; err != nil {
+			return fmt.Errorf("failed to return rental: %w", err)
+		}
+		return events.Record(tx, events.RentalReturned, events.AggregateRental, rental.ID, &rental)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rental, nil
+}
+
+// GetRentalByID retrieves a rental by ID
+func (s *RentalService) GetRentalByID(id uuid.UUID) (*models.RentalPeriod, error) {
+	var rental models.RentalPeriod
+	if err := s.db.Preload("Book").First(&rental, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("rental not found")
+		}
+		return nil, fmt.Errorf("failed to get rental: %w", err)
+	}
+	return &rental, nil
+}
+
+// ResolveRentalID returns the ID of the rental value names, by ID or short
+// code
+func (s *RentalService) ResolveRentalID(value string) (uuid.UUID, error) {
+	id, err := resolveID(s.db, &models.RentalPeriod{}, value)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("rental not found")
+	}
+	return id, nil
+}
+
+// IsRentalOwner reports whether the rental was made for the user
+func (s *RentalService) IsRentalOwner(rentalID, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.RentalPeriod{}).Where("id = ? AND user_id = ?", rentalID, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check rental owner: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetRentals retrieves rentals matching the filter, latest starting first, with pagination
+func (s *RentalService) GetRentals(filter RentalFilter, page, limit int) ([]models.RentalPeriod, int64, error) {
+	query := s.db.Model(&models.RentalPeriod{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.BookID != nil {
+		query = query.Where("book_id = ?", *filter.BookID)
+	}
+	switch filter.Status {
+	case "":
+	case RentalStatusOut:
+		query = query.Where("returned_at IS NULL")
+	case RentalStatusOverdue:
+		query = query.Where("returned_at IS NULL AND due_at < ?", time.Now())
+	case RentalStatusReturned:
+		query = query.Where("returned_at IS NOT NULL")
+	default:
+		return nil, 0, fmt.Errorf("invalid rental status %q", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count rentals: %w", err)
+	}
+
+	var rentals []models.RentalPeriod
+	offset := (page - 1) * limit
+	if err := query.Preload("Book").Order("starts_at DESC").Offset(offset).Limit(limit).Find(&rentals).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get rentals: %w", err)
+	}
+	return rentals, total, nil
+}
+
+// GetAvailability returns how many copies of a book are free for each day
+// (UTC) from the day of from up to but excluding the day of to
+func (s *RentalService) GetAvailability(bookID uuid.UUID, from, to time.Time) (*Availability, error) {
+	var book models.Book
+	if err := s.db.Select("id", "stock").First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	from = truncateDay(from)
+	to = truncateDay(to)
+	now := time.Now()
+	rentals, err := overlappingRentals(s.db, bookID, from, to, now)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &Availability{
+		BookID: book.ID,
+		Copies: book.Stock,
+		Days:   []DayAvailability{},
+	}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		available := book.Stock - peakOut(rentals, day, day.AddDate(0, 0, 1), now)
+		if available < 0 {
+			available = 0
+		}
+		availability.Days = append(availability.Days, DayAvailability{
+			Date:      day.Format("2006-01-02"),
+			Available: available,
+		})
+	}
+	return availability, nil
+}
+
+// FlagLateRentals marks rentals still out past their due date as late,
+// returning how many were flagged
+func (s *RentalService) FlagLateRentals(now time.Time) (int, error) {
+	flagged := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var overdue []models.RentalPeriod
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("returned_at IS NULL AND late = ? AND due_at < ?", false, now).
+			Find(&overdue).Error; err != nil {
+			return fmt.Errorf("failed to get overdue rentals: %w", err)
+		}
+
+		for i := range overdue {
+			rental := &overdue[i]
+			if err := tx.Model(rental).Update("late", true).Error; err != nil {
+				return fmt.Errorf("failed to flag rental: %w", err)
+			}
+			if err := events.Record(tx, events.RentalOverdue, events.AggregateRental, rental.ID, rental); err != nil {
+				return err
+			}
+			flagged++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return flagged, nil
+}
+
+// overlappingRentals returns the rentals of a book whose copy is out at some
+// time between from and to
+func overlappingRentals(db *gorm.DB, bookID uuid.UUID, from, to, now time.Time) ([]models.RentalPeriod, error) {
+	var rentals []models.RentalPeriod
+	if err := db.Where("book_id = ? AND starts_at < ?", bookID, to).
+		Where("returned_at > ? OR (returned_at IS NULL AND (due_at > ? OR due_at <= ?))", from, from, now).
+		Find(&rentals).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rentals: %w", err)
+	}
+	return rentals, nil
+}
+
+// peakOut returns the largest number of the rentals' copies out at the same
+// time between from and to
+func peakOut(rentals []models.RentalPeriod, from, to, now time.Time) int {
+	type change struct {
+		at    time.Time
+		delta int
+	}
+
+	changes := make([]change, 0, 2*len(rentals))
+	for i := range rentals {
+		start := rentals[i].StartsAt
+		if start.Before(from) {
+			start = from
+		}
+		end := to
+		if until := rentals[i].OutUntil(now); until != nil && until.Before(to) {
+			end = *until
+		}
+		if start.Before(end) {
+			changes = append(changes, change{start, 1}, change{end, -1})
+		}
+	}
+
+	// A copy returned at the moment another rental starts can be lent again
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].delta < changes[j].delta
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+
+	out, peak := 0, 0
+	for _, c := range changes {
+		out += c.delta
+		if out > peak {
+			peak = out
+		}
+	}
+	return peak
+}
+
+// truncateDay returns the start of t's day in UTC
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
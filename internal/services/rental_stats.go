@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bookstore-api/internal/tenancy"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// statsViews are the materialized views behind the aggregate statistics, in
+// refresh order
+var statsViews = []string{"rental_daily_stats"}
+
+// RentalStatsOptions controls the rental statistics report
+type RentalStatsOptions struct {
+	From     time.Time
+	To       time.Time
+	TopLimit int
+}
+
+// BookRentalStat is a book ranked by its rentals
+type BookRentalStat struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Rentals     int64     `json:"rentals"`
+	LateRentals int64     `json:"late_rentals"`
+}
+
+// RentalStatsReport is the most-rented books and the daily number of rentals
+// started in a date range
+type RentalStatsReport struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Rentals  int64            `json:"rentals"`
+	TopBooks []BookRentalStat `json:"top_books"`
+	Daily    []TrendPoint     `json:"daily"`
+}
+
+// GetRentalStats builds the rental statistics report from the
+// rental_daily_stats view, so it reflects rentals up to the view's last
+// refresh. Days are UTC days; the range covers the day of from up to, but
+// not including, the day of to, and defaults to the 30 days through today.
+func (s *StatsService) GetRentalStats(opts RentalStatsOptions) (*RentalStatsReport, error) {
+	if opts.To.IsZero() {
+		now := time.Now().UTC()
+		opts.To = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.AddDate(0, 0, -30)
+	}
+	if !opts.From.Before(opts.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if opts.TopLimit <= 0 {
+		opts.TopLimit = defaultStatsTopLimit
+	}
+
+	report := &RentalStatsReport{
+		From: opts.From,
+		To:   opts.To,
+	}
+
+	// Raw SQL bypasses the tenancy plugin, so the view is filtered explicitly
+	tenantID, ok := tenancy.TenantID(s.db.Statement.Context)
+	if !ok {
+		return nil, tenancy.ErrNoTenant
+	}
+	args := map[string]interface{}{
+		"tenant": tenantID,
+		"from":   opts.From.UTC().Format("2006-01-02"),
+		"to":     opts.To.UTC().Format("2006-01-02"),
+		"top":    opts.TopLimit,
+	}
+
+	if err := s.db.Raw(`
+		SELECT COALESCE(SUM(rentals), 0)
+		FROM rental_daily_stats
+		WHERE tenant_id = @tenant AND day >= @from::date AND day < @to::date`,
+		args,
+	).Scan(&report.Rentals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count rentals: %w", err)
+	}
+
+	report.TopBooks = []BookRentalStat{}
+	if err := s.db.Raw(`
+		SELECT books.id, books.title, SUM(stats.rentals) AS rentals, SUM(stats.late_rentals) AS late_rentals
+		FROM rental_daily_stats stats
+		JOIN books ON books.id = stats.book_id
+		WHERE stats.tenant_id = @tenant AND stats.day >= @from::date AND stats.day < @to::date
+		GROUP BY books.id, books.title
+		ORDER BY rentals DESC, books.title
+		LIMIT @top`,
+		args,
+	).Scan(&report.TopBooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get most rented books: %w", err)
+	}
+
+	// Fill days without rentals so the trend can be charted directly
+	report.Daily = []TrendPoint{}
+	if err := s.db.Raw(`
+		SELECT series.period, COALESCE(SUM(stats.rentals), 0) AS count
+		FROM generate_series(@from::date, @to::date - 1, '1 day'::interval) AS series(period)
+		LEFT JOIN rental_daily_stats stats ON stats.day = series.period::date AND stats.tenant_id = @tenant
+		GROUP BY series.period
+		ORDER BY series.period`,
+		args,
+	).Scan(&report.Daily).Error; err != nil {
+		return nil, fmt.Errorf("failed to get daily rentals: %w", err)
+	}
+
+	return report, nil
+}
+
+// RefreshStatsViews recomputes the materialized views behind the aggregate
+// statistics for every tenant. Views are refreshed concurrently, so reports
+// keep reading the previous contents until each refresh completes.
+func RefreshStatsViews(db *gorm.DB) ([]string, error) {
+	for _, view := range statsViews {
+		if err := db.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY " + view).Error; err != nil {
+			return nil, fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+	}
+	return statsViews, nil
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/pdf"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportTypes lists the supported report types
+var ReportTypes = []string{
+	models.ReportTypeInventory,
+	models.ReportTypeLowStock,
+	models.ReportTypeNewTitles,
+	models.ReportTypeCatalogSummary,
+	models.ReportTypeTopRentals,
+}
+
+// maxDueReports caps the number of reports scheduled per dispatch
+const maxDueReports = 100
+
+// ReportService handles report definitions and report generation
+type ReportService struct {
+	db    *gorm.DB
+	books *BookService
+	stats *StatsService
+}
+
+// NewReportService creates a new report service
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{
+		db:    db,
+		books: NewBookService(db),
+		stats: NewStatsService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *ReportService) WithContext(ctx context.Context) *ReportService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.books = s.books.WithContext(ctx)
+	clone.stats = s.stats.WithContext(ctx)
+	return &clone
+}
+
+// CreateReport creates a new report definition
+func (s *ReportService) CreateReport(report *models.ReportDefinition) error {
+	if report.Recipients == nil {
+		report.Recipients = models.StringList{}
+	}
+	if err := s.db.Create(report).Error; err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	return nil
+}
+
+// GetReportByID retrieves a report definition by ID
+func (s *ReportService) GetReportByID(id uuid.UUID) (*models.ReportDefinition, error) {
+	var report models.ReportDefinition
+	if err := s.db.First(&report, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("report not found")
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	return &report, nil
+}
+
+// GetAllReports retrieves all report definitions with pagination
+func (s *ReportService) GetAllReports(page, limit int) ([]models.ReportDefinition, int64, error) {
+	var reports []models.ReportDefinition
+	var total int64
+
+	// Count total records
+	if err := s.db.Model(&models.ReportDefinition{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Get reports with pagination
+	if err := s.db.Order("name ASC").Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get reports: %w", err)
+	}
+
+	return reports, total, nil
+}
+
+// UpdateReport updates a report definition. Updates are given as a column map
+// so that reports can be disabled or have their recipients cleared.
+func (s *ReportService) UpdateReport(id uuid.UUID, updates map[string]interface{}) error {
+	result := s.db.Model(&models.ReportDefinition{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update report: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+// DeleteReport soft deletes a report definition
+func (s *ReportService) DeleteReport(id uuid.UUID) error {
+	result := s.db.Delete(&models.ReportDefinition{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete report: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+// ScheduleDue locks enabled reports whose next run is due, calls schedule for
+// each within the transaction, and advances next_run_at using next. Locked rows
+// are skipped so concurrent dispatchers never schedule a report twice.
+func (s *ReportService) ScheduleDue(now time.Time, next func(report *models.ReportDefinition) (time.Time, error), schedule func(tx *gorm.DB, report *models.ReportDefinition) error) (int, error) {
+	scheduled := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var reports []models.ReportDefinition
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("enabled = ? AND next_run_at <= ?", true, now).
+			Order("next_run_at ASC").
+			Limit(maxDueReports).
+			Find(&reports).Error; err != nil {
+			return fmt.Errorf("failed to get due reports: %w", err)
+		}
+
+		for i := range reports {
+			report := &reports[i]
+			nextRun, err := next(report)
+			if err != nil {
+				// A bad schedule would otherwise be retried every tick
+				if err := tx.Model(report).Updates(map[string]interface{}{
+					"enabled":     false,
+					"last_status": models.ReportRunFailed,
+					"last_error":  err.Error(),
+				}).Error; err != nil {
+					return fmt.Errorf("failed to disable report: %w", err)
+				}
+				continue
+			}
+			if err := schedule(tx, report); err != nil {
+				return err
+			}
+			if err := tx.Model(report).Update("next_run_at", nextRun).Error; err != nil {
+				return fmt.Errorf("failed to advance report schedule: %w", err)
+			}
+			scheduled++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return scheduled, nil
+}
+
+// RecordRun records the outcome of a report run
+func (s *ReportService) RecordRun(id uuid.UUID, runAt time.Time, runErr error) error {
+	updates := map[string]interface{}{
+		"last_run_at": runAt,
+		"last_status": models.ReportRunSucceeded,
+		"last_error":  "",
+	}
+	if runErr != nil {
+		updates["last_status"] = models.ReportRunFailed
+		updates["last_error"] = runErr.Error()
+	}
+	if err := s.db.Model(&models.ReportDefinition{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record report run: %w", err)
+	}
+	return nil
+}
+
+// ReportRange returns the date range a run at now covers: the previous seven
+// days for weekly reports, or the previous calendar month for monthly reports
+func ReportRange(period string, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if period == models.ReportPeriodMonth {
+		to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return to.AddDate(0, -1, 0), to
+	}
+	return today.AddDate(0, 0, -7), today
+}
+
+// ReportTitle returns the title of a report run covering the date range,
+// which is inclusive of from and exclusive of to
+func ReportTitle(report *models.ReportDefinition, from, to time.Time) string {
+	return fmt.Sprintf("%s (%s to %s)", report.Name, from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+}
+
+// rowWriter receives the rows of a report, header first
+type rowWriter interface {
+	Write(row []string) error
+}
+
+// WriteReport writes the report for the date range in its format, CSV or a
+// PDF table. Books at or below lowStock are included in low stock reports.
+func (s *ReportService) WriteReport(report *models.ReportDefinition, from, to time.Time, lowStock int, w io.Writer) error {
+	if report.Format == models.ReportFormatPDF {
+		table := pdf.NewTable(ReportTitle(report, from, to))
+		if err := s.writeRows(table, report, from, to, lowStock); err != nil {
+			return err
+		}
+		return table.Render(w)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := s.writeRows(writer, report, from, to, lowStock); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeRows writes the rows of the report for the date range to writer
+func (s *ReportService) writeRows(writer rowWriter, report *models.ReportDefinition, from, to time.Time, lowStock int) error {
+	switch report.ReportType {
+	case models.ReportTypeInventory:
+		return s.writeInventory(writer)
+	case models.ReportTypeLowStock:
+		return s.writeLowStock(writer, lowStock)
+	case models.ReportTypeNewTitles:
+		return s.writeNewTitles(writer, from, to)
+	case models.ReportTypeCatalogSummary:
+		return s.writeCatalogSummary(writer, from, to, lowStock)
+	case models.ReportTypeTopRentals:
+		return s.writeTopRentals(writer, from, to)
+	default:
+		return fmt.Errorf("unknown report type %q", report.ReportType)
+	}
+}
+
+// writeInventory writes every book with its stock and stock value
+func (s *ReportService) writeInventory(writer rowWriter) error {
+	columns := []string{"id", "title", "isbn", "author_name", "category_name", "price", "stock"}
+	if err := writer.Write(append(columns, "stock_value")); err != nil {
+		return err
+	}
+	return s.books.ExportBooks(BookFilter{}, func(row *BookExportRow) error {
+		value := strconv.FormatFloat(row.Price*float64(row.Stock), 'f', 2, 64)
+		return writer.Write(append(row.Values(columns), value))
+	})
+}
+
+// writeLowStock writes books at or below the threshold, lowest stock first
+func (s *ReportService) writeLowStock(writer rowWriter, threshold int) error {
+	books, err := s.books.GetLowStockBooks(threshold)
+	if err != nil {
+		return err
+	}
+	if err := writer.Write([]string{"id", "title", "isbn", "stock", "price"}); err != nil {
+		return err
+	}
+	for _, book := range books {
+		if err := writer.Write([]string{
+			book.ID.String(),
+			book.Title,
+			book.ISBN,
+			strconv.Itoa(book.Stock),
+			strconv.FormatFloat(book.Price, 'f', 2, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNewTitles writes books added in the date range
+func (s *ReportService) writeNewTitles(writer rowWriter, from, to time.Time) error {
+	var books []models.Book
+	if err := s.db.Preload("Author").Preload("Category").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at ASC").
+		Find(&books).Error; err != nil {
+		return fmt.Errorf("failed to get new titles: %w", err)
+	}
+
+	if err := writer.Write([]string{"id", "title", "isbn", "author_name", "category_name", "price", "stock", "created_at"}); err != nil {
+		return err
+	}
+	for _, book := range books {
+		if err := writer.Write([]string{
+			book.ID.String(),
+			book.Title,
+			book.ISBN,
+			book.Author.Name,
+			book.Category.Name,
+			strconv.FormatFloat(book.Price, 'f', 2, 64),
+			strconv.Itoa(book.Stock),
+			book.CreatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCatalogSummary writes the admin statistics for the date range as metric rows
+func (s *ReportService) writeCatalogSummary(writer rowWriter, from, to time.Time, lowStock int) error {
+	stats, err := s.stats.GetStats(StatsOptions{From: from, To: to, LowStock: lowStock})
+	if err != nil {
+		return err
+	}
+
+	counts := stats.Counts
+	rows := [][]string{
+		{"section", "name", "value"},
+		{"counts", "books", strconv.FormatInt(counts.Books, 10)},
+		{"counts", "authors", strconv.FormatInt(counts.Authors, 10)},
+		{"counts", "categories", strconv.FormatInt(counts.Categories, 10)},
+		{"counts", "publishers", strconv.FormatInt(counts.Publishers, 10)},
+		{"counts", "out_of_stock", strconv.FormatInt(counts.OutOfStock, 10)},
+		{"counts", "low_stock", strconv.FormatInt(counts.LowStock, 10)},
+		{"counts", "new_books", strconv.FormatInt(counts.NewBooks, 10)},
+		{"counts", "new_authors", strconv.FormatInt(counts.NewAuthors, 10)},
+		{"counts", "units_in_stock", strconv.FormatInt(counts.UnitsInStock, 10)},
+		{"stock_value", "total", strconv.FormatFloat(stats.StockValue.Total, 'f', 2, 64)},
+		{"stock_value", "average_price", strconv.FormatFloat(stats.StockValue.AveragePrice, 'f', 2, 64)},
+	}
+	for _, category := range stats.TopCategories {
+		rows = append(rows, []string{"top_categories", category.Name, strconv.FormatInt(category.BookCount, 10)})
+	}
+	for _, author := range stats.TopAuthors {
+		rows = append(rows, []string{"top_authors", author.Name, strconv.FormatInt(author.BookCount, 10)})
+	}
+	for _, point := range stats.NewTitles {
+		rows = append(rows, []string{"new_titles", point.Period.UTC().Format("2006-01-02"), strconv.FormatInt(point.Count, 10)})
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topRentalsLimit is the number of books listed in top rentals reports
+const topRentalsLimit = 100
+
+// writeTopRentals writes the most rented books in the date range
+func (s *ReportService) writeTopRentals(writer rowWriter, from, to time.Time) error {
+	stats, err := s.stats.GetRentalStats(RentalStatsOptions{From: from, To: to, TopLimit: topRentalsLimit})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Write([]string{"id", "title", "rentals", "late_rentals"}); err != nil {
+		return err
+	}
+	for _, book := range stats.TopBooks {
+		if err := writer.Write([]string{
+			book.ID.String(),
+			book.Title,
+			strconv.FormatInt(book.Rentals, 10),
+			strconv.FormatInt(book.LateRentals, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,501 @@
+package services
+
+import (
+	"bookstore-api/internal/access"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revisableFields are the book fields a revision can change. Stock is kept
+// up to date by admins and is not revised.
+var revisableFields = []string{"title", "isbn", "description", "price", "published_at", "author_id", "category_id", "status", "format", "page_count"}
+
+// RevisionFilter selects book revisions. Zero fields select every revision.
+type RevisionFilter struct {
+	Status      string
+	BookID      uuid.UUID
+	SubmittedBy uuid.UUID
+	// NewestFirst lists the newest revisions first, as a history, rather
+	// than in the order they wait for review
+	NewestFirst bool
+}
+
+// RevisionService manages changes to books that wait for an admin's approval
+type RevisionService struct {
+	db    *gorm.DB
+	books *BookService
+}
+
+// NewRevisionService creates a new revision service
+func NewRevisionService(db *gorm.DB) *RevisionService {
+	return &RevisionService{
+		db:    db,
+		books: NewBookService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *RevisionService) WithContext(ctx context.Context) *RevisionService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.books = s.books.WithContext(ctx)
+	return &clone
+}
+
+// SubmitRevision records the updates to a book as a pending revision by
+// the user. updates holds column values as for BookService.UpdateBook;
+// values the book already has are left out.
+func (s *RevisionService) SubmitRevision(bookID, userID uuid.UUID, updates map[string]interface{}, note string) (*models.BookRevision, error) {
+	if len(note) > 1000 {
+		return nil, fmt.Errorf("invalid revision: note must be at most 1000 characters")
+	}
+	if value, ok := updates["isbn"].(string); ok {
+		isbn, ok := utils.CanonicalISBN(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid isbn")
+		}
+		updates["isbn"] = isbn
+		if err := s.books.checkISBNAvailable(isbn, bookID); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateBookUpdates(updates); err != nil {
+		return nil, err
+	}
+
+	var book models.Book
+	if err := s.db.First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+	_, updatingAuthor := updates["author_id"]
+	_, updatingCategory := updates["category_id"]
+	if updatingAuthor || updatingCategory {
+		authorID, categoryID := book.AuthorID, book.CategoryID
+		if id, ok := updates["author_id"].(uuid.UUID); ok {
+			authorID = id
+		}
+		if id, ok := updates["category_id"].(uuid.UUID); ok {
+			categoryID = id
+		}
+		if err := s.books.validateAuthorAndCategory(authorID, categoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := revisableValues(&book)
+	if err != nil {
+		return nil, err
+	}
+	changes := models.FieldChanges{}
+	for field, value := range updates {
+		if _, ok := current[field]; !ok {
+			return nil, fmt.Errorf("invalid revision: %s cannot be revised", field)
+		}
+		to, err := jsonValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current[field], to) {
+			changes[field] = models.FieldChange{From: current[field], To: to}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("invalid revision: nothing would change")
+	}
+
+	revision := &models.BookRevision{
+		BookID:      bookID,
+		Status:      models.RevisionStatusPending,
+		Changes:     changes,
+		Note:        strings.TrimSpace(note),
+		SubmittedBy: &userID,
+	}
+	if err := s.db.Omit(clause.Associations).Create(revision).Error; err != nil {
+		return nil, fmt.Errorf("failed to create revision: %w", err)
+	}
+	return revision, nil
+}
+
+// GetRevisions retrieves revisions matching filter with pagination, oldest
+// first so pending revisions are reviewed in the order they were submitted
+// unless filter asks for the newest first, with the title and ISBN of each
+// book
+func (s *RevisionService) GetRevisions(filter RevisionFilter, page, limit int) ([]models.BookRevision, int64, error) {
+	query := s.db.Model(&models.BookRevision{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.BookID != uuid.Nil {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	if filter.SubmittedBy != uuid.Nil {
+		query = query.Where("submitted_by = ?", filter.SubmittedBy)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	order := "created_at, id"
+	if filter.NewestFirst {
+		order = "created_at DESC, id DESC"
+	}
+	var revisions []models.BookRevision
+	if err := query.Preload("Book", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Select("id", "title", "isbn", "slug")
+	}).Order(order).Offset((page - 1) * limit).Limit(limit).Find(&revisions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	return revisions, total, nil
+}
+
+// GetRevisionByID retrieves a revision with its book as it is now
+func (s *RevisionService) GetRevisionByID(id uuid.UUID) (*models.BookRevision, error) {
+	var revision models.BookRevision
+	if err := s.db.Preload("Book", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).First(&revision, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("revision not found")
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return &revision, nil
+}
+
+// ApproveRevision applies a pending revision to the live catalog. A
+// revision of fields that changed since it was submitted is refused unless
+// force is set, so an editor's stale values do not silently overwrite newer
+// ones.
+func (s *RevisionService) ApproveRevision(id uuid.UUID, reviewerID *uuid.UUID, note string, force bool) (*models.BookRevision, error) {
+	var revision *models.BookRevision
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockPendingRevision(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var book models.Book
+		if err := tx.First(&book, "id = ?", current.BookID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("book not found")
+			}
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		values, err := revisableValues(&book)
+		if err != nil {
+			return err
+		}
+		if !force {
+			for field, change := range current.Changes {
+				if !reflect.DeepEqual(values[field], change.From) {
+					return fmt.Errorf("revision conflicts with later changes to %s", field)
+				}
+			}
+		}
+
+		to := map[string]interface{}{}
+		for field, change := range current.Changes {
+			to[field] = change.To
+		}
+		updates, err := revisionUpdates(to)
+		if err != nil {
+			return err
+		}
+		books := *s.books
+		books.db = tx
+		if err := books.updateBook(current.BookID, updates, current); err != nil {
+			return err
+		}
+
+		if err := reviewRevision(tx, current, models.RevisionStatusApproved, reviewerID, note); err != nil {
+			return err
+		}
+		revision = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// RevertRevision undoes an approved revision of a book, setting the fields
+// it changed back to their values before it, and records the revert as a
+// new revision. Fields changed again since are not reverted over unless
+// force is set.
+func (s *RevisionService) RevertRevision(bookID, revisionID uuid.UUID, force bool) (*models.BookRevision, error) {
+	var revert *models.BookRevision
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var target models.BookRevision
+		if err := tx.First(&target, "id = ? AND book_id = ?", revisionID, bookID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("revision not found")
+			}
+			return fmt.Errorf("failed to get revision: %w", err)
+		}
+		if target.Status != models.RevisionStatusApproved {
+			return fmt.Errorf("revision is not approved")
+		}
+
+		var book models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, "id = ?", bookID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("book not found")
+			}
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		values, err := revisableValues(&book)
+		if err != nil {
+			return err
+		}
+		from := map[string]interface{}{}
+		for field, change := range target.Changes {
+			if !force && !reflect.DeepEqual(values[field], change.To) {
+				return fmt.Errorf("revision conflicts with later changes to %s", field)
+			}
+			from[field] = change.From
+		}
+		updates, err := revisionUpdates(from)
+		if err != nil {
+			return err
+		}
+
+		revision := &models.BookRevision{
+			RevertedFrom: &target.ID,
+			Note:         fmt.Sprintf("Revert of revision %s", target.ID),
+		}
+		books := *s.books
+		books.db = tx
+		if err := books.updateBook(bookID, updates, revision); err != nil {
+			return err
+		}
+		if revision.ID == uuid.Nil {
+			return fmt.Errorf("invalid revision: nothing would change")
+		}
+		revert = revision
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revert, nil
+}
+
+// RejectRevision rejects a pending revision, leaving the book unchanged
+func (s *RevisionService) RejectRevision(id uuid.UUID, reviewerID *uuid.UUID, note string) (*models.BookRevision, error) {
+	var revision *models.BookRevision
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockPendingRevision(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := reviewRevision(tx, current, models.RevisionStatusRejected, reviewerID, note); err != nil {
+			return err
+		}
+		revision = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// lockPendingRevision locks a revision for review, failing unless it is
+// pending
+func lockPendingRevision(tx *gorm.DB, id uuid.UUID) (*models.BookRevision, error) {
+	var revision models.BookRevision
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&revision, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("revision not found")
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	if revision.Status != models.RevisionStatusPending {
+		return nil, fmt.Errorf("revision is not pending")
+	}
+	return &revision, nil
+}
+
+// reviewRevision records the outcome of a review. The reviewer is nil for
+// admin API keys, which belong to no user.
+func reviewRevision(tx *gorm.DB, revision *models.BookRevision, status string, reviewerID *uuid.UUID, note string) error {
+	note = strings.TrimSpace(note)
+	if len(note) > 1000 {
+		return fmt.Errorf("invalid revision: review note must be at most 1000 characters")
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"reviewed_by": reviewerID,
+		"review_note": note,
+		"reviewed_at": now,
+	}
+	if revision.Snapshot != nil {
+		updates["snapshot"] = revision.Snapshot
+	}
+	if err := tx.Model(revision).Omit(clause.Associations).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to review revision: %w", err)
+	}
+	revision.Status = status
+	revision.ReviewedBy = reviewerID
+	revision.ReviewNote = note
+	revision.ReviewedAt = &now
+	return nil
+}
+
+// recordRevision records the update of a book from previous to book in its
+// history. A pending revision being approved gets the snapshot, saved when
+// its review is; otherwise the fields that changed are recorded as a new
+// approved revision by the context's principal, built on revision when set.
+// Updates of fields outside the history, such as stock, record nothing.
+func recordRevision(tx *gorm.DB, previous, book *models.Book, revision *models.BookRevision) error {
+	before, err := revisableValues(previous)
+	if err != nil {
+		return err
+	}
+	after, err := revisableValues(book)
+	if err != nil {
+		return err
+	}
+	if revision != nil && revision.ID != uuid.Nil {
+		revision.Snapshot = after
+		return nil
+	}
+
+	changes := models.FieldChanges{}
+	for _, field := range revisableFields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes[field] = models.FieldChange{From: before[field], To: after[field]}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if revision == nil {
+		revision = &models.BookRevision{}
+	}
+	now := time.Now()
+	revision.BookID = book.ID
+	revision.Status = models.RevisionStatusApproved
+	revision.Changes = changes
+	revision.Snapshot = after
+	revision.ReviewedAt = &now
+	if principal := access.FromContext(tx.Statement.Context); principal != nil && principal.UserID != uuid.Nil {
+		userID := principal.UserID
+		revision.SubmittedBy = &userID
+		revision.ReviewedBy = &userID
+	}
+	if err := tx.Omit(clause.Associations).Create(revision).Error; err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	return nil
+}
+
+// revisableValues returns the book's revisable fields as JSON values, so
+// they compare equal to the values stored in revisions
+func revisableValues(book *models.Book) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	fields := map[string]interface{}{
+		"title":        book.Title,
+		"isbn":         book.ISBN,
+		"description":  book.Description,
+		"price":        book.Price,
+		"published_at": book.PublishedAt,
+		"author_id":    book.AuthorID,
+		"category_id":  book.CategoryID,
+		"format":       book.Format,
+		"page_count":   book.PageCount,
+	}
+	for _, field := range revisableFields {
+		value, err := jsonValue(fields[field])
+		if err != nil {
+			return nil, err
+		}
+		values[field] = value
+	}
+	return values, nil
+}
+
+// jsonValue returns value as it reads back from JSON
+func jsonValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revision value: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode revision value: %w", err)
+	}
+	return decoded, nil
+}
+
+// revisionUpdates converts revised fields' JSON values back into the column
+// values BookService.UpdateBook takes
+func revisionUpdates(values map[string]interface{}) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+	for field, value := range values {
+		switch field {
+		case "title", "isbn", "description", "status", "format":
+			value, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid revision value for %s", field)
+			}
+			updates[field] = value
+		case "price":
+			value, ok := value.(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid revision value for %s", field)
+			}
+			updates[field] = value
+		case "published_at":
+			if value == nil {
+				updates[field] = nil
+				continue
+			}
+			text, _ := value.(string)
+			date, err := models.ParseDateOnly(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid revision value for %s: %w", field, err)
+			}
+			updates[field] = date
+		case "page_count":
+			if value == nil {
+				updates[field] = nil
+				continue
+			}
+			value, ok := value.(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid revision value for %s", field)
+			}
+			updates[field] = int(value)
+		case "author_id", "category_id":
+			text, _ := value.(string)
+			id, err := uuid.Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid revision value for %s: %w", field, err)
+			}
+			updates[field] = id
+		default:
+			return nil, fmt.Errorf("invalid revision value for %s", field)
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSearchQueryLength caps the characters of a search query
+const MaxSearchQueryLength = 200
+
+// likeEscaper escapes the characters ILIKE treats specially, with the
+// backslash Postgres uses as its default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchQuery is a search query as a user typed it. Its text matches
+// anywhere in a field, unless the query was wrapped in double quotes, which
+// makes it match the whole field. Either way, case is ignored and % and _
+// match only themselves.
+type SearchQuery struct {
+	Text  string
+	Exact bool
+}
+
+// ParseSearchQuery parses raw, rejecting queries longer than
+// MaxSearchQueryLength. A query of only spaces or of "" has empty text.
+func ParseSearchQuery(raw string) (SearchQuery, error) {
+	raw = strings.TrimSpace(raw)
+	if utf8.RuneCountInString(raw) > MaxSearchQueryLength {
+		return SearchQuery{}, fmt.Errorf("invalid search query: query must be at most %d characters", MaxSearchQueryLength)
+	}
+	return newSearchQuery(raw), nil
+}
+
+// newSearchQuery parses raw without checking its length, for queries
+// validated when they were saved
+func newSearchQuery(raw string) SearchQuery {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 2 && strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) {
+		return SearchQuery{Text: strings.TrimSpace(raw[1 : len(raw)-1]), Exact: true}
+	}
+	return SearchQuery{Text: raw}
+}
+
+// Pattern returns the ILIKE pattern matching fields the query matches
+func (q SearchQuery) Pattern() string {
+	if q.Exact {
+		return likeEscaper.Replace(q.Text)
+	}
+	return "%" + likeEscaper.Replace(q.Text) + "%"
+}
+
+// String returns the query as a user would type it, quoted when exact
+func (q SearchQuery) String() string {
+	if q.Exact {
+		return `"` + q.Text + `"`
+	}
+	return q.Text
+}
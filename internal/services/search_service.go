@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchPage selects a page of one group of search results
+type SearchPage struct {
+	Page  int
+	Limit int
+}
+
+// SearchOptions selects the page of each group of search results
+type SearchOptions struct {
+	Books      SearchPage
+	Authors    SearchPage
+	Categories SearchPage
+}
+
+// SearchResults holds one page of matching books, authors, and categories,
+// each ranked by how closely its title or name matches the query
+type SearchResults struct {
+	Books           []models.Book
+	BooksTotal      int64
+	Authors         []models.Author
+	AuthorsTotal    int64
+	Categories      []models.Category
+	CategoriesTotal int64
+}
+
+// SearchService searches books, authors, and categories at once
+type SearchService struct {
+	db     *gorm.DB
+	counts *CountCache
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{
+		db:     db,
+		counts: GetCountCache(),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *SearchService) WithContext(ctx context.Context) *SearchService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// Search queries books, authors, and categories concurrently. Active books
+// match on title, ISBN, or description, authors on name or email, and categories on
+// name or description, as in their own search endpoints. Within each group,
+// exact title or name matches rank first, then prefix matches, then other
+// title or name matches, then matches on the other fields.
+func (s *SearchService) Search(query SearchQuery, opts SearchOptions) (*SearchResults, error) {
+	pattern := query.Pattern()
+	results := &SearchResults{}
+
+	var group errgroup.Group
+	group.Go(func() error {
+		where := s.db.Where("status = ?", models.BookStatusActive).
+			Where(s.db.Where("title ILIKE ? OR isbn ILIKE ? OR description ILIKE ?", pattern, pattern, pattern))
+		total, err := s.counts.Count("books:search:"+query.String()+":"+models.BookStatusActive, s.db.Model(&models.Book{}).Where(where))
+		if err != nil {
+			return fmt.Errorf("failed to count books: %w", err)
+		}
+		var books []models.Book
+		if err := s.page(where, "title", query, opts.Books).Preload("Author").Preload("Category").Find(&books).Error; err != nil {
+			return fmt.Errorf("failed to search books: %w", err)
+		}
+		if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+			return err
+		}
+		results.Books, results.BooksTotal = books, total
+		return nil
+	})
+	group.Go(func() error {
+		where := s.db.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+		total, err := s.counts.Count("authors:search:"+query.String(), s.db.Model(&models.Author{}).Where(where))
+		if err != nil {
+			return fmt.Errorf("failed to count authors: %w", err)
+		}
+		var authors []models.Author
+		if err := s.page(where, "name", query, opts.Authors).Find(&authors).Error; err != nil {
+			return fmt.Errorf("failed to search authors: %w", err)
+		}
+		if err := setAuthorBooksCounts(s.db, authors); err != nil {
+			return err
+		}
+		results.Authors, results.AuthorsTotal = authors, total
+		return nil
+	})
+	group.Go(func() error {
+		where := s.db.Where("name ILIKE ? OR description ILIKE ?", pattern, pattern)
+		total, err := s.counts.Count("categories:search:"+query.String(), s.db.Model(&models.Category{}).Where(where))
+		if err != nil {
+			return fmt.Errorf("failed to count categories: %w", err)
+		}
+		var categories []models.Category
+		if err := s.page(where, "name", query, opts.Categories).Find(&categories).Error; err != nil {
+			return fmt.Errorf("failed to search categories: %w", err)
+		}
+		if err := setCategoryBooksCounts(s.db, categories); err != nil {
+			return err
+		}
+		results.Categories, results.CategoriesTotal = categories, total
+		return nil
+	})
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// page returns a query for one page of the rows matching where, ranked by how
+// closely column matches query
+func (s *SearchService) page(where *gorm.DB, column string, query SearchQuery, page SearchPage) *gorm.DB {
+	text := likeEscaper.Replace(query.Text)
+	rank := clause.Expr{
+		SQL: "CASE WHEN " + column + " ILIKE ? THEN 0 WHEN " + column + " ILIKE ? THEN 1 WHEN " + column + " ILIKE ? THEN 2 ELSE 3 END, " +
+			column + ", id",
+		Vars:               []interface{}{text, text + "%", "%" + text + "%"},
+		WithoutParentheses: true,
+	}
+	return s.db.Where(where).Order(clause.OrderBy{Expression: rank}).Offset((page.Page - 1) * page.Limit).Limit(page.Limit)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginAttempt describes a sign-in or token refresh attempt
+type LoginAttempt struct {
+	IPAddress string
+	UserAgent string
+	// UserID is the account the attempt was for, when it is known
+	UserID *uuid.UUID
+}
+
+// SecurityService counts failed logins for brute-force protection and records
+// security events for the auth endpoints
+type SecurityService struct {
+	db    *gorm.DB
+	ctx   context.Context
+	guard *auth.LoginGuard
+}
+
+// NewSecurityService creates a new security service
+func NewSecurityService(db *gorm.DB) *SecurityService {
+	return &SecurityService{
+		db:    db,
+		ctx:   context.Background(),
+		guard: auth.GetLoginGuard(),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *SecurityService) WithContext(ctx context.Context) *SecurityService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// LockedOut returns how long attempts from the IP address, or for the
+// account when it is known, remain locked out, or zero
+func (s *SecurityService) LockedOut(attempt LoginAttempt) time.Duration {
+	wait := s.guard.LockedOut(s.ipKey(attempt))
+	if key, ok := s.accountKey(attempt); ok {
+		if accountWait := s.guard.LockedOut(key); accountWait > wait {
+			wait = accountWait
+		}
+	}
+	return wait
+}
+
+// CaptchaRequired reports whether attempts from the IP address must carry a
+// solved CAPTCHA
+func (s *SecurityService) CaptchaRequired(attempt LoginAttempt) bool {
+	return s.guard.CaptchaRequired(s.ipKey(attempt))
+}
+
+// VerifyCaptcha checks the CAPTCHA response sent with an attempt
+func (s *SecurityService) VerifyCaptcha(attempt LoginAttempt, response string) error {
+	verifier := s.guard.Captcha()
+	if verifier == nil {
+		return nil
+	}
+	return verifier.Verify(s.ctx, response, attempt.IPAddress)
+}
+
+// Failed counts a failed attempt against the IP address and the account, and
+// records it as an event of the given type. An IP address or account reaching
+// the lockout threshold is locked out, which is recorded too.
+func (s *SecurityService) Failed(eventType string, attempt LoginAttempt, reason string) {
+	failures, lockout := s.guard.Fail(s.ipKey(attempt))
+	s.Record(eventType, attempt, models.StringMap{
+		"reason":   reason,
+		"failures": strconv.Itoa(failures),
+	})
+	if lockout > 0 {
+		s.Record(models.SecurityEventLockout, attempt, models.StringMap{
+			"scope":    "ip",
+			"duration": lockout.String(),
+			"failures": strconv.Itoa(failures),
+		})
+	}
+
+	key, ok := s.accountKey(attempt)
+	if !ok {
+		return
+	}
+	failures, lockout = s.guard.Fail(key)
+	if lockout > 0 {
+		s.Record(models.SecurityEventLockout, attempt, models.StringMap{
+			"scope":    "account",
+			"duration": lockout.String(),
+			"failures": strconv.Itoa(failures),
+		})
+	}
+}
+
+// LoginSucceeded records a successful sign-in and clears the account's
+// failures. It must be called before the sign-in's session is created: a
+// sign-in from an IP address and browser the user has never signed in from
+// is recorded as a possible anomaly, as are the IP address's earlier failures.
+func (s *SecurityService) LoginSucceeded(attempt LoginAttempt) {
+	details := models.StringMap{}
+	if failures := s.guard.Failures(s.ipKey(attempt)); failures > 0 {
+		details["prior_ip_failures"] = strconv.Itoa(failures)
+	}
+	s.Record(models.SecurityEventLoginSucceeded, attempt, details)
+
+	key, ok := s.accountKey(attempt)
+	if !ok {
+		return
+	}
+	s.guard.Reset(key)
+
+	newDevice, err := s.isNewDevice(*attempt.UserID, attempt.IPAddress, attempt.UserAgent)
+	if err != nil {
+		utils.LogError("Failed to check sign-in device", map[string]interface{}{
+			"user_id": attempt.UserID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if newDevice {
+		s.Record(models.SecurityEventNewDevice, attempt, details)
+	}
+}
+
+// RefreshSucceeded clears the failures of the account whose token was refreshed
+func (s *SecurityService) RefreshSucceeded(attempt LoginAttempt) {
+	if key, ok := s.accountKey(attempt); ok {
+		s.guard.Reset(key)
+	}
+}
+
+// Record appends a security event to the log. Events are also written to the
+// application log, and a failure to store one is logged rather than returned
+// so the request it describes is answered regardless.
+func (s *SecurityService) Record(eventType string, attempt LoginAttempt, details models.StringMap) {
+	if details == nil {
+		details = models.StringMap{}
+	}
+	event := &models.SecurityEvent{
+		Type:      eventType,
+		UserID:    attempt.UserID,
+		IPAddress: attempt.IPAddress,
+		UserAgent: truncate(attempt.UserAgent, 512),
+		Details:   details,
+	}
+
+	if eventType == models.SecurityEventLoginSucceeded {
+		utils.LogInfo("Security event: "+eventType, event)
+	} else {
+		utils.LogWarn("Security event: "+eventType, event)
+	}
+	if err := s.db.Create(event).Error; err != nil {
+		utils.LogError("Failed to record security event", map[string]interface{}{
+			"type":  eventType,
+			"error": err.Error(),
+		})
+	}
+}
+
+// GetSecurityEvents lists security events, newest first, optionally filtered
+// by type and user
+func (s *SecurityService) GetSecurityEvents(eventType string, userID *uuid.UUID, page, limit int) ([]models.SecurityEvent, int64, error) {
+	var events []models.SecurityEvent
+	var total int64
+
+	query := s.db.Model(&models.SecurityEvent{})
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count security events: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get security events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// isNewDevice reports whether the user has signed in before, but neither from
+// the IP address nor with the user agent. Sessions are the record of earlier
+// sign-ins, so devices are remembered until their sessions are purged.
+func (s *SecurityService) isNewDevice(userID uuid.UUID, ipAddress, userAgent string) (bool, error) {
+	var sessions int64
+	if err := s.db.Model(&models.Session{}).Where("user_id = ?", userID).Count(&sessions).Error; err != nil {
+		return false, fmt.Errorf("failed to check sessions: %w", err)
+	}
+	if sessions == 0 {
+		return false, nil
+	}
+
+	var known int64
+	if err := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND (ip_address = ? OR user_agent = ?)", userID, ipAddress, truncate(userAgent, 512)).
+		Count(&known).Error; err != nil {
+		return false, fmt.Errorf("failed to check sessions: %w", err)
+	}
+	return known == 0, nil
+}
+
+// ipKey returns the key the attempt's IP address is counted under
+func (s *SecurityService) ipKey(attempt LoginAttempt) string {
+	tenantID, _ := tenancy.TenantID(s.ctx)
+	return auth.IPKey(tenantID, attempt.IPAddress)
+}
+
+// accountKey returns the key the attempt's account is counted under. ok is
+// false when the account is not known.
+func (s *SecurityService) accountKey(attempt LoginAttempt) (string, bool) {
+	if attempt.UserID == nil {
+		return "", false
+	}
+	tenantID, _ := tenancy.TenantID(s.ctx)
+	return auth.AccountKey(tenantID, *attempt.UserID), true
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// Services holds one of each service, all querying the same database
+// handle, for the HTTP and gRPC servers to share. Each request scopes the
+// service it uses with WithContext.
+type Services struct {
+	Alerts        *AlertService
+	APIKeys       *APIKeyService
+	Archives      *ArchiveService
+	AuthorPhotos  *AuthorPhotoService
+	Authors       *AuthorService
+	BookPreviews  *BookPreviewService
+	Books         *BookService
+	Categories    *CategoryService
+	Feeds         *FeedService
+	Maintenance   *MaintenanceService
+	Notifications *NotificationService
+	ONIX          *ONIXService
+	Preferences   *PreferenceService
+	Promotions    *PromotionService
+	Rentals       *RentalService
+	Reports       *ReportService
+	Revisions     *RevisionService
+	Search        *SearchService
+	Security      *SecurityService
+	Sessions      *SessionService
+	Sitemaps      *SitemapService
+	Stats         *StatsService
+	Suppliers     *SupplierService
+	Tenants       *TenantService
+	Translations  *TranslationService
+	Users         *UserService
+	Views         *ViewService
+	Warehouses    *WarehouseService
+	Webhooks      *WebhookService
+
+	// Listings serves book listings from the read model, and is nil unless
+	// read models are enabled
+	Listings *ListingService
+}
+
+// New builds every service on db, keeping uploaded media such as author
+// photos and book previews in media. It must be called once the count and
+// revocation caches and the login guard are initialized.
+func New(db *gorm.DB, cfg *config.Config, media storage.Storage) *Services {
+	s := &Services{
+		Alerts:        NewAlertService(db),
+		APIKeys:       NewAPIKeyService(db),
+		Archives:      NewArchiveService(db, storage.NewLocal(cfg.Archive.Dir, ""), cfg.Archive.BatchSize),
+		AuthorPhotos:  NewAuthorPhotoService(db, media),
+		Authors:       NewAuthorService(db),
+		BookPreviews:  NewBookPreviewService(db, media),
+		Books:         NewBookService(db),
+		Categories:    NewCategoryService(db),
+		Feeds:         NewFeedService(db, cfg),
+		Maintenance:   NewMaintenanceService(db),
+		Notifications: NewNotificationService(db),
+		ONIX:          NewONIXService(db, cfg.ONIX),
+		Preferences:   NewPreferenceService(db, cfg),
+		Promotions:    NewPromotionService(db),
+		Rentals:       NewRentalService(db, cfg),
+		Reports:       NewReportService(db),
+		Revisions:     NewRevisionService(db),
+		Search:        NewSearchService(db),
+		Security:      NewSecurityService(db),
+		Sessions:      NewSessionService(db, cfg),
+		Sitemaps:      NewSitemapService(db, cfg),
+		Stats:         NewStatsService(db),
+		Suppliers:     NewSupplierService(db, cfg.Suppliers),
+		Tenants:       NewTenantService(db),
+		Translations:  NewTranslationService(db),
+		Users:         NewUserService(db),
+		Views:         NewViewService(db),
+		Warehouses:    NewWarehouseService(db),
+		Webhooks:      NewWebhookService(db),
+	}
+	if cfg.ReadModels.Enabled {
+		s.Listings = NewListingService(db)
+	}
+	return s
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxRevocationCacheEntries bounds the number of cached session statuses
+const maxRevocationCacheEntries = 10000
+
+var (
+	revocationCache     *RevocationCache
+	revocationCacheOnce sync.Once
+)
+
+// SessionService handles login sessions, refresh token rotation, and revocation
+type SessionService struct {
+	db         *gorm.DB
+	refreshTTL time.Duration
+	revoked    *RevocationCache
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(db *gorm.DB, cfg *config.Config) *SessionService {
+	refreshTTL := cfg.Auth.RefreshTokenTTL
+	if refreshTTL <= 0 {
+		refreshTTL = 30 * 24 * time.Hour
+	}
+
+	return &SessionService{
+		db:         db,
+		refreshTTL: refreshTTL,
+		revoked:    GetRevocationCache(),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *SessionService) WithContext(ctx context.Context) *SessionService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreateSession starts a session for the user and returns its refresh token
+func (s *SessionService) CreateSession(userID uuid.UUID, userAgent, ipAddress string) (*models.Session, string, error) {
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:           userID,
+		RefreshTokenHash: hash,
+		UserAgent:        truncate(userAgent, 512),
+		IPAddress:        ipAddress,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.refreshTTL),
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, token, nil
+}
+
+// RefreshTokenUser returns the user of the session a refresh token was issued
+// for, whether it is the session's current or previous token. ok is false for
+// tokens that match no session.
+func (s *SessionService) RefreshTokenUser(refreshToken string) (uuid.UUID, bool, error) {
+	hash := auth.HashRefreshToken(refreshToken)
+	var session models.Session
+	err := s.db.Select("id", "user_id").
+		Where("refresh_token_hash = ? OR previous_token_hash = ?", hash, hash).First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to find session: %w", err)
+	}
+	return session.UserID, true, nil
+}
+
+// RefreshSession rotates a session's refresh token and returns the session, its
+// user, and the new token. A refresh token that was already rotated is treated
+// as stolen and revokes the whole session.
+func (s *SessionService) RefreshSession(refreshToken, userAgent, ipAddress string) (*models.Session, *models.User, string, error) {
+	hash := auth.HashRefreshToken(refreshToken)
+	token, newHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var session models.Session
+	var user models.User
+	reused := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("refresh_token_hash = ?", hash).First(&session).Error
+		if err == gorm.ErrRecordNotFound {
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("previous_token_hash = ?", hash).First(&session).Error
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("invalid refresh token")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to find session: %w", err)
+			}
+			reused = true
+			return s.revoke(tx, &session)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find session: %w", err)
+		}
+
+		now := time.Now()
+		if !session.Active(now) {
+			return fmt.Errorf("invalid refresh token")
+		}
+		if err := tx.First(&user, "id = ?", session.UserID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("user account is disabled")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		session.PreviousTokenHash = &session.RefreshTokenHash
+		session.RefreshTokenHash = newHash
+		session.LastUsedAt = now
+		session.UserAgent = truncate(userAgent, 512)
+		session.IPAddress = ipAddress
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"refresh_token_hash":  session.RefreshTokenHash,
+			"previous_token_hash": session.PreviousTokenHash,
+			"last_used_at":        now,
+			"user_agent":          session.UserAgent,
+			"ip_address":          ipAddress,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	// The revocation has to commit, so report the reuse only after the transaction
+	if reused {
+		s.revoked.Set(session.ID, true)
+		return nil, nil, "", fmt.Errorf("refresh token reused")
+	}
+
+	return &session, &user, token, nil
+}
+
+// GetUserSessions lists the user's active sessions, most recently used first
+func (s *SessionService) GetUserSessions(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions
+func (s *SessionService) RevokeSession(userID, sessionID uuid.UUID) error {
+	var session models.Session
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("session not found")
+			}
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		return s.revoke(tx, &session)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revoked.Set(session.ID, true)
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user and returns how many were revoked
+func (s *SessionService) RevokeAllSessions(userID uuid.UUID) (int64, error) {
+	var ids []uuid.UUID
+	if err := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	result := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", result.Error)
+	}
+
+	for _, id := range ids {
+		s.revoked.Set(id, true)
+	}
+	return result.RowsAffected, nil
+}
+
+// IsRevoked reports whether access tokens for the session must be rejected.
+// Results are cached briefly, so a revocation on another instance takes effect
+// within the cache TTL.
+func (s *SessionService) IsRevoked(sessionID uuid.UUID) (bool, error) {
+	if revoked, ok := s.revoked.Get(sessionID); ok {
+		return revoked, nil
+	}
+
+	var session models.Session
+	err := s.db.Select("id", "revoked_at", "expires_at").First(&session, "id = ?", sessionID).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	// Purged sessions count as revoked
+	revoked := err == gorm.ErrRecordNotFound || !session.Active(time.Now())
+	s.revoked.Set(sessionID, revoked)
+	return revoked, nil
+}
+
+// PurgeExpiredSessions deletes sessions that expired before the given time
+func (s *SessionService) PurgeExpiredSessions(before time.Time) (int64, error) {
+	result := s.db.Where("expires_at < ?", before).Delete(&models.Session{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// revoke marks a session as revoked
+func (s *SessionService) revoke(tx *gorm.DB, session *models.Session) error {
+	if session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	if err := tx.Model(session).Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// revocationEntry is a cached session status with its expiry time
+type revocationEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// RevocationCache caches session revocation status checked on every authenticated request
+type RevocationCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[uuid.UUID]revocationEntry
+}
+
+// NewRevocationCache creates a new revocation cache
+func NewRevocationCache(ttl time.Duration) *RevocationCache {
+	if ttl <= 0 {
+		ttl = 10 * time.Second
+	}
+	return &RevocationCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]revocationEntry),
+	}
+}
+
+// InitializeRevocationCache initializes the shared revocation cache from configuration
+func InitializeRevocationCache(cfg *config.Config) {
+	revocationCacheOnce.Do(func() {
+		revocationCache = NewRevocationCache(cfg.Auth.RevocationCacheTTL)
+	})
+}
+
+// GetRevocationCache returns the shared revocation cache
+func GetRevocationCache() *RevocationCache {
+	revocationCacheOnce.Do(func() {
+		revocationCache = NewRevocationCache(0)
+	})
+	return revocationCache
+}
+
+// Get returns the cached status of a session
+func (c *RevocationCache) Get(sessionID uuid.UUID) (bool, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[sessionID]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+	return entry.revoked, true
+}
+
+// Set caches the status of a session, dropping expired entries when the cache is full
+func (c *RevocationCache) Set(sessionID uuid.UUID, revoked bool) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxRevocationCacheEntries {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		// Everything is still fresh; start over rather than grow without bound
+		if len(c.entries) >= maxRevocationCacheEntries {
+			c.entries = make(map[uuid.UUID]revocationEntry)
+		}
+	}
+	c.entries[sessionID] = revocationEntry{revoked: revoked, expiresAt: now.Add(c.ttl)}
+}
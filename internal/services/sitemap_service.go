@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/storage"
+	"bookstore-api/internal/storefront"
+	"bookstore-api/internal/tenancy"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxSitemapPageSize is the most URLs the sitemap protocol allows per file
+const maxSitemapPageSize = 50000
+
+// sitemapPagePattern matches the names of sitemap pages, <section>-<page>.xml
+var sitemapPagePattern = regexp.MustCompile(`^(books|authors|categories)-([1-9][0-9]*)\.xml$`)
+
+// SitemapService generates the sitemaps of the context's tenant and keeps
+// them in storage until they are next generated
+type SitemapService struct {
+	db       *gorm.DB
+	ctx      context.Context
+	store    storage.Storage
+	links    *storefront.Links
+	pageSize int
+}
+
+// NewSitemapService creates a new sitemap service keeping sitemaps under the
+// configured feeds directory
+func NewSitemapService(db *gorm.DB, cfg *config.Config) *SitemapService {
+	pageSize := cfg.Feeds.SitemapPageSize
+	if pageSize <= 0 || pageSize > maxSitemapPageSize {
+		pageSize = maxSitemapPageSize
+	}
+	return &SitemapService{
+		db:       db,
+		ctx:      context.Background(),
+		store:    storage.NewLocal(cfg.Feeds.Dir, ""),
+		links:    storefront.NewLinks(cfg.Storefront),
+		pageSize: pageSize,
+	}
+}
+
+// WithContext returns a copy of the service whose queries and storage calls
+// run with ctx, which carries the tenant they are scoped to
+func (s *SitemapService) WithContext(ctx context.Context) *SitemapService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// sitemapEntry is a page listed in a sitemap, by its slug
+type sitemapEntry struct {
+	Slug      string
+	UpdatedAt time.Time
+}
+
+// GenerateSitemaps renders the sitemaps of the tenant's active books, its
+// authors, and its categories, split into pages of the configured size,
+// and the index listing them. It returns the number of URLs in each section.
+func (s *SitemapService) GenerateSitemaps() (map[string]int, error) {
+	tenant := tenancy.FromContext(s.ctx)
+	if tenant == nil {
+		return nil, fmt.Errorf("failed to generate sitemaps: no tenant")
+	}
+
+	counts := make(map[string]int, len(storefront.SitemapSections))
+	var refs []storefront.SitemapRef
+	for _, section := range storefront.SitemapSections {
+		entries, err := s.sectionEntries(section)
+		if err != nil {
+			return nil, err
+		}
+		sectionRefs, err := s.storeSection(tenant, section, entries)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, sectionRefs...)
+		counts[section] = len(entries)
+	}
+
+	data, err := storefront.RenderSitemapIndex(refs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(s.ctx, sitemapKey(tenant, storefront.SitemapIndex), "application/xml", data); err != nil {
+		return nil, fmt.Errorf("failed to store sitemap index: %w", err)
+	}
+	return counts, nil
+}
+
+// OpenSitemap opens the stored sitemap index or a sitemap page of the
+// tenant, generating the tenant's sitemaps first when they have not been
+// generated yet
+func (s *SitemapService) OpenSitemap(name string) (io.ReadSeekCloser, error) {
+	if name != storefront.SitemapIndex && !sitemapPagePattern.MatchString(name) {
+		return nil, fmt.Errorf("sitemap not found")
+	}
+	tenant := tenancy.FromContext(s.ctx)
+	if tenant == nil {
+		return nil, fmt.Errorf("sitemap not found")
+	}
+
+	file, err := s.store.Open(s.ctx, sitemapKey(tenant, name))
+	if errors.Is(err, os.ErrNotExist) {
+		// A page missing once the index exists is past the end of its section
+		if index, err := s.store.Open(s.ctx, sitemapKey(tenant, storefront.SitemapIndex)); err == nil {
+			index.Close()
+			return nil, fmt.Errorf("sitemap not found")
+		}
+		if _, err := s.GenerateSitemaps(); err != nil {
+			return nil, err
+		}
+		file, err = s.store.Open(s.ctx, sitemapKey(tenant, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("sitemap not found")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// sectionEntries returns the slugs and modification times of a section's
+// pages, in a stable order so URLs keep their sitemap page between runs
+func (s *SitemapService) sectionEntries(section string) ([]sitemapEntry, error) {
+	var query *gorm.DB
+	switch section {
+	case storefront.SitemapBooks:
+		query = s.db.Model(&models.Book{}).Where("books.status = ?", models.BookStatusActive)
+	case storefront.SitemapAuthors:
+		query = s.db.Model(&models.Author{})
+	case storefront.SitemapCategories:
+		query = s.db.Model(&models.Category{})
+	default:
+		return nil, fmt.Errorf("unknown sitemap section: %s", section)
+	}
+
+	var entries []sitemapEntry
+	if err := query.Select("slug", "updated_at").Order("id").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", section, err)
+	}
+	return entries, nil
+}
+
+// storeSection renders and stores a section's sitemap pages, removes pages
+// left over from a run when the section was larger, and returns the pages
+// for the index
+func (s *SitemapService) storeSection(tenant *models.Tenant, section string, entries []sitemapEntry) ([]storefront.SitemapRef, error) {
+	var refs []storefront.SitemapRef
+	for start := 0; start < len(entries); start += s.pageSize {
+		end := min(start+s.pageSize, len(entries))
+		pages := make([]storefront.Page, 0, end-start)
+		var lastMod time.Time
+		for _, entry := range entries[start:end] {
+			pages = append(pages, storefront.Page{Loc: s.pageURL(tenant, section, entry.Slug), LastMod: entry.UpdatedAt})
+			if entry.UpdatedAt.After(lastMod) {
+				lastMod = entry.UpdatedAt
+			}
+		}
+
+		data, err := storefront.RenderSitemap(pages)
+		if err != nil {
+			return nil, err
+		}
+		name := sitemapPageName(section, len(refs)+1)
+		if err := s.store.Put(s.ctx, sitemapKey(tenant, name), "application/xml", data); err != nil {
+			return nil, fmt.Errorf("failed to store sitemap %s: %w", name, err)
+		}
+		refs = append(refs, storefront.SitemapRef{Loc: s.links.Sitemap(tenant, name), LastMod: lastMod})
+	}
+
+	for page := len(refs) + 1; ; page++ {
+		key := sitemapKey(tenant, sitemapPageName(section, page))
+		file, err := s.store.Open(s.ctx, key)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		file.Close()
+		if err := s.store.Delete(s.ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to remove stale sitemap: %w", err)
+		}
+	}
+	return refs, nil
+}
+
+// pageURL returns the storefront URL of a page in a section
+func (s *SitemapService) pageURL(tenant *models.Tenant, section, slug string) string {
+	switch section {
+	case storefront.SitemapAuthors:
+		return s.links.Author(tenant, slug)
+	case storefront.SitemapCategories:
+		return s.links.Category(tenant, slug)
+	default:
+		return s.links.Book(tenant, slug)
+	}
+}
+
+// sitemapPageName returns the name of a section's sitemap page
+func sitemapPageName(section string, page int) string {
+	return section + "-" + strconv.Itoa(page) + ".xml"
+}
+
+// sitemapKey returns the storage key of a tenant's sitemap
+func sitemapKey(tenant *models.Tenant, name string) string {
+	return tenant.ID.String() + "/sitemaps/" + name
+}
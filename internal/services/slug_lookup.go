@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/slug"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// slugID returns the ID of the entity of model, stored in table, whose slug
+// is value or was value before it was renamed, or uuid.Nil if there is none
+func slugID(db *gorm.DB, model interface{}, table, value string) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := db.Model(model).Where("slug = ?", value).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to look up slug: %w", err)
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+	return slug.Resolve(db, table, value)
+}
+
+// resolveID returns the ID value names: the UUID itself, or the ID of the
+// entity of model whose short code is value. It returns uuid.Nil when no
+// entity has the short code, and an error when value is neither.
+func resolveID(db *gorm.DB, model interface{}, value string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(value); err == nil {
+		return id, nil
+	}
+	if !ids.IsShortCode(value) {
+		return uuid.Nil, fmt.Errorf("invalid ID or short code")
+	}
+	var found []uuid.UUID
+	if err := db.Model(model).Where("short_code = ?", value).Limit(1).Pluck("id", &found).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to look up short code: %w", err)
+	}
+	if len(found) == 0 {
+		return uuid.Nil, nil
+	}
+	return found[0], nil
+}
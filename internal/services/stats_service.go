@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bookstore-api/internal/tenancy"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Trend periods for new title statistics
+const (
+	StatsPeriodDay   = "day"
+	StatsPeriodWeek  = "week"
+	StatsPeriodMonth = "month"
+)
+
+// defaultStatsTopLimit is the number of top categories and authors returned
+const defaultStatsTopLimit = 5
+
+// StatsOptions controls the statistics report. The date range applies to the
+// new title trend and to "new" counts; catalog totals always cover the whole catalog.
+type StatsOptions struct {
+	From     time.Time
+	To       time.Time
+	Period   string
+	TopLimit int
+	LowStock int
+}
+
+// CatalogCounts holds catalog totals
+type CatalogCounts struct {
+	Books                 int64 `json:"books"`
+	Authors               int64 `json:"authors"`
+	Categories            int64 `json:"categories"`
+	Publishers            int64 `json:"publishers"`
+	OutOfStock            int64 `json:"out_of_stock"`
+	LowStock              int64 `json:"low_stock"`
+	NewBooks              int64 `json:"new_books"`
+	NewAuthors            int64 `json:"new_authors"`
+	UnitsInStock          int64 `json:"units_in_stock"`
+	DeletedBooks          int64 `json:"deleted_books"`
+	BooksWithoutPublisher int64 `json:"books_without_publisher"`
+}
+
+// StockValue is the retail value of stock on hand
+type StockValue struct {
+	Total        float64 `json:"total"`
+	AveragePrice float64 `json:"average_price"`
+}
+
+// CategoryStat is a category ranked by its books
+type CategoryStat struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	BookCount  int64     `json:"book_count"`
+	Units      int64     `json:"units_in_stock"`
+	StockValue float64   `json:"stock_value"`
+}
+
+// AuthorStat is an author ranked by their books
+type AuthorStat struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	BookCount  int64     `json:"book_count"`
+	Units      int64     `json:"units_in_stock"`
+	StockValue float64   `json:"stock_value"`
+}
+
+// TrendPoint is the number of titles added in one period
+type TrendPoint struct {
+	Period time.Time `json:"period"`
+	Count  int64     `json:"count"`
+}
+
+// StatsReport is the admin statistics report
+type StatsReport struct {
+	From          time.Time      `json:"from"`
+	To            time.Time      `json:"to"`
+	Period        string         `json:"period"`
+	Counts        CatalogCounts  `json:"counts"`
+	StockValue    StockValue     `json:"stock_value"`
+	TopCategories []CategoryStat `json:"top_categories"`
+	TopAuthors    []AuthorStat   `json:"top_authors"`
+	NewTitles     []TrendPoint   `json:"new_titles"`
+}
+
+// StatsService computes back-office statistics with aggregate queries
+type StatsService struct {
+	db *gorm.DB
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(db *gorm.DB) *StatsService {
+	return &StatsService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *StatsService) WithContext(ctx context.Context) *StatsService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// GetStats builds the statistics report
+func (s *StatsService) GetStats(opts StatsOptions) (*StatsReport, error) {
+	switch opts.Period {
+	case StatsPeriodDay, StatsPeriodWeek, StatsPeriodMonth:
+	case "":
+		opts.Period = StatsPeriodDay
+	default:
+		return nil, fmt.Errorf("invalid period %q", opts.Period)
+	}
+	if opts.To.IsZero() {
+		opts.To = time.Now().UTC()
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.AddDate(0, 0, -30)
+	}
+	if !opts.From.Before(opts.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if opts.TopLimit <= 0 {
+		opts.TopLimit = defaultStatsTopLimit
+	}
+
+	report := &StatsReport{
+		From:   opts.From,
+		To:     opts.To,
+		Period: opts.Period,
+	}
+
+	// Raw SQL bypasses the tenancy plugin, so every table is filtered explicitly
+	tenantID, ok := tenancy.TenantID(s.db.Statement.Context)
+	if !ok {
+		return nil, tenancy.ErrNoTenant
+	}
+	args := map[string]interface{}{
+		"tenant": tenantID,
+		"low":    opts.LowStock,
+		"from":   opts.From,
+		"to":     opts.To,
+		"period": opts.Period,
+		"top":    opts.TopLimit,
+	}
+
+	if err := s.db.Raw(`
+		WITH b AS (SELECT * FROM books WHERE tenant_id = @tenant),
+			a AS (SELECT * FROM authors WHERE tenant_id = @tenant)
+		SELECT
+			(SELECT COUNT(*) FROM b WHERE deleted_at IS NULL) AS books,
+			(SELECT COUNT(*) FROM a WHERE deleted_at IS NULL) AS authors,
+			(SELECT COUNT(*) FROM categories WHERE tenant_id = @tenant AND deleted_at IS NULL) AS categories,
+			(SELECT COUNT(*) FROM publishers WHERE tenant_id = @tenant AND deleted_at IS NULL) AS publishers,
+			(SELECT COUNT(*) FROM b WHERE deleted_at IS NULL AND stock = 0) AS out_of_stock,
+			(SELECT COUNT(*) FROM b WHERE deleted_at IS NULL AND stock > 0 AND stock <= @low) AS low_stock,
+			(SELECT COUNT(*) FROM b WHERE deleted_at IS NULL AND created_at >= @from AND created_at < @to) AS new_books,
+			(SELECT COUNT(*) FROM a WHERE deleted_at IS NULL AND created_at >= @from AND created_at < @to) AS new_authors,
+			(SELECT COALESCE(SUM(stock), 0) FROM b WHERE deleted_at IS NULL) AS units_in_stock,
+			(SELECT COUNT(*) FROM b WHERE deleted_at IS NOT NULL) AS deleted_books,
+			(SELECT COUNT(*) FROM b WHERE deleted_at IS NULL AND publisher_id IS NULL) AS books_without_publisher`,
+		args,
+	).Scan(&report.Counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count catalog: %w", err)
+	}
+
+	if err := s.db.Raw(`
+		SELECT COALESCE(SUM(price * stock), 0) AS total, COALESCE(AVG(price), 0) AS average_price
+		FROM books WHERE tenant_id = @tenant AND deleted_at IS NULL`,
+		args,
+	).Scan(&report.StockValue).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute stock value: %w", err)
+	}
+
+	report.TopCategories = []CategoryStat{}
+	if err := s.db.Raw(`
+		SELECT categories.id, categories.name, COUNT(books.id) AS book_count,
+			COALESCE(SUM(books.stock), 0) AS units, COALESCE(SUM(books.price * books.stock), 0) AS stock_value
+		FROM categories
+		JOIN books ON books.category_id = categories.id AND books.deleted_at IS NULL
+		WHERE categories.tenant_id = @tenant AND categories.deleted_at IS NULL
+		GROUP BY categories.id, categories.name
+		ORDER BY book_count DESC, stock_value DESC
+		LIMIT @top`,
+		args,
+	).Scan(&report.TopCategories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get top categories: %w", err)
+	}
+
+	report.TopAuthors = []AuthorStat{}
+	if err := s.db.Raw(`
+		SELECT authors.id, authors.name, COUNT(books.id) AS book_count,
+			COALESCE(SUM(books.stock), 0) AS units, COALESCE(SUM(books.price * books.stock), 0) AS stock_value
+		FROM authors
+		JOIN books ON books.author_id = authors.id AND books.deleted_at IS NULL
+		WHERE authors.tenant_id = @tenant AND authors.deleted_at IS NULL
+		GROUP BY authors.id, authors.name
+		ORDER BY book_count DESC, stock_value DESC
+		LIMIT @top`,
+		args,
+	).Scan(&report.TopAuthors).Error; err != nil {
+		return nil, fmt.Errorf("failed to get top authors: %w", err)
+	}
+
+	// Fill empty periods so the trend can be charted directly
+	report.NewTitles = []TrendPoint{}
+	if err := s.db.Raw(`
+		SELECT series.period, COUNT(books.id) AS count
+		FROM generate_series(date_trunc(@period, @from::timestamptz), date_trunc(@period, @to::timestamptz), ('1 ' || @period)::interval) AS series(period)
+		LEFT JOIN books ON date_trunc(@period, books.created_at) = series.period
+			AND books.tenant_id = @tenant AND books.deleted_at IS NULL
+			AND books.created_at >= @from AND books.created_at < @to
+		GROUP BY series.period
+		ORDER BY series.period`,
+		args,
+	).Scan(&report.NewTitles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get new title trend: %w", err)
+	}
+
+	return report, nil
+}
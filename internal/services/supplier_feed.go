@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// supplierUserAgent identifies the API to supplier feed endpoints
+const supplierUserAgent = "Bookstore-API/1.0 (supplier sync)"
+
+// supplierFeedItem is one row of a supplier feed. Price and Stock are nil
+// when the feed leaves them out.
+type supplierFeedItem struct {
+	ISBN  string   `json:"isbn"`
+	Price *float64 `json:"price"`
+	Stock *int     `json:"stock"`
+	// invalid marks a CSV row whose price or stock is not a number
+	invalid bool
+}
+
+// fetchSupplierFeed downloads a supplier's feed, refusing one larger than
+// maxSize bytes
+func fetchSupplierFeed(ctx context.Context, client *http.Client, supplier *models.Supplier, maxSize int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, supplier.FeedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if supplier.Format == models.SupplierFormatJSON {
+		req.Header.Set("Accept", "application/json")
+	} else {
+		req.Header.Set("Accept", "text/csv")
+	}
+	req.Header.Set("User-Agent", supplierUserAgent)
+	if supplier.AuthHeader != "" {
+		req.Header.Set(supplier.AuthHeader, supplier.AuthValue)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("feed is larger than %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// parseSupplierFeed reads the rows of a feed in format
+func parseSupplierFeed(format string, data []byte) ([]supplierFeedItem, error) {
+	switch format {
+	case models.SupplierFormatCSV:
+		return parseSupplierCSV(data)
+	case models.SupplierFormatJSON:
+		return parseSupplierJSON(data)
+	}
+	return nil, fmt.Errorf("unsupported feed format %q", format)
+}
+
+// parseSupplierCSV reads a CSV feed whose header row names an isbn column
+// and price and stock columns, in any order. Other columns are ignored, and
+// blank cells leave the field out.
+func parseSupplierCSV(data []byte) ([]supplierFeedItem, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("feed is empty")
+		}
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	isbnColumn, ok := columns["isbn"]
+	if !ok {
+		return nil, fmt.Errorf("feed has no isbn column")
+	}
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []supplierFeedItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse feed: %w", err)
+		}
+
+		item := supplierFeedItem{}
+		if isbnColumn < len(record) {
+			item.ISBN = record[isbnColumn]
+		}
+		if value := cell(record, "price"); value != "" {
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				item.invalid = true
+			}
+			item.Price = &price
+		}
+		if value := cell(record, "stock"); value != "" {
+			stock, err := strconv.Atoi(value)
+			if err != nil {
+				item.invalid = true
+			}
+			item.Stock = &stock
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseSupplierJSON reads a JSON feed: an array of objects with isbn, price,
+// and stock, or an object holding that array as items
+func parseSupplierJSON(data []byte) ([]supplierFeedItem, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("feed is empty")
+	}
+
+	var items []supplierFeedItem
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse feed: %w", err)
+		}
+		return items, nil
+	}
+
+	var envelope struct {
+		Items *[]supplierFeedItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	if envelope.Items == nil {
+		return nil, fmt.Errorf("feed has no items array")
+	}
+	return *envelope.Items, nil
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// supplierBatchSize is the number of feed ISBNs matched against the catalog
+// at a time
+const supplierBatchSize = 500
+
+// SupplierService manages dropshipping suppliers and syncs their
+// availability and price feeds into the catalog
+type SupplierService struct {
+	db          *gorm.DB
+	client      *http.Client
+	maxFeedSize int
+}
+
+// NewSupplierService creates a new supplier service
+func NewSupplierService(db *gorm.DB, cfg config.SuppliersConfig) *SupplierService {
+	return &SupplierService{
+		db:          db,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		maxFeedSize: cfg.MaxFeedSize,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *SupplierService) WithContext(ctx context.Context) *SupplierService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreateSupplier creates a new supplier
+func (s *SupplierService) CreateSupplier(supplier *models.Supplier) error {
+	if err := validateFeedURL(supplier.FeedURL); err != nil {
+		return err
+	}
+	if err := validateFeedFormat(supplier.Format); err != nil {
+		return err
+	}
+	if supplier.WarehouseID != nil {
+		if _, err := NewWarehouseService(s.db).GetWarehouseByID(*supplier.WarehouseID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.Create(supplier).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("supplier with this name already exists")
+		}
+		return fmt.Errorf("failed to create supplier: %w", err)
+	}
+	return nil
+}
+
+// GetSupplierByID retrieves a supplier by ID
+func (s *SupplierService) GetSupplierByID(id uuid.UUID) (*models.Supplier, error) {
+	var supplier models.Supplier
+	if err := s.db.First(&supplier, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("supplier not found")
+		}
+		return nil, fmt.Errorf("failed to get supplier: %w", err)
+	}
+	return &supplier, nil
+}
+
+// GetAllSuppliers retrieves suppliers by name with pagination
+func (s *SupplierService) GetAllSuppliers(page, limit int) ([]models.Supplier, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.Supplier{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	var suppliers []models.Supplier
+	offset := (page - 1) * limit
+	if err := s.db.Order("name").Offset(offset).Limit(limit).Find(&suppliers).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+	return suppliers, total, nil
+}
+
+// UpdateSupplier sets the columns in updates on an existing supplier
+func (s *SupplierService) UpdateSupplier(id uuid.UUID, updates map[string]interface{}) (*models.Supplier, error) {
+	if feedURL, ok := updates["feed_url"].(string); ok {
+		if err := validateFeedURL(feedURL); err != nil {
+			return nil, err
+		}
+	}
+	if format, ok := updates["format"].(string); ok {
+		if err := validateFeedFormat(format); err != nil {
+			return nil, err
+		}
+	}
+	if warehouseID, ok := updates["warehouse_id"].(*uuid.UUID); ok && warehouseID != nil {
+		if _, err := NewWarehouseService(s.db).GetWarehouseByID(*warehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Updates by struct run the pii serializer on the auth value, which a
+	// map of columns would store as given
+	if value, ok := updates["auth_value"].(string); ok {
+		delete(updates, "auth_value")
+		if err := s.db.Model(&models.Supplier{ID: id}).Select("auth_value").Updates(&models.Supplier{AuthValue: value}).Error; err != nil {
+			return nil, fmt.Errorf("failed to update supplier: %w", err)
+		}
+		if len(updates) == 0 {
+			return s.GetSupplierByID(id)
+		}
+	}
+
+	result := s.db.Model(&models.Supplier{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("supplier with this name already exists")
+		}
+		return nil, fmt.Errorf("failed to update supplier: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("supplier not found")
+	}
+	return s.GetSupplierByID(id)
+}
+
+// DeleteSupplier deletes a supplier and its sync history. The prices and
+// stock it set are kept.
+func (s *SupplierService) DeleteSupplier(id uuid.UUID) error {
+	result := s.db.Delete(&models.Supplier{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete supplier: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("supplier not found")
+	}
+	return nil
+}
+
+// GetSupplierSyncs retrieves a supplier's syncs, newest first, without their
+// changes, with pagination
+func (s *SupplierService) GetSupplierSyncs(supplierID uuid.UUID, page, limit int) ([]models.SupplierSync, int64, error) {
+	if _, err := s.GetSupplierByID(supplierID); err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	query := s.db.Model(&models.SupplierSync{}).Where("supplier_id = ?", supplierID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count supplier syncs: %w", err)
+	}
+
+	var syncs []models.SupplierSync
+	offset := (page - 1) * limit
+	if err := query.Omit("changes").Order("started_at DESC").Offset(offset).Limit(limit).Find(&syncs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get supplier syncs: %w", err)
+	}
+	return syncs, total, nil
+}
+
+// GetSupplierSync retrieves one of a supplier's syncs with its changes
+func (s *SupplierService) GetSupplierSync(supplierID, syncID uuid.UUID) (*models.SupplierSync, error) {
+	var run models.SupplierSync
+	if err := s.db.First(&run, "id = ? AND supplier_id = ?", syncID, supplierID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("supplier sync not found")
+		}
+		return nil, fmt.Errorf("failed to get supplier sync: %w", err)
+	}
+	return &run, nil
+}
+
+// SyncSupplier pulls a supplier's feed, matches its rows to books by ISBN,
+// and applies the prices and stock that differ from the catalog, recording
+// the sync and its changes. A dry run records the changes it would make
+// without applying them. A feed that cannot be fetched or parsed gives a
+// failed sync rather than an error.
+func (s *SupplierService) SyncSupplier(id uuid.UUID, dryRun bool) (*models.SupplierSync, error) {
+	supplier, err := s.GetSupplierByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(supplier, dryRun)
+}
+
+// SyncEnabledSuppliers syncs every enabled supplier and returns their syncs
+func (s *SupplierService) SyncEnabledSuppliers() ([]models.SupplierSync, error) {
+	var suppliers []models.Supplier
+	if err := s.db.Where("enabled = ?", true).Order("name").Find(&suppliers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+
+	syncs := make([]models.SupplierSync, 0, len(suppliers))
+	for i := range suppliers {
+		run, err := s.sync(&suppliers[i], false)
+		if err != nil {
+			return syncs, err
+		}
+		syncs = append(syncs, *run)
+	}
+	return syncs, nil
+}
+
+// sync runs and records a sync of supplier's feed
+func (s *SupplierService) sync(supplier *models.Supplier, dryRun bool) (*models.SupplierSync, error) {
+	run := &models.SupplierSync{
+		SupplierID: supplier.ID,
+		DryRun:     dryRun,
+		Changes:    models.SupplierChanges{},
+		StartedAt:  time.Now(),
+	}
+	run.Status = models.SupplierSyncSucceeded
+	if err := s.runSync(supplier, run); err != nil {
+		run.Status = models.SupplierSyncFailed
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now()
+
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record supplier sync: %w", err)
+	}
+	if !dryRun {
+		if err := s.db.Model(&models.Supplier{}).Where("id = ?", supplier.ID).Updates(map[string]interface{}{
+			"last_synced_at": run.FinishedAt,
+			"last_error":     run.Error,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to update supplier: %w", err)
+		}
+	}
+	return run, nil
+}
+
+// supplierBook is a book listed in a supplier's feed with its price and the
+// stock the feed sets: its total, or its stock at the supplier's warehouse
+type supplierBook struct {
+	ID    uuid.UUID
+	ISBN  string
+	Price float64
+	Stock int
+}
+
+// runSync fetches and applies supplier's feed, counting its rows and adding
+// its changes to run
+func (s *SupplierService) runSync(supplier *models.Supplier, run *models.SupplierSync) error {
+	data, err := fetchSupplierFeed(s.db.Statement.Context, s.client, supplier, s.maxFeedSize)
+	if err != nil {
+		return err
+	}
+	items, err := parseSupplierFeed(supplier.Format, data)
+	if err != nil {
+		return err
+	}
+	run.Rows = len(items)
+
+	// Later rows for an ISBN replace earlier ones
+	wanted := make(map[string]supplierFeedItem, len(items))
+	var isbns []string
+	for _, item := range items {
+		isbn, ok := utils.CanonicalISBN(item.ISBN)
+		if !ok || item.invalid || (item.Price != nil && *item.Price < 0) || (item.Stock != nil && *item.Stock < 0) {
+			run.Invalid++
+			continue
+		}
+		if _, seen := wanted[isbn]; !seen {
+			isbns = append(isbns, isbn)
+		}
+		wanted[isbn] = item
+	}
+
+	for start := 0; start < len(isbns); start += supplierBatchSize {
+		end := min(start+supplierBatchSize, len(isbns))
+		books, err := s.supplierBooks(supplier, isbns[start:end])
+		if err != nil {
+			return err
+		}
+
+		for _, isbn := range isbns[start:end] {
+			book, ok := books[isbn]
+			if !ok {
+				run.Unmatched++
+				continue
+			}
+			run.Matched++
+
+			changes := diffSupplierItem(supplier, book, wanted[isbn])
+			if len(changes) == 0 {
+				continue
+			}
+			if !run.DryRun {
+				if err := s.applySupplierChanges(supplier, book.ID, changes); err != nil {
+					for i := range changes {
+						changes[i].Error = err.Error()
+					}
+					run.Failed++
+					run.Changes = append(run.Changes, changes...)
+					continue
+				}
+			}
+			run.Updated++
+			run.Changes = append(run.Changes, changes...)
+		}
+	}
+	return nil
+}
+
+// supplierBooks returns the books with isbns by ISBN
+func (s *SupplierService) supplierBooks(supplier *models.Supplier, isbns []string) (map[string]supplierBook, error) {
+	var rows []supplierBook
+	query := s.db.Model(&models.Book{}).Where("books.isbn IN ?", isbns)
+	if supplier.WarehouseID != nil {
+		query = query.Select("books.id, books.isbn, books.price, COALESCE(warehouse_stocks.quantity, 0) AS stock").
+			Joins("LEFT JOIN warehouse_stocks ON warehouse_stocks.book_id = books.id AND warehouse_stocks.warehouse_id = ?", *supplier.WarehouseID)
+	} else {
+		query = query.Select("books.id, books.isbn, books.price, books.stock")
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+
+	books := make(map[string]supplierBook, len(rows))
+	for _, row := range rows {
+		books[row.ISBN] = row
+	}
+	return books, nil
+}
+
+// diffSupplierItem returns the changes a feed row makes to a book's price
+// and stock, as far as the supplier is set to update them
+func diffSupplierItem(supplier *models.Supplier, book supplierBook, item supplierFeedItem) []models.SupplierChange {
+	var changes []models.SupplierChange
+	if supplier.UpdatePrices && item.Price != nil {
+		price := math.Round(*item.Price*100) / 100
+		if price != book.Price {
+			changes = append(changes, models.SupplierChange{BookID: book.ID, ISBN: book.ISBN, Field: "price", From: book.Price, To: price})
+		}
+	}
+	if supplier.UpdateStock && item.Stock != nil && *item.Stock != book.Stock {
+		changes = append(changes, models.SupplierChange{BookID: book.ID, ISBN: book.ISBN, Field: "stock", From: book.Stock, To: *item.Stock})
+	}
+	return changes
+}
+
+// applySupplierChanges applies a book's changes from a supplier feed in one
+// transaction, recording the price history, stock changes, and events the
+// same changes made through the API would
+func (s *SupplierService) applySupplierChanges(supplier *models.Supplier, bookID uuid.UUID, changes []models.SupplierChange) error {
+	note := "supplier sync: " + supplier.Name
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			switch change.Field {
+			case "price":
+				if err := setSupplierPrice(tx, bookID, change.To.(float64)); err != nil {
+					return err
+				}
+			case "stock":
+				stock := change.To.(int)
+				if supplier.WarehouseID != nil {
+					if _, err := setWarehouseStock(tx, *supplier.WarehouseID, bookID, stock, models.StockReasonSupplier, note); err != nil {
+						return err
+					}
+				} else if err := setSupplierStock(tx, bookID, stock, note); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// setSupplierPrice sets a book's price from a supplier feed
+func setSupplierPrice(tx *gorm.DB, bookID uuid.UUID, price float64) error {
+	var book models.Book
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price").First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("book not found")
+		}
+		return fmt.Errorf("failed to get book: %w", err)
+	}
+	if book.Price == price {
+		return nil
+	}
+
+	if err := tx.Model(&models.Book{}).Where("id = ?", bookID).Update("price", price).Error; err != nil {
+		return fmt.Errorf("failed to update book price: %w", err)
+	}
+	if err := recordPrice(tx, bookID, &book.Price, price, models.PriceSourceSupplier); err != nil {
+		return err
+	}
+	return events.Record(tx, events.BookPriceChanged, events.AggregateBook, bookID, events.PriceChangedPayload{
+		BookID:        bookID,
+		PreviousPrice: book.Price,
+		Price:         price,
+	})
+}
+
+// setSupplierStock sets a book's total stock from a supplier feed, refusing
+// to take it below the stock held at warehouses
+func setSupplierStock(tx *gorm.DB, bookID uuid.UUID, stock int, note string) error {
+	var book models.Book
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "stock").First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("book not found")
+		}
+		return fmt.Errorf("failed to get book: %w", err)
+	}
+	if book.Stock == stock {
+		return nil
+	}
+	return changeStock(tx, bookID, stock-book.Stock, models.StockReasonSupplier, note)
+}
+
+// validateFeedURL refuses feed URLs that are not absolute http or https URLs
+func validateFeedURL(feedURL string) error {
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid supplier: feed_url must be an http or https URL")
+	}
+	return nil
+}
+
+// validateFeedFormat refuses feed formats other than csv and json
+func validateFeedFormat(format string) error {
+	if format != models.SupplierFormatCSV && format != models.SupplierFormatJSON {
+		return fmt.Errorf("invalid supplier: format must be csv or json")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/tenancy"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TenantService handles tenant management. Tenants are not themselves tenant
+// scoped, so its queries run with a system context.
+type TenantService struct {
+	db *gorm.DB
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(db *gorm.DB) *TenantService {
+	return &TenantService{
+		db: tenancy.System(db),
+	}
+}
+
+// CreateTenant creates a new tenant
+func (s *TenantService) CreateTenant(tenant *models.Tenant) error {
+	if err := s.db.Create(tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("tenant slug or domain already in use")
+		}
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// GetTenantByID retrieves a tenant by ID
+func (s *TenantService) GetTenantByID(id uuid.UUID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.db.First(&tenant, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+// GetAllTenants retrieves all tenants with pagination
+func (s *TenantService) GetAllTenants(page, limit int) ([]models.Tenant, int64, error) {
+	var tenants []models.Tenant
+	var total int64
+
+	if err := s.db.Model(&models.Tenant{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tenants: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := s.db.Order("created_at ASC").Offset(offset).Limit(limit).Find(&tenants).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get tenants: %w", err)
+	}
+
+	return tenants, total, nil
+}
+
+// GetActiveTenants retrieves every active tenant
+func (s *TenantService) GetActiveTenants() ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	if err := s.db.Where("active = ?", true).Order("created_at ASC").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// UpdateTenant updates an existing tenant
+func (s *TenantService) UpdateTenant(id uuid.UUID, updates map[string]interface{}) error {
+	result := s.db.Model(&models.Tenant{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("tenant slug or domain already in use")
+		}
+		return fmt.Errorf("failed to update tenant: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tenant not found")
+	}
+	return nil
+}
+
+// DeleteTenant soft deletes a tenant. The default tenant cannot be deleted.
+func (s *TenantService) DeleteTenant(id uuid.UUID) error {
+	if id == models.DefaultTenantID {
+		return fmt.Errorf("default tenant cannot be deleted")
+	}
+	result := s.db.Delete(&models.Tenant{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete tenant: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tenant not found")
+	}
+	return nil
+}
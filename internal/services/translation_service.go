@@ -0,0 +1,288 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TranslationService handles translations of book and category fields
+type TranslationService struct {
+	db *gorm.DB
+}
+
+// NewTranslationService creates a new translation service
+func NewTranslationService(db *gorm.DB) *TranslationService {
+	return &TranslationService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *TranslationService) WithContext(ctx context.Context) *TranslationService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// GetTranslations retrieves all translations of a book or category, grouped by locale
+func (s *TranslationService) GetTranslations(entityType string, entityID uuid.UUID) (map[string]map[string]string, error) {
+	if err := s.checkEntity(entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	var translations []models.Translation
+	if err := s.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("locale ASC").
+		Find(&translations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get translations: %w", err)
+	}
+
+	locales := make(map[string]map[string]string)
+	for _, translation := range translations {
+		if locales[translation.Locale] == nil {
+			locales[translation.Locale] = make(map[string]string)
+		}
+		locales[translation.Locale][translation.Field] = translation.Value
+	}
+	return locales, nil
+}
+
+// SetTranslations creates or replaces the given fields of a book or category in
+// one locale. An empty value removes that field's translation.
+func (s *TranslationService) SetTranslations(entityType string, entityID uuid.UUID, locale string, values map[string]string) error {
+	for field := range values {
+		if !isTranslatableField(entityType, field) {
+			return fmt.Errorf("field %q cannot be translated", field)
+		}
+	}
+	if err := s.checkEntity(entityType, entityID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for field, value := range values {
+			if value == "" {
+				if err := tx.Where("entity_type = ? AND entity_id = ? AND locale = ? AND field = ?", entityType, entityID, locale, field).
+					Delete(&models.Translation{}).Error; err != nil {
+					return fmt.Errorf("failed to delete translation: %w", err)
+				}
+				continue
+			}
+
+			translation := &models.Translation{
+				EntityType: entityType,
+				EntityID:   entityID,
+				Locale:     locale,
+				Field:      field,
+				Value:      value,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "entity_type"}, {Name: "entity_id"}, {Name: "locale"}, {Name: "field"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+			}).Create(translation).Error; err != nil {
+				return fmt.Errorf("failed to save translation: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteTranslations removes every translation of a book or category in one locale
+func (s *TranslationService) DeleteTranslations(entityType string, entityID uuid.UUID, locale string) error {
+	result := s.db.Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).
+		Delete(&models.Translation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete translations: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("translation not found")
+	}
+	return nil
+}
+
+// LocalizeBooks replaces the translatable fields of books and their categories
+// with the best available translation among locales, most preferred first. It
+// returns the most preferred locale applied, or "" if no translation was found.
+func (s *TranslationService) LocalizeBooks(books []models.Book, locales []string) (string, error) {
+	if len(books) == 0 || len(locales) == 0 {
+		return "", nil
+	}
+
+	l := newLocalizer(locales)
+	bookIDs := make([]uuid.UUID, 0, len(books))
+	categoryIDs := make([]uuid.UUID, 0, len(books))
+	for i := range books {
+		bookIDs = append(bookIDs, books[i].ID)
+		if books[i].Category.ID != uuid.Nil {
+			categoryIDs = append(categoryIDs, books[i].Category.ID)
+		}
+	}
+	bookValues, err := s.load(l, models.TranslationEntityBook, bookIDs)
+	if err != nil {
+		return "", err
+	}
+	categoryValues, err := s.load(l, models.TranslationEntityCategory, categoryIDs)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range books {
+		applyBook(&books[i], bookValues[books[i].ID])
+		applyCategory(&books[i].Category, categoryValues[books[i].Category.ID])
+	}
+	return l.applied(), nil
+}
+
+// LocalizeCategories replaces the translatable fields of categories and their
+// books like LocalizeBooks
+func (s *TranslationService) LocalizeCategories(categories []models.Category, locales []string) (string, error) {
+	if len(categories) == 0 || len(locales) == 0 {
+		return "", nil
+	}
+
+	l := newLocalizer(locales)
+	categoryIDs := make([]uuid.UUID, 0, len(categories))
+	var bookIDs []uuid.UUID
+	for i := range categories {
+		categoryIDs = append(categoryIDs, categories[i].ID)
+		for j := range categories[i].Books {
+			bookIDs = append(bookIDs, categories[i].Books[j].ID)
+		}
+	}
+	categoryValues, err := s.load(l, models.TranslationEntityCategory, categoryIDs)
+	if err != nil {
+		return "", err
+	}
+	bookValues, err := s.load(l, models.TranslationEntityBook, bookIDs)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range categories {
+		applyCategory(&categories[i], categoryValues[categories[i].ID])
+		for j := range categories[i].Books {
+			applyBook(&categories[i].Books[j], bookValues[categories[i].Books[j].ID])
+		}
+	}
+	return l.applied(), nil
+}
+
+// load returns the best translated value of each field of the given entities
+func (s *TranslationService) load(l *localizer, entityType string, ids []uuid.UUID) (map[uuid.UUID]map[string]string, error) {
+	values := make(map[uuid.UUID]map[string]string)
+	if len(ids) == 0 {
+		return values, nil
+	}
+
+	var translations []models.Translation
+	if err := s.db.Where("entity_type = ? AND entity_id IN ? AND locale IN ?", entityType, ids, l.locales).
+		Find(&translations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get translations: %w", err)
+	}
+
+	ranks := make(map[uuid.UUID]map[string]int)
+	for _, translation := range translations {
+		rank := l.rank[translation.Locale]
+		if values[translation.EntityID] == nil {
+			values[translation.EntityID] = make(map[string]string)
+			ranks[translation.EntityID] = make(map[string]int)
+		}
+		if best, ok := ranks[translation.EntityID][translation.Field]; ok && best <= rank {
+			continue
+		}
+		values[translation.EntityID][translation.Field] = translation.Value
+		ranks[translation.EntityID][translation.Field] = rank
+		if rank < l.best {
+			l.best = rank
+		}
+	}
+	return values, nil
+}
+
+// checkEntity verifies that the translated book or category exists
+func (s *TranslationService) checkEntity(entityType string, entityID uuid.UUID) error {
+	var model interface{}
+	switch entityType {
+	case models.TranslationEntityBook:
+		model = &models.Book{}
+	case models.TranslationEntityCategory:
+		model = &models.Category{}
+	default:
+		return fmt.Errorf("unknown translation entity type %q", entityType)
+	}
+
+	var count int64
+	if err := s.db.Model(model).Where("id = ?", entityID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get %s: %w", entityType, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s not found", entityType)
+	}
+	return nil
+}
+
+// localizer tracks the locales being matched and the best one applied
+type localizer struct {
+	locales []string
+	rank    map[string]int
+	best    int
+}
+
+// newLocalizer creates a localizer for locales in order of preference
+func newLocalizer(locales []string) *localizer {
+	l := &localizer{
+		locales: locales,
+		rank:    make(map[string]int, len(locales)),
+		best:    len(locales),
+	}
+	for i, locale := range locales {
+		if _, ok := l.rank[locale]; !ok {
+			l.rank[locale] = i
+		}
+	}
+	return l
+}
+
+// applied returns the most preferred locale used by any translation, or ""
+func (l *localizer) applied() string {
+	if l.best < len(l.locales) {
+		return l.locales[l.best]
+	}
+	return ""
+}
+
+// applyBook sets a book's translated fields
+func applyBook(book *models.Book, values map[string]string) {
+	if value, ok := values["title"]; ok {
+		book.Title = value
+	}
+	if value, ok := values["description"]; ok {
+		book.Description = value
+	}
+}
+
+// applyCategory sets a category's translated fields
+func applyCategory(category *models.Category, values map[string]string) {
+	if value, ok := values["name"]; ok {
+		category.Name = value
+	}
+	if value, ok := values["description"]; ok {
+		category.Description = value
+	}
+}
+
+// isTranslatableField reports whether field of the entity type may be translated
+func isTranslatableField(entityType, field string) bool {
+	for _, allowed := range models.TranslatableFields[entityType] {
+		if field == allowed {
+			return true
+		}
+	}
+	return false
+}
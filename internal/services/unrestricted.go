@@ -0,0 +1,14 @@
+package services
+
+import (
+	"bookstore-api/internal/access"
+
+	"gorm.io/gorm"
+)
+
+// unrestricted returns db for queries that must see every row of the tenant,
+// whatever the access rules hide from the caller. Uniqueness checks use it,
+// so a value held by a row the caller cannot see is not reported available.
+func unrestricted(db *gorm.DB) *gorm.DB {
+	return db.WithContext(access.Unrestricted(db.Statement.Context))
+}
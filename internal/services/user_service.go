@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/pii"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserService handles local users and their external identities
+type UserService struct {
+	db *gorm.DB
+}
+
+// NewUserService creates a new user service
+func NewUserService(db *gorm.DB) *UserService {
+	return &UserService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// GetUserByID retrieves a user and their linked identities by ID
+func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := s.db.Preload("Identities").First(&user, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// CreateUser creates a user ahead of their first login. The user is linked to
+// the first social login that verifies the same email.
+func (s *UserService) CreateUser(email, name, role string) (*models.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user := &models.User{
+		Email: &email,
+		Name:  name,
+		Role:  role,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := unrestricted(tx).Model(&models.User{}).Where(emailCondition(email)).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check email: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("user with this email already exists")
+		}
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetUserRole changes a user's role. Access tokens already issued keep the old
+// role until they are refreshed.
+func (s *UserService) SetUserRole(id uuid.UUID, role string) error {
+	result := s.db.Model(&models.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// LoginWithIdentity returns the local user for an external identity. An identity
+// seen before maps to its linked user; otherwise it is linked to the user with
+// the same verified email, or a new user is created for it.
+func (s *UserService) LoginWithIdentity(provider string, identity *auth.Identity) (*models.User, error) {
+	user, err := s.loginWithIdentity(provider, identity)
+	// Two first logins for the same identity raced and the other one linked it;
+	// retrying finds the identity it created
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		user, err = s.loginWithIdentity(provider, identity)
+	}
+	return user, err
+}
+
+func (s *UserService) loginWithIdentity(provider string, identity *auth.Identity) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, identity.Subject).First(&link).Error
+		switch {
+		case err == nil:
+			if err := tx.First(&user, "id = ?", link.UserID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return fmt.Errorf("user account is disabled")
+				}
+				return fmt.Errorf("failed to get user: %w", err)
+			}
+			if link.Email != email {
+				// Updated from a model so the pii serializer encrypts the email
+				if err := tx.Model(&link).Select("email").Updates(&models.UserIdentity{Email: email}).Error; err != nil {
+					return fmt.Errorf("failed to update identity: %w", err)
+				}
+			}
+		case err == gorm.ErrRecordNotFound:
+			if err := s.linkIdentity(tx, &user, provider, identity, email); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("failed to find identity: %w", err)
+		}
+
+		now := time.Now()
+		user.LastLoginAt = &now
+		if err := tx.Model(&user).Update("last_login_at", now).Error; err != nil {
+			return fmt.Errorf("failed to update last login: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// linkIdentity attaches a new identity to the user owning its verified email,
+// creating the user when there is none
+func (s *UserService) linkIdentity(tx *gorm.DB, user *models.User, provider string, identity *auth.Identity, email string) error {
+	found := false
+	// Only a provider-verified email proves ownership of an existing account
+	if email != "" && identity.EmailVerified {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(emailCondition(email)).Order("created_at").First(user).Error
+		switch {
+		case err == nil:
+			found = true
+		case err != gorm.ErrRecordNotFound:
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+	}
+
+	if !found {
+		*user = models.User{
+			Name:      identity.Name,
+			AvatarURL: identity.AvatarURL,
+			Role:      models.RoleCustomer,
+		}
+		if email != "" && identity.EmailVerified {
+			user.Email = &email
+		}
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+	}
+
+	link := models.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    email,
+	}
+	if err := tx.Create(&link).Error; err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// emailCondition matches users by email. Encrypted emails are matched by their
+// blind index, and emails stored before encryption was enabled by their value.
+func emailCondition(email string) clause.Expr {
+	return gorm.Expr("users.email_hash = ? OR (users.email_hash IS NULL AND LOWER(users.email) = ?)",
+		pii.GetKeyring().Hash(email), email)
+}
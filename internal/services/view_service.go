@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// statsDays is the number of days covered by the daily views in book stats
+const statsDays = 30
+
+// BookStats holds a book's view counts. UniqueViewers counts signed-in
+// viewers only; anonymous views are included in every other count.
+type BookStats struct {
+	BookID        uuid.UUID    `json:"book_id"`
+	TotalViews    int64        `json:"total_views"`
+	UniqueViewers int64        `json:"unique_viewers"`
+	Views24h      int64        `json:"views_24h"`
+	Views7d       int64        `json:"views_7d"`
+	Views30d      int64        `json:"views_30d"`
+	Daily         []DailyViews `json:"daily"`
+}
+
+// DailyViews is the number of views of a book on a UTC day
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int64  `json:"views"`
+}
+
+// ViewService reports on book views recorded by the view tracker
+type ViewService struct {
+	db *gorm.DB
+}
+
+// NewViewService creates a new view service
+func NewViewService(db *gorm.DB) *ViewService {
+	return &ViewService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *ViewService) WithContext(ctx context.Context) *ViewService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// GetBookStats returns the book's view counts as of now, with its views on
+// each of the last 30 days, oldest first
+func (s *ViewService) GetBookStats(bookID uuid.UUID, now time.Time) (*BookStats, error) {
+	var count int64
+	if err := s.db.Model(&models.Book{}).Where("id = ?", bookID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check book: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("book not found")
+	}
+
+	var totals struct {
+		Total   int64
+		Viewers int64
+		Day     int64
+		Week    int64
+		Month   int64
+	}
+	err := s.db.Model(&models.BookView{}).
+		Select("COUNT(*) AS total, COUNT(DISTINCT user_id) AS viewers, "+
+			"COUNT(*) FILTER (WHERE viewed_at >= ?) AS day, "+
+			"COUNT(*) FILTER (WHERE viewed_at >= ?) AS week, "+
+			"COUNT(*) FILTER (WHERE viewed_at >= ?) AS month",
+			now.Add(-24*time.Hour), now.AddDate(0, 0, -7), now.AddDate(0, 0, -30)).
+		Where("book_id = ?", bookID).
+		Find(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count book views: %w", err)
+	}
+	stats := &BookStats{
+		BookID:        bookID,
+		TotalViews:    totals.Total,
+		UniqueViewers: totals.Viewers,
+		Views24h:      totals.Day,
+		Views7d:       totals.Week,
+		Views30d:      totals.Month,
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, 1-statsDays)
+	var rows []struct {
+		Day   time.Time
+		Views int64
+	}
+	err = s.db.Model(&models.BookView{}).
+		Select("(viewed_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS views").
+		Where("book_id = ? AND viewed_at >= ?", bookID, from).
+		Group("day").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count daily book views: %w", err)
+	}
+	byDay := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		byDay[row.Day.Format("2006-01-02")] = row.Views
+	}
+	stats.Daily = make([]DailyViews, statsDays)
+	for i := range stats.Daily {
+		date := from.AddDate(0, 0, i).Format("2006-01-02")
+		stats.Daily[i] = DailyViews{Date: date, Views: byDay[date]}
+	}
+	return stats, nil
+}
+
+// GetRecentlyViewedBooks returns up to limit books the user viewed, most
+// recently viewed first
+func (s *ViewService) GetRecentlyViewedBooks(userID uuid.UUID, limit int) ([]models.Book, error) {
+	var rows []struct {
+		BookID uuid.UUID
+	}
+	err := s.db.Model(&models.BookView{}).
+		Select("book_id").
+		Where("user_id = ? AND book_id IN (?)", userID, s.db.Model(&models.Book{}).Select("id")).
+		Group("book_id").
+		Order("MAX(viewed_at) DESC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently viewed books: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.BookID
+	}
+	return s.booksInOrder(ids)
+}
+
+// GetTrendingBooks returns a page of the active books viewed since since, most
+// viewed first, with Views set to their number of views in that time
+func (s *ViewService) GetTrendingBooks(since time.Time, page, limit int) ([]models.Book, int64, error) {
+	views := s.db.Model(&models.BookView{}).
+		Where("viewed_at >= ? AND book_id IN (?)", since, s.db.Model(&models.Book{}).Select("id").Where("status = ?", models.BookStatusActive))
+
+	var total int64
+	if err := s.db.Model(&models.BookView{}).Where(views).Distinct("book_id").Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trending books: %w", err)
+	}
+
+	var rows []struct {
+		BookID uuid.UUID
+		Views  int64
+	}
+	err := s.db.Model(&models.BookView{}).Where(views).
+		Select("book_id, COUNT(*) AS views").
+		Group("book_id").
+		Order("views DESC, book_id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get trending books: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.BookID
+	}
+	books, err := s.booksInOrder(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.BookID] = row.Views
+	}
+	for i := range books {
+		count := counts[books[i].ID]
+		books[i].Views = &count
+	}
+	return books, total, nil
+}
+
+// booksInOrder loads the books with ids, in the order of ids. Books deleted
+// since their IDs were read are left out.
+func (s *ViewService) booksInOrder(ids []uuid.UUID) ([]models.Book, error) {
+	if len(ids) == 0 {
+		return []models.Book{}, nil
+	}
+
+	var found []models.Book
+	if err := s.db.Preload("Author").Preload("Category").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+	byID := make(map[uuid.UUID]models.Book, len(found))
+	for _, book := range found {
+		byID[book.ID] = book
+	}
+	books := make([]models.Book, 0, len(ids))
+	for _, id := range ids {
+		if book, ok := byID[id]; ok {
+			books = append(books, book)
+		}
+	}
+	if err := applyPromotions(s.db, bookRefs(books)); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
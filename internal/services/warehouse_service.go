@@ -0,0 +1,339 @@
+package services
+
+import (
+	"bookstore-api/internal/access"
+	"bookstore-api/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferFilter selects stock transfers. Zero fields select every transfer.
+type TransferFilter struct {
+	BookID uuid.UUID
+	// WarehouseID selects transfers from or to the warehouse
+	WarehouseID uuid.UUID
+}
+
+// WarehouseService manages warehouses, the stock of books held at each, and
+// transfers of stock between them
+type WarehouseService struct {
+	db *gorm.DB
+}
+
+// NewWarehouseService creates a new warehouse service
+func NewWarehouseService(db *gorm.DB) *WarehouseService {
+	return &WarehouseService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *WarehouseService) WithContext(ctx context.Context) *WarehouseService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreateWarehouse creates a new warehouse. Codes are stored upper case.
+func (s *WarehouseService) CreateWarehouse(warehouse *models.Warehouse) error {
+	warehouse.Code = strings.ToUpper(strings.TrimSpace(warehouse.Code))
+	if err := s.db.Create(warehouse).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("warehouse with this code already exists")
+		}
+		return fmt.Errorf("failed to create warehouse: %w", err)
+	}
+	return nil
+}
+
+// GetWarehouseByID retrieves a warehouse by ID
+func (s *WarehouseService) GetWarehouseByID(id uuid.UUID) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	if err := s.db.First(&warehouse, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("warehouse not found")
+		}
+		return nil, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+	return &warehouse, nil
+}
+
+// GetAllWarehouses retrieves warehouses by code with pagination
+func (s *WarehouseService) GetAllWarehouses(page, limit int) ([]models.Warehouse, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.Warehouse{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count warehouses: %w", err)
+	}
+
+	var warehouses []models.Warehouse
+	offset := (page - 1) * limit
+	if err := s.db.Order("code").Offset(offset).Limit(limit).Find(&warehouses).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get warehouses: %w", err)
+	}
+	return warehouses, total, nil
+}
+
+// UpdateWarehouse sets the columns in updates on an existing warehouse
+func (s *WarehouseService) UpdateWarehouse(id uuid.UUID, updates map[string]interface{}) (*models.Warehouse, error) {
+	if code, ok := updates["code"].(string); ok {
+		updates["code"] = strings.ToUpper(strings.TrimSpace(code))
+	}
+
+	result := s.db.Model(&models.Warehouse{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("warehouse with this code already exists")
+		}
+		return nil, fmt.Errorf("failed to update warehouse: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+	return s.GetWarehouseByID(id)
+}
+
+// DeleteWarehouse deletes a warehouse that holds no stock
+func (s *WarehouseService) DeleteWarehouse(id uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var warehouse models.Warehouse
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&warehouse, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("warehouse not found")
+			}
+			return fmt.Errorf("failed to get warehouse: %w", err)
+		}
+
+		var held int64
+		if err := tx.Model(&models.WarehouseStock{}).Where("warehouse_id = ? AND quantity > 0", id).Count(&held).Error; err != nil {
+			return fmt.Errorf("failed to get warehouse stock: %w", err)
+		}
+		if held > 0 {
+			return fmt.Errorf("warehouse holds stock")
+		}
+
+		if err := tx.Delete(&warehouse).Error; err != nil {
+			return fmt.Errorf("failed to delete warehouse: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetWarehouseStock retrieves the stock levels of a warehouse, with the
+// title and ISBN of each book, with pagination
+func (s *WarehouseService) GetWarehouseStock(warehouseID uuid.UUID, page, limit int) ([]models.WarehouseStock, int64, error) {
+	if _, err := s.GetWarehouseByID(warehouseID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.Model(&models.WarehouseStock{}).Where("warehouse_id = ? AND quantity > 0", warehouseID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count warehouse stock: %w", err)
+	}
+
+	var levels []models.WarehouseStock
+	offset := (page - 1) * limit
+	if err := query.Preload("Book", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "title", "isbn", "slug", "stock")
+	}).Order("book_id").Offset(offset).Limit(limit).Find(&levels).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+	return levels, total, nil
+}
+
+// SetWarehouseStock sets the stock of a book held at a warehouse after a
+// count or a delivery. The book's total stock changes by the same amount, so
+// its unallocated stock is unchanged.
+func (s *WarehouseService) SetWarehouseStock(warehouseID, bookID uuid.UUID, quantity int, note string) (*models.WarehouseStock, error) {
+	if quantity < 0 {
+		return nil, fmt.Errorf("stock cannot be negative")
+	}
+
+	var level *models.WarehouseStock
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		level, err = setWarehouseStock(tx, warehouseID, bookID, quantity, models.StockReasonWarehouse, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return level, nil
+}
+
+// TransferStock moves stock of a book between warehouses, or between a
+// warehouse and the book's unallocated stock, and records the transfer. The
+// book's total stock is unchanged.
+func (s *WarehouseService) TransferStock(transfer *models.StockTransfer) error {
+	if transfer.Quantity <= 0 {
+		return fmt.Errorf("invalid transfer: quantity must be positive")
+	}
+	if transfer.FromWarehouseID == nil && transfer.ToWarehouseID == nil {
+		return fmt.Errorf("invalid transfer: set from_warehouse_id, to_warehouse_id, or both")
+	}
+	if transfer.FromWarehouseID != nil && transfer.ToWarehouseID != nil && *transfer.FromWarehouseID == *transfer.ToWarehouseID {
+		return fmt.Errorf("invalid transfer: from and to warehouses must differ")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the book serializes every change to its stock
+		if err := lockStockBook(tx, transfer.BookID); err != nil {
+			return err
+		}
+
+		if transfer.FromWarehouseID != nil {
+			from, err := lockWarehouseStock(tx, *transfer.FromWarehouseID, transfer.BookID)
+			if err != nil {
+				return err
+			}
+			if from.Quantity < transfer.Quantity {
+				return fmt.Errorf("insufficient stock")
+			}
+			from.Quantity -= transfer.Quantity
+			if err := tx.Omit(clause.Associations).Save(from).Error; err != nil {
+				return fmt.Errorf("failed to update warehouse stock: %w", err)
+			}
+		}
+		if transfer.ToWarehouseID != nil {
+			to, err := lockWarehouseStock(tx, *transfer.ToWarehouseID, transfer.BookID)
+			if err != nil {
+				return err
+			}
+			to.Quantity += transfer.Quantity
+			if err := tx.Omit(clause.Associations).Save(to).Error; err != nil {
+				return fmt.Errorf("failed to update warehouse stock: %w", err)
+			}
+		}
+
+		// Stock moved to a warehouse from unallocated stock must be on hand
+		var book models.Book
+		if err := tx.Select("stock").First(&book, "id = ?", transfer.BookID).Error; err != nil {
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		if err := checkAllocatedStock(tx, transfer.BookID, book.Stock); err != nil {
+			return err
+		}
+
+		if principal := access.FromContext(tx.Statement.Context); principal != nil && principal.UserID != uuid.Nil {
+			userID := principal.UserID
+			transfer.CreatedBy = &userID
+		}
+		if err := tx.Omit(clause.Associations).Create(transfer).Error; err != nil {
+			return fmt.Errorf("failed to record transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetTransfers retrieves stock transfers matching filter, newest first, with
+// pagination
+func (s *WarehouseService) GetTransfers(filter TransferFilter, page, limit int) ([]models.StockTransfer, int64, error) {
+	query := s.db.Model(&models.StockTransfer{})
+	if filter.BookID != uuid.Nil {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	if filter.WarehouseID != uuid.Nil {
+		query = query.Where("from_warehouse_id = ? OR to_warehouse_id = ?", filter.WarehouseID, filter.WarehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count transfers: %w", err)
+	}
+
+	var transfers []models.StockTransfer
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&transfers).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get transfers: %w", err)
+	}
+	return transfers, total, nil
+}
+
+// lockStockBook locks a book's row for a change to its stock
+func lockStockBook(tx *gorm.DB, bookID uuid.UUID) error {
+	var book models.Book
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&book, "id = ?", bookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("book not found")
+		}
+		return fmt.Errorf("failed to get book: %w", err)
+	}
+	return nil
+}
+
+// lockWarehouseStock returns the locked stock level of a book at a
+// warehouse, creating an empty level when the warehouse holds none
+func lockWarehouseStock(tx *gorm.DB, warehouseID, bookID uuid.UUID) (*models.WarehouseStock, error) {
+	var warehouse models.Warehouse
+	if err := tx.Select("id").First(&warehouse, "id = ?", warehouseID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("warehouse not found")
+		}
+		return nil, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+
+	level := models.WarehouseStock{WarehouseID: warehouseID, BookID: bookID}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND book_id = ?", warehouseID, bookID).
+		FirstOrCreate(&level).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+	return &level, nil
+}
+
+// setWarehouseStock sets the stock of a book held at a warehouse, changing
+// the book's total stock by the same amount with reason
+func setWarehouseStock(tx *gorm.DB, warehouseID, bookID uuid.UUID, quantity int, reason, note string) (*models.WarehouseStock, error) {
+	if err := lockStockBook(tx, bookID); err != nil {
+		return nil, err
+	}
+	level, err := lockWarehouseStock(tx, warehouseID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := quantity - level.Quantity
+	if delta == 0 {
+		return level, nil
+	}
+	level.Quantity = quantity
+	if err := tx.Omit(clause.Associations).Save(level).Error; err != nil {
+		return nil, fmt.Errorf("failed to update warehouse stock: %w", err)
+	}
+	if err := changeStock(tx, bookID, delta, reason, note); err != nil {
+		return nil, err
+	}
+	return level, nil
+}
+
+// setAvailability sets where a book's stock is held on the book
+func setAvailability(db *gorm.DB, book *models.Book) error {
+	var rows []models.WarehouseAvailability
+	if err := db.Model(&models.WarehouseStock{}).
+		Select("warehouse_stocks.warehouse_id, warehouses.code, warehouses.name, warehouse_stocks.quantity").
+		Joins("JOIN warehouses ON warehouses.id = warehouse_stocks.warehouse_id").
+		Where("warehouse_stocks.book_id = ? AND warehouse_stocks.quantity > 0", book.ID).
+		Order("warehouses.code").
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+
+	availability := &models.StockAvailability{Total: book.Stock, Warehouses: rows}
+	if availability.Warehouses == nil {
+		availability.Warehouses = []models.WarehouseAvailability{}
+	}
+	for _, row := range rows {
+		availability.Allocated += row.Quantity
+	}
+	availability.Unallocated = book.Stock - availability.Allocated
+	book.Availability = availability
+	return nil
+}
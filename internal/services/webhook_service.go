@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookService handles webhook subscription and delivery log logic
+type WebhookService struct {
+	db *gorm.DB
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, which
+// carries the tenant they are scoped to
+func (s *WebhookService) WithContext(ctx context.Context) *WebhookService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// CreateWebhook creates a new webhook, generating a signing secret if none is set
+func (s *WebhookService) CreateWebhook(webhook *models.Webhook) error {
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		webhook.Secret = secret
+	}
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = models.StringList{}
+	}
+
+	if err := s.db.Create(webhook).Error; err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookByID retrieves a webhook by ID
+func (s *WebhookService) GetWebhookByID(id uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.First(&webhook, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// GetAllWebhooks retrieves all webhooks with pagination
+func (s *WebhookService) GetAllWebhooks(page, limit int) ([]models.Webhook, int64, error) {
+	var webhooks []models.Webhook
+	var total int64
+
+	// Count total records
+	if err := s.db.Model(&models.Webhook{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Get webhooks with pagination
+	if err := s.db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&webhooks).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	return webhooks, total, nil
+}
+
+// UpdateWebhook updates an existing webhook. Updates are given as a column map
+// so that fields can be cleared or deactivated.
+func (s *WebhookService) UpdateWebhook(id uuid.UUID, updates map[string]interface{}) error {
+	result := s.db.Model(&models.Webhook{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// DeleteWebhook soft deletes a webhook
+func (s *WebhookService) DeleteWebhook(id uuid.UUID) error {
+	result := s.db.Delete(&models.Webhook{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// GetSubscribedWebhooks retrieves active webhooks subscribed to an event type
+func (s *WebhookService) GetSubscribedWebhooks(eventType string) ([]models.Webhook, error) {
+	eventTypeJSON, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks []models.Webhook
+	if err := s.db.Where("active = ?", true).
+		Where(`event_types = '[]'::jsonb OR event_types @> '["*"]'::jsonb OR event_types @> ?::jsonb`, string(eventTypeJSON)).
+		Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subscribed webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// CreateDelivery records a pending delivery and calls schedule within the same
+// transaction. It returns false if the event was already delivered to this webhook.
+func (s *WebhookService) CreateDelivery(delivery *models.WebhookDelivery, schedule func(tx *gorm.DB) error) (bool, error) {
+	created := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return schedule(tx)
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// GetDeliveryByID retrieves a webhook delivery by ID
+func (s *WebhookService) GetDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := s.db.First(&delivery, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// GetDeliveriesByWebhook retrieves the delivery log of a webhook, newest first
+func (s *WebhookService) GetDeliveriesByWebhook(webhookID uuid.UUID, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Get deliveries with pagination
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt
+func (s *WebhookService) UpdateDelivery(id uuid.UUID, updates map[string]interface{}) error {
+	if err := s.db.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	return nil
+}
+
+// generateSecret returns a random hex-encoded signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,299 @@
+// Package slowquery logs database statements that take longer than a
+// threshold, with their bound parameters, and keeps the most recent ones in
+// memory for inspection through the admin API. Optionally, slow SELECT
+// statements are run again under EXPLAIN ANALYZE and their plans are kept
+// with them; EXPLAIN ANALYZE executes the query a second time, so the capture
+// is meant for non-production environments.
+package slowquery
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// startKey is the statement instance key holding the time a statement started
+const startKey = "slowquery:start"
+
+// maxParamLength caps the length of each parameter kept with an entry
+const maxParamLength = 200
+
+// explainTimeout bounds how long an EXPLAIN ANALYZE capture may run
+const explainTimeout = 30 * time.Second
+
+// Entry is a statement that ran longer than the threshold
+type Entry struct {
+	ID         uint64    `json:"id"`
+	SQL        string    `json:"sql"`
+	Params     []string  `json:"params"`
+	Table      string    `json:"table,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	Rows       int64     `json:"rows"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+	// Plan is the EXPLAIN ANALYZE output, set once the capture completes
+	Plan      string `json:"plan,omitempty"`
+	PlanError string `json:"plan_error,omitempty"`
+}
+
+// Log keeps the most recent slow statements
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	size    int
+	nextID  uint64
+}
+
+// NewLog creates a log keeping the last size slow statements
+func NewLog(size int) *Log {
+	if size <= 0 {
+		size = 100
+	}
+	return &Log{size: size}
+}
+
+// Entries returns the slow statements kept, newest first
+func (l *Log) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]Entry, len(l.entries))
+	for i, entry := range l.entries {
+		entries[len(l.entries)-1-i] = entry
+	}
+	return entries
+}
+
+// Clear discards the slow statements kept
+func (l *Log) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
+
+// add keeps entry, dropping the oldest entry when the log is full, and
+// returns the ID assigned to it
+func (l *Log) add(entry Entry) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	entry.ID = l.nextID
+	if len(l.entries) >= l.size {
+		l.entries = append(l.entries[:0], l.entries[1:]...)
+	}
+	l.entries = append(l.entries, entry)
+	return entry.ID
+}
+
+// setPlan records the outcome of the EXPLAIN ANALYZE capture of an entry
+// still kept
+func (l *Log) setPlan(id uint64, plan string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.entries {
+		if l.entries[i].ID == id {
+			l.entries[i].Plan = plan
+			if err != nil {
+				l.entries[i].PlanError = err.Error()
+			}
+			return
+		}
+	}
+}
+
+var (
+	slowLog *Log
+	once    sync.Once
+)
+
+// Initialize creates the shared slow query log
+func Initialize(cfg *config.Config) {
+	once.Do(func() {
+		slowLog = NewLog(cfg.SlowQuery.BufferSize)
+	})
+}
+
+// GetLog returns the shared slow query log, or nil when it was not initialized
+func GetLog() *Log {
+	return slowLog
+}
+
+// Plugin times every statement and records those slower than the threshold
+// in the log
+type Plugin struct {
+	threshold time.Duration
+	explain   bool
+	log       *Log
+	// explaining holds a token while a capture runs, so slow statements
+	// arriving meanwhile are not explained
+	explaining chan struct{}
+}
+
+// NewPlugin creates a slow query plugin recording into log
+func NewPlugin(cfg config.SlowQueryConfig, log *Log) *Plugin {
+	return &Plugin{
+		threshold:  cfg.Threshold,
+		explain:    cfg.Explain,
+		log:        log,
+		explaining: make(chan struct{}, 1),
+	}
+}
+
+// Name returns the plugin name
+func (*Plugin) Name() string {
+	return "slowquery"
+}
+
+// Initialize registers the plugin's callbacks around every kind of statement
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("slowquery:start", p.start),
+		callbacks.Create().After("gorm:create").Register("slowquery:record", p.record),
+		callbacks.Query().Before("gorm:query").Register("slowquery:start", p.start),
+		callbacks.Query().After("gorm:query").Register("slowquery:record", p.record),
+		callbacks.Update().Before("gorm:update").Register("slowquery:start", p.start),
+		callbacks.Update().After("gorm:update").Register("slowquery:record", p.record),
+		callbacks.Delete().Before("gorm:delete").Register("slowquery:start", p.start),
+		callbacks.Delete().After("gorm:delete").Register("slowquery:record", p.record),
+		callbacks.Row().Before("gorm:row").Register("slowquery:start", p.start),
+		callbacks.Row().After("gorm:row").Register("slowquery:record", p.record),
+		callbacks.Raw().Before("gorm:raw").Register("slowquery:start", p.start),
+		callbacks.Raw().After("gorm:raw").Register("slowquery:record", p.record),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start notes when the statement started
+func (p *Plugin) start(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+// record logs the statement when it ran longer than the threshold
+func (p *Plugin) record(db *gorm.DB) {
+	value, ok := db.InstanceGet(startKey)
+	if !ok {
+		return
+	}
+	started, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(started)
+	if elapsed < p.threshold || db.Statement.SQL.Len() == 0 {
+		return
+	}
+
+	query := db.Statement.SQL.String()
+	vars := append([]interface{}(nil), db.Statement.Vars...)
+	// Parameters are logged as the database logger renders them, with those
+	// of redacted columns masked
+	params := vars
+	if filter, ok := db.Logger.(gorm.ParamsFilter); ok {
+		_, params = filter.ParamsFilter(db.Statement.Context, query, vars...)
+	}
+	entry := Entry{
+		SQL:        query,
+		Params:     formatParams(params),
+		Table:      db.Statement.Table,
+		DurationMs: float64(elapsed.Microseconds()) / 1000,
+		Rows:       db.RowsAffected,
+		At:         started,
+	}
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		entry.Error = db.Error.Error()
+	}
+
+	utils.LogWarn("Slow query", map[string]interface{}{
+		"sql":         entry.SQL,
+		"params":      entry.Params,
+		"duration_ms": entry.DurationMs,
+		"rows":        entry.Rows,
+	})
+	if p.log == nil {
+		return
+	}
+	id := p.log.add(entry)
+
+	if p.explain && isSelect(query) {
+		select {
+		case p.explaining <- struct{}{}:
+			go func() {
+				defer func() { <-p.explaining }()
+				plan, err := explain(db.Config.ConnPool, query, vars)
+				p.log.setPlan(id, plan, err)
+			}()
+		default:
+		}
+	}
+}
+
+// explain runs the query under EXPLAIN ANALYZE on the connection pool, outside
+// any transaction and GORM callbacks, and returns the plan
+func explain(pool gorm.ConnPool, query string, vars []interface{}) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	rows, err := pool.QueryContext(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+query, vars...)
+	if err != nil {
+		return "", fmt.Errorf("failed to explain query: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line sql.NullString
+		if err := rows.Scan(&line); err != nil {
+			return "", fmt.Errorf("failed to read query plan: %w", err)
+		}
+		lines = append(lines, line.String)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to read query plan: %w", err)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// isSelect reports whether the statement only reads, so running it again
+// under EXPLAIN ANALYZE has no side effects
+func isSelect(query string) bool {
+	query = strings.TrimSpace(query)
+	return len(query) >= 6 && strings.EqualFold(query[:6], "SELECT") &&
+		!strings.Contains(strings.ToUpper(query), "FOR UPDATE")
+}
+
+// formatParams renders bound parameters for logging, shortening long values
+func formatParams(vars []interface{}) []string {
+	params := make([]string, len(vars))
+	for i, v := range vars {
+		var param string
+		switch value := v.(type) {
+		case []byte:
+			param = fmt.Sprintf("<%d bytes>", len(value))
+		case nil:
+			param = "NULL"
+		default:
+			param = fmt.Sprintf("%v", value)
+		}
+		if utf8.RuneCountInString(param) > maxParamLength {
+			param = string([]rune(param)[:maxParamLength]) + "…"
+		}
+		params[i] = param
+	}
+	return params
+}
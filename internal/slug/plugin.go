@@ -0,0 +1,92 @@
+package slug
+
+import (
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sluggable is implemented by models whose rows get slugs, derived from the
+// text SlugSource returns
+type Sluggable interface {
+	SlugSource() string
+}
+
+// Plugin assigns a unique slug to every created row of a Sluggable model that
+// has none. It runs after the tenancy plugin, which sets the rows' tenant.
+type Plugin struct{}
+
+// Name returns the plugin name
+func (Plugin) Name() string {
+	return "slug"
+}
+
+// Initialize registers the plugin's callbacks
+func (p Plugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().After("tenancy:create").Before("gorm:create").Register("slug:create", p.create)
+}
+
+// create assigns slugs to new rows
+func (Plugin) create(db *gorm.DB) {
+	s := db.Statement.Schema
+	if db.Error != nil || s == nil || s.PrioritizedPrimaryField == nil {
+		return
+	}
+	slugField, tenantField := s.LookUpField("Slug"), s.LookUpField("TenantID")
+	if slugField == nil || tenantField == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	// Slugs given to earlier rows of the same statement are not in the
+	// database yet
+	assigned := make(map[uuid.UUID]map[string]bool)
+	assign := func(rv reflect.Value) {
+		var value interface{} = rv.Interface()
+		if rv.CanAddr() {
+			value = rv.Addr().Interface()
+		}
+		record, ok := value.(Sluggable)
+		if !ok {
+			return
+		}
+		if _, zero := slugField.ValueOf(ctx, rv); !zero {
+			return
+		}
+
+		tenantValue, _ := tenantField.ValueOf(ctx, rv)
+		idValue, _ := s.PrioritizedPrimaryField.ValueOf(ctx, rv)
+		tenantID, _ := tenantValue.(uuid.UUID)
+		id, _ := idValue.(uuid.UUID)
+
+		base := Make(record.SlugSource())
+		used, err := taken(db, s.Table, tenantID, id, base)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		if assigned[tenantID] == nil {
+			assigned[tenantID] = make(map[string]bool)
+		}
+		for slug := range assigned[tenantID] {
+			used[slug] = true
+		}
+
+		slug := pick(base, used)
+		assigned[tenantID][slug] = true
+		if err := slugField.Set(ctx, rv, slug); err != nil {
+			db.AddError(err)
+		}
+	}
+
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
@@ -0,0 +1,174 @@
+// Package slug gives books, authors, and categories URL slugs derived from
+// their title or name, unique within their tenant.
+//
+// The Plugin assigns slugs to created rows. When an entity is renamed, Rename
+// gives it a new slug and records the old one in the slug history, so Resolve
+// still finds the entity by any slug it has had. Slugs in the history stay
+// reserved for their entity.
+package slug
+
+import (
+	"bookstore-api/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// maxLength bounds the length of the text part of a slug, leaving room for a
+// numeric suffix within the 255 character column
+const maxLength = 200
+
+// fallback is the slug of titles and names without letters or digits
+const fallback = "untitled"
+
+// Make returns the slug for a title or name: lower case ASCII letters and
+// digits, with runs of anything else replaced by single hyphens
+func Make(source string) string {
+	// Strip accents so "Café" becomes "cafe" rather than "caf"
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), source)
+	if err != nil {
+		stripped = source
+	}
+
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(stripped) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+		if b.Len() >= maxLength {
+			break
+		}
+	}
+
+	if b.Len() == 0 {
+		return fallback
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// matches reports whether slug is base or base with a numeric suffix, as
+// generated by unique
+func matches(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// taken returns the slugs derived from base that are in use in table or in
+// the slug history for the tenant, other than the history of entity id
+func taken(db *gorm.DB, table string, tenantID, id uuid.UUID, base string) (map[string]bool, error) {
+	db = db.Session(&gorm.Session{NewDB: true})
+	pattern := base + "-%"
+
+	var current []string
+	if err := db.Table(table).Where("tenant_id = ? AND (slug = ? OR slug LIKE ?)", tenantID, base, pattern).
+		Pluck("slug", &current).Error; err != nil {
+		return nil, fmt.Errorf("failed to check slugs: %w", err)
+	}
+
+	var previous []string
+	if err := db.Model(&models.SlugHistory{}).
+		Where("tenant_id = ? AND entity_table = ? AND entity_id <> ? AND (slug = ? OR slug LIKE ?)", tenantID, table, id, base, pattern).
+		Pluck("slug", &previous).Error; err != nil {
+		return nil, fmt.Errorf("failed to check slug history: %w", err)
+	}
+
+	used := make(map[string]bool, len(current)+len(previous))
+	for _, slug := range append(current, previous...) {
+		used[slug] = true
+	}
+	return used, nil
+}
+
+// pick returns base, or base with the lowest numeric suffix from 2 up, that
+// is not in used
+func pick(base string, used map[string]bool) string {
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+	return candidate
+}
+
+// Rename gives the entity id in table a slug derived from its new title or
+// name, recording its current slug in the history, and returns the slug. The
+// current slug is kept if it already derives from source.
+func Rename(tx *gorm.DB, table string, tenantID, id uuid.UUID, current, source string) (string, error) {
+	base := Make(source)
+	if matches(current, base) {
+		return current, nil
+	}
+
+	used, err := taken(tx, table, tenantID, id, base)
+	if err != nil {
+		return "", err
+	}
+	slug := pick(base, used)
+
+	db := tx.Session(&gorm.Session{NewDB: true})
+	if current != "" {
+		if err := db.Create(&models.SlugHistory{TenantID: tenantID, EntityTable: table, EntityID: id, Slug: current}).Error; err != nil {
+			return "", fmt.Errorf("failed to record slug history: %w", err)
+		}
+	}
+	// An entity renamed back to an earlier slug takes it out of the history
+	if err := db.Where("tenant_id = ? AND entity_table = ? AND slug = ?", tenantID, table, slug).
+		Delete(&models.SlugHistory{}).Error; err != nil {
+		return "", fmt.Errorf("failed to update slug history: %w", err)
+	}
+	if err := db.Table(table).Where("id = ?", id).Update("slug", slug).Error; err != nil {
+		return "", fmt.Errorf("failed to update slug: %w", err)
+	}
+	return slug, nil
+}
+
+// Resolve returns the ID of the entity in table that had slug before it was
+// renamed. It returns uuid.Nil if no entity had it; current slugs are looked
+// up on the entity tables themselves.
+func Resolve(db *gorm.DB, table, slug string) (uuid.UUID, error) {
+	var history models.SlugHistory
+	err := db.Where("entity_table = ? AND slug = ?", table, slug).First(&history).Error
+	if err == gorm.ErrRecordNotFound {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to look up slug: %w", err)
+	}
+	return history.EntityID, nil
+}
+
+// Forward makes the slugs of entity from, which was merged into entity to,
+// lead to to: its current slug and those in its history
+func Forward(tx *gorm.DB, table string, tenantID, from, to uuid.UUID, slug string) error {
+	db := tx.Session(&gorm.Session{NewDB: true})
+	if err := db.Model(&models.SlugHistory{}).Where("tenant_id = ? AND entity_table = ? AND entity_id = ?", tenantID, table, from).
+		Update("entity_id", to).Error; err != nil {
+		return fmt.Errorf("failed to update slug history: %w", err)
+	}
+	history := models.SlugHistory{TenantID: tenantID, EntityTable: table, EntityID: to, Slug: slug}
+	if err := db.Create(&history).Error; err != nil {
+		return fmt.Errorf("failed to record slug history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package storage stores uploaded media such as author photos and book covers.
+//
+// Files are addressed by slash-separated keys chosen by the caller, for
+// example "authors/<id>/<version>/medium.jpg", and served from the URL the
+// Storage returns for the key.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage stores media files by key
+type Storage interface {
+	// Put stores data under key, replacing any file stored there
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Open opens the file stored under key for reading
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the file stored under key; deleting a missing file is not
+	// an error
+	Delete(ctx context.Context, key string) error
+	// URL returns the URL clients fetch the file stored under key from
+	URL(key string) string
+}
+
+// Local stores files in a directory on disk. The directory is served by the
+// HTTP server when the URL prefix is a path.
+type Local struct {
+	dir       string
+	urlPrefix string
+}
+
+// NewLocal creates a storage that keeps files under dir and serves them under
+// urlPrefix, which is a path such as /media or an absolute URL of a CDN
+// mirroring dir
+func NewLocal(dir, urlPrefix string) *Local {
+	return &Local{dir: dir, urlPrefix: strings.TrimSuffix(urlPrefix, "/")}
+}
+
+// Put implements Storage. The file is written to a temporary name and renamed
+// into place, so readers never see a partial file.
+func (l *Local) Put(ctx context.Context, key, contentType string, data []byte) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("failed to create media directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create media file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store media file: %w", err)
+	}
+	return nil
+}
+
+// Open implements Storage
+func (l *Local) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open media file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete implements Storage
+func (l *Local) Delete(ctx context.Context, key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete media file: %w", err)
+	}
+	return nil
+}
+
+// URL implements Storage
+func (l *Local) URL(key string) string {
+	return l.urlPrefix + "/" + key
+}
+
+// Dir returns the directory files are stored in
+func (l *Local) Dir() string {
+	return l.dir
+}
+
+// path returns the file name for key, rejecting keys that escape the directory
+func (l *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key {
+		return "", fmt.Errorf("invalid media key %q", key)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(clean)), nil
+}
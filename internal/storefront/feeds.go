@@ -0,0 +1,302 @@
+package storefront
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Feed names, which are also the file names feeds are served under
+const (
+	FeedGoogleMerchant  = "google-merchant.xml"
+	FeedNewReleasesRSS  = "new-releases.rss"
+	FeedNewReleasesAtom = "new-releases.atom"
+)
+
+// Feeds lists the generated feeds
+var Feeds = []string{FeedGoogleMerchant, FeedNewReleasesRSS, FeedNewReleasesAtom}
+
+// Google Merchant Center limits on attribute lengths
+const (
+	merchantTitleLimit       = 150
+	merchantDescriptionLimit = 5000
+)
+
+// IsFeed reports whether name is a generated feed
+func IsFeed(name string) bool {
+	for _, feed := range Feeds {
+		if feed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the media type a feed is served as
+func ContentType(name string) string {
+	switch name {
+	case FeedNewReleasesRSS:
+		return "application/rss+xml; charset=utf-8"
+	case FeedNewReleasesAtom:
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/xml; charset=utf-8"
+}
+
+// Channel describes the storefront a feed is published for
+type Channel struct {
+	// ID identifies the feed in Atom, such as urn:bookstore:<tenant>:new-releases
+	ID          string
+	Title       string
+	Description string
+	// Link is the storefront's URL
+	Link     string
+	Currency string
+	Updated  time.Time
+}
+
+// Item is a book listed in a feed
+type Item struct {
+	ISBN        string
+	Title       string
+	Description string
+	// Link is the URL of the book's storefront page
+	Link      string
+	Author    string
+	Category  string
+	Publisher string
+	Price     float64
+	// SalePrice is the price under a promotion ending at SaleEndsAt
+	SalePrice   *float64
+	SaleEndsAt  time.Time
+	InStock     bool
+	PublishedAt *time.Time
+	UpdatedAt   time.Time
+}
+
+// merchantFeed is a Google Merchant Center product feed: RSS 2.0 with the
+// g: namespace
+type merchantFeed struct {
+	XMLName xml.Name        `xml:"rss"`
+	Version string          `xml:"version,attr"`
+	G       string          `xml:"xmlns:g,attr"`
+	Channel merchantChannel `xml:"channel"`
+}
+
+type merchantChannel struct {
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	Items       []merchantItem `xml:"item"`
+}
+
+type merchantItem struct {
+	ID                     string `xml:"g:id"`
+	Title                  string `xml:"g:title"`
+	Description            string `xml:"g:description,omitempty"`
+	Link                   string `xml:"g:link"`
+	Price                  string `xml:"g:price"`
+	SalePrice              string `xml:"g:sale_price,omitempty"`
+	SalePriceEffectiveDate string `xml:"g:sale_price_effective_date,omitempty"`
+	Availability           string `xml:"g:availability"`
+	Condition              string `xml:"g:condition"`
+	GTIN                   string `xml:"g:gtin"`
+	Brand                  string `xml:"g:brand,omitempty"`
+	GoogleProductCategory  string `xml:"g:google_product_category"`
+	ProductType            string `xml:"g:product_type,omitempty"`
+}
+
+// GoogleMerchant renders a Google Merchant Center product feed of items.
+// Books are identified by ISBN, which is also their GTIN, and branded with
+// their publisher, or their author when they have none.
+func GoogleMerchant(channel Channel, items []Item) ([]byte, error) {
+	feed := merchantFeed{
+		Version: "2.0",
+		G:       "http://base.google.com/ns/1.0",
+		Channel: merchantChannel{
+			Title:       channel.Title,
+			Link:        channel.Link,
+			Description: channel.Description,
+			Items:       make([]merchantItem, 0, len(items)),
+		},
+	}
+	for _, item := range items {
+		entry := merchantItem{
+			ID:                    item.ISBN,
+			Title:                 truncate(item.Title, merchantTitleLimit),
+			Description:           truncate(item.Description, merchantDescriptionLimit),
+			Link:                  item.Link,
+			Price:                 merchantPrice(item.Price, channel.Currency),
+			Availability:          "out_of_stock",
+			Condition:             "new",
+			GTIN:                  item.ISBN,
+			Brand:                 item.Publisher,
+			GoogleProductCategory: "Media > Books",
+			ProductType:           item.Category,
+		}
+		if entry.Brand == "" {
+			entry.Brand = item.Author
+		}
+		if item.InStock {
+			entry.Availability = "in_stock"
+		}
+		if item.SalePrice != nil {
+			entry.SalePrice = merchantPrice(*item.SalePrice, channel.Currency)
+			entry.SalePriceEffectiveDate = channel.Updated.UTC().Format(time.RFC3339) + "/" + item.SaleEndsAt.UTC().Format(time.RFC3339)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, entry)
+	}
+	return render(feed)
+}
+
+// rssFeed is an RSS 2.0 feed
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description,omitempty"`
+	Creator     string  `xml:"dc:creator,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders an RSS 2.0 feed of items, dated by publication
+func RSS(channel Channel, items []Item) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         channel.Title,
+			Link:          channel.Link,
+			Description:   channel.Description,
+			LastBuildDate: channel.Updated.UTC().Format(time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(items)),
+		},
+	}
+	for _, item := range items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: "urn:isbn:" + item.ISBN},
+			Description: item.Description,
+			Creator:     item.Author,
+			Category:    item.Category,
+		}
+		if item.PublishedAt != nil {
+			entry.PubDate = item.PublishedAt.UTC().Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, entry)
+	}
+	return render(feed)
+}
+
+// atomFeed is an Atom feed
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Link      atomLink      `xml:"link"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published,omitempty"`
+	Author    *atomAuthor   `xml:"author,omitempty"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Summary   string        `xml:"summary,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Atom renders an Atom feed of items
+func Atom(channel Channel, items []Item) ([]byte, error) {
+	feed := atomFeed{
+		ID:      channel.ID,
+		Title:   channel.Title,
+		Updated: channel.Updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Rel: "alternate", Href: channel.Link},
+		Entries: make([]atomEntry, 0, len(items)),
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			ID:      "urn:isbn:" + item.ISBN,
+			Title:   item.Title,
+			Link:    atomLink{Rel: "alternate", Href: item.Link},
+			Updated: item.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary: item.Description,
+		}
+		if item.PublishedAt != nil {
+			entry.Published = item.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		if item.Category != "" {
+			entry.Category = &atomCategory{Term: item.Category}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return render(feed)
+}
+
+// render encodes a feed as an indented XML document
+func render(feed interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render feed: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// merchantPrice formats a price as Google Merchant Center expects, such as
+// "12.50 USD"
+func merchantPrice(price float64, currency string) string {
+	return strconv.FormatFloat(price, 'f', 2, 64) + " " + currency
+}
+
+// truncate shortens s to at most limit characters
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit])
+}
@@ -0,0 +1,78 @@
+package storefront
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// sitemapNamespace is the namespace of sitemaps and sitemap indexes
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapIndex is the name the sitemap index is served under
+const SitemapIndex = "sitemap.xml"
+
+// Sitemap sections, which name their pages <section>-<page>.xml
+const (
+	SitemapBooks      = "books"
+	SitemapAuthors    = "authors"
+	SitemapCategories = "categories"
+)
+
+// SitemapSections lists the sections in the order the index lists them
+var SitemapSections = []string{SitemapBooks, SitemapAuthors, SitemapCategories}
+
+// Page is a storefront page listed in a sitemap
+type Page struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// SitemapRef is a sitemap listed in the sitemap index
+type SitemapRef struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// RenderSitemap renders a sitemap of pages
+func RenderSitemap(pages []Page) ([]byte, error) {
+	set := urlSet{Xmlns: sitemapNamespace, URLs: make([]sitemapURL, 0, len(pages))}
+	for _, page := range pages {
+		set.URLs = append(set.URLs, sitemapURL{Loc: page.Loc, LastMod: lastMod(page.LastMod)})
+	}
+	return render(set)
+}
+
+// RenderSitemapIndex renders a sitemap index of sitemaps
+func RenderSitemapIndex(sitemaps []SitemapRef) ([]byte, error) {
+	index := sitemapIndex{Xmlns: sitemapNamespace, Sitemaps: make([]sitemapURL, 0, len(sitemaps))}
+	for _, sitemap := range sitemaps {
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{Loc: sitemap.Loc, LastMod: lastMod(sitemap.LastMod)})
+	}
+	return render(index)
+}
+
+// lastMod formats a modification time in W3C datetime format, leaving out
+// unknown times
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,67 @@
+// Package storefront links to the storefront the catalog is sold on and
+// renders the product feeds marketing tools read the catalog from and the
+// sitemaps search engines crawl it by.
+package storefront
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"net/url"
+	"strings"
+)
+
+// Links builds URLs of storefront pages
+type Links struct {
+	url          string
+	bookPath     string
+	authorPath   string
+	categoryPath string
+}
+
+// NewLinks creates links to the storefront in cfg
+func NewLinks(cfg config.StorefrontConfig) *Links {
+	return &Links{
+		url:          strings.TrimSuffix(cfg.URL, "/"),
+		bookPath:     cfg.BookPath,
+		authorPath:   cfg.AuthorPath,
+		categoryPath: cfg.CategoryPath,
+	}
+}
+
+// BaseURL returns the storefront URL of tenant: https://<domain> for tenants
+// with a domain, and the configured URL otherwise
+func (l *Links) BaseURL(tenant *models.Tenant) string {
+	if tenant != nil && tenant.Domain != nil && *tenant.Domain != "" {
+		return "https://" + *tenant.Domain
+	}
+	return l.url
+}
+
+// Book returns the URL of a book's page
+func (l *Links) Book(tenant *models.Tenant, slug string) string {
+	return l.BaseURL(tenant) + pagePath(l.bookPath, slug)
+}
+
+// Author returns the URL of an author's page
+func (l *Links) Author(tenant *models.Tenant, slug string) string {
+	return l.BaseURL(tenant) + pagePath(l.authorPath, slug)
+}
+
+// Category returns the URL of a category's page
+func (l *Links) Category(tenant *models.Tenant, slug string) string {
+	return l.BaseURL(tenant) + pagePath(l.categoryPath, slug)
+}
+
+// Sitemap returns the URL of a sitemap page, which the storefront serves
+// from this API so it is on the same host as the pages it lists
+func (l *Links) Sitemap(tenant *models.Tenant, name string) string {
+	return l.BaseURL(tenant) + "/sitemaps/" + name
+}
+
+// pagePath returns path with {slug} replaced by the escaped slug
+func pagePath(path, slug string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return strings.ReplaceAll(path, "{slug}", url.PathEscape(slug))
+}
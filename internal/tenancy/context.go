@@ -0,0 +1,63 @@
+package tenancy
+
+import (
+	"bookstore-api/internal/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type contextKey int
+
+const (
+	tenantKey contextKey = iota
+	tenantIDKey
+	systemKey
+)
+
+// WithTenant returns a context whose queries are scoped to the tenant
+func WithTenant(ctx context.Context, tenant *models.Tenant) context.Context {
+	ctx = context.WithValue(ctx, tenantKey, tenant)
+	return context.WithValue(ctx, tenantIDKey, tenant.ID)
+}
+
+// WithTenantID returns a context whose queries are scoped to the tenant with the
+// given ID, for background work that only knows the ID
+func WithTenantID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantIDKey, id)
+}
+
+// WithSystem returns a context allowed to query across all tenants. Only
+// background work that is not acting for a storefront should use it.
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey, true)
+}
+
+// FromContext returns the tenant set by WithTenant, or nil
+func FromContext(ctx context.Context) *models.Tenant {
+	tenant, _ := ctx.Value(tenantKey).(*models.Tenant)
+	return tenant
+}
+
+// TenantID returns the ID of the context's tenant
+func TenantID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(tenantIDKey).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
+
+// IsSystem reports whether the context may query across tenants
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey).(bool)
+	return system
+}
+
+// System returns db with a context allowed to query across all tenants
+func System(db *gorm.DB) *gorm.DB {
+	return db.WithContext(WithSystem(context.Background()))
+}
+
+// Scoped returns db with its queries scoped to the tenant with the given ID
+func Scoped(db *gorm.DB, tenantID uuid.UUID) *gorm.DB {
+	return db.WithContext(WithTenantID(context.Background(), tenantID))
+}
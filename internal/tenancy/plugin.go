@@ -0,0 +1,117 @@
+package tenancy
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrNoTenant is returned for queries on tenant-scoped models made without a tenant
+var ErrNoTenant = errors.New("tenancy: no tenant in context")
+
+// ErrTenantMismatch is returned when creating a row for a tenant other than the context's
+var ErrTenantMismatch = errors.New("tenancy: row belongs to another tenant")
+
+// tenantField is the field that marks a model as tenant-scoped
+const tenantField = "TenantID"
+
+// Plugin scopes every query on models with a TenantID field to the tenant in
+// the statement context, and sets TenantID on created rows. Statements without
+// a tenant fail unless their context was marked with WithSystem, so a missing
+// scope is an error rather than a cross-tenant read. Raw SQL is not scoped.
+type Plugin struct{}
+
+// Name returns the plugin name
+func (Plugin) Name() string {
+	return "tenancy"
+}
+
+// Initialize registers the plugin's callbacks
+func (p Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenancy:create", p.create); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenancy:query", p.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenancy:update", p.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenancy:delete", p.scope); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("tenancy:row", p.scope)
+}
+
+// scope adds the tenant condition to queries, updates, and deletes
+func (Plugin) scope(db *gorm.DB) {
+	field := tenantFieldOf(db.Statement.Schema)
+	if field == nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	tenantID, ok := TenantID(db.Statement.Context)
+	if !ok {
+		if !IsSystem(db.Statement.Context) {
+			db.AddError(ErrNoTenant)
+		}
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// create assigns the context's tenant to new rows
+func (Plugin) create(db *gorm.DB) {
+	field := tenantFieldOf(db.Statement.Schema)
+	if field == nil {
+		return
+	}
+
+	tenantID, ok := TenantID(db.Statement.Context)
+	system := IsSystem(db.Statement.Context)
+	if !ok && !system {
+		db.AddError(ErrNoTenant)
+		return
+	}
+
+	ctx := db.Statement.Context
+	assign := func(rv reflect.Value) {
+		value, zero := field.ValueOf(ctx, rv)
+		switch {
+		case zero && ok:
+			if err := field.Set(ctx, rv, tenantID); err != nil {
+				db.AddError(err)
+			}
+		case zero:
+			// System writes must say which tenant the row belongs to
+			db.AddError(ErrNoTenant)
+		case ok && value.(uuid.UUID) != tenantID:
+			db.AddError(ErrTenantMismatch)
+		}
+	}
+
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
+
+// tenantFieldOf returns the schema's tenant field, or nil for unscoped models
+func tenantFieldOf(s *schema.Schema) *schema.Field {
+	if s == nil {
+		return nil
+	}
+	return s.LookUpField(tenantField)
+}
@@ -0,0 +1,173 @@
+package tenancy
+
+import (
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/models"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownTenant is returned when no active tenant matches a request
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// maxResolverEntries bounds the lookup cache, which unknown hosts could otherwise grow
+const maxResolverEntries = 10000
+
+var (
+	resolver     *Resolver
+	resolverOnce sync.Once
+)
+
+// resolverEntry is a cached lookup result with its expiry time
+type resolverEntry struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+// Resolver finds the tenant a request belongs to by header value, host name,
+// or the configured default, caching lookups briefly
+type Resolver struct {
+	db      *gorm.DB
+	cfg     config.TenancyConfig
+	mu      sync.RWMutex
+	entries map[string]resolverEntry
+}
+
+// NewResolver creates a new tenant resolver
+func NewResolver(db *gorm.DB, cfg config.TenancyConfig) *Resolver {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Minute
+	}
+	cfg.BaseDomain = strings.ToLower(strings.TrimPrefix(cfg.BaseDomain, "."))
+
+	return &Resolver{
+		db:      System(db),
+		cfg:     cfg,
+		entries: make(map[string]resolverEntry),
+	}
+}
+
+// InitializeResolver initializes the shared tenant resolver
+func InitializeResolver(db *gorm.DB, cfg *config.Config) {
+	resolverOnce.Do(func() {
+		resolver = NewResolver(db, cfg.Tenancy)
+	})
+}
+
+// GetResolver returns the shared tenant resolver
+func GetResolver() *Resolver {
+	if resolver == nil {
+		log.Fatal("Tenant resolver not initialized. Call InitializeResolver first.")
+	}
+	return resolver
+}
+
+// Resolve returns the tenant named by an explicit identifier (slug or ID, e.g.
+// from a header), else by the request host, else the default tenant
+func (r *Resolver) Resolve(identifier, host string) (*models.Tenant, error) {
+	if identifier = strings.TrimSpace(identifier); identifier != "" {
+		return r.lookup("key:"+strings.ToLower(identifier), func(q *gorm.DB) *gorm.DB {
+			if id, err := uuid.Parse(identifier); err == nil {
+				return q.Where("id = ?", id)
+			}
+			return q.Where("slug = ?", strings.ToLower(identifier))
+		})
+	}
+
+	if host = hostname(host); host != "" {
+		tenant, err := r.lookup("host:"+host, func(q *gorm.DB) *gorm.DB {
+			if slug := r.subdomain(host); slug != "" {
+				return q.Where("domain = ? OR slug = ?", host, slug)
+			}
+			return q.Where("domain = ?", host)
+		})
+		if !errors.Is(err, ErrUnknownTenant) {
+			return tenant, err
+		}
+	}
+
+	if r.cfg.DefaultTenant == "" {
+		return nil, ErrUnknownTenant
+	}
+	return r.lookup("key:"+r.cfg.DefaultTenant, func(q *gorm.DB) *gorm.DB {
+		return q.Where("slug = ?", r.cfg.DefaultTenant)
+	})
+}
+
+// Get returns an active tenant by ID
+func (r *Resolver) Get(id uuid.UUID) (*models.Tenant, error) {
+	return r.lookup("id:"+id.String(), func(q *gorm.DB) *gorm.DB {
+		return q.Where("id = ?", id)
+	})
+}
+
+// Invalidate drops all cached lookups, e.g. after a tenant changes
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[string]resolverEntry)
+}
+
+// lookup returns the cached tenant for key, querying with where on a miss.
+// Misses are cached too so unknown hosts cannot hammer the database.
+func (r *Resolver) lookup(key string, where func(*gorm.DB) *gorm.DB) (*models.Tenant, error) {
+	r.mu.RLock()
+	entry, ok := r.entries[key]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		if entry.tenant == nil {
+			return nil, ErrUnknownTenant
+		}
+		return entry.tenant, nil
+	}
+
+	var tenant models.Tenant
+	err := where(r.db.Model(&models.Tenant{})).Where("active = ?", true).First(&tenant).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	entry = resolverEntry{expiresAt: time.Now().Add(r.cfg.CacheTTL)}
+	if err == nil {
+		entry.tenant = &tenant
+	}
+	r.mu.Lock()
+	if len(r.entries) >= maxResolverEntries {
+		r.entries = make(map[string]resolverEntry)
+	}
+	r.entries[key] = entry
+	r.mu.Unlock()
+
+	if entry.tenant == nil {
+		return nil, ErrUnknownTenant
+	}
+	return entry.tenant, nil
+}
+
+// subdomain returns the tenant slug in host under the base domain, if any
+func (r *Resolver) subdomain(host string) string {
+	if r.cfg.BaseDomain == "" || !strings.HasSuffix(host, "."+r.cfg.BaseDomain) {
+		return ""
+	}
+	slug := strings.TrimSuffix(host, "."+r.cfg.BaseDomain)
+	if strings.Contains(slug, ".") {
+		return ""
+	}
+	return slug
+}
+
+// hostname lower-cases host and strips any port
+func hostname(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return host
+}
@@ -0,0 +1,338 @@
+package testutil
+
+import (
+	"bookstore-api/internal/analytics"
+	"bookstore-api/internal/auth"
+	"bookstore-api/internal/config"
+	"bookstore-api/internal/dashboard"
+	"bookstore-api/internal/database"
+	"bookstore-api/internal/events"
+	bookstoregrpc "bookstore-api/internal/grpc"
+	"bookstore-api/internal/handlers"
+	"bookstore-api/internal/ids"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/metadata"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/notifications"
+	"bookstore-api/internal/operations"
+	"bookstore-api/internal/opsalerts"
+	"bookstore-api/internal/pii"
+	"bookstore-api/internal/scheduler"
+	"bookstore-api/internal/server"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/storage"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/timestamps"
+	"bookstore-api/internal/utils/signing"
+	pb "bookstore-api/proto"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcBufferSize is the size of the in-memory gRPC connection buffer
+const grpcBufferSize = 1 << 20
+
+// testRateLimit is the default tenant's rate limit, for both the general and
+// the strict limiter, in requests per minute. Tests send requests far faster
+// than the clients the default limits are sized for.
+const testRateLimit = 1000000
+
+// Env is a migrated database with the REST and gRPC APIs served against it.
+// The database, token issuer, and other process-wide singletons can only be
+// initialized once, so create one Env per test binary, typically in TestMain.
+type Env struct {
+	Config   *config.Config
+	Postgres *Postgres
+
+	// dir holds the files the APIs write, such as uploads and archives
+	dir      string
+	services *services.Services
+	app      *fiber.App
+	listener *bufconn.Listener
+	conn     *grpc.ClientConn
+}
+
+// Response is a decoded REST API response
+type Response struct {
+	StatusCode int             `json:"-"`
+	Header     http.Header     `json:"-"`
+	Error      bool            `json:"error"`
+	Code       string          `json:"code"`
+	Field      string          `json:"field"`
+	Message    string          `json:"message"`
+	Data       json.RawMessage `json:"data"`
+	Details    interface{}     `json:"details"`
+	Fields     json.RawMessage `json:"fields"`
+	Pagination json.RawMessage `json:"pagination"`
+}
+
+// Setup starts PostgreSQL, runs the migrations, and serves the APIs. It
+// changes the working directory to the module root, where the migrations are.
+func Setup(ctx context.Context) (*Env, error) {
+	root, err := moduleRoot()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(root); err != nil {
+		return nil, fmt.Errorf("failed to change to module root: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Keep the files the APIs write out of the module, and serve the routes
+	// of the optional modules too
+	dir, err := os.MkdirTemp("", "bookstore-test-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file directory: %w", err)
+	}
+	cfg.Storage.Dir = filepath.Join(dir, "media")
+	cfg.Operations.Dir = filepath.Join(dir, "operations")
+	cfg.Feeds.Dir = filepath.Join(dir, "feeds")
+	cfg.Archive.Dir = filepath.Join(dir, "archives")
+	cfg.Archive.Enabled = true
+	cfg.Rentals.Enabled = true
+
+	pg, err := StartPostgres(ctx)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	pg.Configure(cfg)
+
+	env := &Env{Config: cfg, Postgres: pg, dir: dir}
+	if err := env.start(); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+// start initializes the application as the server command does, without the
+// background workers and scheduler, and serves the APIs in-process
+func (e *Env) start() error {
+	cfg := e.Config
+
+	// Process-wide singletons, in the order the server command initializes
+	// them; the handlers look several of them up when they are built
+	if err := timestamps.Initialize(cfg); err != nil {
+		return fmt.Errorf("failed to initialize timestamps: %w", err)
+	}
+	if err := pii.InitializeKeyring(cfg); err != nil {
+		return fmt.Errorf("failed to initialize PII encryption: %w", err)
+	}
+	if err := ids.Initialize(cfg); err != nil {
+		return fmt.Errorf("failed to initialize ID generation: %w", err)
+	}
+	opsalerts.Initialize(cfg)
+	if err := database.InitializeDB(cfg); err != nil {
+		return err
+	}
+	if err := database.Migrate(cfg); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	db := database.GetDB()
+	if err := db.WithContext(tenancy.WithSystem(context.Background())).
+		Model(&models.Tenant{}).
+		Where("id = ?", models.DefaultTenantID).
+		Updates(map[string]interface{}{"rate_limit": testRateLimit, "strict_rate_limit": testRateLimit}).Error; err != nil {
+		return fmt.Errorf("failed to raise the default tenant's rate limits: %w", err)
+	}
+	database.InitializeSupervisor(cfg)
+	opsalerts.InitializeErrorRateMonitor(cfg)
+	tenancy.InitializeResolver(db, cfg)
+	services.InitializeCountCache(cfg)
+	if err := services.InitializeInventory(cfg); err != nil {
+		return fmt.Errorf("failed to configure inventory: %w", err)
+	}
+	if err := auth.InitializeIssuer(cfg); err != nil {
+		return fmt.Errorf("failed to initialize token issuer: %w", err)
+	}
+	services.InitializeRevocationCache(cfg)
+	auth.InitializeLoginGuard(cfg)
+	svc := services.New(db, cfg, storage.NewLocal(cfg.Storage.Dir, cfg.Storage.URLPrefix))
+	e.services = svc
+
+	jobs.InitializeQueue(db, cfg)
+	events.InitializeDispatcher(db, cfg)
+	notifications.InitializeDispatcher(cfg, svc)
+	analytics.InitializeTracker(db, cfg)
+	events.InitializeStream(db, cfg)
+	dashboard.InitializeHub(db, cfg)
+	if err := metadata.InitializeLookup(cfg); err != nil {
+		return fmt.Errorf("failed to configure metadata providers: %w", err)
+	}
+	operations.InitializeManager(cfg)
+	if err := signing.InitializeSigner(cfg); err != nil {
+		return fmt.Errorf("failed to initialize URL signer: %w", err)
+	}
+	// The scheduler is created for the admin handlers but has no tasks and
+	// is not started
+	scheduler.InitializeScheduler()
+
+	httpServer := server.NewHTTPServer(cfg, svc, handlers.New(cfg, svc))
+	httpServer.SetupRoutes()
+	e.app = httpServer.GetApp()
+
+	e.listener = bufconn.Listen(grpcBufferSize)
+	go bookstoregrpc.NewGRPCServer(svc).Serve(cfg, e.listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return e.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	e.conn = conn
+	return nil
+}
+
+// Close stops the APIs, closes the database, and removes the files and the
+// container
+func (e *Env) Close() error {
+	if e.conn != nil {
+		e.conn.Close()
+	}
+	if e.listener != nil {
+		e.listener.Close()
+	}
+	database.CloseDB()
+	os.RemoveAll(e.dir)
+	if e.Postgres != nil {
+		return e.Postgres.Terminate()
+	}
+	return nil
+}
+
+// AdminKey creates an admin API key for the default tenant
+func (e *Env) AdminKey() (string, error) {
+	ctx := tenancy.WithTenantID(context.Background(), models.DefaultTenantID)
+	_, key, err := e.services.APIKeys.WithContext(ctx).CreateAPIKey("integration tests", "admin", nil)
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// UserToken creates a user with role in the default tenant, signs them in,
+// and returns their ID and access token
+func (e *Env) UserToken(email, role string) (uuid.UUID, string, error) {
+	ctx := tenancy.WithTenantID(context.Background(), models.DefaultTenantID)
+	user, err := e.services.Users.WithContext(ctx).CreateUser(email, "Test User", role)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	session, _, err := e.services.Sessions.WithContext(ctx).CreateSession(user.ID, "integration tests", "127.0.0.1")
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	token, _, err := auth.GetIssuer().Issue(user.ID, session.ID, models.DefaultTenantID, role, email)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return user.ID, token, nil
+}
+
+// Request sends a REST request to /api/v1 path with body encoded as JSON and
+// token as the bearer token, and decodes the response envelope
+func (e *Env) Request(method, path string, body interface{}, token string) (*Response, error) {
+	if body == nil {
+		return e.Upload(method, path, "", nil, token)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return e.Upload(method, path, "application/json", data, token)
+}
+
+// Upload sends a REST request to /api/v1 path with body as content of type
+// contentType, such as an image or a document, and token as the bearer token,
+// and decodes the response envelope
+func (e *Env) Upload(method, path, contentType string, body []byte, token string) (*Response, error) {
+	req := httptest.NewRequest(method, "/api/v1"+path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := e.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{StatusCode: resp.StatusCode, Header: resp.Header}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decode %s %s response (%s): %w", method, path, resp.Status, err)
+	}
+	return result, nil
+}
+
+// Do sends a raw HTTP request to the REST API
+func (e *Env) Do(req *http.Request) (*http.Response, error) {
+	return e.app.Test(req, -1)
+}
+
+// Decode decodes a response's data into v
+func (r *Response) Decode(v interface{}) error {
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// Books returns a gRPC book service client
+func (e *Env) Books() pb.BookServiceClient {
+	return pb.NewBookServiceClient(e.conn)
+}
+
+// Authors returns a gRPC author service client
+func (e *Env) Authors() pb.AuthorServiceClient {
+	return pb.NewAuthorServiceClient(e.conn)
+}
+
+// Categories returns a gRPC category service client
+func (e *Env) Categories() pb.CategoryServiceClient {
+	return pb.NewCategoryServiceClient(e.conn)
+}
+
+// moduleRoot returns the directory holding go.mod, searching up from the
+// working directory
+func moduleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
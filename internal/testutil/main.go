@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// setupTimeout bounds starting PostgreSQL and migrating it for a test binary
+const setupTimeout = 5 * time.Minute
+
+// Main runs the tests of a package against an Env, setting env to it before
+// the tests run and closing it after. Without a Docker daemon the tests are
+// skipped rather than failed, so go test ./... passes on machines without
+// Docker. It returns the exit code for TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testutil.Main(m, &env))
+//	}
+func Main(m *testing.M, env **Env) int {
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	if err := DockerAvailable(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "skipping integration tests: %v\n", err)
+		return 0
+	}
+	e, err := Setup(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to set up test environment: %v\n", err)
+		return 1
+	}
+	*env = e
+
+	code := m.Run()
+	if err := e.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return code
+}
+
+// DockerAvailable returns an error if the docker CLI is missing or cannot
+// reach a daemon
+func DockerAvailable(ctx context.Context) error {
+	if _, err := docker(ctx, "info", "--format", "{{.ServerVersion}}"); err != nil {
+		return fmt.Errorf("docker is not available: %w", err)
+	}
+	return nil
+}
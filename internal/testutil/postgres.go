@@ -0,0 +1,147 @@
+// Package testutil runs the API end to end for integration tests: it starts a
+// throwaway PostgreSQL container, migrates it, and serves the REST and gRPC
+// APIs in-process.
+//
+// Containers are started with the docker CLI, so the only requirement is a
+// running Docker daemon. One container per test binary does not need
+// testcontainers-go and the dependencies it would add to the module. Set
+// TEST_POSTGRES_IMAGE to use another image.
+package testutil
+
+import (
+	"bookstore-api/internal/config"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	defaultPostgresImage = "postgres:16-alpine"
+	postgresUser         = "bookstore"
+	postgresPassword     = "bookstore"
+	postgresDB           = "bookstore_test"
+	// postgresStartTimeout bounds how long a container may take to accept connections
+	postgresStartTimeout = time.Minute
+)
+
+// Postgres is a PostgreSQL container
+type Postgres struct {
+	ContainerID string
+	Host        string
+	Port        string
+}
+
+// StartPostgres starts a PostgreSQL container and waits until it accepts
+// connections. The container is removed by Terminate.
+func StartPostgres(ctx context.Context) (*Postgres, error) {
+	image := os.Getenv("TEST_POSTGRES_IMAGE")
+	if image == "" {
+		image = defaultPostgresImage
+	}
+
+	output, err := docker(ctx, "run", "--detach", "--rm",
+		"--env", "POSTGRES_USER="+postgresUser,
+		"--env", "POSTGRES_PASSWORD="+postgresPassword,
+		"--env", "POSTGRES_DB="+postgresDB,
+		"--publish", "127.0.0.1::5432",
+		image,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start postgres container: %w", err)
+	}
+	pg := &Postgres{ContainerID: output, Host: "127.0.0.1"}
+
+	// The published port is chosen by Docker
+	output, err = docker(ctx, "port", pg.ContainerID, "5432/tcp")
+	if err != nil {
+		pg.Terminate()
+		return nil, fmt.Errorf("failed to get postgres port: %w", err)
+	}
+	_, port, err := net.SplitHostPort(strings.SplitN(output, "\n", 2)[0])
+	if err != nil {
+		pg.Terminate()
+		return nil, fmt.Errorf("failed to parse postgres port %q: %w", output, err)
+	}
+	pg.Port = port
+
+	if err := pg.wait(ctx); err != nil {
+		pg.Terminate()
+		return nil, err
+	}
+	return pg, nil
+}
+
+// Configure points cfg's database settings at the container
+func (p *Postgres) Configure(cfg *config.Config) {
+	cfg.Database.Host = p.Host
+	cfg.Database.Port = p.Port
+	cfg.Database.User = postgresUser
+	cfg.Database.Password = postgresPassword
+	cfg.Database.DBName = postgresDB
+	cfg.Database.SSLMode = "disable"
+}
+
+// Terminate stops and removes the container
+func (p *Postgres) Terminate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := docker(ctx, "rm", "--force", "--volumes", p.ContainerID); err != nil {
+		return fmt.Errorf("failed to remove postgres container: %w", err)
+	}
+	return nil
+}
+
+// wait polls the container until PostgreSQL accepts connections. The image
+// initializes the database on a server listening only on a local socket, so
+// the first TCP connection reaches the final server.
+func (p *Postgres) wait(ctx context.Context) error {
+	cfg := &config.Config{}
+	p.Configure(cfg)
+
+	ctx, cancel := context.WithTimeout(ctx, postgresStartTimeout)
+	defer cancel()
+
+	for {
+		if err := ping(ctx, cfg.GetDSN()); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("postgres container did not become ready: %w", ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// ping opens a connection to dsn and pings the server
+func ping(ctx context.Context, dsn string) error {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	return sqlDB.PingContext(ctx)
+}
+
+// docker runs a docker CLI command and returns its trimmed output
+func docker(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,77 @@
+// Package timestamps formats and parses the timestamps the REST and gRPC APIs
+// exchange. Timestamps always leave the service in UTC as RFC 3339.
+//
+// Publication dates are dates without a time of day. When one is supplied as
+// a full timestamp, its date in the configured publication time zone, UTC by
+// default, is kept, so an instant late on a date in the publisher's zone is
+// not read as the next day.
+package timestamps
+
+import (
+	"bookstore-api/internal/config"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DateLayout is the layout of date-only values
+const DateLayout = "2006-01-02"
+
+var (
+	publicationZone = time.UTC
+	once            sync.Once
+)
+
+// Initialize makes UTC the process's local time zone, so times read from the
+// database, which the driver returns in local time, and times taken from the
+// clock are UTC wherever they are serialized, and loads the publication time
+// zone
+func Initialize(cfg *config.Config) error {
+	var err error
+	once.Do(func() {
+		time.Local = time.UTC
+
+		var zone *time.Location
+		zone, err = time.LoadLocation(cfg.Timestamps.PublicationTimezone)
+		if err != nil {
+			err = fmt.Errorf("invalid publication time zone %q: %w", cfg.Timestamps.PublicationTimezone, err)
+			return
+		}
+		publicationZone = zone
+	})
+	return err
+}
+
+// PublicationZone returns the time zone publication timestamps are read in
+func PublicationZone() *time.Location {
+	return publicationZone
+}
+
+// Format formats t in UTC as RFC 3339
+func Format(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Parse parses an RFC 3339 timestamp and returns it in UTC
+func Parse(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// ParsePublication parses a publication date given as a date or an RFC 3339
+// timestamp and returns the date at midnight UTC. Timestamps contribute their
+// date in the publication time zone.
+func ParsePublication(value string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := t.In(publicationZone).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+}
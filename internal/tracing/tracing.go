@@ -0,0 +1,189 @@
+// Package tracing follows each REST request and gRPC call through the
+// service. A call gets a span, continuing the trace of the W3C traceparent
+// header the caller sent, if any, and the span carries the trace through the
+// request context so the work done for the call, such as database statements,
+// is recorded on it and logged with its trace ID.
+package tracing
+
+import (
+	"bookstore-api/internal/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header is the W3C trace context header spans are read from and written to
+const Header = "traceparent"
+
+// maxEvents caps the events kept on a span, so a call running many
+// statements does not hold all of them in memory
+const maxEvents = 256
+
+// Event is something that happened during a span, such as a statement run
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// Span is the work done for one call
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Name     string
+	Start    time.Time
+
+	mu      sync.Mutex
+	events  []Event
+	dropped int
+}
+
+type spanKey struct{}
+
+// Start starts a span named name, continuing the trace of the traceparent
+// header value when it is valid and starting a new trace otherwise, and
+// returns a context carrying it
+func Start(ctx context.Context, name, traceparent string) (context.Context, *Span) {
+	span := &Span{
+		SpanID: randomHex(8),
+		Name:   name,
+		Start:  time.Now(),
+	}
+	if traceID, parentID, ok := parse(traceparent); ok {
+		span.TraceID = traceID
+		span.ParentID = parentID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext returns the span of ctx, or nil when ctx is not traced
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// AddEvent records an event on the span
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.events) >= maxEvents {
+		s.dropped++
+		return
+	}
+	s.events = append(s.events, Event{Name: name, Time: time.Now(), Attributes: attributes})
+}
+
+// Events returns the events recorded on the span
+func (s *Span) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Traceparent returns the traceparent header value identifying the span, for
+// responses and outgoing calls
+func (s *Span) Traceparent() string {
+	return "00-" + s.TraceID + "-" + s.SpanID + "-01"
+}
+
+// Traceparent returns the traceparent of the span of ctx, or "" when ctx is
+// not traced. Work done later for the call, such as webhook deliveries and
+// background jobs, stores it to continue the call's trace.
+func Traceparent(ctx context.Context) string {
+	if span := FromContext(ctx); span != nil {
+		return span.Traceparent()
+	}
+	return ""
+}
+
+// TraceID returns the trace ID of the span of ctx, or "" when ctx is not
+// traced
+func TraceID(ctx context.Context) string {
+	if span := FromContext(ctx); span != nil {
+		return span.TraceID
+	}
+	return ""
+}
+
+// Inject sets the traceparent header of an outgoing HTTP request to the span
+// of ctx, so the service called can continue the trace
+func Inject(ctx context.Context, header http.Header) {
+	if traceparent := Traceparent(ctx); traceparent != "" {
+		header.Set(Header, traceparent)
+	}
+}
+
+// LogAttrs returns the attributes identifying the span in log lines
+func (s *Span) LogAttrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("trace_id", s.TraceID),
+		slog.String("span_id", s.SpanID),
+	}
+}
+
+// End logs the span at debug level with the number of events recorded on it
+func (s *Span) End(ctx context.Context, attributes ...slog.Attr) {
+	s.mu.Lock()
+	events, dropped := len(s.events), s.dropped
+	s.mu.Unlock()
+
+	attrs := append(s.LogAttrs(),
+		slog.String("name", s.Name),
+		slog.Duration("duration", time.Since(s.Start)),
+		slog.Int("events", events+dropped),
+	)
+	if s.ParentID != "" {
+		attrs = append(attrs, slog.String("parent_span_id", s.ParentID))
+	}
+	if dropped > 0 {
+		attrs = append(attrs, slog.Int("events_dropped", dropped))
+	}
+	utils.Slog().LogAttrs(ctx, slog.LevelDebug, "Span ended", append(attrs, attributes...)...)
+}
+
+// parse reads the trace and parent span IDs of a version 00 traceparent
+// header value
+func parse(traceparent string) (traceID, parentID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[3]) != 2 {
+		return "", "", false
+	}
+	traceID, parentID = strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHexID(traceID, 32) || !isHexID(parentID, 16) {
+		return "", "", false
+	}
+	return traceID, parentID, true
+}
+
+// isHexID reports whether id is length lower-case hex digits, not all zero
+func isHexID(id string, length int) bool {
+	if len(id) != length {
+		return false
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return false
+	}
+	return strings.Trim(id, "0") != ""
+}
+
+// randomHex returns n random bytes in hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic("tracing: failed to generate an ID: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,83 @@
+package utils
+
+import "strings"
+
+// NormalizeISBN strips hyphens and spaces from an ISBN and upper-cases an ISBN-10 check digit
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn)))
+}
+
+// CanonicalISBN normalizes an ISBN-10 or ISBN-13 and returns it as an ISBN-13.
+// It returns false if the input is not a valid ISBN.
+func CanonicalISBN(isbn string) (string, bool) {
+	isbn = NormalizeISBN(isbn)
+	switch {
+	case IsValidISBN13(isbn):
+		return isbn, true
+	case IsValidISBN10(isbn):
+		return ISBN10To13(isbn), true
+	default:
+		return "", false
+	}
+}
+
+// IsValidISBN13 reports whether a normalized ISBN-13 has a 978/979 prefix and a valid check digit
+func IsValidISBN13(isbn string) bool {
+	if len(isbn) != 13 || !allDigits(isbn) {
+		return false
+	}
+	if !strings.HasPrefix(isbn, "978") && !strings.HasPrefix(isbn, "979") {
+		return false
+	}
+	return isbn13CheckDigit(isbn[:12]) == isbn[12]
+}
+
+// IsValidISBN10 reports whether a normalized ISBN-10 has a valid check digit
+func IsValidISBN10(isbn string) bool {
+	if len(isbn) != 10 || !allDigits(isbn[:9]) {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(isbn[i]-'0') * (10 - i)
+	}
+	switch check := isbn[9]; {
+	case check == 'X':
+		sum += 10
+	case check >= '0' && check <= '9':
+		sum += int(check - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// ISBN10To13 converts a valid, normalized ISBN-10 to its ISBN-13 form
+func ISBN10To13(isbn string) string {
+	prefix := "978" + isbn[:9]
+	return prefix + string(isbn13CheckDigit(prefix))
+}
+
+// isbn13CheckDigit computes the check digit for the first 12 digits of an ISBN-13
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// allDigits reports whether s contains only ASCII digits
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
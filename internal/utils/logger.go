@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -17,37 +19,97 @@ const (
 	ERROR
 )
 
-var currentLogLevel LogLevel = INFO
+// logLevelNames are the names of the log levels, as configured and reported
+var logLevelNames = map[LogLevel]string{
+	DEBUG: "debug",
+	INFO:  "info",
+	WARN:  "warn",
+	ERROR: "error",
+}
+
+// slogLevels are the slog levels of the log levels
+var slogLevels = map[LogLevel]slog.Level{
+	DEBUG: slog.LevelDebug,
+	INFO:  slog.LevelInfo,
+	WARN:  slog.LevelWarn,
+	ERROR: slog.LevelError,
+}
+
+var (
+	// logLevel is the current level of both these functions and the slog
+	// logger; it may change at runtime, so it is read atomically
+	logLevel = new(slog.LevelVar)
+	slogger  = slog.New(slog.NewTextHandler(log.Writer(), &slog.HandlerOptions{Level: logLevel}))
+)
+
+// String returns the name of the level
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// ParseLogLevel parses a level name: debug, info, warn, or error
+func ParseLogLevel(name string) (LogLevel, error) {
+	for level, levelName := range logLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return INFO, fmt.Errorf("invalid log level %q: expected debug, info, warn, or error", name)
+}
 
-// SetLogLevel sets the current log level
+// SetLogLevel sets the current log level, which takes effect immediately for
+// these functions and the slog logger
 func SetLogLevel(level LogLevel) {
-	currentLogLevel = level
+	logLevel.Set(slogLevels[level])
+}
+
+// GetLogLevel returns the current log level
+func GetLogLevel() LogLevel {
+	for level, slogLevel := range slogLevels {
+		if slogLevel == logLevel.Level() {
+			return level
+		}
+	}
+	return INFO
+}
+
+// Slog returns the structured logger, which logs at the current log level
+func Slog() *slog.Logger {
+	return slogger
+}
+
+// enabled reports whether messages of the level are logged
+func enabled(level LogLevel) bool {
+	return slogLevels[level] >= logLevel.Level()
 }
 
 // LogInfo logs an info message
 func LogInfo(message string, data interface{}) {
-	if currentLogLevel <= INFO {
+	if enabled(INFO) {
 		logMessage("INFO", message, data)
 	}
 }
 
 // LogError logs an error message
 func LogError(message string, data interface{}) {
-	if currentLogLevel <= ERROR {
+	if enabled(ERROR) {
 		logMessage("ERROR", message, data)
 	}
 }
 
 // LogWarn logs a warning message
 func LogWarn(message string, data interface{}) {
-	if currentLogLevel <= WARN {
+	if enabled(WARN) {
 		logMessage("WARN", message, data)
 	}
 }
 
 // LogDebug logs a debug message
 func LogDebug(message string, data interface{}) {
-	if currentLogLevel <= DEBUG {
+	if enabled(DEBUG) {
 		logMessage("DEBUG", message, data)
 	}
 }
@@ -1,49 +1,233 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
-var validate *validator.Validate
+var (
+	validate *validator.Validate
+
+	messagesMu sync.RWMutex
+	// messages are the formats of failure messages by rule tag, with the
+	// field as %[1]s and the rule's parameter as %[2]s
+	messages = map[string]string{
+		"required": "%[1]s is required",
+		"min":      "%[1]s must be at least %[2]s characters long",
+		"max":      "%[1]s must be at most %[2]s characters long",
+		"email":    "%[1]s must be a valid email address",
+		"len":      "%[1]s must be exactly %[2]s characters long",
+		"uuid":     "%[1]s must be a valid UUID",
+		"gt":       "%[1]s must be greater than %[2]s",
+		"lt":       "%[1]s must be less than %[2]s",
+		"oneof":    "%[1]s must be one of: %[2]s",
+		"gtfield":  "%[1]s must be after %[2]s",
+		"isbn13":   "%[1]s must be a valid ISBN-13",
+	}
+	// numberMessages replace messages about lengths for numeric fields
+	numberMessages = map[string]string{
+		"min": "%[1]s must be at least %[2]s",
+		"max": "%[1]s must be at most %[2]s",
+	}
+)
+
+// FieldError describes a field that failed a validation rule, for clients to
+// point at the field in their forms
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+}
+
+// ValidationErrors are the fields of a struct that failed validation
+type ValidationErrors []FieldError
+
+// Error joins the messages of the failed fields
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// FieldErrors returns the failed fields of err, which may wrap a validation
+// error. ok is false for other errors.
+func FieldErrors(err error) (fields ValidationErrors, ok bool) {
+	ok = errors.As(err, &fields)
+	return fields, ok
+}
 
 func init() {
 	validate = validator.New()
+	// Fields are named as clients send them, falling back to the Go name of
+	// fields without a JSON name
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	RegisterValidation("isbn", validateISBN, "%[1]s must be a valid ISBN-10 or ISBN-13")
+	RegisterValidation("currency", validateCurrency, "%[1]s must be an ISO 4217 currency code")
+	RegisterValidation("max_years_ahead", validateMaxYearsAhead, "%[1]s must be at most %[2]s years in the future")
+}
+
+// RegisterValidation registers a field rule under tag, replacing any rule
+// with the same tag, and the format of its failure messages, with the field
+// as %[1]s and the rule's parameter as %[2]s. Rules are registered
+// once, from package init functions, and apply to every struct validated,
+// whether it came from a REST or gRPC request.
+func RegisterValidation(tag string, fn validator.Func, message string) {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("utils: failed to register validation %q: %v", tag, err))
+	}
+	RegisterMessage(tag, message)
+}
+
+// RegisterStructRule registers a rule checking whole structs of the given
+// types, for constraints between fields. The rule reports each failure with
+// StructLevel.ReportError, whose tag names the message to use.
+func RegisterStructRule(fn validator.StructLevelFunc, types ...interface{}) {
+	validate.RegisterStructValidation(fn, types...)
+}
+
+// RegisterMessage sets the format of failure messages of the rule tag, with
+// the field as %[1]s and the rule's parameter as %[2]s
+func RegisterMessage(tag string, message string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages[tag] = message
 }
 
-// validateStruct validates a struct using the validator package
+// ValidateStruct validates a struct using the validator package
 func ValidateStruct(s interface{}) error {
-	if err := validate.Struct(s); err != nil {
-		var validationErrors []string
-		for _, err := range err.(validator.ValidationErrors) {
-			field := err.Field()
-			tag := err.Tag()
-			param := err.Param()
-			
-			var message string
-			switch tag {
-			case "required":
-				message = fmt.Sprintf("%s is required", field)
-			case "min":
-				message = fmt.Sprintf("%s must be at least %s characters long", field, param)
-			case "max":
-				message = fmt.Sprintf("%s must be at most %s characters long", field, param)
-			case "email":
-				message = fmt.Sprintf("%s must be a valid email address", field)
-			case "len":
-				message = fmt.Sprintf("%s must be exactly %s characters long", field, param)
-			case "uuid":
-				message = fmt.Sprintf("%s must be a valid UUID", field)
-			default:
-				message = fmt.Sprintf("%s is invalid", field)
+	return validationError(validate.Struct(s))
+}
+
+// ValidateFields validates only the named fields of a struct, for partial
+// updates
+func ValidateFields(s interface{}, fields ...string) error {
+	return validationError(validate.StructPartial(s, fields...))
+}
+
+// validationError describes the rules that failed
+func validationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	validationErrors := make(ValidationErrors, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		message, ok := messages[fieldError.Tag()]
+		if !ok {
+			message = "%[1]s is invalid"
+		}
+		if numberMessage, isNumber := numberMessages[fieldError.Tag()]; isNumber && isNumeric(fieldError.Kind()) {
+			message = numberMessage
+		}
+		validationErrors = append(validationErrors, FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Message: fmt.Sprintf(message, fieldError.Field(), fieldError.Param()),
+			Param:   fieldError.Param(),
+		})
+	}
+	return validationErrors
+}
+
+// isNumeric reports whether values of kind are numbers
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// validateISBN accepts an ISBN-10 or ISBN-13, with or without hyphens, with a
+// valid check digit
+func validateISBN(fl validator.FieldLevel) bool {
+	_, ok := CanonicalISBN(fl.Field().String())
+	return ok
+}
+
+// validateCurrency accepts an upper-case ISO 4217 currency code
+func validateCurrency(fl validator.FieldLevel) bool {
+	return currencyCodes[fl.Field().String()]
+}
+
+// validateMaxYearsAhead accepts times, and types embedding time.Time such as
+// dates, no more than the parameter's number of years after now
+func validateMaxYearsAhead(fl validator.FieldLevel) bool {
+	years, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("utils: invalid max_years_ahead parameter %q", fl.Param()))
+	}
+	t, ok := timeValue(fl.Field())
+	if !ok {
+		return false
+	}
+	return !t.After(time.Now().AddDate(years, 0, 0))
+}
+
+// timeValue returns the time held by a time.Time field or a struct embedding
+// one
+func timeValue(field reflect.Value) (time.Time, bool) {
+	if t, ok := field.Interface().(time.Time); ok {
+		return t, true
+	}
+	if field.Kind() == reflect.Struct {
+		if embedded := field.FieldByName("Time"); embedded.IsValid() {
+			if t, ok := embedded.Interface().(time.Time); ok {
+				return t, true
 			}
-			validationErrors = append(validationErrors, message)
 		}
-		return fmt.Errorf(strings.Join(validationErrors, "; "))
 	}
-	return nil
+	return time.Time{}, false
+}
+
+// currencyCodes are the active ISO 4217 currency codes
+var currencyCodes = map[string]bool{
+	"AED": true, "AFN": true, "ALL": true, "AMD": true, "ANG": true, "AOA": true, "ARS": true, "AUD": true,
+	"AWG": true, "AZN": true, "BAM": true, "BBD": true, "BDT": true, "BGN": true, "BHD": true, "BIF": true,
+	"BMD": true, "BND": true, "BOB": true, "BRL": true, "BSD": true, "BTN": true, "BWP": true, "BYN": true,
+	"BZD": true, "CAD": true, "CDF": true, "CHF": true, "CLP": true, "CNY": true, "COP": true, "CRC": true,
+	"CUP": true, "CVE": true, "CZK": true, "DJF": true, "DKK": true, "DOP": true, "DZD": true, "EGP": true,
+	"ERN": true, "ETB": true, "EUR": true, "FJD": true, "FKP": true, "GBP": true, "GEL": true, "GHS": true,
+	"GIP": true, "GMD": true, "GNF": true, "GTQ": true, "GYD": true, "HKD": true, "HNL": true, "HTG": true,
+	"HUF": true, "IDR": true, "ILS": true, "INR": true, "IQD": true, "IRR": true, "ISK": true, "JMD": true,
+	"JOD": true, "JPY": true, "KES": true, "KGS": true, "KHR": true, "KMF": true, "KPW": true, "KRW": true,
+	"KWD": true, "KYD": true, "KZT": true, "LAK": true, "LBP": true, "LKR": true, "LRD": true, "LSL": true,
+	"LYD": true, "MAD": true, "MDL": true, "MGA": true, "MKD": true, "MMK": true, "MNT": true, "MOP": true,
+	"MRU": true, "MUR": true, "MVR": true, "MWK": true, "MXN": true, "MYR": true, "MZN": true, "NAD": true,
+	"NGN": true, "NIO": true, "NOK": true, "NPR": true, "NZD": true, "OMR": true, "PAB": true, "PEN": true,
+	"PGK": true, "PHP": true, "PKR": true, "PLN": true, "PYG": true, "QAR": true, "RON": true, "RSD": true,
+	"RUB": true, "RWF": true, "SAR": true, "SBD": true, "SCR": true, "SDG": true, "SEK": true, "SGD": true,
+	"SHP": true, "SLE": true, "SOS": true, "SRD": true, "SSP": true, "STN": true, "SVC": true, "SYP": true,
+	"SZL": true, "THB": true, "TJS": true, "TMT": true, "TND": true, "TOP": true, "TRY": true, "TTD": true,
+	"TWD": true, "TZS": true, "UAH": true, "UGX": true, "USD": true, "UYU": true, "UZS": true, "VES": true,
+	"VND": true, "VUV": true, "WST": true, "XAF": true, "XCD": true, "XOF": true, "XPF": true, "YER": true,
+	"ZAR": true, "ZMW": true, "ZWL": true,
 }
 
 // validateStruct is a wrapper for the exported ValidateStruct function
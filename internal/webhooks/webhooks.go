@@ -0,0 +1,209 @@
+package webhooks
+
+import (
+	"bookstore-api/internal/events"
+	"bookstore-api/internal/jobs"
+	"bookstore-api/internal/models"
+	"bookstore-api/internal/services"
+	"bookstore-api/internal/tenancy"
+	"bookstore-api/internal/tracing"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobType is the background job type that delivers a single webhook
+const JobType = "webhook.deliver"
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Bookstore-Signature"
+	TimestampHeader = "X-Bookstore-Timestamp"
+	EventHeader     = "X-Bookstore-Event"
+	DeliveryHeader  = "X-Bookstore-Delivery"
+)
+
+// maxResponseBody is the number of response bytes kept in the delivery log
+const maxResponseBody = 4096
+
+// deliverPayload is the payload of webhook delivery jobs
+type deliverPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// Sign returns the signature header value for a delivery: "sha256=" followed by
+// the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Consumer fans domain events out to subscribed webhooks by scheduling a delivery job per webhook
+type Consumer struct {
+	webhookService *services.WebhookService
+	queue          *jobs.Queue
+}
+
+// NewConsumer creates a new webhook event consumer
+func NewConsumer(queue *jobs.Queue, svc *services.Services) *Consumer {
+	return &Consumer{
+		webhookService: svc.Webhooks,
+		queue:          queue,
+	}
+}
+
+// Consume schedules deliveries of the event to every subscribed webhook of the
+// event's tenant
+func (c *Consumer) Consume(ctx context.Context, event *models.OutboxEvent) error {
+	webhookService := c.webhookService.WithContext(tenancy.WithTenantID(ctx, event.TenantID))
+	webhooks, err := webhookService.GetSubscribedWebhooks(event.EventType)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		delivery := &models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			EventType: event.EventType,
+			Payload:   string(payload),
+			Status:    models.DeliveryStatusPending,
+		}
+		if _, err := webhookService.CreateDelivery(delivery, func(tx *gorm.DB) error {
+			_, err := c.queue.EnqueueTx(tx, JobType, deliverPayload{DeliveryID: delivery.ID})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliverer is the job handler that sends a webhook delivery and logs the attempt.
+// Failed attempts are returned as errors so the job queue retries them with backoff.
+type Deliverer struct {
+	webhookService *services.WebhookService
+	client         *http.Client
+}
+
+// NewDeliverer creates a new webhook deliverer
+func NewDeliverer(svc *services.Services, timeout time.Duration) *Deliverer {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Deliverer{
+		webhookService: svc.Webhooks,
+		client:         &http.Client{Timeout: timeout},
+	}
+}
+
+// Handle delivers the webhook referenced by the job. Jobs are not tenant
+// scoped, so the delivery is looked up across tenants by its ID.
+func (d *Deliverer) Handle(ctx context.Context, job *models.Job) error {
+	var payload deliverPayload
+	if err := job.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("invalid webhook job payload: %w", err)
+	}
+	webhookService := d.webhookService.WithContext(tenancy.WithSystem(ctx))
+
+	delivery, err := webhookService.GetDeliveryByID(payload.DeliveryID)
+	if err != nil {
+		if err.Error() == "delivery not found" {
+			return nil
+		}
+		return err
+	}
+	if delivery.Status == models.DeliveryStatusSucceeded {
+		return nil
+	}
+
+	webhook, err := webhookService.GetWebhookByID(delivery.WebhookID)
+	if err != nil && err.Error() != "webhook not found" {
+		return err
+	}
+	if webhook == nil || !webhook.Active {
+		return webhookService.UpdateDelivery(delivery.ID, map[string]interface{}{
+			"status": models.DeliveryStatusFailed,
+			"error":  "webhook deleted or inactive",
+		})
+	}
+
+	start := time.Now()
+	statusCode, body, sendErr := d.send(ctx, webhook, delivery)
+	updates := map[string]interface{}{
+		"attempts":        job.Attempts,
+		"response_status": statusCode,
+		"response_body":   body,
+		"duration_ms":     time.Since(start).Milliseconds(),
+	}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.DeliveryStatusSucceeded
+		updates["delivered_at"] = time.Now()
+		updates["error"] = ""
+	case job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.DeliveryStatusFailed
+		updates["error"] = sendErr.Error()
+	default:
+		updates["error"] = sendErr.Error()
+	}
+
+	if err := webhookService.UpdateDelivery(delivery.ID, updates); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// send posts the signed payload, with the traceparent of the request behind
+// the event, and returns the response status and truncated body
+func (d *Deliverer) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Bookstore-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
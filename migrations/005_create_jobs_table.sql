@@ -0,0 +1,29 @@
+-- Create jobs table
+-- Persistent queue for background work processed by the worker pool
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    last_error TEXT,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_jobs_status CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- Create indexes for claiming due jobs and filtering by type
+CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at ON jobs(status, run_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs(type);
+
+-- Create trigger to automatically update updated_at
+CREATE TRIGGER update_jobs_updated_at 
+    BEFORE UPDATE ON jobs 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,27 @@
+-- Create publishers table and link books to their publisher
+-- Publishers are populated by ONIX feed imports
+
+CREATE TABLE IF NOT EXISTS publishers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT uni_publishers_name UNIQUE (name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_publishers_deleted_at ON publishers(deleted_at);
+
+CREATE TRIGGER update_publishers_updated_at 
+    BEFORE UPDATE ON publishers 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS publisher_id UUID 
+    CONSTRAINT fk_books_publisher 
+    REFERENCES publishers(id) 
+    ON UPDATE CASCADE 
+    ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_books_publisher_id ON books(publisher_id);
@@ -0,0 +1,29 @@
+-- Create report definitions table
+-- Each definition is a recurring report generated by a background job and emailed to its recipients
+
+CREATE TABLE IF NOT EXISTS report_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    report_type VARCHAR(50) NOT NULL,
+    format VARCHAR(10) NOT NULL DEFAULT 'csv',
+    period VARCHAR(10) NOT NULL DEFAULT 'week',
+    schedule VARCHAR(100) NOT NULL,
+    recipients JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_status VARCHAR(20),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Due enabled reports are found by the dispatcher on every tick
+CREATE INDEX IF NOT EXISTS idx_report_definitions_due ON report_definitions(next_run_at) WHERE enabled AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_report_definitions_deleted_at ON report_definitions(deleted_at);
+
+CREATE TRIGGER update_report_definitions_updated_at 
+    BEFORE UPDATE ON report_definitions 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
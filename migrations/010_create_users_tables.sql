@@ -0,0 +1,47 @@
+-- Create users and external identities tables
+-- Users sign in through OAuth2/OIDC providers; each provider account is an identity linked to one user
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255),
+    name VARCHAR(255),
+    avatar_url VARCHAR(2048),
+    role VARCHAR(20) NOT NULL DEFAULT 'customer',
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Emails are optional (some providers hide them) but unique among active users
+CREATE UNIQUE INDEX IF NOT EXISTS uni_users_email ON users(LOWER(email)) WHERE email IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+
+CREATE TRIGGER update_users_updated_at 
+    BEFORE UPDATE ON users 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uni_user_identities_provider_subject UNIQUE (provider, subject),
+    CONSTRAINT fk_user_identities_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+CREATE TRIGGER update_user_identities_updated_at 
+    BEFORE UPDATE ON user_identities 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,11 @@
+-- Link authors to the user accounts that claimed them
+-- An owning user may edit the author's profile and their books' descriptions
+
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS user_id UUID 
+    CONSTRAINT fk_authors_user 
+    REFERENCES users(id) 
+    ON UPDATE CASCADE 
+    ON DELETE SET NULL;
+
+-- A user can own at most one author profile
+CREATE UNIQUE INDEX IF NOT EXISTS uni_authors_user_id ON authors(user_id);
@@ -0,0 +1,145 @@
+-- Create tenants table and scope every entity to a tenant
+-- Existing rows are assigned to the default tenant; unique constraints become per-tenant
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug VARCHAR(63) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    domain VARCHAR(255),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    rate_limit INTEGER NOT NULL DEFAULT 0,
+    strict_rate_limit INTEGER NOT NULL DEFAULT 0,
+    low_stock_threshold INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT uni_tenants_slug UNIQUE (slug),
+    CONSTRAINT uni_tenants_domain UNIQUE (domain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants(deleted_at);
+
+CREATE TRIGGER update_tenants_updated_at 
+    BEFORE UPDATE ON tenants 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO tenants (id, slug, name) 
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Default Storefront')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_authors_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE authors ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_authors_tenant_id ON authors(tenant_id);
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_categories_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE categories ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_categories_tenant_id ON categories(tenant_id);
+
+ALTER TABLE publishers ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_publishers_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE publishers ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_publishers_tenant_id ON publishers(tenant_id);
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_books_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE books ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_books_tenant_id ON books(tenant_id);
+
+ALTER TABLE book_ratings ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_book_ratings_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE book_ratings ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_book_ratings_tenant_id ON book_ratings(tenant_id);
+
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_outbox_events_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE outbox_events ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_tenant_id ON outbox_events(tenant_id);
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_webhooks_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE webhooks ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks(tenant_id);
+
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_webhook_deliveries_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE webhook_deliveries ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant_id ON webhook_deliveries(tenant_id);
+
+ALTER TABLE report_definitions ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_report_definitions_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE report_definitions ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_report_definitions_tenant_id ON report_definitions(tenant_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_users_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id);
+
+ALTER TABLE user_identities ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_user_identities_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE user_identities ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_user_identities_tenant_id ON user_identities(tenant_id);
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' 
+    CONSTRAINT fk_sessions_tenant 
+    REFERENCES tenants(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+ALTER TABLE sessions ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_sessions_tenant_id ON sessions(tenant_id);
+
+-- Uniqueness is per storefront
+ALTER TABLE authors DROP CONSTRAINT IF EXISTS authors_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_authors_tenant_email ON authors(tenant_id, email);
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_categories_tenant_name ON categories(tenant_id, name);
+
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_isbn_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_books_tenant_isbn ON books(tenant_id, isbn);
+
+ALTER TABLE publishers DROP CONSTRAINT IF EXISTS uni_publishers_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_publishers_tenant_name ON publishers(tenant_id, name);
+
+DROP INDEX IF EXISTS uni_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_users_tenant_email ON users(tenant_id, LOWER(email)) WHERE email IS NOT NULL AND deleted_at IS NULL;
+
+ALTER TABLE user_identities DROP CONSTRAINT IF EXISTS uni_user_identities_provider_subject;
+ALTER TABLE user_identities ADD CONSTRAINT uni_user_identities_provider_subject UNIQUE (tenant_id, provider, subject);
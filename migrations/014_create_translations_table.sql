@@ -0,0 +1,29 @@
+-- Create translations table
+-- Holds per-locale values of translatable fields (book title/description, category name/description).
+-- entity_id references books or categories depending on entity_type, so it has no foreign key.
+
+CREATE TABLE IF NOT EXISTS translations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    value TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_translations_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT uni_translations_entity_locale_field UNIQUE (tenant_id, entity_type, entity_id, locale, field)
+);
+
+CREATE INDEX IF NOT EXISTS idx_translations_entity ON translations(entity_id, locale);
+
+CREATE TRIGGER update_translations_updated_at 
+    BEFORE UPDATE ON translations 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,32 @@
+-- Create price history table
+-- Append-only log of every price a book has had, used for pricing audits and
+-- the "lowest price in the last 30 days" disclosure required for EU price reductions
+
+CREATE TABLE IF NOT EXISTS price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    price DECIMAL(10,2) NOT NULL,
+    previous_price DECIMAL(10,2),
+    source VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_price_history_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_price_history_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_book_changed_at ON price_history(book_id, changed_at);
+
+-- Seed the history with each existing book's current price
+INSERT INTO price_history (tenant_id, book_id, price, source, changed_at)
+SELECT tenant_id, id, price, 'initial', created_at
+FROM books
+WHERE NOT EXISTS (SELECT 1 FROM price_history WHERE price_history.book_id = books.id);
@@ -0,0 +1,50 @@
+-- Create promotions table
+-- Scheduled discounts on a single book or on every book in a category. A promotion
+-- sets either a fixed promotional price (book promotions only) or a percentage off.
+-- active is maintained by the promotion sync task as promotions start and end.
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    book_id UUID,
+    category_id UUID,
+    price DECIMAL(10,2),
+    discount_percent DECIMAL(5,2),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_promotions_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_promotions_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT fk_promotions_category 
+        FOREIGN KEY (category_id) 
+        REFERENCES categories(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT chk_promotions_target CHECK ((book_id IS NULL) <> (category_id IS NULL)),
+    CONSTRAINT chk_promotions_discount CHECK ((price IS NULL) <> (discount_percent IS NULL)),
+    CONSTRAINT chk_promotions_price CHECK (price IS NULL OR (price >= 0 AND book_id IS NOT NULL)),
+    CONSTRAINT chk_promotions_discount_percent CHECK (discount_percent IS NULL OR (discount_percent > 0 AND discount_percent < 100)),
+    CONSTRAINT chk_promotions_schedule CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_book_id ON promotions(book_id);
+CREATE INDEX IF NOT EXISTS idx_promotions_category_id ON promotions(category_id);
+CREATE INDEX IF NOT EXISTS idx_promotions_active ON promotions(active);
+CREATE INDEX IF NOT EXISTS idx_promotions_schedule ON promotions(starts_at, ends_at);
+
+CREATE TRIGGER update_promotions_updated_at 
+    BEFORE UPDATE ON promotions 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
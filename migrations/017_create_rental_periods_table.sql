@@ -0,0 +1,42 @@
+-- Create rental periods table
+-- Loans of book copies for libraries. A book's stock is its number of copies; a copy is
+-- out from starts_at until returned_at, or past due_at while it is not returned (late).
+
+CREATE TABLE IF NOT EXISTS rental_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    returned_at TIMESTAMP WITH TIME ZONE,
+    late BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_rental_periods_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_rental_periods_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT fk_rental_periods_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT chk_rental_periods_due CHECK (due_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rental_periods_book_starts_at ON rental_periods(book_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_rental_periods_user_id ON rental_periods(user_id);
+CREATE INDEX IF NOT EXISTS idx_rental_periods_outstanding ON rental_periods(due_at) WHERE returned_at IS NULL;
+
+CREATE TRIGGER update_rental_periods_updated_at 
+    BEFORE UPDATE ON rental_periods 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,32 @@
+-- Create API keys table
+-- Long-lived credentials for scripts and the bookstorectl admin CLI, stored as SHA-256 hashes.
+-- Rotating a key replaces its hash, so the previous key stops working at once.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    hint VARCHAR(8) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    rotated_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_api_keys_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+CREATE TRIGGER update_api_keys_updated_at 
+    BEFORE UPDATE ON api_keys 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
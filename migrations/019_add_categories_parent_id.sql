@@ -0,0 +1,10 @@
+-- Nest categories under a parent category
+-- Top-level categories have no parent; the service rejects moves that would create a cycle
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id UUID 
+    CONSTRAINT fk_categories_parent 
+    REFERENCES categories(id) 
+    ON UPDATE CASCADE 
+    ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
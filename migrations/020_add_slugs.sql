@@ -0,0 +1,49 @@
+-- Add URL slugs to books, authors, and categories, and the history of slugs they had before being renamed
+-- Existing rows get slugs from their title or name; duplicates within a tenant get a suffix from their ID
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+UPDATE books SET slug = COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(lower(left(title, 200)), '[^a-z0-9]+', '-', 'g')), ''), 'untitled') WHERE slug IS NULL;
+UPDATE authors SET slug = COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(lower(left(name, 200)), '[^a-z0-9]+', '-', 'g')), ''), 'untitled') WHERE slug IS NULL;
+UPDATE categories SET slug = COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(lower(left(name, 200)), '[^a-z0-9]+', '-', 'g')), ''), 'untitled') WHERE slug IS NULL;
+
+WITH duplicates AS (
+    SELECT id, row_number() OVER (PARTITION BY tenant_id, slug ORDER BY created_at, id) AS n FROM books
+)
+UPDATE books SET slug = books.slug || '-' || left(books.id::text, 8) FROM duplicates WHERE books.id = duplicates.id AND duplicates.n > 1;
+WITH duplicates AS (
+    SELECT id, row_number() OVER (PARTITION BY tenant_id, slug ORDER BY created_at, id) AS n FROM authors
+)
+UPDATE authors SET slug = authors.slug || '-' || left(authors.id::text, 8) FROM duplicates WHERE authors.id = duplicates.id AND duplicates.n > 1;
+WITH duplicates AS (
+    SELECT id, row_number() OVER (PARTITION BY tenant_id, slug ORDER BY created_at, id) AS n FROM categories
+)
+UPDATE categories SET slug = categories.slug || '-' || left(categories.id::text, 8) FROM duplicates WHERE categories.id = duplicates.id AND duplicates.n > 1;
+
+ALTER TABLE books ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE authors ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uni_books_tenant_slug ON books(tenant_id, slug);
+CREATE UNIQUE INDEX IF NOT EXISTS uni_authors_tenant_slug ON authors(tenant_id, slug);
+CREATE UNIQUE INDEX IF NOT EXISTS uni_categories_tenant_slug ON categories(tenant_id, slug);
+
+CREATE TABLE IF NOT EXISTS slug_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    entity_table VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_slug_history_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT uni_slug_history_slug UNIQUE (tenant_id, entity_table, slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slug_history_entity_id ON slug_history(entity_id);
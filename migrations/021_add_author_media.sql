@@ -0,0 +1,8 @@
+-- Author photos and external links
+-- photos maps each photo size to its URL; photo_key is the media storage key
+-- prefix the sizes are stored under. external_links maps a label such as
+-- website or twitter to a URL.
+
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS photos JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS photo_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS external_links JSONB NOT NULL DEFAULT '{}';
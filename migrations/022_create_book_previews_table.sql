@@ -0,0 +1,23 @@
+-- Book previews: one sample excerpt per book, kept in the media storage under
+-- key and served at GET /api/v1/books/:id/preview
+
+CREATE TABLE IF NOT EXISTS book_previews (
+    book_id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_book_previews_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT fk_book_previews_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT
+);
@@ -0,0 +1,69 @@
+-- Create saved searches and stock alerts tables
+-- Users save book searches to be told about newly added matches, and subscribe
+-- to out-of-stock books to be told when they are back in stock. The alert_scan
+-- scheduled task checks both and emails the users.
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    query VARCHAR(255) NOT NULL,
+    last_checked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_saved_searches_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_saved_searches_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches(user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_last_checked_at ON saved_searches(last_checked_at);
+
+CREATE TRIGGER update_saved_searches_updated_at 
+    BEFORE UPDATE ON saved_searches 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stock_alerts_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_stock_alerts_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT fk_stock_alerts_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_alerts_user_book ON stock_alerts(user_id, book_id);
+CREATE INDEX IF NOT EXISTS idx_stock_alerts_book_id ON stock_alerts(book_id);
+CREATE INDEX IF NOT EXISTS idx_stock_alerts_pending ON stock_alerts(book_id) WHERE notified_at IS NULL;
+
+CREATE TRIGGER update_stock_alerts_updated_at 
+    BEFORE UPDATE ON stock_alerts 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,26 @@
+-- Create book views table
+-- One row per view of a book's page, written in batches by the view tracker.
+-- Views feed book stats, users' recently viewed books, and trending books.
+
+CREATE TABLE IF NOT EXISTS book_views (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    user_id UUID,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    CONSTRAINT fk_book_views_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_book_views_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_views_tenant_viewed_at ON book_views(tenant_id, viewed_at);
+CREATE INDEX IF NOT EXISTS idx_book_views_book_viewed_at ON book_views(book_id, viewed_at);
+CREATE INDEX IF NOT EXISTS idx_book_views_user_viewed_at ON book_views(user_id, viewed_at) WHERE user_id IS NOT NULL;
@@ -0,0 +1,62 @@
+-- Create user preferences and consent records tables
+-- user_preferences holds each customer's current consents; consent_records is
+-- the append-only history of consents given and withdrawn, with the privacy
+-- policy version each was given under. Marketing email is only sent to users
+-- whose email_marketing consent is given.
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    email_marketing BOOLEAN NOT NULL DEFAULT FALSE,
+    email_marketing_at TIMESTAMP WITH TIME ZONE,
+    email_marketing_policy VARCHAR(50),
+    data_processing BOOLEAN NOT NULL DEFAULT FALSE,
+    data_processing_at TIMESTAMP WITH TIME ZONE,
+    data_processing_policy VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_preferences_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_user_preferences_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_preferences_tenant_id ON user_preferences(tenant_id);
+
+CREATE TRIGGER update_user_preferences_updated_at 
+    BEFORE UPDATE ON user_preferences 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS consent_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    granted BOOLEAN NOT NULL,
+    policy_version VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    CONSTRAINT fk_consent_records_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_consent_records_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT chk_consent_records_purpose CHECK (purpose IN ('email_marketing', 'data_processing'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_consent_records_user_recorded_at ON consent_records(user_id, recorded_at);
@@ -0,0 +1,12 @@
+-- Prepare user emails for encryption at rest
+-- Encrypted emails are longer than the plaintext and differ on every write, so
+-- the columns are widened and users get email_hash, a keyed hash of the
+-- lowercased email that lookups and uniqueness use instead. The LOWER(email)
+-- index still guards emails stored while encryption is disabled.
+-- Existing rows are encrypted by `go run ./cmd/migrate -action=rotate-keys`.
+
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(512);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_hash VARCHAR(64);
+ALTER TABLE user_identities ALTER COLUMN email TYPE VARCHAR(512);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uni_users_tenant_email_hash ON users(tenant_id, email_hash) WHERE email_hash IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,30 @@
+-- Create security events table
+-- Append-only log of authentication events: failed and successful logins and
+-- token refreshes, lockouts, failed CAPTCHAs, refresh token reuse, and
+-- sign-ins from a device the user has not used before. user_id is set when
+-- the event could be tied to an account.
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    user_id UUID,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_security_events_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_security_events_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_tenant_created_at ON security_events(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_security_events_user_created_at ON security_events(user_id, created_at);
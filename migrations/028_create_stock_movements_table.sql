@@ -0,0 +1,35 @@
+-- Create stock movements table
+-- Append-only log of stock changes. In the event-sourced inventory mode every
+-- change is recorded here and books.stock is the sum of a book's deltas, which
+-- `make rebuild-stock` recomputes.
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    delta INTEGER NOT NULL,
+    stock_after INTEGER NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    note VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stock_movements_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_stock_movements_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_book_created_at ON stock_movements(book_id, created_at);
+
+-- Seed the log with each existing book's current stock
+INSERT INTO stock_movements (tenant_id, book_id, delta, stock_after, reason, created_at)
+SELECT tenant_id, id, stock, stock, 'opening_balance', created_at
+FROM books
+WHERE stock <> 0
+  AND NOT EXISTS (SELECT 1 FROM stock_movements WHERE stock_movements.book_id = books.id);
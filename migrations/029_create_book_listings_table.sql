@@ -0,0 +1,48 @@
+-- Create book listings table
+-- Denormalized read model of the catalog: one row per book with its author and
+-- category names, rating summary, and effective price under active
+-- promotions. Rows are written from domain events when READ_MODELS_ENABLED is
+-- set, and rebuilt by the listing_rebuild task.
+
+CREATE TABLE IF NOT EXISTS book_listings (
+    book_id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    isbn VARCHAR(20) NOT NULL,
+    description TEXT,
+    price DECIMAL(10,2) NOT NULL,
+    stock INTEGER NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    publisher_id UUID,
+    author_id UUID NOT NULL,
+    author_name VARCHAR(255) NOT NULL,
+    author_slug VARCHAR(255) NOT NULL,
+    category_id UUID NOT NULL,
+    category_name VARCHAR(100) NOT NULL,
+    category_slug VARCHAR(255) NOT NULL,
+    average_rating DECIMAL(3,2),
+    ratings_count BIGINT NOT NULL DEFAULT 0,
+    effective_price DECIMAL(10,2) NOT NULL,
+    promotion_id UUID,
+    promotion_name VARCHAR(255),
+    promotion_ends_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    CONSTRAINT fk_book_listings_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_book_listings_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_listings_tenant_created_at ON book_listings(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_book_listings_author_id ON book_listings(author_id);
+CREATE INDEX IF NOT EXISTS idx_book_listings_category_id ON book_listings(category_id);
@@ -0,0 +1,19 @@
+-- Create rental statistics materialized views
+-- Daily rental counts per book, so most-rented rankings and rental trends are
+-- read from a small precomputed view instead of scanning rental_periods. The
+-- stats_refresh task refreshes the view; the unique index allows it to be
+-- refreshed concurrently, without blocking readers.
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS rental_daily_stats AS
+SELECT
+    tenant_id,
+    book_id,
+    (starts_at AT TIME ZONE 'UTC')::date AS day,
+    COUNT(*) AS rentals,
+    COUNT(*) FILTER (WHERE late) AS late_rentals,
+    COUNT(*) FILTER (WHERE returned_at IS NOT NULL) AS returned
+FROM rental_periods
+GROUP BY tenant_id, book_id, (starts_at AT TIME ZONE 'UTC')::date;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uni_rental_daily_stats_book_day ON rental_daily_stats(tenant_id, book_id, day);
+CREATE INDEX IF NOT EXISTS idx_rental_daily_stats_tenant_day ON rental_daily_stats(tenant_id, day);
@@ -0,0 +1,182 @@
+-- Partition the append-only log tables by month
+-- stock_movements, security_events, and book_views grow without bound, so each
+-- is range partitioned on its timestamp into one partition per UTC month.
+-- Queries over a date range only scan the months they cover, and old months
+-- can be detached or dropped without a bulk delete. Rows outside every
+-- monthly partition land in the table's default partition.
+--
+-- create_monthly_partitions creates the partitions for a range of months and
+-- is also called by the partition_sync task, which keeps partitions
+-- created ahead of time. Primary keys include the partition column, as
+-- PostgreSQL requires.
+
+-- Creates the monthly partitions of parent from first_month through
+-- last_month that do not exist yet and returns their names. Rows already in
+-- the default partition for a new month are moved into it.
+CREATE OR REPLACE FUNCTION create_monthly_partitions(parent TEXT, first_month DATE, last_month DATE)
+RETURNS SETOF TEXT AS $$
+DECLARE
+    key_column TEXT;
+    month DATE;
+    partition_name TEXT;
+    starts_at TIMESTAMP WITH TIME ZONE;
+    ends_at TIMESTAMP WITH TIME ZONE;
+BEGIN
+    SELECT a.attname INTO key_column
+    FROM pg_partitioned_table p
+    JOIN pg_attribute a ON a.attrelid = p.partrelid AND a.attnum = p.partattrs[0]
+    WHERE p.partrelid = parent::regclass;
+
+    IF key_column IS NULL THEN
+        RAISE EXCEPTION '% is not a partitioned table', parent;
+    END IF;
+
+    FOR month IN
+        SELECT generate_series(date_trunc('month', first_month::timestamp), date_trunc('month', last_month::timestamp), INTERVAL '1 month')::date
+    LOOP
+        partition_name := parent || '_p' || to_char(month, 'YYYYMM');
+        CONTINUE WHEN to_regclass(partition_name) IS NOT NULL;
+
+        starts_at := month::timestamp AT TIME ZONE 'UTC';
+        ends_at := (month + INTERVAL '1 month')::timestamp AT TIME ZONE 'UTC';
+
+        EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', partition_name, parent);
+        IF to_regclass(parent || '_default') IS NOT NULL THEN
+            EXECUTE format(
+                'WITH moved AS (DELETE FROM %I WHERE %I >= %L AND %I < %L RETURNING *) INSERT INTO %I SELECT * FROM moved',
+                parent || '_default', key_column, starts_at, key_column, ends_at, partition_name
+            );
+        END IF;
+        EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', parent, partition_name, starts_at, ends_at);
+
+        RETURN NEXT partition_name;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Stock movements
+ALTER TABLE stock_movements RENAME TO stock_movements_legacy;
+ALTER TABLE stock_movements_legacy RENAME CONSTRAINT stock_movements_pkey TO stock_movements_legacy_pkey;
+DROP INDEX IF EXISTS idx_stock_movements_book_created_at;
+
+CREATE TABLE stock_movements (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    delta INTEGER NOT NULL,
+    stock_after INTEGER NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    note VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id, created_at),
+    CONSTRAINT fk_stock_movements_tenant
+        FOREIGN KEY (tenant_id)
+        REFERENCES tenants(id)
+        ON UPDATE CASCADE
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_stock_movements_book
+        FOREIGN KEY (book_id)
+        REFERENCES books(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE stock_movements_default PARTITION OF stock_movements DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_stock_movements_book_created_at ON stock_movements(book_id, created_at);
+
+-- Security events
+ALTER TABLE security_events RENAME TO security_events_legacy;
+ALTER TABLE security_events_legacy RENAME CONSTRAINT security_events_pkey TO security_events_legacy_pkey;
+DROP INDEX IF EXISTS idx_security_events_tenant_created_at;
+DROP INDEX IF EXISTS idx_security_events_user_created_at;
+
+CREATE TABLE security_events (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    user_id UUID,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id, created_at),
+    CONSTRAINT fk_security_events_tenant
+        FOREIGN KEY (tenant_id)
+        REFERENCES tenants(id)
+        ON UPDATE CASCADE
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_security_events_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE security_events_default PARTITION OF security_events DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_security_events_tenant_created_at ON security_events(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_security_events_user_created_at ON security_events(user_id, created_at);
+
+-- Book views keep their id sequence
+ALTER TABLE book_views RENAME TO book_views_legacy;
+ALTER TABLE book_views_legacy RENAME CONSTRAINT book_views_pkey TO book_views_legacy_pkey;
+ALTER SEQUENCE book_views_id_seq OWNED BY NONE;
+DROP INDEX IF EXISTS idx_book_views_tenant_viewed_at;
+DROP INDEX IF EXISTS idx_book_views_book_viewed_at;
+DROP INDEX IF EXISTS idx_book_views_user_viewed_at;
+
+CREATE TABLE book_views (
+    id BIGINT NOT NULL DEFAULT nextval('book_views_id_seq'),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    user_id UUID,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    PRIMARY KEY (id, viewed_at),
+    CONSTRAINT fk_book_views_tenant
+        FOREIGN KEY (tenant_id)
+        REFERENCES tenants(id)
+        ON UPDATE CASCADE
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_book_views_book
+        FOREIGN KEY (book_id)
+        REFERENCES books(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+) PARTITION BY RANGE (viewed_at);
+
+ALTER SEQUENCE book_views_id_seq OWNED BY book_views.id;
+
+CREATE TABLE book_views_default PARTITION OF book_views DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_book_views_tenant_viewed_at ON book_views(tenant_id, viewed_at);
+CREATE INDEX IF NOT EXISTS idx_book_views_book_viewed_at ON book_views(book_id, viewed_at);
+CREATE INDEX IF NOT EXISTS idx_book_views_user_viewed_at ON book_views(user_id, viewed_at) WHERE user_id IS NOT NULL;
+
+-- Create partitions for every month with existing rows and the next three,
+-- then copy the rows over
+SELECT create_monthly_partitions('stock_movements',
+    COALESCE((SELECT MIN(created_at) AT TIME ZONE 'UTC' FROM stock_movements_legacy), CURRENT_TIMESTAMP AT TIME ZONE 'UTC')::date,
+    ((CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '3 months')::date);
+SELECT create_monthly_partitions('security_events',
+    COALESCE((SELECT MIN(created_at) AT TIME ZONE 'UTC' FROM security_events_legacy), CURRENT_TIMESTAMP AT TIME ZONE 'UTC')::date,
+    ((CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '3 months')::date);
+SELECT create_monthly_partitions('book_views',
+    COALESCE((SELECT MIN(viewed_at) AT TIME ZONE 'UTC' FROM book_views_legacy), CURRENT_TIMESTAMP AT TIME ZONE 'UTC')::date,
+    ((CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '3 months')::date);
+
+INSERT INTO stock_movements (id, tenant_id, book_id, delta, stock_after, reason, note, created_at)
+SELECT id, tenant_id, book_id, delta, stock_after, reason, note, COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM stock_movements_legacy;
+
+INSERT INTO security_events (id, tenant_id, type, user_id, ip_address, user_agent, details, created_at)
+SELECT id, tenant_id, type, user_id, ip_address, user_agent, details, COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM security_events_legacy;
+
+INSERT INTO book_views (id, tenant_id, book_id, user_id, viewed_at)
+SELECT id, tenant_id, book_id, user_id, viewed_at
+FROM book_views_legacy;
+
+DROP TABLE stock_movements_legacy;
+DROP TABLE security_events_legacy;
+DROP TABLE book_views_legacy;
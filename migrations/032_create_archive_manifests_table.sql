@@ -0,0 +1,20 @@
+-- Create archive manifests table
+-- One row per gzipped JSON Lines archive the archive task wrote to cold
+-- storage before deleting the archived rows. Archives hold every tenant's
+-- rows, so the table has no tenant_id.
+
+CREATE TABLE IF NOT EXISTS archive_manifests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_table VARCHAR(100) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    rows BIGINT NOT NULL,
+    oldest_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    newest_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uni_archive_manifests_key UNIQUE (key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_archive_manifests_table_newest ON archive_manifests(source_table, newest_at);
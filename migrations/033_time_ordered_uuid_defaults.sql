@@ -0,0 +1,42 @@
+-- Generate time-ordered primary keys in the database
+-- The application assigns IDs itself (see ID_STRATEGY), but rows inserted by
+-- SQL, and models without a BeforeCreate hook, take the column default.
+-- gen_random_uuid() returns random UUIDv4 values, which scatter inserts across
+-- the primary key index; uuid_generate_v7() starts each UUID with the current
+-- Unix time in milliseconds, so new rows land at the end of the index.
+--
+-- Existing IDs are kept: UUIDv4 and UUIDv7 values share the UUID type and
+-- never collide, so no rows or foreign keys are rewritten.
+
+-- Returns a UUIDv7: the random bits of a UUIDv4 with the first 48 bits
+-- replaced by the Unix time in milliseconds and the version set to 7
+CREATE OR REPLACE FUNCTION uuid_generate_v7()
+RETURNS UUID AS $$
+BEGIN
+    RETURN encode(
+        set_bit(
+            set_bit(
+                overlay(uuid_send(gen_random_uuid())
+                    PLACING substring(int8send(floor(extract(epoch FROM clock_timestamp()) * 1000)::BIGINT) FROM 3)
+                    FROM 1 FOR 6),
+                52, 1),
+            53, 1),
+        'hex')::UUID;
+END;
+$$ LANGUAGE plpgsql VOLATILE;
+
+-- Switch every column defaulting to gen_random_uuid() to uuid_generate_v7(),
+-- including the partitions of partitioned tables
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema() AND column_default = 'gen_random_uuid()'
+    LOOP
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I SET DEFAULT uuid_generate_v7()', col.table_name, col.column_name);
+    END LOOP;
+END;
+$$;
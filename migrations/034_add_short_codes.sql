@@ -0,0 +1,37 @@
+-- Add short codes to books and rentals
+-- Short codes are 8-character base58 public identifiers, without the easily
+-- confused 0, O, I, and l, for customer-facing URLs and support calls. The
+-- application assigns them to new rows; gen_short_code() backfills existing
+-- rows and is the column default for rows inserted by SQL. Codes are unique
+-- per tenant, like slugs.
+
+CREATE OR REPLACE FUNCTION gen_short_code()
+RETURNS TEXT AS $$
+DECLARE
+    alphabet CONSTANT TEXT := '123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz';
+    code TEXT := '';
+BEGIN
+    FOR i IN 1..8 LOOP
+        code := code || substr(alphabet, 1 + floor(random() * 58)::INTEGER, 1);
+    END LOOP;
+    RETURN code;
+END;
+$$ LANGUAGE plpgsql VOLATILE;
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS short_code VARCHAR(16);
+UPDATE books SET short_code = gen_short_code() WHERE short_code IS NULL;
+ALTER TABLE books ALTER COLUMN short_code SET DEFAULT gen_short_code();
+ALTER TABLE books ALTER COLUMN short_code SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_books_tenant_short_code ON books(tenant_id, short_code);
+
+ALTER TABLE rental_periods ADD COLUMN IF NOT EXISTS short_code VARCHAR(16);
+UPDATE rental_periods SET short_code = gen_short_code() WHERE short_code IS NULL;
+ALTER TABLE rental_periods ALTER COLUMN short_code SET DEFAULT gen_short_code();
+ALTER TABLE rental_periods ALTER COLUMN short_code SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uni_rental_periods_tenant_short_code ON rental_periods(tenant_id, short_code);
+
+-- The storefront read model carries the book's short code
+ALTER TABLE book_listings ADD COLUMN IF NOT EXISTS short_code VARCHAR(16);
+UPDATE book_listings SET short_code = books.short_code FROM books WHERE books.id = book_listings.book_id;
+UPDATE book_listings SET short_code = '' WHERE short_code IS NULL;
+ALTER TABLE book_listings ALTER COLUMN short_code SET NOT NULL;
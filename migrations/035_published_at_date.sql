@@ -0,0 +1,10 @@
+-- Store publication dates as dates
+-- Publishers supply a publication date, not an instant, so published_at
+-- becomes a DATE. Existing values keep their UTC date, which is the date they
+-- were given as: date-only values were stored at midnight UTC.
+
+ALTER TABLE books
+    ALTER COLUMN published_at TYPE DATE USING (published_at AT TIME ZONE 'UTC')::date;
+
+ALTER TABLE book_listings
+    ALTER COLUMN published_at TYPE DATE USING (published_at AT TIME ZONE 'UTC')::date;
@@ -0,0 +1,5 @@
+-- Add progress reporting to background jobs
+-- Long-running jobs, such as rebuilding search indexes, record how far they
+-- have got so admins can follow them
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS progress JSONB;
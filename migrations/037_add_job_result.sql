@@ -0,0 +1,5 @@
+-- Add results to background jobs
+-- Imports and exports run as long-running operations record what they
+-- produced, such as links to the files they wrote, for clients polling them
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS result JSONB;
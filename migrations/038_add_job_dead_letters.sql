@@ -0,0 +1,12 @@
+-- Keep failed background jobs as a dead-letter queue
+-- Jobs that exhaust their attempts stay in the jobs table with the time they
+-- failed, so admins can inspect, retry, or purge them, and count how often
+-- they were retried by hand
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS retries INTEGER NOT NULL DEFAULT 0;
+
+-- Jobs that failed before this migration are dated by their last update
+UPDATE jobs SET failed_at = updated_at WHERE status = 'failed' AND failed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_failed_at ON jobs(failed_at) WHERE status = 'failed';
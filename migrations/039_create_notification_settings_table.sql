@@ -0,0 +1,34 @@
+-- Create notification settings table
+-- Each user's choice of channels (email, sms, push, slack) per notification
+-- topic, with the contact details the channels need. Phone numbers and Slack
+-- webhook URLs are personal data, encrypted like emails when
+-- PII_ENCRYPTION_KEYS is set.
+
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    channels JSONB NOT NULL DEFAULT '{}',
+    phone VARCHAR(512),
+    push_tokens JSONB NOT NULL DEFAULT '[]',
+    slack_webhook_url VARCHAR(4096),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_notification_settings_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_notification_settings_user 
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_settings_tenant_id ON notification_settings(tenant_id);
+
+CREATE TRIGGER update_notification_settings_updated_at 
+    BEFORE UPDATE ON notification_settings 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,41 @@
+-- Create book revisions table
+-- Changes to books proposed by editors who are not admins wait here as
+-- pending revisions, with each changed field's value before and after, until
+-- an admin approves them onto the live catalog or rejects them.
+
+CREATE TABLE IF NOT EXISTS book_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    changes JSONB NOT NULL DEFAULT '{}',
+    note VARCHAR(1000),
+    submitted_by UUID NOT NULL,
+    reviewed_by UUID,
+    review_note VARCHAR(1000),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_book_revisions_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_book_revisions_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT chk_book_revisions_status 
+        CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_revisions_tenant_status ON book_revisions(tenant_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_book_revisions_book_id ON book_revisions(book_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_book_revisions_submitted_by ON book_revisions(submitted_by, created_at);
+
+CREATE TRIGGER update_book_revisions_updated_at 
+    BEFORE UPDATE ON book_revisions 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
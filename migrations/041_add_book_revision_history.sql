@@ -0,0 +1,16 @@
+-- Add revision history to book revisions
+-- Every change applied to a book, whether made by an admin directly,
+-- approved from an editor's revision, or reverting an earlier revision, is
+-- kept as an approved revision with a snapshot of the book's fields after
+-- it. Changes made with an admin API key record no submitter.
+
+ALTER TABLE book_revisions ALTER COLUMN submitted_by DROP NOT NULL;
+ALTER TABLE book_revisions ADD COLUMN IF NOT EXISTS snapshot JSONB;
+ALTER TABLE book_revisions ADD COLUMN IF NOT EXISTS reverted_from UUID;
+
+ALTER TABLE book_revisions
+    ADD CONSTRAINT fk_book_revisions_reverted_from
+        FOREIGN KEY (reverted_from)
+        REFERENCES book_revisions(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
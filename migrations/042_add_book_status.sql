@@ -0,0 +1,14 @@
+-- Add a status to books
+-- Books are draft, active, or archived. Only active books are listed and
+-- searched publicly; existing books stay visible as active.
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE books
+    ADD CONSTRAINT chk_books_status CHECK (status IN ('draft', 'active', 'archived'));
+CREATE INDEX IF NOT EXISTS idx_books_status ON books(status);
+
+-- The storefront read model carries the book's status so listings can leave
+-- out books that are not active
+ALTER TABLE book_listings ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+UPDATE book_listings SET status = books.status FROM books WHERE books.id = book_listings.book_id;
+CREATE INDEX IF NOT EXISTS idx_book_listings_tenant_status_created_at ON book_listings(tenant_id, status, created_at);
@@ -0,0 +1,102 @@
+-- Create warehouses tables
+-- Warehouses are the locations stock is held at. warehouse_stocks holds the
+-- stock of each book at each warehouse; a book's stock stays its total on
+-- hand, of which the part not held at any warehouse is unallocated.
+-- stock_transfers records stock moved between warehouses.
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v7(),
+    tenant_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_warehouses_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uni_warehouses_tenant_code ON warehouses(tenant_id, code);
+
+CREATE TRIGGER update_warehouses_updated_at 
+    BEFORE UPDATE ON warehouses 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS warehouse_stocks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v7(),
+    tenant_id UUID NOT NULL,
+    warehouse_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_warehouse_stocks_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_warehouse_stocks_warehouse 
+        FOREIGN KEY (warehouse_id) 
+        REFERENCES warehouses(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT fk_warehouse_stocks_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT chk_warehouse_stocks_quantity CHECK (quantity >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uni_warehouse_stocks_warehouse_book ON warehouse_stocks(warehouse_id, book_id);
+CREATE INDEX IF NOT EXISTS idx_warehouse_stocks_book_id ON warehouse_stocks(book_id);
+
+CREATE TRIGGER update_warehouse_stocks_updated_at 
+    BEFORE UPDATE ON warehouse_stocks 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v7(),
+    tenant_id UUID NOT NULL,
+    book_id UUID NOT NULL,
+    from_warehouse_id UUID,
+    to_warehouse_id UUID,
+    quantity INTEGER NOT NULL,
+    note VARCHAR(255),
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stock_transfers_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_stock_transfers_book 
+        FOREIGN KEY (book_id) 
+        REFERENCES books(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT fk_stock_transfers_from_warehouse 
+        FOREIGN KEY (from_warehouse_id) 
+        REFERENCES warehouses(id) 
+        ON UPDATE CASCADE 
+        ON DELETE SET NULL,
+    CONSTRAINT fk_stock_transfers_to_warehouse 
+        FOREIGN KEY (to_warehouse_id) 
+        REFERENCES warehouses(id) 
+        ON UPDATE CASCADE 
+        ON DELETE SET NULL,
+    CONSTRAINT chk_stock_transfers_quantity CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_book_id ON stock_transfers(book_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_from_warehouse_id ON stock_transfers(from_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_to_warehouse_id ON stock_transfers(to_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_created_at ON stock_transfers(created_at);
@@ -0,0 +1,74 @@
+-- Create suppliers tables
+-- suppliers are dropshipping suppliers whose availability and price feeds
+-- (CSV or JSON over HTTP) are synced into the catalog by ISBN. auth_value is
+-- encrypted by the application when PII_ENCRYPTION_KEYS is set.
+-- supplier_syncs records each sync and the changes it applied.
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v7(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    feed_url VARCHAR(2048) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    auth_header VARCHAR(100),
+    auth_value VARCHAR(4096),
+    warehouse_id UUID,
+    update_prices BOOLEAN NOT NULL DEFAULT TRUE,
+    update_stock BOOLEAN NOT NULL DEFAULT TRUE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_suppliers_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_suppliers_warehouse 
+        FOREIGN KEY (warehouse_id) 
+        REFERENCES warehouses(id) 
+        ON UPDATE CASCADE 
+        ON DELETE SET NULL,
+    CONSTRAINT chk_suppliers_format CHECK (format IN ('csv', 'json'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uni_suppliers_tenant_name ON suppliers(tenant_id, name);
+
+CREATE TRIGGER update_suppliers_updated_at 
+    BEFORE UPDATE ON suppliers 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS supplier_syncs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v7(),
+    tenant_id UUID NOT NULL,
+    supplier_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    rows INTEGER NOT NULL DEFAULT 0,
+    invalid INTEGER NOT NULL DEFAULT 0,
+    matched INTEGER NOT NULL DEFAULT 0,
+    unmatched INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    changes JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    CONSTRAINT fk_supplier_syncs_tenant 
+        FOREIGN KEY (tenant_id) 
+        REFERENCES tenants(id) 
+        ON UPDATE CASCADE 
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_supplier_syncs_supplier 
+        FOREIGN KEY (supplier_id) 
+        REFERENCES suppliers(id) 
+        ON UPDATE CASCADE 
+        ON DELETE CASCADE,
+    CONSTRAINT chk_supplier_syncs_status CHECK (status IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_syncs_supplier_started ON supplier_syncs(supplier_id, started_at);
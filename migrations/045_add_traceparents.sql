@@ -0,0 +1,7 @@
+-- Keep the trace of the request behind outbox events and background jobs
+-- Event consumers and jobs, such as webhook deliveries and notifications,
+-- continue the trace of the request that recorded or enqueued them, so its
+-- trace ID follows the request through every subsystem
+
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS traceparent VARCHAR(55);
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS traceparent VARCHAR(55);
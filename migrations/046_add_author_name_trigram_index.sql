@@ -0,0 +1,8 @@
+-- Index author names by trigram for fuzzy author search
+-- /authors/search also matches names whose words are close to the query,
+-- so a misspelling such as "Tolkein" still finds "Tolkien". The GIN index
+-- serves the pg_trgm <% (word similarity) operator the search uses.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_authors_name_trgm ON authors USING GIN (name gin_trgm_ops);
@@ -0,0 +1,6 @@
+-- Add format and page count to books
+-- Both are optional. format is hardcover, paperback, ebook, or audiobook;
+-- they are shown side by side by the book comparison endpoint.
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS format VARCHAR(20);
+ALTER TABLE books ADD COLUMN IF NOT EXISTS page_count INTEGER CHECK (page_count > 0);
@@ -0,0 +1,160 @@
+// Package grpcclient is a client of the bookstore gRPC API for other Go
+// services. It keeps a pool of connections, gives calls without a deadline a
+// default one, retries calls the server reports UNAVAILABLE, and sends the
+// tenant with every call, along with the trace of the call's context so the
+// server's log lines for the call carry the caller's trace ID:
+//
+//	client, err := grpcclient.New(grpcclient.Config{Target: "bookstore:50051", Tenant: "acme"})
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	book, err := client.Books.GetBook(ctx, &pb.GetBookRequest{Id: id})
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Defaults of the configuration
+const (
+	DefaultTenantHeader = "x-tenant"
+	DefaultPoolSize     = 1
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Config configures a client. Zero values take the defaults.
+type Config struct {
+	// Target is the server address, such as bookstore:50051, or any target
+	// grpc.NewClient accepts
+	Target string
+	// Tenant is sent as the tenant metadata key of every call; empty uses the
+	// server's tenant resolution, falling back to the default tenant
+	Tenant string
+	// TenantHeader is the metadata key the server reads the tenant from
+	TenantHeader string
+	// Credentials secure the connections; nil connects without TLS
+	Credentials credentials.TransportCredentials
+	// PoolSize is the number of connections calls are spread over
+	PoolSize int
+	// Timeout is the deadline of calls whose context has none
+	Timeout time.Duration
+	// MaxRetries is how many times a call the server reports UNAVAILABLE is
+	// retried; negative disables retries
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled before each
+	// further retry
+	RetryBackoff time.Duration
+	// Interceptors run on each attempt of each call, after the client's own
+	// interceptors, for logging, metrics, or authentication
+	Interceptors []grpc.UnaryClientInterceptor
+	// DialOptions are added to the options of every connection
+	DialOptions []grpc.DialOption
+}
+
+// Client is a client of the bookstore gRPC API. It is safe for concurrent
+// use.
+type Client struct {
+	Books      *BookClient
+	Authors    *AuthorClient
+	Operations *OperationsClient
+
+	conns []*grpc.ClientConn
+	next  atomic.Uint64
+}
+
+// New creates a client of the server at cfg.Target. Connections are
+// established lazily, on the first call.
+func New(cfg Config) (*Client, error) {
+	if cfg.Target == "" {
+		return nil, errors.New("grpcclient: target is required")
+	}
+	cfg = withDefaults(cfg)
+
+	creds := cfg.Credentials
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	interceptors := append([]grpc.UnaryClientInterceptor{
+		deadlineInterceptor(cfg.Timeout),
+		retryInterceptor(cfg.MaxRetries, cfg.RetryBackoff),
+		tenantInterceptor(cfg.TenantHeader, cfg.Tenant),
+		traceInterceptor(),
+	}, cfg.Interceptors...)
+	options := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	}, cfg.DialOptions...)
+
+	c := &Client{}
+	for i := 0; i < cfg.PoolSize; i++ {
+		conn, err := grpc.NewClient(cfg.Target, options...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("grpcclient: failed to create connection: %w", err)
+		}
+		c.conns = append(c.conns, conn)
+	}
+	c.Books = newBookClient(c)
+	c.Authors = newAuthorClient(c)
+	c.Operations = newOperationsClient(c)
+	return c, nil
+}
+
+// withDefaults fills in the zero values of cfg
+func withDefaults(cfg Config) Config {
+	if cfg.TenantHeader == "" {
+		cfg.TenantHeader = DefaultTenantHeader
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = DefaultPoolSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	return cfg
+}
+
+// Invoke sends a unary call on the next connection of the pool, so the
+// client can back generated service clients
+func (c *Client) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return c.conn().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream opens a stream on the next connection of the pool
+func (c *Client) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.conn().NewStream(ctx, desc, method, opts...)
+}
+
+// conn returns the connections of the pool in turn
+func (c *Client) conn() *grpc.ClientConn {
+	return c.conns[(c.next.Add(1)-1)%uint64(len(c.conns))]
+}
+
+// Close closes the connections of the pool
+func (c *Client) Close() error {
+	var errs []error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
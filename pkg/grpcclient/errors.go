@@ -0,0 +1,31 @@
+package grpcclient
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorReason returns the reason of the ErrorInfo detail of a call's error,
+// such as BOOK_NOT_FOUND or VALIDATION_FAILED, or "" when it has none
+func ErrorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+// FieldViolations returns the invalid fields of a call's InvalidArgument
+// error, mapping each field to its description
+func FieldViolations(err error) map[string]string {
+	violations := make(map[string]string)
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+	return violations
+}
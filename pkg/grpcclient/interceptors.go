@@ -0,0 +1,75 @@
+package grpcclient
+
+import (
+	"bookstore-api/internal/tracing"
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// deadlineInterceptor gives calls whose context has no deadline the default
+// timeout, which covers their retries
+func deadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryInterceptor retries calls the server reports UNAVAILABLE, which it
+// returns before handling them, waiting backoff before the first retry and
+// twice as long before each further one. Creating a book with an ISBN that
+// already exists returns the existing book, so retried creates are safe.
+func retryInterceptor(maxRetries int, backoff time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		wait := backoff
+		for attempt := 0; attempt < maxRetries && status.Code(err) == codes.Unavailable; attempt++ {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+			wait *= 2
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return err
+	}
+}
+
+// tenantInterceptor sends the tenant in the metadata of every call, unless
+// the call's context already names one
+func tenantInterceptor(header, tenant string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if tenant != "" {
+			if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(header)) == 0 {
+				ctx = metadata.AppendToOutgoingContext(ctx, header, tenant)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// traceInterceptor sends the traceparent of the call context's span, when it
+// has one and the context does not already carry traceparent metadata, so the
+// server continues the caller's trace
+func traceInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if traceparent := tracing.Traceparent(ctx); traceparent != "" {
+			if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(tracing.Header)) == 0 {
+				ctx = metadata.AppendToOutgoingContext(ctx, tracing.Header, traceparent)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,128 @@
+package grpcclient
+
+import (
+	pb "bookstore-api/proto"
+	"context"
+	"time"
+)
+
+// listPageSize is the page size the iterators request
+const listPageSize = 100
+
+// BookClient calls the book service. Besides the service's methods, it walks
+// book listings page by page.
+type BookClient struct {
+	pb.BookServiceClient
+}
+
+// newBookClient creates a book client on the client's connections
+func newBookClient(c *Client) *BookClient {
+	return &BookClient{BookServiceClient: pb.NewBookServiceClient(c)}
+}
+
+// EachBook calls fn with every book, in the order GetAllBooks lists them,
+// stopping at the first error fn returns
+func (b *BookClient) EachBook(ctx context.Context, fn func(*pb.Book) error) error {
+	req := &pb.GetAllBooksRequest{PageSize: listPageSize}
+	for {
+		resp, err := b.GetAllBooks(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, book := range resp.GetBooks() {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		if resp.GetNextPageToken() == "" {
+			return nil
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+}
+
+// EachSearchResult calls fn with every book matching query, stopping at the
+// first error fn returns
+func (b *BookClient) EachSearchResult(ctx context.Context, query string, fn func(*pb.Book) error) error {
+	req := &pb.SearchBooksRequest{Query: query, PageSize: listPageSize}
+	for {
+		resp, err := b.SearchBooks(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, book := range resp.GetBooks() {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		if resp.GetNextPageToken() == "" {
+			return nil
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+}
+
+// AuthorClient calls the author service. Besides the service's methods, it
+// walks author listings page by page.
+type AuthorClient struct {
+	pb.AuthorServiceClient
+}
+
+// newAuthorClient creates an author client on the client's connections
+func newAuthorClient(c *Client) *AuthorClient {
+	return &AuthorClient{AuthorServiceClient: pb.NewAuthorServiceClient(c)}
+}
+
+// EachAuthor calls fn with every author, in the order GetAllAuthors lists
+// them, stopping at the first error fn returns
+func (a *AuthorClient) EachAuthor(ctx context.Context, fn func(*pb.Author) error) error {
+	req := &pb.GetAllAuthorsRequest{PageSize: listPageSize}
+	for {
+		resp, err := a.GetAllAuthors(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, author := range resp.GetAuthors() {
+			if err := fn(author); err != nil {
+				return err
+			}
+		}
+		if resp.GetNextPageToken() == "" {
+			return nil
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+}
+
+// OperationsClient calls the operations service. Besides the service's
+// methods, it waits for operations to finish.
+type OperationsClient struct {
+	pb.OperationsServiceClient
+}
+
+// newOperationsClient creates an operations client on the client's
+// connections
+func newOperationsClient(c *Client) *OperationsClient {
+	return &OperationsClient{OperationsServiceClient: pb.NewOperationsServiceClient(c)}
+}
+
+// Wait polls the named operation every interval until it is done, returning
+// it completed or failed, or until ctx ends
+func (o *OperationsClient) Wait(ctx context.Context, name string, interval time.Duration) (*pb.Operation, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp, err := o.GetOperation(ctx, &pb.GetOperationRequest{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		if resp.GetOperation().GetDone() {
+			return resp.GetOperation(), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}